@@ -0,0 +1,241 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+	mcp "github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+const (
+	queryRangePath = "/api/prometheus/api/v1/query_range"
+
+	// backtestMaxSteps is the most evaluation steps a backtest may request,
+	// matching the Prometheus query_range point limit.
+	backtestMaxSteps = 11000
+)
+
+// BacktestCheckRule returns the dash0_alerting_check_rules_backtest tool definition.
+func (p *Tools) BacktestCheckRule() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_alerting_check_rules_backtest",
+		Description: `Backtest a check rule expression against historical metrics in Dash0.
+
+Evaluates the PromQL expression over the past time range at every interval step with
+Dash0's query_range API, then applies the rule's "for" and "keepFiringFor" semantics to
+report when each alert would have become pending, fired, and resolved. Use it to tune
+thresholds before creating a rule with dash0_alerting_check_rules_create.
+
+Example queries:
+- Would a 5% error rate have fired today: {"expression": "sum(rate(http_requests_total{status=~\"5..\"}[5m])) / sum(rate(http_requests_total[5m])) > 0.05", "for": "5m"}
+- A week at a coarser step: {"expression": "up == 0", "for": "2m", "time_range_minutes": 10080, "interval": "5m"}`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"expression": map[string]interface{}{
+					"type":        "string",
+					"description": "PromQL alert expression to evaluate",
+				},
+				"time_range_minutes": map[string]interface{}{
+					"type":        "integer",
+					"description": "Minutes of history to evaluate (default: 1440, max: 10080)",
+				},
+				"interval": map[string]interface{}{
+					"type":        "string",
+					"description": "Evaluation interval (default: 1m)",
+				},
+				"for": map[string]interface{}{
+					"type":        "string",
+					"description": "Duration the condition must hold before firing (default: 0s)",
+				},
+				"keepFiringFor": map[string]interface{}{
+					"type":        "string",
+					"description": "Duration an alert keeps firing after the condition clears (default: 0s)",
+				},
+				"dataset": map[string]interface{}{
+					"type":        "string",
+					"description": "Dash0 dataset to query (e.g., 'astronomy-demo'). If omitted, uses the globally configured dataset or 'default'.",
+				},
+			},
+			Required: []string{"expression"},
+		},
+	}
+}
+
+// BacktestCheckRuleHandler handles the dash0_alerting_check_rules_backtest tool.
+func (p *Tools) BacktestCheckRuleHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	expression, _ := args["expression"].(string)
+	expression = strings.TrimSpace(expression)
+	if expression == "" {
+		return client.ErrorResult(400, "expression is required")
+	}
+	if _, err := parser.ParseExpr(expression); err != nil {
+		return client.ErrorResult(400, fmt.Sprintf("invalid expression: %v", err))
+	}
+
+	interval, err := parseRuleDuration(args, "interval", time.Minute)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+	if interval < time.Second {
+		return client.ErrorResult(400, "interval must be at least 1s")
+	}
+	forDuration, err := parseRuleDuration(args, "for", 0)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+	keepFiringFor, err := parseRuleDuration(args, "keepFiringFor", 0)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+
+	minutes := 1440
+	if m, ok := args["time_range_minutes"].(float64); ok {
+		if m < 0 {
+			return client.ErrorResult(400, "time_range_minutes must not be negative")
+		}
+		if m > 0 {
+			minutes = int(m)
+			if minutes > 10080 {
+				minutes = 10080 // Max 7 days
+			}
+		}
+	}
+	window := time.Duration(minutes) * time.Minute
+	if int64(window/interval) >= backtestMaxSteps {
+		return client.ErrorResult(400, fmt.Sprintf("time_range_minutes / interval exceeds %d evaluation steps; use a larger interval", backtestMaxSteps))
+	}
+
+	// Align the evaluation steps to the interval, as rule evaluation does.
+	end := time.Now().UTC().Truncate(interval)
+	start := end.Add(-window)
+
+	query := url.Values{}
+	query.Set("query", expression)
+	query.Set("start", strconv.FormatInt(start.Unix(), 10))
+	query.Set("end", strconv.FormatInt(end.Unix(), 10))
+	query.Set("step", strconv.FormatFloat(interval.Seconds(), 'f', -1, 64))
+
+	dataset, _ := args["dataset"].(string)
+	result := p.client.Get(client.WithDataset(ctx, dataset), queryRangePath+"?"+query.Encode())
+	if !result.Success {
+		return result
+	}
+
+	series, err := parseQueryRange(result.Data, start, interval)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+
+	steps := int(window/interval) + 1
+	at := func(d time.Duration) string { return start.Add(d).Format(time.RFC3339) }
+	alerts := replayAlerts(series, steps, interval, forDuration, keepFiringFor, at)
+
+	fired := 0
+	for _, a := range alerts {
+		if a.Fired {
+			fired++
+		}
+	}
+
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: formatBacktest(expression, alerts, fired, start, end, interval, forDuration),
+		Data: map[string]interface{}{
+			"expression": expression,
+			"alerts":     alerts,
+			"fired":      fired,
+			"pending":    len(alerts) - fired,
+			"steps":      steps,
+			"series":     len(series),
+			"time_range": map[string]string{
+				"from": start.Format(time.RFC3339),
+				"to":   end.Format(time.RFC3339),
+			},
+		},
+	}
+}
+
+// parseQueryRange converts a Prometheus query_range matrix response into
+// ruleSeries, indexing each sample by its evaluation step after start.
+func parseQueryRange(data interface{}, start time.Time, interval time.Duration) ([]ruleSeries, error) {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected query_range response")
+	}
+	if status, _ := m["status"].(string); status == "error" {
+		msg, _ := m["error"].(string)
+		return nil, fmt.Errorf("query failed: %s", msg)
+	}
+	body, ok := m["data"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected query_range response")
+	}
+	if resultType, _ := body["resultType"].(string); resultType != "" && resultType != "matrix" {
+		return nil, fmt.Errorf("expression must return an instant vector, got %s", resultType)
+	}
+
+	raw, _ := body["result"].([]interface{})
+	series := make([]ruleSeries, 0, len(raw))
+	for _, r := range raw {
+		rm, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		metric := map[string]string{}
+		if mm, ok := rm["metric"].(map[string]interface{}); ok {
+			for k, v := range mm {
+				metric[k] = fmt.Sprintf("%v", v)
+			}
+		}
+		rs := ruleSeries{
+			labels: labels.FromMap(metric).DropMetricName().String(),
+			values: map[int]float64{},
+		}
+		values, _ := rm["values"].([]interface{})
+		for _, v := range values {
+			pair, ok := v.([]interface{})
+			if !ok || len(pair) != 2 {
+				continue
+			}
+			ts, ok := pair[0].(float64)
+			if !ok {
+				continue
+			}
+			s, _ := pair[1].(string)
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				continue
+			}
+			step := int(math.Round((ts - float64(start.Unix())) / interval.Seconds()))
+			rs.values[step] = f
+		}
+		series = append(series, rs)
+	}
+	return series, nil
+}
+
+// formatBacktest renders backtest results as a markdown table.
+func formatBacktest(expression string, alerts []RuleTestAlert, fired int, start, end time.Time, interval, forDuration time.Duration) string {
+	summary := strings.Join([]string{
+		fmt.Sprintf("**%d alerts would have fired**, %d only pending", fired, len(alerts)-fired),
+		fmt.Sprintf("Evaluated `%s` from %s to %s every %s (for: %s)",
+			expression, start.Format("15:04 2006-01-02"), end.Format("15:04 2006-01-02"),
+			model.Duration(interval), model.Duration(forDuration)),
+	}, " | ")
+
+	if len(alerts) == 0 {
+		return fmt.Sprintf("## Check Rule Backtest\n\n%s\n\nThe expression never returned a result in this range, so the rule would not have fired.\n", summary)
+	}
+	return formatter.Table("Check Rule Backtest", summary, ruleAlertHeaders, ruleAlertRows(alerts), "")
+}
@@ -1,3 +1,5 @@
 // Package alerting provides MCP tools for Dash0 alerting operations.
-// This package enables management of check rules (Prometheus-style alert rules).
+// This package enables management of check rules (Prometheus-style alert rules),
+// testing them against synthetic or historical data, and reading the alerts
+// they raise.
 package alerting
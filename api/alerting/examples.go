@@ -0,0 +1,84 @@
+package alerting
+
+import "github.com/npcomplete777/dash0-mcp/internal/registry"
+
+// Compile-time interface check.
+var _ registry.ExampleProvider = (*Tools)(nil)
+
+// highErrorRateRule is a complete check rule body.
+var highErrorRateRule = map[string]interface{}{
+	"name":       "HighErrorRate",
+	"expression": `sum by (service_name) (rate(http_requests_total{status=~"5.."}[5m])) / sum by (service_name) (rate(http_requests_total[5m])) > 0.05`,
+	"interval":   "1m",
+	"for":        "5m",
+	"labels":     map[string]interface{}{"severity": "critical", "team": "platform"},
+	"annotations": map[string]interface{}{
+		"summary":     "High error rate on {{ $labels.service_name }}",
+		"description": "More than 5% of requests failed for 5 minutes",
+	},
+}
+
+// Examples returns example invocations for the alerting tools.
+func (p *Tools) Examples() map[string][]registry.Example {
+	return map[string][]registry.Example{
+		"dash0_alerting_check_rules_list": {
+			{Title: "List all check rules", Arguments: map[string]interface{}{}},
+		},
+		"dash0_alerting_check_rules_get": {
+			{Title: "Get a check rule by origin", Arguments: map[string]interface{}{"origin_or_id": "high-error-rate"}},
+		},
+		"dash0_alerting_check_rules_create": {
+			{Title: "Alert on a 5% error rate", Arguments: map[string]interface{}{"body": highErrorRateRule}},
+			{
+				Title: "Alert when a scrape target is down",
+				Arguments: map[string]interface{}{
+					"body": map[string]interface{}{
+						"name":          "TargetDown",
+						"expression":    "up == 0",
+						"interval":      "30s",
+						"for":           "2m",
+						"keepFiringFor": "5m",
+						"labels":        map[string]interface{}{"severity": "warning"},
+						"annotations":   map[string]interface{}{"summary": "{{ $labels.job }} is down"},
+					},
+				},
+			},
+		},
+		"dash0_alerting_check_rules_update": {
+			{Title: "Replace a check rule", Arguments: map[string]interface{}{"origin_or_id": "high-error-rate", "body": highErrorRateRule}},
+		},
+		"dash0_alerting_check_rules_delete": {
+			{Title: "Delete a check rule", Arguments: map[string]interface{}{"origin_or_id": "high-error-rate"}},
+		},
+		"dash0_alerting_check_rules_test": {
+			{
+				Title: "Check that a rising error counter fires",
+				Arguments: map[string]interface{}{
+					"expression": `rate(http_requests_total{status="500"}[5m]) > 0.1`,
+					"for":        "5m",
+					"series": []interface{}{
+						map[string]interface{}{"series": `http_requests_total{status="500",job="api"}`, "values": "0+1x10 10+60x20"},
+					},
+					"expect_firing": true,
+				},
+			},
+		},
+		"dash0_alerting_check_rules_backtest": {
+			{
+				Title: "Would a 5% error rate have fired in the last day",
+				Arguments: map[string]interface{}{
+					"expression": `sum(rate(http_requests_total{status=~"5.."}[5m])) / sum(rate(http_requests_total[5m])) > 0.05`,
+					"for":        "5m",
+				},
+			},
+			{
+				Title:     "A week of a target-down rule at a 5m step",
+				Arguments: map[string]interface{}{"expression": "up == 0", "for": "2m", "time_range_minutes": 10080, "interval": "5m"},
+			},
+		},
+		"dash0_alerting_active_alerts": {
+			{Title: "Show firing alerts", Arguments: map[string]interface{}{"state": "firing"}},
+			{Title: "Show firing and pending alerts", Arguments: map[string]interface{}{}},
+		},
+	}
+}
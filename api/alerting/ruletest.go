@@ -0,0 +1,337 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/prometheus/prometheus/tsdb/chunks"
+	"github.com/prometheus/prometheus/util/annotations"
+)
+
+const (
+	// ruleTestMaxSamples bounds the samples a single rule test may load or evaluate.
+	ruleTestMaxSamples = 1_000_000
+	ruleTestTimeout    = 10 * time.Second
+)
+
+// fixtureSeries is a synthetic input series for a rule test.
+// Sample timestamps are milliseconds relative to the start of the test.
+type fixtureSeries struct {
+	labels  labels.Labels
+	samples []chunks.Sample
+}
+
+// fixtureSample is a float sample implementing chunks.Sample.
+type fixtureSample struct {
+	t int64
+	f float64
+}
+
+func (s fixtureSample) T() int64                      { return s.t }
+func (s fixtureSample) F() float64                    { return s.f }
+func (s fixtureSample) H() *histogram.Histogram       { return nil }
+func (s fixtureSample) FH() *histogram.FloatHistogram { return nil }
+func (s fixtureSample) Type() chunkenc.ValueType      { return chunkenc.ValFloat }
+func (s fixtureSample) Copy() chunks.Sample           { return s }
+
+// parseFixtureSeries builds a fixture from either a promtool-style values
+// string ("0+10x5 _ 100"), spaced by interval, or explicit [offset, value] pairs.
+func parseFixtureSeries(i int, raw interface{}, interval time.Duration) (fixtureSeries, error) {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return fixtureSeries{}, fmt.Errorf("series[%d] must be an object", i)
+	}
+
+	selector, _ := m["series"].(string)
+	selector = strings.TrimSpace(selector)
+	if selector == "" {
+		return fixtureSeries{}, fmt.Errorf("series[%d].series is required (e.g. 'http_requests_total{job=\"api\"}')", i)
+	}
+
+	values, hasValues := m["values"].(string)
+	samples, hasSamples := m["samples"].([]interface{})
+	if hasValues == hasSamples {
+		return fixtureSeries{}, fmt.Errorf("series[%d] requires exactly one of values or samples", i)
+	}
+
+	if hasValues {
+		lset, seq, err := parser.ParseSeriesDesc(selector + " " + values)
+		if err != nil {
+			return fixtureSeries{}, fmt.Errorf("series[%d]: %v", i, err)
+		}
+		fs := fixtureSeries{labels: lset}
+		for step, v := range seq {
+			if v.Omitted {
+				continue
+			}
+			if v.Histogram != nil {
+				return fixtureSeries{}, fmt.Errorf("series[%d]: native histogram values are not supported", i)
+			}
+			fs.samples = append(fs.samples, fixtureSample{t: int64(step) * interval.Milliseconds(), f: v.Value})
+		}
+		return fs, nil
+	}
+
+	lset, err := parser.ParseMetric(selector)
+	if err != nil {
+		return fixtureSeries{}, fmt.Errorf("series[%d]: %v", i, err)
+	}
+	fs := fixtureSeries{labels: lset}
+	for j, s := range samples {
+		pair, ok := s.([]interface{})
+		if !ok || len(pair) != 2 {
+			return fixtureSeries{}, fmt.Errorf("series[%d].samples[%d] must be an [offset, value] pair", i, j)
+		}
+		offset, err := parseOffset(pair[0])
+		if err != nil {
+			return fixtureSeries{}, fmt.Errorf("series[%d].samples[%d]: %v", i, j, err)
+		}
+		value, ok := pair[1].(float64)
+		if !ok {
+			return fixtureSeries{}, fmt.Errorf("series[%d].samples[%d]: value must be a number", i, j)
+		}
+		fs.samples = append(fs.samples, fixtureSample{t: offset.Milliseconds(), f: value})
+	}
+	sort.Slice(fs.samples, func(a, b int) bool { return fs.samples[a].T() < fs.samples[b].T() })
+	return fs, nil
+}
+
+// parseOffset accepts seconds as a number or a Prometheus duration string.
+func parseOffset(v interface{}) (time.Duration, error) {
+	switch o := v.(type) {
+	case float64:
+		if o < 0 {
+			return 0, fmt.Errorf("offset must not be negative")
+		}
+		return time.Duration(o * float64(time.Second)), nil
+	case string:
+		d, err := model.ParseDuration(o)
+		if err != nil {
+			return 0, fmt.Errorf("invalid offset %q: %v", o, err)
+		}
+		return time.Duration(d), nil
+	}
+	return 0, fmt.Errorf("offset must be seconds or a duration string")
+}
+
+// parseRuleDuration parses a Prometheus duration string, using def when empty.
+func parseRuleDuration(args map[string]interface{}, key string, def time.Duration) (time.Duration, error) {
+	s, _ := args[key].(string)
+	if strings.TrimSpace(s) == "" {
+		return def, nil
+	}
+	d, err := model.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", key, err)
+	}
+	return time.Duration(d), nil
+}
+
+// fixtureQueryable serves fixture series to the PromQL engine.
+type fixtureQueryable []fixtureSeries
+
+func (q fixtureQueryable) Querier(mint, maxt int64) (storage.Querier, error) {
+	return fixtureQuerier(q), nil
+}
+
+type fixtureQuerier []fixtureSeries
+
+func (q fixtureQuerier) Select(_ context.Context, sortSeries bool, _ *storage.SelectHints, matchers ...*labels.Matcher) storage.SeriesSet {
+	var matched []storage.Series
+	for _, fs := range q {
+		ok := true
+		for _, m := range matchers {
+			if !m.Matches(fs.labels.Get(m.Name)) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matched = append(matched, storage.NewListSeries(fs.labels, fs.samples))
+		}
+	}
+	if sortSeries {
+		sort.Slice(matched, func(a, b int) bool {
+			return labels.Compare(matched[a].Labels(), matched[b].Labels()) < 0
+		})
+	}
+	return &listSeriesSet{series: matched, i: -1}
+}
+
+func (q fixtureQuerier) LabelValues(_ context.Context, name string, _ *storage.LabelHints, _ ...*labels.Matcher) ([]string, annotations.Annotations, error) {
+	seen := map[string]bool{}
+	var values []string
+	for _, fs := range q {
+		if v := fs.labels.Get(name); v != "" && !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+	sort.Strings(values)
+	return values, nil, nil
+}
+
+func (q fixtureQuerier) LabelNames(_ context.Context, _ *storage.LabelHints, _ ...*labels.Matcher) ([]string, annotations.Annotations, error) {
+	seen := map[string]bool{}
+	var names []string
+	for _, fs := range q {
+		fs.labels.Range(func(l labels.Label) {
+			if !seen[l.Name] {
+				seen[l.Name] = true
+				names = append(names, l.Name)
+			}
+		})
+	}
+	sort.Strings(names)
+	return names, nil, nil
+}
+
+func (q fixtureQuerier) Close() error { return nil }
+
+// listSeriesSet iterates over an in-memory slice of series.
+type listSeriesSet struct {
+	series []storage.Series
+	i      int
+}
+
+func (s *listSeriesSet) Next() bool                        { s.i++; return s.i < len(s.series) }
+func (s *listSeriesSet) At() storage.Series                { return s.series[s.i] }
+func (s *listSeriesSet) Err() error                        { return nil }
+func (s *listSeriesSet) Warnings() annotations.Annotations { return nil }
+
+// RuleTestAlert is one alert instance produced by a rule test. Times are
+// offsets from the start of the fixture series.
+type RuleTestAlert struct {
+	Labels     string  `json:"labels"`
+	ActiveAt   string  `json:"active_at"`
+	FiringAt   string  `json:"firing_at,omitempty"`
+	ResolvedAt string  `json:"resolved_at,omitempty"`
+	Value      float64 `json:"value"`
+	Fired      bool    `json:"fired"`
+}
+
+// evaluateRule runs expression over [0, end] at each interval step and replays
+// the alerting state machine with the given for and keepFiringFor durations.
+func evaluateRule(ctx context.Context, expression string, series []fixtureSeries, end, interval, forDuration, keepFiringFor time.Duration) ([]RuleTestAlert, error) {
+	engine := promql.NewEngine(promql.EngineOpts{
+		MaxSamples: ruleTestMaxSamples,
+		Timeout:    ruleTestTimeout,
+	})
+
+	start := time.Unix(0, 0).UTC()
+	q, err := engine.NewRangeQuery(ctx, fixtureQueryable(series), nil, expression, start, start.Add(end), interval)
+	if err != nil {
+		return nil, err
+	}
+	defer q.Close()
+
+	res := q.Exec(ctx)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+
+	var matrix promql.Matrix
+	switch v := res.Value.(type) {
+	case promql.Matrix:
+		matrix = v
+	default:
+		return nil, fmt.Errorf("expression must return an instant vector, got %s", res.Value.Type())
+	}
+
+	results := make([]ruleSeries, 0, len(matrix))
+	for _, s := range matrix {
+		rs := ruleSeries{labels: s.Metric.DropMetricName().String(), values: make(map[int]float64, len(s.Floats))}
+		for _, p := range s.Floats {
+			rs.values[int(p.T/interval.Milliseconds())] = p.F
+		}
+		results = append(results, rs)
+	}
+
+	steps := int(end/interval) + 1
+	return replayAlerts(results, steps, interval, forDuration, keepFiringFor, formatOffset), nil
+}
+
+// ruleSeries is one result series of a rule expression: its labels and its
+// value at each evaluation step where it returned a result.
+type ruleSeries struct {
+	labels string
+	values map[int]float64
+}
+
+// replayAlerts replays the Prometheus alerting state machine (pending →
+// firing → resolved) over steps evaluation steps spaced by interval. at
+// renders the time of a step, given as its offset from the first step.
+func replayAlerts(series []ruleSeries, steps int, interval, forDuration, keepFiringFor time.Duration, at func(time.Duration) string) []RuleTestAlert {
+	var alerts []RuleTestAlert
+	for _, s := range series {
+		var current *RuleTestAlert
+		var activeSince, lastActive time.Duration
+		for step := 0; step < steps; step++ {
+			ts := time.Duration(step) * interval
+			value, isActive := s.values[step]
+
+			if isActive {
+				lastActive = ts
+				if current == nil {
+					activeSince = ts
+					current = &RuleTestAlert{Labels: s.labels, ActiveAt: at(ts), Value: value}
+				}
+				if !current.Fired && ts-activeSince >= forDuration {
+					current.Fired = true
+					current.FiringAt = at(ts)
+					current.Value = value
+				}
+				continue
+			}
+
+			if current == nil {
+				continue
+			}
+			if current.Fired && ts-lastActive < keepFiringFor {
+				continue
+			}
+			current.ResolvedAt = at(ts)
+			alerts = append(alerts, *current)
+			current = nil
+		}
+		if current != nil {
+			alerts = append(alerts, *current)
+		}
+	}
+
+	sort.SliceStable(alerts, func(a, b int) bool { return alerts[a].Labels < alerts[b].Labels })
+	return alerts
+}
+
+// formatOffset renders an offset from the test start, e.g. "+5m".
+func formatOffset(d time.Duration) string {
+	if d == 0 {
+		return "+0s"
+	}
+	return "+" + model.Duration(d).String()
+}
+
+// maxFixtureOffset returns the latest sample offset across all series.
+func maxFixtureOffset(series []fixtureSeries) time.Duration {
+	var maxT int64 = math.MinInt64
+	for _, fs := range series {
+		if n := len(fs.samples); n > 0 && fs.samples[n-1].T() > maxT {
+			maxT = fs.samples[n-1].T()
+		}
+	}
+	if maxT == math.MinInt64 {
+		return 0
+	}
+	return time.Duration(maxT) * time.Millisecond
+}
@@ -9,8 +9,12 @@ import (
 
 	"github.com/npcomplete777/dash0-mcp/internal/client"
 	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+	"github.com/npcomplete777/dash0-mcp/internal/listing"
 	"github.com/npcomplete777/dash0-mcp/internal/registry"
+	"github.com/npcomplete777/dash0-mcp/internal/schema"
 	mcp "github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
 )
 
 const (
@@ -40,18 +44,22 @@ func (p *Tools) Tools() []mcp.Tool {
 		p.UpdateCheckRule(),
 		p.DeleteCheckRule(),
 		p.ActiveAlerts(),
+		p.TestCheckRule(),
+		p.BacktestCheckRule(),
 	}
 }
 
 // Handlers returns a map of tool name to handler function.
 func (p *Tools) Handlers() map[string]func(context.Context, map[string]interface{}) *client.ToolResult {
 	return map[string]func(context.Context, map[string]interface{}) *client.ToolResult{
-		"dash0_alerting_check_rules_list":   p.ListCheckRulesHandler,
-		"dash0_alerting_check_rules_get":    p.GetCheckRuleHandler,
-		"dash0_alerting_check_rules_create": p.CreateCheckRuleHandler,
-		"dash0_alerting_check_rules_update": p.UpdateCheckRuleHandler,
-		"dash0_alerting_check_rules_delete": p.DeleteCheckRuleHandler,
-		"dash0_alerting_active_alerts":      p.ActiveAlertsHandler,
+		"dash0_alerting_check_rules_list":     p.ListCheckRulesHandler,
+		"dash0_alerting_check_rules_get":      p.GetCheckRuleHandler,
+		"dash0_alerting_check_rules_create":   p.CreateCheckRuleHandler,
+		"dash0_alerting_check_rules_update":   p.UpdateCheckRuleHandler,
+		"dash0_alerting_check_rules_delete":   p.DeleteCheckRuleHandler,
+		"dash0_alerting_active_alerts":        p.ActiveAlertsHandler,
+		"dash0_alerting_check_rules_test":     p.TestCheckRuleHandler,
+		"dash0_alerting_check_rules_backtest": p.BacktestCheckRuleHandler,
 	}
 }
 
@@ -61,24 +69,27 @@ func (p *Tools) ListCheckRules() mcp.Tool {
 		Name:        "dash0_alerting_check_rules_list",
 		Description: "List all check rules (Prometheus-style alert rules) configured in Dash0.",
 		InputSchema: mcp.ToolInputSchema{
-			Type:       "object",
-			Properties: map[string]interface{}{},
+			Type: "object",
+			Properties: map[string]interface{}{
+				"page_token": listing.PageTokenProperty(),
+				"summary":    listing.SummaryProperty(),
+			},
 		},
 	}
 }
 
 // ListCheckRulesHandler handles the dash0_alerting_check_rules_list tool.
 func (p *Tools) ListCheckRulesHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
-	result := p.client.Get(ctx, basePath)
+	result := p.client.Get(ctx, listing.Path(basePath, args))
 	if result.Success {
 		result.Markdown = formatCheckRulesList(result.Data)
 	}
-	return result
+	return listing.Summarize(listing.Adapt(result), args, "Check Rules")
 }
 
 // formatCheckRulesList formats check rules as a markdown table.
 func formatCheckRulesList(data interface{}) string {
-	items := extractItems(data)
+	items := listing.FromResponse(data).Items
 	if len(items) == 0 {
 		return "## Check Rules\n\nNo check rules found.\n"
 	}
@@ -123,24 +134,6 @@ func formatCheckRulesList(data interface{}) string {
 	return formatter.Table("Check Rules", summary, headers, rows, "")
 }
 
-// extractItems tries to get a slice of items from various response shapes.
-func extractItems(data interface{}) []interface{} {
-	if data == nil {
-		return nil
-	}
-	if arr, ok := data.([]interface{}); ok {
-		return arr
-	}
-	if m, ok := data.(map[string]interface{}); ok {
-		for _, key := range []string{"items", "data", "results", "rules"} {
-			if arr, ok := m[key].([]interface{}); ok {
-				return arr
-			}
-		}
-	}
-	return nil
-}
-
 func extractField(m map[string]interface{}, key string) string {
 	if v, ok := m[key]; ok && v != nil {
 		return fmt.Sprintf("%v", v)
@@ -273,8 +266,9 @@ func (p *Tools) CreateCheckRuleHandler(ctx context.Context, args map[string]inte
 	if !ok {
 		return client.ErrorResult(400, "body is required")
 	}
+	body, fixes := schema.Normalize(schema.KindCheckRule, body)
 
-	return p.client.Post(ctx, basePath, body)
+	return schema.Report(p.client.Post(ctx, basePath, body), fixes)
 }
 
 // UpdateCheckRule returns the dash0_alerting_check_rules_update tool definition.
@@ -347,9 +341,10 @@ func (p *Tools) UpdateCheckRuleHandler(ctx context.Context, args map[string]inte
 	if !ok {
 		return client.ErrorResult(400, "body is required")
 	}
+	body, fixes := schema.Normalize(schema.KindCheckRule, body)
 
 	path := fmt.Sprintf(basePath+"/%s", url.PathEscape(originOrID))
-	return p.client.Put(ctx, path, body)
+	return schema.Report(p.client.Put(ctx, path, body), fixes)
 }
 
 // DeleteCheckRule returns the dash0_alerting_check_rules_delete tool definition.
@@ -416,9 +411,233 @@ func (p *Tools) ActiveAlertsHandler(ctx context.Context, args map[string]interfa
 	return result
 }
 
+// TestCheckRule returns the dash0_alerting_check_rules_test tool definition.
+func (p *Tools) TestCheckRule() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_alerting_check_rules_test",
+		Description: `Unit-test a check rule expression against synthetic series, promtool-style.
+
+Evaluates the PromQL expression locally with an embedded Prometheus engine (no data is
+sent to Dash0) at every interval step, then applies the rule's "for" and "keepFiringFor"
+semantics to report when each alert became pending, fired, and resolved.
+Times are offsets from the start of the series.
+
+Each series is a metric selector plus either promtool expanding notation in "values"
+(spaced by interval; "0+10x5" = 0 10 20 30 40 50, "_" = missing sample) or explicit
+"samples" as [offset, value] pairs (offset in seconds or a duration like "5m").
+
+Example:
+{
+  "expression": "rate(http_requests_total{status=\"500\"}[5m]) > 0.1",
+  "for": "5m",
+  "series": [
+    {"series": "http_requests_total{status=\"500\",job=\"api\"}", "values": "0+1x10 10+60x20"}
+  ],
+  "expect_firing": true
+}`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"expression": map[string]interface{}{
+					"type":        "string",
+					"description": "PromQL alert expression to evaluate",
+				},
+				"series": map[string]interface{}{
+					"type":        "array",
+					"description": "Synthetic input series",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"series": map[string]interface{}{
+								"type":        "string",
+								"description": "Metric name and labels, e.g. 'http_requests_total{job=\"api\"}'",
+							},
+							"values": map[string]interface{}{
+								"type":        "string",
+								"description": "promtool expanding notation, one value per interval (e.g. '0+10x5 _ 100')",
+							},
+							"samples": map[string]interface{}{
+								"type":        "array",
+								"description": "Explicit [offset, value] pairs; offset is seconds or a duration string",
+							},
+						},
+						"required": []string{"series"},
+					},
+				},
+				"interval": map[string]interface{}{
+					"type":        "string",
+					"description": "Evaluation interval and spacing of 'values' samples (default: 1m)",
+				},
+				"for": map[string]interface{}{
+					"type":        "string",
+					"description": "Duration the condition must hold before firing (default: 0s)",
+				},
+				"keepFiringFor": map[string]interface{}{
+					"type":        "string",
+					"description": "How long to keep firing after the condition resolves (default: 0s)",
+				},
+				"eval_duration": map[string]interface{}{
+					"type":        "string",
+					"description": "How long to evaluate for (default: up to the last sample)",
+				},
+				"expect_firing": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Optional assertion: whether any alert is expected to fire. The result reports passed/failed.",
+				},
+			},
+			Required: []string{"expression", "series"},
+		},
+	}
+}
+
+// TestCheckRuleHandler handles the dash0_alerting_check_rules_test tool.
+func (p *Tools) TestCheckRuleHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	expression, _ := args["expression"].(string)
+	expression = strings.TrimSpace(expression)
+	if expression == "" {
+		return client.ErrorResult(400, "expression is required")
+	}
+	if _, err := parser.ParseExpr(expression); err != nil {
+		return client.ErrorResult(400, fmt.Sprintf("invalid expression: %v", err))
+	}
+
+	rawSeries, ok := args["series"].([]interface{})
+	if !ok || len(rawSeries) == 0 {
+		return client.ErrorResult(400, "series is required")
+	}
+
+	interval, err := parseRuleDuration(args, "interval", time.Minute)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+	if interval <= 0 {
+		return client.ErrorResult(400, "interval must be positive")
+	}
+	forDuration, err := parseRuleDuration(args, "for", 0)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+	keepFiringFor, err := parseRuleDuration(args, "keepFiringFor", 0)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+
+	series := make([]fixtureSeries, 0, len(rawSeries))
+	totalSamples := 0
+	for i, raw := range rawSeries {
+		fs, err := parseFixtureSeries(i, raw, interval)
+		if err != nil {
+			return client.ErrorResult(400, err.Error())
+		}
+		totalSamples += len(fs.samples)
+		series = append(series, fs)
+	}
+	if totalSamples > ruleTestMaxSamples {
+		return client.ErrorResult(400, fmt.Sprintf("series contain %d samples, max is %d", totalSamples, ruleTestMaxSamples))
+	}
+
+	end, err := parseRuleDuration(args, "eval_duration", maxFixtureOffset(series))
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+	if int64(end/interval) >= ruleTestMaxSamples {
+		return client.ErrorResult(400, "eval_duration / interval exceeds the maximum number of evaluation steps")
+	}
+
+	alerts, err := evaluateRule(ctx, expression, series, end, interval, forDuration, keepFiringFor)
+	if err != nil {
+		return client.ErrorResult(400, fmt.Sprintf("evaluation failed: %v", err))
+	}
+
+	fired := 0
+	for _, a := range alerts {
+		if a.Fired {
+			fired++
+		}
+	}
+
+	data := map[string]interface{}{
+		"expression": expression,
+		"alerts":     alerts,
+		"fired":      fired,
+		"pending":    len(alerts) - fired,
+		"steps":      int(end/interval) + 1,
+	}
+	var passed *bool
+	if expect, ok := args["expect_firing"].(bool); ok {
+		ok := (fired > 0) == expect
+		passed = &ok
+		data["expect_firing"] = expect
+		data["passed"] = ok
+	}
+
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: formatRuleTest(expression, alerts, fired, end, interval, forDuration, passed),
+		Data:     data,
+	}
+}
+
+// formatRuleTest renders rule test results as a markdown table.
+func formatRuleTest(expression string, alerts []RuleTestAlert, fired int, end, interval, forDuration time.Duration, passed *bool) string {
+	summaryParts := []string{
+		fmt.Sprintf("**%d alerts fired**, %d only pending", fired, len(alerts)-fired),
+		fmt.Sprintf("Evaluated `%s` over %s every %s (for: %s)",
+			expression, formatOffset(end), model.Duration(interval), model.Duration(forDuration)),
+	}
+	if passed != nil {
+		if *passed {
+			summaryParts = append(summaryParts, "**Assertion: PASSED**")
+		} else {
+			summaryParts = append(summaryParts, "**Assertion: FAILED**")
+		}
+	}
+	summary := strings.Join(summaryParts, " | ")
+
+	if len(alerts) == 0 {
+		return fmt.Sprintf("## Check Rule Test\n\n%s\n\nThe expression never returned a result, so no alerts were produced.\n", summary)
+	}
+
+	return formatter.Table("Check Rule Test", summary, ruleAlertHeaders, ruleAlertRows(alerts), "")
+}
+
+var ruleAlertHeaders = []string{"#", "Labels", "State", "Active At", "Firing At", "Resolved At", "Value"}
+
+// ruleAlertRows renders replayed alerts as rows under ruleAlertHeaders.
+func ruleAlertRows(alerts []RuleTestAlert) [][]string {
+	var rows [][]string
+	for i, a := range alerts {
+		state := "pending"
+		if a.Fired {
+			state = "firing"
+			if a.ResolvedAt != "" {
+				state = "resolved"
+			}
+		}
+		resolved := a.ResolvedAt
+		if resolved == "" {
+			resolved = "-"
+		}
+		firing := a.FiringAt
+		if firing == "" {
+			firing = "-"
+		}
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", i+1),
+			formatter.Truncate(a.Labels, 60),
+			state,
+			a.ActiveAt,
+			firing,
+			resolved,
+			fmt.Sprintf("%g", a.Value),
+		})
+	}
+	return rows
+}
+
 // formatActiveAlerts formats active alert instances as a markdown table.
 func formatActiveAlerts(data interface{}, stateFilter string) string {
-	items := extractItems(data)
+	items := listing.FromResponse(data).Items
 	if len(items) == 0 {
 		return "## Active Alerts\n\nNo active alerts found.\n"
 	}
@@ -530,5 +749,6 @@ func Register(reg *registry.Registry, c *client.Client) {
 	for _, tool := range p.Tools() {
 		handler := p.Handlers()[tool.Name]
 		reg.Register(tool, handler)
+		reg.AddExamples(tool.Name, p.Examples()[tool.Name]...)
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -27,17 +28,19 @@ func TestTools(t *testing.T) {
 	pkg := New(&client.Client{})
 	tools := pkg.Tools()
 
-	if len(tools) != 6 {
-		t.Errorf("Tools() returned %d tools, expected 6", len(tools))
+	if len(tools) != 8 {
+		t.Errorf("Tools() returned %d tools, expected 8", len(tools))
 	}
 
 	expectedNames := map[string]bool{
-		"dash0_alerting_check_rules_list":   false,
-		"dash0_alerting_check_rules_get":    false,
-		"dash0_alerting_check_rules_create": false,
-		"dash0_alerting_check_rules_update": false,
-		"dash0_alerting_check_rules_delete": false,
-		"dash0_alerting_active_alerts":      false,
+		"dash0_alerting_check_rules_list":     false,
+		"dash0_alerting_check_rules_get":      false,
+		"dash0_alerting_check_rules_create":   false,
+		"dash0_alerting_check_rules_update":   false,
+		"dash0_alerting_check_rules_delete":   false,
+		"dash0_alerting_active_alerts":        false,
+		"dash0_alerting_check_rules_test":     false,
+		"dash0_alerting_check_rules_backtest": false,
 	}
 
 	for _, tool := range tools {
@@ -65,6 +68,8 @@ func TestHandlers(t *testing.T) {
 		"dash0_alerting_check_rules_update",
 		"dash0_alerting_check_rules_delete",
 		"dash0_alerting_active_alerts",
+		"dash0_alerting_check_rules_test",
+		"dash0_alerting_check_rules_backtest",
 	}
 
 	if len(handlers) != len(expectedHandlers) {
@@ -274,6 +279,17 @@ func TestCreateCheckRuleHandler(t *testing.T) {
 			},
 			expectSuccess: true,
 		},
+		{
+			name: "prometheus rule fields are normalized",
+			args: map[string]interface{}{
+				"body": map[string]interface{}{
+					"alert": "HighErrorRate",
+					"expr":  "rate(http_errors_total[5m]) > 0.05",
+					"for":   "5m",
+				},
+			},
+			expectSuccess: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -307,6 +323,9 @@ func TestCreateCheckRuleHandler(t *testing.T) {
 			if tt.expectSuccess && !result.Success {
 				t.Errorf("Expected success, got failure: %v", result.Error)
 			}
+			if receivedBody["name"] != "HighErrorRate" || receivedBody["expression"] == nil {
+				t.Errorf("sent body = %v, want name and expression", receivedBody)
+			}
 		})
 	}
 }
@@ -649,6 +668,82 @@ func TestFormatActiveAlerts_Empty(t *testing.T) {
 	}
 }
 
+func TestBacktestCheckRuleHandler(t *testing.T) {
+	var query map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/prometheus/api/v1/query_range" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		query = r.URL.Query()
+		start, _ := strconv.ParseFloat(query["start"][0], 64)
+		// checkout breaches the threshold for 3 steps, cart for 1.
+		var checkout, cart []interface{}
+		for step := 2; step <= 4; step++ {
+			checkout = append(checkout, []interface{}{start + float64(step*60), "0.2"})
+		}
+		cart = append(cart, []interface{}{start + 600, "0.3"})
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "success",
+			"data": map[string]interface{}{
+				"resultType": "matrix",
+				"result": []interface{}{
+					map[string]interface{}{"metric": map[string]interface{}{"service_name": "checkout"}, "values": checkout},
+					map[string]interface{}{"metric": map[string]interface{}{"service_name": "cart"}, "values": cart},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.BacktestCheckRuleHandler(context.Background(), map[string]interface{}{
+		"expression":         "error_rate > 0.1",
+		"for":                "2m",
+		"time_range_minutes": float64(60),
+		"dataset":            "staging",
+	})
+	if !result.Success {
+		t.Fatalf("BacktestCheckRuleHandler failed: %v", result.Error)
+	}
+	if query["query"][0] != "error_rate > 0.1" || query["step"][0] != "60" || query["dataset"][0] != "staging" {
+		t.Errorf("query = %v", query)
+	}
+	start, _ := strconv.ParseInt(query["start"][0], 10, 64)
+	end, _ := strconv.ParseInt(query["end"][0], 10, 64)
+	if end-start != 3600 || end%60 != 0 {
+		t.Errorf("start/end = %d/%d, expected an hour aligned to the interval", start, end)
+	}
+
+	data := result.Data.(map[string]interface{})
+	alerts := data["alerts"].([]RuleTestAlert)
+	if len(alerts) != 2 || data["fired"] != 1 || data["steps"] != 61 {
+		t.Fatalf("data = %+v, expected checkout to fire and cart to stay pending", data)
+	}
+	// Sorted by labels: cart, then checkout.
+	if alerts[0].Fired || alerts[1].Labels != `{service_name="checkout"}` || !alerts[1].Fired {
+		t.Errorf("alerts = %+v", alerts)
+	}
+	firingAt := time.Unix(start+240, 0).UTC().Format(time.RFC3339)
+	resolvedAt := time.Unix(start+300, 0).UTC().Format(time.RFC3339)
+	if alerts[1].FiringAt != firingAt || alerts[1].ResolvedAt != resolvedAt {
+		t.Errorf("checkout = %+v, expected firing at %s and resolved at %s", alerts[1], firingAt, resolvedAt)
+	}
+	if !strings.Contains(result.Markdown, "1 alerts would have fired") {
+		t.Errorf("Markdown = %s", result.Markdown)
+	}
+
+	for _, args := range []map[string]interface{}{
+		{},
+		{"expression": "rate(x[5m]"},
+		{"expression": "up == 0", "interval": "10s", "time_range_minutes": float64(10080)},
+		{"expression": "up == 0", "interval": "500ms"},
+	} {
+		if bad := pkg.BacktestCheckRuleHandler(context.Background(), args); bad.Success || bad.Error.StatusCode != 400 {
+			t.Errorf("%v: result = %+v, expected a 400", args, bad)
+		}
+	}
+}
+
 func TestFormatAlertDuration(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -683,3 +778,167 @@ func TestToolDescriptionsNotEmpty(t *testing.T) {
 		}
 	}
 }
+
+func TestTestCheckRuleHandler(t *testing.T) {
+	pkg := New(&client.Client{})
+
+	tests := []struct {
+		name          string
+		args          map[string]interface{}
+		expectFired   int
+		expectPending int
+		expectPassed  interface{}
+		checkAlerts   func(t *testing.T, alerts []RuleTestAlert)
+	}{
+		{
+			name: "fires after for duration and resolves",
+			args: map[string]interface{}{
+				"expression": "errors > 5",
+				"for":        "2m",
+				"series": []interface{}{
+					map[string]interface{}{"series": `errors{job="api"}`, "values": "0 10 10 10 10 0 0"},
+				},
+				"expect_firing": true,
+			},
+			expectFired:  1,
+			expectPassed: true,
+			checkAlerts: func(t *testing.T, alerts []RuleTestAlert) {
+				a := alerts[0]
+				if a.Labels != `{job="api"}` {
+					t.Errorf("Labels = %s", a.Labels)
+				}
+				if a.ActiveAt != "+1m" || a.FiringAt != "+3m" || a.ResolvedAt != "+5m" {
+					t.Errorf("unexpected timeline: active=%s firing=%s resolved=%s", a.ActiveAt, a.FiringAt, a.ResolvedAt)
+				}
+			},
+		},
+		{
+			name: "condition too short for for duration",
+			args: map[string]interface{}{
+				"expression": "errors > 5",
+				"for":        "5m",
+				"series": []interface{}{
+					map[string]interface{}{"series": "errors", "values": "0 10 10 0"},
+				},
+				"expect_firing": true,
+			},
+			expectPending: 1,
+			expectPassed:  false,
+		},
+		{
+			name: "rate over explicit samples per series",
+			args: map[string]interface{}{
+				"expression": "rate(requests_total[2m]) > 1",
+				"interval":   "30s",
+				"series": []interface{}{
+					map[string]interface{}{
+						"series":  `requests_total{pod="a"}`,
+						"samples": []interface{}{[]interface{}{float64(0), float64(0)}, []interface{}{"1m", float64(300)}, []interface{}{"2m", float64(600)}},
+					},
+					map[string]interface{}{
+						"series":  `requests_total{pod="b"}`,
+						"samples": []interface{}{[]interface{}{float64(0), float64(0)}, []interface{}{"1m", float64(6)}, []interface{}{"2m", float64(12)}},
+					},
+				},
+			},
+			expectFired: 1,
+			checkAlerts: func(t *testing.T, alerts []RuleTestAlert) {
+				if alerts[0].Labels != `{pod="a"}` {
+					t.Errorf("expected only pod a to fire, got %s", alerts[0].Labels)
+				}
+			},
+		},
+		{
+			name: "keepFiringFor holds alert across a gap",
+			args: map[string]interface{}{
+				"expression":    "up == 0",
+				"keepFiringFor": "2m",
+				"series": []interface{}{
+					map[string]interface{}{"series": "up", "values": "1 0 1 0 1 1 1"},
+				},
+			},
+			expectFired: 1,
+			checkAlerts: func(t *testing.T, alerts []RuleTestAlert) {
+				if alerts[0].ActiveAt != "+1m" || alerts[0].ResolvedAt != "+5m" {
+					t.Errorf("unexpected timeline: %+v", alerts[0])
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := pkg.TestCheckRuleHandler(context.Background(), tt.args)
+			if !result.Success {
+				t.Fatalf("TestCheckRuleHandler failed: %v", result.Error)
+			}
+
+			data := result.Data.(map[string]interface{})
+			if data["fired"] != tt.expectFired {
+				t.Errorf("fired = %v, expected %d", data["fired"], tt.expectFired)
+			}
+			if data["pending"] != tt.expectPending {
+				t.Errorf("pending = %v, expected %d", data["pending"], tt.expectPending)
+			}
+			if tt.expectPassed != nil && data["passed"] != tt.expectPassed {
+				t.Errorf("passed = %v, expected %v", data["passed"], tt.expectPassed)
+			}
+			if tt.checkAlerts != nil {
+				tt.checkAlerts(t, data["alerts"].([]RuleTestAlert))
+			}
+			if result.Markdown == "" {
+				t.Error("expected markdown output")
+			}
+		})
+	}
+}
+
+func TestTestCheckRuleHandler_Validation(t *testing.T) {
+	pkg := New(&client.Client{})
+	validSeries := []interface{}{map[string]interface{}{"series": "up", "values": "1 1"}}
+
+	tests := []struct {
+		name        string
+		args        map[string]interface{}
+		expectError string
+	}{
+		{"missing expression", map[string]interface{}{"series": validSeries}, "expression is required"},
+		{"invalid expression", map[string]interface{}{"expression": "up >", "series": validSeries}, "invalid expression"},
+		{"missing series", map[string]interface{}{"expression": "up"}, "series is required"},
+		{"invalid interval", map[string]interface{}{"expression": "up", "series": validSeries, "interval": "soon"}, "interval"},
+		{
+			"values and samples",
+			map[string]interface{}{"expression": "up", "series": []interface{}{
+				map[string]interface{}{"series": "up", "values": "1", "samples": []interface{}{}},
+			}},
+			"exactly one of values or samples",
+		},
+		{
+			"bad values notation",
+			map[string]interface{}{"expression": "up", "series": []interface{}{
+				map[string]interface{}{"series": "up", "values": "1 x 2"},
+			}},
+			"series[0]",
+		},
+		{
+			"bad sample pair",
+			map[string]interface{}{"expression": "up", "series": []interface{}{
+				map[string]interface{}{"series": "up", "samples": []interface{}{[]interface{}{float64(0)}}},
+			}},
+			"[offset, value] pair",
+		},
+		{"range vector result", map[string]interface{}{"expression": "up[5m]", "series": validSeries}, "evaluation failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := pkg.TestCheckRuleHandler(context.Background(), tt.args)
+			if result.Success {
+				t.Fatal("Expected error, got success")
+			}
+			if !strings.Contains(result.Error.Detail, tt.expectError) {
+				t.Errorf("Error = %q, expected to contain %q", result.Error.Detail, tt.expectError)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/otlp"
+)
+
+// Baselines a window can be compared against.
+const (
+	baselinePreviousPeriod = "previous_period"
+	baselineYesterday      = "same_time_yesterday"
+	baselineLastWeek       = "same_time_last_week"
+)
+
+// baselineKinds are the values of the baseline argument.
+var baselineKinds = []string{baselinePreviousPeriod, baselineLastWeek}
+
+// baselineSchema is the JSON schema of the baseline argument.
+var baselineSchema = map[string]interface{}{
+	"type":        "string",
+	"description": "Also compute each window over a comparison period and report the change: previous_period (the window just before) or same_time_last_week",
+	"enum":        baselineKinds,
+}
+
+// parseBaseline returns the baseline argument, or "" if none was given.
+func parseBaseline(args map[string]interface{}) (string, *client.ToolResult) {
+	kind, _ := args["baseline"].(string)
+	kind = strings.TrimSpace(kind)
+	if kind == "" || kind == baselinePreviousPeriod || kind == baselineLastWeek {
+		return kind, nil
+	}
+	return "", client.ErrorResult(400, fmt.Sprintf("baseline must be %s, got %q", strings.Join(baselineKinds, " or "), kind))
+}
+
+// baselineOffset returns how far before a window of the given length its
+// baseline starts.
+func baselineOffset(kind string, window time.Duration) time.Duration {
+	switch kind {
+	case baselinePreviousPeriod:
+		return window
+	case baselineYesterday:
+		return 24 * time.Hour
+	}
+	return 7 * 24 * time.Hour
+}
+
+// baselineLabel describes a baseline in prose.
+func baselineLabel(kind string) string {
+	switch kind {
+	case baselinePreviousPeriod:
+		return "the previous period"
+	case baselineYesterday:
+		return "the same time yesterday"
+	}
+	return "the same time last week"
+}
+
+// Delta is the change of signals from a baseline. Percentages are nil when
+// the baseline value is zero.
+type Delta struct {
+	RequestsPct *float64 `json:"requests_pct,omitempty"`
+	RatePct     *float64 `json:"rate_pct,omitempty"`
+	// ErrorRatePoints is the change of the error rate in percentage points.
+	ErrorRatePoints float64  `json:"error_rate_points"`
+	P50Pct          *float64 `json:"p50_pct,omitempty"`
+	P95Pct          *float64 `json:"p95_pct,omitempty"`
+	P99Pct          *float64 `json:"p99_pct,omitempty"`
+}
+
+// BaselineComparison holds the signals of a comparison window and the
+// change from them.
+type BaselineComparison struct {
+	Kind    string  `json:"kind"`
+	From    string  `json:"from"`
+	To      string  `json:"to"`
+	Signals Signals `json:"signals"`
+	Delta   Delta   `json:"delta"`
+}
+
+// newBaseline compares current with the signals of the baseline window.
+func newBaseline(kind string, from, to time.Time, current, base Signals) *BaselineComparison {
+	return &BaselineComparison{
+		Kind:    kind,
+		From:    from.Format(time.RFC3339),
+		To:      to.Format(time.RFC3339),
+		Signals: base,
+		Delta: Delta{
+			RequestsPct:     pctChange(float64(current.Requests), float64(base.Requests)),
+			RatePct:         pctChange(current.RatePerSecond, base.RatePerSecond),
+			ErrorRatePoints: current.ErrorRate - base.ErrorRate,
+			P50Pct:          pctChange(current.P50Ms, base.P50Ms),
+			P95Pct:          pctChange(current.P95Ms, base.P95Ms),
+			P99Pct:          pctChange(current.P99Ms, base.P99Ms),
+		},
+	}
+}
+
+// pctChange returns the relative change from base to current in percent, or
+// nil if base is zero.
+func pctChange(current, base float64) *float64 {
+	if base == 0 {
+		return nil
+	}
+	pct := (current - base) / base * 100
+	return &pct
+}
+
+// formatPct renders a relative change, or "-" when there is none.
+func formatPct(pct *float64) string {
+	if pct == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%+.0f%%", *pct)
+}
+
+// formatPoints renders a change of a percentage in percentage points.
+func formatPoints(points float64) string {
+	return fmt.Sprintf("%+.1fpp", points)
+}
+
+// spanSignals fetches the spans matching filters between from and to and
+// computes their signals. The returned flag is false when the spans had no
+// SERVER/CONSUMER spans and all spans were used.
+func spanSignals(ctx context.Context, c *client.Client, dataset string, filters []otlp.AttributeFilter, from, to time.Time, maxSpans int, includeAll bool) (Signals, bool, *client.ToolResult) {
+	sample, errResult := fetchSpans(ctx, c, dataset, filters, from, to, maxSpans)
+	if errResult != nil {
+		return Signals{}, false, errResult
+	}
+	ss, entry := sample.Spans, false
+	if !includeAll {
+		ss, entry = entrySpans(sample.Spans)
+	}
+	return computeSignals(ss, to.Sub(from), sample.Sampled), entry, nil
+}
@@ -0,0 +1,11 @@
+// Package analysis provides MCP tools that aggregate Dash0 telemetry into
+// higher-level service views. Tools in this package page through span queries
+// and summarize them locally (latency percentiles, error rates, throughput),
+// so a single call answers questions that would otherwise need several queries.
+// The service health score also reads the service's active alerts, and the
+// error summary its error logs.
+// dash0_compare_windows runs one span or log query over two time ranges and
+// tests whether the later one is worse.
+// dash0_wait_for polls one of these signals until a condition holds, reporting
+// progress as it goes, so a deploy script can wait for a rollout to settle.
+package analysis
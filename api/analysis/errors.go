@@ -0,0 +1,293 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/api/logs"
+	"github.com/npcomplete777/dash0-mcp/api/spans"
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/followup"
+	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+	"github.com/npcomplete777/dash0-mcp/internal/otlp"
+)
+
+const (
+	defaultErrorsMinutes = 60
+	defaultMaxErrorLogs  = 1000
+	maxMaxErrorLogs      = 5000
+
+	// errorGroupTraces is how many trace IDs are kept per group.
+	errorGroupTraces = 3
+	// maxErrorGroups is how many groups are listed per dimension.
+	maxErrorGroups = 10
+
+	// unsetValue groups the errors without a value for a dimension.
+	unsetValue = "(unset)"
+)
+
+// ErrorGroup counts the failed spans and error logs that share one value of
+// a dimension.
+type ErrorGroup struct {
+	Value string `json:"value"`
+	Spans int    `json:"spans"`
+	Logs  int    `json:"logs"`
+	// TraceIDs are representative traces, in the order they were seen.
+	TraceIDs []string `json:"trace_ids"`
+	// Message is the first exception, status, or log message of the group.
+	Message string `json:"message,omitempty"`
+	// SpanName is true for a route that is a span name, because the spans
+	// had no http.route.
+	SpanName bool `json:"span_name,omitempty"`
+}
+
+// ErrorSummary groups the errors of a service by exception type, route, and
+// status code.
+type ErrorSummary struct {
+	ErrorSpans      int          `json:"error_spans"`
+	ErrorLogs       int          `json:"error_logs"`
+	SpansSampled    bool         `json:"spans_sampled"`
+	LogsSampled     bool         `json:"logs_sampled"`
+	ByExceptionType []ErrorGroup `json:"by_exception_type"`
+	ByRoute         []ErrorGroup `json:"by_route"`
+	ByStatusCode    []ErrorGroup `json:"by_status_code"`
+	// Groups is the number of distinct values per dimension, before the
+	// lists were cut to the largest groups.
+	Groups map[string]int `json:"groups"`
+}
+
+// errorGroups accumulates the groups of one dimension.
+type errorGroups map[string]*ErrorGroup
+
+// add counts one error under value.
+func (g errorGroups) add(value string, isLog bool, traceID, message string) {
+	if value == "" {
+		value = unsetValue
+	}
+	group, ok := g[value]
+	if !ok {
+		group = &ErrorGroup{Value: value, TraceIDs: []string{}}
+		g[value] = group
+	}
+	if isLog {
+		group.Logs++
+	} else {
+		group.Spans++
+	}
+	if traceID != "" && len(group.TraceIDs) < errorGroupTraces && !containsString(group.TraceIDs, traceID) {
+		group.TraceIDs = append(group.TraceIDs, traceID)
+	}
+	if group.Message == "" {
+		group.Message = message
+	}
+}
+
+// largest returns the groups with the most errors first, at most
+// maxErrorGroups of them. On a tie, the unset group comes last.
+func (g errorGroups) largest() []ErrorGroup {
+	out := make([]ErrorGroup, 0, len(g))
+	for _, group := range g {
+		out = append(out, *group)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if a, b := out[i].Spans+out[i].Logs, out[j].Spans+out[j].Logs; a != b {
+			return a > b
+		}
+		if (out[i].Value == unsetValue) != (out[j].Value == unsetValue) {
+			return out[j].Value == unsetValue
+		}
+		return out[i].Value < out[j].Value
+	})
+	if len(out) > maxErrorGroups {
+		out = out[:maxErrorGroups]
+	}
+	return out
+}
+
+// summarizeErrors groups failed spans and error logs by exception type
+// (error.type when unset), route (the span name when http.route is unset),
+// and HTTP status code.
+func summarizeErrors(failed []spans.FlatSpan, errorLogs []logs.FlatLog) ErrorSummary {
+	byType, byRoute, byStatus := errorGroups{}, errorGroups{}, errorGroups{}
+	for _, s := range failed {
+		message := attrString(s.Attributes, "exception.message")
+		if message == "" {
+			message = s.StatusMessage
+		}
+		exceptionType := attrString(s.Attributes, "exception.type")
+		if exceptionType == "" {
+			exceptionType = attrString(s.Attributes, "error.type")
+		}
+		byType.add(exceptionType, false, s.TraceID, message)
+		if route := attrString(s.Attributes, "http.route"); route != "" || s.Name == "" {
+			byRoute.add(route, false, s.TraceID, message)
+		} else {
+			byRoute.add(s.Name, false, s.TraceID, message)
+			byRoute[s.Name].SpanName = true
+		}
+		byStatus.add(attrString(s.Attributes, "http.response.status_code"), false, s.TraceID, message)
+	}
+	for _, l := range errorLogs {
+		message := attrString(l.Attributes, "exception.message")
+		if message == "" {
+			message = formatter.Truncate(l.Body, 200)
+		}
+		byType.add(attrString(l.Attributes, "exception.type"), true, l.TraceID, message)
+		byRoute.add(attrString(l.Attributes, "http.route"), true, l.TraceID, message)
+		byStatus.add(attrString(l.Attributes, "http.response.status_code"), true, l.TraceID, message)
+	}
+
+	return ErrorSummary{
+		ErrorSpans:      len(failed),
+		ErrorLogs:       len(errorLogs),
+		ByExceptionType: byType.largest(),
+		ByRoute:         byRoute.largest(),
+		ByStatusCode:    byStatus.largest(),
+		Groups: map[string]int{
+			"exception_type": len(byType),
+			"route":          len(byRoute),
+			"status_code":    len(byStatus),
+		},
+	}
+}
+
+// attrString returns an attribute as a string, or "".
+func attrString(attrs map[string]interface{}, key string) string {
+	switch v := attrs[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// errorSpanFilter matches spans with an ERROR status.
+func errorSpanFilter() otlp.AttributeFilter {
+	errorCode := "2" // OTLP error status code
+	return otlp.AttributeFilter{
+		Key:      "status.code",
+		Operator: "is",
+		Value:    &otlp.AttributeFilterValue{IntValue: &errorCode},
+	}
+}
+
+// fetchErrorLogs reads up to maxLogs logs of the service and keeps those of
+// ERROR severity or above.
+func fetchErrorLogs(ctx context.Context, c *client.Client, dataset, serviceName string, from, to time.Time, maxLogs int) ([]logs.FlatLog, bool, *client.ToolResult) {
+	req := logs.QueryLogsRequest{
+		Dataset: dataset,
+		TimeRange: otlp.TimeRange{
+			From: from.Format(time.RFC3339),
+			To:   to.Format(time.RFC3339),
+		},
+		Filter: []otlp.AttributeFilter{serviceFilter(serviceName)},
+	}
+	all, sampled, errResult := logs.Fetch(ctx, c, req, dataset, maxLogs)
+	if errResult != nil {
+		return nil, false, errResult
+	}
+	var errorLogs []logs.FlatLog
+	for _, l := range all {
+		if level := logs.SeverityLevel(l); level == "ERROR" || level == "FATAL" {
+			errorLogs = append(errorLogs, l)
+		}
+	}
+	return errorLogs, sampled, nil
+}
+
+// errorFollowUps suggests the failing spans of the route with the most
+// errors and the service's error logs.
+func errorFollowUps(serviceName string, s ErrorSummary, minutes int) []followup.Suggestion {
+	suggestions := []followup.Suggestion{}
+	for _, g := range s.ByRoute {
+		if g.Spans == 0 || g.Value == unsetValue {
+			continue
+		}
+		args := map[string]interface{}{
+			"service_name":       serviceName,
+			"error_only":         true,
+			"time_range_minutes": minutes,
+		}
+		if g.SpanName {
+			args["span_name"] = g.Value
+		} else {
+			args["attribute_filters"] = []interface{}{map[string]interface{}{"key": "http.route", "value": g.Value}}
+		}
+		suggestions = append(suggestions, followup.New("dash0_spans_query", args,
+			fmt.Sprintf("%s has the most failed spans (%d); inspect them.", g.Value, g.Spans)))
+		break
+	}
+	if s.ErrorLogs > 0 {
+		suggestions = append(suggestions, followup.New("dash0_logs_query", map[string]interface{}{
+			"service_name":       serviceName,
+			"min_severity":       "ERROR",
+			"time_range_minutes": minutes,
+		}, fmt.Sprintf("%d error logs in the window; read them.", s.ErrorLogs)))
+	}
+	return suggestions
+}
+
+// formatErrorSummary renders one table per dimension.
+func formatErrorSummary(serviceName string, s ErrorSummary, minutes, maxSpans, maxLogs int) string {
+	var b strings.Builder
+	b.WriteString("## Error Summary\n\n")
+	fmt.Fprintf(&b, "Service **%s** · Last %d minutes · **%d failed spans**, **%d error logs**\n\n", serviceName, minutes, s.ErrorSpans, s.ErrorLogs)
+	if s.ErrorSpans == 0 && s.ErrorLogs == 0 {
+		b.WriteString("No failed spans or error logs found.\n")
+		return b.String()
+	}
+
+	for _, dim := range []struct {
+		title, key string
+		groups     []ErrorGroup
+	}{
+		{"By exception type", "exception_type", s.ByExceptionType},
+		{"By route", "route", s.ByRoute},
+		{"By status code", "status_code", s.ByStatusCode},
+	} {
+		rows := make([][]string, len(dim.groups))
+		for i, g := range dim.groups {
+			rows[i] = []string{
+				formatter.Truncate(g.Value, 60),
+				fmt.Sprintf("%d", g.Spans),
+				fmt.Sprintf("%d", g.Logs),
+				strings.Join(g.TraceIDs, ", "),
+				formatter.Truncate(g.Message, 80),
+			}
+		}
+		footer := ""
+		if n := s.Groups[dim.key]; n > len(dim.groups) {
+			footer = fmt.Sprintf("Largest %d of %d groups.", len(dim.groups), n)
+		}
+		b.WriteString("### " + dim.title + "\n\n")
+		b.WriteString(formatter.Table("", "", []string{"Value", "Spans", "Logs", "Traces", "Message"}, rows, footer))
+		b.WriteString("\n")
+	}
+
+	var notes []string
+	if s.SpansSampled {
+		notes = append(notes, fmt.Sprintf("More than %d failed spans; counts are from the first %d.", maxSpans, maxSpans))
+	}
+	if s.LogsSampled {
+		notes = append(notes, fmt.Sprintf("Error logs are from the first %d logs of the service.", maxLogs))
+	}
+	if len(notes) > 0 {
+		b.WriteString("> " + strings.Join(notes, " ") + "\n")
+	}
+	return b.String()
+}
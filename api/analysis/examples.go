@@ -0,0 +1,58 @@
+package analysis
+
+import "github.com/npcomplete777/dash0-mcp/internal/registry"
+
+// Compile-time interface check.
+var _ registry.ExampleProvider = (*Tools)(nil)
+
+// Examples returns example invocations for the analysis tools.
+func (p *Tools) Examples() map[string][]registry.Example {
+	return map[string][]registry.Example{
+		"dash0_golden_signals": {
+			{Title: "Golden signals of a service", Arguments: map[string]interface{}{"service_name": "checkout"}},
+			{Title: "Golden signals over every span of a service", Arguments: map[string]interface{}{"service_name": "checkout", "include_all_spans": true}},
+			{Title: "Golden signals against the same time last week", Arguments: map[string]interface{}{"service_name": "checkout", "baseline": "same_time_last_week"}},
+		},
+		"dash0_canary_analyze": {
+			{
+				Title: "Compare a canary deployment with the stable one",
+				Arguments: map[string]interface{}{
+					"service_name": "checkout",
+					"baseline":     []interface{}{map[string]interface{}{"key": "deployment.variant", "value": "stable"}},
+					"canary":       []interface{}{map[string]interface{}{"key": "deployment.variant", "value": "canary"}},
+				},
+			},
+			{
+				Title: "Compare blue and green by service version over the last 2 hours",
+				Arguments: map[string]interface{}{
+					"service_name":       "checkout",
+					"baseline":           []interface{}{map[string]interface{}{"key": "service.version", "value": "1.4.2"}},
+					"canary":             []interface{}{map[string]interface{}{"key": "service.version", "value": "1.5.0"}},
+					"time_range_minutes": 120,
+				},
+			},
+		},
+		"dash0_services_compare": {
+			{Title: "Which checkout service is misbehaving", Arguments: map[string]interface{}{"services": []interface{}{"frontend", "checkout", "cart", "payment", "shipping"}}},
+			{Title: "Slowest services over the last 15 minutes", Arguments: map[string]interface{}{"services": []interface{}{"frontend", "checkout", "cart"}, "time_range_minutes": 15, "sort_by": "p95"}},
+			{Title: "Services against the previous hour", Arguments: map[string]interface{}{"services": []interface{}{"checkout", "cart"}, "baseline": "previous_period"}},
+		},
+		"dash0_errors_summarize": {
+			{Title: "What is breaking in the checkout service", Arguments: map[string]interface{}{"service_name": "checkout"}},
+			{Title: "Errors of the last 15 minutes, reading more logs", Arguments: map[string]interface{}{"service_name": "checkout", "time_range_minutes": 15, "max_logs": 5000}},
+		},
+		"dash0_wait_for": {
+			{Title: "Wait until the checkout error rate drops below 1%", Arguments: map[string]interface{}{"condition": "error_rate_below", "service_name": "checkout", "threshold": 1}},
+			{Title: "Wait for version 1.5.0 to report spans", Arguments: map[string]interface{}{"condition": "version_visible", "service_name": "checkout", "version": "1.5.0", "wait_seconds": 600}},
+		},
+		"dash0_compare_windows": {
+			{Title: "Is checkout worse than the same hour yesterday", Arguments: map[string]interface{}{"service_name": "checkout"}},
+			{Title: "The 30 minutes since a deploy against the 30 minutes before", Arguments: map[string]interface{}{"service_name": "checkout", "window_minutes": 30, "baseline": "previous_period"}},
+			{Title: "Error logs of a namespace against last week", Arguments: map[string]interface{}{"signal": "logs", "k8s_namespace": "shop", "baseline": "same_time_last_week"}},
+		},
+		"dash0_service_health": {
+			{Title: "Is the checkout service healthy", Arguments: map[string]interface{}{"service_name": "checkout"}},
+			{Title: "Health over the last hour against the hour before", Arguments: map[string]interface{}{"service_name": "checkout", "time_range_minutes": 60, "baseline": "previous_period"}},
+		},
+	}
+}
@@ -0,0 +1,120 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/npcomplete777/dash0-mcp/internal/followup"
+)
+
+const (
+	// followUpMinutes is the look-back of suggested drill-down queries.
+	followUpMinutes = 15
+	// latencyRegressionFactor is how much the recent P95 must exceed the 24h
+	// P95 before a slow-span drill-down is suggested.
+	latencyRegressionFactor = 1.5
+)
+
+// goldenSignalFollowUps suggests drill-downs based on the most recent window
+// compared with the longest one.
+func goldenSignalFollowUps(serviceName string, windows []WindowSignals) []followup.Suggestion {
+	suggestions := []followup.Suggestion{}
+	if len(windows) == 0 {
+		return suggestions
+	}
+	recent, longest := windows[0], windows[len(windows)-1]
+
+	if recent.Requests == 0 && longest.Requests > 0 {
+		return append(suggestions, followup.New("dash0_alerting_active_alerts", nil,
+			fmt.Sprintf("No requests in the last %s after %d in the last %s; check for firing alerts.",
+				recent.Window, longest.Requests, longest.Window)))
+	}
+
+	if recent.Errors > 0 {
+		reason := fmt.Sprintf("%.1f%% of requests failed in the last %s.", recent.ErrorRate, recent.Window)
+		suggestions = append(suggestions,
+			followup.New("dash0_spans_query", map[string]interface{}{
+				"service_name":       serviceName,
+				"error_only":         true,
+				"time_range_minutes": followUpMinutes,
+			}, reason+" Inspect the failing spans."),
+			followup.New("dash0_logs_query", map[string]interface{}{
+				"service_name":       serviceName,
+				"min_severity":       "ERROR",
+				"time_range_minutes": followUpMinutes,
+			}, reason+" Look for matching error logs."),
+		)
+	}
+
+	if longest.P95Ms > 0 && recent.P95Ms > longest.P95Ms*latencyRegressionFactor {
+		suggestions = append(suggestions, followup.New("dash0_spans_query", map[string]interface{}{
+			"service_name":       serviceName,
+			"min_duration_ms":    math.Round(longest.P95Ms),
+			"time_range_minutes": followUpMinutes,
+		}, fmt.Sprintf("P95 in the last %s is %.1fx the %s P95; find the slow requests.",
+			recent.Window, recent.P95Ms/longest.P95Ms, longest.Window)))
+	}
+	return suggestions
+}
+
+// canaryFollowUps suggests the next step for a canary recommendation: drill
+// into the canary's errors or slow spans on hold, or widen the window when the
+// result is inconclusive. selector is the canary argument as given.
+func canaryFollowUps(r CanaryResult, selector []interface{}, serviceName string, args map[string]interface{}, minutes int) []followup.Suggestion {
+	suggestions := []followup.Suggestion{}
+	spansArgs := func(extra map[string]interface{}) map[string]interface{} {
+		a := map[string]interface{}{
+			"attribute_filters":  selector,
+			"time_range_minutes": minutes,
+		}
+		if serviceName != "" {
+			a["service_name"] = serviceName
+		}
+		for k, v := range extra {
+			a[k] = v
+		}
+		return a
+	}
+
+	switch r.Recommendation {
+	case "hold":
+		if r.ErrorTest.PValue < r.Significance {
+			suggestions = append(suggestions, followup.New("dash0_spans_query",
+				spansArgs(map[string]interface{}{"error_only": true}),
+				"The canary's error rate is significantly higher; inspect its failing spans."))
+		}
+		if r.Baseline.P95Ms > 0 && r.P95IncreasePct > 0 && r.LatencyTest.PValue < r.Significance {
+			suggestions = append(suggestions, followup.New("dash0_spans_query",
+				spansArgs(map[string]interface{}{"min_duration_ms": math.Round(r.Baseline.P95Ms)}),
+				"The canary is significantly slower; find its requests above the baseline P95."))
+		}
+	case "inconclusive":
+		if minutes < 1440 {
+			retry := make(map[string]interface{}, len(args)+1)
+			for k, v := range args {
+				retry[k] = v
+			}
+			retry["time_range_minutes"] = min(minutes*4, 1440)
+			suggestions = append(suggestions, followup.New("dash0_canary_analyze", retry,
+				"Too few requests for a decision; compare over a longer window."))
+		}
+	}
+	return suggestions
+}
+
+// compareFollowUps suggests a golden signals drill-down for each flagged
+// service that had traffic.
+func compareFollowUps(results []ServiceSignals, minutes int) []followup.Suggestion {
+	suggestions := []followup.Suggestion{}
+	for _, r := range results {
+		if r.Requests == 0 || len(r.Flags) == 0 {
+			continue
+		}
+		suggestions = append(suggestions, followup.New("dash0_golden_signals",
+			map[string]interface{}{"service_name": r.Service},
+			fmt.Sprintf("%s has the %s of the compared services over the last %d minutes; see how it trends.",
+				r.Service, strings.Join(r.Flags, " and the "), minutes)))
+	}
+	return suggestions
+}
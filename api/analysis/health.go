@@ -0,0 +1,309 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/followup"
+	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+)
+
+const (
+	alertsPath = "/api/alerting/alerts"
+
+	defaultHealthMinutes = 15
+
+	// Scores at or above these are healthy or degraded; below is unhealthy.
+	healthyScore  = 80
+	degradedScore = 50
+)
+
+// Health factor statuses.
+const (
+	factorOK       = "ok"
+	factorWarning  = "warning"
+	factorCritical = "critical"
+	factorUnknown  = "unknown"
+)
+
+// serviceLabels are the alert labels that name a service.
+var serviceLabels = []string{"service.name", "service_name", "service"}
+
+// HealthFactor is one input of a service health score and the points it
+// took off.
+type HealthFactor struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Penalty int    `json:"penalty"`
+	Value   string `json:"value"`
+	Detail  string `json:"detail"`
+}
+
+// HealthAlert is an active alert that concerns the service.
+type HealthAlert struct {
+	Name     string `json:"name"`
+	State    string `json:"state"`
+	Severity string `json:"severity,omitempty"`
+	Since    string `json:"since,omitempty"`
+}
+
+// ServiceHealth is the result of dash0_service_health.
+type ServiceHealth struct {
+	Service  string
+	Score    int
+	Status   string
+	Factors  []HealthFactor
+	Signals  Signals
+	Baseline *BaselineComparison
+	Alerts   []HealthAlert
+}
+
+// scoreHealth sums the penalties of the factors into a 0-100 score and its
+// status, and orders the factors by penalty, largest first.
+func scoreHealth(h *ServiceHealth) {
+	sort.SliceStable(h.Factors, func(i, j int) bool { return h.Factors[i].Penalty > h.Factors[j].Penalty })
+	h.Score = 100
+	for _, f := range h.Factors {
+		h.Score -= f.Penalty
+	}
+	if h.Score < 0 {
+		h.Score = 0
+	}
+	switch {
+	case h.Score >= healthyScore:
+		h.Status = "healthy"
+	case h.Score >= degradedScore:
+		h.Status = "degraded"
+	default:
+		h.Status = "unhealthy"
+	}
+}
+
+// trafficFactor flags a service whose traffic stopped.
+func trafficFactor(current Signals, base *BaselineComparison) HealthFactor {
+	f := HealthFactor{Name: "traffic", Status: factorOK, Value: fmt.Sprintf("%.2f/s", current.RatePerSecond)}
+	switch {
+	case current.Requests > 0:
+		f.Detail = fmt.Sprintf("%d requests", current.Requests)
+	case base != nil && base.Signals.Requests > 0:
+		f.Status, f.Penalty = factorCritical, 40
+		f.Detail = fmt.Sprintf("No requests, after %d in %s", base.Signals.Requests, baselineLabel(base.Kind))
+	default:
+		f.Status = factorUnknown
+		f.Detail = "No requests in the window or the baseline"
+	}
+	return f
+}
+
+// errorRateFactor rates the share of failed requests: 1% is a warning and
+// 5% critical.
+func errorRateFactor(current Signals) HealthFactor {
+	f := HealthFactor{Name: "error_rate", Status: factorOK}
+	if current.Requests == 0 {
+		f.Status, f.Value, f.Detail = factorUnknown, "-", "No requests to rate"
+		return f
+	}
+	f.Value = fmt.Sprintf("%.1f%%", current.ErrorRate)
+	f.Detail = fmt.Sprintf("%d of %d requests failed", current.Errors, current.Requests)
+	switch {
+	case current.ErrorRate >= 5:
+		f.Status, f.Penalty = factorCritical, 40
+	case current.ErrorRate >= 1:
+		f.Status, f.Penalty = factorWarning, 20
+	}
+	return f
+}
+
+// latencyFactor rates the P95 against the baseline: 50% slower is a warning
+// and twice as slow critical.
+func latencyFactor(current Signals, base *BaselineComparison) HealthFactor {
+	f := HealthFactor{Name: "latency", Status: factorOK, Value: "-"}
+	if current.Requests == 0 {
+		f.Status, f.Detail = factorUnknown, "No requests to rate"
+		return f
+	}
+	f.Value = formatter.FormatDuration(current.P95Ms)
+	if base == nil {
+		f.Status, f.Detail = factorUnknown, fmt.Sprintf("P95 %s; no baseline to compare with", f.Value)
+		return f
+	}
+	if base.Delta.P95Pct == nil {
+		f.Status, f.Detail = factorUnknown, fmt.Sprintf("P95 %s; no traffic in %s to compare with", f.Value, baselineLabel(base.Kind))
+		return f
+	}
+	change := *base.Delta.P95Pct
+	f.Detail = fmt.Sprintf("P95 %s vs %s in %s (%s)", f.Value, formatter.FormatDuration(base.Signals.P95Ms), baselineLabel(base.Kind), formatPct(base.Delta.P95Pct))
+	switch {
+	case change >= 100:
+		f.Status, f.Penalty = factorCritical, 30
+	case change >= 50:
+		f.Status, f.Penalty = factorWarning, 15
+	}
+	return f
+}
+
+// alertsFactor rates the service's active alerts: a firing critical alert
+// costs the most, a pending one the least.
+func alertsFactor(alerts []HealthAlert, errResult *client.ToolResult) HealthFactor {
+	f := HealthFactor{Name: "alerts", Status: factorOK}
+	if errResult != nil {
+		f.Status, f.Value, f.Detail = factorUnknown, "-", "Could not read active alerts: "+errorDetail(errResult)
+		return f
+	}
+	var firing, pending []string
+	critical := false
+	for _, a := range alerts {
+		switch a.State {
+		case "firing":
+			firing = append(firing, a.Name)
+			critical = critical || strings.EqualFold(a.Severity, "critical")
+		case "pending":
+			pending = append(pending, a.Name)
+		}
+	}
+	f.Value = fmt.Sprintf("%d firing, %d pending", len(firing), len(pending))
+	switch {
+	case critical:
+		f.Status, f.Penalty = factorCritical, 40
+		f.Detail = "Critical alert firing: " + strings.Join(firing, ", ")
+	case len(firing) > 0:
+		f.Status, f.Penalty = factorCritical, 25
+		f.Detail = "Firing: " + strings.Join(firing, ", ")
+	case len(pending) > 0:
+		f.Status, f.Penalty = factorWarning, 5
+		f.Detail = "Pending: " + strings.Join(pending, ", ")
+	default:
+		f.Detail = "No active alerts for the service"
+	}
+	return f
+}
+
+// errorDetail returns the message of a failed result.
+func errorDetail(r *client.ToolResult) string {
+	if r.Error == nil {
+		return "request failed"
+	}
+	return r.Error.Detail
+}
+
+// fetchServiceAlerts returns the pending and firing alerts labelled with the
+// service.
+func fetchServiceAlerts(ctx context.Context, c *client.Client, serviceName string) ([]HealthAlert, *client.ToolResult) {
+	result := c.Get(ctx, alertsPath)
+	if !result.Success {
+		return nil, result
+	}
+	alerts := []HealthAlert{}
+	for _, item := range listItems(result.Data, "alerts") {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		labels, _ := m["labels"].(map[string]interface{})
+		if !labelsName(labels, serviceName) {
+			continue
+		}
+		state := stringField(m, "state")
+		if state != "firing" && state != "pending" {
+			continue
+		}
+		name := stringField(m, "name")
+		if name == "" {
+			name = stringField(labels, "alertname")
+		}
+		severity := stringField(labels, "severity")
+		if severity == "" {
+			severity = stringField(m, "severity")
+		}
+		since := stringField(m, "activeAt")
+		if since == "" {
+			since = stringField(m, "startsAt")
+		}
+		alerts = append(alerts, HealthAlert{Name: name, State: state, Severity: severity, Since: since})
+	}
+	return alerts, nil
+}
+
+// listItems returns the items of a list response: the array itself, or the
+// array under items, data, results, or one of extra.
+func listItems(data interface{}, extra ...string) []interface{} {
+	if arr, ok := data.([]interface{}); ok {
+		return arr
+	}
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	for _, key := range append([]string{"items", "data", "results"}, extra...) {
+		if arr, ok := m[key].([]interface{}); ok {
+			return arr
+		}
+	}
+	return nil
+}
+
+// labelsName reports whether one of the service labels equals serviceName.
+func labelsName(labels map[string]interface{}, serviceName string) bool {
+	for _, key := range serviceLabels {
+		if stringField(labels, key) == serviceName {
+			return true
+		}
+	}
+	return false
+}
+
+// stringField returns a string value of m, or "".
+func stringField(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+// healthFollowUps suggests a drill-down for each factor that took points off.
+func healthFollowUps(h ServiceHealth, minutes int) []followup.Suggestion {
+	suggestions := []followup.Suggestion{}
+	for _, f := range h.Factors {
+		if f.Penalty == 0 {
+			continue
+		}
+		reason := fmt.Sprintf("%s is %s (%s).", strings.ReplaceAll(f.Name, "_", " "), f.Status, f.Detail)
+		switch f.Name {
+		case "traffic":
+			suggestions = append(suggestions, followup.New("dash0_golden_signals",
+				map[string]interface{}{"service_name": h.Service}, reason+" See when traffic stopped."))
+		case "error_rate":
+			suggestions = append(suggestions, followup.New("dash0_spans_query", map[string]interface{}{
+				"service_name":       h.Service,
+				"error_only":         true,
+				"time_range_minutes": minutes,
+			}, reason+" Inspect the failing spans."))
+		case "latency":
+			args := map[string]interface{}{"service_name": h.Service, "time_range_minutes": minutes}
+			if h.Baseline != nil && h.Baseline.Signals.P95Ms > 0 {
+				args["min_duration_ms"] = math.Round(h.Baseline.Signals.P95Ms)
+			}
+			suggestions = append(suggestions, followup.New("dash0_spans_query", args, reason+" Find the slow requests."))
+		case "alerts":
+			suggestions = append(suggestions, followup.New("dash0_alerting_active_alerts", nil, reason+" Review the alerts."))
+		}
+	}
+	return suggestions
+}
+
+// formatServiceHealth renders the score and one row per factor.
+func formatServiceHealth(h ServiceHealth, minutes int) string {
+	rows := make([][]string, len(h.Factors))
+	for i, f := range h.Factors {
+		penalty := "-"
+		if f.Penalty > 0 {
+			penalty = fmt.Sprintf("-%d", f.Penalty)
+		}
+		rows[i] = []string{strings.ReplaceAll(f.Name, "_", " "), f.Status, penalty, f.Value, f.Detail}
+	}
+	summary := fmt.Sprintf("Service **%s** · **%d/100 (%s)** · Last %d minutes", h.Service, h.Score, h.Status, minutes)
+	notes := fmt.Sprintf("Scores of %d and above are healthy, %d and above degraded. Unknown factors take no points off.", healthyScore, degradedScore)
+	return formatter.Table("Service Health", summary, []string{"Factor", "Status", "Penalty", "Value", "Detail"}, rows, notes)
+}
@@ -0,0 +1,168 @@
+package analysis
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/api/spans"
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/otlp"
+)
+
+// spanSample is the result of paging a spans query up to a span budget.
+type spanSample struct {
+	Spans []spans.FlatSpan
+	// Sampled is true when more spans matched than were fetched.
+	Sampled bool
+}
+
+// fetchSpans pages through a spans query until maxSpans spans are collected
+// or no further pages are available.
+func fetchSpans(ctx context.Context, c *client.Client, dataset string, filters []otlp.AttributeFilter, from, to time.Time, maxSpans int) (spanSample, *client.ToolResult) {
+	req := spans.QuerySpansRequest{
+		Dataset: dataset,
+		TimeRange: otlp.TimeRange{
+			From: from.Format(time.RFC3339),
+			To:   to.Format(time.RFC3339),
+		},
+		Filter: filters,
+	}
+	ss, sampled, errResult := spans.Fetch(ctx, c, req, dataset, maxSpans)
+	return spanSample{Spans: ss, Sampled: sampled}, errResult
+}
+
+// serviceFilter returns an exact-match service.name filter.
+func serviceFilter(serviceName string) otlp.AttributeFilter {
+	return otlp.AttributeFilter{
+		Key:      "service.name",
+		Operator: "is",
+		Value:    &otlp.AttributeFilterValue{StringValue: &serviceName},
+	}
+}
+
+// entrySpans keeps SERVER and CONSUMER spans, the spans that represent work a
+// service performs for its callers. If there are none, all spans are returned
+// so that services without entry spans still produce signals.
+func entrySpans(all []spans.FlatSpan) ([]spans.FlatSpan, bool) {
+	var entry []spans.FlatSpan
+	for _, s := range all {
+		if s.SpanKind == "SERVER" || s.SpanKind == "CONSUMER" {
+			entry = append(entry, s)
+		}
+	}
+	if len(entry) == 0 {
+		return all, false
+	}
+	return entry, true
+}
+
+// Signals summarizes the golden signals of a span sample.
+type Signals struct {
+	Requests int `json:"requests"`
+	Errors   int `json:"errors"`
+	// ErrorRate is the percentage of requests with an error status.
+	ErrorRate float64 `json:"error_rate"`
+	// RatePerSecond is the request throughput. When the sample was capped it is
+	// estimated from the time span the sample covers.
+	RatePerSecond float64 `json:"rate_per_second"`
+	P50Ms         float64 `json:"p50_ms"`
+	P95Ms         float64 `json:"p95_ms"`
+	P99Ms         float64 `json:"p99_ms"`
+	MeanMs        float64 `json:"mean_ms"`
+	// InFlight is the average number of concurrent requests (rate × mean
+	// latency, by Little's law), used as the saturation signal.
+	InFlight float64 `json:"in_flight"`
+	// Sampled is true when more spans matched than were read: requests and
+	// errors then count only the sample, and the other signals are
+	// estimated from it.
+	Sampled bool `json:"sampled"`
+}
+
+// computeSignals derives golden signals from spans observed over window.
+// sampled indicates the spans are a capped subset of the window.
+func computeSignals(ss []spans.FlatSpan, window time.Duration, sampled bool) Signals {
+	sig := Signals{Requests: len(ss), Sampled: sampled}
+	if len(ss) == 0 {
+		return sig
+	}
+
+	durations := make([]float64, 0, len(ss))
+	var total float64
+	for _, s := range ss {
+		durations = append(durations, s.DurationMs)
+		total += s.DurationMs
+		if s.StatusCode == 2 {
+			sig.Errors++
+		}
+	}
+	sort.Float64s(durations)
+
+	sig.ErrorRate = float64(sig.Errors) / float64(len(ss)) * 100
+	sig.MeanMs = total / float64(len(ss))
+	sig.P50Ms = percentile(durations, 0.50)
+	sig.P95Ms = percentile(durations, 0.95)
+	sig.P99Ms = percentile(durations, 0.99)
+
+	covered := window
+	if sampled {
+		if span := sampleSpan(ss); span > 0 && span < window {
+			covered = span
+		}
+	}
+	if covered > 0 {
+		sig.RatePerSecond = float64(len(ss)) / covered.Seconds()
+	}
+	sig.InFlight = sig.RatePerSecond * sig.MeanMs / 1000
+	return sig
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	idx := int(math.Ceil(p*float64(n))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= n {
+		idx = n - 1
+	}
+	return sorted[idx]
+}
+
+// sampleSpan returns the time between the earliest and latest span start.
+func sampleSpan(ss []spans.FlatSpan) time.Duration {
+	var first, last time.Time
+	for _, s := range ss {
+		t, err := time.Parse(time.RFC3339Nano, s.StartTime)
+		if err != nil {
+			continue
+		}
+		if first.IsZero() || t.Before(first) {
+			first = t
+		}
+		if t.After(last) {
+			last = t
+		}
+	}
+	return last.Sub(first)
+}
+
+// resolveDataset returns the per-call dataset argument or the client default.
+func resolveDataset(c *client.Client, args map[string]interface{}) string {
+	if ds := datasetArg(args); ds != "" {
+		return ds
+	}
+	return c.GetDataset()
+}
+
+// datasetArg returns the trimmed dataset argument, or "" if none was given.
+func datasetArg(args map[string]interface{}) string {
+	ds, _ := args["dataset"].(string)
+	return strings.TrimSpace(ds)
+}
@@ -0,0 +1,93 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+)
+
+// normalSF returns the upper-tail probability P(Z > z) of the standard normal.
+func normalSF(z float64) float64 {
+	return 0.5 * math.Erfc(z/math.Sqrt2)
+}
+
+// TestResult is the outcome of a one-sided hypothesis test that the canary is
+// worse than the baseline.
+type TestResult struct {
+	Test string `json:"test"`
+	// Statistic is the z score; positive values mean the canary is worse.
+	Statistic float64 `json:"statistic"`
+	PValue    float64 `json:"p_value"`
+}
+
+// twoProportionZTest tests whether the canary error proportion (e2/n2) is
+// greater than the baseline proportion (e1/n1) using a pooled z-test.
+func twoProportionZTest(e1, n1, e2, n2 int) TestResult {
+	r := TestResult{Test: "two-proportion z-test", PValue: 1}
+	if n1 == 0 || n2 == 0 {
+		return r
+	}
+	p1 := float64(e1) / float64(n1)
+	p2 := float64(e2) / float64(n2)
+	pooled := float64(e1+e2) / float64(n1+n2)
+	se := math.Sqrt(pooled * (1 - pooled) * (1/float64(n1) + 1/float64(n2)))
+	if se == 0 {
+		// Both samples are all errors or all successes: no evidence either way.
+		return r
+	}
+	r.Statistic = (p2 - p1) / se
+	r.PValue = normalSF(r.Statistic)
+	return r
+}
+
+// mannWhitneyU tests whether canary latencies tend to be larger than baseline
+// latencies, using the normal approximation with tie correction.
+func mannWhitneyU(baseline, canary []float64) TestResult {
+	r := TestResult{Test: "Mann-Whitney U", PValue: 1}
+	n1, n2 := len(baseline), len(canary)
+	if n1 == 0 || n2 == 0 {
+		return r
+	}
+
+	type obs struct {
+		v      float64
+		canary bool
+	}
+	all := make([]obs, 0, n1+n2)
+	for _, v := range baseline {
+		all = append(all, obs{v, false})
+	}
+	for _, v := range canary {
+		all = append(all, obs{v, true})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].v < all[j].v })
+
+	// Assign average ranks to ties and accumulate the tie correction term.
+	var rankSumCanary, tieTerm float64
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+		rank := float64(i+j+1) / 2 // ranks are 1-based: (i+1 + j) / 2
+		for k := i; k < j; k++ {
+			if all[k].canary {
+				rankSumCanary += rank
+			}
+		}
+		t := float64(j - i)
+		tieTerm += t*t*t - t
+		i = j
+	}
+
+	fn1, fn2 := float64(n1), float64(n2)
+	n := fn1 + fn2
+	u := rankSumCanary - fn2*(fn2+1)/2
+	mean := fn1 * fn2 / 2
+	variance := fn1 * fn2 / 12 * ((n + 1) - tieTerm/(n*(n-1)))
+	if n < 2 || variance <= 0 {
+		return r
+	}
+	r.Statistic = (u - mean) / math.Sqrt(variance)
+	r.PValue = normalSF(r.Statistic)
+	return r
+}
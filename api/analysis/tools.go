@@ -0,0 +1,1689 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/api/logs"
+	"github.com/npcomplete777/dash0-mcp/api/spans"
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/followup"
+	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+	"github.com/npcomplete777/dash0-mcp/internal/otlp"
+	"github.com/npcomplete777/dash0-mcp/internal/progress"
+	"github.com/npcomplete777/dash0-mcp/internal/registry"
+	mcp "github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	defaultMaxSpansPerWindow = 1000
+	maxMaxSpansPerWindow     = 5000
+
+	defaultCanaryMinutes         = 60
+	defaultSignificance          = 0.05
+	defaultMinRequests           = 30
+	defaultMaxLatencyIncreasePct = 10.0
+
+	defaultCompareMinutes = 60
+	maxCompareServices    = 20
+)
+
+// goldenSignalWindows are the look-back windows of dash0_golden_signals.
+var goldenSignalWindows = []struct {
+	Label    string
+	Duration time.Duration
+}{
+	{"5m", 5 * time.Minute},
+	{"1h", time.Hour},
+	{"24h", 24 * time.Hour},
+}
+
+// Compile-time interface check.
+var _ registry.ToolProvider = (*Tools)(nil)
+
+// Tools provides MCP tools for telemetry analysis.
+type Tools struct {
+	client *client.Client
+}
+
+// New creates a new Analysis tools instance.
+func New(c *client.Client) *Tools {
+	return &Tools{client: c}
+}
+
+// Tools returns all MCP tools in this package.
+func (p *Tools) Tools() []mcp.Tool {
+	return []mcp.Tool{
+		p.GoldenSignals(),
+		p.CanaryAnalyze(),
+		p.ServicesCompare(),
+		p.ServiceHealth(),
+		p.ErrorsSummarize(),
+		p.WaitFor(),
+		p.CompareWindows(),
+	}
+}
+
+// Handlers returns a map of tool name to handler function.
+func (p *Tools) Handlers() map[string]func(context.Context, map[string]interface{}) *client.ToolResult {
+	return map[string]func(context.Context, map[string]interface{}) *client.ToolResult{
+		"dash0_golden_signals":   p.GoldenSignalsHandler,
+		"dash0_canary_analyze":   p.CanaryAnalyzeHandler,
+		"dash0_services_compare": p.ServicesCompareHandler,
+		"dash0_service_health":   p.ServiceHealthHandler,
+		"dash0_errors_summarize": p.ErrorsSummarizeHandler,
+		"dash0_wait_for":         p.WaitForHandler,
+		"dash0_compare_windows":  p.CompareWindowsHandler,
+	}
+}
+
+// GoldenSignals returns the dash0_golden_signals tool definition.
+func (p *Tools) GoldenSignals() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_golden_signals",
+		Description: `Snapshot a service's golden signals over the last 5 minutes, 1 hour, and 24 hours in one table.
+
+For each window, reports:
+- Latency: P50/P95/P99 span duration
+- Traffic: request count and requests per second
+- Errors: error count and error rate (spans with ERROR status)
+- Saturation: average in-flight requests (throughput × mean latency)
+
+Signals are computed from the service's SERVER and CONSUMER spans (all spans if it has none),
+not from span metrics. Each window reads at most max_spans_per_window spans; when a window
+has more, the numbers are estimates: the window's sampled flag is true and its row is marked
+with "~", requests and errors count only the spans read, latency and error rate come from
+them, and rates are estimated from the time span they cover. Raise max_spans_per_window
+for busy services.
+
+The result includes suggested_follow_ups: ready-to-run tool calls for recent errors, a
+latency regression against the 24h window, or traffic that stopped.
+
+Pass baseline to also compute every window over the previous period or the same time last
+week and add the change in rate, error rate (percentage points), and P95.
+
+The canonical first look at a service. Example: {"service_name": "checkout"}
+Against last week: {"service_name": "checkout", "baseline": "same_time_last_week"}`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"service_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Service to analyze (exact match on service.name)",
+				},
+				"include_all_spans": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Use every span of the service instead of only SERVER/CONSUMER spans. Default: false",
+				},
+				"max_spans_per_window": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum spans to read per window (default: 1000, max: 5000)",
+				},
+				"baseline": baselineSchema,
+				"dataset": map[string]interface{}{
+					"type":        "string",
+					"description": "Dash0 dataset to query (e.g., 'astronomy-demo'). If omitted, uses the globally configured dataset or 'default'.",
+				},
+			},
+			Required: []string{"service_name"},
+		},
+	}
+}
+
+// WindowSignals are the golden signals for one look-back window.
+type WindowSignals struct {
+	Window string `json:"window"`
+	Signals
+	// EntrySpans is false when the service had no SERVER/CONSUMER spans and all
+	// spans were used instead.
+	EntrySpans bool `json:"entry_spans"`
+	// Baseline is set when the call asked for a baseline comparison.
+	Baseline *BaselineComparison `json:"baseline,omitempty"`
+}
+
+// GoldenSignalsHandler handles the dash0_golden_signals tool.
+func (p *Tools) GoldenSignalsHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	serviceName, _ := args["service_name"].(string)
+	serviceName = strings.TrimSpace(serviceName)
+	if serviceName == "" {
+		return client.ErrorResult(400, "service_name is required")
+	}
+
+	maxSpans := defaultMaxSpansPerWindow
+	if v, ok := args["max_spans_per_window"].(float64); ok {
+		if v < 0 {
+			return client.ErrorResult(400, "max_spans_per_window must not be negative")
+		}
+		if v > 0 {
+			maxSpans = int(v)
+			if maxSpans > maxMaxSpansPerWindow {
+				maxSpans = maxMaxSpansPerWindow
+			}
+		}
+	}
+	includeAll, _ := args["include_all_spans"].(bool)
+	baseline, errResult := parseBaseline(args)
+	if errResult != nil {
+		return errResult
+	}
+	dataset := resolveDataset(p.client, args)
+	filters := []otlp.AttributeFilter{serviceFilter(serviceName)}
+
+	// Baseline windows, if requested, are fetched alongside the current
+	// ones: task i < n is current window i, task n+i its baseline.
+	n := len(goldenSignalWindows)
+	tasks := n
+	if baseline != "" {
+		tasks *= 2
+	}
+	now := time.Now().UTC()
+	windows := make([]WindowSignals, n)
+	baselines := make([]Signals, n)
+	errResult = p.client.Parallel(ctx, tasks, func(ctx context.Context, i int) *client.ToolResult {
+		w := goldenSignalWindows[i%n]
+		to := now
+		if i >= n {
+			to = now.Add(-baselineOffset(baseline, w.Duration))
+		}
+		sig, entry, errResult := spanSignals(ctx, p.client, dataset, filters, to.Add(-w.Duration), to, maxSpans, includeAll)
+		if errResult != nil {
+			return errResult
+		}
+		if i >= n {
+			baselines[i-n] = sig
+			return nil
+		}
+		windows[i] = WindowSignals{
+			Window:     w.Label,
+			Signals:    sig,
+			EntrySpans: entry,
+		}
+		return nil
+	})
+	if errResult != nil {
+		return errResult
+	}
+	if baseline != "" {
+		for i, w := range goldenSignalWindows {
+			to := now.Add(-baselineOffset(baseline, w.Duration))
+			windows[i].Baseline = newBaseline(baseline, to.Add(-w.Duration), to, windows[i].Signals, baselines[i])
+		}
+	}
+
+	followUps := followup.WithDataset(goldenSignalFollowUps(serviceName, windows), datasetArg(args))
+	data := map[string]interface{}{
+		"service_name": serviceName,
+		"windows":      windows,
+		"evaluated_at": now.Format(time.RFC3339),
+		followup.Key:   followUps,
+	}
+	if baseline != "" {
+		data["baseline"] = baseline
+	}
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: formatGoldenSignals(serviceName, windows, maxSpans, baseline) + followup.Markdown(followUps),
+		Data:     data,
+	}
+}
+
+// formatGoldenSignals renders one row per window, with the change from the
+// baseline when one was requested.
+func formatGoldenSignals(serviceName string, windows []WindowSignals, maxSpans int, baseline string) string {
+	headers := []string{"Window", "Requests", "Rate", "Errors", "Error %", "P50", "P95", "P99", "In-flight"}
+	if baseline != "" {
+		headers = append(headers, "Rate Δ", "Error Δ", "P95 Δ")
+	}
+	rows := make([][]string, 0, len(windows))
+	var estimated, allSpans bool
+	for _, w := range windows {
+		marker := ""
+		if w.Sampled {
+			marker = "~"
+			estimated = true
+		}
+		if w.Requests > 0 && !w.EntrySpans {
+			allSpans = true
+		}
+		var row []string
+		if w.Requests == 0 {
+			row = []string{w.Window, "0", "-", "-", "-", "-", "-", "-", "-"}
+		} else {
+			row = []string{
+				w.Window,
+				fmt.Sprintf("%s%d", marker, w.Requests),
+				fmt.Sprintf("%s%.2f/s", marker, w.RatePerSecond),
+				fmt.Sprintf("%d", w.Errors),
+				fmt.Sprintf("%.1f%%", w.ErrorRate),
+				formatter.FormatDuration(w.P50Ms),
+				formatter.FormatDuration(w.P95Ms),
+				formatter.FormatDuration(w.P99Ms),
+				fmt.Sprintf("%s%.2f", marker, w.InFlight),
+			}
+		}
+		if b := w.Baseline; b != nil {
+			row = append(row, formatPct(b.Delta.RatePct), formatPoints(b.Delta.ErrorRatePoints), formatPct(b.Delta.P95Pct))
+		}
+		rows = append(rows, row)
+	}
+
+	summary := fmt.Sprintf("Service **%s** — latency, traffic, errors, and saturation (average in-flight requests).", serviceName)
+	if baseline != "" {
+		summary += fmt.Sprintf(" Δ columns compare each window with %s.", baselineLabel(baseline))
+	}
+	var notes []string
+	if estimated {
+		notes = append(notes, fmt.Sprintf("~ Window has more than %d spans; requests are a sample and rates are estimated from it.", maxSpans))
+	}
+	if allSpans {
+		notes = append(notes, "No SERVER/CONSUMER spans found; signals use all of the service's spans.")
+	}
+	return formatter.Table("Golden Signals", summary, headers, rows, strings.Join(notes, " "))
+}
+
+// CanaryAnalyze returns the dash0_canary_analyze tool definition.
+func (p *Tools) CanaryAnalyze() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_canary_analyze",
+		Description: `Compare a canary (or green) deployment against its baseline and recommend promote or hold.
+
+Both sides are selected with attribute filters over the same time window, typically on a
+deployment variant label. Signals come from SERVER and CONSUMER spans (all spans if none).
+
+Statistical tests (one-sided: is the canary worse?):
+- Error rate: two-proportion z-test
+- Latency: Mann-Whitney U test on span durations
+
+Recommendation:
+- hold: the error rate is significantly higher, or latency is significantly higher and the
+  P95 increase exceeds max_latency_increase_percent
+- inconclusive: either side has fewer than min_requests requests
+- promote: otherwise
+
+On hold or inconclusive, suggested_follow_ups lists the next tool call to make.
+
+Example: {"service_name": "checkout",
+  "baseline": [{"key": "deployment.variant", "value": "stable"}],
+  "canary": [{"key": "deployment.variant", "value": "canary"}]}`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"baseline": selectorSchema("Attribute filters selecting the baseline (e.g., the stable or blue deployment)"),
+				"canary":   selectorSchema("Attribute filters selecting the canary (e.g., the canary or green deployment)"),
+				"service_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Restrict both sides to this service (exact match on service.name)",
+				},
+				"time_range_minutes": map[string]interface{}{
+					"type":        "integer",
+					"description": "Minutes back to compare (default: 60, max: 1440)",
+				},
+				"significance": map[string]interface{}{
+					"type":        "number",
+					"description": "P-value threshold for a significant regression (default: 0.05)",
+				},
+				"min_requests": map[string]interface{}{
+					"type":        "integer",
+					"description": "Minimum requests per side for a promote/hold decision (default: 30)",
+				},
+				"max_latency_increase_percent": map[string]interface{}{
+					"type":        "number",
+					"description": "Tolerated P95 latency increase before a significant slowdown blocks promotion (default: 10)",
+				},
+				"include_all_spans": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Use every matching span instead of only SERVER/CONSUMER spans. Default: false",
+				},
+				"max_spans": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum spans to read per side (default: 1000, max: 5000)",
+				},
+				"dataset": map[string]interface{}{
+					"type":        "string",
+					"description": "Dash0 dataset to query (e.g., 'astronomy-demo'). If omitted, uses the globally configured dataset or 'default'.",
+				},
+			},
+			Required: []string{"baseline", "canary"},
+		},
+	}
+}
+
+// selectorSchema describes an attribute filter list argument.
+func selectorSchema(description string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "array",
+		"description": description,
+		"items": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"key": map[string]interface{}{
+					"type":        "string",
+					"description": "Attribute key (e.g., deployment.variant)",
+				},
+				"operator": map[string]interface{}{
+					"type":        "string",
+					"description": "Comparison operator (default: is)",
+					"enum":        otlp.FilterOperators,
+				},
+				"value": map[string]interface{}{
+					"description": "Value to compare against",
+				},
+			},
+			"required": []string{"key", "value"},
+		},
+	}
+}
+
+// CanaryResult is the outcome of dash0_canary_analyze.
+type CanaryResult struct {
+	Recommendation string     `json:"recommendation"`
+	Reasons        []string   `json:"reasons"`
+	Baseline       Signals    `json:"baseline"`
+	Canary         Signals    `json:"canary"`
+	ErrorTest      TestResult `json:"error_rate_test"`
+	LatencyTest    TestResult `json:"latency_test"`
+	// P95IncreasePct is the relative change of canary P95 over baseline P95.
+	P95IncreasePct float64 `json:"p95_increase_percent"`
+	Significance   float64 `json:"significance"`
+}
+
+// CanaryAnalyzeHandler handles the dash0_canary_analyze tool.
+func (p *Tools) CanaryAnalyzeHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	var selectors [2][]otlp.AttributeFilter
+	var descs [2][]string
+	for i, name := range []string{"baseline", "canary"} {
+		raw, ok := args[name].([]interface{})
+		if !ok || len(raw) == 0 {
+			return client.ErrorResult(400, fmt.Sprintf("%s is required: a non-empty array of {key, operator, value} filters", name))
+		}
+		filters, d, err := otlp.ParseAttributeFilters(raw)
+		if err != nil {
+			return client.ErrorResult(400, fmt.Sprintf("%s: %v", name, err))
+		}
+		selectors[i], descs[i] = filters, d
+	}
+
+	minutes := defaultCanaryMinutes
+	if m, ok := args["time_range_minutes"].(float64); ok {
+		if m < 0 {
+			return client.ErrorResult(400, "time_range_minutes must not be negative")
+		}
+		if m > 0 {
+			minutes = int(m)
+			if minutes > 1440 {
+				minutes = 1440 // Max 24 hours
+			}
+		}
+	}
+
+	alpha := defaultSignificance
+	if v, ok := args["significance"].(float64); ok {
+		if v <= 0 || v >= 1 {
+			return client.ErrorResult(400, "significance must be between 0 and 1")
+		}
+		alpha = v
+	}
+
+	minRequests := defaultMinRequests
+	if v, ok := args["min_requests"].(float64); ok {
+		if v < 0 {
+			return client.ErrorResult(400, "min_requests must not be negative")
+		}
+		minRequests = int(v)
+	}
+
+	maxLatencyIncrease := defaultMaxLatencyIncreasePct
+	if v, ok := args["max_latency_increase_percent"].(float64); ok {
+		if v < 0 {
+			return client.ErrorResult(400, "max_latency_increase_percent must not be negative")
+		}
+		maxLatencyIncrease = v
+	}
+
+	maxSpans := defaultMaxSpansPerWindow
+	if v, ok := args["max_spans"].(float64); ok {
+		if v < 0 {
+			return client.ErrorResult(400, "max_spans must not be negative")
+		}
+		if v > 0 {
+			maxSpans = int(v)
+			if maxSpans > maxMaxSpansPerWindow {
+				maxSpans = maxMaxSpansPerWindow
+			}
+		}
+	}
+
+	var common []otlp.AttributeFilter
+	serviceName, _ := args["service_name"].(string)
+	serviceName = strings.TrimSpace(serviceName)
+	if serviceName != "" {
+		common = append(common, serviceFilter(serviceName))
+	}
+	includeAll, _ := args["include_all_spans"].(bool)
+	dataset := resolveDataset(p.client, args)
+
+	now := time.Now().UTC()
+	window := time.Duration(minutes) * time.Minute
+	from := now.Add(-window)
+
+	var signals [2]Signals
+	var durations [2][]float64
+	for i := range selectors {
+		filters := append(append([]otlp.AttributeFilter{}, common...), selectors[i]...)
+		sample, errResult := fetchSpans(ctx, p.client, dataset, filters, from, now, maxSpans)
+		if errResult != nil {
+			return errResult
+		}
+		ss := sample.Spans
+		if !includeAll {
+			ss, _ = entrySpans(ss)
+		}
+		signals[i] = computeSignals(ss, window, sample.Sampled)
+		for _, s := range ss {
+			durations[i] = append(durations[i], s.DurationMs)
+		}
+	}
+
+	res := CanaryResult{
+		Baseline:     signals[0],
+		Canary:       signals[1],
+		ErrorTest:    twoProportionZTest(signals[0].Errors, signals[0].Requests, signals[1].Errors, signals[1].Requests),
+		LatencyTest:  mannWhitneyU(durations[0], durations[1]),
+		Significance: alpha,
+	}
+	if signals[0].P95Ms > 0 {
+		res.P95IncreasePct = (signals[1].P95Ms - signals[0].P95Ms) / signals[0].P95Ms * 100
+	}
+	res.Recommendation, res.Reasons = recommendCanary(res, minRequests, maxLatencyIncrease)
+	canarySelector, _ := args["canary"].([]interface{})
+	followUps := followup.WithDataset(canaryFollowUps(res, canarySelector, serviceName, args, minutes), datasetArg(args))
+
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: formatCanaryResult(res, descs, minutes) + followup.Markdown(followUps),
+		Data: map[string]interface{}{
+			"result":     res,
+			followup.Key: followUps,
+			"query": map[string]interface{}{
+				"baseline":           selectors[0],
+				"canary":             selectors[1],
+				"common_filters":     common,
+				"time_range_minutes": minutes,
+			},
+		},
+	}
+}
+
+// recommendCanary turns the test results into promote, hold, or inconclusive.
+func recommendCanary(r CanaryResult, minRequests int, maxLatencyIncrease float64) (string, []string) {
+	if r.Baseline.Requests < minRequests || r.Canary.Requests < minRequests {
+		return "inconclusive", []string{fmt.Sprintf(
+			"Not enough requests for a decision (baseline %d, canary %d, need %d each).",
+			r.Baseline.Requests, r.Canary.Requests, minRequests)}
+	}
+
+	var reasons []string
+	if r.ErrorTest.PValue < r.Significance {
+		reasons = append(reasons, fmt.Sprintf(
+			"Error rate is significantly higher: %.2f%% vs %.2f%% (p=%.4f).",
+			r.Canary.ErrorRate, r.Baseline.ErrorRate, r.ErrorTest.PValue))
+	}
+	if r.LatencyTest.PValue < r.Significance && r.P95IncreasePct > maxLatencyIncrease {
+		reasons = append(reasons, fmt.Sprintf(
+			"Latency is significantly higher: P95 %s vs %s (+%.1f%%, p=%.4f).",
+			formatter.FormatDuration(r.Canary.P95Ms), formatter.FormatDuration(r.Baseline.P95Ms),
+			r.P95IncreasePct, r.LatencyTest.PValue))
+	}
+	if len(reasons) > 0 {
+		return "hold", reasons
+	}
+	return "promote", []string{fmt.Sprintf(
+		"No significant error rate or latency regression at p<%.2g.", r.Significance)}
+}
+
+// formatCanaryResult renders the side-by-side comparison and the recommendation.
+func formatCanaryResult(r CanaryResult, descs [2][]string, minutes int) string {
+	headers := []string{"Metric", "Baseline", "Canary", "Test", "p-value"}
+	rows := [][]string{
+		{"Requests", fmt.Sprintf("%d", r.Baseline.Requests), fmt.Sprintf("%d", r.Canary.Requests), "", ""},
+		{"Error rate",
+			fmt.Sprintf("%.2f%% (%d)", r.Baseline.ErrorRate, r.Baseline.Errors),
+			fmt.Sprintf("%.2f%% (%d)", r.Canary.ErrorRate, r.Canary.Errors),
+			r.ErrorTest.Test, fmt.Sprintf("%.4f", r.ErrorTest.PValue)},
+		{"P50", formatter.FormatDuration(r.Baseline.P50Ms), formatter.FormatDuration(r.Canary.P50Ms),
+			r.LatencyTest.Test, fmt.Sprintf("%.4f", r.LatencyTest.PValue)},
+		{"P95", formatter.FormatDuration(r.Baseline.P95Ms), formatter.FormatDuration(r.Canary.P95Ms), "", ""},
+		{"P99", formatter.FormatDuration(r.Baseline.P99Ms), formatter.FormatDuration(r.Canary.P99Ms), "", ""},
+		{"Rate", fmt.Sprintf("%.2f/s", r.Baseline.RatePerSecond), fmt.Sprintf("%.2f/s", r.Canary.RatePerSecond), "", ""},
+	}
+
+	summary := fmt.Sprintf("**Recommendation: %s**\n\nBaseline: %s · Canary: %s · Last %d minutes",
+		strings.ToUpper(r.Recommendation), strings.Join(descs[0], ", "), strings.Join(descs[1], ", "), minutes)
+	footer := "> " + strings.Join(r.Reasons, " ")
+	if r.Baseline.Sampled || r.Canary.Sampled {
+		footer += "\n\n_At least one side hit max_spans; statistics use a sample of its spans._"
+	}
+	return formatter.Table("Canary Analysis", summary, headers, rows, footer)
+}
+
+// ServicesCompare returns the dash0_services_compare tool definition.
+func (p *Tools) ServicesCompare() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_services_compare",
+		Description: `Compare several services side by side over one time window to find the one that is misbehaving.
+
+For each service, reports throughput, error rate, and P50/P95 latency from its SERVER and
+CONSUMER spans (all spans if it has none). The services are queried concurrently and the
+table is sorted by sort_by, worst first; the service with the highest error rate and the
+slowest P95 are flagged.
+
+The result includes suggested_follow_ups: dash0_golden_signals for the flagged services.
+
+Pass baseline to also compare each service with its own signals over the previous period
+or the same time last week.
+
+Example: {"services": ["checkout", "cart", "payment", "shipping"], "time_range_minutes": 30}
+Against the previous period: {"services": ["checkout", "cart"], "baseline": "previous_period"}`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"services": map[string]interface{}{
+					"type":        "array",
+					"description": "Services to compare (exact match on service.name, up to 20)",
+					"items":       map[string]interface{}{"type": "string"},
+				},
+				"time_range_minutes": map[string]interface{}{
+					"type":        "integer",
+					"description": "Minutes back to compare (default: 60, max: 1440)",
+				},
+				"sort_by": map[string]interface{}{
+					"type":        "string",
+					"description": "Order of the table, worst first (default: error_rate)",
+					"enum":        []string{"error_rate", "p95", "throughput"},
+				},
+				"include_all_spans": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Use every span of each service instead of only SERVER/CONSUMER spans. Default: false",
+				},
+				"max_spans_per_service": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum spans to read per service (default: 1000, max: 5000)",
+				},
+				"baseline": baselineSchema,
+				"dataset": map[string]interface{}{
+					"type":        "string",
+					"description": "Dash0 dataset to query (e.g., 'astronomy-demo'). If omitted, uses the globally configured dataset or 'default'.",
+				},
+			},
+			Required: []string{"services"},
+		},
+	}
+}
+
+// ServiceSignals are the signals of one service in dash0_services_compare.
+type ServiceSignals struct {
+	Service string `json:"service"`
+	Signals
+	// EntrySpans is false when the service had no SERVER/CONSUMER spans and all
+	// spans were used instead.
+	EntrySpans bool     `json:"entry_spans"`
+	Flags      []string `json:"flags,omitempty"`
+	// Baseline is set when the call asked for a baseline comparison.
+	Baseline *BaselineComparison `json:"baseline,omitempty"`
+}
+
+// ServicesCompareHandler handles the dash0_services_compare tool.
+func (p *Tools) ServicesCompareHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	raw, _ := args["services"].([]interface{})
+	var services []string
+	seen := map[string]bool{}
+	for _, v := range raw {
+		name, ok := v.(string)
+		if !ok {
+			return client.ErrorResult(400, "services must be an array of service names")
+		}
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		services = append(services, name)
+	}
+	if len(services) == 0 {
+		return client.ErrorResult(400, "services is required: a non-empty array of service names")
+	}
+	if len(services) > maxCompareServices {
+		return client.ErrorResult(400, fmt.Sprintf("at most %d services can be compared, got %d", maxCompareServices, len(services)))
+	}
+
+	minutes := defaultCompareMinutes
+	if m, ok := args["time_range_minutes"].(float64); ok {
+		if m < 0 {
+			return client.ErrorResult(400, "time_range_minutes must not be negative")
+		}
+		if m > 0 {
+			minutes = int(m)
+			if minutes > 1440 {
+				minutes = 1440 // Max 24 hours
+			}
+		}
+	}
+
+	sortBy := "error_rate"
+	if v, ok := args["sort_by"].(string); ok && strings.TrimSpace(v) != "" {
+		sortBy = strings.TrimSpace(v)
+		if sortBy != "error_rate" && sortBy != "p95" && sortBy != "throughput" {
+			return client.ErrorResult(400, fmt.Sprintf("sort_by must be error_rate, p95, or throughput, got %q", sortBy))
+		}
+	}
+
+	maxSpans := defaultMaxSpansPerWindow
+	if v, ok := args["max_spans_per_service"].(float64); ok {
+		if v < 0 {
+			return client.ErrorResult(400, "max_spans_per_service must not be negative")
+		}
+		if v > 0 {
+			maxSpans = int(v)
+			if maxSpans > maxMaxSpansPerWindow {
+				maxSpans = maxMaxSpansPerWindow
+			}
+		}
+	}
+	includeAll, _ := args["include_all_spans"].(bool)
+	baseline, errResult := parseBaseline(args)
+	if errResult != nil {
+		return errResult
+	}
+	dataset := resolveDataset(p.client, args)
+
+	// Task i < n is service i over the window, task n+i its baseline.
+	n := len(services)
+	tasks := n
+	if baseline != "" {
+		tasks *= 2
+	}
+	now := time.Now().UTC()
+	window := time.Duration(minutes) * time.Minute
+	baselineTo := now.Add(-baselineOffset(baseline, window))
+	results := make([]ServiceSignals, n)
+	baselines := make([]Signals, n)
+	errResult = p.client.Parallel(ctx, tasks, func(ctx context.Context, i int) *client.ToolResult {
+		filters := []otlp.AttributeFilter{serviceFilter(services[i%n])}
+		to := now
+		if i >= n {
+			to = baselineTo
+		}
+		sig, entry, errResult := spanSignals(ctx, p.client, dataset, filters, to.Add(-window), to, maxSpans, includeAll)
+		if errResult != nil {
+			return errResult
+		}
+		if i >= n {
+			baselines[i-n] = sig
+			return nil
+		}
+		results[i] = ServiceSignals{
+			Service:    services[i],
+			Signals:    sig,
+			EntrySpans: entry,
+		}
+		return nil
+	})
+	if errResult != nil {
+		return errResult
+	}
+	if baseline != "" {
+		for i := range results {
+			results[i].Baseline = newBaseline(baseline, baselineTo.Add(-window), baselineTo, results[i].Signals, baselines[i])
+		}
+	}
+
+	flagOutliers(results)
+	sortServices(results, sortBy)
+	followUps := followup.WithDataset(compareFollowUps(results, minutes), datasetArg(args))
+
+	data := map[string]interface{}{
+		"services":           results,
+		"sort_by":            sortBy,
+		"time_range_minutes": minutes,
+		"evaluated_at":       now.Format(time.RFC3339),
+		followup.Key:         followUps,
+	}
+	if baseline != "" {
+		data["baseline"] = baseline
+	}
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: formatServicesCompare(results, minutes, sortBy, maxSpans, baseline) + followup.Markdown(followUps),
+		Data:     data,
+	}
+}
+
+// flagOutliers marks the service with the highest error rate and the one with
+// the slowest P95, and services without traffic. Ties and single-service
+// comparisons are not flagged.
+func flagOutliers(results []ServiceSignals) {
+	worst := func(value func(ServiceSignals) float64) int {
+		best, idx, tied := 0.0, -1, false
+		for i, r := range results {
+			if r.Requests == 0 {
+				continue
+			}
+			switch v := value(r); {
+			case v > best:
+				best, idx, tied = v, i, false
+			case v == best && idx >= 0:
+				tied = true
+			}
+		}
+		if tied || len(results) < 2 {
+			return -1
+		}
+		return idx
+	}
+
+	if i := worst(func(r ServiceSignals) float64 { return r.ErrorRate }); i >= 0 {
+		results[i].Flags = append(results[i].Flags, "highest error rate")
+	}
+	if i := worst(func(r ServiceSignals) float64 { return r.P95Ms }); i >= 0 {
+		results[i].Flags = append(results[i].Flags, "slowest P95")
+	}
+	for i := range results {
+		if results[i].Requests == 0 {
+			results[i].Flags = append(results[i].Flags, "no traffic")
+		}
+	}
+}
+
+// sortServices orders the results worst first by the given signal: highest
+// error rate, slowest P95, or lowest throughput. Services without traffic
+// come last.
+func sortServices(results []ServiceSignals, sortBy string) {
+	key := func(r ServiceSignals) float64 {
+		switch sortBy {
+		case "p95":
+			return r.P95Ms
+		case "throughput":
+			return -r.RatePerSecond
+		}
+		return r.ErrorRate
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if (results[i].Requests == 0) != (results[j].Requests == 0) {
+			return results[j].Requests == 0
+		}
+		return key(results[i]) > key(results[j])
+	})
+}
+
+// formatServicesCompare renders one row per service, with the change from
+// the baseline when one was requested.
+func formatServicesCompare(results []ServiceSignals, minutes int, sortBy string, maxSpans int, baseline string) string {
+	headers := []string{"Service", "Requests", "Throughput", "Errors", "Error %", "P50", "P95", "Flags"}
+	if baseline != "" {
+		headers = append(headers, "Throughput Δ", "Error Δ", "P95 Δ")
+	}
+	rows := make([][]string, 0, len(results))
+	var estimated, allSpans bool
+	for _, r := range results {
+		flags := strings.Join(r.Flags, ", ")
+		var row []string
+		if r.Requests == 0 {
+			row = []string{r.Service, "0", "-", "-", "-", "-", "-", flags}
+		} else {
+			marker := ""
+			if r.Sampled {
+				marker = "~"
+				estimated = true
+			}
+			if !r.EntrySpans {
+				allSpans = true
+			}
+			row = []string{
+				r.Service,
+				fmt.Sprintf("%s%d", marker, r.Requests),
+				fmt.Sprintf("%s%.2f/s", marker, r.RatePerSecond),
+				fmt.Sprintf("%d", r.Errors),
+				fmt.Sprintf("%.1f%%", r.ErrorRate),
+				formatter.FormatDuration(r.P50Ms),
+				formatter.FormatDuration(r.P95Ms),
+				flags,
+			}
+		}
+		if b := r.Baseline; b != nil {
+			row = append(row, formatPct(b.Delta.RatePct), formatPoints(b.Delta.ErrorRatePoints), formatPct(b.Delta.P95Pct))
+		}
+		rows = append(rows, row)
+	}
+
+	summary := fmt.Sprintf("**%d services** · Last %d minutes · Sorted by %s, worst first", len(results), minutes, strings.ReplaceAll(sortBy, "_", " "))
+	if baseline != "" {
+		summary += " · Δ vs " + strings.TrimPrefix(baselineLabel(baseline), "the ")
+	}
+	var notes []string
+	if estimated {
+		notes = append(notes, fmt.Sprintf("~ Service has more than %d spans; requests are a sample and throughput is estimated from it.", maxSpans))
+	}
+	if allSpans {
+		notes = append(notes, "Services without SERVER/CONSUMER spans use all of their spans.")
+	}
+	return formatter.Table("Service Comparison", summary, headers, rows, strings.Join(notes, " "))
+}
+
+// ServiceHealth returns the dash0_service_health tool definition.
+func (p *Tools) ServiceHealth() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_service_health",
+		Description: `Score a service's health from 0 to 100 and list the factors behind the score.
+
+Starts at 100 and takes points off for each factor:
+- Traffic: -40 when the service has no requests but had some in the baseline
+- Error rate: -20 at 1% failed requests, -40 at 5%
+- Latency: -15 when P95 is 50% above the baseline P95, -30 when it doubled
+- Alerts: -5 for a pending alert, -25 for a firing one, -40 for a firing critical one
+
+80 and above is healthy, 50 and above degraded, below 50 unhealthy. Factors that cannot be
+evaluated (no traffic, no baseline, an API error) are reported as unknown and take
+no points off.
+
+Errors and latency come from the service's SERVER and CONSUMER spans over the window.
+Alerts count when a service.name, service_name, or service label names the service.
+
+The result includes suggested_follow_ups for every factor that took points off.
+
+Example: {"service_name": "checkout"}`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"service_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Service to score (exact match on service.name)",
+				},
+				"time_range_minutes": map[string]interface{}{
+					"type":        "integer",
+					"description": "Window to score, in minutes (default: 15, max: 1440)",
+				},
+				"baseline": map[string]interface{}{
+					"type":        "string",
+					"description": "Period the P95 is compared with: previous_period (the window just before) or same_time_last_week (default)",
+					"enum":        baselineKinds,
+				},
+				"include_all_spans": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Use every span of the service instead of only SERVER/CONSUMER spans. Default: false",
+				},
+				"max_spans_per_window": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum spans to read for the window and for the baseline (default: 1000, max: 5000)",
+				},
+				"dataset": map[string]interface{}{
+					"type":        "string",
+					"description": "Dash0 dataset to query (e.g., 'astronomy-demo'). If omitted, uses the globally configured dataset or 'default'.",
+				},
+			},
+			Required: []string{"service_name"},
+		},
+	}
+}
+
+// ServiceHealthHandler handles the dash0_service_health tool.
+func (p *Tools) ServiceHealthHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	serviceName, _ := args["service_name"].(string)
+	serviceName = strings.TrimSpace(serviceName)
+	if serviceName == "" {
+		return client.ErrorResult(400, "service_name is required")
+	}
+
+	minutes := defaultHealthMinutes
+	if m, ok := args["time_range_minutes"].(float64); ok {
+		if m < 0 {
+			return client.ErrorResult(400, "time_range_minutes must not be negative")
+		}
+		if m > 0 {
+			minutes = int(m)
+			if minutes > 1440 {
+				minutes = 1440 // Max 24 hours
+			}
+		}
+	}
+
+	maxSpans := defaultMaxSpansPerWindow
+	if v, ok := args["max_spans_per_window"].(float64); ok {
+		if v < 0 {
+			return client.ErrorResult(400, "max_spans_per_window must not be negative")
+		}
+		if v > 0 {
+			maxSpans = int(v)
+			if maxSpans > maxMaxSpansPerWindow {
+				maxSpans = maxMaxSpansPerWindow
+			}
+		}
+	}
+
+	includeAll, _ := args["include_all_spans"].(bool)
+	baseline, errResult := parseBaseline(args)
+	if errResult != nil {
+		return errResult
+	}
+	if baseline == "" {
+		baseline = baselineLastWeek
+	}
+	dataset := resolveDataset(p.client, args)
+	filters := []otlp.AttributeFilter{serviceFilter(serviceName)}
+
+	now := time.Now().UTC()
+	window := time.Duration(minutes) * time.Minute
+	baselineTo := now.Add(-baselineOffset(baseline, window))
+
+	// Spans are required; alerts that cannot be read make their factor
+	// unknown instead of failing the call.
+	var current, base Signals
+	var alerts []HealthAlert
+	var alertsErr *client.ToolResult
+	errResult = p.client.Parallel(ctx, 3, func(ctx context.Context, i int) *client.ToolResult {
+		var errResult *client.ToolResult
+		switch i {
+		case 0:
+			current, _, errResult = spanSignals(ctx, p.client, dataset, filters, now.Add(-window), now, maxSpans, includeAll)
+		case 1:
+			base, _, errResult = spanSignals(ctx, p.client, dataset, filters, baselineTo.Add(-window), baselineTo, maxSpans, includeAll)
+		case 2:
+			alerts, alertsErr = fetchServiceAlerts(ctx, p.client, serviceName)
+		}
+		return errResult
+	})
+	if errResult != nil {
+		return errResult
+	}
+
+	comparison := newBaseline(baseline, baselineTo.Add(-window), baselineTo, current, base)
+	h := ServiceHealth{
+		Service:  serviceName,
+		Signals:  current,
+		Baseline: comparison,
+		Alerts:   alerts,
+		Factors: []HealthFactor{
+			trafficFactor(current, comparison),
+			errorRateFactor(current),
+			latencyFactor(current, comparison),
+			alertsFactor(alerts, alertsErr),
+		},
+	}
+	scoreHealth(&h)
+
+	followUps := followup.WithDataset(healthFollowUps(h, minutes), datasetArg(args))
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: formatServiceHealth(h, minutes) + followup.Markdown(followUps),
+		Data: map[string]interface{}{
+			"service_name":       h.Service,
+			"score":              h.Score,
+			"status":             h.Status,
+			"factors":            h.Factors,
+			"signals":            h.Signals,
+			"baseline":           h.Baseline,
+			"alerts":             h.Alerts,
+			"time_range_minutes": minutes,
+			"evaluated_at":       now.Format(time.RFC3339),
+			followup.Key:         followUps,
+		},
+	}
+}
+
+// ErrorsSummarize returns the dash0_errors_summarize tool definition.
+func (p *Tools) ErrorsSummarize() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_errors_summarize",
+		Description: `Summarize what is breaking in a service: its failed spans and error logs, grouped by
+exception type, route, and HTTP status code, with representative trace IDs per group.
+
+Reads the service's spans with ERROR status and its logs of ERROR severity or above
+concurrently, then counts per group:
+- Exception type: exception.type (from the span or its exception event), else error.type
+- Route: http.route, else the span name
+- Status code: http.response.status_code
+Errors without a value are grouped under "(unset)". Each dimension lists its 10 largest
+groups with up to 3 trace IDs and the first message seen.
+
+Spans are read up to max_spans and logs up to max_logs; error logs are picked from the
+logs read, so a noisy service may need a larger max_logs.
+
+The result includes suggested_follow_ups for the route with the most failed spans and for
+the error logs.
+
+Example: {"service_name": "checkout", "time_range_minutes": 30}`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"service_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Service to summarize (exact match on service.name)",
+				},
+				"time_range_minutes": map[string]interface{}{
+					"type":        "integer",
+					"description": "How far back to look, in minutes (default: 60, max: 1440)",
+				},
+				"max_spans": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum failed spans to read (default: 1000, max: 5000)",
+				},
+				"max_logs": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum logs of the service to read when looking for error logs (default: 1000, max: 5000)",
+				},
+				"dataset": map[string]interface{}{
+					"type":        "string",
+					"description": "Dash0 dataset to query (e.g., 'astronomy-demo'). If omitted, uses the globally configured dataset or 'default'.",
+				},
+			},
+			Required: []string{"service_name"},
+		},
+	}
+}
+
+// ErrorsSummarizeHandler handles the dash0_errors_summarize tool.
+func (p *Tools) ErrorsSummarizeHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	serviceName, _ := args["service_name"].(string)
+	serviceName = strings.TrimSpace(serviceName)
+	if serviceName == "" {
+		return client.ErrorResult(400, "service_name is required")
+	}
+
+	minutes := defaultErrorsMinutes
+	if m, ok := args["time_range_minutes"].(float64); ok {
+		if m < 0 {
+			return client.ErrorResult(400, "time_range_minutes must not be negative")
+		}
+		if m > 0 {
+			minutes = int(m)
+			if minutes > 1440 {
+				minutes = 1440 // Max 24 hours
+			}
+		}
+	}
+
+	maxSpans := defaultMaxSpansPerWindow
+	if v, ok := args["max_spans"].(float64); ok {
+		if v < 0 {
+			return client.ErrorResult(400, "max_spans must not be negative")
+		}
+		if v > 0 {
+			maxSpans = int(v)
+			if maxSpans > maxMaxSpansPerWindow {
+				maxSpans = maxMaxSpansPerWindow
+			}
+		}
+	}
+
+	maxLogs := defaultMaxErrorLogs
+	if v, ok := args["max_logs"].(float64); ok {
+		if v < 0 {
+			return client.ErrorResult(400, "max_logs must not be negative")
+		}
+		if v > 0 {
+			maxLogs = int(v)
+			if maxLogs > maxMaxErrorLogs {
+				maxLogs = maxMaxErrorLogs
+			}
+		}
+	}
+
+	dataset := resolveDataset(p.client, args)
+	now := time.Now().UTC()
+	from := now.Add(-time.Duration(minutes) * time.Minute)
+
+	// Task 0 reads the failed spans, task 1 the error logs.
+	var failed spanSample
+	var errorLogs []logs.FlatLog
+	var logsSampled bool
+	errResult := p.client.Parallel(ctx, 2, func(ctx context.Context, i int) *client.ToolResult {
+		var errResult *client.ToolResult
+		if i == 0 {
+			filters := []otlp.AttributeFilter{serviceFilter(serviceName), errorSpanFilter()}
+			failed, errResult = fetchSpans(ctx, p.client, dataset, filters, from, now, maxSpans)
+		} else {
+			errorLogs, logsSampled, errResult = fetchErrorLogs(ctx, p.client, dataset, serviceName, from, now, maxLogs)
+		}
+		return errResult
+	})
+	if errResult != nil {
+		return errResult
+	}
+
+	summary := summarizeErrors(failed.Spans, errorLogs)
+	summary.SpansSampled = failed.Sampled
+	summary.LogsSampled = logsSampled
+
+	followUps := followup.WithDataset(errorFollowUps(serviceName, summary, minutes), datasetArg(args))
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: formatErrorSummary(serviceName, summary, minutes, maxSpans, maxLogs) + followup.Markdown(followUps),
+		Data: map[string]interface{}{
+			"service_name":       serviceName,
+			"summary":            summary,
+			"time_range_minutes": minutes,
+			"evaluated_at":       now.Format(time.RFC3339),
+			followup.Key:         followUps,
+		},
+	}
+}
+
+// WaitFor returns the dash0_wait_for tool definition.
+func (p *Tools) WaitFor() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_wait_for",
+		Description: `Wait until a condition holds, checking it every interval_seconds, instead of calling
+query tools in a loop. Use it to supervise a remediation, rollout, or fix.
+
+Conditions:
+- error_rate_below: the error rate (%) of the service's SERVER/CONSUMER spans over the last
+  window_minutes is below threshold
+- p95_below: their P95 duration (ms) is below threshold
+- version_visible: spans of the service with service.version = version appeared in the last
+  window_minutes, e.g. a new deployment is serving
+
+Error rate and latency need at least min_requests requests in the window to count.
+The call returns as soon as the condition is met, or when wait_seconds elapse, with the
+latest checks. The wait also ends before the server's DASH0_TOOL_TIMEOUT or the call's
+timeout_seconds. When the client sends a progress token, each check is reported as a
+notifications/progress message.
+
+Example: {"condition": "error_rate_below", "service_name": "checkout", "threshold": 1}
+Rollout: {"condition": "version_visible", "service_name": "checkout", "version": "1.5.0"}`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"condition": map[string]interface{}{
+					"type":        "string",
+					"description": "Condition to wait for",
+					"enum":        waitConditions,
+				},
+				"service_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Service of error_rate_below, p95_below, and version_visible (exact match on service.name)",
+				},
+				"threshold": map[string]interface{}{
+					"type":        "number",
+					"description": "Error rate in percent (error_rate_below) or P95 in milliseconds (p95_below)",
+				},
+				"version": map[string]interface{}{
+					"type":        "string",
+					"description": "service.version to wait for (version_visible)",
+				},
+				"window_minutes": map[string]interface{}{
+					"type":        "integer",
+					"description": "Look-back window of each check, in minutes (default: 5, max: 60)",
+				},
+				"min_requests": map[string]interface{}{
+					"type":        "integer",
+					"description": "Requests the window needs before an error rate or latency counts (default: 10)",
+				},
+				"interval_seconds": map[string]interface{}{
+					"type":        "integer",
+					"description": "Seconds between checks (default: 30, min: 5)",
+				},
+				"wait_seconds": map[string]interface{}{
+					"type":        "integer",
+					"description": "Longest time to wait, in seconds (default: 300, max: 3600)",
+				},
+				"dataset": map[string]interface{}{
+					"type":        "string",
+					"description": "Dash0 dataset to query (e.g., 'astronomy-demo'). If omitted, uses the globally configured dataset or 'default'.",
+				},
+			},
+			Required: []string{"condition"},
+		},
+	}
+}
+
+// WaitForHandler handles the dash0_wait_for tool.
+func (p *Tools) WaitForHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	cond := WaitCondition{WindowMinutes: defaultWaitWindow, MinRequests: defaultWaitMinRequests}
+	cond.Kind, _ = args["condition"].(string)
+	if !containsString(waitConditions, cond.Kind) {
+		return client.ErrorResult(400, "condition must be one of "+strings.Join(waitConditions, ", "))
+	}
+
+	cond.ServiceName, _ = args["service_name"].(string)
+	cond.ServiceName = strings.TrimSpace(cond.ServiceName)
+	if cond.ServiceName == "" {
+		return client.ErrorResult(400, "service_name is required for "+cond.Kind)
+	}
+	switch cond.Kind {
+	case conditionErrorRateBelow, conditionP95Below:
+		threshold, ok := args["threshold"].(float64)
+		if !ok || threshold <= 0 {
+			return client.ErrorResult(400, "threshold must be a positive number for "+cond.Kind)
+		}
+		cond.Threshold = threshold
+	case conditionVersionVisible:
+		cond.Version, _ = args["version"].(string)
+		cond.Version = strings.TrimSpace(cond.Version)
+		if cond.Version == "" {
+			return client.ErrorResult(400, "version is required for "+cond.Kind)
+		}
+	}
+	if cond.Kind != conditionErrorRateBelow && cond.Kind != conditionP95Below {
+		cond.MinRequests = 0
+	}
+
+	if v, ok := args["window_minutes"].(float64); ok {
+		if v < 0 {
+			return client.ErrorResult(400, "window_minutes must not be negative")
+		}
+		if v > 0 {
+			cond.WindowMinutes = int(v)
+			if cond.WindowMinutes > maxWaitWindow {
+				cond.WindowMinutes = maxWaitWindow
+			}
+		}
+	}
+	if v, ok := args["min_requests"].(float64); ok && cond.MinRequests > 0 {
+		if v < 0 {
+			return client.ErrorResult(400, "min_requests must not be negative")
+		}
+		cond.MinRequests = int(v)
+	}
+
+	intervalSeconds := defaultWaitInterval
+	if v, ok := args["interval_seconds"].(float64); ok {
+		if v < 0 {
+			return client.ErrorResult(400, "interval_seconds must not be negative")
+		}
+		if v > 0 {
+			intervalSeconds = int(v)
+			if intervalSeconds < minWaitInterval {
+				intervalSeconds = minWaitInterval
+			}
+		}
+	}
+	waitSeconds := defaultWaitSeconds
+	if v, ok := args["wait_seconds"].(float64); ok {
+		if v < 0 {
+			return client.ErrorResult(400, "wait_seconds must not be negative")
+		}
+		if v > 0 {
+			waitSeconds = int(v)
+			if waitSeconds > maxWaitSeconds {
+				waitSeconds = maxWaitSeconds
+			}
+		}
+	}
+
+	dataset := resolveDataset(p.client, args)
+	interval := time.Duration(intervalSeconds) * waitUnit
+	start := time.Now()
+	deadline := start.Add(time.Duration(waitSeconds) * waitUnit)
+	if d, ok := ctx.Deadline(); ok && d.Add(-waitDeadlineMargin).Before(deadline) {
+		deadline = d.Add(-waitDeadlineMargin)
+	}
+	total := deadline.Sub(start).Seconds()
+
+	result := WaitResult{Condition: cond, WaitSeconds: waitSeconds, History: []WaitObservation{}}
+	for {
+		result.Attempts++
+		// The wait reports its own progress, not that of each check's queries
+		obs, errResult := cond.evaluate(progress.Without(ctx), p.client, dataset, time.Now().UTC())
+		if errResult != nil {
+			if permanentError(errResult) || ctx.Err() != nil {
+				return errResult
+			}
+			obs.Error = errResult.Error.Message()
+		}
+		result.Last = obs
+		result.History = append(result.History, obs)
+		if len(result.History) > waitHistory {
+			result.History = result.History[1:]
+		}
+
+		detail := obs.Detail
+		if obs.Error != "" {
+			detail = obs.Error
+		}
+		progress.Report(ctx, time.Since(start).Seconds(), total, fmt.Sprintf("check %d: %s", result.Attempts, detail))
+
+		if obs.Met || !time.Now().Add(interval).Before(deadline) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return client.ErrorResult(http.StatusGatewayTimeout, fmt.Sprintf("wait cancelled after %d checks: %v", result.Attempts, ctx.Err()))
+		case <-time.After(interval):
+		}
+	}
+
+	result.Met = result.Last.Met
+	result.ElapsedSeconds = math.Round(time.Since(start).Seconds()*10) / 10
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: formatWaitResult(result),
+		Data:     result,
+	}
+}
+
+// CompareWindows returns the dash0_compare_windows tool definition.
+func (p *Tools) CompareWindows() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_compare_windows",
+		Description: `Run the same span or log query over two time ranges and report the change in volume,
+error rate, and latency percentiles, answering "did the deploy make things worse?".
+
+The window is the last window_minutes, or the window_minutes up to window_end. It is compared
+with a baseline window of the same length: the same time yesterday (default), the previous
+period, the same time last week, or the window ending at baseline_end.
+
+For spans, the error rate is the share of spans with ERROR status and latency is compared
+at P50, P95, and P99. Only SERVER/CONSUMER spans count unless include_all_spans is set.
+For logs, the error rate is the share of logs of ERROR severity or above.
+
+A one-sided two-proportion z-test (error rate) and Mann-Whitney U test (latency) decide the
+verdict: worse, better, unchanged, or inconclusive when either window has fewer than
+min_requests spans or logs. Latency only counts as worse when P95 also rose by more than 10%.
+
+Example queries:
+- Last hour vs the same hour yesterday: {"service_name": "checkout"}
+- The 30 minutes since a deploy vs the 30 minutes before: {"service_name": "checkout", "window_minutes": 30, "baseline": "previous_period"}
+- An endpoint around a deploy: {"service_name": "checkout", "span_name": "POST /api/checkout", "window_end": "2024-05-01T15:00:00Z", "baseline_end": "2024-05-01T14:00:00Z"}
+- Error logs of a namespace: {"signal": "logs", "k8s_namespace": "shop", "baseline": "same_time_last_week"}`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"signal": map[string]interface{}{
+					"type":        "string",
+					"description": "Telemetry to compare (default: spans)",
+					"enum":        []string{windowSignalSpans, windowSignalLogs},
+				},
+				"service_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter by service name (exact match unless service_name_match is set, which applies to spans only)",
+				},
+				"service_name_match": spans.MatchSchema("service_name"),
+				"span_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Spans only: filter by span name (exact match unless span_name_match is set)",
+				},
+				"span_name_match": spans.MatchSchema("span_name"),
+				"http_method": map[string]interface{}{
+					"type":        "string",
+					"description": "Spans only: filter by HTTP method (GET, POST, PUT, DELETE, etc)",
+				},
+				"http_status_code": map[string]interface{}{
+					"type":        "integer",
+					"description": "Spans only: filter by HTTP response status code",
+				},
+				"k8s_namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter by Kubernetes namespace (k8s.namespace.name, exact match)",
+				},
+				"k8s_deployment_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter by Kubernetes deployment (k8s.deployment.name, exact match)",
+				},
+				"k8s_pod_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter by Kubernetes pod (k8s.pod.name, exact match)",
+				},
+				"k8s_container_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Logs only: filter by Kubernetes container (k8s.container.name, exact match)",
+				},
+				"attribute_filters": otlp.AttributeFiltersSchema("Arbitrary attribute filters, combined with AND."),
+				"window_minutes": map[string]interface{}{
+					"type":        "integer",
+					"description": "Length of both windows, in minutes (default: 60, max: 1440)",
+				},
+				"window_end": map[string]interface{}{
+					"type":        "string",
+					"description": "End of the window: an RFC 3339 timestamp or a duration ago such as 30m (default: now)",
+				},
+				"baseline": map[string]interface{}{
+					"type":        "string",
+					"description": "Baseline window: same_time_yesterday (default), previous_period (the window just before), or same_time_last_week",
+					"enum":        windowBaselines,
+				},
+				"baseline_end": map[string]interface{}{
+					"type":        "string",
+					"description": "End of the baseline window, instead of baseline: an RFC 3339 timestamp or a duration ago such as 2h",
+				},
+				"include_all_spans": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Spans only: count every matching span, not just SERVER/CONSUMER spans",
+				},
+				"min_requests": map[string]interface{}{
+					"type":        "integer",
+					"description": "Spans or logs each window needs for a verdict (default: 30)",
+				},
+				"significance": map[string]interface{}{
+					"type":        "number",
+					"description": "Significance level of the tests (default: 0.05)",
+				},
+				"max_spans": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum spans to read per window (default: 1000, max: 5000)",
+				},
+				"max_logs": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum logs to read per window (default: 5000, max: 20000)",
+				},
+				"dataset": map[string]interface{}{
+					"type":        "string",
+					"description": "Dash0 dataset to query (e.g., 'astronomy-demo'). If omitted, uses the globally configured dataset or 'default'.",
+				},
+			},
+		},
+	}
+}
+
+// CompareWindowsHandler handles the dash0_compare_windows tool.
+func (p *Tools) CompareWindowsHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	signal, _ := args["signal"].(string)
+	signal = strings.TrimSpace(signal)
+	if signal == "" {
+		signal = windowSignalSpans
+	}
+	if signal != windowSignalSpans && signal != windowSignalLogs {
+		return client.ErrorResult(400, fmt.Sprintf("signal must be %s or %s, got %q", windowSignalSpans, windowSignalLogs, signal))
+	}
+	for other, names := range windowFilterArgs {
+		for _, name := range names {
+			if _, ok := args[name]; ok && other != signal {
+				return client.ErrorResult(400, fmt.Sprintf("%s only applies to signal %s", name, other))
+			}
+		}
+	}
+
+	var filters []otlp.AttributeFilter
+	var descs []string
+	var matchers spans.Matchers
+	var err error
+	if signal == windowSignalSpans {
+		filters, descs, matchers, err = spans.ParseFilters(args)
+	} else {
+		filters, descs, err = logs.ParseFilters(args)
+	}
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+
+	minutes := defaultWindowMinutes
+	if m, ok := args["window_minutes"].(float64); ok {
+		if m < 0 {
+			return client.ErrorResult(400, "window_minutes must not be negative")
+		}
+		if m > 0 {
+			minutes = int(m)
+			if minutes > 1440 {
+				minutes = 1440 // Max 24 hours
+			}
+		}
+	}
+	window := time.Duration(minutes) * time.Minute
+
+	now := time.Now().UTC()
+	to := now
+	if raw, _ := args["window_end"].(string); strings.TrimSpace(raw) != "" {
+		var errResult *client.ToolResult
+		if to, errResult = parseTimeArg("window_end", raw, now); errResult != nil {
+			return errResult
+		}
+		if to.After(now) {
+			return client.ErrorResult(400, "window_end must not be in the future")
+		}
+	}
+
+	baseline, _ := args["baseline"].(string)
+	baseline = strings.TrimSpace(baseline)
+	if baseline != "" && !containsString(windowBaselines, baseline) {
+		return client.ErrorResult(400, fmt.Sprintf("baseline must be one of %s, got %q", strings.Join(windowBaselines, ", "), baseline))
+	}
+	var baselineTo time.Time
+	if raw, _ := args["baseline_end"].(string); strings.TrimSpace(raw) != "" {
+		if baseline != "" {
+			return client.ErrorResult(400, "pass either baseline or baseline_end, not both")
+		}
+		var errResult *client.ToolResult
+		if baselineTo, errResult = parseTimeArg("baseline_end", raw, now); errResult != nil {
+			return errResult
+		}
+		if baselineTo.After(to.Add(-window)) {
+			return client.ErrorResult(400, "baseline_end must not be after the start of the window")
+		}
+		baseline = baselineCustom
+	} else {
+		if baseline == "" {
+			baseline = baselineYesterday
+		}
+		baselineTo = to.Add(-baselineOffset(baseline, window))
+	}
+
+	minCount := defaultMinRequests
+	if v, ok := args["min_requests"].(float64); ok {
+		if v < 0 {
+			return client.ErrorResult(400, "min_requests must not be negative")
+		}
+		minCount = int(v)
+	}
+
+	alpha := defaultSignificance
+	if v, ok := args["significance"].(float64); ok {
+		if v <= 0 || v >= 1 {
+			return client.ErrorResult(400, "significance must be between 0 and 1")
+		}
+		alpha = v
+	}
+
+	maxItems := defaultMaxSpansPerWindow
+	limitArg, limitMax := "max_spans", maxMaxSpansPerWindow
+	if signal == windowSignalLogs {
+		maxItems = defaultWindowMaxLogs
+		limitArg, limitMax = "max_logs", maxWindowMaxLogs
+	}
+	if v, ok := args[limitArg].(float64); ok {
+		if v < 0 {
+			return client.ErrorResult(400, limitArg+" must not be negative")
+		}
+		if v > 0 {
+			maxItems = int(v)
+			if maxItems > limitMax {
+				maxItems = limitMax
+			}
+		}
+	}
+
+	includeAll, _ := args["include_all_spans"].(bool)
+	dataset := resolveDataset(p.client, args)
+
+	// Task 0 reads the window, task 1 its baseline.
+	ends := [2]time.Time{to, baselineTo}
+	var signals [2]Signals
+	var durations [2][]float64
+	errResult := p.client.Parallel(ctx, 2, func(ctx context.Context, i int) *client.ToolResult {
+		from := ends[i].Add(-window)
+		if signal == windowSignalLogs {
+			req := logs.QueryLogsRequest{
+				Dataset: dataset,
+				TimeRange: otlp.TimeRange{
+					From: from.Format(time.RFC3339),
+					To:   ends[i].Format(time.RFC3339),
+				},
+				Filter: filters,
+			}
+			ll, sampled, errResult := logs.Fetch(ctx, p.client, req, dataset, maxItems)
+			if errResult != nil {
+				return errResult
+			}
+			signals[i] = logSignals(ll, window, sampled)
+			return nil
+		}
+		sample, errResult := fetchSpans(ctx, p.client, dataset, filters, from, ends[i], maxItems)
+		if errResult != nil {
+			return errResult
+		}
+		ss := matchers.Filter(sample.Spans)
+		if !includeAll {
+			ss, _ = entrySpans(ss)
+		}
+		signals[i] = computeSignals(ss, window, sample.Sampled)
+		durations[i] = spanDurations(ss)
+		return nil
+	})
+	if errResult != nil {
+		return errResult
+	}
+
+	res := WindowComparison{
+		Signal:        signal,
+		Filters:       descs,
+		From:          to.Add(-window).Format(time.RFC3339),
+		To:            to.Format(time.RFC3339),
+		Current:       signals[0],
+		Baseline:      newBaseline(baseline, baselineTo.Add(-window), baselineTo, signals[0], signals[1]),
+		ErrorRateTest: twoProportionZTest(signals[1].Errors, signals[1].Requests, signals[0].Errors, signals[0].Requests),
+		Significance:  alpha,
+	}
+	if signal == windowSignalSpans {
+		test := mannWhitneyU(durations[1], durations[0])
+		res.LatencyTest = &test
+	}
+	assessWindows(&res, durations[1], durations[0], minCount)
+
+	// Follow-ups look back far enough to cover the window.
+	sinceMinutes := int(math.Ceil(now.Sub(to.Add(-window)).Minutes()))
+	if sinceMinutes > 1440 {
+		sinceMinutes = 1440
+	}
+	// The follow-ups select the service by its exact name.
+	serviceName, _ := args["service_name"].(string)
+	if mode, _ := args["service_name_match"].(string); mode != "" && mode != "exact" {
+		serviceName = ""
+	}
+	followUps := followup.WithDataset(windowFollowUps(res, strings.TrimSpace(serviceName), sinceMinutes), datasetArg(args))
+
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: formatWindowComparison(res, maxItems) + followup.Markdown(followUps),
+		Data: map[string]interface{}{
+			"result":     res,
+			followup.Key: followUps,
+			"query": map[string]interface{}{
+				"signal":         signal,
+				"filters":        filters,
+				"window_minutes": minutes,
+			},
+		},
+	}
+}
+
+// Register registers all analysis tools with the registry.
+func Register(reg *registry.Registry, c *client.Client) {
+	p := New(c)
+	for _, tool := range p.Tools() {
+		handler := p.Handlers()[tool.Name]
+		reg.Register(tool, handler)
+		reg.AddExamples(tool.Name, p.Examples()[tool.Name]...)
+	}
+}
@@ -0,0 +1,1311 @@
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/api/logs"
+	"github.com/npcomplete777/dash0-mcp/api/spans"
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/followup"
+	"github.com/npcomplete777/dash0-mcp/internal/progress"
+)
+
+func TestNew(t *testing.T) {
+	c := &client.Client{}
+	pkg := New(c)
+	if pkg == nil {
+		t.Fatal("New() returned nil")
+	}
+	if pkg.client != c {
+		t.Error("New() did not set client correctly")
+	}
+}
+
+func TestTools(t *testing.T) {
+	pkg := New(&client.Client{})
+	tools := pkg.Tools()
+
+	if len(tools) != 7 {
+		t.Fatalf("Tools() returned %d tools, expected 7", len(tools))
+	}
+	handlers := pkg.Handlers()
+	for _, tool := range tools {
+		if _, ok := handlers[tool.Name]; !ok {
+			t.Errorf("Missing handler for %s", tool.Name)
+		}
+	}
+}
+
+// spanJSON builds one OTLP span starting at start with the given duration.
+func spanJSON(i int, kind int, start time.Time, duration time.Duration, errored bool) map[string]interface{} {
+	status := map[string]interface{}{}
+	if errored {
+		status["code"] = float64(2)
+	}
+	return map[string]interface{}{
+		"traceId":           fmt.Sprintf("trace-%d", i),
+		"spanId":            fmt.Sprintf("span-%d", i),
+		"name":              "GET /api/cart",
+		"kind":              float64(kind),
+		"startTimeUnixNano": fmt.Sprintf("%d", start.UnixNano()),
+		"endTimeUnixNano":   fmt.Sprintf("%d", start.Add(duration).UnixNano()),
+		"status":            status,
+	}
+}
+
+func spansResponse(spanList []interface{}, next string) map[string]interface{} {
+	resp := map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": []interface{}{
+						map[string]interface{}{"key": "service.name", "value": map[string]interface{}{"stringValue": "cart"}},
+					},
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{"spans": spanList},
+				},
+			},
+		},
+	}
+	if next != "" {
+		resp["cursors"] = map[string]interface{}{"after": next}
+	}
+	return resp
+}
+
+func TestComputeSignals(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	var ss []spans.FlatSpan
+	for i := 1; i <= 100; i++ {
+		ss = append(ss, spans.FlatSpan{
+			DurationMs: float64(i),
+			StartTime:  start.Add(time.Duration(i) * time.Second).Format(time.RFC3339Nano),
+			StatusCode: map[bool]int{true: 2, false: 0}[i%10 == 0],
+		})
+	}
+
+	sig := computeSignals(ss, 100*time.Second, false)
+	if sig.Requests != 100 || sig.Errors != 10 {
+		t.Errorf("requests/errors = %d/%d, want 100/10", sig.Requests, sig.Errors)
+	}
+	if sig.ErrorRate != 10 {
+		t.Errorf("ErrorRate = %v, want 10", sig.ErrorRate)
+	}
+	if sig.P50Ms != 50 || sig.P95Ms != 95 || sig.P99Ms != 99 {
+		t.Errorf("percentiles = %v/%v/%v, want 50/95/99", sig.P50Ms, sig.P95Ms, sig.P99Ms)
+	}
+	if sig.RatePerSecond != 1 {
+		t.Errorf("RatePerSecond = %v, want 1", sig.RatePerSecond)
+	}
+	// 1 req/s × 50.5ms mean latency
+	if sig.InFlight < 0.0504 || sig.InFlight > 0.0506 {
+		t.Errorf("InFlight = %v, want 0.0505", sig.InFlight)
+	}
+
+	// A capped sample estimates the rate from the 99s it covers, not the window.
+	sampled := computeSignals(ss, time.Hour, true)
+	if !sampled.Sampled {
+		t.Error("expected Sampled for a sampled window")
+	}
+	if got := sampled.RatePerSecond; got < 1.01 || got > 1.02 {
+		t.Errorf("sampled RatePerSecond = %v, want ~1.0101", got)
+	}
+
+	empty := computeSignals(nil, time.Hour, false)
+	if empty.Requests != 0 || empty.RatePerSecond != 0 {
+		t.Errorf("unexpected signals for empty sample: %+v", empty)
+	}
+}
+
+func TestGoldenSignalFollowUps(t *testing.T) {
+	window := func(label string, requests, errors int, p95 float64) WindowSignals {
+		w := WindowSignals{Window: label}
+		w.Requests, w.Errors, w.P95Ms = requests, errors, p95
+		if requests > 0 {
+			w.ErrorRate = float64(errors) / float64(requests) * 100
+		}
+		return w
+	}
+
+	tests := []struct {
+		name    string
+		windows []WindowSignals
+		want    []string
+	}{
+		{"healthy", []WindowSignals{window("5m", 100, 0, 100), window("24h", 1000, 0, 100)}, nil},
+		{"errors", []WindowSignals{window("5m", 100, 5, 100), window("24h", 1000, 5, 100)},
+			[]string{"dash0_spans_query", "dash0_logs_query"}},
+		{"latency regression", []WindowSignals{window("5m", 100, 0, 400), window("24h", 1000, 0, 100)},
+			[]string{"dash0_spans_query"}},
+		{"traffic stopped", []WindowSignals{window("5m", 0, 0, 0), window("24h", 1000, 0, 100)},
+			[]string{"dash0_alerting_active_alerts"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := goldenSignalFollowUps("cart", tt.windows)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %+v, want tools %v", got, tt.want)
+			}
+			for i, tool := range tt.want {
+				if got[i].Tool != tool {
+					t.Errorf("follow-up %d = %s, want %s", i, got[i].Tool, tool)
+				}
+			}
+		})
+	}
+
+	got := goldenSignalFollowUps("cart", []WindowSignals{window("5m", 100, 0, 400), window("24h", 1000, 0, 100)})
+	if got[0].Arguments["min_duration_ms"] != float64(100) || got[0].Arguments["service_name"] != "cart" {
+		t.Errorf("unexpected latency follow-up arguments: %v", got[0].Arguments)
+	}
+}
+
+func TestGoldenSignalsHandler_Validation(t *testing.T) {
+	pkg := New(client.NewWithBaseURL("http://unused", "test-token"))
+
+	tests := []struct {
+		name        string
+		args        map[string]interface{}
+		expectError string
+	}{
+		{"missing service", map[string]interface{}{}, "service_name is required"},
+		{"negative max spans", map[string]interface{}{"service_name": "cart", "max_spans_per_window": float64(-1)}, "must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := pkg.GoldenSignalsHandler(context.Background(), tt.args)
+			if result.Success {
+				t.Fatal("expected failure")
+			}
+			if !strings.Contains(result.Error.Detail, tt.expectError) {
+				t.Errorf("error = %q, want it to contain %q", result.Error.Detail, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestGoldenSignalsHandler(t *testing.T) {
+	var mu sync.Mutex
+	var ranges []time.Duration
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/spans" {
+			t.Errorf("Expected /api/spans, got %s", r.URL.Path)
+		}
+		var req spans.QuerySpansRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Filter) != 1 || req.Filter[0].Key != "service.name" || *req.Filter[0].Value.StringValue != "cart" {
+			t.Errorf("unexpected filters: %+v", req.Filter)
+		}
+		from, _ := time.Parse(time.RFC3339, req.TimeRange.From)
+		to, _ := time.Parse(time.RFC3339, req.TimeRange.To)
+		mu.Lock()
+		ranges = append(ranges, to.Sub(from))
+		mu.Unlock()
+
+		now := time.Now()
+		list := []interface{}{
+			spanJSON(1, 2, now.Add(-time.Minute), 100*time.Millisecond, false),
+			spanJSON(2, 2, now.Add(-time.Minute), 300*time.Millisecond, true),
+			spanJSON(3, 3, now.Add(-time.Minute), 5*time.Second, false), // CLIENT span, ignored
+		}
+		json.NewEncoder(w).Encode(spansResponse(list, ""))
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.GoldenSignalsHandler(context.Background(), map[string]interface{}{"service_name": "cart"})
+	if !result.Success {
+		t.Fatalf("expected success, got %+v", result.Error)
+	}
+
+	// The windows are queried concurrently, so sort them before comparing.
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i] < ranges[j] })
+	want := []time.Duration{5 * time.Minute, time.Hour, 24 * time.Hour}
+	if len(ranges) != len(want) {
+		t.Fatalf("expected %d queries, got %d", len(want), len(ranges))
+	}
+	for i, d := range want {
+		if ranges[i] != d {
+			t.Errorf("query %d covers %v, want %v", i, ranges[i], d)
+		}
+	}
+
+	windows := result.Data.(map[string]interface{})["windows"].([]WindowSignals)
+	if windows[0].Requests != 2 || windows[0].Errors != 1 || !windows[0].EntrySpans {
+		t.Errorf("unexpected 5m signals: %+v", windows[0])
+	}
+	if windows[0].P99Ms != 300 {
+		t.Errorf("P99Ms = %v, want 300", windows[0].P99Ms)
+	}
+
+	for _, s := range []string{"Golden Signals", "| 5m | 2 |", "| 24h |", "50.0%"} {
+		if !strings.Contains(result.Markdown, s) {
+			t.Errorf("markdown missing %q:\n%s", s, result.Markdown)
+		}
+	}
+}
+
+func TestGoldenSignalsHandler_Baseline(t *testing.T) {
+	var mu sync.Mutex
+	baselineQueries := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req spans.QuerySpansRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		to, _ := time.Parse(time.RFC3339, req.TimeRange.To)
+
+		now := time.Now()
+		var list []interface{}
+		if now.Sub(to) > 6*24*time.Hour {
+			// Last week: four fast, healthy requests.
+			mu.Lock()
+			baselineQueries++
+			mu.Unlock()
+			for i := 0; i < 4; i++ {
+				list = append(list, spanJSON(i, 2, to.Add(-time.Minute), 100*time.Millisecond, false))
+			}
+		} else {
+			list = []interface{}{
+				spanJSON(1, 2, now.Add(-time.Minute), 200*time.Millisecond, false),
+				spanJSON(2, 2, now.Add(-time.Minute), 200*time.Millisecond, true),
+			}
+		}
+		json.NewEncoder(w).Encode(spansResponse(list, ""))
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.GoldenSignalsHandler(context.Background(), map[string]interface{}{
+		"service_name": "cart",
+		"baseline":     "same_time_last_week",
+	})
+	if !result.Success {
+		t.Fatalf("expected success, got %+v", result.Error)
+	}
+	if baselineQueries != len(goldenSignalWindows) {
+		t.Errorf("baseline queries = %d, want %d", baselineQueries, len(goldenSignalWindows))
+	}
+
+	windows := result.Data.(map[string]interface{})["windows"].([]WindowSignals)
+	b := windows[0].Baseline
+	if b == nil || b.Kind != "same_time_last_week" || b.Signals.Requests != 4 {
+		t.Fatalf("unexpected 5m baseline: %+v", b)
+	}
+	if b.Delta.ErrorRatePoints != 50 {
+		t.Errorf("ErrorRatePoints = %v, want 50", b.Delta.ErrorRatePoints)
+	}
+	if b.Delta.RequestsPct == nil || *b.Delta.RequestsPct != -50 {
+		t.Errorf("RequestsPct = %v, want -50", b.Delta.RequestsPct)
+	}
+	if b.Delta.P95Pct == nil || *b.Delta.P95Pct != 100 {
+		t.Errorf("P95Pct = %v, want 100", b.Delta.P95Pct)
+	}
+
+	for _, s := range []string{"| Rate Δ | Error Δ | P95 Δ |", "+50.0pp | +100% |", "the same time last week"} {
+		if !strings.Contains(result.Markdown, s) {
+			t.Errorf("markdown missing %q:\n%s", s, result.Markdown)
+		}
+	}
+
+	bad := pkg.GoldenSignalsHandler(context.Background(), map[string]interface{}{"service_name": "cart", "baseline": "yesterday"})
+	if bad.Success || !strings.Contains(bad.Error.Detail, "baseline must be") {
+		t.Errorf("expected baseline validation error, got %+v", bad.Error)
+	}
+}
+
+func TestGoldenSignalsHandler_Sampled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now()
+		list := []interface{}{
+			spanJSON(1, 2, now.Add(-2*time.Second), 10*time.Millisecond, false),
+			spanJSON(2, 2, now.Add(-time.Second), 10*time.Millisecond, false),
+		}
+		json.NewEncoder(w).Encode(spansResponse(list, "more"))
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.GoldenSignalsHandler(context.Background(), map[string]interface{}{
+		"service_name":         "cart",
+		"max_spans_per_window": float64(2),
+	})
+	if !result.Success {
+		t.Fatalf("expected success, got %+v", result.Error)
+	}
+	if !strings.Contains(result.Markdown, "~2") || !strings.Contains(result.Markdown, "more than 2 spans") {
+		t.Errorf("expected sampled marker and note:\n%s", result.Markdown)
+	}
+	windows := result.Data.(map[string]interface{})["windows"].([]WindowSignals)
+	for _, w := range windows {
+		if !w.Sampled {
+			t.Errorf("expected window %s to be sampled", w.Window)
+		}
+	}
+}
+
+func TestGoldenSignalsHandler_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		var req spans.QuerySpansRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Pagination.Cursor == "" {
+			list := []interface{}{spanJSON(1, 2, time.Now().Add(-time.Minute), 10*time.Millisecond, false)}
+			json.NewEncoder(w).Encode(spansResponse(list, "more"))
+			return
+		}
+		// The user stops the tool call while a second page is in flight
+		cancel()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.GoldenSignalsHandler(ctx, map[string]interface{}{"service_name": "cart"})
+	if result.Success {
+		t.Fatal("expected cancelled call to fail")
+	}
+	if result.Error.StatusCode != client.StatusClientClosedRequest {
+		t.Errorf("StatusCode = %d, want %d", result.Error.StatusCode, client.StatusClientClosedRequest)
+	}
+	// Windows are fetched concurrently; none may read past its second page.
+	if n, limit := requests.Load(), int32(2*len(goldenSignalWindows)); n > limit {
+		t.Errorf("expected no upstream requests after cancellation, got %d in total (max %d)", n, limit)
+	}
+}
+
+func TestTwoProportionZTest(t *testing.T) {
+	// 10/1000 vs 30/1000: z ≈ 3.18, p ≈ 0.0007
+	r := twoProportionZTest(10, 1000, 30, 1000)
+	if r.Statistic < 3.1 || r.Statistic > 3.3 {
+		t.Errorf("Statistic = %v, want ~3.18", r.Statistic)
+	}
+	if r.PValue > 0.001 {
+		t.Errorf("PValue = %v, want < 0.001", r.PValue)
+	}
+
+	// A better canary is not a regression.
+	if r := twoProportionZTest(30, 1000, 10, 1000); r.PValue < 0.5 {
+		t.Errorf("PValue = %v for improving canary, want > 0.5", r.PValue)
+	}
+
+	// No errors on either side carries no evidence.
+	if r := twoProportionZTest(0, 100, 0, 100); r.PValue != 1 {
+		t.Errorf("PValue = %v with no errors, want 1", r.PValue)
+	}
+}
+
+func TestMannWhitneyU(t *testing.T) {
+	baseline := make([]float64, 50)
+	slower := make([]float64, 50)
+	same := make([]float64, 50)
+	for i := range baseline {
+		baseline[i] = float64(100 + i)
+		slower[i] = float64(130 + i)
+		same[i] = float64(100 + i)
+	}
+
+	if r := mannWhitneyU(baseline, slower); r.PValue > 0.001 {
+		t.Errorf("PValue = %v for slower canary, want < 0.001", r.PValue)
+	}
+	if r := mannWhitneyU(baseline, same); r.PValue < 0.4 || r.PValue > 0.6 {
+		t.Errorf("PValue = %v for identical samples, want ~0.5", r.PValue)
+	}
+	if r := mannWhitneyU(slower, baseline); r.PValue < 0.999 {
+		t.Errorf("PValue = %v for faster canary, want ~1", r.PValue)
+	}
+	if r := mannWhitneyU(nil, slower); r.PValue != 1 {
+		t.Errorf("PValue = %v with empty baseline, want 1", r.PValue)
+	}
+}
+
+// canaryServer serves spans for the deployment.variant selector in each
+// request: the canary variant gets the supplied durations and error flags.
+func canaryServer(baseline, canary func(i int) (time.Duration, bool), n int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req spans.QuerySpansRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		gen := baseline
+		for _, f := range req.Filter {
+			if f.Key == "deployment.variant" && f.Value.StringValue != nil && *f.Value.StringValue == "canary" {
+				gen = canary
+			}
+		}
+
+		now := time.Now()
+		list := make([]interface{}, n)
+		for i := 0; i < n; i++ {
+			d, errored := gen(i)
+			list[i] = spanJSON(i, 2, now.Add(-time.Duration(i)*time.Second), d, errored)
+		}
+		json.NewEncoder(w).Encode(spansResponse(list, ""))
+	}))
+}
+
+func canaryArgs() map[string]interface{} {
+	return map[string]interface{}{
+		"service_name": "cart",
+		"baseline":     []interface{}{map[string]interface{}{"key": "deployment.variant", "value": "stable"}},
+		"canary":       []interface{}{map[string]interface{}{"key": "deployment.variant", "value": "canary"}},
+	}
+}
+
+func TestCanaryAnalyzeHandler(t *testing.T) {
+	healthy := func(i int) (time.Duration, bool) {
+		return time.Duration(100+i%20) * time.Millisecond, i%50 == 0
+	}
+	erroring := func(i int) (time.Duration, bool) {
+		return time.Duration(100+i%20) * time.Millisecond, i%5 == 0
+	}
+	slow := func(i int) (time.Duration, bool) {
+		return time.Duration(200+i%20) * time.Millisecond, i%50 == 0
+	}
+
+	tests := []struct {
+		name     string
+		canary   func(int) (time.Duration, bool)
+		n        int
+		want     string
+		reason   string
+		followUp string
+	}{
+		{"healthy canary", healthy, 100, "promote", "No significant", ""},
+		{"error regression", erroring, 100, "hold", "Error rate is significantly higher", "error_only"},
+		{"latency regression", slow, 100, "hold", "Latency is significantly higher", "min_duration_ms"},
+		{"too few requests", slow, 10, "inconclusive", "Not enough requests", "time_range_minutes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := canaryServer(healthy, tt.canary, tt.n)
+			defer server.Close()
+
+			result := New(client.NewWithBaseURL(server.URL, "test-token")).CanaryAnalyzeHandler(context.Background(), canaryArgs())
+			if !result.Success {
+				t.Fatalf("expected success, got %+v", result.Error)
+			}
+			res := result.Data.(map[string]interface{})["result"].(CanaryResult)
+			if res.Recommendation != tt.want {
+				t.Errorf("Recommendation = %s, want %s (reasons: %v)", res.Recommendation, tt.want, res.Reasons)
+			}
+			if !strings.Contains(strings.Join(res.Reasons, " "), tt.reason) {
+				t.Errorf("reasons %v missing %q", res.Reasons, tt.reason)
+			}
+			if !strings.Contains(result.Markdown, "Recommendation: "+strings.ToUpper(tt.want)) {
+				t.Errorf("markdown missing recommendation:\n%s", result.Markdown)
+			}
+
+			followUps := result.Data.(map[string]interface{})[followup.Key].([]followup.Suggestion)
+			if tt.followUp == "" {
+				if len(followUps) != 0 {
+					t.Errorf("expected no follow-ups, got %+v", followUps)
+				}
+				return
+			}
+			if len(followUps) == 0 {
+				t.Fatal("expected a follow-up suggestion")
+			}
+			if _, ok := followUps[0].Arguments[tt.followUp]; !ok {
+				t.Errorf("follow-up %+v missing %q", followUps[0], tt.followUp)
+			}
+		})
+	}
+}
+
+func TestCanaryAnalyzeHandler_Validation(t *testing.T) {
+	pkg := New(client.NewWithBaseURL("http://unused", "test-token"))
+
+	tests := []struct {
+		name        string
+		mutate      func(map[string]interface{})
+		expectError string
+	}{
+		{"missing baseline", func(a map[string]interface{}) { delete(a, "baseline") }, "baseline is required"},
+		{"empty canary", func(a map[string]interface{}) { a["canary"] = []interface{}{} }, "canary is required"},
+		{"bad filter", func(a map[string]interface{}) {
+			a["canary"] = []interface{}{map[string]interface{}{"key": "x", "operator": "regex", "value": "y"}}
+		}, "canary: "},
+		{"significance out of range", func(a map[string]interface{}) { a["significance"] = float64(1.5) }, "significance must be between 0 and 1"},
+		{"negative min requests", func(a map[string]interface{}) { a["min_requests"] = float64(-1) }, "min_requests must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := canaryArgs()
+			tt.mutate(args)
+			result := pkg.CanaryAnalyzeHandler(context.Background(), args)
+			if result.Success {
+				t.Fatal("expected failure")
+			}
+			if !strings.Contains(result.Error.Detail, tt.expectError) {
+				t.Errorf("error = %q, want it to contain %q", result.Error.Detail, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestServicesCompareHandler(t *testing.T) {
+	// cart is slow, payment fails half its requests, and shipping has no traffic.
+	profiles := map[string]struct {
+		duration time.Duration
+		errors   bool
+	}{
+		"cart":     {duration: 900 * time.Millisecond},
+		"payment":  {duration: 50 * time.Millisecond, errors: true},
+		"checkout": {duration: 100 * time.Millisecond},
+	}
+	var mu sync.Mutex
+	queried := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req spans.QuerySpansRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		service := *req.Filter[0].Value.StringValue
+		mu.Lock()
+		queried[service] = true
+		mu.Unlock()
+
+		prof, ok := profiles[service]
+		if !ok {
+			json.NewEncoder(w).Encode(spansResponse(nil, ""))
+			return
+		}
+		now := time.Now()
+		var list []interface{}
+		for i := 0; i < 4; i++ {
+			list = append(list, spanJSON(i, 2, now.Add(-time.Minute), prof.duration, prof.errors && i%2 == 0))
+		}
+		json.NewEncoder(w).Encode(spansResponse(list, ""))
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.ServicesCompareHandler(context.Background(), map[string]interface{}{
+		"services":           []interface{}{"cart", "payment", " cart ", "checkout", "shipping"},
+		"time_range_minutes": float64(30),
+	})
+	if !result.Success {
+		t.Fatalf("expected success, got %+v", result.Error)
+	}
+	if len(queried) != 4 {
+		t.Errorf("expected 4 services queried, got %v", queried)
+	}
+
+	results := result.Data.(map[string]interface{})["services"].([]ServiceSignals)
+	var order []string
+	for _, r := range results {
+		order = append(order, r.Service)
+	}
+	if got := strings.Join(order, ","); got != "payment,cart,checkout,shipping" {
+		t.Errorf("order = %s, want payment first and shipping last", got)
+	}
+	if results[0].ErrorRate != 50 || strings.Join(results[0].Flags, ",") != "highest error rate" {
+		t.Errorf("payment = %+v", results[0])
+	}
+	if strings.Join(results[1].Flags, ",") != "slowest P95" || strings.Join(results[3].Flags, ",") != "no traffic" {
+		t.Errorf("flags = %v / %v", results[1].Flags, results[3].Flags)
+	}
+	for _, s := range []string{"Service Comparison", "Last 30 minutes", "| payment | 4 |", "| shipping | 0 |"} {
+		if !strings.Contains(result.Markdown, s) {
+			t.Errorf("markdown missing %q:\n%s", s, result.Markdown)
+		}
+	}
+
+	followUps := result.Data.(map[string]interface{})[followup.Key].([]followup.Suggestion)
+	if len(followUps) != 2 || followUps[0].Tool != "dash0_golden_signals" || followUps[0].Arguments["service_name"] != "payment" {
+		t.Errorf("unexpected follow-ups: %+v", followUps)
+	}
+
+	byP95 := pkg.ServicesCompareHandler(context.Background(), map[string]interface{}{
+		"services": []interface{}{"payment", "cart"},
+		"sort_by":  "p95",
+	})
+	if first := byP95.Data.(map[string]interface{})["services"].([]ServiceSignals)[0].Service; first != "cart" {
+		t.Errorf("sorted by p95, first = %s, want cart", first)
+	}
+}
+
+func TestServicesCompareHandler_Baseline(t *testing.T) {
+	var mu sync.Mutex
+	var windows []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req spans.QuerySpansRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		from, _ := time.Parse(time.RFC3339, req.TimeRange.From)
+		to, _ := time.Parse(time.RFC3339, req.TimeRange.To)
+		mu.Lock()
+		windows = append(windows, fmt.Sprintf("%s %v", *req.Filter[0].Value.StringValue, time.Since(to).Round(time.Minute)))
+		mu.Unlock()
+		if to.Sub(from) != 30*time.Minute {
+			t.Errorf("window = %v, want 30m", to.Sub(from))
+		}
+
+		// The previous period had half the traffic.
+		n := 4
+		if time.Since(to) > 20*time.Minute {
+			n = 2
+		}
+		var list []interface{}
+		for i := 0; i < n; i++ {
+			list = append(list, spanJSON(i, 2, to.Add(-time.Minute), 100*time.Millisecond, false))
+		}
+		json.NewEncoder(w).Encode(spansResponse(list, ""))
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.ServicesCompareHandler(context.Background(), map[string]interface{}{
+		"services":           []interface{}{"cart", "checkout"},
+		"time_range_minutes": float64(30),
+		"baseline":           "previous_period",
+	})
+	if !result.Success {
+		t.Fatalf("expected success, got %+v", result.Error)
+	}
+
+	sort.Strings(windows)
+	if got := strings.Join(windows, ","); got != "cart 0s,cart 30m0s,checkout 0s,checkout 30m0s" {
+		t.Errorf("queried windows = %s", got)
+	}
+	for _, r := range result.Data.(map[string]interface{})["services"].([]ServiceSignals) {
+		if r.Baseline == nil || r.Baseline.Signals.Requests != 2 || r.Baseline.Delta.RatePct == nil || *r.Baseline.Delta.RatePct != 100 {
+			t.Errorf("%s baseline = %+v", r.Service, r.Baseline)
+		}
+	}
+	for _, s := range []string{"Δ vs previous period", "| Throughput Δ | Error Δ | P95 Δ |", "+100% | +0.0pp | +0% |"} {
+		if !strings.Contains(result.Markdown, s) {
+			t.Errorf("markdown missing %q:\n%s", s, result.Markdown)
+		}
+	}
+}
+
+func TestServicesCompareHandler_Validation(t *testing.T) {
+	pkg := New(client.NewWithBaseURL("http://unused", "test-token"))
+
+	many := make([]interface{}, maxCompareServices+1)
+	for i := range many {
+		many[i] = fmt.Sprintf("svc-%d", i)
+	}
+	tests := []struct {
+		name        string
+		args        map[string]interface{}
+		expectError string
+	}{
+		{"missing services", map[string]interface{}{}, "services is required"},
+		{"blank services", map[string]interface{}{"services": []interface{}{" "}}, "services is required"},
+		{"non-string service", map[string]interface{}{"services": []interface{}{float64(1)}}, "array of service names"},
+		{"too many services", map[string]interface{}{"services": many}, "at most 20 services"},
+		{"bad sort", map[string]interface{}{"services": []interface{}{"cart"}, "sort_by": "name"}, "sort_by must be"},
+		{"negative range", map[string]interface{}{"services": []interface{}{"cart"}, "time_range_minutes": float64(-1)}, "time_range_minutes must not be negative"},
+		{"bad baseline", map[string]interface{}{"services": []interface{}{"cart"}, "baseline": "last_month"}, "baseline must be"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := pkg.ServicesCompareHandler(context.Background(), tt.args)
+			if result.Success {
+				t.Fatal("expected failure")
+			}
+			if !strings.Contains(result.Error.Detail, tt.expectError) {
+				t.Errorf("error = %q, want it to contain %q", result.Error.Detail, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestScoreHealth(t *testing.T) {
+	base := func(requests int, p95 float64, current Signals) *BaselineComparison {
+		return newBaseline(baselineLastWeek, time.Time{}, time.Time{}, current, Signals{Requests: requests, P95Ms: p95})
+	}
+	healthy := Signals{Requests: 100, Errors: 0, P95Ms: 100}
+	failing := Signals{Requests: 100, Errors: 10, ErrorRate: 10, P95Ms: 250}
+	stopped := Signals{}
+
+	tests := []struct {
+		name       string
+		factors    []HealthFactor
+		wantScore  int
+		wantStatus string
+	}{
+		{"healthy", []HealthFactor{
+			trafficFactor(healthy, base(100, 100, healthy)),
+			errorRateFactor(healthy),
+			latencyFactor(healthy, base(100, 100, healthy)),
+			alertsFactor(nil, nil),
+		}, 100, "healthy"},
+		{"errors and slow", []HealthFactor{
+			errorRateFactor(failing),
+			latencyFactor(failing, base(100, 100, failing)),
+		}, 30, "unhealthy"},
+		{"pending alert", []HealthFactor{
+			alertsFactor([]HealthAlert{{Name: "HighLatency", State: "pending"}}, nil),
+		}, 95, "healthy"},
+		{"critical alert", []HealthFactor{
+			alertsFactor([]HealthAlert{{Name: "Down", State: "firing", Severity: "critical"}}, nil),
+		}, 60, "degraded"},
+		{"traffic stopped", []HealthFactor{
+			trafficFactor(stopped, base(50, 100, stopped)),
+			errorRateFactor(stopped),
+			latencyFactor(stopped, base(50, 100, stopped)),
+		}, 60, "degraded"},
+		{"everything unknown", []HealthFactor{
+			trafficFactor(stopped, base(0, 0, stopped)),
+			alertsFactor(nil, client.ErrorResult(403, "forbidden")),
+		}, 100, "healthy"},
+		{"clamped", []HealthFactor{
+			trafficFactor(stopped, base(50, 100, stopped)),
+			errorRateFactor(failing),
+			latencyFactor(failing, base(100, 100, failing)),
+			alertsFactor([]HealthAlert{{Name: "Down", State: "firing"}}, nil),
+		}, 0, "unhealthy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := ServiceHealth{Factors: tt.factors}
+			scoreHealth(&h)
+			if h.Score != tt.wantScore || h.Status != tt.wantStatus {
+				t.Errorf("score = %d (%s), want %d (%s); factors %+v", h.Score, h.Status, tt.wantScore, tt.wantStatus, h.Factors)
+			}
+			for i := 1; i < len(h.Factors); i++ {
+				if h.Factors[i].Penalty > h.Factors[i-1].Penalty {
+					t.Errorf("factors not ordered by penalty: %+v", h.Factors)
+				}
+			}
+		})
+	}
+}
+
+func TestServiceHealthHandler(t *testing.T) {
+	now := time.Now().UTC()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/spans":
+			var req spans.QuerySpansRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			to, _ := time.Parse(time.RFC3339, req.TimeRange.To)
+			var list []interface{}
+			if now.Sub(to) > 6*24*time.Hour {
+				// Last week: fast and healthy
+				for i := 0; i < 10; i++ {
+					list = append(list, spanJSON(i, 2, to.Add(-time.Minute), 100*time.Millisecond, false))
+				}
+			} else {
+				// Now: one in ten fails, and P95 tripled
+				for i := 0; i < 10; i++ {
+					list = append(list, spanJSON(i, 2, now.Add(-time.Minute), 300*time.Millisecond, i == 0))
+				}
+			}
+			json.NewEncoder(w).Encode(spansResponse(list, ""))
+		case r.URL.Path == "/api/alerting/alerts":
+			json.NewEncoder(w).Encode([]interface{}{
+				map[string]interface{}{"state": "firing", "labels": map[string]interface{}{"alertname": "CartErrors", "service_name": "cart", "severity": "warning"}},
+				map[string]interface{}{"state": "firing", "labels": map[string]interface{}{"alertname": "Other", "service_name": "checkout"}},
+			})
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.ServiceHealthHandler(context.Background(), map[string]interface{}{"service_name": "cart"})
+	if !result.Success {
+		t.Fatalf("expected success, got %+v", result.Error)
+	}
+
+	data := result.Data.(map[string]interface{})
+	// error rate 10% (-40), P95 +200% (-30), firing alert (-25)
+	if data["score"] != 5 || data["status"] != "unhealthy" {
+		t.Errorf("score = %v (%v), want 5 (unhealthy)", data["score"], data["status"])
+	}
+	penalties := map[string]int{}
+	for _, f := range data["factors"].([]HealthFactor) {
+		penalties[f.Name] = f.Penalty
+	}
+	want := map[string]int{"traffic": 0, "error_rate": 40, "latency": 30, "alerts": 25}
+	if fmt.Sprint(penalties) != fmt.Sprint(want) {
+		t.Errorf("penalties = %v, want %v", penalties, want)
+	}
+	if alerts := data["alerts"].([]HealthAlert); len(alerts) != 1 || alerts[0].Name != "CartErrors" {
+		t.Errorf("alerts = %+v, want only CartErrors", alerts)
+	}
+
+	var tools []string
+	for _, s := range data[followup.Key].([]followup.Suggestion) {
+		tools = append(tools, s.Tool)
+	}
+	if len(tools) != 3 {
+		t.Errorf("follow-ups = %v, want one per penalized factor", tools)
+	}
+	for _, s := range []string{"Service Health", "**5/100 (unhealthy)**", "| error rate | critical | -40 | 10.0% |"} {
+		if !strings.Contains(result.Markdown, s) {
+			t.Errorf("markdown missing %q:\n%s", s, result.Markdown)
+		}
+	}
+}
+
+func TestServiceHealthHandler_Unavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/spans" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		json.NewEncoder(w).Encode(spansResponse(nil, ""))
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.ServiceHealthHandler(context.Background(), map[string]interface{}{
+		"service_name": "cart",
+		"baseline":     "previous_period",
+	})
+	if !result.Success {
+		t.Fatalf("expected success, got %+v", result.Error)
+	}
+	data := result.Data.(map[string]interface{})
+	if data["score"] != 100 {
+		t.Errorf("score = %v, want 100 when nothing can be evaluated", data["score"])
+	}
+	for _, f := range data["factors"].([]HealthFactor) {
+		if f.Status != factorUnknown {
+			t.Errorf("factor %s = %s, want unknown", f.Name, f.Status)
+		}
+	}
+	if !strings.Contains(result.Markdown, "Could not read active alerts") {
+		t.Errorf("markdown does not explain the unknown alerts:\n%s", result.Markdown)
+	}
+}
+
+func TestServiceHealthHandler_Validation(t *testing.T) {
+	pkg := New(client.NewWithBaseURL("http://unused", "test-token"))
+	tests := []struct {
+		name        string
+		args        map[string]interface{}
+		expectError string
+	}{
+		{"missing service", map[string]interface{}{}, "service_name is required"},
+		{"negative range", map[string]interface{}{"service_name": "cart", "time_range_minutes": float64(-5)}, "time_range_minutes must not be negative"},
+		{"bad baseline", map[string]interface{}{"service_name": "cart", "baseline": "yesterday"}, "baseline must be"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := pkg.ServiceHealthHandler(context.Background(), tt.args)
+			if result.Success {
+				t.Fatal("expected failure")
+			}
+			if !strings.Contains(result.Error.Detail, tt.expectError) {
+				t.Errorf("error = %q, want it to contain %q", result.Error.Detail, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestSummarizeErrors(t *testing.T) {
+	failed := []spans.FlatSpan{
+		{TraceID: "t1", Name: "POST /charge", Attributes: map[string]interface{}{"http.route": "/charge", "http.response.status_code": int64(500), "exception.type": "TimeoutError", "exception.message": "upstream timed out"}},
+		{TraceID: "t2", Name: "POST /charge", Attributes: map[string]interface{}{"http.route": "/charge", "http.response.status_code": int64(500), "exception.type": "TimeoutError"}},
+		{TraceID: "t2", Name: "POST /charge", Attributes: map[string]interface{}{"http.route": "/charge", "http.response.status_code": int64(500), "exception.type": "TimeoutError"}},
+		{TraceID: "t3", Name: "SELECT orders", StatusMessage: "deadlock", Attributes: map[string]interface{}{"error.type": "DeadlockError"}},
+	}
+	errorLogs := []logs.FlatLog{
+		{TraceID: "t1", Body: "charge failed", Attributes: map[string]interface{}{"exception.type": "TimeoutError"}},
+		{Body: "disk full"},
+	}
+
+	s := summarizeErrors(failed, errorLogs)
+	if s.ErrorSpans != 4 || s.ErrorLogs != 2 {
+		t.Errorf("totals = %d spans, %d logs", s.ErrorSpans, s.ErrorLogs)
+	}
+
+	top := s.ByExceptionType[0]
+	if top.Value != "TimeoutError" || top.Spans != 3 || top.Logs != 1 || fmt.Sprint(top.TraceIDs) != "[t1 t2]" || top.Message != "upstream timed out" {
+		t.Errorf("top exception type = %+v", top)
+	}
+	if len(s.ByExceptionType) != 3 || s.ByExceptionType[1].Value != "DeadlockError" || s.ByExceptionType[1].Message != "deadlock" || s.ByExceptionType[2].Value != "(unset)" {
+		t.Errorf("exception types = %+v, want the error.type group, then the unset group, after TimeoutError", s.ByExceptionType)
+	}
+	if s.ByRoute[0].Value != "/charge" || s.ByRoute[0].Spans != 3 {
+		t.Errorf("routes = %+v", s.ByRoute)
+	}
+	var routes []string
+	for _, g := range s.ByRoute {
+		routes = append(routes, g.Value)
+	}
+	if fmt.Sprint(routes) != "[/charge (unset) SELECT orders]" || s.ByRoute[0].SpanName || !s.ByRoute[2].SpanName {
+		t.Errorf("routes = %+v, want the span name for spans without http.route", s.ByRoute)
+	}
+	if f := errorFollowUps("payment", summarizeErrors(failed[3:], nil), 15); len(f) != 1 || f[0].Arguments["span_name"] != "SELECT orders" {
+		t.Errorf("follow-ups = %+v, want a span_name query for a span-name route", f)
+	}
+	if s.ByStatusCode[0].Value != "500" || s.ByStatusCode[0].Spans != 3 {
+		t.Errorf("status codes = %+v", s.ByStatusCode)
+	}
+
+	var many []spans.FlatSpan
+	for i := 0; i < maxErrorGroups+5; i++ {
+		many = append(many, spans.FlatSpan{Name: fmt.Sprintf("op-%d", i)})
+	}
+	if s := summarizeErrors(many, nil); len(s.ByRoute) != maxErrorGroups || s.Groups["route"] != maxErrorGroups+5 {
+		t.Errorf("routes = %d of %d, want the largest %d", len(s.ByRoute), s.Groups["route"], maxErrorGroups)
+	}
+}
+
+func TestErrorsSummarizeHandler(t *testing.T) {
+	now := time.Now()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/spans":
+			var req spans.QuerySpansRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if len(req.Filter) != 2 || req.Filter[1].Key != "status.code" || *req.Filter[1].Value.IntValue != "2" {
+				t.Errorf("unexpected span filters: %+v", req.Filter)
+			}
+			span := spanJSON(1, 2, now.Add(-time.Minute), 100*time.Millisecond, true)
+			span["attributes"] = []interface{}{
+				map[string]interface{}{"key": "http.route", "value": map[string]interface{}{"stringValue": "/api/cart"}},
+				map[string]interface{}{"key": "http.response.status_code", "value": map[string]interface{}{"intValue": "503"}},
+			}
+			span["events"] = []interface{}{map[string]interface{}{"name": "exception", "attributes": []interface{}{
+				map[string]interface{}{"key": "exception.type", "value": map[string]interface{}{"stringValue": "RedisTimeout"}},
+			}}}
+			json.NewEncoder(w).Encode(spansResponse([]interface{}{span}, ""))
+		case "/api/logs":
+			record := func(severity int, body string) map[string]interface{} {
+				return map[string]interface{}{
+					"timeUnixNano":   fmt.Sprintf("%d", now.Add(-time.Minute).UnixNano()),
+					"severityNumber": float64(severity),
+					"body":           map[string]interface{}{"stringValue": body},
+					"traceId":        "trace-9",
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"resourceLogs": []interface{}{
+				map[string]interface{}{"scopeLogs": []interface{}{map[string]interface{}{"logRecords": []interface{}{
+					record(9, "cart loaded"),
+					record(17, "redis timeout"),
+				}}}},
+			}})
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.ErrorsSummarizeHandler(context.Background(), map[string]interface{}{"service_name": "cart", "time_range_minutes": float64(30)})
+	if !result.Success {
+		t.Fatalf("expected success, got %+v", result.Error)
+	}
+	data := result.Data.(map[string]interface{})
+	s := data["summary"].(ErrorSummary)
+	if s.ErrorSpans != 1 || s.ErrorLogs != 1 {
+		t.Errorf("totals = %d spans, %d logs; want 1 and 1 (the INFO log is dropped)", s.ErrorSpans, s.ErrorLogs)
+	}
+	if s.ByExceptionType[0].Value != "RedisTimeout" || s.ByStatusCode[0].Value != "503" {
+		t.Errorf("groups = %+v, %+v", s.ByExceptionType, s.ByStatusCode)
+	}
+
+	suggestions := data[followup.Key].([]followup.Suggestion)
+	if len(suggestions) != 2 || suggestions[0].Tool != "dash0_spans_query" || suggestions[1].Tool != "dash0_logs_query" {
+		t.Errorf("follow-ups = %+v", suggestions)
+	}
+	for _, want := range []string{"## Error Summary", "**1 failed spans**, **1 error logs**", "### By exception type", "| RedisTimeout | 1 | 0 | trace-1 |", "| /api/cart | 1 | 0 |"} {
+		if !strings.Contains(result.Markdown, want) {
+			t.Errorf("markdown missing %q:\n%s", want, result.Markdown)
+		}
+	}
+}
+
+func TestErrorsSummarizeHandler_Validation(t *testing.T) {
+	pkg := New(client.NewWithBaseURL("http://unused", "test-token"))
+	tests := []struct {
+		name        string
+		args        map[string]interface{}
+		expectError string
+	}{
+		{"missing service", map[string]interface{}{}, "service_name is required"},
+		{"negative range", map[string]interface{}{"service_name": "cart", "time_range_minutes": float64(-1)}, "time_range_minutes must not be negative"},
+		{"negative spans", map[string]interface{}{"service_name": "cart", "max_spans": float64(-1)}, "max_spans must not be negative"},
+		{"negative logs", map[string]interface{}{"service_name": "cart", "max_logs": float64(-1)}, "max_logs must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := pkg.ErrorsSummarizeHandler(context.Background(), tt.args)
+			if result.Success {
+				t.Fatal("expected failure")
+			}
+			if !strings.Contains(result.Error.Detail, tt.expectError) {
+				t.Errorf("error = %q, want it to contain %q", result.Error.Detail, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestWaitForHandler_ErrorRate(t *testing.T) {
+	waitUnit = time.Millisecond
+	defer func() { waitUnit = time.Second }()
+
+	now := time.Now().UTC()
+	var queries atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := queries.Add(1)
+		var list []interface{}
+		for i := 0; i < 20; i++ {
+			// Half the requests fail until the third check
+			list = append(list, spanJSON(i, 2, now.Add(-time.Minute), 50*time.Millisecond, n < 3 && i%2 == 0))
+		}
+		json.NewEncoder(w).Encode(spansResponse(list, ""))
+	}))
+	defer server.Close()
+
+	type update struct {
+		progress, total float64
+		message         string
+	}
+	var updates []update
+	ctx := progress.WithReporter(context.Background(), func(p, total float64, message string, _ interface{}) {
+		updates = append(updates, update{p, total, message})
+	})
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.WaitForHandler(ctx, map[string]interface{}{
+		"condition":        "error_rate_below",
+		"service_name":     "cart",
+		"threshold":        float64(5),
+		"interval_seconds": float64(5),
+	})
+	if !result.Success {
+		t.Fatalf("expected success, got %+v", result.Error)
+	}
+	r := result.Data.(WaitResult)
+	if !r.Met || r.Attempts != 3 || len(r.History) != 3 || r.History[0].Met || *r.History[0].Value != 50 {
+		t.Errorf("result = %+v, want met on the third check", r)
+	}
+	if len(updates) != 3 || updates[2].progress < updates[0].progress || updates[0].total <= 0 || !strings.Contains(updates[0].message, "check 1: error rate 50.00%") {
+		t.Errorf("progress updates = %+v", updates)
+	}
+	for _, s := range []string{"**Met:** error rate of cart below 5.00%", "after 3 checks"} {
+		if !strings.Contains(result.Markdown, s) {
+			t.Errorf("markdown missing %q:\n%s", s, result.Markdown)
+		}
+	}
+}
+
+func TestWaitForHandler_VersionVisible(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req spans.QuerySpansRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Filter) != 2 || req.Filter[1].Key != "service.version" || *req.Filter[1].Value.StringValue != "1.5.0" {
+			t.Errorf("unexpected filters: %+v", req.Filter)
+		}
+		json.NewEncoder(w).Encode(spansResponse([]interface{}{spanJSON(0, 2, time.Now(), time.Millisecond, false)}, ""))
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.WaitForHandler(context.Background(), map[string]interface{}{
+		"condition":    "version_visible",
+		"service_name": "cart",
+		"version":      "1.5.0",
+	})
+	if !result.Success || !result.Data.(WaitResult).Met || result.Data.(WaitResult).Attempts != 1 {
+		t.Errorf("result = %+v, want met on the first check", result)
+	}
+}
+
+func TestWaitForHandler_PermanentError(t *testing.T) {
+	var queries atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": "not found"}`))
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.WaitForHandler(context.Background(), map[string]interface{}{"condition": "version_visible", "service_name": "cart", "version": "1.5.0"})
+	if result.Success || result.Error.StatusCode != http.StatusNotFound || queries.Load() != 1 {
+		t.Errorf("result = %+v after %d queries, want the 404 at once", result, queries.Load())
+	}
+}
+
+func TestWaitForHandler_Validation(t *testing.T) {
+	pkg := New(client.NewWithBaseURL("http://localhost", "test-token"))
+	tests := map[string]map[string]interface{}{
+		"unknown condition": {"condition": "healthy", "service_name": "cart"},
+		"missing service":   {"condition": "error_rate_below", "threshold": float64(1)},
+		"missing threshold": {"condition": "p95_below", "service_name": "cart"},
+		"missing version":   {"condition": "version_visible", "service_name": "cart"},
+		"negative wait":     {"condition": "version_visible", "service_name": "cart", "version": "1", "wait_seconds": float64(-1)},
+		"negative interval": {"condition": "version_visible", "service_name": "cart", "version": "1", "interval_seconds": float64(-1)},
+		"negative window":   {"condition": "version_visible", "service_name": "cart", "version": "1", "window_minutes": float64(-1)},
+		"negative min_reqs": {"condition": "error_rate_below", "service_name": "cart", "threshold": float64(1), "min_requests": float64(-1)},
+	}
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			result := pkg.WaitForHandler(context.Background(), args)
+			if result.Success || result.Error.StatusCode != 400 {
+				t.Errorf("result = %+v, want a 400", result)
+			}
+		})
+	}
+}
+
+func TestCompareWindowsHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req spans.QuerySpansRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		to, _ := time.Parse(time.RFC3339, req.TimeRange.To)
+		if len(req.Filter) != 2 || req.Filter[1].Key != "name" {
+			t.Errorf("unexpected filters: %+v", req.Filter)
+		}
+
+		// The window after the deploy is slower and fails more often than
+		// the same hour yesterday.
+		var list []interface{}
+		baseline := time.Since(to) > 23*time.Hour
+		for i := 0; i < 100; i++ {
+			if baseline {
+				list = append(list, spanJSON(i, 2, to.Add(-time.Minute), time.Duration(100+i)*time.Millisecond, i == 0))
+			} else {
+				list = append(list, spanJSON(i, 2, to.Add(-time.Minute), time.Duration(200+i)*time.Millisecond, i%5 == 0))
+			}
+		}
+		json.NewEncoder(w).Encode(spansResponse(list, ""))
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.CompareWindowsHandler(context.Background(), map[string]interface{}{
+		"service_name": "cart",
+		"span_name":    "GET /cart",
+	})
+	if !result.Success {
+		t.Fatalf("expected success, got %+v", result.Error)
+	}
+	data := result.Data.(map[string]interface{})
+	r := data["result"].(WindowComparison)
+	if r.Verdict != "worse" || len(r.Findings) != 2 {
+		t.Errorf("verdict = %s, findings = %v; want worse on error rate and latency", r.Verdict, r.Findings)
+	}
+	if r.Baseline.Kind != "same_time_yesterday" || r.Current.Errors != 20 || r.Baseline.Signals.Errors != 1 {
+		t.Errorf("unexpected comparison: %+v", r)
+	}
+	if r.Baseline.Delta.ErrorRatePoints != 19 || r.LatencyTest == nil || r.LatencyTest.PValue > 0.05 {
+		t.Errorf("delta = %+v, latency test = %+v", r.Baseline.Delta, r.LatencyTest)
+	}
+
+	suggestions := data[followup.Key].([]followup.Suggestion)
+	if len(suggestions) != 2 || suggestions[0].Tool != "dash0_errors_summarize" || suggestions[1].Tool != "dash0_spans_query" {
+		t.Errorf("follow-ups = %+v", suggestions)
+	}
+	for _, s := range []string{"## Window Comparison", "**Verdict: WORSE**", "service=cart, name=GET /cart", "| Error rate | 20.00% (20) | 1.00% (1) | +19.0pp |"} {
+		if !strings.Contains(result.Markdown, s) {
+			t.Errorf("markdown missing %q:\n%s", s, result.Markdown)
+		}
+	}
+}
+
+func TestCompareWindowsHandler_Logs(t *testing.T) {
+	now := time.Now()
+	var mu sync.Mutex
+	var ends []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/logs" {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		var req logs.QueryLogsRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		ends = append(ends, req.TimeRange.To)
+		mu.Unlock()
+
+		var records []interface{}
+		for i := 0; i < 40; i++ {
+			severity := 9
+			if i < 2 {
+				severity = 17
+			}
+			records = append(records, map[string]interface{}{
+				"timeUnixNano":   fmt.Sprintf("%d", now.Add(-time.Minute).UnixNano()),
+				"severityNumber": float64(severity),
+				"body":           map[string]interface{}{"stringValue": "cart loaded"},
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"resourceLogs": []interface{}{
+			map[string]interface{}{"scopeLogs": []interface{}{map[string]interface{}{"logRecords": records}}},
+		}})
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.CompareWindowsHandler(context.Background(), map[string]interface{}{
+		"signal":         "logs",
+		"k8s_namespace":  "shop",
+		"window_minutes": float64(30),
+		"window_end":     "2024-05-01T15:00:00Z",
+		"baseline_end":   "2024-05-01T14:30:00Z",
+	})
+	if !result.Success {
+		t.Fatalf("expected success, got %+v", result.Error)
+	}
+	r := result.Data.(map[string]interface{})["result"].(WindowComparison)
+	if r.Verdict != "unchanged" || r.Current.Requests != 40 || r.Current.Errors != 2 || r.LatencyTest != nil {
+		t.Errorf("unexpected comparison: %+v", r)
+	}
+	if r.From != "2024-05-01T14:30:00Z" || r.Baseline.Kind != "custom" || r.Baseline.From != "2024-05-01T14:00:00Z" || len(ends) != 2 {
+		t.Errorf("windows: %s, baseline %+v, queried up to %v", r.From, r.Baseline, ends)
+	}
+	if strings.Contains(result.Markdown, "| P95 |") {
+		t.Errorf("log comparison should not list latency:\n%s", result.Markdown)
+	}
+}
+
+func TestCompareWindowsHandler_Inconclusive(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(spansResponse([]interface{}{spanJSON(1, 2, time.Now(), time.Second, true)}, ""))
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.CompareWindowsHandler(context.Background(), map[string]interface{}{"service_name": "cart", "baseline": "previous_period"})
+	r := result.Data.(map[string]interface{})["result"].(WindowComparison)
+	if r.Verdict != "inconclusive" || !strings.Contains(r.Findings[0], "Not enough requests") {
+		t.Errorf("verdict = %s, findings = %v; want inconclusive", r.Verdict, r.Findings)
+	}
+}
+
+func TestCompareWindowsHandler_Validation(t *testing.T) {
+	pkg := New(client.NewWithBaseURL("http://localhost", "test-token"))
+	tests := map[string]map[string]interface{}{
+		"unknown signal":         {"signal": "metrics"},
+		"span filter on logs":    {"signal": "logs", "span_name": "GET /cart"},
+		"log filter on spans":    {"k8s_container_name": "server"},
+		"negative window":        {"window_minutes": float64(-1)},
+		"bad window_end":         {"window_end": "yesterday"},
+		"future window_end":      {"window_end": time.Now().Add(time.Hour).Format(time.RFC3339)},
+		"unknown baseline":       {"baseline": "last_month"},
+		"baseline and its end":   {"baseline": "previous_period", "baseline_end": "2h"},
+		"baseline_end in window": {"baseline_end": "10m"},
+		"bad significance":       {"significance": float64(1)},
+		"negative max_logs":      {"signal": "logs", "max_logs": float64(-1)},
+	}
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			result := pkg.CompareWindowsHandler(context.Background(), args)
+			if result.Success || result.Error.StatusCode != 400 {
+				t.Errorf("result = %+v, want a 400", result)
+			}
+		})
+	}
+}
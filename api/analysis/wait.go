@@ -0,0 +1,183 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+	"github.com/npcomplete777/dash0-mcp/internal/otlp"
+)
+
+// Conditions of dash0_wait_for.
+const (
+	conditionErrorRateBelow = "error_rate_below"
+	conditionP95Below       = "p95_below"
+	conditionVersionVisible = "version_visible"
+)
+
+var waitConditions = []string{conditionErrorRateBelow, conditionP95Below, conditionVersionVisible}
+
+const (
+	defaultWaitSeconds     = 300
+	maxWaitSeconds         = 3600
+	defaultWaitInterval    = 30
+	minWaitInterval        = 5
+	defaultWaitWindow      = 5
+	maxWaitWindow          = 60
+	defaultWaitMinRequests = 10
+
+	// waitMaxSpans bounds the spans read per evaluation.
+	waitMaxSpans = 1000
+	// waitHistory is how many evaluations the result lists.
+	waitHistory = 20
+	// waitDeadlineMargin is kept free before the call's deadline, so the
+	// wait ends with a result rather than a timeout error.
+	waitDeadlineMargin = 2 * time.Second
+)
+
+// waitUnit is the unit of interval_seconds and wait_seconds; tests shorten it.
+var waitUnit = time.Second
+
+// WaitCondition is a condition dash0_wait_for polls.
+type WaitCondition struct {
+	Kind        string  `json:"condition"`
+	ServiceName string  `json:"service_name,omitempty"`
+	Threshold   float64 `json:"threshold,omitempty"`
+	Version     string  `json:"version,omitempty"`
+	// WindowMinutes is the look-back window of each evaluation.
+	WindowMinutes int `json:"window_minutes"`
+	// MinRequests is the traffic an error rate or latency needs to count.
+	MinRequests int `json:"min_requests,omitempty"`
+}
+
+// String describes the condition.
+func (c WaitCondition) String() string {
+	switch c.Kind {
+	case conditionErrorRateBelow:
+		return fmt.Sprintf("error rate of %s below %.2f%% over the last %dm", c.ServiceName, c.Threshold, c.WindowMinutes)
+	case conditionP95Below:
+		return fmt.Sprintf("P95 latency of %s below %s over the last %dm", c.ServiceName, formatter.FormatDuration(c.Threshold), c.WindowMinutes)
+	case conditionVersionVisible:
+		return fmt.Sprintf("spans of %s version %s in the last %dm", c.ServiceName, c.Version, c.WindowMinutes)
+	}
+	return c.Kind
+}
+
+// WaitObservation is one evaluation of the condition.
+type WaitObservation struct {
+	At  string `json:"at"`
+	Met bool   `json:"met"`
+	// Value is the measured error rate (%), P95 (ms), or number of spans.
+	Value  *float64 `json:"value,omitempty"`
+	Detail string   `json:"detail"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// WaitResult is the result of dash0_wait_for.
+type WaitResult struct {
+	Condition      WaitCondition     `json:"condition"`
+	Met            bool              `json:"met"`
+	Attempts       int               `json:"attempts"`
+	ElapsedSeconds float64           `json:"elapsed_seconds"`
+	WaitSeconds    int               `json:"wait_seconds"`
+	Last           WaitObservation   `json:"last"`
+	History        []WaitObservation `json:"history"`
+}
+
+// evaluate checks the condition once. Failed API requests are returned for
+// the caller to decide whether to keep polling.
+func (c WaitCondition) evaluate(ctx context.Context, cl *client.Client, dataset string, now time.Time) (WaitObservation, *client.ToolResult) {
+	obs := WaitObservation{At: now.Format(time.RFC3339)}
+	from := now.Add(-time.Duration(c.WindowMinutes) * time.Minute)
+
+	switch c.Kind {
+	case conditionErrorRateBelow, conditionP95Below:
+		sample, errResult := fetchSpans(ctx, cl, dataset, []otlp.AttributeFilter{serviceFilter(c.ServiceName)}, from, now, waitMaxSpans)
+		if errResult != nil {
+			return obs, errResult
+		}
+		entry, _ := entrySpans(sample.Spans)
+		sig := computeSignals(entry, now.Sub(from), sample.Sampled)
+		if sig.Requests < c.MinRequests {
+			obs.Detail = fmt.Sprintf("%d requests, fewer than the %d needed", sig.Requests, c.MinRequests)
+			return obs, nil
+		}
+		if c.Kind == conditionErrorRateBelow {
+			obs.Value = &sig.ErrorRate
+			obs.Met = sig.ErrorRate < c.Threshold
+			obs.Detail = fmt.Sprintf("error rate %.2f%% (%d of %d requests)", sig.ErrorRate, sig.Errors, sig.Requests)
+		} else {
+			obs.Value = &sig.P95Ms
+			obs.Met = sig.P95Ms < c.Threshold
+			obs.Detail = fmt.Sprintf("P95 %s over %d requests", formatter.FormatDuration(sig.P95Ms), sig.Requests)
+		}
+
+	case conditionVersionVisible:
+		version := c.Version
+		filters := []otlp.AttributeFilter{serviceFilter(c.ServiceName), {
+			Key:      "service.version",
+			Operator: "is",
+			Value:    &otlp.AttributeFilterValue{StringValue: &version},
+		}}
+		sample, errResult := fetchSpans(ctx, cl, dataset, filters, from, now, 1)
+		if errResult != nil {
+			return obs, errResult
+		}
+		n := float64(len(sample.Spans))
+		obs.Value = &n
+		obs.Met = len(sample.Spans) > 0
+		if obs.Met {
+			obs.Detail = fmt.Sprintf("version %s is reporting spans", c.Version)
+		} else {
+			obs.Detail = fmt.Sprintf("no spans of version %s yet", c.Version)
+		}
+	}
+	return obs, nil
+}
+
+// permanentError reports whether a failed evaluation will not succeed when
+// retried: a client error other than a rate limit.
+func permanentError(r *client.ToolResult) bool {
+	if r.Error == nil {
+		return false
+	}
+	code := r.Error.StatusCode
+	return code >= 400 && code < 500 && code != http.StatusTooManyRequests && code != http.StatusRequestTimeout
+}
+
+// formatWaitResult renders the outcome and the latest evaluations.
+func formatWaitResult(r WaitResult) string {
+	var b strings.Builder
+	b.WriteString("## Wait For Condition\n\n")
+	if r.Met {
+		fmt.Fprintf(&b, "**Met:** %s, after %d checks (%.0fs).\n\n", r.Condition, r.Attempts, r.ElapsedSeconds)
+	} else {
+		fmt.Fprintf(&b, "**Not met:** %s, after %d checks over %.0fs (waited up to %ds).\n\n", r.Condition, r.Attempts, r.ElapsedSeconds, r.WaitSeconds)
+	}
+
+	rows := make([][]string, len(r.History))
+	for i, obs := range r.History {
+		met := "no"
+		if obs.Met {
+			met = "yes"
+		}
+		detail := obs.Detail
+		if obs.Error != "" {
+			met, detail = "error", obs.Error
+		}
+		rows[i] = []string{obs.At, met, formatter.Truncate(detail, 100)}
+	}
+	footer := ""
+	if r.Attempts > len(r.History) {
+		footer = fmt.Sprintf("Latest %d of %d checks.", len(r.History), r.Attempts)
+	}
+	b.WriteString(formatter.Table("", "", []string{"Checked", "Met", "Detail"}, rows, footer))
+	if !r.Met {
+		b.WriteString("\n> Call again to keep waiting, or investigate with dash0_errors_summarize or dash0_golden_signals.\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,218 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/api/logs"
+	"github.com/npcomplete777/dash0-mcp/api/spans"
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/followup"
+	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+)
+
+// Signals dash0_compare_windows can compare.
+const (
+	windowSignalSpans = "spans"
+	windowSignalLogs  = "logs"
+)
+
+// Verdicts of dash0_compare_windows.
+const (
+	verdictWorse        = "worse"
+	verdictBetter       = "better"
+	verdictUnchanged    = "unchanged"
+	verdictInconclusive = "inconclusive"
+)
+
+const (
+	defaultWindowMinutes = 60
+	defaultWindowMaxLogs = 5000
+	maxWindowMaxLogs     = 20000
+
+	// baselineCustom is the baseline kind of an explicit baseline_end.
+	baselineCustom = "custom"
+	// volumeChangePct is the change of volume reported as a finding.
+	volumeChangePct = 50.0
+)
+
+// windowBaselines are the values of dash0_compare_windows' baseline argument.
+var windowBaselines = []string{baselinePreviousPeriod, baselineYesterday, baselineLastWeek}
+
+// windowFilterArgs are the filter arguments each signal accepts besides
+// service_name, the Kubernetes namespace, deployment, and pod, and
+// attribute_filters.
+var windowFilterArgs = map[string][]string{
+	windowSignalSpans: {"span_name", "span_name_match", "service_name_match", "http_method", "http_status_code"},
+	windowSignalLogs:  {"k8s_container_name"},
+}
+
+// WindowComparison is the result of dash0_compare_windows.
+type WindowComparison struct {
+	Signal  string   `json:"signal"`
+	Filters []string `json:"filters,omitempty"`
+	From    string   `json:"from"`
+	To      string   `json:"to"`
+	// Current holds the signals of the window. For logs, requests counts the
+	// logs and errors those of ERROR severity or above; latencies are zero.
+	Current  Signals             `json:"current"`
+	Baseline *BaselineComparison `json:"baseline"`
+	// ErrorRateTest and LatencyTest test whether the window is worse than the
+	// baseline; LatencyTest is only set for spans.
+	ErrorRateTest TestResult  `json:"error_rate_test"`
+	LatencyTest   *TestResult `json:"latency_test,omitempty"`
+	Significance  float64     `json:"significance"`
+	Verdict       string      `json:"verdict"`
+	Findings      []string    `json:"findings"`
+}
+
+// parseTimeArg parses an RFC 3339 timestamp or a duration ago such as 30m.
+func parseTimeArg(name, raw string, now time.Time) (time.Time, *client.ToolResult) {
+	raw = strings.TrimSpace(raw)
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.UTC(), nil
+	}
+	if d, err := time.ParseDuration(raw); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, client.ErrorResult(400, fmt.Sprintf("%s must be an RFC 3339 timestamp such as 2024-05-01T14:30:00Z, or a duration ago such as 30m", name))
+}
+
+// logSignals derives volume and error share from logs read over window.
+// sampled indicates the logs are a capped subset of the window.
+func logSignals(ll []logs.FlatLog, window time.Duration, sampled bool) Signals {
+	sig := Signals{Requests: len(ll), Sampled: sampled}
+	if len(ll) == 0 {
+		return sig
+	}
+	for _, l := range ll {
+		if level := logs.SeverityLevel(l); level == "ERROR" || level == "FATAL" {
+			sig.Errors++
+		}
+	}
+	sig.ErrorRate = float64(sig.Errors) / float64(len(ll)) * 100
+	if window > 0 {
+		sig.RatePerSecond = float64(len(ll)) / window.Seconds()
+	}
+	return sig
+}
+
+// spanDurations returns the durations of ss.
+func spanDurations(ss []spans.FlatSpan) []float64 {
+	durations := make([]float64, len(ss))
+	for i, s := range ss {
+		durations[i] = s.DurationMs
+	}
+	return durations
+}
+
+// assessWindows sets the verdict and findings of r. base and current are the
+// span durations of both windows, nil for logs.
+func assessWindows(r *WindowComparison, base, current []float64, minCount int) {
+	cur, prev := r.Current, r.Baseline.Signals
+	noun := "requests"
+	if r.Signal == windowSignalLogs {
+		noun = "logs"
+	}
+
+	if pct := r.Baseline.Delta.RequestsPct; pct != nil && math.Abs(*pct) >= volumeChangePct {
+		r.Findings = append(r.Findings, fmt.Sprintf("Volume changed by %s: %d %s vs %d.", formatPct(pct), cur.Requests, noun, prev.Requests))
+	} else if prev.Requests == 0 && cur.Requests > 0 {
+		r.Findings = append(r.Findings, fmt.Sprintf("%d %s, none in the baseline.", cur.Requests, noun))
+	}
+	if cur.Requests < minCount || prev.Requests < minCount {
+		r.Verdict = verdictInconclusive
+		r.Findings = append(r.Findings, fmt.Sprintf("Not enough %s to compare error rate or latency (window %d, baseline %d, need %d each).", noun, cur.Requests, prev.Requests, minCount))
+		return
+	}
+
+	alpha := r.Significance
+	var worse, better bool
+	if r.ErrorRateTest.PValue < alpha {
+		worse = true
+		r.Findings = append(r.Findings, fmt.Sprintf("Error rate is significantly higher: %.2f%% vs %.2f%% (p=%.4f).", cur.ErrorRate, prev.ErrorRate, r.ErrorRateTest.PValue))
+	} else if twoProportionZTest(cur.Errors, cur.Requests, prev.Errors, prev.Requests).PValue < alpha {
+		better = true
+		r.Findings = append(r.Findings, fmt.Sprintf("Error rate is significantly lower: %.2f%% vs %.2f%%.", cur.ErrorRate, prev.ErrorRate))
+	}
+
+	if r.LatencyTest != nil {
+		p95 := r.Baseline.Delta.P95Pct
+		if r.LatencyTest.PValue < alpha && p95 != nil && *p95 > defaultMaxLatencyIncreasePct {
+			worse = true
+			r.Findings = append(r.Findings, fmt.Sprintf("Latency is significantly higher: P95 %s vs %s (%s, p=%.4f).",
+				formatter.FormatDuration(cur.P95Ms), formatter.FormatDuration(prev.P95Ms), formatPct(p95), r.LatencyTest.PValue))
+		} else if p95 != nil && *p95 < -defaultMaxLatencyIncreasePct && mannWhitneyU(current, base).PValue < alpha {
+			better = true
+			r.Findings = append(r.Findings, fmt.Sprintf("Latency is significantly lower: P95 %s vs %s (%s).",
+				formatter.FormatDuration(cur.P95Ms), formatter.FormatDuration(prev.P95Ms), formatPct(p95)))
+		}
+	}
+
+	switch {
+	case worse:
+		r.Verdict = verdictWorse
+	case better:
+		r.Verdict = verdictBetter
+	default:
+		r.Verdict = verdictUnchanged
+		r.Findings = append(r.Findings, fmt.Sprintf("No significant change of error rate or latency at p<%.2g.", alpha))
+	}
+}
+
+// windowFollowUps suggests drill-downs into a regression of the service.
+func windowFollowUps(r WindowComparison, serviceName string, minutes int) []followup.Suggestion {
+	suggestions := []followup.Suggestion{}
+	if serviceName == "" || r.Verdict != verdictWorse {
+		return suggestions
+	}
+	if r.ErrorRateTest.PValue < r.Significance {
+		suggestions = append(suggestions, followup.New("dash0_errors_summarize", map[string]interface{}{
+			"service_name":       serviceName,
+			"time_range_minutes": minutes,
+		}, fmt.Sprintf("The error rate rose from %.2f%% to %.2f%%; see what is failing.", r.Baseline.Signals.ErrorRate, r.Current.ErrorRate)))
+	}
+	if p95 := r.Baseline.Delta.P95Pct; r.LatencyTest != nil && r.LatencyTest.PValue < r.Significance && p95 != nil && *p95 > defaultMaxLatencyIncreasePct {
+		suggestions = append(suggestions, followup.New("dash0_spans_query", map[string]interface{}{
+			"service_name":       serviceName,
+			"min_duration_ms":    math.Round(r.Baseline.Signals.P95Ms),
+			"time_range_minutes": minutes,
+		}, "Latency rose; find the requests slower than the baseline P95."))
+	}
+	return suggestions
+}
+
+// formatWindowComparison renders both windows side by side with the change.
+func formatWindowComparison(r WindowComparison, maxItems int) string {
+	b := r.Baseline
+	count := func(s Signals) string { return fmt.Sprintf("%d", s.Requests) }
+	rows := [][]string{
+		{"Volume", count(r.Current), count(b.Signals), formatPct(b.Delta.RequestsPct)},
+		{"Rate", fmt.Sprintf("%.2f/s", r.Current.RatePerSecond), fmt.Sprintf("%.2f/s", b.Signals.RatePerSecond), formatPct(b.Delta.RatePct)},
+		{"Error rate",
+			fmt.Sprintf("%.2f%% (%d)", r.Current.ErrorRate, r.Current.Errors),
+			fmt.Sprintf("%.2f%% (%d)", b.Signals.ErrorRate, b.Signals.Errors),
+			formatPoints(b.Delta.ErrorRatePoints)},
+	}
+	if r.Signal == windowSignalSpans {
+		rows = append(rows,
+			[]string{"P50", formatter.FormatDuration(r.Current.P50Ms), formatter.FormatDuration(b.Signals.P50Ms), formatPct(b.Delta.P50Pct)},
+			[]string{"P95", formatter.FormatDuration(r.Current.P95Ms), formatter.FormatDuration(b.Signals.P95Ms), formatPct(b.Delta.P95Pct)},
+			[]string{"P99", formatter.FormatDuration(r.Current.P99Ms), formatter.FormatDuration(b.Signals.P99Ms), formatPct(b.Delta.P99Pct)},
+		)
+	}
+
+	filters := "no filters"
+	if len(r.Filters) > 0 {
+		filters = strings.Join(r.Filters, ", ")
+	}
+	summary := fmt.Sprintf("**Verdict: %s**\n\n%s · %s · Window %s to %s · Baseline %s to %s",
+		strings.ToUpper(r.Verdict), r.Signal, filters, r.From, r.To, b.From, b.To)
+	footer := "> " + strings.Join(r.Findings, " ")
+	if r.Current.Sampled || b.Signals.Sampled {
+		footer += fmt.Sprintf("\n\n_At least one window matched more than %d %s; its statistics cover the ones read._", maxItems, r.Signal)
+	}
+	return formatter.Table("Window Comparison", summary, []string{"Metric", "Window", "Baseline", "Change"}, rows, footer)
+}
@@ -0,0 +1,322 @@
+package dashboards
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/npcomplete777/dash0-mcp/internal/canonical"
+	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+)
+
+// Change operations.
+const (
+	opAdded   = "added"
+	opRemoved = "removed"
+	opChanged = "changed"
+)
+
+// Change is one field that differs between two dashboards.
+type Change struct {
+	// Path is the JSON pointer of the field, relative to the dashboard or,
+	// for panel changes, to the panel.
+	Path   string      `json:"path"`
+	Op     string      `json:"op"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// PanelChange lists the changed fields of a panel that is in both
+// dashboards.
+type PanelChange struct {
+	Panel   string   `json:"panel"`
+	Changes []Change `json:"changes"`
+}
+
+// PanelDiff is the panel part of a dashboard diff. Panels are matched by
+// their key when spec.panels is an object, and by display name when it is
+// an array.
+type PanelDiff struct {
+	Added   []string      `json:"added"`
+	Removed []string      `json:"removed"`
+	Changed []PanelChange `json:"changed"`
+}
+
+// Diff is the difference between a current and a proposed dashboard.
+// Server-maintained fields, key order, and number formatting are ignored.
+type Diff struct {
+	Identical bool `json:"identical"`
+	// Metadata are the changes outside spec: kind and metadata.
+	Metadata []Change `json:"metadata"`
+	// Spec are the changes in spec other than the panels, e.g. the display
+	// name, layouts, variables, or duration.
+	Spec   []Change  `json:"spec"`
+	Panels PanelDiff `json:"panels"`
+}
+
+// diffDashboards compares the current dashboard with a proposed body.
+func diffDashboards(current, proposed interface{}) (*Diff, error) {
+	a, err := canonical.Canonicalize(current)
+	if err != nil {
+		return nil, err
+	}
+	b, err := canonical.Canonicalize(proposed)
+	if err != nil {
+		return nil, err
+	}
+	dropAssignedLabels(a, b)
+
+	d := &Diff{Metadata: []Change{}, Spec: []Change{}, Panels: PanelDiff{Added: []string{}, Removed: []string{}, Changed: []PanelChange{}}}
+	var changes []Change
+	diffValues(withoutPanels(a), withoutPanels(b), "", &changes)
+	for _, c := range changes {
+		if c.Path == "/spec" || strings.HasPrefix(c.Path, "/spec/") {
+			d.Spec = append(d.Spec, c)
+		} else {
+			d.Metadata = append(d.Metadata, c)
+		}
+	}
+
+	before, beforeKeys := panelsByKey(a)
+	after, afterKeys := panelsByKey(b)
+	for _, key := range beforeKeys {
+		if _, ok := after[key]; !ok {
+			d.Panels.Removed = append(d.Panels.Removed, key)
+		}
+	}
+	for _, key := range afterKeys {
+		old, ok := before[key]
+		if !ok {
+			d.Panels.Added = append(d.Panels.Added, key)
+			continue
+		}
+		var panelChanges []Change
+		diffValues(old, after[key], "", &panelChanges)
+		if len(panelChanges) > 0 {
+			d.Panels.Changed = append(d.Panels.Changed, PanelChange{Panel: key, Changes: panelChanges})
+		}
+	}
+
+	d.Identical = len(d.Metadata) == 0 && len(d.Spec) == 0 &&
+		len(d.Panels.Added) == 0 && len(d.Panels.Removed) == 0 && len(d.Panels.Changed) == 0
+	return d, nil
+}
+
+// diffValues appends the changes from a to b at path to out. Objects are
+// compared key by key and arrays index by index; other values as a whole.
+func diffValues(a, b interface{}, path string, out *[]Change) {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make(map[string]bool, len(av)+len(bv))
+		for k := range av {
+			keys[k] = true
+		}
+		for k := range bv {
+			keys[k] = true
+		}
+		for _, k := range sortedKeys(keys) {
+			p := path + "/" + escapePointer(k)
+			before, inA := av[k]
+			after, inB := bv[k]
+			switch {
+			case !inA:
+				*out = append(*out, Change{Path: p, Op: opAdded, After: after})
+			case !inB:
+				*out = append(*out, Change{Path: p, Op: opRemoved, Before: before})
+			default:
+				diffValues(before, after, p, out)
+			}
+		}
+		return
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(av) || i < len(bv); i++ {
+			p := fmt.Sprintf("%s/%d", path, i)
+			switch {
+			case i >= len(av):
+				*out = append(*out, Change{Path: p, Op: opAdded, After: bv[i]})
+			case i >= len(bv):
+				*out = append(*out, Change{Path: p, Op: opRemoved, Before: av[i]})
+			default:
+				diffValues(av[i], bv[i], p, out)
+			}
+		}
+		return
+	}
+	if !canonical.Equal(a, b) {
+		if path == "" {
+			path = "/"
+		}
+		*out = append(*out, Change{Path: path, Op: opChanged, Before: a, After: b})
+	}
+}
+
+// dropAssignedLabels removes the dash0.com/ labels of the current dashboard
+// that the proposed body leaves out, such as dash0.com/origin: Dash0 assigns
+// them from the request, so leaving them out does not remove them.
+func dropAssignedLabels(current, proposed interface{}) {
+	labels := func(v interface{}) map[string]interface{} {
+		m, _ := v.(map[string]interface{})
+		meta, _ := m["metadata"].(map[string]interface{})
+		l, _ := meta["labels"].(map[string]interface{})
+		return l
+	}
+	cur, prop := labels(current), labels(proposed)
+	if cur == nil {
+		return
+	}
+	for k := range cur {
+		if _, ok := prop[k]; !ok && strings.HasPrefix(k, "dash0.com/") {
+			delete(cur, k)
+		}
+	}
+	if len(cur) == 0 && prop == nil {
+		delete(current.(map[string]interface{})["metadata"].(map[string]interface{}), "labels")
+	}
+}
+
+// withoutPanels returns a shallow copy of a dashboard without spec.panels.
+func withoutPanels(dashboard interface{}) interface{} {
+	m, ok := dashboard.(map[string]interface{})
+	if !ok {
+		return dashboard
+	}
+	spec, ok := m["spec"].(map[string]interface{})
+	if !ok {
+		return dashboard
+	}
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	specCopy := make(map[string]interface{}, len(spec))
+	for k, v := range spec {
+		if k != "panels" {
+			specCopy[k] = v
+		}
+	}
+	out["spec"] = specCopy
+	return out
+}
+
+// panelsByKey returns the panels of a dashboard by key, and the keys in
+// dashboard order. An object of panels is keyed by its keys; an array by
+// the panels' display names, numbered when names repeat, or by their index
+// when a panel has no name.
+func panelsByKey(dashboard interface{}) (map[string]interface{}, []string) {
+	panels := map[string]interface{}{}
+	var keys []string
+	m, _ := dashboard.(map[string]interface{})
+	spec, _ := m["spec"].(map[string]interface{})
+	switch list := spec["panels"].(type) {
+	case map[string]interface{}:
+		for k, v := range list {
+			panels[k] = v
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+	case []interface{}:
+		seen := map[string]int{}
+		for i, v := range list {
+			key := panelName(v)
+			if key == "" {
+				key = fmt.Sprintf("#%d", i)
+			}
+			if seen[key]++; seen[key] > 1 {
+				key = fmt.Sprintf("%s (%d)", key, seen[key])
+			}
+			panels[key] = v
+			keys = append(keys, key)
+		}
+	}
+	return panels, keys
+}
+
+// panelName returns spec.display.name of a panel.
+func panelName(panel interface{}) string {
+	m, _ := panel.(map[string]interface{})
+	spec, _ := m["spec"].(map[string]interface{})
+	display, _ := spec["display"].(map[string]interface{})
+	name, _ := display["name"].(string)
+	return name
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// escapePointer escapes a key for a JSON pointer (RFC 6901).
+func escapePointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
+
+// maxDiffValueLen bounds a value rendered in the markdown diff.
+const maxDiffValueLen = 80
+
+// formatDiff renders a dashboard diff as markdown.
+func formatDiff(name string, d *Diff) string {
+	title := "Dashboard Diff"
+	if name != "" {
+		title += ": " + name
+	}
+	if d.Identical {
+		return formatter.Table(title, "No changes: the proposed body matches the current dashboard.", nil, nil, "")
+	}
+
+	summary := fmt.Sprintf("%d panels added, %d removed, %d changed; %d other fields changed",
+		len(d.Panels.Added), len(d.Panels.Removed), len(d.Panels.Changed), len(d.Metadata)+len(d.Spec))
+	var rows [][]string
+	for _, key := range d.Panels.Added {
+		rows = append(rows, []string{key, opAdded, ""})
+	}
+	for _, key := range d.Panels.Removed {
+		rows = append(rows, []string{key, opRemoved, ""})
+	}
+	for _, pc := range d.Panels.Changed {
+		paths := make([]string, len(pc.Changes))
+		for i, c := range pc.Changes {
+			paths[i] = "`" + c.Path + "`"
+		}
+		rows = append(rows, []string{pc.Panel, opChanged, strings.Join(paths, ", ")})
+	}
+	markdown := formatter.Table(title, summary, []string{"Panel", "Change", "Fields"}, rows, "")
+
+	fields := append(append([]Change{}, d.Metadata...), d.Spec...)
+	if len(fields) > 0 {
+		var fieldRows [][]string
+		for _, c := range fields {
+			fieldRows = append(fieldRows, []string{"`" + c.Path + "`", c.Op, diffValue(c.Before), diffValue(c.After)})
+		}
+		markdown += "\n" + formatter.Table("Other Changes", "", []string{"Field", "Change", "Before", "After"}, fieldRows, "")
+	}
+	return markdown
+}
+
+// diffValue renders a changed value as short JSON.
+func diffValue(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	s := []rune(string(data))
+	if len(s) > maxDiffValueLen {
+		s = append(s[:maxDiffValueLen-1], '…')
+	}
+	return "`" + string(s) + "`"
+}
@@ -1,3 +1,5 @@
 // Package dashboards provides MCP tools for Dash0 dashboard operations.
-// This package enables creating, retrieving, updating, and deleting dashboards.
+// This package enables creating, retrieving, updating, and deleting dashboards,
+// reviewing an update as a diff against the current dashboard, and adding
+// panels from templates.
 package dashboards
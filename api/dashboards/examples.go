@@ -0,0 +1,115 @@
+package dashboards
+
+import "github.com/npcomplete777/dash0-mcp/internal/registry"
+
+// Compile-time interface check.
+var _ registry.ExampleProvider = (*Tools)(nil)
+
+// requestRatePanel is a time series panel charting an HTTP request rate.
+var requestRatePanel = map[string]interface{}{
+	"kind": "Panel",
+	"spec": map[string]interface{}{
+		"display": map[string]interface{}{"name": "Request Rate"},
+		"plugin": map[string]interface{}{
+			"kind": "TimeSeriesChart",
+			"spec": map[string]interface{}{"legend": map[string]interface{}{"position": "bottom"}},
+		},
+		"queries": []interface{}{
+			map[string]interface{}{
+				"kind": "TimeSeriesQuery",
+				"spec": map[string]interface{}{
+					"plugin": map[string]interface{}{
+						"kind": "PrometheusTimeSeriesQuery",
+						"spec": map[string]interface{}{"query": "sum by (service_name) (rate(http_requests_total[5m]))"},
+					},
+				},
+			},
+		},
+	},
+}
+
+// Examples returns example invocations for the dashboard tools.
+func (p *Tools) Examples() map[string][]registry.Example {
+	return map[string][]registry.Example{
+		"dash0_dashboards_list": {
+			{Title: "List all dashboards", Arguments: map[string]interface{}{}},
+		},
+		"dash0_dashboards_get": {
+			{Title: "Get a dashboard by origin", Arguments: map[string]interface{}{"origin_or_id": "api-metrics"}},
+		},
+		"dash0_dashboards_create": {
+			{
+				Title: "Create an empty dashboard",
+				Arguments: map[string]interface{}{
+					"body": map[string]interface{}{
+						"kind":     "PersesDashboard",
+						"metadata": map[string]interface{}{"name": "my-service-dashboard"},
+						"spec": map[string]interface{}{
+							"display": map[string]interface{}{"name": "My Service Dashboard"},
+							"panels":  []interface{}{},
+						},
+					},
+				},
+			},
+			{
+				Title: "Create a dashboard with a request rate chart",
+				Arguments: map[string]interface{}{
+					"body": map[string]interface{}{
+						"kind":     "PersesDashboard",
+						"metadata": map[string]interface{}{"name": "api-metrics"},
+						"spec": map[string]interface{}{
+							"display": map[string]interface{}{"name": "API Metrics"},
+							"panels":  []interface{}{requestRatePanel},
+						},
+					},
+				},
+			},
+		},
+		"dash0_dashboards_update": {
+			{
+				Title: "Rename a dashboard",
+				Arguments: map[string]interface{}{
+					"origin_or_id": "api-metrics",
+					"body": map[string]interface{}{
+						"kind":     "PersesDashboard",
+						"metadata": map[string]interface{}{"name": "api-metrics"},
+						"spec": map[string]interface{}{
+							"display": map[string]interface{}{"name": "API Metrics (production)"},
+							"panels":  []interface{}{requestRatePanel},
+						},
+					},
+				},
+			},
+		},
+		"dash0_dashboards_delete": {
+			{Title: "Delete a dashboard", Arguments: map[string]interface{}{"origin_or_id": "my-service-dashboard"}},
+		},
+		"dash0_dashboards_add_panel": {
+			{Title: "List the panel templates", Arguments: map[string]interface{}{"origin_or_id": "api-metrics"}},
+			{
+				Title: "Add a p95 latency chart for a service",
+				Arguments: map[string]interface{}{
+					"origin_or_id": "api-metrics",
+					"template":     "p95-latency",
+					"variables":    map[string]interface{}{"service_name": "checkout", "window": "10m"},
+				},
+			},
+		},
+		"dash0_dashboards_diff": {
+			{
+				Title: "Review a rename before updating",
+				Arguments: map[string]interface{}{
+					"origin_or_id": "api-metrics",
+					"body": map[string]interface{}{
+						"kind":     "PersesDashboard",
+						"metadata": map[string]interface{}{"name": "api-metrics"},
+						"spec": map[string]interface{}{
+							"display": map[string]interface{}{"name": "API Metrics (production)"},
+							"panels":  []interface{}{requestRatePanel},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,222 @@
+package dashboards
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/npcomplete777/dash0-mcp/internal/config"
+	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+)
+
+// Size of a panel added to a grid layout, in grid units.
+const (
+	panelWidth  = 12
+	panelHeight = 8
+)
+
+// panelKeyInvalid matches the characters not allowed in a derived panel key.
+var panelKeyInvalid = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// renderPanel returns the panel of a template with its variables replaced
+// by vars, or by their defaults. Unknown and missing required variables are
+// errors.
+func renderPanel(t config.PanelTemplate, vars map[string]string) (map[string]interface{}, error) {
+	values := make(map[string]string, len(t.Variables))
+	declared := make(map[string]bool, len(t.Variables))
+	var missing []string
+	for _, v := range t.Variables {
+		declared[v.Name] = true
+		value, ok := vars[v.Name]
+		if !ok || value == "" {
+			value = v.Default
+		}
+		if value == "" && v.Required {
+			missing = append(missing, v.Name)
+		}
+		values[v.Name] = value
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("template %s needs a value for %s", t.Name, strings.Join(missing, ", "))
+	}
+	for name := range vars {
+		if !declared[name] {
+			return nil, fmt.Errorf("template %s has no variable %q (variables: %s)", t.Name, name, variableNames(t))
+		}
+	}
+
+	// Substitute in decoded string values, so a value with quotes cannot
+	// break the JSON
+	data, err := json.Marshal(t.Panel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode template %s: %w", t.Name, err)
+	}
+	var panel map[string]interface{}
+	if err := json.Unmarshal(data, &panel); err != nil {
+		return nil, fmt.Errorf("failed to decode template %s: %w", t.Name, err)
+	}
+	return substitute(panel, values).(map[string]interface{}), nil
+}
+
+// substitute replaces the template variables in every string of v.
+func substitute(v interface{}, values map[string]string) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			v[k] = substitute(val, values)
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = substitute(val, values)
+		}
+		return v
+	case string:
+		return config.PanelVariablePattern.ReplaceAllStringFunc(v, func(m string) string {
+			return values[config.PanelVariablePattern.FindStringSubmatch(m)[1]]
+		})
+	}
+	return v
+}
+
+// variableNames lists the variables of a template for error messages.
+func variableNames(t config.PanelTemplate) string {
+	names := make([]string, len(t.Variables))
+	for i, v := range t.Variables {
+		names[i] = v.Name
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+// panelKey derives the key of a new panel from the template name and the
+// service it charts, e.g. request_rate_checkout.
+func panelKey(template string, vars map[string]string) string {
+	key := template
+	if service := vars["service_name"]; service != "" {
+		key += "_" + service
+	}
+	return strings.Trim(panelKeyInvalid.ReplaceAllString(key, "_"), "_")
+}
+
+// insertPanel adds a panel to a dashboard and returns the key or display
+// name it was added under. When spec.panels is an object, the panel is
+// added under key (numbered if the key is taken) and placed below the
+// other panels of the last grid layout; when it is an array, it is
+// appended.
+func insertPanel(dashboard map[string]interface{}, key string, panel map[string]interface{}) (string, error) {
+	spec, ok := dashboard["spec"].(map[string]interface{})
+	if !ok {
+		if dashboard["spec"] != nil {
+			return "", fmt.Errorf("dashboard spec is not an object")
+		}
+		spec = map[string]interface{}{}
+		dashboard["spec"] = spec
+	}
+
+	switch panels := spec["panels"].(type) {
+	case []interface{}:
+		spec["panels"] = append(panels, panel)
+		return panelName(panel), nil
+	case map[string]interface{}, nil:
+		if panels == nil {
+			panels = map[string]interface{}{}
+			spec["panels"] = panels
+		}
+		m := panels.(map[string]interface{})
+		unique := key
+		for i := 2; m[unique] != nil; i++ {
+			unique = fmt.Sprintf("%s_%d", key, i)
+		}
+		m[unique] = panel
+		addToLayout(spec, unique)
+		return unique, nil
+	default:
+		return "", fmt.Errorf("dashboard spec.panels is neither an object nor an array")
+	}
+}
+
+// addToLayout places the panel with key below the items of the last grid
+// layout, creating a grid layout if there is none.
+func addToLayout(spec map[string]interface{}, key string) {
+	item := map[string]interface{}{
+		"x":       0,
+		"width":   panelWidth,
+		"height":  panelHeight,
+		"content": map[string]interface{}{"$ref": "#/spec/panels/" + escapePointer(key)},
+	}
+
+	layouts, _ := spec["layouts"].([]interface{})
+	for i := len(layouts) - 1; i >= 0; i-- {
+		layout, _ := layouts[i].(map[string]interface{})
+		if layout["kind"] != "Grid" {
+			continue
+		}
+		layoutSpec, ok := layout["spec"].(map[string]interface{})
+		if !ok {
+			layoutSpec = map[string]interface{}{}
+			layout["spec"] = layoutSpec
+		}
+		items, _ := layoutSpec["items"].([]interface{})
+		bottom := 0
+		for _, it := range items {
+			it, _ := it.(map[string]interface{})
+			if b := number(it["y"]) + number(it["height"]); b > bottom {
+				bottom = b
+			}
+		}
+		item["y"] = bottom
+		layoutSpec["items"] = append(items, item)
+		return
+	}
+
+	item["y"] = 0
+	spec["layouts"] = append(layouts, map[string]interface{}{
+		"kind": "Grid",
+		"spec": map[string]interface{}{"items": []interface{}{item}},
+	})
+}
+
+// number returns a decoded JSON number as an int, or 0.
+func number(v interface{}) int {
+	switch n := v.(type) {
+	case float64:
+		return int(n)
+	case int:
+		return n
+	case json.Number:
+		f, _ := n.Float64()
+		return int(f)
+	}
+	return 0
+}
+
+// formatTemplates renders the available panel templates as markdown.
+func formatTemplates(templates map[string]config.PanelTemplate) string {
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	rows := make([][]string, len(names))
+	for i, name := range names {
+		t := templates[name]
+		vars := make([]string, len(t.Variables))
+		for j, v := range t.Variables {
+			switch {
+			case v.Required:
+				vars[j] = v.Name + " (required)"
+			case v.Default != "":
+				vars[j] = fmt.Sprintf("%s (default: %s)", v.Name, v.Default)
+			default:
+				vars[j] = v.Name
+			}
+		}
+		rows[i] = []string{name, t.Description, strings.Join(vars, ", ")}
+	}
+	return formatter.Table("Panel Templates", fmt.Sprintf("%d templates", len(names)), []string{"Template", "Description", "Variables"}, rows, "")
+}
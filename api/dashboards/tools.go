@@ -4,10 +4,16 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"sort"
+	"strings"
 
+	"github.com/npcomplete777/dash0-mcp/internal/canonical"
 	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/config"
 	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+	"github.com/npcomplete777/dash0-mcp/internal/listing"
 	"github.com/npcomplete777/dash0-mcp/internal/registry"
+	"github.com/npcomplete777/dash0-mcp/internal/schema"
 	mcp "github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -21,6 +27,9 @@ var _ registry.ToolProvider = (*Tools)(nil)
 // Tools provides MCP tools for Dashboards API operations.
 type Tools struct {
 	client *client.Client
+	// templates are the panel templates of dash0_dashboards_add_panel, by
+	// name.
+	templates map[string]config.PanelTemplate
 }
 
 // New creates a new Dashboards tools instance.
@@ -36,17 +45,30 @@ func (p *Tools) Tools() []mcp.Tool {
 		p.CreateDashboard(),
 		p.UpdateDashboard(),
 		p.DeleteDashboard(),
+		p.DiffDashboard(),
+		p.AddPanel(),
+	}
+}
+
+// SetPanelTemplates sets the templates dash0_dashboards_add_panel can
+// insert.
+func (p *Tools) SetPanelTemplates(templates []config.PanelTemplate) {
+	p.templates = make(map[string]config.PanelTemplate, len(templates))
+	for _, t := range templates {
+		p.templates[t.Name] = t
 	}
 }
 
 // Handlers returns a map of tool name to handler function.
 func (p *Tools) Handlers() map[string]func(context.Context, map[string]interface{}) *client.ToolResult {
 	return map[string]func(context.Context, map[string]interface{}) *client.ToolResult{
-		"dash0_dashboards_list":   p.ListDashboardsHandler,
-		"dash0_dashboards_get":    p.GetDashboardHandler,
-		"dash0_dashboards_create": p.CreateDashboardHandler,
-		"dash0_dashboards_update": p.UpdateDashboardHandler,
-		"dash0_dashboards_delete": p.DeleteDashboardHandler,
+		"dash0_dashboards_list":      p.ListDashboardsHandler,
+		"dash0_dashboards_get":       p.GetDashboardHandler,
+		"dash0_dashboards_create":    p.CreateDashboardHandler,
+		"dash0_dashboards_update":    p.UpdateDashboardHandler,
+		"dash0_dashboards_delete":    p.DeleteDashboardHandler,
+		"dash0_dashboards_diff":      p.DiffDashboardHandler,
+		"dash0_dashboards_add_panel": p.AddPanelHandler,
 	}
 }
 
@@ -56,19 +78,22 @@ func (p *Tools) ListDashboards() mcp.Tool {
 		Name:        "dash0_dashboards_list",
 		Description: "List all dashboards in Dash0. Returns dashboard metadata including names, IDs, and modification times.",
 		InputSchema: mcp.ToolInputSchema{
-			Type:       "object",
-			Properties: map[string]interface{}{},
+			Type: "object",
+			Properties: map[string]interface{}{
+				"page_token": listing.PageTokenProperty(),
+				"summary":    listing.SummaryProperty(),
+			},
 		},
 	}
 }
 
 // ListDashboardsHandler handles the dash0_dashboards_list tool.
 func (p *Tools) ListDashboardsHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
-	result := p.client.Get(ctx, basePath)
+	result := p.client.Get(ctx, listing.Path(basePath, args))
 	if result.Success {
 		result.Markdown = formatter.FormatListResponse("Dashboards", result.Data)
 	}
-	return result
+	return listing.Summarize(listing.Adapt(result), args, "Dashboards")
 }
 
 // GetDashboard returns the dash0_dashboards_get tool definition.
@@ -157,7 +182,7 @@ With panels:
 }`,
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
-			Properties: map[string]interface{}{
+			Properties: schema.WithBodyYAML(map[string]interface{}{
 				"body": map[string]interface{}{
 					"type":        "object",
 					"description": "The dashboard configuration in Perses CRD format.",
@@ -201,17 +226,19 @@ With panels:
 					},
 					"required": []interface{}{"kind", "metadata", "spec"},
 				},
-			},
-			Required: []string{"body"},
+			}),
 		},
 	}
 }
 
 // CreateDashboardHandler handles the dash0_dashboards_create tool.
 func (p *Tools) CreateDashboardHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
-	body, ok := args["body"]
-	if !ok {
-		return client.ErrorResult(400, "body is required")
+	body, errResult := schema.Body(args)
+	if errResult != nil {
+		return errResult
+	}
+	if result := schema.Check(schema.KindDashboard, body); result != nil {
+		return result
 	}
 
 	return p.client.Post(ctx, basePath, body)
@@ -234,7 +261,7 @@ The body should follow the same Perses CRD format as create:
 }`,
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
-			Properties: map[string]interface{}{
+			Properties: schema.WithBodyYAML(map[string]interface{}{
 				"origin_or_id": map[string]interface{}{
 					"type":        "string",
 					"description": "The origin or ID of the dashboard to update.",
@@ -258,8 +285,8 @@ The body should follow the same Perses CRD format as create:
 					},
 					"required": []interface{}{"kind", "metadata", "spec"},
 				},
-			},
-			Required: []string{"origin_or_id", "body"},
+			}),
+			Required: []string{"origin_or_id"},
 		},
 	}
 }
@@ -271,9 +298,12 @@ func (p *Tools) UpdateDashboardHandler(ctx context.Context, args map[string]inte
 		return client.ErrorResult(400, "origin_or_id is required")
 	}
 
-	body, ok := args["body"]
-	if !ok {
-		return client.ErrorResult(400, "body is required")
+	body, errResult := schema.Body(args)
+	if errResult != nil {
+		return errResult
+	}
+	if result := schema.Check(schema.KindDashboard, body); result != nil {
+		return result
 	}
 
 	path := fmt.Sprintf(basePath+"/%s", url.PathEscape(originOrID))
@@ -309,11 +339,200 @@ func (p *Tools) DeleteDashboardHandler(ctx context.Context, args map[string]inte
 	return p.client.Delete(ctx, path)
 }
 
-// Register registers all dashboard tools with the registry.
-func Register(reg *registry.Registry, c *client.Client) {
+// DiffDashboard returns the dash0_dashboards_diff tool definition.
+func (p *Tools) DiffDashboard() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_dashboards_diff",
+		Description: `Compare a proposed dashboard body with the current dashboard, without changing anything.
+
+Fetches the dashboard by origin or ID and returns a structured diff: panels added, removed,
+and changed (with the changed fields of each panel), plus changes to metadata and to the
+rest of spec, such as the display name, layouts, and variables. Panels are matched by key
+when spec.panels is an object and by display name when it is an array. Server-maintained
+fields, key order, and number formatting are ignored.
+
+Use it to review an update before running dash0_dashboards_update with the same body.`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"origin_or_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The origin or ID of the dashboard to compare with.",
+				},
+				"body": map[string]interface{}{
+					"type":        "object",
+					"description": "The proposed dashboard configuration in Perses CRD format, as it would be passed to dash0_dashboards_update.",
+				},
+			},
+			Required: []string{"origin_or_id", "body"},
+		},
+	}
+}
+
+// DiffDashboardHandler handles the dash0_dashboards_diff tool.
+func (p *Tools) DiffDashboardHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	originOrID, ok := args["origin_or_id"].(string)
+	if !ok || originOrID == "" {
+		return client.ErrorResult(400, "origin_or_id is required")
+	}
+
+	body, ok := args["body"]
+	if !ok {
+		return client.ErrorResult(400, "body is required")
+	}
+	if result := schema.Check(schema.KindDashboard, body); result != nil {
+		return result
+	}
+
+	path := fmt.Sprintf(basePath+"/%s", url.PathEscape(originOrID))
+	current := p.client.Get(ctx, path)
+	if !current.Success {
+		return current
+	}
+
+	diff, err := diffDashboards(current.Data, body)
+	if err != nil {
+		return client.ErrorResult(400, fmt.Sprintf("failed to compare dashboards: %v", err))
+	}
+	return &client.ToolResult{
+		Success:  true,
+		Data:     diff,
+		Markdown: formatDiff(originOrID, diff),
+	}
+}
+
+// AddPanel returns the dash0_dashboards_add_panel tool definition.
+func (p *Tools) AddPanel() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_dashboards_add_panel",
+		Description: `Add a panel from a template to an existing dashboard, without writing Perses JSON.
+
+Templates are loaded from config/panel-templates; the built-in ones are request-rate, p95-latency,
+error-rate, and log-volume, each charting one service (variable service_name). Call without a
+template to list the available templates and their variables.
+
+The panel is added under a key derived from the template and service, placed below the other
+panels of the dashboard's grid layout, and the dashboard is updated. Example:
+{"origin_or_id": "checkout-overview", "template": "p95-latency", "variables": {"service_name": "checkout"}}`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"origin_or_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The origin or ID of the dashboard to add the panel to.",
+				},
+				"template": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the panel template, e.g. request-rate. Omit to list the templates.",
+				},
+				"variables": map[string]interface{}{
+					"type":        "object",
+					"description": `Values of the template's variables, e.g. {"service_name": "checkout", "window": "10m"}. Variables with a default may be omitted.`,
+				},
+				"title": map[string]interface{}{
+					"type":        "string",
+					"description": "Display name of the panel, instead of the template's.",
+				},
+				"panel_key": map[string]interface{}{
+					"type":        "string",
+					"description": "Key of the panel in spec.panels (default: derived from the template and service_name, e.g. p95_latency_checkout).",
+				},
+			},
+			Required: []string{"origin_or_id"},
+		},
+	}
+}
+
+// AddPanelHandler handles the dash0_dashboards_add_panel tool.
+func (p *Tools) AddPanelHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	originOrID, ok := args["origin_or_id"].(string)
+	if !ok || originOrID == "" {
+		return client.ErrorResult(400, "origin_or_id is required")
+	}
+	if len(p.templates) == 0 {
+		return client.ErrorResult(400, "no panel templates are configured; add JSON files to config/panel-templates")
+	}
+
+	name, _ := args["template"].(string)
+	if name == "" {
+		return &client.ToolResult{Success: true, Data: p.templates, Markdown: formatTemplates(p.templates)}
+	}
+	t, ok := p.templates[name]
+	if !ok {
+		names := make([]string, 0, len(p.templates))
+		for n := range p.templates {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return client.ErrorResult(400, fmt.Sprintf("unknown panel template %q (templates: %s)", name, strings.Join(names, ", ")))
+	}
+
+	vars := map[string]string{}
+	if raw, ok := args["variables"].(map[string]interface{}); ok {
+		for k, v := range raw {
+			vars[k] = fmt.Sprint(v)
+		}
+	}
+	panel, err := renderPanel(t, vars)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+	if title, _ := args["title"].(string); title != "" {
+		spec, _ := panel["spec"].(map[string]interface{})
+		if spec == nil {
+			spec = map[string]interface{}{}
+			panel["spec"] = spec
+		}
+		display, _ := spec["display"].(map[string]interface{})
+		if display == nil {
+			display = map[string]interface{}{}
+			spec["display"] = display
+		}
+		display["name"] = title
+	}
+
+	path := fmt.Sprintf(basePath+"/%s", url.PathEscape(originOrID))
+	current := p.client.Get(ctx, path)
+	if !current.Success {
+		return current
+	}
+	// A clean copy without the server-maintained fields
+	clean, err := canonical.Canonicalize(current.Data)
+	if err != nil {
+		return client.ErrorResult(502, fmt.Sprintf("failed to read dashboard: %v", err))
+	}
+	dashboard, ok := clean.(map[string]interface{})
+	if !ok {
+		return client.ErrorResult(502, "dashboard is not a JSON object")
+	}
+
+	key, _ := args["panel_key"].(string)
+	if key == "" {
+		key = panelKey(name, vars)
+	}
+	added, err := insertPanel(dashboard, key, panel)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+	if result := schema.Check(schema.KindDashboard, dashboard); result != nil {
+		return result
+	}
+
+	result := p.client.Put(ctx, path, dashboard)
+	if result.Success && result.Meta == nil {
+		result.Meta = map[string]interface{}{"panel": added, "template": name}
+	}
+	return result
+}
+
+// Register registers all dashboard tools with the registry. The panel
+// templates are the ones dash0_dashboards_add_panel can insert.
+func Register(reg *registry.Registry, c *client.Client, templates []config.PanelTemplate) {
 	p := New(c)
+	p.SetPanelTemplates(templates)
 	for _, tool := range p.Tools() {
 		handler := p.Handlers()[tool.Name]
 		reg.Register(tool, handler)
+		reg.AddExamples(tool.Name, p.Examples()[tool.Name]...)
 	}
 }
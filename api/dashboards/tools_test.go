@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/config"
 )
 
 func TestNew(t *testing.T) {
@@ -26,16 +27,18 @@ func TestTools(t *testing.T) {
 	pkg := New(&client.Client{})
 	tools := pkg.Tools()
 
-	if len(tools) != 5 {
-		t.Errorf("Tools() returned %d tools, expected 5", len(tools))
+	if len(tools) != 7 {
+		t.Errorf("Tools() returned %d tools, expected 7", len(tools))
 	}
 
 	expectedNames := map[string]bool{
-		"dash0_dashboards_list":   false,
-		"dash0_dashboards_get":    false,
-		"dash0_dashboards_create": false,
-		"dash0_dashboards_update": false,
-		"dash0_dashboards_delete": false,
+		"dash0_dashboards_list":      false,
+		"dash0_dashboards_get":       false,
+		"dash0_dashboards_create":    false,
+		"dash0_dashboards_update":    false,
+		"dash0_dashboards_delete":    false,
+		"dash0_dashboards_diff":      false,
+		"dash0_dashboards_add_panel": false,
 	}
 
 	for _, tool := range tools {
@@ -62,6 +65,8 @@ func TestHandlers(t *testing.T) {
 		"dash0_dashboards_create",
 		"dash0_dashboards_update",
 		"dash0_dashboards_delete",
+		"dash0_dashboards_diff",
+		"dash0_dashboards_add_panel",
 	}
 
 	if len(handlers) != len(expectedHandlers) {
@@ -225,9 +230,12 @@ func TestCreateDashboardToolDefinition(t *testing.T) {
 		t.Error("CreateDashboard() description should mention 'PersesDashboard'")
 	}
 
-	// Should require body
-	if len(tool.InputSchema.Required) != 1 || tool.InputSchema.Required[0] != "body" {
-		t.Error("CreateDashboard() should require 'body'")
+	// Takes body or body_yaml, so neither is required
+	if len(tool.InputSchema.Required) != 0 {
+		t.Errorf("CreateDashboard() Required = %v, expected none", tool.InputSchema.Required)
+	}
+	if _, ok := tool.InputSchema.Properties["body_yaml"]; !ok {
+		t.Error("CreateDashboard() should accept 'body_yaml'")
 	}
 
 	// Body should have properties for kind, metadata, spec
@@ -324,17 +332,12 @@ func TestUpdateDashboardToolDefinition(t *testing.T) {
 		t.Errorf("UpdateDashboard() name = %s, expected dash0_dashboards_update", tool.Name)
 	}
 
-	// Should require origin_or_id and body
-	if len(tool.InputSchema.Required) != 2 {
-		t.Error("UpdateDashboard() should require 2 parameters")
-	}
-
-	required := make(map[string]bool)
-	for _, r := range tool.InputSchema.Required {
-		required[r] = true
+	// Should require origin_or_id; the body comes from body or body_yaml
+	if len(tool.InputSchema.Required) != 1 || tool.InputSchema.Required[0] != "origin_or_id" {
+		t.Errorf("UpdateDashboard() Required = %v, expected [origin_or_id]", tool.InputSchema.Required)
 	}
-	if !required["origin_or_id"] || !required["body"] {
-		t.Error("UpdateDashboard() should require origin_or_id and body")
+	if _, ok := tool.InputSchema.Properties["body_yaml"]; !ok {
+		t.Error("UpdateDashboard() should accept 'body_yaml'")
 	}
 }
 
@@ -535,3 +538,201 @@ func TestCreateDashboardDescription_ContainsExamples(t *testing.T) {
 		t.Error("CreateDashboard() description should mention panels")
 	}
 }
+
+func TestDiffDashboardHandler(t *testing.T) {
+	current := `{
+		"kind": "PersesDashboard",
+		"metadata": {"name": "api", "createdAt": "2026-01-01T00:00:00Z", "labels": {"dash0.com/origin": "api", "dash0.com/version": "4"}},
+		"spec": {
+			"display": {"name": "API"},
+			"duration": "1h",
+			"panels": [
+				{"kind": "Panel", "spec": {"display": {"name": "Errors"}, "queries": [{"spec": {"query": "errors"}}]}},
+				{"kind": "Panel", "spec": {"display": {"name": "Latency"}, "plugin": {"spec": {"max": 1.0}}}},
+				{"kind": "Panel", "spec": {"display": {"name": "Old"}}}
+			]
+		}
+	}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Expected GET, got %s", r.Method)
+		}
+		if r.URL.Path != "/api/dashboards/api" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(current))
+	}))
+	defer server.Close()
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+
+	var proposed map[string]interface{}
+	json.Unmarshal([]byte(`{
+		"spec": {
+			"panels": [
+				{"spec": {"plugin": {"spec": {"max": 1}}, "display": {"name": "Latency"}}, "kind": "Panel"},
+				{"kind": "Panel", "spec": {"display": {"name": "Errors"}, "queries": [{"spec": {"query": "sum(errors)"}}]}},
+				{"kind": "Panel", "spec": {"display": {"name": "Throughput"}}}
+			],
+			"duration": "1h",
+			"display": {"name": "API (production)"}
+		},
+		"metadata": {"name": "api"},
+		"kind": "PersesDashboard"
+	}`), &proposed)
+
+	result := pkg.DiffDashboardHandler(context.Background(), map[string]interface{}{"origin_or_id": "api", "body": proposed})
+	if !result.Success {
+		t.Fatalf("expected success, got %+v", result.Error)
+	}
+	d := result.Data.(*Diff)
+	if d.Identical || len(d.Metadata) != 0 {
+		t.Errorf("metadata changes = %+v, want none (server fields and labels ignored)", d.Metadata)
+	}
+	if len(d.Spec) != 1 || d.Spec[0].Path != "/spec/display/name" || d.Spec[0].Op != "changed" {
+		t.Errorf("spec changes = %+v, want the display name", d.Spec)
+	}
+	if len(d.Panels.Added) != 1 || d.Panels.Added[0] != "Throughput" || len(d.Panels.Removed) != 1 || d.Panels.Removed[0] != "Old" {
+		t.Errorf("panels added/removed = %v/%v", d.Panels.Added, d.Panels.Removed)
+	}
+	// Latency only differs in key order and number format
+	if len(d.Panels.Changed) != 1 || d.Panels.Changed[0].Panel != "Errors" || d.Panels.Changed[0].Changes[0].Path != "/spec/queries/0/spec/query" {
+		t.Errorf("panels changed = %+v, want the Errors query", d.Panels.Changed)
+	}
+	for _, s := range []string{"## Dashboard Diff: api", "1 panels added, 1 removed, 1 changed", "| Throughput | added |", "`/spec/display/name`"} {
+		if !strings.Contains(result.Markdown, s) {
+			t.Errorf("markdown missing %q:\n%s", s, result.Markdown)
+		}
+	}
+
+	var same interface{}
+	json.Unmarshal([]byte(current), &same)
+	result = pkg.DiffDashboardHandler(context.Background(), map[string]interface{}{"origin_or_id": "api", "body": same})
+	if !result.Success || !result.Data.(*Diff).Identical {
+		t.Errorf("diff against itself = %+v, want identical", result.Data)
+	}
+
+	result = pkg.DiffDashboardHandler(context.Background(), map[string]interface{}{"origin_or_id": "missing", "body": proposed})
+	if result.Success || result.Error.Code != "not_found" {
+		t.Errorf("missing dashboard = %+v, want not_found", result)
+	}
+	if result := pkg.DiffDashboardHandler(context.Background(), map[string]interface{}{"origin_or_id": "api"}); result.Success {
+		t.Error("expected an error without a body")
+	}
+}
+
+func TestAddPanelHandler(t *testing.T) {
+	var putBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/dashboards/checkout" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`{
+				"kind": "PersesDashboard",
+				"metadata": {"name": "checkout", "createdAt": "2026-01-01T00:00:00Z"},
+				"spec": {
+					"display": {"name": "Checkout"},
+					"panels": {"p95_latency_checkout": {"kind": "Panel", "spec": {"display": {"name": "Old"}}}},
+					"layouts": [{"kind": "Grid", "spec": {"items": [
+						{"x": 0, "y": 0, "width": 12, "height": 6, "content": {"$ref": "#/spec/panels/p95_latency_checkout"}}
+					]}}]
+				}
+			}`))
+		case http.MethodPut:
+			json.NewDecoder(r.Body).Decode(&putBody)
+			w.Write([]byte(`{"metadata": {"name": "checkout"}}`))
+		}
+	}))
+	defer server.Close()
+
+	templates, err := config.LoadPanelTemplates("../../config")
+	if err != nil {
+		t.Fatalf("LoadPanelTemplates() error = %v", err)
+	}
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	pkg.SetPanelTemplates(templates)
+
+	t.Run("lists the templates", func(t *testing.T) {
+		result := pkg.AddPanelHandler(context.Background(), map[string]interface{}{"origin_or_id": "checkout"})
+		if !result.Success {
+			t.Fatalf("expected success, got %+v", result.Error)
+		}
+		for _, name := range []string{"request-rate", "p95-latency", "error-rate", "log-volume"} {
+			if !strings.Contains(result.Markdown, "| "+name+" |") {
+				t.Errorf("markdown missing template %s:\n%s", name, result.Markdown)
+			}
+		}
+	})
+
+	t.Run("adds the panel", func(t *testing.T) {
+		result := pkg.AddPanelHandler(context.Background(), map[string]interface{}{
+			"origin_or_id": "checkout",
+			"template":     "p95-latency",
+			"variables":    map[string]interface{}{"service_name": "checkout"},
+		})
+		if !result.Success {
+			t.Fatalf("expected success, got %+v", result.Error)
+		}
+		if meta, _ := result.Meta.(map[string]interface{}); meta["panel"] != "p95_latency_checkout_2" {
+			t.Errorf("Meta = %v, want the numbered key", result.Meta)
+		}
+		if _, ok := putBody["metadata"].(map[string]interface{})["createdAt"]; ok {
+			t.Error("server-maintained fields were sent back")
+		}
+		spec := putBody["spec"].(map[string]interface{})
+		panel := spec["panels"].(map[string]interface{})["p95_latency_checkout_2"].(map[string]interface{})
+		data, _ := json.Marshal(panel)
+		if !strings.Contains(string(data), `service_name=\"checkout\"`) || !strings.Contains(string(data), "[5m]") || strings.Contains(string(data), "{{") {
+			t.Errorf("panel = %s, want the variables and defaults substituted", data)
+		}
+		items := spec["layouts"].([]interface{})[0].(map[string]interface{})["spec"].(map[string]interface{})["items"].([]interface{})
+		item := items[len(items)-1].(map[string]interface{})
+		if len(items) != 2 || item["y"] != float64(6) || item["content"].(map[string]interface{})["$ref"] != "#/spec/panels/p95_latency_checkout_2" {
+			t.Errorf("layout items = %v, want the panel below the existing one", items)
+		}
+	})
+
+	t.Run("title and Perses placeholders", func(t *testing.T) {
+		result := pkg.AddPanelHandler(context.Background(), map[string]interface{}{
+			"origin_or_id": "checkout",
+			"template":     "log-volume",
+			"variables":    map[string]interface{}{"service_name": "checkout"},
+			"title":        "Checkout logs",
+			"panel_key":    "logs",
+		})
+		if !result.Success {
+			t.Fatalf("expected success, got %+v", result.Error)
+		}
+		panel := putBody["spec"].(map[string]interface{})["panels"].(map[string]interface{})["logs"].(map[string]interface{})
+		data, _ := json.Marshal(panel)
+		if !strings.Contains(string(data), `"name":"Checkout logs"`) || !strings.Contains(string(data), `"seriesNameFormat":"{{otel_log_severity_range}}"`) {
+			t.Errorf("panel = %s, want the title and the Perses placeholder kept", data)
+		}
+	})
+
+	errorCases := []struct {
+		name string
+		args map[string]interface{}
+		want string
+	}{
+		{"unknown template", map[string]interface{}{"template": "nope"}, "unknown panel template"},
+		{"missing variable", map[string]interface{}{"template": "error-rate"}, "needs a value for service_name"},
+		{"unknown variable", map[string]interface{}{"template": "error-rate", "variables": map[string]interface{}{"service_name": "a", "env": "prod"}}, `no variable "env"`},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.args["origin_or_id"] = "checkout"
+			result := pkg.AddPanelHandler(context.Background(), tc.args)
+			if result.Success || !strings.Contains(result.Error.Message(), tc.want) {
+				t.Errorf("result = %+v, want an error containing %q", result.Error, tc.want)
+			}
+		})
+	}
+
+	if result := New(&client.Client{}).AddPanelHandler(context.Background(), map[string]interface{}{"origin_or_id": "checkout"}); result.Success {
+		t.Error("expected an error without templates")
+	}
+}
@@ -0,0 +1,170 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/listing"
+	"gopkg.in/yaml.v3"
+)
+
+// configCollection is a configuration object type included in bundles.
+type configCollection struct {
+	// Name is the bundle key and the value accepted by the resources argument.
+	Name string
+	// Title is the human-readable name used in tool descriptions.
+	Title string
+	// Path is the Dash0 API path for listing and getting objects.
+	Path string
+}
+
+// configCollections lists the exportable object types in bundle order.
+var configCollections = []configCollection{
+	{Name: "dashboards", Title: "dashboards", Path: "/api/dashboards"},
+	{Name: "views", Title: "views", Path: "/api/views"},
+	{Name: "check_rules", Title: "check rules", Path: "/api/alerting/check-rules"},
+	{Name: "synthetic_checks", Title: "synthetic checks", Path: "/api/synthetic-checks"},
+	{Name: "sampling_rules", Title: "sampling rules", Path: "/api/sampling-rules"},
+}
+
+// volatileFields are server-maintained fields that change without a
+// configuration change; they are dropped so bundles diff cleanly in git.
+var volatileFields = []string{"createdAt", "updatedAt", "version", "resourceVersion", "generation", "creationTimestamp", "status"}
+
+// volatileLabels are server-maintained metadata labels.
+var volatileLabels = []string{"dash0.com/version", "dash0.com/created-at", "dash0.com/updated-at"}
+
+// configCollectionNames returns the names accepted by the resources argument.
+func configCollectionNames() []string {
+	names := make([]string, len(configCollections))
+	for i, col := range configCollections {
+		names[i] = col.Name
+	}
+	return names
+}
+
+// selectConfigCollections returns the collections named in raw, or all of
+// them when raw is empty.
+func selectConfigCollections(raw interface{}) ([]configCollection, error) {
+	list, _ := raw.([]interface{})
+	if len(list) == 0 {
+		return configCollections, nil
+	}
+	want := map[string]bool{}
+	for _, v := range list {
+		name, _ := v.(string)
+		name = strings.TrimSpace(name)
+		found := false
+		for _, col := range configCollections {
+			if col.Name == name {
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown resource type %q; valid types: %s", name, strings.Join(configCollectionNames(), ", "))
+		}
+		want[name] = true
+	}
+	var out []configCollection
+	for _, col := range configCollections {
+		if want[col.Name] {
+			out = append(out, col)
+		}
+	}
+	return out, nil
+}
+
+// fetchCollection lists a collection and fetches the full definition of
+// every object in it, several objects at a time.
+func fetchCollection(ctx context.Context, c *client.Client, col configCollection) ([]interface{}, *client.ToolResult) {
+	list := c.Get(ctx, col.Path)
+	if !list.Success {
+		return nil, list
+	}
+
+	var entries []map[string]interface{}
+	for _, raw := range listing.FromResponse(list.Data).Items {
+		if m, ok := raw.(map[string]interface{}); ok {
+			entries = append(entries, m)
+		}
+	}
+
+	objects := make([]interface{}, len(entries))
+	errResult := c.Parallel(ctx, len(entries), func(ctx context.Context, i int) *client.ToolResult {
+		id := listing.ItemID(entries[i])
+		if id == "" {
+			// Without an identifier the list entry is all there is.
+			objects[i] = cleanObject(entries[i])
+			return nil
+		}
+		full := c.Get(ctx, fmt.Sprintf("%s/%s", col.Path, url.PathEscape(id)))
+		if !full.Success {
+			return full
+		}
+		objects[i] = cleanObject(full.Data)
+		return nil
+	})
+	if errResult != nil {
+		return nil, errResult
+	}
+	return objects, nil
+}
+
+// cleanObject drops volatile server-maintained fields from an object.
+func cleanObject(data interface{}) interface{} {
+	obj, ok := data.(map[string]interface{})
+	if !ok {
+		return data
+	}
+	for _, f := range volatileFields {
+		delete(obj, f)
+	}
+	if meta, ok := obj["metadata"].(map[string]interface{}); ok {
+		for _, f := range volatileFields {
+			delete(meta, f)
+		}
+		if labels, ok := meta["labels"].(map[string]interface{}); ok {
+			for _, l := range volatileLabels {
+				delete(labels, l)
+			}
+		}
+	}
+	return obj
+}
+
+// encodeBundle renders a bundle as indented JSON or YAML.
+func encodeBundle(bundle map[string]interface{}, format string) (string, error) {
+	if format == "yaml" {
+		// Round-trip through JSON so numbers and nested values are plain
+		// YAML scalars, maps, and lists.
+		raw, err := json.Marshal(bundle)
+		if err != nil {
+			return "", err
+		}
+		var doc interface{}
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return "", err
+		}
+		out, err := yaml.Marshal(doc)
+		return string(out), err
+	}
+	out, err := json.MarshalIndent(bundle, "", "  ")
+	return string(out) + "\n", err
+}
+
+// writeBundle writes an encoded bundle to path, replacing an earlier export.
+func writeBundle(path, content string) error {
+	f, err := createFile(path, true)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		return fmt.Errorf("failed to write bundle: %v", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,5 @@
+// Package export provides MCP tools for exporting Dash0 data to local files.
+// This package enables paging through span and log queries and writing the results
+// as typed SQLite tables or Parquet files for offline analysis, and exporting
+// configuration objects as JSON or YAML bundles for configuration-as-code workflows.
+package export
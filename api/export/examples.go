@@ -0,0 +1,29 @@
+package export
+
+import "github.com/npcomplete777/dash0-mcp/internal/registry"
+
+// Compile-time interface check.
+var _ registry.ExampleProvider = (*Tools)(nil)
+
+// Examples returns example invocations for the export tools.
+func (p *Tools) Examples() map[string][]registry.Example {
+	examples := map[string][]registry.Example{
+		"dash0_export_table": {
+			{Title: "Spans for a service to SQLite", Arguments: map[string]interface{}{"signal": "spans", "path": "dash0.db", "service_name": "cart"}},
+			{Title: "Logs to the same database", Arguments: map[string]interface{}{"signal": "logs", "path": "dash0.db", "table": "logs"}},
+			{Title: "Last 24h of spans to Parquet", Arguments: map[string]interface{}{"signal": "spans", "format": "parquet", "path": "spans.parquet", "time_range_minutes": 1440}},
+			{Title: "Spans with their logs in one table", Arguments: map[string]interface{}{"signal": "joined", "path": "dash0.db", "service_name": "cart"}},
+		},
+		"dash0_export_all": {
+			{Title: "Everything as YAML", Arguments: map[string]interface{}{"format": "yaml"}},
+			{Title: "Alerting config to a file", Arguments: map[string]interface{}{"resources": []interface{}{"check_rules"}, "format": "yaml", "path": "dash0/alerts.yaml"}},
+		},
+	}
+	for _, col := range configCollections {
+		examples[exportResourceName(col)] = []registry.Example{
+			{Title: "All " + col.Title + " as JSON", Arguments: map[string]interface{}{}},
+			{Title: "All " + col.Title + " to a YAML file", Arguments: map[string]interface{}{"format": "yaml", "path": "./dash0/" + col.Name + ".yaml"}},
+		}
+	}
+	return examples
+}
@@ -0,0 +1,105 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// errExportsDisabled is returned for a path when no export directory is
+// configured.
+var errExportsDisabled = errors.New("writing files is disabled: set DASH0_MCP_EXPORT_DIR to the directory exports may be written to")
+
+// resolvePath returns the file a path argument names inside the export
+// directory (DASH0_MCP_EXPORT_DIR), creating its parent directories. The path
+// must be relative and must not leave the directory, neither with ".." nor
+// through a symlink.
+func (p *Tools) resolvePath(path string) (string, error) {
+	if p.exportDir == "" {
+		return "", errExportsDisabled
+	}
+	if !filepath.IsLocal(path) {
+		return "", fmt.Errorf("path %s must be relative to the export directory and must not contain '..'", path)
+	}
+	root, err := filepath.EvalSymlinks(p.exportDir)
+	if err != nil {
+		return "", fmt.Errorf("export directory: %w", err)
+	}
+
+	// Check the directories that exist before creating the missing ones,
+	// so that a symlinked directory cannot make MkdirAll write elsewhere
+	dir := filepath.Dir(filepath.Join(root, path))
+	existing := dir
+	for {
+		if _, err := os.Lstat(existing); err == nil || existing == root {
+			break
+		}
+		existing = filepath.Dir(existing)
+	}
+	if err := checkWithin(root, existing, path); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := checkWithin(root, dir, path); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, filepath.Base(path)), nil
+}
+
+// checkWithin returns an error unless dir, with symlinks resolved, is root
+// or inside it.
+func checkWithin(root, dir, path string) error {
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve output directory: %w", err)
+	}
+	if rel, err := filepath.Rel(root, resolved); err != nil || (rel != "." && !filepath.IsLocal(rel)) {
+		return fmt.Errorf("path %s leaves the export directory through a symlink", path)
+	}
+	return nil
+}
+
+// createFile creates a new file at path. An existing file is an error unless
+// replace is set, in which case it is removed first. The file is opened with
+// O_EXCL, so nothing created at path in the meantime, including a symlink,
+// is followed or overwritten.
+func createFile(path string, replace bool) (*os.File, error) {
+	if replace {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to replace file: %w", err)
+		}
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		return nil, fmt.Errorf("file %s already exists (set overwrite=true to replace it)", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+	return f, nil
+}
+
+// prepareDatabase makes sure path is a regular file a SQLite database can be
+// opened at: an existing database, or a new empty file, which SQLite treats
+// as an empty database.
+func prepareDatabase(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		f, err := createFile(path, false)
+		if err != nil {
+			return err
+		}
+		return f.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to inspect database file: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", path)
+	}
+	return nil
+}
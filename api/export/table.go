@@ -0,0 +1,350 @@
+package export
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/api/logs"
+	"github.com/npcomplete777/dash0-mcp/api/spans"
+	"github.com/parquet-go/parquet-go"
+
+	// Register the pure-Go "sqlite" database/sql driver.
+	_ "modernc.org/sqlite"
+)
+
+// column describes a typed table column using SQLite type names.
+type column struct {
+	Name string
+	Type string
+}
+
+// spanColumns is the table layout for exported spans.
+var spanColumns = []column{
+	{"trace_id", "TEXT"},
+	{"span_id", "TEXT"},
+	{"parent_span_id", "TEXT"},
+	{"name", "TEXT"},
+	{"service_name", "TEXT"},
+	{"span_kind", "TEXT"},
+	{"start_time", "TEXT"},
+	{"end_time", "TEXT"},
+	{"duration_ms", "REAL"},
+	{"status_code", "INTEGER"},
+	{"status_message", "TEXT"},
+	{"k8s_pod_name", "TEXT"},
+	{"event_count", "INTEGER"},
+	{"link_count", "INTEGER"},
+	{"has_children", "INTEGER"},
+	{"attributes", "TEXT"},
+}
+
+// spanRow is a single exported span. Field order matches spanColumns.
+type spanRow struct {
+	TraceID       string  `parquet:"trace_id"`
+	SpanID        string  `parquet:"span_id"`
+	ParentSpanID  string  `parquet:"parent_span_id"`
+	Name          string  `parquet:"name"`
+	ServiceName   string  `parquet:"service_name"`
+	SpanKind      string  `parquet:"span_kind"`
+	StartTime     string  `parquet:"start_time"`
+	EndTime       string  `parquet:"end_time"`
+	DurationMs    float64 `parquet:"duration_ms"`
+	StatusCode    int64   `parquet:"status_code"`
+	StatusMessage string  `parquet:"status_message"`
+	K8sPodName    string  `parquet:"k8s_pod_name"`
+	EventCount    int64   `parquet:"event_count"`
+	LinkCount     int64   `parquet:"link_count"`
+	HasChildren   bool    `parquet:"has_children"`
+	Attributes    string  `parquet:"attributes"`
+}
+
+func newSpanRow(s spans.FlatSpan) spanRow {
+	return spanRow{
+		TraceID:       s.TraceID,
+		SpanID:        s.SpanID,
+		ParentSpanID:  s.ParentSpanID,
+		Name:          s.Name,
+		ServiceName:   s.ServiceName,
+		SpanKind:      s.SpanKind,
+		StartTime:     s.StartTime,
+		EndTime:       s.EndTime,
+		DurationMs:    s.DurationMs,
+		StatusCode:    int64(s.StatusCode),
+		StatusMessage: s.StatusMessage,
+		K8sPodName:    s.K8sPodName,
+		EventCount:    int64(s.EventCount),
+		LinkCount:     int64(s.LinkCount),
+		HasChildren:   s.HasChildren,
+		Attributes:    attributesJSON(s.Attributes),
+	}
+}
+
+func (r spanRow) values() []interface{} {
+	return []interface{}{
+		r.TraceID, r.SpanID, r.ParentSpanID, r.Name, r.ServiceName, r.SpanKind,
+		r.StartTime, r.EndTime, r.DurationMs, r.StatusCode, r.StatusMessage,
+		r.K8sPodName, r.EventCount, r.LinkCount, r.HasChildren, r.Attributes,
+	}
+}
+
+// logColumns is the table layout for exported log records.
+var logColumns = []column{
+	{"timestamp", "TEXT"},
+	{"service_name", "TEXT"},
+	{"severity_text", "TEXT"},
+	{"severity_number", "INTEGER"},
+	{"body", "TEXT"},
+	{"trace_id", "TEXT"},
+	{"span_id", "TEXT"},
+	{"k8s_namespace", "TEXT"},
+	{"k8s_pod_name", "TEXT"},
+	{"k8s_container_name", "TEXT"},
+	{"attributes", "TEXT"},
+}
+
+// logRow is a single exported log record. Field order matches logColumns.
+type logRow struct {
+	Timestamp        string `parquet:"timestamp"`
+	ServiceName      string `parquet:"service_name"`
+	SeverityText     string `parquet:"severity_text"`
+	SeverityNumber   int64  `parquet:"severity_number"`
+	Body             string `parquet:"body"`
+	TraceID          string `parquet:"trace_id"`
+	SpanID           string `parquet:"span_id"`
+	K8sNamespace     string `parquet:"k8s_namespace"`
+	K8sPodName       string `parquet:"k8s_pod_name"`
+	K8sContainerName string `parquet:"k8s_container_name"`
+	Attributes       string `parquet:"attributes"`
+}
+
+func newLogRow(l logs.FlatLog) logRow {
+	return logRow{
+		Timestamp:        l.Timestamp,
+		ServiceName:      l.ServiceName,
+		SeverityText:     l.SeverityText,
+		SeverityNumber:   int64(l.SeverityNumber),
+		Body:             l.Body,
+		TraceID:          l.TraceID,
+		SpanID:           l.SpanID,
+		K8sNamespace:     l.K8sNamespace,
+		K8sPodName:       l.K8sPodName,
+		K8sContainerName: l.K8sContainerName,
+		Attributes:       attributesJSON(l.Attributes),
+	}
+}
+
+func (r logRow) values() []interface{} {
+	return []interface{}{
+		r.Timestamp, r.ServiceName, r.SeverityText, r.SeverityNumber, r.Body,
+		r.TraceID, r.SpanID, r.K8sNamespace, r.K8sPodName, r.K8sContainerName,
+		r.Attributes,
+	}
+}
+
+// joinedColumns is the table layout for spans joined with their correlated logs.
+var joinedColumns = []column{
+	{"trace_id", "TEXT"},
+	{"span_id", "TEXT"},
+	{"parent_span_id", "TEXT"},
+	{"span_name", "TEXT"},
+	{"service_name", "TEXT"},
+	{"span_kind", "TEXT"},
+	{"start_time", "TEXT"},
+	{"end_time", "TEXT"},
+	{"duration_ms", "REAL"},
+	{"status_code", "INTEGER"},
+	{"log_timestamp", "TEXT"},
+	{"log_offset_ms", "REAL"},
+	{"severity_text", "TEXT"},
+	{"severity_number", "INTEGER"},
+	{"log_body", "TEXT"},
+	{"log_attributes", "TEXT"},
+}
+
+// joinedRow is a span paired with one correlated log record. Spans without
+// correlated logs produce a single row whose log fields are NULL.
+// Field order matches joinedColumns.
+type joinedRow struct {
+	TraceID        string   `parquet:"trace_id"`
+	SpanID         string   `parquet:"span_id"`
+	ParentSpanID   string   `parquet:"parent_span_id"`
+	SpanName       string   `parquet:"span_name"`
+	ServiceName    string   `parquet:"service_name"`
+	SpanKind       string   `parquet:"span_kind"`
+	StartTime      string   `parquet:"start_time"`
+	EndTime        string   `parquet:"end_time"`
+	DurationMs     float64  `parquet:"duration_ms"`
+	StatusCode     int64    `parquet:"status_code"`
+	LogTimestamp   *string  `parquet:"log_timestamp,optional"`
+	LogOffsetMs    *float64 `parquet:"log_offset_ms,optional"`
+	SeverityText   *string  `parquet:"severity_text,optional"`
+	SeverityNumber *int64   `parquet:"severity_number,optional"`
+	LogBody        *string  `parquet:"log_body,optional"`
+	LogAttributes  *string  `parquet:"log_attributes,optional"`
+}
+
+func (r joinedRow) values() []interface{} {
+	return []interface{}{
+		r.TraceID, r.SpanID, r.ParentSpanID, r.SpanName, r.ServiceName, r.SpanKind,
+		r.StartTime, r.EndTime, r.DurationMs, r.StatusCode,
+		nullable(r.LogTimestamp), nullable(r.LogOffsetMs), nullable(r.SeverityText),
+		nullable(r.SeverityNumber), nullable(r.LogBody), nullable(r.LogAttributes),
+	}
+}
+
+// nullable converts a nil pointer into a SQL NULL and dereferences others.
+func nullable[T any](v *T) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// joinSpansAndLogs left-joins spans with logs on trace_id and span_id.
+// Logs that carry a trace_id but no span_id are not attributed to a span.
+// It returns the joined rows and the number of logs that matched a span.
+func joinSpansAndLogs(flatSpans []spans.FlatSpan, flatLogs []logs.FlatLog) ([]joinedRow, int) {
+	type spanKey struct{ traceID, spanID string }
+
+	logsBySpan := make(map[spanKey][]logs.FlatLog)
+	for _, l := range flatLogs {
+		if l.TraceID == "" || l.SpanID == "" {
+			continue
+		}
+		key := spanKey{l.TraceID, l.SpanID}
+		logsBySpan[key] = append(logsBySpan[key], l)
+	}
+
+	var rows []joinedRow
+	matched := 0
+	for _, s := range flatSpans {
+		base := joinedRow{
+			TraceID:      s.TraceID,
+			SpanID:       s.SpanID,
+			ParentSpanID: s.ParentSpanID,
+			SpanName:     s.Name,
+			ServiceName:  s.ServiceName,
+			SpanKind:     s.SpanKind,
+			StartTime:    s.StartTime,
+			EndTime:      s.EndTime,
+			DurationMs:   s.DurationMs,
+			StatusCode:   int64(s.StatusCode),
+		}
+
+		correlated := logsBySpan[spanKey{s.TraceID, s.SpanID}]
+		if len(correlated) == 0 {
+			rows = append(rows, base)
+			continue
+		}
+
+		spanStart, startErr := time.Parse(time.RFC3339Nano, s.StartTime)
+		for _, l := range correlated {
+			row := base
+			ts, sev, body, attrs := l.Timestamp, l.SeverityText, l.Body, attributesJSON(l.Attributes)
+			sevNum := int64(l.SeverityNumber)
+			row.LogTimestamp = &ts
+			row.SeverityText = &sev
+			row.SeverityNumber = &sevNum
+			row.LogBody = &body
+			row.LogAttributes = &attrs
+			if logTime, err := time.Parse(time.RFC3339Nano, l.Timestamp); err == nil && startErr == nil {
+				offset := float64(logTime.Sub(spanStart).Nanoseconds()) / 1_000_000
+				row.LogOffsetMs = &offset
+			}
+			rows = append(rows, row)
+			matched++
+		}
+	}
+
+	return rows, matched
+}
+
+// attributesJSON encodes an attribute map as a JSON string column value.
+func attributesJSON(attrs map[string]interface{}) string {
+	if len(attrs) == 0 {
+		return "{}"
+	}
+	data, err := json.Marshal(attrs)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// writeSQLite writes rows into the named table of a SQLite database file.
+// Other tables in an existing database are left untouched, so several exports
+// can share one file and be joined with SQL.
+func writeSQLite(path, table string, columns []column, rows [][]interface{}, overwrite bool) error {
+	if err := prepareDatabase(path); err != nil {
+		return err
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	var existing string
+	err = db.QueryRow("SELECT name FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&existing)
+	if err == nil && !overwrite {
+		return fmt.Errorf("table %s already exists in %s (set overwrite=true to replace it)", table, path)
+	}
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to inspect database: %w", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	defs := make([]string, len(columns))
+	placeholders := make([]string, len(columns))
+	for i, col := range columns {
+		defs[i] = fmt.Sprintf("%q %s", col.Name, col.Type)
+		placeholders[i] = "?"
+	}
+
+	if _, err := tx.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %q", table)); err != nil {
+		return fmt.Errorf("failed to drop table: %w", err)
+	}
+	if _, err := tx.Exec(fmt.Sprintf("CREATE TABLE %q (%s)", table, strings.Join(defs, ", "))); err != nil {
+		return fmt.Errorf("failed to create table: %w", err)
+	}
+
+	stmt, err := tx.Prepare(fmt.Sprintf("INSERT INTO %q VALUES (%s)", table, strings.Join(placeholders, ", ")))
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, row := range rows {
+		if _, err := stmt.Exec(row...); err != nil {
+			return fmt.Errorf("failed to insert row: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// writeParquet writes rows to a Parquet file using the struct's parquet tags as the schema.
+func writeParquet[T any](path string, rows []T, overwrite bool) error {
+	f, err := createFile(path, overwrite)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := parquet.NewGenericWriter[T](f)
+	if _, err := w.Write(rows); err != nil {
+		return fmt.Errorf("failed to write rows: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to finalize parquet file: %w", err)
+	}
+	return f.Close()
+}
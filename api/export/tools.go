@@ -0,0 +1,602 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/api/logs"
+	"github.com/npcomplete777/dash0-mcp/api/spans"
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+	"github.com/npcomplete777/dash0-mcp/internal/otlp"
+	"github.com/npcomplete777/dash0-mcp/internal/progress"
+	"github.com/npcomplete777/dash0-mcp/internal/registry"
+	mcp "github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	spansPath = "/api/spans"
+	logsPath  = "/api/logs"
+
+	// Page sizes match the per-request caps of the spans and logs query tools.
+	spansPageSize = 200
+	logsPageSize  = 500
+
+	defaultMaxRows = 1000
+	maxMaxRows     = 10000
+)
+
+// tableNamePattern restricts table names to safe SQL identifiers.
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Compile-time interface check.
+var _ registry.ToolProvider = (*Tools)(nil)
+
+// Tools provides MCP tools for exporting query results.
+type Tools struct {
+	client *client.Client
+	// exportDir is the directory files are written to; empty disables
+	// writing files.
+	exportDir string
+}
+
+// New creates a new Export tools instance.
+func New(c *client.Client) *Tools {
+	return &Tools{client: c}
+}
+
+// SetExportDir sets the directory the path arguments are relative to
+// (DASH0_MCP_EXPORT_DIR). Without one, the tools refuse to write files.
+func (p *Tools) SetExportDir(dir string) {
+	p.exportDir = dir
+}
+
+// Tools returns all MCP tools in this package.
+func (p *Tools) Tools() []mcp.Tool {
+	tools := []mcp.Tool{
+		p.ExportTable(),
+		p.ExportAll(),
+	}
+	for _, col := range configCollections {
+		tools = append(tools, exportResourceTool(col))
+	}
+	return tools
+}
+
+// Handlers returns a map of tool name to handler function.
+func (p *Tools) Handlers() map[string]func(context.Context, map[string]interface{}) *client.ToolResult {
+	handlers := map[string]func(context.Context, map[string]interface{}) *client.ToolResult{
+		"dash0_export_table": p.ExportTableHandler,
+		"dash0_export_all":   p.ExportAllHandler,
+	}
+	for _, col := range configCollections {
+		handlers[exportResourceName(col)] = p.exportResourceHandler(col)
+	}
+	return handlers
+}
+
+// ExportTable returns the dash0_export_table tool definition.
+func (p *Tools) ExportTable() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_export_table",
+		Description: `Export spans or logs to a local SQLite database or Parquet file with typed columns.
+
+Pages through the query until max_rows is reached or no more results are available,
+then writes one row per span/log record. Attributes are stored as a JSON text column.
+
+The "joined" signal fetches both spans and logs and writes one table keyed by
+trace_id/span_id: each span is combined with every log emitted inside it (log fields
+are NULL for spans without logs), including the log's offset from the span start.
+
+SQLite exports write a single table and leave other tables in the file untouched,
+so spans and logs can be exported into the same database and joined on trace_id.
+Parquet files can be queried directly with duckdb.
+
+Files are written only inside the export directory (DASH0_MCP_EXPORT_DIR); path
+must be relative to it. Without an export directory the tool writes nothing.
+
+Example exports:
+- Spans for a service to SQLite: {"signal": "spans", "path": "dash0.db", "service_name": "cart"}
+- Logs to the same database: {"signal": "logs", "path": "dash0.db", "table": "logs"}
+- Last 24h of spans to Parquet: {"signal": "spans", "format": "parquet", "path": "spans.parquet", "time_range_minutes": 1440}
+- Spans with their logs in one table: {"signal": "joined", "path": "dash0.db", "service_name": "cart"}`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"signal": map[string]interface{}{
+					"type":        "string",
+					"description": "Telemetry signal to export. 'joined' combines spans with their correlated logs.",
+					"enum":        []string{"spans", "logs", "joined"},
+				},
+				"path": map[string]interface{}{
+					"type":        "string",
+					"description": "File to write, relative to the export directory (DASH0_MCP_EXPORT_DIR); parent directories are created",
+				},
+				"format": map[string]interface{}{
+					"type":        "string",
+					"description": "Output format (default: sqlite)",
+					"enum":        []string{"sqlite", "parquet"},
+				},
+				"table": map[string]interface{}{
+					"type":        "string",
+					"description": "SQLite table name (default: the signal name). Ignored for parquet.",
+				},
+				"overwrite": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Replace an existing table (sqlite) or file (parquet). Default: false",
+				},
+				"service_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter by service name (exact match)",
+				},
+				"time_range_minutes": map[string]interface{}{
+					"type":        "integer",
+					"description": "Minutes back to search (default: 60, max: 1440)",
+				},
+				"max_rows": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum rows to export (default: 1000, max: 10000). For 'joined', applies to spans and logs fetched separately.",
+				},
+				"dataset": map[string]interface{}{
+					"type":        "string",
+					"description": "Dash0 dataset to query (e.g., 'astronomy-demo'). If omitted, uses the globally configured dataset or 'default'.",
+				},
+			},
+			Required: []string{"signal", "path"},
+		},
+	}
+}
+
+// ExportTableHandler handles the dash0_export_table tool.
+func (p *Tools) ExportTableHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	signal, _ := args["signal"].(string)
+	if signal != "spans" && signal != "logs" && signal != "joined" {
+		return client.ErrorResult(400, "signal must be 'spans', 'logs', or 'joined'")
+	}
+
+	path, _ := args["path"].(string)
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return client.ErrorResult(400, "path is required")
+	}
+
+	format := "sqlite"
+	if f, ok := args["format"].(string); ok && f != "" {
+		format = f
+	}
+	if format != "sqlite" && format != "parquet" {
+		return client.ErrorResult(400, "format must be 'sqlite' or 'parquet'")
+	}
+
+	table := signal
+	if t, ok := args["table"].(string); ok && strings.TrimSpace(t) != "" {
+		table = strings.TrimSpace(t)
+	}
+	if !tableNamePattern.MatchString(table) {
+		return client.ErrorResult(400, "table must start with a letter or underscore and contain only letters, digits, and underscores")
+	}
+
+	overwrite, _ := args["overwrite"].(bool)
+
+	// Calculate time range
+	now := time.Now().UTC()
+	minutes := 60
+	if m, ok := args["time_range_minutes"].(float64); ok {
+		if m < 0 {
+			return client.ErrorResult(400, "time_range_minutes must not be negative")
+		}
+		if m > 0 {
+			minutes = int(m)
+			if minutes > 1440 {
+				minutes = 1440 // Max 24 hours
+			}
+		}
+	}
+	from := now.Add(-time.Duration(minutes) * time.Minute)
+
+	maxRows := defaultMaxRows
+	if m, ok := args["max_rows"].(float64); ok {
+		if m < 0 {
+			return client.ErrorResult(400, "max_rows must not be negative")
+		}
+		if m > 0 {
+			maxRows = int(m)
+			if maxRows > maxMaxRows {
+				maxRows = maxMaxRows
+			}
+		}
+	}
+
+	// Resolve dataset: per-tool param overrides global config
+	dataset := ""
+	if ds, ok := args["dataset"].(string); ok && ds != "" {
+		dataset = ds
+	} else {
+		dataset = p.client.GetDataset()
+	}
+
+	var filters []otlp.AttributeFilter
+	if serviceName, ok := args["service_name"].(string); ok {
+		serviceName = strings.TrimSpace(serviceName)
+		if serviceName != "" {
+			filters = append(filters, otlp.AttributeFilter{
+				Key:      "service.name",
+				Operator: "is",
+				Value:    &otlp.AttributeFilterValue{StringValue: &serviceName},
+			})
+		}
+	}
+
+	timeRange := otlp.TimeRange{
+		From: from.Format(time.RFC3339),
+		To:   now.Format(time.RFC3339),
+	}
+
+	path, err := p.resolvePath(path)
+	if err != nil {
+		return client.ErrorResult(403, err.Error())
+	}
+
+	var columns []column
+	var rowCount, pages int
+	var truncated bool
+	var writeErr error
+	var extra map[string]interface{}
+
+	switch signal {
+	case "spans":
+		columns = spanColumns
+		flat, n, more, errResult := fetchPages(ctx, p.client, "spans", spansPath, dataset, spansPageSize, maxRows,
+			func(pg otlp.Pagination) interface{} {
+				return spans.QuerySpansRequest{Dataset: dataset, TimeRange: timeRange, Filter: filters, Pagination: pg}
+			},
+			spans.FlattenResponse,
+		)
+		if errResult != nil {
+			return errResult
+		}
+		rows := make([]spanRow, len(flat))
+		for i, s := range flat {
+			rows[i] = newSpanRow(s)
+		}
+		rowCount, pages, truncated = len(rows), n, more
+		if format == "parquet" {
+			writeErr = writeParquet(path, rows, overwrite)
+		} else {
+			values := make([][]interface{}, len(rows))
+			for i, r := range rows {
+				values[i] = r.values()
+			}
+			writeErr = writeSQLite(path, table, columns, values, overwrite)
+		}
+	case "logs":
+		columns = logColumns
+		flat, n, more, errResult := fetchPages(ctx, p.client, "logs", logsPath, dataset, logsPageSize, maxRows,
+			func(pg otlp.Pagination) interface{} {
+				return logs.QueryLogsRequest{Dataset: dataset, TimeRange: timeRange, Filter: filters, Pagination: pg}
+			},
+			logs.FlattenResponse,
+		)
+		if errResult != nil {
+			return errResult
+		}
+		rows := make([]logRow, len(flat))
+		for i, l := range flat {
+			rows[i] = newLogRow(l)
+		}
+		rowCount, pages, truncated = len(rows), n, more
+		if format == "parquet" {
+			writeErr = writeParquet(path, rows, overwrite)
+		} else {
+			values := make([][]interface{}, len(rows))
+			for i, r := range rows {
+				values[i] = r.values()
+			}
+			writeErr = writeSQLite(path, table, columns, values, overwrite)
+		}
+	case "joined":
+		columns = joinedColumns
+		// Spans and logs are paged independently, so both run at once.
+		var flatSpans []spans.FlatSpan
+		var flatLogs []logs.FlatLog
+		var spanPages, logPages int
+		var moreSpans, moreLogs bool
+		errResult := p.client.Parallel(ctx, 2, func(ctx context.Context, i int) *client.ToolResult {
+			var errResult *client.ToolResult
+			if i == 0 {
+				flatSpans, spanPages, moreSpans, errResult = fetchPages(ctx, p.client, "spans", spansPath, dataset, spansPageSize, maxRows,
+					func(pg otlp.Pagination) interface{} {
+						return spans.QuerySpansRequest{Dataset: dataset, TimeRange: timeRange, Filter: filters, Pagination: pg}
+					},
+					spans.FlattenResponse,
+				)
+			} else {
+				flatLogs, logPages, moreLogs, errResult = fetchPages(ctx, p.client, "logs", logsPath, dataset, logsPageSize, maxRows,
+					func(pg otlp.Pagination) interface{} {
+						return logs.QueryLogsRequest{Dataset: dataset, TimeRange: timeRange, Filter: filters, Pagination: pg}
+					},
+					logs.FlattenResponse,
+				)
+			}
+			return errResult
+		})
+		if errResult != nil {
+			return errResult
+		}
+		rows, matched := joinSpansAndLogs(flatSpans, flatLogs)
+		rowCount, pages, truncated = len(rows), spanPages+logPages, moreSpans || moreLogs
+		extra = map[string]interface{}{
+			"spans":        len(flatSpans),
+			"logs":         len(flatLogs),
+			"matched_logs": matched,
+		}
+		if format == "parquet" {
+			writeErr = writeParquet(path, rows, overwrite)
+		} else {
+			values := make([][]interface{}, len(rows))
+			for i, r := range rows {
+				values[i] = r.values()
+			}
+			writeErr = writeSQLite(path, table, columns, values, overwrite)
+		}
+	}
+
+	if writeErr != nil {
+		return client.ErrorResult(500, fmt.Sprintf("export failed: %v", writeErr))
+	}
+
+	if format == "parquet" {
+		table = ""
+	}
+
+	data := map[string]interface{}{
+		"signal":    signal,
+		"format":    format,
+		"path":      path,
+		"table":     table,
+		"rows":      rowCount,
+		"pages":     pages,
+		"truncated": truncated,
+	}
+	for k, v := range extra {
+		data[k] = v
+	}
+
+	md := formatExportMarkdown(signal, format, path, table, columns, rowCount, pages, truncated)
+	if extra != nil {
+		md += fmt.Sprintf("\n_Joined %d spans with %d logs (%d logs matched a span)._\n", extra["spans"], extra["logs"], extra["matched_logs"])
+	}
+
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: md,
+		Data:     data,
+	}
+}
+
+// fetchPages pages through a query until maxRows records are collected or the
+// API reports no further pages. It returns the records, the number of pages
+// fetched, and whether more results were available beyond maxRows. Each page
+// of exported records is streamed to the client when it asked for progress.
+func fetchPages[T any](
+	ctx context.Context,
+	c *client.Client,
+	noun, path, dataset string,
+	pageSize, maxRows int,
+	newRequest func(otlp.Pagination) interface{},
+	flatten func(interface{}) []T,
+) ([]T, int, bool, *client.ToolResult) {
+	var records []T
+	var cursor string
+	pages := 0
+
+	for len(records) < maxRows {
+		limit := pageSize
+		if remaining := maxRows - len(records); remaining < limit {
+			limit = remaining
+		}
+
+		result := c.PostWithDataset(ctx, path, newRequest(otlp.Pagination{Limit: limit, Cursor: cursor}), dataset)
+		if !result.Success {
+			return nil, pages, false, result
+		}
+		pages++
+
+		page := flatten(result.Data)
+		if remaining := maxRows - len(records); len(page) > remaining {
+			page = page[:remaining]
+		}
+		records = append(records, page...)
+		progress.ReportItems(ctx, noun, page, c.ResponseLimits())
+
+		cursor = otlp.NextCursor(result.Data)
+		if cursor == "" || len(page) == 0 {
+			return records, pages, false, nil
+		}
+	}
+	return records, pages, cursor != "", nil
+}
+
+// formatExportMarkdown renders a summary of a completed export.
+func formatExportMarkdown(signal, format, path, table string, columns []column, rows, pages int, truncated bool) string {
+	noun := signal
+	if signal == "joined" {
+		noun = "joined rows"
+	}
+	summaryParts := []string{fmt.Sprintf("**Exported %d %s** to `%s`", rows, noun, path)}
+	summaryParts = append(summaryParts, "Format: "+format)
+	if table != "" {
+		summaryParts = append(summaryParts, "Table: "+table)
+	}
+	summaryParts = append(summaryParts, fmt.Sprintf("Pages: %d", pages))
+	summary := strings.Join(summaryParts, " | ")
+
+	headers := []string{"Column", "Type"}
+	tableRows := make([][]string, len(columns))
+	for i, col := range columns {
+		tableRows[i] = []string{col.Name, col.Type}
+	}
+
+	var footer string
+	if format == "sqlite" {
+		footer = fmt.Sprintf("_Query with: `sqlite3 %s \"SELECT * FROM %s LIMIT 10\"`_", path, table)
+	} else {
+		footer = fmt.Sprintf("_Query with: `duckdb -c \"SELECT * FROM '%s' LIMIT 10\"`_", path)
+	}
+	if truncated {
+		footer = "_More results were available; increase `max_rows` or narrow the time range._\n\n" + footer
+	}
+
+	return formatter.Table("Export Complete", summary, headers, tableRows, footer)
+}
+
+// bundleProperties are the output arguments shared by the configuration
+// export tools.
+func bundleProperties() map[string]interface{} {
+	return map[string]interface{}{
+		"format": map[string]interface{}{
+			"type":        "string",
+			"description": "Bundle format (default: json)",
+			"enum":        []string{"json", "yaml"},
+		},
+		"path": map[string]interface{}{
+			"type":        "string",
+			"description": "File to write the bundle to, relative to the export directory (DASH0_MCP_EXPORT_DIR); parent directories are created. If omitted, the bundle is returned inline.",
+		},
+	}
+}
+
+// ExportAll returns the dash0_export_all tool definition.
+func (p *Tools) ExportAll() mcp.Tool {
+	props := bundleProperties()
+	props["resources"] = map[string]interface{}{
+		"type":        "array",
+		"items":       map[string]interface{}{"type": "string", "enum": configCollectionNames()},
+		"description": "Object types to include (default: all)",
+	}
+	return mcp.Tool{
+		Name: "dash0_export_all",
+		Description: `Export all Dash0 configuration as a single bundle for configuration-as-code workflows.
+
+Fetches the full definition of every dashboard, view, check rule, synthetic check, and
+sampling rule in the dataset and returns them as one document keyed by resource type.
+Server-maintained fields (timestamps, versions, status) are dropped so the bundle diffs
+cleanly when committed to a GitOps repository.
+
+Example exports:
+- Everything as YAML: {"format": "yaml"}
+- Alerting config to a file: {"resources": ["check_rules"], "format": "yaml", "path": "dash0/alerts.yaml"}`,
+		InputSchema: mcp.ToolInputSchema{
+			Type:       "object",
+			Properties: props,
+		},
+	}
+}
+
+// ExportAllHandler handles the dash0_export_all tool.
+func (p *Tools) ExportAllHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	cols, err := selectConfigCollections(args["resources"])
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+	return p.exportConfig(ctx, cols, args)
+}
+
+func exportResourceName(col configCollection) string {
+	return "dash0_export_" + col.Name
+}
+
+// exportResourceTool returns the definition of a single-resource export tool.
+func exportResourceTool(col configCollection) mcp.Tool {
+	return mcp.Tool{
+		Name: exportResourceName(col),
+		Description: fmt.Sprintf(`Export all %[1]s as a bundle for configuration-as-code workflows.
+
+Same as dash0_export_all restricted to %[1]s: full definitions without server-maintained
+fields, as JSON or YAML, returned inline or written to path.`, col.Title),
+		InputSchema: mcp.ToolInputSchema{
+			Type:       "object",
+			Properties: bundleProperties(),
+		},
+	}
+}
+
+// exportResourceHandler returns the handler of a single-resource export tool.
+func (p *Tools) exportResourceHandler(col configCollection) func(context.Context, map[string]interface{}) *client.ToolResult {
+	return func(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+		return p.exportConfig(ctx, []configCollection{col}, args)
+	}
+}
+
+// exportConfig fetches the collections into a bundle and returns or writes it.
+func (p *Tools) exportConfig(ctx context.Context, cols []configCollection, args map[string]interface{}) *client.ToolResult {
+	format := "json"
+	if f, ok := args["format"].(string); ok && f != "" {
+		format = f
+	}
+	if format != "json" && format != "yaml" {
+		return client.ErrorResult(400, "format must be 'json' or 'yaml'")
+	}
+	path, _ := args["path"].(string)
+	path = strings.TrimSpace(path)
+
+	bundle := map[string]interface{}{}
+	if ds := p.client.Dataset(ctx); ds != "" {
+		bundle["dataset"] = ds
+	}
+	rows := make([][]string, 0, len(cols))
+	total := 0
+	for _, col := range cols {
+		objects, errResult := fetchCollection(ctx, p.client, col)
+		if errResult != nil {
+			return errResult
+		}
+		bundle[col.Name] = objects
+		rows = append(rows, []string{col.Name, fmt.Sprintf("%d", len(objects))})
+		total += len(objects)
+	}
+
+	content, err := encodeBundle(bundle, format)
+	if err != nil {
+		return client.ErrorResult(500, fmt.Sprintf("failed to encode bundle: %v", err))
+	}
+
+	summary := fmt.Sprintf("**Exported %d objects** as %s", total, format)
+	var footer string
+	if path != "" {
+		if path, err = p.resolvePath(path); err != nil {
+			return client.ErrorResult(403, err.Error())
+		}
+		if err := writeBundle(path, content); err != nil {
+			return client.ErrorResult(500, err.Error())
+		}
+		summary += fmt.Sprintf(" to `%s`", path)
+	} else {
+		footer = fmt.Sprintf("```%s\n%s```", format, content)
+	}
+
+	data := map[string]interface{}{"bundle": bundle, "format": format}
+	if path != "" {
+		data["path"] = path
+	}
+	return &client.ToolResult{
+		Success:  true,
+		Data:     data,
+		Markdown: formatter.Table("Configuration Export", summary, []string{"Resource", "Objects"}, rows, footer),
+	}
+}
+
+// Register registers all export tools with the registry.
+// exportDir is the directory files are written to (DASH0_MCP_EXPORT_DIR).
+func Register(reg *registry.Registry, c *client.Client, exportDir string) {
+	p := New(c)
+	p.SetExportDir(exportDir)
+	for _, tool := range p.Tools() {
+		handler := p.Handlers()[tool.Name]
+		reg.Register(tool, handler)
+		reg.AddExamples(tool.Name, p.Examples()[tool.Name]...)
+	}
+}
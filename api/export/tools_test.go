@@ -0,0 +1,705 @@
+package export
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/npcomplete777/dash0-mcp/api/spans"
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/progress"
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestNew(t *testing.T) {
+	c := &client.Client{}
+	pkg := New(c)
+	if pkg == nil {
+		t.Fatal("New() returned nil")
+	}
+	if pkg.client != c {
+		t.Error("New() did not set client correctly")
+	}
+}
+
+func TestTools(t *testing.T) {
+	pkg := New(&client.Client{})
+	tools := pkg.Tools()
+
+	if len(tools) != 7 {
+		t.Fatalf("Tools() returned %d tools, expected 7", len(tools))
+	}
+
+	handlers := pkg.Handlers()
+	for _, name := range []string{
+		"dash0_export_table",
+		"dash0_export_all",
+		"dash0_export_dashboards",
+		"dash0_export_views",
+		"dash0_export_check_rules",
+		"dash0_export_synthetic_checks",
+		"dash0_export_sampling_rules",
+	} {
+		if _, ok := handlers[name]; !ok {
+			t.Errorf("Missing handler for %s", name)
+		}
+	}
+	for _, tool := range tools {
+		if _, ok := handlers[tool.Name]; !ok {
+			t.Errorf("Tool %s has no handler", tool.Name)
+		}
+	}
+}
+
+func TestExportTableToolDefinition(t *testing.T) {
+	tool := New(&client.Client{}).ExportTable()
+
+	if tool.InputSchema.Type != "object" {
+		t.Errorf("schema type = %s, expected object", tool.InputSchema.Type)
+	}
+
+	required := map[string]bool{}
+	for _, r := range tool.InputSchema.Required {
+		required[r] = true
+	}
+	if !required["signal"] || !required["path"] {
+		t.Errorf("Required = %v, expected signal and path", tool.InputSchema.Required)
+	}
+}
+
+func TestExportTableHandler_Validation(t *testing.T) {
+	pkg := New(client.NewWithBaseURL("http://unused", "test-token"))
+	pkg.SetExportDir(t.TempDir())
+
+	tests := []struct {
+		name        string
+		args        map[string]interface{}
+		expectError string
+	}{
+		{
+			name:        "missing signal",
+			args:        map[string]interface{}{"path": "x.db"},
+			expectError: "signal must be",
+		},
+		{
+			name:        "invalid signal",
+			args:        map[string]interface{}{"signal": "metrics", "path": "x.db"},
+			expectError: "signal must be",
+		},
+		{
+			name:        "missing path",
+			args:        map[string]interface{}{"signal": "spans"},
+			expectError: "path is required",
+		},
+		{
+			name:        "invalid format",
+			args:        map[string]interface{}{"signal": "spans", "path": "x.csv", "format": "csv"},
+			expectError: "format must be",
+		},
+		{
+			name:        "invalid table name",
+			args:        map[string]interface{}{"signal": "spans", "path": "x.db", "table": "spans; DROP TABLE x"},
+			expectError: "table must start",
+		},
+		{
+			name:        "negative max_rows",
+			args:        map[string]interface{}{"signal": "spans", "path": "x.db", "max_rows": float64(-1)},
+			expectError: "max_rows must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := pkg.ExportTableHandler(context.Background(), tt.args)
+			if result.Success {
+				t.Fatal("Expected error, got success")
+			}
+			if !strings.Contains(result.Error.Detail, tt.expectError) {
+				t.Errorf("Error = %q, expected to contain %q", result.Error.Detail, tt.expectError)
+			}
+		})
+	}
+}
+
+// spanPage builds an OTLP spans response with n spans and an optional next cursor.
+func spanPage(start, n int, next string) map[string]interface{} {
+	spanList := make([]interface{}, n)
+	for i := 0; i < n; i++ {
+		spanList[i] = map[string]interface{}{
+			"traceId":           fmt.Sprintf("trace-%d", start+i),
+			"spanId":            fmt.Sprintf("span-%d", start+i),
+			"name":              "GET /api/cart",
+			"kind":              float64(2),
+			"startTimeUnixNano": "1700000000000000000",
+			"endTimeUnixNano":   "1700000000250000000",
+			"status":            map[string]interface{}{"code": float64(2)},
+			"attributes": []interface{}{
+				map[string]interface{}{"key": "http.route", "value": map[string]interface{}{"stringValue": "/api/cart"}},
+			},
+		}
+	}
+	resp := map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": []interface{}{
+						map[string]interface{}{"key": "service.name", "value": map[string]interface{}{"stringValue": "cart"}},
+					},
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{"spans": spanList},
+				},
+			},
+		},
+	}
+	if next != "" {
+		resp["cursors"] = map[string]interface{}{"after": next}
+	}
+	return resp
+}
+
+func TestExportTableHandler_Paths(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(spanPage(0, 1, ""))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(dir, "elsewhere")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "target.parquet"), filepath.Join(dir, "link.parquet")); err != nil {
+		t.Fatal(err)
+	}
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+
+	export := func(path string) *client.ToolResult {
+		return pkg.ExportTableHandler(context.Background(), map[string]interface{}{
+			"signal": "spans",
+			"format": "parquet",
+			"path":   path,
+		})
+	}
+
+	if result := export("spans.parquet"); result.Success || !strings.Contains(result.Error.Detail, "DASH0_MCP_EXPORT_DIR") {
+		t.Errorf("export without an export directory = %+v, want it disabled", result.Error)
+	}
+
+	pkg.SetExportDir(dir)
+	tests := []struct {
+		name        string
+		path        string
+		expectError string
+	}{
+		{name: "absolute path", path: filepath.Join(outside, "spans.parquet"), expectError: "must be relative"},
+		{name: "parent directory", path: filepath.Join("..", filepath.Base(outside), "spans.parquet"), expectError: "must be relative"},
+		{name: "symlinked directory", path: filepath.Join("elsewhere", "spans.parquet"), expectError: "through a symlink"},
+		{name: "symlinked directory to create", path: filepath.Join("elsewhere", "new", "spans.parquet"), expectError: "through a symlink"},
+		{name: "symlinked file", path: "link.parquet", expectError: "already exists"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := export(tt.path)
+			if result.Success {
+				t.Fatal("Expected error, got success")
+			}
+			if !strings.Contains(result.Error.Detail, tt.expectError) {
+				t.Errorf("Error = %q, expected to contain %q", result.Error.Detail, tt.expectError)
+			}
+		})
+	}
+	if entries, _ := os.ReadDir(outside); len(entries) != 0 {
+		t.Errorf("files written outside the export directory: %v", entries)
+	}
+
+	if result := export(filepath.Join("nested", "spans.parquet")); !result.Success {
+		t.Fatalf("export inside the export directory failed: %v", result.Error)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "nested", "spans.parquet")); err != nil {
+		t.Errorf("export not written to the export directory: %v", err)
+	}
+}
+
+func TestExportTableHandler_SQLitePaging(t *testing.T) {
+	var cursors []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/spans" {
+			t.Errorf("Expected /api/spans, got %s", r.URL.Path)
+		}
+		var req spans.QuerySpansRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		cursors = append(cursors, req.Pagination.Cursor)
+
+		if req.Pagination.Cursor == "" {
+			json.NewEncoder(w).Encode(spanPage(0, 3, "page-2"))
+			return
+		}
+		json.NewEncoder(w).Encode(spanPage(3, 2, ""))
+	}))
+	defer server.Close()
+
+	name := filepath.Join("out", "dash0.db")
+	dir := t.TempDir()
+	path := filepath.Join(dir, name)
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	pkg.SetExportDir(dir)
+
+	result := pkg.ExportTableHandler(context.Background(), map[string]interface{}{
+		"signal": "spans",
+		"path":   name,
+	})
+	if !result.Success {
+		t.Fatalf("ExportTableHandler failed: %v", result.Error)
+	}
+
+	if len(cursors) != 2 || cursors[0] != "" || cursors[1] != "page-2" {
+		t.Errorf("cursors sent = %v, expected [\"\" \"page-2\"]", cursors)
+	}
+
+	data := result.Data.(map[string]interface{})
+	if data["rows"] != 5 {
+		t.Errorf("rows = %v, expected 5", data["rows"])
+	}
+	if data["pages"] != 2 {
+		t.Errorf("pages = %v, expected 2", data["pages"])
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("failed to open exported database: %v", err)
+	}
+	defer db.Close()
+
+	var count int
+	var avgDuration float64
+	if err := db.QueryRow(`SELECT COUNT(*), AVG(duration_ms) FROM spans WHERE status_code = 2`).Scan(&count, &avgDuration); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if count != 5 {
+		t.Errorf("COUNT(*) = %d, expected 5", count)
+	}
+	if avgDuration != 250 {
+		t.Errorf("AVG(duration_ms) = %v, expected 250", avgDuration)
+	}
+
+	var attrs string
+	if err := db.QueryRow(`SELECT attributes FROM spans LIMIT 1`).Scan(&attrs); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if !strings.Contains(attrs, `"http.route":"/api/cart"`) {
+		t.Errorf("attributes = %s, expected http.route", attrs)
+	}
+
+	if !strings.Contains(result.Markdown, "Exported 5 spans") {
+		t.Errorf("Markdown missing summary: %s", result.Markdown)
+	}
+}
+
+func TestExportTableHandler_MaxRowsTruncates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(spanPage(0, 2, "more"))
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	pkg.SetExportDir(t.TempDir())
+
+	result := pkg.ExportTableHandler(context.Background(), map[string]interface{}{
+		"signal":   "spans",
+		"path":     "dash0.db",
+		"max_rows": float64(4),
+	})
+	if !result.Success {
+		t.Fatalf("ExportTableHandler failed: %v", result.Error)
+	}
+
+	data := result.Data.(map[string]interface{})
+	if data["rows"] != 4 {
+		t.Errorf("rows = %v, expected 4", data["rows"])
+	}
+	if data["truncated"] != true {
+		t.Error("expected truncated = true")
+	}
+}
+
+func TestExportTableHandler_Progress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req spans.QuerySpansRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Pagination.Cursor == "" {
+			json.NewEncoder(w).Encode(spanPage(0, 3, "page-2"))
+			return
+		}
+		json.NewEncoder(w).Encode(spanPage(3, 3, "page-3"))
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	pkg.SetExportDir(t.TempDir())
+
+	// Each page is streamed as it is fetched, without the rows past max_rows
+	var batches []int
+	ctx := progress.WithReporter(context.Background(), func(_, _ float64, _ string, batch interface{}) {
+		rows, _ := batch.([]spans.FlatSpan)
+		batches = append(batches, len(rows))
+	})
+	result := pkg.ExportTableHandler(ctx, map[string]interface{}{
+		"signal":   "spans",
+		"path":     "dash0.db",
+		"max_rows": float64(4),
+	})
+	if !result.Success {
+		t.Fatalf("ExportTableHandler failed: %v", result.Error)
+	}
+	if fmt.Sprint(batches) != "[3 1]" {
+		t.Errorf("batches = %v, want [3 1]", batches)
+	}
+}
+
+func TestExportTableHandler_ExistingTable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"resourceLogs": []interface{}{}})
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	pkg.SetExportDir(t.TempDir())
+	args := map[string]interface{}{"signal": "logs", "path": "dash0.db"}
+
+	if result := pkg.ExportTableHandler(context.Background(), args); !result.Success {
+		t.Fatalf("first export failed: %v", result.Error)
+	}
+
+	result := pkg.ExportTableHandler(context.Background(), args)
+	if result.Success {
+		t.Fatal("expected error when table already exists")
+	}
+	if !strings.Contains(result.Error.Detail, "already exists") {
+		t.Errorf("Error = %q, expected 'already exists'", result.Error.Detail)
+	}
+
+	args["overwrite"] = true
+	if result := pkg.ExportTableHandler(context.Background(), args); !result.Success {
+		t.Fatalf("overwrite export failed: %v", result.Error)
+	}
+}
+
+func TestExportTableHandler_Parquet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(spanPage(0, 3, ""))
+	}))
+	defer server.Close()
+
+	name := "spans.parquet"
+	dir := t.TempDir()
+	path := filepath.Join(dir, name)
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	pkg.SetExportDir(dir)
+
+	result := pkg.ExportTableHandler(context.Background(), map[string]interface{}{
+		"signal": "spans",
+		"format": "parquet",
+		"path":   name,
+	})
+	if !result.Success {
+		t.Fatalf("ExportTableHandler failed: %v", result.Error)
+	}
+
+	rows, err := parquet.ReadFile[spanRow](path)
+	if err != nil {
+		t.Fatalf("failed to read parquet file: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("read %d rows, expected 3", len(rows))
+	}
+	if rows[0].ServiceName != "cart" || rows[0].DurationMs != 250 || rows[0].StatusCode != 2 {
+		t.Errorf("unexpected row: %+v", rows[0])
+	}
+}
+
+func TestExportTableHandler_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error": "unauthorized"})
+	}))
+	defer server.Close()
+
+	name := "dash0.db"
+	dir := t.TempDir()
+	path := filepath.Join(dir, name)
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	pkg.SetExportDir(dir)
+
+	result := pkg.ExportTableHandler(context.Background(), map[string]interface{}{
+		"signal": "spans",
+		"path":   name,
+	})
+	if result.Success {
+		t.Fatal("expected error")
+	}
+	if result.Error.StatusCode != http.StatusUnauthorized {
+		t.Errorf("StatusCode = %d, expected 401", result.Error.StatusCode)
+	}
+	if _, err := os.Stat(path); err == nil {
+		t.Error("no file should be written when the query fails")
+	}
+}
+
+func TestExportTableHandler_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			json.NewEncoder(w).Encode(spanPage(0, 2, "more"))
+			return
+		}
+		io.Copy(io.Discard, r.Body)
+		cancel()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	name := "dash0.db"
+	dir := t.TempDir()
+	path := filepath.Join(dir, name)
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	pkg.SetExportDir(dir)
+
+	result := pkg.ExportTableHandler(ctx, map[string]interface{}{
+		"signal":   "spans",
+		"path":     name,
+		"max_rows": float64(1000),
+	})
+	if result.Success {
+		t.Fatal("expected cancelled export to fail")
+	}
+	if result.Error.StatusCode != client.StatusClientClosedRequest {
+		t.Errorf("StatusCode = %d, expected %d", result.Error.StatusCode, client.StatusClientClosedRequest)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("expected paging to stop after cancellation, got %d requests", n)
+	}
+	if _, err := os.Stat(path); err == nil {
+		t.Error("no file should be written when the export is cancelled")
+	}
+}
+
+func TestExportTableHandler_Joined(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/spans":
+			json.NewEncoder(w).Encode(spanPage(0, 2, ""))
+		case "/api/logs":
+			logRecord := func(traceID, spanID, nanos, body string) map[string]interface{} {
+				return map[string]interface{}{
+					"timeUnixNano":   nanos,
+					"severityText":   "ERROR",
+					"severityNumber": float64(17),
+					"body":           map[string]interface{}{"stringValue": body},
+					"traceId":        traceID,
+					"spanId":         spanID,
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"resourceLogs": []interface{}{
+					map[string]interface{}{
+						"scopeLogs": []interface{}{
+							map[string]interface{}{
+								"logRecords": []interface{}{
+									logRecord("trace-0", "span-0", "1700000000100000000", "first"),
+									logRecord("trace-0", "span-0", "1700000000200000000", "second"),
+									logRecord("trace-9", "span-9", "1700000000100000000", "orphan"),
+								},
+							},
+						},
+					},
+				},
+			})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	name := "dash0.db"
+	dir := t.TempDir()
+	path := filepath.Join(dir, name)
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	pkg.SetExportDir(dir)
+
+	result := pkg.ExportTableHandler(context.Background(), map[string]interface{}{
+		"signal": "joined",
+		"path":   name,
+	})
+	if !result.Success {
+		t.Fatalf("ExportTableHandler failed: %v", result.Error)
+	}
+
+	data := result.Data.(map[string]interface{})
+	if data["rows"] != 3 {
+		t.Errorf("rows = %v, expected 3 (two logs for span-0, one bare span-1)", data["rows"])
+	}
+	if data["matched_logs"] != 2 {
+		t.Errorf("matched_logs = %v, expected 2", data["matched_logs"])
+	}
+	if data["table"] != "joined" {
+		t.Errorf("table = %v, expected joined", data["table"])
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("failed to open exported database: %v", err)
+	}
+	defer db.Close()
+
+	var body string
+	var offset float64
+	if err := db.QueryRow(`SELECT log_body, log_offset_ms FROM joined WHERE span_id = 'span-0' ORDER BY log_timestamp DESC LIMIT 1`).Scan(&body, &offset); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if body != "second" || offset != 200 {
+		t.Errorf("got body=%q offset=%v, expected second/200", body, offset)
+	}
+
+	var nullLogs int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM joined WHERE span_id = 'span-1' AND log_body IS NULL`).Scan(&nullLogs); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if nullLogs != 1 {
+		t.Errorf("expected span-1 to have one row with NULL log fields, got %d", nullLogs)
+	}
+}
+
+// configServer serves one dashboard and one check rule; the other
+// configuration collections are empty.
+func configServer(t *testing.T) *httptest.Server {
+	objects := map[string]interface{}{
+		"/api/dashboards": []interface{}{
+			map[string]interface{}{"metadata": map[string]interface{}{
+				"name":   "Checkout",
+				"labels": map[string]interface{}{"dash0.com/origin": "checkout"},
+			}},
+		},
+		"/api/dashboards/checkout": map[string]interface{}{
+			"kind": "Dashboard",
+			"metadata": map[string]interface{}{
+				"name":      "Checkout",
+				"createdAt": "2026-01-01T00:00:00Z",
+				"labels":    map[string]interface{}{"dash0.com/origin": "checkout", "dash0.com/version": "7"},
+			},
+			"spec": map[string]interface{}{"display": map[string]interface{}{"name": "Checkout"}},
+		},
+		"/api/alerting/check-rules": map[string]interface{}{
+			"items": []interface{}{map[string]interface{}{"id": "r-1", "name": "HighErrorRate"}},
+		},
+		"/api/alerting/check-rules/r-1": map[string]interface{}{
+			"id": "r-1", "name": "HighErrorRate", "expression": "up == 0", "updatedAt": "2026-01-02T00:00:00Z",
+		},
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if obj, ok := objects[r.URL.Path]; ok {
+			json.NewEncoder(w).Encode(obj)
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+}
+
+func TestExportAllHandler(t *testing.T) {
+	server := configServer(t)
+	defer server.Close()
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+
+	t.Run("json inline", func(t *testing.T) {
+		result := pkg.ExportAllHandler(context.Background(), map[string]interface{}{})
+		if !result.Success {
+			t.Fatalf("expected success, got %v", result.Error)
+		}
+		bundle := result.Data.(map[string]interface{})["bundle"].(map[string]interface{})
+		for _, name := range configCollectionNames() {
+			if _, ok := bundle[name]; !ok {
+				t.Errorf("bundle missing %s", name)
+			}
+		}
+		dashboards := bundle["dashboards"].([]interface{})
+		if len(dashboards) != 1 {
+			t.Fatalf("expected 1 dashboard, got %d", len(dashboards))
+		}
+		meta := dashboards[0].(map[string]interface{})["metadata"].(map[string]interface{})
+		if _, ok := meta["createdAt"]; ok {
+			t.Error("volatile createdAt was exported")
+		}
+		if labels := meta["labels"].(map[string]interface{}); labels["dash0.com/version"] != nil || labels["dash0.com/origin"] != "checkout" {
+			t.Errorf("labels = %v, want origin kept and version dropped", labels)
+		}
+		rule := bundle["check_rules"].([]interface{})[0].(map[string]interface{})
+		if rule["expression"] != "up == 0" || rule["updatedAt"] != nil {
+			t.Errorf("check rule = %v, want full definition without updatedAt", rule)
+		}
+		for _, s := range []string{"Configuration Export", "Exported 2 objects", "```json"} {
+			if !strings.Contains(result.Markdown, s) {
+				t.Errorf("markdown missing %q:\n%s", s, result.Markdown)
+			}
+		}
+	})
+
+	t.Run("yaml to file", func(t *testing.T) {
+		name := filepath.Join("gitops", "dash0.yaml")
+		dir := t.TempDir()
+		path := filepath.Join(dir, name)
+		pkg.SetExportDir(dir)
+		defer pkg.SetExportDir("")
+		result := pkg.ExportAllHandler(context.Background(), map[string]interface{}{
+			"resources": []interface{}{"check_rules"},
+			"format":    "yaml",
+			"path":      name,
+		})
+		if !result.Success {
+			t.Fatalf("expected success, got %v", result.Error)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("bundle not written: %v", err)
+		}
+		if !strings.Contains(string(content), "check_rules:") || !strings.Contains(string(content), "expression: up == 0") {
+			t.Errorf("unexpected YAML bundle:\n%s", content)
+		}
+		if strings.Contains(string(content), "dashboards") {
+			t.Errorf("bundle includes unrequested resources:\n%s", content)
+		}
+		if strings.Contains(result.Markdown, "```") {
+			t.Error("bundle written to a file should not be inlined")
+		}
+	})
+
+	t.Run("per-resource tool", func(t *testing.T) {
+		result := pkg.Handlers()["dash0_export_dashboards"](context.Background(), map[string]interface{}{})
+		bundle := result.Data.(map[string]interface{})["bundle"].(map[string]interface{})
+		if len(bundle) != 1 || bundle["dashboards"] == nil {
+			t.Errorf("bundle = %v, want only dashboards", bundle)
+		}
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		for name, args := range map[string]map[string]interface{}{
+			"bad format":   {"format": "xml"},
+			"bad resource": {"resources": []interface{}{"widgets"}},
+		} {
+			if result := pkg.ExportAllHandler(context.Background(), args); result.Success || result.Error.StatusCode != 400 {
+				t.Errorf("%s: expected 400, got %+v", name, result)
+			}
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package imports
+
+import "github.com/npcomplete777/dash0-mcp/internal/registry"
+
+// Compile-time interface check.
+var _ registry.ExampleProvider = (*Tools)(nil)
+
+// examplePrometheusRules is a Prometheus rules file with one alerting and
+// one recording rule.
+const examplePrometheusRules = `groups:
+  - name: api
+    interval: 30s
+    rules:
+      - alert: HighErrorRate
+        expr: sum(rate(http_requests_total{status=~"5.."}[5m])) / sum(rate(http_requests_total[5m])) > 0.05
+        for: 5m
+        labels:
+          severity: critical
+        annotations:
+          summary: API error rate above 5%
+      - record: job:http_requests:rate5m
+        expr: sum by (job) (rate(http_requests_total[5m]))
+`
+
+// Examples returns example invocations for the import tools.
+func (p *Tools) Examples() map[string][]registry.Example {
+	return map[string][]registry.Example{
+		"dash0_import_check_rule": {
+			{
+				Title: "Import an alert rule",
+				Arguments: map[string]interface{}{
+					"body": map[string]interface{}{
+						"name":        "TargetDown",
+						"expression":  "up == 0",
+						"interval":    "1m",
+						"for":         "5m",
+						"labels":      map[string]interface{}{"severity": "warning"},
+						"annotations": map[string]interface{}{"summary": "{{ $labels.job }} is down"},
+					},
+				},
+			},
+		},
+		"dash0_import_dashboard": {
+			{
+				Title: "Preview the conversion of a Grafana dashboard",
+				Arguments: map[string]interface{}{
+					"dry_run": true,
+					"body": map[string]interface{}{
+						"title": "API Overview",
+						"time":  map[string]interface{}{"from": "now-6h", "to": "now"},
+						"panels": []interface{}{
+							map[string]interface{}{
+								"type":       "timeseries",
+								"title":      "Request Rate",
+								"datasource": map[string]interface{}{"type": "prometheus"},
+								"targets": []interface{}{
+									map[string]interface{}{"expr": "sum by (job) (rate(http_requests_total[5m]))", "legendFormat": "{{job}}"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"dash0_import_prometheus_rules": {
+			{Title: "Preview a rules file import", Arguments: map[string]interface{}{"body": examplePrometheusRules, "dry_run": true}},
+			{Title: "Import a rules file", Arguments: map[string]interface{}{"body": examplePrometheusRules}},
+		},
+		"dash0_import_synthetic_check": {
+			{
+				Title: "Import an HTTP check",
+				Arguments: map[string]interface{}{
+					"body": map[string]interface{}{
+						"kind":     "Dash0SyntheticCheck",
+						"metadata": map[string]interface{}{"name": "api-health-check"},
+						"spec": map[string]interface{}{
+							"enabled": true,
+							"plugin": map[string]interface{}{
+								"kind": "http",
+								"spec": map[string]interface{}{
+									"request": map[string]interface{}{"method": "get", "url": "https://api.example.com/health", "redirects": "follow"},
+								},
+							},
+							"schedule": map[string]interface{}{"interval": "5m", "locations": []interface{}{"eu-west-1"}, "strategy": "all_locations"},
+						},
+					},
+				},
+			},
+		},
+		"dash0_import_view": {
+			{
+				Title: "Import a resources view",
+				Arguments: map[string]interface{}{
+					"body": map[string]interface{}{
+						"kind":     "Dash0View",
+						"metadata": map[string]interface{}{"name": "production-services"},
+						"spec":     map[string]interface{}{"type": "resources"},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,341 @@
+package imports
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// grafanaPanelKinds maps Grafana panel types to Perses panel plugin kinds.
+var grafanaPanelKinds = map[string]string{
+	"graph":      "TimeSeriesChart",
+	"timeseries": "TimeSeriesChart",
+	"stat":       "StatChart",
+	"singlestat": "StatChart",
+	"gauge":      "GaugeChart",
+	"bargauge":   "BarChart",
+	"barchart":   "BarChart",
+	"piechart":   "PieChart",
+	"table":      "Table",
+	"table-old":  "Table",
+	"text":       "Markdown",
+}
+
+// nonPromDatasources are Grafana datasource types whose queries cannot be
+// mapped to PromQL.
+var nonPromDatasources = map[string]bool{
+	"loki":          true,
+	"elasticsearch": true,
+	"influxdb":      true,
+	"graphite":      true,
+	"tempo":         true,
+	"jaeger":        true,
+	"mysql":         true,
+	"postgres":      true,
+	"cloudwatch":    true,
+}
+
+// ConversionIssue describes a Grafana element that could not be converted.
+type ConversionIssue struct {
+	Panel  string `json:"panel,omitempty"`
+	Type   string `json:"type,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// ConversionReport summarizes a Grafana to Perses conversion.
+type ConversionReport struct {
+	Title           string            `json:"title"`
+	Name            string            `json:"name"`
+	PanelsConverted int               `json:"panels_converted"`
+	QueriesMapped   int               `json:"queries_mapped"`
+	Variables       int               `json:"variables_converted"`
+	Unsupported     []ConversionIssue `json:"unsupported"`
+}
+
+// isGrafanaDashboard reports whether body looks like a Grafana dashboard
+// export rather than a Perses document.
+func isGrafanaDashboard(body map[string]interface{}) bool {
+	if _, ok := body["kind"]; ok {
+		return false
+	}
+	if inner, ok := body["dashboard"].(map[string]interface{}); ok {
+		body = inner
+	}
+	_, hasPanels := body["panels"].([]interface{})
+	_, hasRows := body["rows"].([]interface{})
+	return hasPanels || hasRows
+}
+
+// convertGrafanaDashboard converts a Grafana dashboard JSON export (either the
+// bare dashboard or the {"dashboard": ..., "meta": ...} API form) into a
+// PersesDashboard document.
+func convertGrafanaDashboard(body map[string]interface{}) (map[string]interface{}, ConversionReport) {
+	if inner, ok := body["dashboard"].(map[string]interface{}); ok {
+		body = inner
+	}
+
+	title, _ := body["title"].(string)
+	if title == "" {
+		title = "Imported Grafana dashboard"
+	}
+	uid, _ := body["uid"].(string)
+	name := slugify(uid)
+	if name == "" {
+		name = slugify(title)
+	}
+	report := ConversionReport{Title: title, Name: name, Unsupported: []ConversionIssue{}}
+
+	var panels []interface{}
+	for _, raw := range grafanaPanels(body) {
+		gp, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if panel := convertPanel(gp, &report); panel != nil {
+			panels = append(panels, panel)
+			report.PanelsConverted++
+		}
+	}
+	if panels == nil {
+		panels = []interface{}{}
+	}
+
+	display := map[string]interface{}{"name": title}
+	if desc, ok := body["description"].(string); ok && desc != "" {
+		display["description"] = desc
+	}
+	spec := map[string]interface{}{
+		"display": display,
+		"panels":  panels,
+	}
+	if d := grafanaDuration(body); d != "" {
+		spec["duration"] = d
+	}
+	if vars := convertVariables(body, &report); len(vars) > 0 {
+		spec["variables"] = vars
+	}
+
+	return map[string]interface{}{
+		"kind":     "PersesDashboard",
+		"metadata": map[string]interface{}{"name": name},
+		"spec":     spec,
+	}, report
+}
+
+// grafanaPanels flattens top-level panels, panels nested in collapsed rows,
+// and the rows[].panels layout of older dashboard schemas.
+func grafanaPanels(body map[string]interface{}) []interface{} {
+	var out []interface{}
+	top, _ := body["panels"].([]interface{})
+	for _, raw := range top {
+		p, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if p["type"] == "row" {
+			nested, _ := p["panels"].([]interface{})
+			out = append(out, nested...)
+			continue
+		}
+		out = append(out, p)
+	}
+	rows, _ := body["rows"].([]interface{})
+	for _, raw := range rows {
+		if r, ok := raw.(map[string]interface{}); ok {
+			nested, _ := r["panels"].([]interface{})
+			out = append(out, nested...)
+		}
+	}
+	return out
+}
+
+// convertPanel converts one Grafana panel, recording unsupported panel types
+// and queries in the report. It returns nil for panels that are skipped.
+func convertPanel(gp map[string]interface{}, report *ConversionReport) map[string]interface{} {
+	title, _ := gp["title"].(string)
+	panelType, _ := gp["type"].(string)
+	kind, ok := grafanaPanelKinds[panelType]
+	if !ok {
+		report.Unsupported = append(report.Unsupported, ConversionIssue{
+			Panel: title, Type: panelType, Reason: "panel type has no Perses equivalent",
+		})
+		return nil
+	}
+
+	display := map[string]interface{}{"name": title}
+	if desc, ok := gp["description"].(string); ok && desc != "" {
+		display["description"] = desc
+	}
+	pluginSpec := map[string]interface{}{}
+	if kind == "Markdown" {
+		content, _ := gp["content"].(string)
+		if opts, ok := gp["options"].(map[string]interface{}); ok {
+			if c, ok := opts["content"].(string); ok {
+				content = c
+			}
+		}
+		pluginSpec["text"] = content
+	}
+
+	spec := map[string]interface{}{
+		"display": display,
+		"plugin":  map[string]interface{}{"kind": kind, "spec": pluginSpec},
+	}
+	if kind != "Markdown" {
+		queries := convertTargets(gp, title, report)
+		if len(queries) > 0 {
+			spec["queries"] = queries
+		}
+	}
+	return map[string]interface{}{"kind": "Panel", "spec": spec}
+}
+
+// convertTargets maps a panel's PromQL targets to Perses time series queries.
+func convertTargets(gp map[string]interface{}, title string, report *ConversionReport) []interface{} {
+	panelDS := datasourceType(gp["datasource"])
+	targets, _ := gp["targets"].([]interface{})
+
+	var queries []interface{}
+	for _, raw := range targets {
+		t, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if hide, _ := t["hide"].(bool); hide {
+			continue
+		}
+		ds := datasourceType(t["datasource"])
+		if ds == "" {
+			ds = panelDS
+		}
+		expr, _ := t["expr"].(string)
+		if nonPromDatasources[ds] || strings.TrimSpace(expr) == "" {
+			reason := "query has no PromQL expression"
+			if ds != "" {
+				reason = fmt.Sprintf("%s query has no PromQL equivalent", ds)
+			}
+			report.Unsupported = append(report.Unsupported, ConversionIssue{Panel: title, Type: "query", Reason: reason})
+			continue
+		}
+
+		querySpec := map[string]interface{}{"query": expr}
+		if legend, ok := t["legendFormat"].(string); ok && legend != "" && legend != "__auto" {
+			querySpec["seriesNameFormat"] = legend
+		}
+		queries = append(queries, map[string]interface{}{
+			"kind": "TimeSeriesQuery",
+			"spec": map[string]interface{}{
+				"plugin": map[string]interface{}{
+					"kind": "PrometheusTimeSeriesQuery",
+					"spec": querySpec,
+				},
+			},
+		})
+		report.QueriesMapped++
+	}
+	return queries
+}
+
+// convertVariables maps Grafana template variables to Perses variables.
+func convertVariables(body map[string]interface{}, report *ConversionReport) []interface{} {
+	templating, _ := body["templating"].(map[string]interface{})
+	list, _ := templating["list"].([]interface{})
+
+	var vars []interface{}
+	for _, raw := range list {
+		v, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := v["name"].(string)
+		varType, _ := v["type"].(string)
+		display := map[string]interface{}{"name": name}
+		if label, ok := v["label"].(string); ok && label != "" {
+			display["name"] = label
+		}
+
+		var variable map[string]interface{}
+		switch varType {
+		case "query":
+			query := v["query"]
+			if q, ok := query.(map[string]interface{}); ok {
+				query = q["query"]
+			}
+			expr, _ := query.(string)
+			if expr == "" {
+				break
+			}
+			variable = listVariable(name, display, "PrometheusPromQLVariable", map[string]interface{}{"expr": expr})
+		case "custom":
+			q, _ := v["query"].(string)
+			var values []string
+			for _, val := range strings.Split(q, ",") {
+				if val = strings.TrimSpace(val); val != "" {
+					values = append(values, val)
+				}
+			}
+			variable = listVariable(name, display, "StaticListVariable", map[string]interface{}{"values": values})
+		case "constant", "textbox":
+			value, _ := v["query"].(string)
+			variable = map[string]interface{}{
+				"kind": "TextVariable",
+				"spec": map[string]interface{}{"name": name, "display": display, "value": value},
+			}
+		}
+
+		if variable == nil {
+			report.Unsupported = append(report.Unsupported, ConversionIssue{
+				Panel: name, Type: "variable:" + varType, Reason: "variable type has no Perses equivalent",
+			})
+			continue
+		}
+		vars = append(vars, variable)
+		report.Variables++
+	}
+	return vars
+}
+
+func listVariable(name string, display map[string]interface{}, pluginKind string, pluginSpec map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"kind": "ListVariable",
+		"spec": map[string]interface{}{
+			"name":    name,
+			"display": display,
+			"plugin":  map[string]interface{}{"kind": pluginKind, "spec": pluginSpec},
+		},
+	}
+}
+
+// datasourceType returns the type of a Grafana datasource reference, which is
+// either an object with a type or, in older exports, a bare name.
+func datasourceType(ds interface{}) string {
+	switch v := ds.(type) {
+	case map[string]interface{}:
+		t, _ := v["type"].(string)
+		return strings.ToLower(t)
+	case string:
+		return strings.ToLower(v)
+	}
+	return ""
+}
+
+// grafanaDuration converts a relative "now-6h" time range into a Perses
+// duration such as "6h".
+func grafanaDuration(body map[string]interface{}) string {
+	tr, _ := body["time"].(map[string]interface{})
+	from, _ := tr["from"].(string)
+	if to, _ := tr["to"].(string); to != "" && to != "now" {
+		return ""
+	}
+	if d := strings.TrimPrefix(from, "now-"); d != from && d != "" {
+		return d
+	}
+	return ""
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify turns a title or UID into a lowercase, hyphenated resource name.
+func slugify(s string) string {
+	return strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
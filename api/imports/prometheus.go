@@ -0,0 +1,183 @@
+package imports
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultRuleInterval is the evaluation interval for groups that don't set one.
+const defaultRuleInterval = "1m"
+
+// Rule import statuses.
+const (
+	ruleCreated = "created"
+	ruleFailed  = "failed"
+	ruleSkipped = "skipped"
+	// ruleReady marks a rule that a dry run would create.
+	ruleReady = "ready"
+)
+
+// RuleImportResult is the outcome of importing one Prometheus rule.
+type RuleImportResult struct {
+	Group  string `json:"group"`
+	Rule   string `json:"rule"`
+	Status string `json:"status"`
+	ID     string `json:"id,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// prometheusRule is one rule of a rules file, converted to a Dash0 check rule.
+// Rules that cannot be imported have a nil checkRule and a result describing why.
+type prometheusRule struct {
+	checkRule map[string]interface{}
+	result    RuleImportResult
+}
+
+// parsePrometheusRules splits a Prometheus rules file into individual rules.
+// body is either the rules file as a YAML (or JSON) string, or the decoded
+// document. Both the plain {groups: [...]} layout and PrometheusRule resources
+// with spec.groups are accepted.
+func parsePrometheusRules(body interface{}) ([]prometheusRule, error) {
+	if s, ok := body.(string); ok {
+		if strings.TrimSpace(s) == "" {
+			return nil, errors.New("body must not be empty")
+		}
+		var doc interface{}
+		if err := yaml.Unmarshal([]byte(s), &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse rules file: %v", err)
+		}
+		body = doc
+	}
+
+	var groups []interface{}
+	switch doc := body.(type) {
+	case []interface{}:
+		groups = doc
+	case map[string]interface{}:
+		if spec, ok := doc["spec"].(map[string]interface{}); ok {
+			doc = spec
+		}
+		list, ok := doc["groups"].([]interface{})
+		if !ok {
+			return nil, errors.New("rules file must have a groups list")
+		}
+		groups = list
+	default:
+		return nil, errors.New("body must be a rules file string or an object with groups")
+	}
+
+	var rules []prometheusRule
+	for i, raw := range groups {
+		group, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("groups[%d] must be an object", i)
+		}
+		groupName, _ := group["name"].(string)
+		if groupName == "" {
+			groupName = fmt.Sprintf("group-%d", i+1)
+		}
+		interval := ruleString(group["interval"])
+		if interval == "" {
+			interval = defaultRuleInterval
+		}
+		list, _ := group["rules"].([]interface{})
+		for j, rawRule := range list {
+			rules = append(rules, convertPrometheusRule(groupName, interval, j, rawRule))
+		}
+	}
+	if len(rules) == 0 {
+		return nil, errors.New("rules file contains no rules")
+	}
+	return rules, nil
+}
+
+// convertPrometheusRule maps a Prometheus alerting rule to a check rule body.
+func convertPrometheusRule(group, interval string, index int, raw interface{}) prometheusRule {
+	result := RuleImportResult{Group: group, Rule: fmt.Sprintf("rules[%d]", index)}
+	rule, ok := raw.(map[string]interface{})
+	if !ok {
+		result.Status, result.Detail = ruleFailed, "rule must be an object"
+		return prometheusRule{result: result}
+	}
+
+	if record := ruleString(rule["record"]); record != "" {
+		result.Rule = record
+		result.Status, result.Detail = ruleSkipped, "recording rules are not check rules"
+		return prometheusRule{result: result}
+	}
+	name := ruleString(rule["alert"])
+	if name == "" {
+		result.Status, result.Detail = ruleFailed, "alert name is missing"
+		return prometheusRule{result: result}
+	}
+	result.Rule = name
+	expr := ruleString(rule["expr"])
+	if expr == "" {
+		result.Status, result.Detail = ruleFailed, "expr is missing"
+		return prometheusRule{result: result}
+	}
+
+	forDuration := ruleString(rule["for"])
+	if forDuration == "" {
+		forDuration = "0s"
+	}
+	checkRule := map[string]interface{}{
+		"name":       name,
+		"expression": expr,
+		"interval":   interval,
+		"for":        forDuration,
+	}
+	if keep := ruleString(rule["keep_firing_for"]); keep != "" {
+		checkRule["keepFiringFor"] = keep
+	}
+	if labels := ruleStringMap(rule["labels"]); len(labels) > 0 {
+		checkRule["labels"] = labels
+	}
+	if annotations := ruleStringMap(rule["annotations"]); len(annotations) > 0 {
+		checkRule["annotations"] = annotations
+	}
+	return prometheusRule{checkRule: checkRule, result: result}
+}
+
+// ruleString returns a scalar rule field as a trimmed string.
+func ruleString(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return strings.TrimSpace(s)
+	}
+	return fmt.Sprint(v)
+}
+
+// ruleStringMap converts a labels or annotations map to string values, since
+// YAML decodes unquoted values such as `priority: 1` as numbers.
+func ruleStringMap(v interface{}) map[string]string {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, val := range m {
+		out[k] = ruleString(val)
+	}
+	return out
+}
+
+// ruleImportCounts tallies results by status.
+func ruleImportCounts(results []RuleImportResult) (created, failed, skipped int) {
+	for _, r := range results {
+		switch r.Status {
+		case ruleCreated:
+			created++
+		case ruleFailed:
+			failed++
+		case ruleSkipped:
+			skipped++
+		}
+	}
+	return created, failed, skipped
+}
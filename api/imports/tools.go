@@ -2,8 +2,11 @@ package imports
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
 	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/formatter"
 	"github.com/npcomplete777/dash0-mcp/internal/registry"
 	mcp "github.com/mark3labs/mcp-go/mcp"
 )
@@ -13,6 +16,7 @@ const (
 	importDashboardPath      = "/api/import/dashboard"
 	importSyntheticCheckPath = "/api/import/synthetic-check"
 	importViewPath           = "/api/import/view"
+	checkRulesPath           = "/api/alerting/check-rules"
 )
 
 // Compile-time interface check.
@@ -33,6 +37,7 @@ func (p *Tools) Tools() []mcp.Tool {
 	return []mcp.Tool{
 		p.ImportCheckRule(),
 		p.ImportDashboard(),
+		p.ImportPrometheusRules(),
 		p.ImportSyntheticCheck(),
 		p.ImportView(),
 	}
@@ -41,10 +46,11 @@ func (p *Tools) Tools() []mcp.Tool {
 // Handlers returns a map of tool name to handler function.
 func (p *Tools) Handlers() map[string]func(context.Context, map[string]interface{}) *client.ToolResult {
 	return map[string]func(context.Context, map[string]interface{}) *client.ToolResult{
-		"dash0_import_check_rule":     p.ImportCheckRuleHandler,
-		"dash0_import_dashboard":      p.ImportDashboardHandler,
-		"dash0_import_synthetic_check": p.ImportSyntheticCheckHandler,
-		"dash0_import_view":           p.ImportViewHandler,
+		"dash0_import_check_rule":       p.ImportCheckRuleHandler,
+		"dash0_import_dashboard":        p.ImportDashboardHandler,
+		"dash0_import_prometheus_rules": p.ImportPrometheusRulesHandler,
+		"dash0_import_synthetic_check":  p.ImportSyntheticCheckHandler,
+		"dash0_import_view":             p.ImportViewHandler,
 	}
 }
 
@@ -79,8 +85,19 @@ func (p *Tools) ImportCheckRuleHandler(ctx context.Context, args map[string]inte
 // ImportDashboard returns the dash0_import_dashboard tool definition.
 func (p *Tools) ImportDashboard() mcp.Tool {
 	return mcp.Tool{
-		Name:        "dash0_import_dashboard",
-		Description: "Import a dashboard from another observability platform into Dash0. Supports importing Grafana dashboards and other compatible formats.",
+		Name: "dash0_import_dashboard",
+		Description: `Import a dashboard from another observability platform into Dash0. Supports importing Grafana dashboards and other compatible formats.
+
+Grafana dashboard JSON is converted locally into PersesDashboard format before import:
+- Panels: graph/timeseries, stat, gauge, bar gauge, pie chart, table, and text panels
+  (panels nested in rows are included)
+- Queries: Prometheus targets become PrometheusTimeSeriesQuery; legendFormat is kept
+- Variables: query, custom, constant, and textbox variables
+- Unsupported panel types, non-Prometheus queries, and other variables are skipped and
+  listed in the conversion report
+
+Set dry_run to true to get the converted document and report without importing it.
+Bodies that are not Grafana dashboards are sent unchanged.`,
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
@@ -88,6 +105,10 @@ func (p *Tools) ImportDashboard() mcp.Tool {
 					"type":        "object",
 					"description": "The dashboard configuration to import. For Grafana dashboards, this should be the dashboard JSON export.",
 				},
+				"dry_run": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Return the converted PersesDashboard and conversion report without importing it. Default: false",
+				},
 			},
 			Required: []string{"body"},
 		},
@@ -100,8 +121,171 @@ func (p *Tools) ImportDashboardHandler(ctx context.Context, args map[string]inte
 	if !ok {
 		return client.ErrorResult(400, "body is required")
 	}
+	dryRun, _ := args["dry_run"].(bool)
+
+	grafana, ok := body.(map[string]interface{})
+	if !ok || !isGrafanaDashboard(grafana) {
+		if dryRun {
+			return client.ErrorResult(400, "dry_run is only supported for Grafana dashboards")
+		}
+		return p.client.Post(ctx, importDashboardPath, body)
+	}
+
+	dashboard, report := convertGrafanaDashboard(grafana)
+	if dryRun || p.client.DryRun(ctx) {
+		doc, err := json.MarshalIndent(dashboard, "", "  ")
+		if err != nil {
+			return client.ErrorResult(500, fmt.Sprintf("failed to marshal converted dashboard: %v", err))
+		}
+		return &client.ToolResult{
+			Success:  true,
+			Markdown: formatConversion(report, true) + "\n```json\n" + string(doc) + "\n```\n",
+			Data: map[string]interface{}{
+				"dashboard":  dashboard,
+				"conversion": report,
+			},
+		}
+	}
+
+	result := p.client.Post(ctx, importDashboardPath, dashboard)
+	if !result.Success {
+		return result
+	}
+	result.Data = map[string]interface{}{
+		"imported":   result.Data,
+		"conversion": report,
+	}
+	return result
+}
+
+// formatConversion renders the conversion report of a Grafana dashboard.
+func formatConversion(r ConversionReport, dryRun bool) string {
+	summary := fmt.Sprintf("**%s** → `%s`: %d panels, %d queries, %d variables converted",
+		r.Title, r.Name, r.PanelsConverted, r.QueriesMapped, r.Variables)
+	if dryRun {
+		summary += " (dry run, nothing imported)"
+	}
+	if len(r.Unsupported) == 0 {
+		return formatter.Table("Grafana Conversion", summary+"\n\nEverything was converted.", nil, nil, "")
+	}
+
+	rows := make([][]string, 0, len(r.Unsupported))
+	for _, u := range r.Unsupported {
+		rows = append(rows, []string{u.Panel, u.Type, u.Reason})
+	}
+	return formatter.Table("Grafana Conversion", summary, []string{"Panel", "Type", "Reason"}, rows,
+		fmt.Sprintf("%d elements could not be converted.", len(r.Unsupported)))
+}
+
+// ImportPrometheusRules returns the dash0_import_prometheus_rules tool definition.
+func (p *Tools) ImportPrometheusRules() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_import_prometheus_rules",
+		Description: `Bulk import a Prometheus rules file into Dash0 check rules.
+
+The file is split into individual alerting rules and each one is created as a check rule:
+- alert → name, expr → expression, for, keep_firing_for, labels, and annotations are kept
+- The group interval becomes the rule's evaluation interval (default: 1m)
+- Recording rules are skipped; rules without alert or expr fail
+
+Accepts the rules file as a YAML string, the groups document as JSON, or a
+PrometheusRule resource (spec.groups). Returns a per-rule report of created, failed,
+and skipped rules; one failing rule does not stop the others.
+
+Set dry_run to true to see the check rules that would be created without creating them.`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"body": map[string]interface{}{
+					"type":        []string{"string", "object"},
+					"description": "The Prometheus rules file as a YAML string, or a {\"groups\": [...]} object.",
+				},
+				"dry_run": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Return the converted check rules without creating them. Default: false",
+				},
+			},
+			Required: []string{"body"},
+		},
+	}
+}
+
+// ImportPrometheusRulesHandler handles the dash0_import_prometheus_rules tool.
+func (p *Tools) ImportPrometheusRulesHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	body, ok := args["body"]
+	if !ok {
+		return client.ErrorResult(400, "body is required")
+	}
+	dryRun, _ := args["dry_run"].(bool)
+	dryRun = dryRun || p.client.DryRun(ctx)
 
-	return p.client.Post(ctx, importDashboardPath, body)
+	rules, err := parsePrometheusRules(body)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+
+	results := make([]RuleImportResult, 0, len(rules))
+	var checkRules []interface{}
+	for _, rule := range rules {
+		res := rule.result
+		if rule.checkRule == nil {
+			results = append(results, res)
+			continue
+		}
+		if dryRun {
+			res.Status = ruleReady
+			checkRules = append(checkRules, rule.checkRule)
+			results = append(results, res)
+			continue
+		}
+
+		created := p.client.Post(ctx, checkRulesPath, rule.checkRule)
+		if created.Success {
+			res.Status = ruleCreated
+			if m, ok := created.Data.(map[string]interface{}); ok {
+				res.ID, _ = m["id"].(string)
+			}
+		} else {
+			res.Status = ruleFailed
+			res.Detail = created.Error.Detail
+			if res.Detail == "" {
+				res.Detail = created.Error.Title
+			}
+		}
+		results = append(results, res)
+	}
+
+	created, failed, skipped := ruleImportCounts(results)
+	data := map[string]interface{}{
+		"created": created,
+		"failed":  failed,
+		"skipped": skipped,
+		"results": results,
+	}
+	if dryRun {
+		data["check_rules"] = checkRules
+	}
+	return &client.ToolResult{
+		Success:  true,
+		Data:     data,
+		Markdown: formatRuleImport(results, dryRun),
+	}
+}
+
+// formatRuleImport renders the per-rule report of a Prometheus rules import.
+func formatRuleImport(results []RuleImportResult, dryRun bool) string {
+	created, failed, skipped := ruleImportCounts(results)
+	summary := fmt.Sprintf("%d created, %d failed, %d skipped", created, failed, skipped)
+	if dryRun {
+		summary = fmt.Sprintf("%d rules would be created, %d failed, %d skipped (dry run, nothing imported)",
+			len(results)-failed-skipped, failed, skipped)
+	}
+
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		rows = append(rows, []string{r.Group, r.Rule, r.Status, r.Detail})
+	}
+	return formatter.Table("Prometheus Rules Import", summary, []string{"Group", "Rule", "Status", "Detail"}, rows, "")
 }
 
 // ImportSyntheticCheck returns the dash0_import_synthetic_check tool definition.
@@ -166,5 +350,6 @@ func Register(reg *registry.Registry, c *client.Client) {
 	for _, tool := range p.Tools() {
 		handler := p.Handlers()[tool.Name]
 		reg.Register(tool, handler)
+		reg.AddExamples(tool.Name, p.Examples()[tool.Name]...)
 	}
 }
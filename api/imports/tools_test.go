@@ -26,15 +26,16 @@ func TestTools(t *testing.T) {
 	pkg := New(&client.Client{})
 	tools := pkg.Tools()
 
-	if len(tools) != 4 {
-		t.Errorf("Tools() returned %d tools, expected 4", len(tools))
+	if len(tools) != 5 {
+		t.Errorf("Tools() returned %d tools, expected 5", len(tools))
 	}
 
 	expectedNames := map[string]bool{
-		"dash0_import_check_rule":     false,
-		"dash0_import_dashboard":      false,
-		"dash0_import_synthetic_check": false,
-		"dash0_import_view":           false,
+		"dash0_import_check_rule":       false,
+		"dash0_import_dashboard":        false,
+		"dash0_import_prometheus_rules": false,
+		"dash0_import_synthetic_check":  false,
+		"dash0_import_view":             false,
 	}
 
 	for _, tool := range tools {
@@ -58,6 +59,7 @@ func TestHandlers(t *testing.T) {
 	expectedHandlers := []string{
 		"dash0_import_check_rule",
 		"dash0_import_dashboard",
+		"dash0_import_prometheus_rules",
 		"dash0_import_synthetic_check",
 		"dash0_import_view",
 	}
@@ -262,6 +264,342 @@ func TestImportDashboardHandler(t *testing.T) {
 	}
 }
 
+// grafanaFixture is a Grafana dashboard export covering rows, supported and
+// unsupported panels, mixed datasources, and template variables.
+const grafanaFixture = `{
+  "dashboard": {
+    "uid": "API_Overview",
+    "title": "API Overview",
+    "description": "Request metrics",
+    "time": {"from": "now-6h", "to": "now"},
+    "templating": {"list": [
+      {"name": "service", "type": "query", "query": {"query": "label_values(up, service)"}},
+      {"name": "env", "label": "Environment", "type": "custom", "query": "prod, staging"},
+      {"name": "ds", "type": "datasource", "query": "prometheus"}
+    ]},
+    "panels": [
+      {"type": "timeseries", "title": "Request rate", "datasource": {"type": "prometheus"},
+       "targets": [
+         {"expr": "sum(rate(http_requests_total[5m])) by (route)", "legendFormat": "{{route}}"},
+         {"expr": "up", "hide": true}
+       ]},
+      {"type": "row", "title": "Details", "collapsed": true, "panels": [
+        {"type": "stat", "title": "Error ratio", "targets": [{"expr": "sum(rate(errors_total[5m]))"}]},
+        {"type": "logs", "title": "Recent logs", "datasource": {"type": "loki"}, "targets": [{"expr": "{app=\"api\"}"}]}
+      ]},
+      {"type": "text", "title": "Notes", "options": {"content": "# Runbook"}},
+      {"type": "timeseries", "title": "Log volume", "datasource": {"type": "loki"},
+       "targets": [{"expr": "count_over_time({app=\"api\"}[5m])"}]},
+      {"type": "nodeGraph", "title": "Service map"}
+    ]
+  },
+  "meta": {"slug": "api-overview"}
+}`
+
+func grafanaBody(t *testing.T) map[string]interface{} {
+	t.Helper()
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(grafanaFixture), &body); err != nil {
+		t.Fatalf("invalid fixture: %v", err)
+	}
+	return body
+}
+
+func TestConvertGrafanaDashboard(t *testing.T) {
+	dashboard, report := convertGrafanaDashboard(grafanaBody(t))
+
+	if dashboard["kind"] != "PersesDashboard" {
+		t.Errorf("kind = %v, expected PersesDashboard", dashboard["kind"])
+	}
+	if name := dashboard["metadata"].(map[string]interface{})["name"]; name != "api-overview" {
+		t.Errorf("metadata.name = %v, expected api-overview", name)
+	}
+
+	spec := dashboard["spec"].(map[string]interface{})
+	if spec["duration"] != "6h" {
+		t.Errorf("duration = %v, expected 6h", spec["duration"])
+	}
+	if spec["display"].(map[string]interface{})["name"] != "API Overview" {
+		t.Errorf("display = %v", spec["display"])
+	}
+
+	panels := spec["panels"].([]interface{})
+	kinds := make([]string, 0, len(panels))
+	for _, p := range panels {
+		plugin := p.(map[string]interface{})["spec"].(map[string]interface{})["plugin"].(map[string]interface{})
+		kinds = append(kinds, plugin["kind"].(string))
+	}
+	wantKinds := "TimeSeriesChart,StatChart,Markdown,TimeSeriesChart"
+	if strings.Join(kinds, ",") != wantKinds {
+		t.Errorf("panel kinds = %v, expected %s", kinds, wantKinds)
+	}
+
+	first := panels[0].(map[string]interface{})["spec"].(map[string]interface{})
+	queries := first["queries"].([]interface{})
+	if len(queries) != 1 {
+		t.Fatalf("expected hidden target to be dropped, got %d queries", len(queries))
+	}
+	querySpec := queries[0].(map[string]interface{})["spec"].(map[string]interface{})["plugin"].(map[string]interface{})["spec"].(map[string]interface{})
+	if querySpec["query"] != "sum(rate(http_requests_total[5m])) by (route)" || querySpec["seriesNameFormat"] != "{{route}}" {
+		t.Errorf("query spec = %v", querySpec)
+	}
+
+	if report.PanelsConverted != 4 || report.QueriesMapped != 2 || report.Variables != 2 {
+		t.Errorf("report = %+v, expected 4 panels, 2 queries, 2 variables", report)
+	}
+	var reasons []string
+	for _, u := range report.Unsupported {
+		reasons = append(reasons, u.Panel+": "+u.Reason)
+	}
+	for _, want := range []string{
+		"Recent logs: panel type has no Perses equivalent",
+		"Log volume: loki query has no PromQL equivalent",
+		"Service map: panel type has no Perses equivalent",
+		"ds: variable type has no Perses equivalent",
+	} {
+		if !strings.Contains(strings.Join(reasons, "\n"), want) {
+			t.Errorf("unsupported list %v missing %q", reasons, want)
+		}
+	}
+
+	vars := spec["variables"].([]interface{})
+	custom := vars[1].(map[string]interface{})["spec"].(map[string]interface{})
+	values := custom["plugin"].(map[string]interface{})["spec"].(map[string]interface{})["values"].([]string)
+	if len(values) != 2 || values[0] != "prod" || values[1] != "staging" {
+		t.Errorf("custom variable values = %v", values)
+	}
+}
+
+func TestImportDashboardHandler_GrafanaConversion(t *testing.T) {
+	var received map[string]interface{}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		json.NewDecoder(r.Body).Decode(&received)
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": "imported-dashboard"})
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+
+	t.Run("dry run", func(t *testing.T) {
+		result := pkg.ImportDashboardHandler(context.Background(), map[string]interface{}{
+			"body":    grafanaBody(t),
+			"dry_run": true,
+		})
+		if !result.Success {
+			t.Fatalf("expected success, got %v", result.Error)
+		}
+		if requests != 0 {
+			t.Errorf("dry run must not call the API, got %d requests", requests)
+		}
+		data := result.Data.(map[string]interface{})
+		if data["dashboard"].(map[string]interface{})["kind"] != "PersesDashboard" {
+			t.Errorf("expected converted dashboard in result, got %v", data["dashboard"])
+		}
+		for _, s := range []string{"Grafana Conversion", "dry run", "Service map", `"kind": "PersesDashboard"`} {
+			if !strings.Contains(result.Markdown, s) {
+				t.Errorf("markdown missing %q:\n%s", s, result.Markdown)
+			}
+		}
+	})
+
+	t.Run("import", func(t *testing.T) {
+		result := pkg.ImportDashboardHandler(context.Background(), map[string]interface{}{"body": grafanaBody(t)})
+		if !result.Success {
+			t.Fatalf("expected success, got %v", result.Error)
+		}
+		if received["kind"] != "PersesDashboard" {
+			t.Errorf("expected converted document to be sent, got %v", received)
+		}
+		data := result.Data.(map[string]interface{})
+		if _, ok := data["conversion"].(ConversionReport); !ok {
+			t.Errorf("expected conversion report in result, got %v", data)
+		}
+	})
+
+	t.Run("dry run needs grafana", func(t *testing.T) {
+		result := pkg.ImportDashboardHandler(context.Background(), map[string]interface{}{
+			"body":    map[string]interface{}{"kind": "PersesDashboard"},
+			"dry_run": true,
+		})
+		if result.Success || !strings.Contains(result.Error.Detail, "only supported for Grafana") {
+			t.Errorf("expected dry_run error, got %+v", result)
+		}
+	})
+}
+
+const prometheusRulesFixture = `
+groups:
+  - name: checkout
+    interval: 30s
+    rules:
+      - alert: HighErrorRate
+        expr: rate(http_requests_total{status=~"5.."}[5m]) > 0.05
+        for: 5m
+        keep_firing_for: 10m
+        labels:
+          severity: critical
+          priority: 1
+        annotations:
+          summary: High error rate
+      - record: job:http_requests:rate5m
+        expr: sum by (job) (rate(http_requests_total[5m]))
+      - alert: Rejected
+        expr: up == 0
+  - name: broken
+    rules:
+      - expr: up == 0
+`
+
+func TestParsePrometheusRules(t *testing.T) {
+	rules, err := parsePrometheusRules(prometheusRulesFixture)
+	if err != nil {
+		t.Fatalf("parsePrometheusRules() error = %v", err)
+	}
+	if len(rules) != 4 {
+		t.Fatalf("got %d rules, want 4", len(rules))
+	}
+
+	first := rules[0].checkRule
+	want := map[string]interface{}{
+		"name":          "HighErrorRate",
+		"expression":    `rate(http_requests_total{status=~"5.."}[5m]) > 0.05`,
+		"interval":      "30s",
+		"for":           "5m",
+		"keepFiringFor": "10m",
+	}
+	for k, v := range want {
+		if first[k] != v {
+			t.Errorf("checkRule[%s] = %v, want %v", k, first[k], v)
+		}
+	}
+	if labels := first["labels"].(map[string]string); labels["priority"] != "1" || labels["severity"] != "critical" {
+		t.Errorf("labels = %v, want string values", labels)
+	}
+
+	if r := rules[1]; r.checkRule != nil || r.result.Status != ruleSkipped || r.result.Rule != "job:http_requests:rate5m" {
+		t.Errorf("recording rule = %+v, want skipped", r.result)
+	}
+	if r := rules[2].checkRule; r["for"] != "0s" || r["interval"] != "30s" {
+		t.Errorf("defaults = %v, want for 0s and group interval", r)
+	}
+	if r := rules[3]; r.checkRule != nil || r.result.Status != ruleFailed || r.result.Group != "broken" {
+		t.Errorf("rule without alert = %+v, want failed", r.result)
+	}
+
+	t.Run("accepted layouts", func(t *testing.T) {
+		group := map[string]interface{}{
+			"name":  "g",
+			"rules": []interface{}{map[string]interface{}{"alert": "A", "expr": "up == 0"}},
+		}
+		for name, body := range map[string]interface{}{
+			"json string":    `{"groups": [{"name": "g", "rules": [{"alert": "A", "expr": "up == 0"}]}]}`,
+			"groups object":  map[string]interface{}{"groups": []interface{}{group}},
+			"groups list":    []interface{}{group},
+			"PrometheusRule": map[string]interface{}{"kind": "PrometheusRule", "spec": map[string]interface{}{"groups": []interface{}{group}}},
+		} {
+			rules, err := parsePrometheusRules(body)
+			if err != nil || len(rules) != 1 || rules[0].checkRule["interval"] != "1m" {
+				t.Errorf("%s: rules = %+v, err = %v", name, rules, err)
+			}
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for name, body := range map[string]interface{}{
+			"empty":     "  ",
+			"bad yaml":  "groups: [",
+			"no groups": map[string]interface{}{"rules": []interface{}{}},
+			"no rules":  "groups:\n  - name: empty\n",
+			"number":    42.0,
+		} {
+			if _, err := parsePrometheusRules(body); err == nil {
+				t.Errorf("%s: expected an error", name)
+			}
+		}
+	})
+}
+
+func TestImportPrometheusRulesHandler(t *testing.T) {
+	var received []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/alerting/check-rules" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var rule map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&rule)
+		received = append(received, rule)
+		if rule["name"] == "Rejected" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error": map[string]interface{}{"message": "invalid expression"}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": "rule-" + rule["name"].(string)})
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+
+	t.Run("import", func(t *testing.T) {
+		received = nil
+		result := pkg.ImportPrometheusRulesHandler(context.Background(), map[string]interface{}{"body": prometheusRulesFixture})
+		if !result.Success {
+			t.Fatalf("expected success, got %v", result.Error)
+		}
+		if len(received) != 2 {
+			t.Fatalf("expected 2 check rules created, got %d", len(received))
+		}
+		data := result.Data.(map[string]interface{})
+		if data["created"] != 1 || data["failed"] != 2 || data["skipped"] != 1 {
+			t.Errorf("counts = %v/%v/%v, want 1/2/1", data["created"], data["failed"], data["skipped"])
+		}
+		results := data["results"].([]RuleImportResult)
+		if results[0].Status != ruleCreated || results[0].ID != "rule-HighErrorRate" {
+			t.Errorf("results[0] = %+v, want created with id", results[0])
+		}
+		if results[2].Status != ruleFailed || results[2].Detail == "" {
+			t.Errorf("results[2] = %+v, want failed with detail", results[2])
+		}
+		for _, s := range []string{"Prometheus Rules Import", "1 created, 2 failed, 1 skipped", "HighErrorRate", "recording rules"} {
+			if !strings.Contains(result.Markdown, s) {
+				t.Errorf("markdown missing %q:\n%s", s, result.Markdown)
+			}
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		received = nil
+		result := pkg.ImportPrometheusRulesHandler(context.Background(), map[string]interface{}{
+			"body":    prometheusRulesFixture,
+			"dry_run": true,
+		})
+		if !result.Success {
+			t.Fatalf("expected success, got %v", result.Error)
+		}
+		if len(received) != 0 {
+			t.Errorf("dry run must not call the API, got %d requests", len(received))
+		}
+		data := result.Data.(map[string]interface{})
+		if rules := data["check_rules"].([]interface{}); len(rules) != 2 {
+			t.Errorf("expected 2 check rules in dry run, got %d", len(rules))
+		}
+		if !strings.Contains(result.Markdown, "2 rules would be created") {
+			t.Errorf("markdown missing dry run summary:\n%s", result.Markdown)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if result := pkg.ImportPrometheusRulesHandler(context.Background(), map[string]interface{}{}); result.Success {
+			t.Error("expected error for missing body")
+		}
+		result := pkg.ImportPrometheusRulesHandler(context.Background(), map[string]interface{}{"body": "groups: ["})
+		if result.Success || result.Error.StatusCode != 400 {
+			t.Errorf("expected 400 for invalid YAML, got %+v", result)
+		}
+	})
+}
+
 func TestImportSyntheticCheckToolDefinition(t *testing.T) {
 	pkg := New(&client.Client{})
 	tool := pkg.ImportSyntheticCheck()
@@ -542,9 +880,9 @@ func TestImportToolsOnlySupportPost(t *testing.T) {
 	pkg := New(&client.Client{})
 	tools := pkg.Tools()
 
-	// Should have exactly 4 import tools
-	if len(tools) != 4 {
-		t.Errorf("Expected 4 import tools, got %d", len(tools))
+	// Should have exactly 5 import tools
+	if len(tools) != 5 {
+		t.Errorf("Expected 5 import tools, got %d", len(tools))
 	}
 
 	// All tools should have the same structure (body required)
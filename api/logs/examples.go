@@ -0,0 +1,70 @@
+package logs
+
+import "github.com/npcomplete777/dash0-mcp/internal/registry"
+
+// Compile-time interface check.
+var _ registry.ExampleProvider = (*Tools)(nil)
+
+// Examples returns example invocations for the log tools.
+func (p *Tools) Examples() map[string][]registry.Example {
+	return map[string][]registry.Example{
+		"dash0_logs_query": {
+			{Title: "Logs for a service", Arguments: map[string]interface{}{"service_name": "cart"}},
+			{Title: "Errors for a service in the last 15 minutes", Arguments: map[string]interface{}{"service_name": "frontend", "min_severity": "ERROR", "time_range_minutes": 15}},
+			{Title: "Logs mentioning a timeout", Arguments: map[string]interface{}{"body_contains": "timeout", "limit": 50}},
+			{Title: "Logs of a Kubernetes namespace", Arguments: map[string]interface{}{"k8s_namespace": "shop", "min_severity": "WARN"}},
+			{Title: "Logs of a trace", Arguments: map[string]interface{}{"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736"}},
+			{Title: "Errors without health checks or load generator noise", Arguments: map[string]interface{}{"exclude_services": []interface{}{"load-generator"}, "exclude_severities": []interface{}{"DEBUG", "TRACE"}, "body_not_contains": "/healthz"}},
+			{Title: "The most severe logs of a service", Arguments: map[string]interface{}{"service_name": "cart", "order_by": "severity", "limit": 20}},
+			{
+				Title: "Logs of a deployment's pods in production",
+				Arguments: map[string]interface{}{
+					"k8s_namespace": "shop",
+					"attribute_filters": []interface{}{
+						map[string]interface{}{"key": "k8s.pod.name", "operator": "starts_with", "value": "cart-"},
+						map[string]interface{}{"key": "deployment.environment", "value": "production"},
+					},
+				},
+			},
+		},
+		"dash0_logs_severity_trend": {
+			{Title: "Severity trend of a service", Arguments: map[string]interface{}{"service_name": "checkout"}},
+			{Title: "Severity rates before and after a deploy 30 minutes ago", Arguments: map[string]interface{}{"service_name": "checkout", "split_at": "30m"}},
+			{Title: "Namespace trend in 5-minute buckets", Arguments: map[string]interface{}{"k8s_namespace": "shop", "bucket_minutes": 5}},
+			{Title: "Severity totals against the same time last week", Arguments: map[string]interface{}{"service_name": "checkout", "baseline": "same_time_last_week"}},
+		},
+		"dash0_logs_send": {
+			{
+				Title: "Send an error log for a service",
+				Arguments: map[string]interface{}{
+					"body": map[string]interface{}{
+						"resourceLogs": []interface{}{
+							map[string]interface{}{
+								"resource": map[string]interface{}{
+									"attributes": []interface{}{
+										map[string]interface{}{"key": "service.name", "value": map[string]interface{}{"stringValue": "checkout"}},
+									},
+								},
+								"scopeLogs": []interface{}{
+									map[string]interface{}{
+										"scope": map[string]interface{}{"name": "dash0-mcp"},
+										"logRecords": []interface{}{
+											map[string]interface{}{
+												"severityNumber": 17,
+												"severityText":   "ERROR",
+												"body":           map[string]interface{}{"stringValue": "payment declined: card expired"},
+												"attributes": []interface{}{
+													map[string]interface{}{"key": "order.id", "value": map[string]interface{}{"stringValue": "A-1042"}},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
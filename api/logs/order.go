@@ -0,0 +1,53 @@
+package logs
+
+import (
+	"sort"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/internal/otlp"
+)
+
+// Fields dash0_logs_query can order logs by.
+const (
+	orderTimestamp = "timestamp"
+	orderSeverity  = "severity"
+)
+
+var orderFields = []string{orderTimestamp, orderSeverity}
+
+// severityRank orders a log by severity: its severity number, or the
+// number of its severity text when the number is unset.
+func severityRank(l FlatLog) int {
+	if l.SeverityNumber > 0 {
+		return l.SeverityNumber
+	}
+	return severityOrder[severityLevel(l)]
+}
+
+// sortLogs sorts ll by o, keeping the API's order among equal logs.
+func sortLogs(ll []FlatLog, o otlp.Order) {
+	keys := make([]int64, len(ll))
+	for i, l := range ll {
+		if o.By == orderSeverity {
+			keys[i] = int64(severityRank(l))
+		} else {
+			t, _ := time.Parse(time.RFC3339Nano, l.Timestamp)
+			keys[i] = t.UnixNano()
+		}
+	}
+	idx := make([]int, len(ll))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		if o.Desc {
+			return keys[idx[i]] > keys[idx[j]]
+		}
+		return keys[idx[i]] < keys[idx[j]]
+	})
+	sorted := make([]FlatLog, len(ll))
+	for i, k := range idx {
+		sorted[i] = ll[k]
+	}
+	copy(ll, sorted)
+}
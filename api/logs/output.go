@@ -0,0 +1,43 @@
+package logs
+
+import (
+	"github.com/npcomplete777/dash0-mcp/internal/followup"
+	"github.com/npcomplete777/dash0-mcp/internal/registry"
+	"github.com/npcomplete777/dash0-mcp/internal/schema"
+	"github.com/npcomplete777/dash0-mcp/internal/truncate"
+)
+
+// Compile-time interface check.
+var _ registry.OutputSchemaProvider = (*Tools)(nil)
+
+// OutputSchemas returns the JSON Schemas of the structured results of the
+// log tools.
+func (p *Tools) OutputSchemas() map[string]map[string]interface{} {
+	return map[string]map[string]interface{}{
+		"dash0_logs_query":          queryLogsOutputSchema(),
+		"dash0_logs_severity_trend": schema.Reflect(SeverityTrendResult{}),
+	}
+}
+
+// queryLogsOutputSchema describes the result of dash0_logs_query. With
+// order_by, matched and sampled replace next_cursor and trimmed.
+func queryLogsOutputSchema() map[string]interface{} {
+	logs := schema.Reflect([]FlatLog{})
+	logs["description"] = "The matching log records, flattened"
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"logs":        logs,
+			"count":       map[string]interface{}{"type": "integer", "description": "Number of log records returned"},
+			"next_cursor": map[string]interface{}{"type": "string", "description": "Pass as cursor to fetch the next page; empty on the last page"},
+			"has_more":    map[string]interface{}{"type": "boolean"},
+			"trimmed":     map[string]interface{}{"type": "integer", "description": "Records of the page cut to fit limit, which next_cursor does not revisit"},
+			"matched":     map[string]interface{}{"type": "integer", "description": "With order_by, the number of matching records read and sorted"},
+			"sampled":     map[string]interface{}{"type": "boolean", "description": "With order_by, true when more records matched than max_logs"},
+			"query":       map[string]interface{}{"type": "object", "description": "The time range, filters, and limit of the query"},
+			followup.Key:  schema.Reflect([]followup.Suggestion{}),
+			"truncated":   map[string]interface{}{"type": "boolean"},
+			"truncation":  schema.Reflect(truncate.Report{}),
+		},
+	}
+}
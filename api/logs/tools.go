@@ -9,16 +9,40 @@ import (
 	"time"
 
 	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/followup"
 	"github.com/npcomplete777/dash0-mcp/internal/formatter"
 	"github.com/npcomplete777/dash0-mcp/internal/otlp"
+	"github.com/npcomplete777/dash0-mcp/internal/progress"
 	"github.com/npcomplete777/dash0-mcp/internal/registry"
+	"github.com/npcomplete777/dash0-mcp/internal/truncate"
 	mcp "github.com/mark3labs/mcp-go/mcp"
 )
 
 const (
 	basePath = "/api/logs"
+
+	defaultVerifyTimeoutSeconds = 30
+	maxVerifyTimeoutSeconds     = 120
+
+	// verifyMaxPages bounds the query pages read per verification attempt.
+	verifyMaxPages = 5
+
+	// queryPageSize is the largest page a logs query returns.
+	queryPageSize = 500
+
+	defaultTrendBuckets = 12
+	maxTrendBuckets     = 288
+	defaultTrendMaxLogs = 5000
+	maxTrendMaxLogs     = 20000
+
+	// defaultQueryMaxLogs is how many logs dash0_logs_query reads to sort
+	// them by order_by.
+	defaultQueryMaxLogs = 2000
 )
 
+// verifyPollInterval is the delay between ingestion verification queries.
+var verifyPollInterval = 2 * time.Second
+
 // Compile-time interface check.
 var _ registry.ToolProvider = (*Tools)(nil)
 
@@ -37,28 +61,52 @@ func (p *Tools) Tools() []mcp.Tool {
 	return []mcp.Tool{
 		p.PostLogs(),
 		p.QueryLogs(),
+		p.SeverityTrend(),
 	}
 }
 
 // Handlers returns a map of tool name to handler function.
 func (p *Tools) Handlers() map[string]func(context.Context, map[string]interface{}) *client.ToolResult {
 	return map[string]func(context.Context, map[string]interface{}) *client.ToolResult{
-		"dash0_logs_send":  p.PostLogsHandler,
-		"dash0_logs_query": p.QueryLogsHandler,
+		"dash0_logs_send":           p.PostLogsHandler,
+		"dash0_logs_query":          p.QueryLogsHandler,
+		"dash0_logs_severity_trend": p.SeverityTrendHandler,
 	}
 }
 
 // PostLogs returns the dash0_logs_send tool definition.
 func (p *Tools) PostLogs() mcp.Tool {
 	return mcp.Tool{
-		Name:        "dash0_logs_send",
-		Description: "Send OTLP log records to Dash0. Accepts log data in OTLP JSON format for ingestion into the Dash0 observability platform.",
+		Name: "dash0_logs_send",
+		Description: `Send OTLP log records to Dash0. Accepts log data in OTLP JSON format for ingestion into the Dash0 observability platform,
+or as base64-encoded OTLP protobuf with "content_encoding": "protobuf".
+
+Posted to the ingestion endpoint when DASH0_INGRESS_URL is set, otherwise through the API.
+With content_encoding json_gzip or protobuf_gzip, the payload is gzip-compressed before
+it is sent, for large batches relayed from instrumentation pipelines.
+
+With "verify": true, polls the logs query API after sending until every sent
+record (matched by timestamp and body) is queryable or the timeout elapses, and
+reports which records never appeared.`,
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
 				"body": map[string]interface{}{
-					"type":        "object",
-					"description": "OTLP log records in JSON format. Should follow the OpenTelemetry Protocol specification for logs.",
+					"type":        []string{"object", "string"},
+					"description": "OTLP log records in JSON format. Should follow the OpenTelemetry Protocol specification for logs. With content_encoding protobuf or protobuf_gzip, a base64 string of a binary ExportLogsServiceRequest.",
+				},
+				"content_encoding": map[string]interface{}{
+					"type":        "string",
+					"description": "Encoding of body: json (default), json_gzip, protobuf (a base64 string), or protobuf_gzip. verify requires json or json_gzip",
+					"enum":        client.IngestEncodings,
+				},
+				"verify": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Poll the query API until the sent log records are queryable (default: false)",
+				},
+				"verify_timeout_seconds": map[string]interface{}{
+					"type":        "integer",
+					"description": "How long to wait for the log records to become queryable (default: 30, max: 120)",
 				},
 			},
 			Required: []string{"body"},
@@ -73,31 +121,251 @@ func (p *Tools) PostLogsHandler(ctx context.Context, args map[string]interface{}
 		return client.ErrorResult(400, "body is required")
 	}
 
-	return p.client.Post(ctx, basePath, body)
+	verify, _ := args["verify"].(bool)
+	timeoutSeconds := defaultVerifyTimeoutSeconds
+	if t, ok := args["verify_timeout_seconds"].(float64); ok {
+		if t < 0 {
+			return client.ErrorResult(400, "verify_timeout_seconds must not be negative")
+		}
+		if t > 0 {
+			timeoutSeconds = int(t)
+			if timeoutSeconds > maxVerifyTimeoutSeconds {
+				timeoutSeconds = maxVerifyTimeoutSeconds
+			}
+		}
+	}
+
+	encoding, _ := args["content_encoding"].(string)
+	if verify && (encoding == client.EncodingProtobuf || encoding == client.EncodingProtobufGzip) {
+		return client.ErrorResult(400, "verify needs an OTLP JSON body; use content_encoding json or json_gzip")
+	}
+	payload, err := client.EncodePayload(body, encoding)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+
+	result := p.client.Ingest(ctx, client.SignalLogs, payload)
+	if !result.Success || result.DryRun || !verify {
+		return result
+	}
+
+	sent := collectSentLogs(body)
+	if len(sent.keys) == 0 {
+		return client.ErrorResult(400, "verify requires log records with timeUnixNano or observedTimeUnixNano in the body")
+	}
+
+	verification := otlp.VerifyIngestion(ctx, sent.keys, time.Duration(timeoutSeconds)*time.Second, verifyPollInterval,
+		func(ctx context.Context) (map[string]bool, error) {
+			return p.lookupLogs(ctx, sent)
+		},
+	)
+
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: otlp.FormatVerification("log records", verification),
+		Data: map[string]interface{}{
+			"response":     result.Data,
+			"verification": verification,
+		},
+	}
+}
+
+// sentLogs describes the log records in a send payload for ingestion verification.
+type sentLogs struct {
+	keys     []string // "timestamp|body", matching flattened query results
+	services map[string]bool
+	minTime  int64
+	maxTime  int64
+}
+
+// logKey identifies a log record by its RFC3339Nano timestamp and body.
+func logKey(timestamp, body string) string {
+	return timestamp + "|" + body
+}
+
+// collectSentLogs extracts log identities and time bounds from an OTLP payload.
+func collectSentLogs(body interface{}) sentLogs {
+	sent := sentLogs{services: make(map[string]bool)}
+
+	bodyMap, ok := body.(map[string]interface{})
+	if !ok {
+		return sent
+	}
+	resourceLogs, _ := bodyMap["resourceLogs"].([]interface{})
+	for _, rl := range resourceLogs {
+		rlMap, ok := rl.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		sent.services[otlp.ExtractServiceName(rlMap)] = true
+
+		scopeLogs, _ := rlMap["scopeLogs"].([]interface{})
+		for _, sl := range scopeLogs {
+			slMap, ok := sl.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			logRecords, _ := slMap["logRecords"].([]interface{})
+			for _, lr := range logRecords {
+				logMap, ok := lr.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				nanos, ok := otlp.UnixNanoValue(logMap["timeUnixNano"])
+				if !ok {
+					nanos, ok = otlp.UnixNanoValue(logMap["observedTimeUnixNano"])
+				}
+				if !ok {
+					continue
+				}
+
+				bodyText := ""
+				if b, ok := logMap["body"].(map[string]interface{}); ok {
+					bodyText, _ = b["stringValue"].(string)
+				}
+				sent.keys = append(sent.keys, logKey(time.Unix(0, nanos).UTC().Format(time.RFC3339Nano), bodyText))
+
+				if sent.minTime == 0 || nanos < sent.minTime {
+					sent.minTime = nanos
+				}
+				if nanos > sent.maxTime {
+					sent.maxTime = nanos
+				}
+			}
+		}
+	}
+	return sent
+}
+
+// lookupLogs queries the window around the sent records and returns the keys found.
+func (p *Tools) lookupLogs(ctx context.Context, sent sentLogs) (map[string]bool, error) {
+	from := time.Unix(0, sent.minTime).UTC().Add(-time.Minute)
+	to := time.Unix(0, sent.maxTime).UTC().Add(time.Minute)
+
+	var filters []AttributeFilter
+	if len(sent.services) == 1 {
+		for service := range sent.services {
+			if service != "" {
+				service := service
+				filters = append(filters, AttributeFilter{
+					Key:      "service.name",
+					Operator: "is",
+					Value:    &AttributeFilterValue{StringValue: &service},
+				})
+			}
+		}
+	}
+
+	dataset := p.client.Dataset(ctx)
+	found := make(map[string]bool)
+	cursor := ""
+	for page := 0; page < verifyMaxPages; page++ {
+		req := QueryLogsRequest{
+			Dataset: dataset,
+			TimeRange: TimeRange{
+				From: from.Format(time.RFC3339),
+				To:   to.Format(time.RFC3339),
+			},
+			Filter:     filters,
+			Pagination: Pagination{Limit: queryPageSize, Cursor: cursor},
+		}
+		result := p.client.PostWithDataset(ctx, basePath, req, dataset)
+		if !result.Success {
+			if result.Error != nil {
+				return nil, fmt.Errorf("%d %s", result.Error.StatusCode, result.Error.Detail)
+			}
+			return nil, fmt.Errorf("log query failed")
+		}
+		for _, log := range flattenLogsResponse(result.Data) {
+			found[logKey(log.Timestamp, log.Body)] = true
+		}
+		if allFound(found, sent.keys) {
+			break
+		}
+		cursor = otlp.NextCursor(result.Data)
+		if cursor == "" {
+			break
+		}
+	}
+	return found, nil
+}
+
+// allFound reports whether every key is present in found.
+func allFound(found map[string]bool, keys []string) bool {
+	for _, key := range keys {
+		if !found[key] {
+			return false
+		}
+	}
+	return true
 }
 
 // QueryLogs returns the dash0_logs_query tool definition.
 func (p *Tools) QueryLogs() mcp.Tool {
 	return mcp.Tool{
 		Name: "dash0_logs_query",
-		Description: `Query logs from Dash0 with filtering by service and time range.
+		Description: `Query logs from Dash0 with filtering by service, Kubernetes resource, and time range.
 
 Returns logs as a formatted markdown table with severity, body, and trace context.
 
-NOTE: Only service_name filtering works reliably via the API. Severity filtering
-is applied client-side after fetching results.
+service_name, k8s_namespace, k8s_deployment_name, k8s_pod_name, k8s_container_name,
+exclude_services, trace_id, span_id, and attribute_filters are sent to the API. Severity and body filtering, including
+exclude_severities and body_not_contains, are applied client-side after fetching results.
+
+trace_id returns the logs correlated with one trace, e.g. a trace_id from
+dash0_spans_query or another log line; the result suggests dash0_spans_query for the
+spans of the same trace.
 
 Example queries:
 - Get logs for a service: {"service_name": "cart"}
 - Get recent logs: {"time_range_minutes": 15}
-- Get error logs for a service: {"service_name": "frontend", "min_severity": "ERROR"}`,
+- Get error logs for a service: {"service_name": "frontend", "min_severity": "ERROR"}
+- Get logs of a namespace: {"k8s_namespace": "checkout"}
+- Get logs of a deployment: {"k8s_namespace": "shop", "k8s_deployment_name": "cart"}
+- Get logs of pods by name prefix: {"k8s_namespace": "shop", "attribute_filters": [{"key": "k8s.pod.name", "operator": "starts_with", "value": "cart-"}]}
+- Get the logs of a trace: {"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736"}
+- Drop noise: {"k8s_namespace": "shop", "exclude_services": ["load-generator"], "exclude_severities": ["DEBUG", "TRACE"], "body_not_contains": "/healthz"}
+
+Large result sets are paged: when more logs are available the result includes
+next_cursor. Repeat the query with the same filters and {"cursor": "<next_cursor>"}
+to fetch the next page; the cursor keeps the time window of the first page.
+
+Logs come back in the API's order. order_by (timestamp or severity) with direction (desc by
+default) reads up to max_logs matching logs and returns the first limit of them in that order,
+without paging.
+- The 20 most severe logs of a service: {"service_name": "cart", "order_by": "severity", "limit": 20}`,
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
-			Properties: map[string]interface{}{
+			Properties: truncate.WithSchemaProperties(map[string]interface{}{
 				"service_name": map[string]interface{}{
 					"type":        "string",
 					"description": "Filter by service name (exact match)",
 				},
+				"k8s_namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter by Kubernetes namespace (k8s.namespace.name, exact match)",
+				},
+				"k8s_deployment_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter by Kubernetes deployment (k8s.deployment.name, exact match)",
+				},
+				"k8s_pod_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter by Kubernetes pod (k8s.pod.name, exact match; use attribute_filters with starts_with for a pod name prefix)",
+				},
+				"k8s_container_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter by Kubernetes container (k8s.container.name, exact match)",
+				},
+				"trace_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Only logs of this trace (exact match), to correlate logs with a trace",
+				},
+				"span_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Only logs emitted inside this span (exact match)",
+				},
+				"attribute_filters": otlp.AttributeFiltersSchema("Arbitrary resource or log attribute filters, combined with AND, e.g. deployment.environment or k8s.deployment.name."),
 				"time_range_minutes": map[string]interface{}{
 					"type":        "integer",
 					"description": "Minutes back to search (default: 60, max: 1440)",
@@ -111,10 +379,114 @@ Example queries:
 					"type":        "string",
 					"description": "Filter logs where body contains this text (case-insensitive, applied client-side)",
 				},
+				"body_not_contains": map[string]interface{}{
+					"type":        "string",
+					"description": "Drop logs whose body contains this text, e.g. health checks (case-insensitive, applied client-side)",
+				},
+				"exclude_services": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Drop the logs of these services (exact match, sent to the API)",
+				},
+				"exclude_severities": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string", "enum": severityLevels},
+					"description": "Drop logs of these severity levels, e.g. [\"DEBUG\", \"TRACE\"] (applied client-side)",
+				},
 				"limit": map[string]interface{}{
 					"type":        "integer",
 					"description": "Max logs to return (default: 100, max: 500)",
 				},
+				"cursor": map[string]interface{}{
+					"type":        "string",
+					"description": "Pagination cursor from a previous result's next_cursor, to fetch the next page of the same time window",
+				},
+				"order_by":  otlp.OrderBySchema(orderFields),
+				"direction": otlp.DirectionSchema(),
+				"max_logs": map[string]interface{}{
+					"type":        "integer",
+					"description": "order_by only: maximum logs to read (default: 2000, max: 20000)",
+				},
+				"dataset": map[string]interface{}{
+					"type":        "string",
+					"description": "Dash0 dataset to query (e.g., 'astronomy-demo'). If omitted, uses the globally configured dataset or 'default'.",
+				},
+			}),
+		},
+	}
+}
+
+// SeverityTrend returns the dash0_logs_severity_trend tool definition.
+func (p *Tools) SeverityTrend() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_logs_severity_trend",
+		Description: `Count logs per severity per time bucket to see how log levels trend over a window.
+
+Returns a compact table with one row per bucket and one column per severity (FATAL, ERROR,
+WARN, INFO, DEBUG, TRACE, UNSET), plus a sparkline per severity. Severities are derived
+from severityNumber, falling back to severityText.
+
+Pass split_at (for example a deploy time) to compare the per-minute rate of each severity
+before and after it, answering "did WARN/ERROR volume change after the deploy?".
+
+Pass baseline to also count the previous period or the same time last week and report
+the change of each severity's total.
+
+At most max_logs logs are read; when more match, the counts cover only the logs read and
+the result says so. Narrow the window or the filters for exact counts.
+
+Example queries:
+- ERROR/WARN trend of a service: {"service_name": "checkout"}
+- Around a deploy: {"service_name": "checkout", "time_range_minutes": 120, "split_at": "2024-05-01T14:30:00Z"}
+- Since a deploy 30 minutes ago: {"service_name": "checkout", "split_at": "30m"}
+- A namespace in 5-minute buckets: {"k8s_namespace": "shop", "bucket_minutes": 5}
+- Against last week: {"service_name": "checkout", "baseline": "same_time_last_week"}
+`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"service_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter by service name (exact match)",
+				},
+				"k8s_namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter by Kubernetes namespace (k8s.namespace.name, exact match)",
+				},
+				"k8s_deployment_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter by Kubernetes deployment (k8s.deployment.name, exact match)",
+				},
+				"k8s_pod_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter by Kubernetes pod (k8s.pod.name, exact match)",
+				},
+				"k8s_container_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter by Kubernetes container (k8s.container.name, exact match)",
+				},
+				"attribute_filters": otlp.AttributeFiltersSchema("Arbitrary resource or log attribute filters, combined with AND."),
+				"time_range_minutes": map[string]interface{}{
+					"type":        "integer",
+					"description": "Minutes back to count (default: 60, max: 1440)",
+				},
+				"bucket_minutes": map[string]interface{}{
+					"type":        "integer",
+					"description": "Bucket width in minutes (default: about 12 buckets over the window)",
+				},
+				"split_at": map[string]interface{}{
+					"type":        "string",
+					"description": "Point within the window, such as a deploy time, to compare severity rates before and after: an RFC 3339 timestamp or a duration ago such as 30m",
+				},
+				"max_logs": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum logs to read (default: 5000, max: 20000)",
+				},
+				"baseline": map[string]interface{}{
+					"type":        "string",
+					"description": "Also count the logs of a comparison window and report the change per severity: previous_period (the window just before) or same_time_last_week",
+					"enum":        []string{baselinePreviousPeriod, baselineLastWeek},
+				},
 				"dataset": map[string]interface{}{
 					"type":        "string",
 					"description": "Dash0 dataset to query (e.g., 'astronomy-demo'). If omitted, uses the globally configured dataset or 'default'.",
@@ -124,6 +496,133 @@ Example queries:
 	}
 }
 
+// SeverityTrendHandler handles the dash0_logs_severity_trend tool.
+func (p *Tools) SeverityTrendHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	filters, filterDescs, err := parseFilters(args)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+
+	minutes := 60
+	if m, ok := args["time_range_minutes"].(float64); ok {
+		if m < 0 {
+			return client.ErrorResult(400, "time_range_minutes must not be negative")
+		}
+		if m > 0 {
+			minutes = int(m)
+			if minutes > 1440 {
+				minutes = 1440 // Max 24 hours
+			}
+		}
+	}
+
+	bucketMinutes := (minutes + defaultTrendBuckets - 1) / defaultTrendBuckets
+	if b, ok := args["bucket_minutes"].(float64); ok {
+		if b < 0 {
+			return client.ErrorResult(400, "bucket_minutes must not be negative")
+		}
+		if b > 0 {
+			bucketMinutes = int(b)
+		}
+	}
+	if minBucket := (minutes + maxTrendBuckets - 1) / maxTrendBuckets; bucketMinutes < minBucket {
+		bucketMinutes = minBucket
+	}
+	bucket := time.Duration(bucketMinutes) * time.Minute
+
+	maxLogs := defaultTrendMaxLogs
+	if v, ok := args["max_logs"].(float64); ok {
+		if v < 0 {
+			return client.ErrorResult(400, "max_logs must not be negative")
+		}
+		if v > 0 {
+			maxLogs = int(v)
+			if maxLogs > maxTrendMaxLogs {
+				maxLogs = maxTrendMaxLogs
+			}
+		}
+	}
+
+	now := time.Now().UTC()
+	from := now.Add(-time.Duration(minutes) * time.Minute)
+
+	var splitAt time.Time
+	if raw, _ := args["split_at"].(string); strings.TrimSpace(raw) != "" {
+		raw = strings.TrimSpace(raw)
+		if t, err := time.Parse(time.RFC3339, raw); err == nil {
+			splitAt = t.UTC()
+		} else if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+			splitAt = now.Add(-d)
+		} else {
+			return client.ErrorResult(400, "split_at must be an RFC 3339 timestamp such as 2024-05-01T14:30:00Z, or a duration ago such as 30m")
+		}
+		if !splitAt.After(from) || !splitAt.Before(now) {
+			return client.ErrorResult(400, fmt.Sprintf("split_at must be within the last %d minutes; raise time_range_minutes to include it", minutes))
+		}
+	}
+
+	dataset := ""
+	if ds, ok := args["dataset"].(string); ok && ds != "" {
+		dataset = ds
+	} else {
+		dataset = p.client.GetDataset()
+	}
+
+	baseline, _ := args["baseline"].(string)
+	baseline = strings.TrimSpace(baseline)
+	var baselineTo time.Time
+	switch baseline {
+	case "":
+	case baselinePreviousPeriod:
+		baselineTo = from
+	case baselineLastWeek:
+		baselineTo = now.Add(-7 * 24 * time.Hour)
+	default:
+		return client.ErrorResult(400, fmt.Sprintf("baseline must be %s or %s, got %q", baselinePreviousPeriod, baselineLastWeek, baseline))
+	}
+
+	// The window and its baseline, if any, are fetched concurrently.
+	windows := [][2]time.Time{{from, now}}
+	if baseline != "" {
+		windows = append(windows, [2]time.Time{baselineTo.Add(-now.Sub(from)), baselineTo})
+	}
+	fetched := make([][]FlatLog, len(windows))
+	sampled := make([]bool, len(windows))
+	failed := p.client.Parallel(ctx, len(windows), func(ctx context.Context, i int) *client.ToolResult {
+		req := QueryLogsRequest{
+			Dataset: dataset,
+			TimeRange: TimeRange{
+				From: windows[i][0].Format(time.RFC3339),
+				To:   windows[i][1].Format(time.RFC3339),
+			},
+			Filter: filters,
+		}
+		var errResult *client.ToolResult
+		fetched[i], sampled[i], errResult = Fetch(ctx, p.client, req, dataset, maxLogs)
+		return errResult
+	})
+	if failed != nil {
+		return failed
+	}
+	flatLogs := fetched[0]
+
+	trend := buildSeverityTrend(flatLogs, from, now, bucket)
+	trend.Sampled = sampled[0]
+	if !splitAt.IsZero() {
+		trend.SplitAt = splitAt.Format(time.RFC3339)
+		trend.Changes = compareSeverities(flatLogs, from, splitAt, now)
+	}
+	if baseline != "" {
+		trend.Baseline = newSeverityBaseline(baseline, windows[1][0], windows[1][1], fetched[1], sampled[1], trend.Totals)
+	}
+
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: formatSeverityTrend(trend, filterDescs),
+		Data:     trend,
+	}
+}
+
 // Type aliases for shared OTLP types.
 type AttributeFilter = otlp.AttributeFilter
 type AttributeFilterValue = otlp.AttributeFilterValue
@@ -163,22 +662,155 @@ var severityOrder = map[string]int{
 	"FATAL": 21,
 }
 
-// QueryLogsHandler handles the dash0_logs_query tool.
-func (p *Tools) QueryLogsHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
-	// Build filters
+// resourceFilterArgs maps the exact-match filter arguments of the logs query
+// tools to resource attributes.
+var resourceFilterArgs = []struct {
+	arg, key, label string
+}{
+	{"service_name", "service.name", "service"},
+	{"k8s_namespace", "k8s.namespace.name", "namespace"},
+	{"k8s_deployment_name", "k8s.deployment.name", "deployment"},
+	{"k8s_pod_name", "k8s.pod.name", "pod"},
+	{"k8s_container_name", "k8s.container.name", "container"},
+}
+
+// traceFilterArgs maps the trace_id and span_id arguments to the filter keys
+// of the trace context of a log record.
+var traceFilterArgs = []struct {
+	arg, key, label string
+}{
+	{"trace_id", "otel.trace.id", "trace"},
+	{"span_id", "otel.span.id", "span"},
+}
+
+// parseFilters builds the API filters of a logs query from the resource
+// filter arguments, exclude_services, trace_id, span_id, and
+// attribute_filters, with a description of each.
+func parseFilters(args map[string]interface{}) ([]AttributeFilter, []string, error) {
 	var filters []AttributeFilter
-	var filterDescs []string
+	var descs []string
+
+	for _, rf := range resourceFilterArgs {
+		if value, ok := args[rf.arg].(string); ok {
+			value = strings.TrimSpace(value)
+			if value != "" {
+				filters = append(filters, AttributeFilter{
+					Key:      rf.key,
+					Operator: "is",
+					Value:    &AttributeFilterValue{StringValue: &value},
+				})
+				descs = append(descs, rf.label+"="+value)
+			}
+		}
+	}
 
-	if serviceName, ok := args["service_name"].(string); ok {
-		serviceName = strings.TrimSpace(serviceName)
-		if serviceName != "" {
+	if raw, ok := args["exclude_services"]; ok && raw != nil {
+		services, err := stringList("exclude_services", raw)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, service := range services {
+			service := service
 			filters = append(filters, AttributeFilter{
 				Key:      "service.name",
-				Operator: "is",
-				Value:    &AttributeFilterValue{StringValue: &serviceName},
+				Operator: "is_not",
+				Value:    &AttributeFilterValue{StringValue: &service},
 			})
-			filterDescs = append(filterDescs, "service="+serviceName)
+			descs = append(descs, "service!="+service)
+		}
+	}
+
+	for _, tf := range traceFilterArgs {
+		value, _ := args[tf.arg].(string)
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if strings.ContainsAny(value, " \t") {
+			return nil, nil, fmt.Errorf("%s must be a single ID, got %q", tf.arg, value)
+		}
+		filters = append(filters, AttributeFilter{
+			Key:      tf.key,
+			Operator: "is",
+			Value:    &AttributeFilterValue{StringValue: &value},
+		})
+		descs = append(descs, tf.label+"="+value)
+	}
+
+	if raw, ok := args["attribute_filters"]; ok && raw != nil {
+		attrFilters, attrDescs, err := otlp.ParseAttributeFilters(raw)
+		if err != nil {
+			return nil, nil, err
 		}
+		filters = append(filters, attrFilters...)
+		descs = append(descs, attrDescs...)
+	}
+	return filters, descs, nil
+}
+
+// stringList reads an argument holding an array of strings, dropping blank
+// entries.
+func stringList(name string, raw interface{}) ([]string, error) {
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be an array of strings", name)
+	}
+	var values []string
+	for i, item := range items {
+		v, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s[%d] must be a string", name, i)
+		}
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values, nil
+}
+
+// parseExcludedSeverities reads the exclude_severities argument as a set of
+// severity levels.
+func parseExcludedSeverities(args map[string]interface{}) (map[string]bool, error) {
+	raw, ok := args["exclude_severities"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	levels, err := stringList("exclude_severities", raw)
+	if err != nil {
+		return nil, err
+	}
+	excluded := map[string]bool{}
+	for _, level := range levels {
+		level = strings.ToUpper(level)
+		if level != "UNSET" && severityOrder[level] == 0 {
+			return nil, fmt.Errorf("exclude_severities must hold %s, got %q", strings.Join(severityLevels, ", "), level)
+		}
+		excluded[level] = true
+	}
+	return excluded, nil
+}
+
+// ParseFilters builds the log filters of dash0_logs_query's filter
+// arguments, for tools that run the same query.
+func ParseFilters(args map[string]interface{}) ([]AttributeFilter, []string, error) {
+	return parseFilters(args)
+}
+
+// QueryLogsHandler handles the dash0_logs_query tool.
+func (p *Tools) QueryLogsHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	limits, err := truncate.ParseLimits(args, p.client.ResponseLimits())
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+
+	// Build filters
+	filters, filterDescs, err := parseFilters(args)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+	excluded, err := parseExcludedSeverities(args)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
 	}
 
 	// Calculate time range
@@ -219,6 +851,32 @@ func (p *Tools) QueryLogsHandler(ctx context.Context, args map[string]interface{
 		dataset = p.client.GetDataset()
 	}
 
+	order, err := otlp.ParseOrder(args, orderFields)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+	if order.By != "" {
+		return p.queryOrdered(ctx, args, order, filters, filterDescs, from, now, minutes, dataset, limit, limits)
+	}
+
+	minSeverity, _ := args["min_severity"].(string)
+	bodyContains, _ := args["body_contains"].(string)
+	bodyNotContains, _ := args["body_not_contains"].(string)
+
+	// Fetch extra only when client-side filters may discard records; otherwise the
+	// page size equals the limit so no fetched record is skipped by the next cursor.
+	pageSize := limit
+	if minSeverity != "" || bodyContains != "" || bodyNotContains != "" || len(excluded) > 0 {
+		pageSize = limit * 2
+	}
+
+	// A cursor of an earlier page carries the time window of its query
+	cursor, _ := args["cursor"].(string)
+	cursor = strings.TrimSpace(cursor)
+	if after, cursorFrom, cursorTo, ok := otlp.DecodeCursor(cursor); ok {
+		cursor, from, now = after, cursorFrom, cursorTo
+	}
+
 	// Build request
 	req := QueryLogsRequest{
 		Dataset: dataset,
@@ -227,7 +885,7 @@ func (p *Tools) QueryLogsHandler(ctx context.Context, args map[string]interface{
 			To:   now.Format(time.RFC3339),
 		},
 		Filter:     filters,
-		Pagination: Pagination{Limit: limit * 2}, // Fetch extra for client-side filtering
+		Pagination: Pagination{Limit: pageSize, Cursor: cursor},
 	}
 
 	// Execute query
@@ -238,9 +896,147 @@ func (p *Tools) QueryLogsHandler(ctx context.Context, args map[string]interface{
 
 	// Flatten the OTLP response
 	flatLogs := flattenLogsResponse(result.Data)
+	nextCursor := otlp.EncodeCursor(otlp.NextCursor(result.Data), from, now)
+
+	flatLogs, filterDescs = filterClientSide(flatLogs, args, filterDescs)
+
+	// Apply final limit. Records trimmed here are not revisited by next_cursor.
+	trimmed := 0
+	if len(flatLogs) > limit {
+		trimmed = len(flatLogs) - limit
+		flatLogs = flatLogs[:limit]
+	}
+
+	var report truncate.Report
+	flatLogs = truncate.Items(flatLogs, limits, &report)
+	progress.ReportItems(ctx, "logs", flatLogs, limits)
+
+	// Build markdown table
+	md := formatLogsMarkdown(flatLogs, from, now, filterDescs, limit, nextCursor, trimmed)
+
+	fields := map[string]interface{}{
+		"logs":        flatLogs,
+		"count":       len(flatLogs),
+		"next_cursor": nextCursor,
+		"has_more":    nextCursor != "",
+		"trimmed":     trimmed,
+		"query": map[string]interface{}{
+			"time_range": map[string]string{
+				"from": from.Format(time.RFC3339),
+				"to":   now.Format(time.RFC3339),
+			},
+			"filters": filters,
+			"limit":   limit,
+		},
+	}
+	if suggestions := traceFollowUps(args, minutes); len(suggestions) > 0 {
+		datasetArg, _ := args["dataset"].(string)
+		fields[followup.Key] = followup.WithDataset(suggestions, datasetArg)
+		md += followup.Markdown(suggestions)
+	}
+	var data interface{} = fields
+	data, md = truncate.Apply(data, md, limits, &report)
+
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: md,
+		Data:     data,
+	}
+}
+
+// queryOrdered reads the matching logs up to max_logs and returns the first
+// limit of them in the given order. The API returns logs in its own order,
+// so sorting a single page would not find e.g. the most severe logs.
+func (p *Tools) queryOrdered(ctx context.Context, args map[string]interface{}, order otlp.Order, filters []AttributeFilter, filterDescs []string, from, to time.Time, minutes int, dataset string, limit int, limits truncate.Limits) *client.ToolResult {
+	if cursor, _ := args["cursor"].(string); strings.TrimSpace(cursor) != "" {
+		return client.ErrorResult(400, "cursor does not apply with order_by, which reads up to max_logs logs at once")
+	}
+	maxLogs := defaultQueryMaxLogs
+	if v, ok := args["max_logs"].(float64); ok {
+		if v < 0 {
+			return client.ErrorResult(400, "max_logs must not be negative")
+		}
+		if v > 0 {
+			maxLogs = int(v)
+			if maxLogs > maxTrendMaxLogs {
+				maxLogs = maxTrendMaxLogs
+			}
+		}
+	}
+
+	req := QueryLogsRequest{
+		Dataset: dataset,
+		TimeRange: TimeRange{
+			From: from.Format(time.RFC3339),
+			To:   to.Format(time.RFC3339),
+		},
+		Filter: filters,
+	}
+	flatLogs, sampled, errResult := Fetch(ctx, p.client, req, dataset, maxLogs)
+	if errResult != nil {
+		return errResult
+	}
+	flatLogs, filterDescs = filterClientSide(flatLogs, args, filterDescs)
+
+	matched := len(flatLogs)
+	sortLogs(flatLogs, order)
+	if len(flatLogs) > limit {
+		flatLogs = flatLogs[:limit]
+	}
+
+	var report truncate.Report
+	flatLogs = truncate.Items(flatLogs, limits, &report)
+	progress.ReportItems(ctx, "logs", flatLogs, limits)
+
+	md := formatLogsMarkdown(flatLogs, from, to, filterDescs, limit, "", 0)
+	md += fmt.Sprintf("\n_Sorted by %s across the %d matching logs read._", order, matched)
+	if sampled {
+		md += fmt.Sprintf(" _More than %d logs matched; raise max_logs or narrow the filters to sort them all._", maxLogs)
+	}
+	md += "\n"
+
+	fields := map[string]interface{}{
+		"logs":    flatLogs,
+		"count":   len(flatLogs),
+		"matched": matched,
+		"sampled": sampled,
+		"query": map[string]interface{}{
+			"time_range": map[string]string{
+				"from": from.Format(time.RFC3339),
+				"to":   to.Format(time.RFC3339),
+			},
+			"filters":  filters,
+			"limit":    limit,
+			"order_by": order.String(),
+		},
+	}
+	if suggestions := traceFollowUps(args, minutes); len(suggestions) > 0 {
+		datasetArg, _ := args["dataset"].(string)
+		fields[followup.Key] = followup.WithDataset(suggestions, datasetArg)
+		md += followup.Markdown(suggestions)
+	}
+	var data interface{} = fields
+	data, md = truncate.Apply(data, md, limits, &report)
+
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: md,
+		Data:     data,
+	}
+}
+
+// filterClientSide applies the filters the API cannot: min_severity,
+// exclude_severities, body_contains, body_not_contains, and the check of
+// trace_id and span_id. It adds the filters to filterDescs.
+func filterClientSide(flatLogs []FlatLog, args map[string]interface{}, filterDescs []string) ([]FlatLog, []string) {
+	minSeverity, _ := args["min_severity"].(string)
+	bodyContains, _ := args["body_contains"].(string)
+	bodyNotContains, _ := args["body_not_contains"].(string)
+	// The handler has validated exclude_severities.
+	excluded, _ := parseExcludedSeverities(args)
 
 	// Apply client-side severity filter if specified
-	if minSeverity, ok := args["min_severity"].(string); ok && minSeverity != "" {
+	if minSeverity != "" {
 		minLevel := severityOrder[minSeverity]
 		var filtered []FlatLog
 		for _, log := range flatLogs {
@@ -252,8 +1048,11 @@ func (p *Tools) QueryLogsHandler(ctx context.Context, args map[string]interface{
 		filterDescs = append(filterDescs, "severity>="+minSeverity)
 	}
 
+	// Check the trace context of the records read against trace_id and span_id
+	flatLogs = matchTraceContext(flatLogs, args)
+
 	// Apply client-side body contains filter if specified
-	if bodyContains, ok := args["body_contains"].(string); ok && bodyContains != "" {
+	if bodyContains != "" {
 		bodyContainsLower := strings.ToLower(bodyContains)
 		var filtered []FlatLog
 		for _, log := range flatLogs {
@@ -265,34 +1064,69 @@ func (p *Tools) QueryLogsHandler(ctx context.Context, args map[string]interface{
 		filterDescs = append(filterDescs, "body~"+bodyContains)
 	}
 
-	// Apply final limit
-	if len(flatLogs) > limit {
-		flatLogs = flatLogs[:limit]
+	// Drop the excluded severities and bodies
+	if len(excluded) > 0 || bodyNotContains != "" {
+		bodyNotContainsLower := strings.ToLower(bodyNotContains)
+		var filtered []FlatLog
+		for _, log := range flatLogs {
+			if excluded[severityLevel(log)] {
+				continue
+			}
+			if bodyNotContains != "" && strings.Contains(strings.ToLower(log.Body), bodyNotContainsLower) {
+				continue
+			}
+			filtered = append(filtered, log)
+		}
+		flatLogs = filtered
+		for _, level := range severityLevels {
+			if excluded[level] {
+				filterDescs = append(filterDescs, "severity!="+level)
+			}
+		}
+		if bodyNotContains != "" {
+			filterDescs = append(filterDescs, "body!~"+bodyNotContains)
+		}
 	}
+	return flatLogs, filterDescs
+}
 
-	// Build markdown table
-	md := formatLogsMarkdown(flatLogs, from, now, filterDescs, limit)
+// matchTraceContext keeps the logs whose trace and span IDs equal the
+// trace_id and span_id arguments, ignoring case.
+func matchTraceContext(flatLogs []FlatLog, args map[string]interface{}) []FlatLog {
+	traceID, _ := args["trace_id"].(string)
+	spanID, _ := args["span_id"].(string)
+	traceID, spanID = strings.TrimSpace(traceID), strings.TrimSpace(spanID)
+	if traceID == "" && spanID == "" {
+		return flatLogs
+	}
+	var filtered []FlatLog
+	for _, log := range flatLogs {
+		if traceID != "" && !strings.EqualFold(log.TraceID, traceID) {
+			continue
+		}
+		if spanID != "" && !strings.EqualFold(log.SpanID, spanID) {
+			continue
+		}
+		filtered = append(filtered, log)
+	}
+	return filtered
+}
 
-	return &client.ToolResult{
-		Success:  true,
-		Markdown: md,
-		Data: map[string]interface{}{
-			"logs":  flatLogs,
-			"count": len(flatLogs),
-			"query": map[string]interface{}{
-				"time_range": map[string]string{
-					"from": from.Format(time.RFC3339),
-					"to":   now.Format(time.RFC3339),
-				},
-				"filters": filters,
-				"limit":   limit,
-			},
-		},
+// traceFollowUps suggests the spans of the trace a trace_id query looked up.
+func traceFollowUps(args map[string]interface{}, minutes int) []followup.Suggestion {
+	traceID, _ := args["trace_id"].(string)
+	traceID = strings.TrimSpace(traceID)
+	if traceID == "" {
+		return nil
 	}
+	return []followup.Suggestion{followup.New("dash0_spans_query", map[string]interface{}{
+		"trace_id":           traceID,
+		"time_range_minutes": minutes,
+	}, "See the spans of this trace.")}
 }
 
 // formatLogsMarkdown renders logs as a markdown table with summary statistics.
-func formatLogsMarkdown(logs []FlatLog, from, to time.Time, filterDescs []string, limit int) string {
+func formatLogsMarkdown(logs []FlatLog, from, to time.Time, filterDescs []string, limit int, nextCursor string, trimmed int) string {
 	summaryParts := []string{fmt.Sprintf("**Found %d logs**", len(logs))}
 	summaryParts = append(summaryParts, fmt.Sprintf("Time: %s → %s", from.Format("15:04:05"), to.Format("15:04:05 2006-01-02")))
 	if len(filterDescs) > 0 {
@@ -331,7 +1165,12 @@ func formatLogsMarkdown(logs []FlatLog, from, to time.Time, filterDescs []string
 	}
 
 	footer := ""
-	if len(logs) >= limit {
+	if nextCursor != "" {
+		footer = fmt.Sprintf("_More logs available. Repeat the query with `cursor=%q` for the next page._", nextCursor)
+		if trimmed > 0 {
+			footer += fmt.Sprintf(" _%d matching logs on this page were trimmed by the limit and are not included in the next page; raise limit to see them._", trimmed)
+		}
+	} else if len(logs) >= limit {
 		footer = fmt.Sprintf("_Showing %d of %d+ logs (limit reached). Use limit=500 for more, or add filters._", len(logs), len(logs))
 	}
 
@@ -439,6 +1278,12 @@ func buildLogStats(logs []FlatLog) string {
 	return "> **Stats:** " + strings.Join(statParts, " | ")
 }
 
+// FlattenResponse extracts log records from an OTLP query response, for tools
+// that page through log queries themselves.
+func FlattenResponse(data interface{}) []FlatLog {
+	return flattenLogsResponse(data)
+}
+
 // flattenLogsResponse extracts logs from nested OTLP response structure.
 func flattenLogsResponse(data interface{}) []FlatLog {
 	var logs []FlatLog
@@ -612,5 +1457,7 @@ func Register(reg *registry.Registry, c *client.Client) {
 	for _, tool := range p.Tools() {
 		handler := p.Handlers()[tool.Name]
 		reg.Register(tool, handler)
+		reg.AddExamples(tool.Name, p.Examples()[tool.Name]...)
+		reg.SetOutputSchema(tool.Name, p.OutputSchemas()[tool.Name])
 	}
 }
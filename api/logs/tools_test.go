@@ -5,10 +5,16 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/followup"
+	"github.com/npcomplete777/dash0-mcp/internal/otlp"
+	"github.com/npcomplete777/dash0-mcp/internal/progress"
 )
 
 func TestTools_Tools(t *testing.T) {
@@ -17,13 +23,14 @@ func TestTools_Tools(t *testing.T) {
 
 	tools := pkg.Tools()
 
-	if len(tools) != 2 {
-		t.Errorf("expected 2 tools, got %d", len(tools))
+	if len(tools) != 3 {
+		t.Errorf("expected 3 tools, got %d", len(tools))
 	}
 
 	expectedNames := map[string]bool{
-		"dash0_logs_send":  false,
-		"dash0_logs_query": false,
+		"dash0_logs_send":           false,
+		"dash0_logs_query":          false,
+		"dash0_logs_severity_trend": false,
 	}
 
 	for _, tool := range tools {
@@ -46,11 +53,11 @@ func TestTools_Handlers(t *testing.T) {
 
 	handlers := pkg.Handlers()
 
-	if len(handlers) != 2 {
-		t.Errorf("expected 2 handlers, got %d", len(handlers))
+	if len(handlers) != 3 {
+		t.Errorf("expected 3 handlers, got %d", len(handlers))
 	}
 
-	expectedHandlers := []string{"dash0_logs_send", "dash0_logs_query"}
+	expectedHandlers := []string{"dash0_logs_send", "dash0_logs_query", "dash0_logs_severity_trend"}
 	for _, name := range expectedHandlers {
 		if _, exists := handlers[name]; !exists {
 			t.Errorf("handler %s not found", name)
@@ -82,6 +89,24 @@ func TestPostLogsHandler(t *testing.T) {
 			args:        map[string]interface{}{},
 			wantSuccess: false,
 		},
+		{
+			name: "gzip-compressed json",
+			args: map[string]interface{}{
+				"body":             map[string]interface{}{"resourceLogs": []interface{}{}},
+				"content_encoding": "json_gzip",
+			},
+			serverCode:  http.StatusOK,
+			serverResp:  map[string]interface{}{"status": "ok"},
+			wantSuccess: true,
+		},
+		{
+			name: "invalid base64 protobuf",
+			args: map[string]interface{}{
+				"body":             "%%%",
+				"content_encoding": "protobuf",
+			},
+			wantSuccess: false,
+		},
 		{
 			name: "server error",
 			args: map[string]interface{}{
@@ -890,7 +915,7 @@ func TestQueryLogs_ToolDefinition(t *testing.T) {
 	}
 
 	// Verify all expected properties exist
-	expectedProps := []string{"service_name", "time_range_minutes", "min_severity", "body_contains", "limit"}
+	expectedProps := []string{"service_name", "time_range_minutes", "min_severity", "body_contains", "limit", "cursor"}
 	for _, prop := range expectedProps {
 		if _, exists := tool.InputSchema.Properties[prop]; !exists {
 			t.Errorf("expected property %s not found", prop)
@@ -962,6 +987,431 @@ func TestQueryLogsHandler_NegativeLimit(t *testing.T) {
 	}
 }
 
+func TestQueryLogsHandler_ResourceFilters(t *testing.T) {
+	var receivedRequest QueryLogsRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&receivedRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"resourceLogs": []interface{}{},
+		})
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.QueryLogsHandler(context.Background(), map[string]interface{}{
+		"k8s_namespace":       "shop",
+		"k8s_deployment_name": "cart",
+		"k8s_pod_name":        "cart-7d9f-abcde",
+		"k8s_container_name":  " server ",
+		"attribute_filters": []interface{}{
+			map[string]interface{}{"key": "deployment.environment", "value": "prod"},
+		},
+	})
+	if !result.Success {
+		t.Fatalf("QueryLogsHandler failed: %v", result.Error)
+	}
+
+	expected := []struct{ key, value string }{
+		{"k8s.namespace.name", "shop"},
+		{"k8s.deployment.name", "cart"},
+		{"k8s.pod.name", "cart-7d9f-abcde"},
+		{"k8s.container.name", "server"},
+		{"deployment.environment", "prod"},
+	}
+	if len(receivedRequest.Filter) != len(expected) {
+		t.Fatalf("Filter count = %d, expected %d", len(receivedRequest.Filter), len(expected))
+	}
+	for i, want := range expected {
+		f := receivedRequest.Filter[i]
+		if f.Key != want.key || f.Operator != "is" || f.Value.StringValue == nil || *f.Value.StringValue != want.value {
+			t.Errorf("Filter[%d] = %+v, expected %s is %s", i, f, want.key, want.value)
+		}
+	}
+
+	for _, desc := range []string{"namespace=shop", "deployment=cart", "pod=cart-7d9f-abcde", "container=server"} {
+		if !strings.Contains(result.Markdown, desc) {
+			t.Errorf("Markdown missing filter %q: %s", desc, result.Markdown)
+		}
+	}
+}
+
+func TestQueryLogsHandler_TraceID(t *testing.T) {
+	var receivedRequest QueryLogsRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&receivedRequest)
+		var records []interface{}
+		for _, traceID := range []string{"4bf92f35", "0af76519", "4BF92F35"} {
+			records = append(records, map[string]interface{}{
+				"timeUnixNano": "1000000000",
+				"severityText": "INFO",
+				"body":         map[string]interface{}{"stringValue": "order placed"},
+				"traceId":      traceID,
+				"spanId":       "00f067aa",
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"resourceLogs": []interface{}{map[string]interface{}{
+				"scopeLogs": []interface{}{map[string]interface{}{"logRecords": records}},
+			}},
+		})
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.QueryLogsHandler(context.Background(), map[string]interface{}{
+		"trace_id": "4bf92f35",
+		"span_id":  "00F067AA",
+	})
+	if !result.Success {
+		t.Fatalf("QueryLogsHandler failed: %v", result.Error)
+	}
+	if len(receivedRequest.Filter) != 2 || receivedRequest.Filter[0].Key != "otel.trace.id" || receivedRequest.Filter[1].Key != "otel.span.id" {
+		t.Errorf("Filter = %+v, expected otel.trace.id and otel.span.id", receivedRequest.Filter)
+	}
+
+	data := result.Data.(map[string]interface{})
+	if data["count"] != 2 {
+		t.Errorf("count = %v, expected the 2 logs of the trace", data["count"])
+	}
+	suggestions, _ := data["suggested_follow_ups"].([]followup.Suggestion)
+	if len(suggestions) != 1 || suggestions[0].Tool != "dash0_spans_query" || suggestions[0].Arguments["trace_id"] != "4bf92f35" {
+		t.Errorf("follow-ups = %+v, expected dash0_spans_query for the trace", suggestions)
+	}
+	if !strings.Contains(result.Markdown, "trace=4bf92f35") {
+		t.Errorf("Markdown missing the trace filter: %s", result.Markdown)
+	}
+
+	bad := pkg.QueryLogsHandler(context.Background(), map[string]interface{}{"trace_id": "4bf9 2f35"})
+	if bad.Success || bad.Error.StatusCode != 400 {
+		t.Errorf("result = %+v, expected a 400 for the trace ID", bad)
+	}
+}
+
+func TestQueryLogsHandler_OrderBy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req QueryLogsRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		// Two pages, the most severe log on the second.
+		severities := []string{"INFO", "WARN", "DEBUG"}
+		next := "page-2"
+		if req.Pagination.Cursor == "page-2" {
+			severities, next = []string{"ERROR", "INFO"}, ""
+		}
+		var records []interface{}
+		for i, severity := range severities {
+			records = append(records, map[string]interface{}{
+				"timeUnixNano":   strconv.Itoa((len(req.Pagination.Cursor)*10 + i + 1) * 1e9),
+				"severityText":   severity,
+				"severityNumber": severityOrder[severity],
+				"body":           map[string]interface{}{"stringValue": severity + " message"},
+			})
+		}
+		resp := map[string]interface{}{
+			"resourceLogs": []interface{}{map[string]interface{}{
+				"scopeLogs": []interface{}{map[string]interface{}{"logRecords": records}},
+			}},
+		}
+		if next != "" {
+			resp["cursors"] = map[string]interface{}{"after": next}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.QueryLogsHandler(context.Background(), map[string]interface{}{
+		"order_by": "severity",
+		"limit":    float64(2),
+	})
+	if !result.Success {
+		t.Fatalf("QueryLogsHandler failed: %v", result.Error)
+	}
+	data := result.Data.(map[string]interface{})
+	got := data["logs"].([]FlatLog)
+	if len(got) != 2 || got[0].SeverityText != "ERROR" || got[1].SeverityText != "WARN" || data["matched"] != 5 {
+		t.Errorf("logs = %+v of %v matched, expected ERROR then WARN of 5", got, data["matched"])
+	}
+	if !strings.Contains(result.Markdown, "Sorted by severity desc across the 5 matching logs read") {
+		t.Errorf("Markdown missing the order note: %s", result.Markdown)
+	}
+
+	result = pkg.QueryLogsHandler(context.Background(), map[string]interface{}{
+		"order_by":     "timestamp",
+		"min_severity": "INFO",
+	})
+	got = result.Data.(map[string]interface{})["logs"].([]FlatLog)
+	if len(got) != 4 || got[0].SeverityText != "INFO" || got[0].Body != "INFO message" || got[len(got)-1].SeverityText != "INFO" {
+		t.Errorf("logs = %+v, expected the 4 logs of INFO or above, newest first", got)
+	}
+
+	for _, args := range []map[string]interface{}{
+		{"order_by": "duration"},
+		{"order_by": "severity", "direction": "sideways"},
+		{"order_by": "severity", "cursor": "page-2"},
+	} {
+		if bad := pkg.QueryLogsHandler(context.Background(), args); bad.Success || bad.Error.StatusCode != 400 {
+			t.Errorf("%v: result = %+v, expected a 400", args, bad)
+		}
+	}
+}
+
+func TestQueryLogsHandler_Exclusions(t *testing.T) {
+	var receivedRequest QueryLogsRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&receivedRequest)
+		var records []interface{}
+		for _, rec := range []struct{ severity, body string }{
+			{"INFO", "GET /healthz 200"},
+			{"DEBUG", "cache miss"},
+			{"ERROR", "payment failed"},
+			{"INFO", "order placed"},
+		} {
+			records = append(records, map[string]interface{}{
+				"timeUnixNano":   "1000000000",
+				"severityText":   rec.severity,
+				"severityNumber": severityOrder[rec.severity],
+				"body":           map[string]interface{}{"stringValue": rec.body},
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"resourceLogs": []interface{}{map[string]interface{}{
+				"scopeLogs": []interface{}{map[string]interface{}{"logRecords": records}},
+			}},
+		})
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.QueryLogsHandler(context.Background(), map[string]interface{}{
+		"exclude_services":   []interface{}{"load-generator", " "},
+		"exclude_severities": []interface{}{"debug"},
+		"body_not_contains":  "HEALTHZ",
+		"limit":              float64(10),
+	})
+	if !result.Success {
+		t.Fatalf("QueryLogsHandler failed: %v", result.Error)
+	}
+	if len(receivedRequest.Filter) != 1 || receivedRequest.Filter[0].Key != "service.name" || receivedRequest.Filter[0].Operator != "is_not" {
+		t.Errorf("Filter = %+v, expected service.name is_not load-generator", receivedRequest.Filter)
+	}
+	if receivedRequest.Pagination.Limit != 20 {
+		t.Errorf("page size = %d, expected 20 to make up for the logs dropped", receivedRequest.Pagination.Limit)
+	}
+
+	got := result.Data.(map[string]interface{})["logs"].([]FlatLog)
+	if len(got) != 2 || got[0].Body != "payment failed" || got[1].Body != "order placed" {
+		t.Errorf("logs = %+v, expected the payment and order logs", got)
+	}
+	for _, desc := range []string{"service!=load-generator", "severity!=DEBUG", "body!~HEALTHZ"} {
+		if !strings.Contains(result.Markdown, desc) {
+			t.Errorf("Markdown missing filter %q: %s", desc, result.Markdown)
+		}
+	}
+
+	for _, args := range []map[string]interface{}{
+		{"exclude_severities": []interface{}{"LOUD"}},
+		{"exclude_severities": "DEBUG"},
+		{"exclude_services": []interface{}{42}},
+	} {
+		if bad := pkg.QueryLogsHandler(context.Background(), args); bad.Success || bad.Error.StatusCode != 400 {
+			t.Errorf("%v: result = %+v, expected a 400", args, bad)
+		}
+	}
+}
+
+func TestQueryLogsHandler_InvalidAttributeFilters(t *testing.T) {
+	pkg := New(client.NewWithBaseURL("http://example.com", "test-token"))
+
+	result := pkg.QueryLogsHandler(context.Background(), map[string]interface{}{
+		"attribute_filters": []interface{}{
+			map[string]interface{}{"value": "prod"},
+		},
+	})
+	if result.Success {
+		t.Fatal("expected error for attribute filter without key")
+	}
+	if result.Error.StatusCode != 400 {
+		t.Errorf("StatusCode = %d, expected 400", result.Error.StatusCode)
+	}
+}
+
+func TestQueryLogsHandler_Progress(t *testing.T) {
+	now := time.Now().UTC()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(logRecordsResponse("",
+			logRecord(now.Add(-3*time.Minute), 17, "ERROR"),
+			logRecord(now.Add(-2*time.Minute), 9, "INFO"),
+			logRecord(now.Add(-1*time.Minute), 17, "ERROR"),
+		))
+	}))
+	defer server.Close()
+
+	// The logs of the result are streamed after the client-side filters and
+	// max_items
+	var batches [][]FlatLog
+	ctx := progress.WithReporter(context.Background(), func(_, _ float64, _ string, batch interface{}) {
+		logs, _ := batch.([]FlatLog)
+		batches = append(batches, logs)
+	})
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.QueryLogsHandler(ctx, map[string]interface{}{"min_severity": "ERROR", "max_items": float64(1)})
+	if !result.Success {
+		t.Fatalf("QueryLogsHandler failed: %v", result.Error)
+	}
+	if len(batches) != 1 || len(batches[0]) != 1 || batches[0][0].SeverityText != "ERROR" {
+		t.Errorf("batches = %+v, want one batch with one ERROR log", batches)
+	}
+}
+
+func TestQueryLogsHandler_Cursor(t *testing.T) {
+	tests := []struct {
+		name             string
+		args             map[string]interface{}
+		expectedPageSize int
+	}{
+		{
+			name:             "no client-side filters",
+			args:             map[string]interface{}{"cursor": "page-2", "limit": float64(50)},
+			expectedPageSize: 50,
+		},
+		{
+			name:             "client-side filter over-fetches",
+			args:             map[string]interface{}{"cursor": "page-2", "limit": float64(50), "min_severity": "ERROR"},
+			expectedPageSize: 100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var receivedRequest QueryLogsRequest
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewDecoder(r.Body).Decode(&receivedRequest)
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"resourceLogs": []interface{}{},
+					"cursors":      map[string]interface{}{"after": "page-3"},
+				})
+			}))
+			defer server.Close()
+
+			pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+			result := pkg.QueryLogsHandler(context.Background(), tt.args)
+			if !result.Success {
+				t.Fatalf("QueryLogsHandler failed: %v", result.Error)
+			}
+
+			if receivedRequest.Pagination.Cursor != "page-2" {
+				t.Errorf("Cursor = %q, expected page-2", receivedRequest.Pagination.Cursor)
+			}
+			if receivedRequest.Pagination.Limit != tt.expectedPageSize {
+				t.Errorf("Limit = %d, expected %d", receivedRequest.Pagination.Limit, tt.expectedPageSize)
+			}
+
+			data := result.Data.(map[string]interface{})
+			after, from, to, ok := otlp.DecodeCursor(data["next_cursor"].(string))
+			if !ok || after != "page-3" {
+				t.Fatalf("next_cursor = %v, expected page-3 with the query window", data["next_cursor"])
+			}
+			if window := (TimeRange{From: from.Format(time.RFC3339), To: to.Format(time.RFC3339)}); window != receivedRequest.TimeRange {
+				t.Errorf("next_cursor window = %+v, expected %+v", window, receivedRequest.TimeRange)
+			}
+
+			// The next page is read from the same window, whatever
+			// time_range_minutes says
+			window := receivedRequest.TimeRange
+			args := map[string]interface{}{"time_range_minutes": float64(240)}
+			for k, v := range tt.args {
+				args[k] = v
+			}
+			args["cursor"] = data["next_cursor"]
+			if result := pkg.QueryLogsHandler(context.Background(), args); !result.Success {
+				t.Fatalf("QueryLogsHandler failed: %v", result.Error)
+			}
+			if receivedRequest.Pagination.Cursor != "page-3" || receivedRequest.TimeRange != window {
+				t.Errorf("next page request = %q %+v, expected page-3 %+v", receivedRequest.Pagination.Cursor, receivedRequest.TimeRange, window)
+			}
+		})
+	}
+}
+
+func TestPostLogsHandler_Verify(t *testing.T) {
+	verifyPollInterval = 10 * time.Millisecond
+	defer func() { verifyPollInterval = 2 * time.Second }()
+
+	queries := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if _, isQuery := body["timeRange"]; !isQuery {
+			json.NewEncoder(w).Encode(map[string]interface{}{})
+			return
+		}
+
+		queries++
+		var records []interface{}
+		if queries >= 2 {
+			records = append(records, map[string]interface{}{
+				"timeUnixNano": "1700000000123456789",
+				"body":         map[string]interface{}{"stringValue": "checkout failed"},
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"resourceLogs": []interface{}{
+				map[string]interface{}{"scopeLogs": []interface{}{map[string]interface{}{"logRecords": records}}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.PostLogsHandler(context.Background(), map[string]interface{}{
+		"body": map[string]interface{}{
+			"resourceLogs": []interface{}{
+				map[string]interface{}{
+					"scopeLogs": []interface{}{
+						map[string]interface{}{
+							"logRecords": []interface{}{
+								map[string]interface{}{
+									"timeUnixNano": "1700000000123456789",
+									"body":         map[string]interface{}{"stringValue": "checkout failed"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"verify":                 true,
+		"verify_timeout_seconds": float64(5),
+	})
+	if !result.Success {
+		t.Fatalf("PostLogsHandler failed: %v", result.Error)
+	}
+
+	v := result.Data.(map[string]interface{})["verification"].(otlp.IngestVerification)
+	if !v.Verified || v.Attempts != 2 {
+		t.Errorf("verification = %+v, expected verified after 2 attempts", v)
+	}
+	if !strings.Contains(result.Markdown, "all 1 log records are queryable") {
+		t.Errorf("Markdown = %q", result.Markdown)
+	}
+}
+
+func TestPostLogsHandler_NegativeVerifyTimeout(t *testing.T) {
+	pkg := New(client.NewWithBaseURL("http://example.com", "test-token"))
+	result := pkg.PostLogsHandler(context.Background(), map[string]interface{}{
+		"body":                   map[string]interface{}{},
+		"verify":                 true,
+		"verify_timeout_seconds": float64(-1),
+	})
+	if result.Success {
+		t.Error("expected error for negative verify_timeout_seconds")
+	}
+}
+
 func TestBuildLogStats(t *testing.T) {
 	logs := []FlatLog{
 		{SeverityText: "ERROR", ServiceName: "svc-a", K8sPodName: "pod-1", TraceID: "t1"},
@@ -1012,3 +1462,304 @@ func TestBuildLogStats_EmptyLogs(t *testing.T) {
 		t.Errorf("should show 0%% trace correlation, got: %s", result)
 	}
 }
+
+func TestOutputSchemas(t *testing.T) {
+	now := time.Now().UTC()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(logRecordsResponse("", logRecord(now.Add(-time.Minute), 17, "ERROR")))
+	}))
+	defer server.Close()
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	schemas := pkg.OutputSchemas()
+
+	for _, tt := range []struct {
+		tool string
+		args map[string]interface{}
+	}{
+		{"dash0_logs_query", map[string]interface{}{}},
+		{"dash0_logs_query", map[string]interface{}{"order_by": "severity"}},
+		{"dash0_logs_severity_trend", map[string]interface{}{}},
+	} {
+		result := pkg.Handlers()[tt.tool](context.Background(), tt.args)
+		if !result.Success {
+			t.Fatalf("%s %v failed: %v", tt.tool, tt.args, result.Error)
+		}
+		data, _ := json.Marshal(result.Data)
+		var got map[string]interface{}
+		json.Unmarshal(data, &got)
+
+		props := schemas[tt.tool]["properties"].(map[string]interface{})
+		for key := range got {
+			if _, ok := props[key]; !ok {
+				t.Errorf("%s %v: field %q is not in the output schema", tt.tool, tt.args, key)
+			}
+		}
+		logs, _ := got["logs"].([]interface{})
+		for _, log := range logs {
+			items := props["logs"].(map[string]interface{})["items"].(map[string]interface{})["properties"].(map[string]interface{})
+			for key := range log.(map[string]interface{}) {
+				if _, ok := items[key]; !ok {
+					t.Errorf("log field %q is not in the output schema", key)
+				}
+			}
+		}
+	}
+}
+
+// logRecordsResponse builds a one-resource logs query response.
+func logRecordsResponse(cursor string, records ...map[string]interface{}) map[string]interface{} {
+	logRecords := make([]interface{}, len(records))
+	for i, r := range records {
+		logRecords[i] = r
+	}
+	resp := map[string]interface{}{
+		"resourceLogs": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": []interface{}{
+						map[string]interface{}{"key": "service.name", "value": map[string]interface{}{"stringValue": "checkout"}},
+					},
+				},
+				"scopeLogs": []interface{}{
+					map[string]interface{}{"logRecords": logRecords},
+				},
+			},
+		},
+	}
+	if cursor != "" {
+		resp["cursors"] = map[string]interface{}{"after": cursor}
+	}
+	return resp
+}
+
+// logRecord builds a log record at the given time.
+func logRecord(at time.Time, severityNumber int, severityText string) map[string]interface{} {
+	return map[string]interface{}{
+		"timeUnixNano":   strconv.FormatInt(at.UnixNano(), 10),
+		"severityNumber": float64(severityNumber),
+		"severityText":   severityText,
+		"body":           map[string]interface{}{"stringValue": "message"},
+	}
+}
+
+func TestSeverityTrendHandler(t *testing.T) {
+	now := time.Now().UTC()
+	var requests []QueryLogsRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req QueryLogsRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		if req.Pagination.Cursor == "" {
+			json.NewEncoder(w).Encode(logRecordsResponse("page-2",
+				logRecord(now.Add(-50*time.Minute), 17, "ERROR"),
+				logRecord(now.Add(-45*time.Minute), 9, "INFO"),
+			))
+			return
+		}
+		json.NewEncoder(w).Encode(logRecordsResponse("",
+			logRecord(now.Add(-10*time.Minute), 0, "warning"),
+			logRecord(now.Add(-5*time.Minute), 17, "ERROR"),
+		))
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.SeverityTrendHandler(context.Background(), map[string]interface{}{
+		"service_name":       "checkout",
+		"time_range_minutes": float64(60),
+		"bucket_minutes":     float64(10),
+		"split_at":           "30m",
+	})
+	if !result.Success {
+		t.Fatalf("SeverityTrendHandler failed: %v", result.Error)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("requests = %d, expected 2 pages", len(requests))
+	}
+	if requests[1].Pagination.Cursor != "page-2" {
+		t.Errorf("second page cursor = %q, expected page-2", requests[1].Pagination.Cursor)
+	}
+	if len(requests[0].Filter) != 1 || requests[0].Filter[0].Key != "service.name" {
+		t.Errorf("unexpected filters: %+v", requests[0].Filter)
+	}
+
+	trend, ok := result.Data.(SeverityTrendResult)
+	if !ok {
+		t.Fatalf("Data is %T, expected SeverityTrendResult", result.Data)
+	}
+	if trend.Logs != 4 || trend.Sampled {
+		t.Errorf("Logs = %d, Sampled = %v; expected 4, false", trend.Logs, trend.Sampled)
+	}
+	if trend.BucketMinutes != 10 || len(trend.Buckets) < 6 || len(trend.Buckets) > 7 {
+		t.Errorf("BucketMinutes = %d with %d buckets, expected 10 with 6-7", trend.BucketMinutes, len(trend.Buckets))
+	}
+	if trend.Totals["ERROR"] != 2 || trend.Totals["WARN"] != 1 || trend.Totals["INFO"] != 1 {
+		t.Errorf("Totals = %v", trend.Totals)
+	}
+	bucketed := 0
+	for _, b := range trend.Buckets {
+		bucketed += b.Total
+	}
+	if bucketed != 4 {
+		t.Errorf("bucket totals = %d, expected 4", bucketed)
+	}
+
+	changes := make(map[string]SeverityChange)
+	for _, c := range trend.Changes {
+		changes[c.Severity] = c
+	}
+	if c := changes["ERROR"]; c.ChangePct == nil || *c.ChangePct != 0 {
+		t.Errorf("ERROR change = %+v, expected 0%%", c)
+	}
+	if c := changes["WARN"]; c.ChangePct != nil || c.AfterPerMinute == 0 {
+		t.Errorf("WARN change = %+v, expected new after the split", c)
+	}
+	if c := changes["INFO"]; c.ChangePct == nil || *c.ChangePct != -100 {
+		t.Errorf("INFO change = %+v, expected -100%%", c)
+	}
+
+	for _, want := range []string{"Log Severity Trend", "| Bucket | ERROR | WARN | INFO | Total |", "Before vs after", "WARN 0.00/min → 0.03/min (new)", "service=checkout"} {
+		if !strings.Contains(result.Markdown, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, result.Markdown)
+		}
+	}
+}
+
+func TestSeverityTrendHandler_Sampled(t *testing.T) {
+	now := time.Now().UTC()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(logRecordsResponse("more",
+			logRecord(now.Add(-time.Minute), 17, "ERROR"),
+			logRecord(now.Add(-2*time.Minute), 17, "ERROR"),
+		))
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.SeverityTrendHandler(context.Background(), map[string]interface{}{
+		"max_logs": float64(2),
+	})
+	if !result.Success {
+		t.Fatalf("SeverityTrendHandler failed: %v", result.Error)
+	}
+	trend := result.Data.(SeverityTrendResult)
+	if !trend.Sampled || trend.Logs != 2 {
+		t.Errorf("Sampled = %v, Logs = %d; expected true, 2", trend.Sampled, trend.Logs)
+	}
+	if !strings.Contains(result.Markdown, "counts cover the first 2") {
+		t.Errorf("Markdown missing sampling note:\n%s", result.Markdown)
+	}
+}
+
+func TestSeverityTrendHandler_Baseline(t *testing.T) {
+	var mu sync.Mutex
+	var baselineTo time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req QueryLogsRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		to, _ := time.Parse(time.RFC3339, req.TimeRange.To)
+		if time.Since(to) < time.Minute {
+			json.NewEncoder(w).Encode(logRecordsResponse("",
+				logRecord(to.Add(-5*time.Minute), 17, "ERROR"),
+				logRecord(to.Add(-4*time.Minute), 17, "ERROR"),
+				logRecord(to.Add(-3*time.Minute), 13, "WARN"),
+			))
+			return
+		}
+		mu.Lock()
+		baselineTo = to
+		mu.Unlock()
+		json.NewEncoder(w).Encode(logRecordsResponse("",
+			logRecord(to.Add(-5*time.Minute), 17, "ERROR"),
+			logRecord(to.Add(-4*time.Minute), 9, "INFO"),
+		))
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.SeverityTrendHandler(context.Background(), map[string]interface{}{
+		"time_range_minutes": float64(60),
+		"baseline":           "same_time_last_week",
+	})
+	if !result.Success {
+		t.Fatalf("SeverityTrendHandler failed: %v", result.Error)
+	}
+
+	if ago := time.Since(baselineTo); ago < 7*24*time.Hour-time.Minute || ago > 7*24*time.Hour+time.Minute {
+		t.Errorf("baseline window ends %v ago, want a week", ago)
+	}
+	b := result.Data.(SeverityTrendResult).Baseline
+	if b == nil || b.Kind != "same_time_last_week" || b.Logs != 2 {
+		t.Fatalf("unexpected baseline: %+v", b)
+	}
+	if b.ChangePct["ERROR"] != 100 || b.ChangePct["INFO"] != -100 {
+		t.Errorf("ChangePct = %v", b.ChangePct)
+	}
+	if _, ok := b.ChangePct["WARN"]; ok {
+		t.Errorf("WARN was absent from the baseline and should have no change: %v", b.ChangePct)
+	}
+	for _, want := range []string{"vs the same time last week:", "ERROR 1 → 2 (+100%)", "WARN 0 → 1 (new)", "INFO 1 → 0 (-100%)"} {
+		if !strings.Contains(result.Markdown, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, result.Markdown)
+		}
+	}
+}
+
+func TestSeverityTrendHandler_Validation(t *testing.T) {
+	pkg := New(client.NewWithBaseURL("http://example.com", "test-token"))
+
+	tests := []struct {
+		name        string
+		args        map[string]interface{}
+		expectError string
+	}{
+		{"negative bucket", map[string]interface{}{"bucket_minutes": float64(-1)}, "bucket_minutes must not be negative"},
+		{"negative max_logs", map[string]interface{}{"max_logs": float64(-1)}, "max_logs must not be negative"},
+		{"bad split_at", map[string]interface{}{"split_at": "after lunch"}, "split_at must be an RFC 3339 timestamp"},
+		{"split_at outside window", map[string]interface{}{"split_at": "2h"}, "split_at must be within the last 60 minutes"},
+		{"invalid attribute filter", map[string]interface{}{"attribute_filters": "k8s.pod.name=cart"}, "must be an array"},
+		{"bad baseline", map[string]interface{}{"baseline": "yesterday"}, "baseline must be previous_period or same_time_last_week"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := pkg.SeverityTrendHandler(context.Background(), tt.args)
+			if result.Success {
+				t.Fatal("expected error")
+			}
+			if result.Error.StatusCode != 400 || !strings.Contains(result.Error.Detail, tt.expectError) {
+				t.Errorf("error = %d %q, expected 400 containing %q", result.Error.StatusCode, result.Error.Detail, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestSeverityLevel(t *testing.T) {
+	tests := []struct {
+		number   int
+		text     string
+		expected string
+	}{
+		{24, "", "FATAL"},
+		{17, "", "ERROR"},
+		{14, "WARN2", "WARN"},
+		{9, "", "INFO"},
+		{5, "", "DEBUG"},
+		{1, "", "TRACE"},
+		{0, "Warning", "WARN"},
+		{0, "critical", "FATAL"},
+		{0, "ERROR3", "ERROR"},
+		{0, "notice", "INFO"},
+		{0, "", "UNSET"},
+		{0, "verbose", "UNSET"},
+	}
+
+	for _, tt := range tests {
+		got := severityLevel(FlatLog{SeverityNumber: tt.number, SeverityText: tt.text})
+		if got != tt.expected {
+			t.Errorf("severityLevel(%d, %q) = %q, want %q", tt.number, tt.text, got, tt.expected)
+		}
+	}
+}
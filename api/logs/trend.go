@@ -0,0 +1,365 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+	"github.com/npcomplete777/dash0-mcp/internal/otlp"
+)
+
+// Baselines a trend can be compared against.
+const (
+	baselinePreviousPeriod = "previous_period"
+	baselineLastWeek       = "same_time_last_week"
+)
+
+// severityLevels are the severity columns of a trend, most severe first.
+var severityLevels = []string{"FATAL", "ERROR", "WARN", "INFO", "DEBUG", "TRACE", "UNSET"}
+
+// severityAliases maps severity texts that are not OpenTelemetry short names
+// to the level they stand for.
+var severityAliases = map[string]string{
+	"CRITICAL":    "FATAL",
+	"CRIT":        "FATAL",
+	"EMERGENCY":   "FATAL",
+	"ALERT":       "FATAL",
+	"PANIC":       "FATAL",
+	"ERR":         "ERROR",
+	"WARNING":     "WARN",
+	"NOTICE":      "INFO",
+	"INFORMATION": "INFO",
+}
+
+// severityLevel returns the severity column of a log: the range its
+// severityNumber falls in, or its severityText when the number is unset.
+func severityLevel(log FlatLog) string {
+	switch n := log.SeverityNumber; {
+	case n >= severityOrder["FATAL"]:
+		return "FATAL"
+	case n >= severityOrder["ERROR"]:
+		return "ERROR"
+	case n >= severityOrder["WARN"]:
+		return "WARN"
+	case n >= severityOrder["INFO"]:
+		return "INFO"
+	case n >= severityOrder["DEBUG"]:
+		return "DEBUG"
+	case n >= severityOrder["TRACE"]:
+		return "TRACE"
+	}
+
+	text := strings.ToUpper(strings.TrimSpace(log.SeverityText))
+	if level, ok := severityAliases[text]; ok {
+		return level
+	}
+	for _, level := range severityLevels {
+		// Short names may carry a suffix, as in ERROR2 or INFO4.
+		if strings.HasPrefix(text, level) {
+			return level
+		}
+	}
+	return "UNSET"
+}
+
+// SeverityLevel returns the severity column of a log, for tools that group
+// logs by severity themselves.
+func SeverityLevel(log FlatLog) string {
+	return severityLevel(log)
+}
+
+// SeverityBucket counts the logs of one time bucket by severity.
+type SeverityBucket struct {
+	Start  string         `json:"start"`
+	Counts map[string]int `json:"counts"`
+	Total  int            `json:"total"`
+}
+
+// SeverityChange compares the rate of one severity before and after a point
+// in time.
+type SeverityChange struct {
+	Severity        string  `json:"severity"`
+	BeforePerMinute float64 `json:"before_per_minute"`
+	AfterPerMinute  float64 `json:"after_per_minute"`
+	// ChangePct is the relative change of the rate; it is omitted when there
+	// were no logs of the severity before.
+	ChangePct *float64 `json:"change_pct,omitempty"`
+}
+
+// SeverityTrendResult is the result of dash0_logs_severity_trend.
+type SeverityTrendResult struct {
+	From          string           `json:"from"`
+	To            string           `json:"to"`
+	BucketMinutes int              `json:"bucket_minutes"`
+	Buckets       []SeverityBucket `json:"buckets"`
+	Totals        map[string]int   `json:"totals"`
+	Logs          int              `json:"logs"`
+	// Sampled is true when more logs matched than were read.
+	Sampled bool             `json:"sampled"`
+	SplitAt string           `json:"split_at,omitempty"`
+	Changes []SeverityChange `json:"changes,omitempty"`
+	// Baseline is set when the call asked for a baseline comparison.
+	Baseline *SeverityBaseline `json:"baseline,omitempty"`
+}
+
+// SeverityBaseline holds the severity totals of a comparison window and the
+// change from them.
+type SeverityBaseline struct {
+	Kind    string         `json:"kind"`
+	From    string         `json:"from"`
+	To      string         `json:"to"`
+	Totals  map[string]int `json:"totals"`
+	Logs    int            `json:"logs"`
+	Sampled bool           `json:"sampled"`
+	// ChangePct is the relative change of each severity's total; severities
+	// absent from the baseline are omitted.
+	ChangePct map[string]float64 `json:"change_pct"`
+}
+
+// newSeverityBaseline counts the baseline logs by severity and compares the
+// current totals with them.
+func newSeverityBaseline(kind string, from, to time.Time, logs []FlatLog, sampled bool, current map[string]int) *SeverityBaseline {
+	b := &SeverityBaseline{
+		Kind:      kind,
+		From:      from.Format(time.RFC3339),
+		To:        to.Format(time.RFC3339),
+		Totals:    make(map[string]int),
+		Logs:      len(logs),
+		Sampled:   sampled,
+		ChangePct: make(map[string]float64),
+	}
+	for _, log := range logs {
+		b.Totals[severityLevel(log)]++
+	}
+	for level, base := range b.Totals {
+		b.ChangePct[level] = float64(current[level]-base) / float64(base) * 100
+	}
+	return b
+}
+
+// Fetch pages through a logs query until maxLogs records are collected or
+// no further pages are available. The returned flag reports whether more
+// logs matched than were collected.
+func Fetch(ctx context.Context, c *client.Client, req QueryLogsRequest, dataset string, maxLogs int) ([]FlatLog, bool, *client.ToolResult) {
+	var collected []FlatLog
+	cursor := ""
+
+	for len(collected) < maxLogs {
+		limit := queryPageSize
+		if remaining := maxLogs - len(collected); remaining < limit {
+			limit = remaining
+		}
+		req.Pagination = Pagination{Limit: limit, Cursor: cursor}
+
+		result := c.PostWithDataset(ctx, basePath, req, dataset)
+		if !result.Success {
+			return nil, false, result
+		}
+
+		page := flattenLogsResponse(result.Data)
+		collected = append(collected, page...)
+
+		cursor = otlp.NextCursor(result.Data)
+		if cursor == "" || len(page) == 0 {
+			return collected, false, nil
+		}
+	}
+
+	if len(collected) > maxLogs {
+		collected = collected[:maxLogs]
+	}
+	return collected, cursor != "", nil
+}
+
+// buildSeverityTrend counts logs per severity in buckets of the given width
+// covering from..to. Buckets are aligned to multiples of the width so that
+// repeated calls line up.
+func buildSeverityTrend(logs []FlatLog, from, to time.Time, bucket time.Duration) SeverityTrendResult {
+	start := from.Truncate(bucket)
+	n := int((to.Sub(start) + bucket - 1) / bucket)
+	if n < 1 {
+		n = 1
+	}
+
+	trend := SeverityTrendResult{
+		From:          from.Format(time.RFC3339),
+		To:            to.Format(time.RFC3339),
+		BucketMinutes: int(bucket / time.Minute),
+		Buckets:       make([]SeverityBucket, n),
+		Totals:        make(map[string]int),
+		Logs:          len(logs),
+	}
+	for i := range trend.Buckets {
+		trend.Buckets[i] = SeverityBucket{
+			Start:  start.Add(time.Duration(i) * bucket).Format(time.RFC3339),
+			Counts: make(map[string]int),
+		}
+	}
+
+	for _, log := range logs {
+		t, err := time.Parse(time.RFC3339Nano, log.Timestamp)
+		if err != nil {
+			continue
+		}
+		i := int(t.Sub(start) / bucket)
+		if i < 0 {
+			i = 0
+		}
+		if i >= n {
+			i = n - 1
+		}
+		level := severityLevel(log)
+		trend.Buckets[i].Counts[level]++
+		trend.Buckets[i].Total++
+		trend.Totals[level]++
+	}
+	return trend
+}
+
+// compareSeverities compares the per-minute rate of each severity before
+// and after splitAt.
+func compareSeverities(logs []FlatLog, from, splitAt, to time.Time) []SeverityChange {
+	before := make(map[string]int)
+	after := make(map[string]int)
+	for _, log := range logs {
+		t, err := time.Parse(time.RFC3339Nano, log.Timestamp)
+		if err != nil {
+			continue
+		}
+		if t.Before(splitAt) {
+			before[severityLevel(log)]++
+		} else {
+			after[severityLevel(log)]++
+		}
+	}
+
+	beforeMinutes := splitAt.Sub(from).Minutes()
+	afterMinutes := to.Sub(splitAt).Minutes()
+
+	var changes []SeverityChange
+	for _, level := range severityLevels {
+		if before[level] == 0 && after[level] == 0 {
+			continue
+		}
+		c := SeverityChange{
+			Severity:        level,
+			BeforePerMinute: float64(before[level]) / beforeMinutes,
+			AfterPerMinute:  float64(after[level]) / afterMinutes,
+		}
+		if c.BeforePerMinute > 0 {
+			pct := (c.AfterPerMinute - c.BeforePerMinute) / c.BeforePerMinute * 100
+			c.ChangePct = &pct
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// formatSeverityTrend renders a severity trend as a sparkline per severity
+// and a table with one row per bucket.
+func formatSeverityTrend(trend SeverityTrendResult, filterDescs []string) string {
+	from, _ := time.Parse(time.RFC3339, trend.From)
+	to, _ := time.Parse(time.RFC3339, trend.To)
+
+	summaryParts := []string{fmt.Sprintf("**%d logs**", trend.Logs)}
+	summaryParts = append(summaryParts, fmt.Sprintf("Time: %s → %s", from.Format("15:04:05"), to.Format("15:04:05 2006-01-02")))
+	summaryParts = append(summaryParts, fmt.Sprintf("Buckets: %dm", trend.BucketMinutes))
+	if len(filterDescs) > 0 {
+		summaryParts = append(summaryParts, "Filters: "+strings.Join(filterDescs, ", "))
+	}
+	summary := strings.Join(summaryParts, " | ")
+
+	// Only severities that occur get a column.
+	var levels []string
+	for _, level := range severityLevels {
+		if trend.Totals[level] > 0 {
+			levels = append(levels, level)
+		}
+	}
+
+	var lines []string
+	for _, level := range levels {
+		counts := make([]int, len(trend.Buckets))
+		for i, b := range trend.Buckets {
+			counts[i] = b.Counts[level]
+		}
+		lines = append(lines, fmt.Sprintf("- %s `%s` %d", level, formatter.Sparkline(counts), trend.Totals[level]))
+	}
+	if len(lines) > 0 {
+		summary += "\n\n" + strings.Join(lines, "\n")
+	}
+
+	if trend.SplitAt != "" {
+		splitAt, _ := time.Parse(time.RFC3339, trend.SplitAt)
+		var parts []string
+		for _, c := range trend.Changes {
+			change := "new"
+			if c.ChangePct != nil {
+				change = fmt.Sprintf("%+.0f%%", *c.ChangePct)
+			}
+			parts = append(parts, fmt.Sprintf("%s %.2f/min → %.2f/min (%s)", c.Severity, c.BeforePerMinute, c.AfterPerMinute, change))
+		}
+		if len(parts) == 0 {
+			parts = append(parts, "no logs")
+		}
+		summary += fmt.Sprintf("\n\n> **Before vs after %s:** %s", splitAt.Format("15:04:05"), strings.Join(parts, " | "))
+	}
+
+	if b := trend.Baseline; b != nil {
+		label := "the previous period"
+		if b.Kind == baselineLastWeek {
+			label = "the same time last week"
+		}
+		var parts []string
+		for _, level := range severityLevels {
+			cur, base := trend.Totals[level], b.Totals[level]
+			if cur == 0 && base == 0 {
+				continue
+			}
+			change := "new"
+			if pct, ok := b.ChangePct[level]; ok {
+				change = fmt.Sprintf("%+.0f%%", pct)
+			}
+			parts = append(parts, fmt.Sprintf("%s %d → %d (%s)", level, base, cur, change))
+		}
+		if len(parts) == 0 {
+			parts = append(parts, "no logs in either window")
+		}
+		summary += fmt.Sprintf("\n\n> **vs %s:** %s", label, strings.Join(parts, " | "))
+		if b.Sampled {
+			summary += " _(baseline counts cover a sample)_"
+		}
+	}
+
+	// Show the date in bucket labels when the window crosses midnight.
+	layout := "15:04"
+	if from.YearDay() != to.YearDay() {
+		layout = "01-02 15:04"
+	}
+
+	headers := append([]string{"Bucket"}, levels...)
+	headers = append(headers, "Total")
+	var rows [][]string
+	for _, b := range trend.Buckets {
+		label := b.Start
+		if t, err := time.Parse(time.RFC3339, b.Start); err == nil {
+			label = t.Format(layout)
+		}
+		row := []string{label}
+		for _, level := range levels {
+			row = append(row, fmt.Sprintf("%d", b.Counts[level]))
+		}
+		row = append(row, fmt.Sprintf("%d", b.Total))
+		rows = append(rows, row)
+	}
+
+	footer := ""
+	if trend.Sampled {
+		footer = fmt.Sprintf("_More logs matched than were read; counts cover the first %d. Narrow the window or filters, or raise max_logs._", trend.Logs)
+	}
+
+	return formatter.Table("Log Severity Trend", summary, headers, rows, footer)
+}
@@ -0,0 +1,10 @@
+// Package meta provides MCP tools that describe the server's own tools.
+// This package serves curated, runnable example arguments for every tool, so
+// clients can offer one-click samples and agents can copy the exact shape of
+// complex CRD-style bodies instead of guessing it. It also reports the
+// session's upstream API usage and the failures of each endpoint, runs a
+// self-test that exercises a safe subset of the tools against the live
+// organization, checks connectivity and the auth token with a ping, reports
+// the server's version and build, and enables or disables tools for the rest
+// of the session.
+package meta
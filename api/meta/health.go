@@ -0,0 +1,138 @@
+package meta
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+	"github.com/npcomplete777/dash0-mcp/internal/tokencheck"
+	"github.com/npcomplete777/dash0-mcp/internal/version"
+	mcp "github.com/mark3labs/mcp-go/mcp"
+)
+
+// PingResult is the result of dash0_ping.
+type PingResult struct {
+	BaseURL   string `json:"base_url"`
+	LatencyMs int64  `json:"latency_ms"`
+	// Dataset is the dataset queries go to.
+	Dataset string `json:"dataset"`
+}
+
+// Ping returns the dash0_ping tool definition.
+func (p *Tools) Ping() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_ping",
+		Description: `Check that the Dash0 API is reachable and accepts the configured auth token, before
+running real workflows. Makes one cheap authenticated request, bypassing the cache, and
+returns the API base URL, the round-trip latency, and the dataset queries go to.
+
+A rejected token fails with auth_error, an unreachable API (wrong region or base URL,
+network, TLS) with upstream_error; the error names the base URL that was tried.`,
+		InputSchema: mcp.ToolInputSchema{
+			Type:       "object",
+			Properties: map[string]interface{}{},
+		},
+	}
+}
+
+// PingHandler handles the dash0_ping tool.
+func (p *Tools) PingHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	baseURL := p.client.BaseURL()
+	start := time.Now()
+	result := p.client.Get(client.WithoutCache(ctx), tokencheck.ValidatePath)
+	latency := time.Since(start)
+	if !result.Success {
+		if result.Error != nil {
+			result.Error.Detail = fmt.Sprintf("ping of %s failed after %s: %s", baseURL, latency.Round(time.Millisecond), result.Error.Detail)
+		}
+		return result
+	}
+
+	res := PingResult{
+		BaseURL:   baseURL,
+		LatencyMs: latency.Milliseconds(),
+		Dataset:   p.client.Dataset(ctx),
+	}
+	if res.Dataset == "" {
+		res.Dataset = "default"
+	}
+
+	rows := [][]string{
+		{"Base URL", res.BaseURL},
+		{"Latency", latency.Round(time.Millisecond).String()},
+		{"Auth token", "accepted"},
+		{"Dataset", res.Dataset},
+	}
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: formatter.Table("Dash0 Ping", "The API is reachable and accepts the token.", []string{"Check", "Result"}, rows, ""),
+		Data:     res,
+	}
+}
+
+// ServerVersionResult is the result of dash0_server_version.
+type ServerVersionResult struct {
+	version.Info
+	MCPProtocolVersion string `json:"mcp_protocol_version"`
+	BaseURL            string `json:"base_url"`
+	ToolsRegistered    int    `json:"tools_registered"`
+	ToolsEnabled       int    `json:"tools_enabled"`
+}
+
+// ServerVersion returns the dash0_server_version tool definition.
+func (p *Tools) ServerVersion() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_server_version",
+		Description: `Report the version of this MCP server and the build it runs: server version, Go module
+version, VCS revision and time, Go version, and platform, with the MCP protocol version,
+the configured API base URL, and how many tools are registered and enabled. Makes no API
+request; include it in bug reports.`,
+		InputSchema: mcp.ToolInputSchema{
+			Type:       "object",
+			Properties: map[string]interface{}{},
+		},
+	}
+}
+
+// ServerVersionHandler handles the dash0_server_version tool.
+func (p *Tools) ServerVersionHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	res := ServerVersionResult{
+		Info:               version.Get(),
+		MCPProtocolVersion: mcp.LATEST_PROTOCOL_VERSION,
+		BaseURL:            p.client.BaseURL(),
+		ToolsRegistered:    p.reg.ToolCount(),
+		ToolsEnabled:       p.reg.EnabledCount(),
+	}
+
+	revision := res.Revision
+	if revision == "" {
+		revision = "unknown"
+	} else if res.Modified {
+		revision += " (modified)"
+	}
+	rows := [][]string{
+		{"Server version", res.Version},
+		{"Module version", orUnknown(res.Module)},
+		{"Revision", revision},
+		{"Revision time", orUnknown(res.RevisionTime)},
+		{"Go version", res.GoVersion},
+		{"Platform", res.Platform},
+		{"MCP protocol", res.MCPProtocolVersion},
+		{"API base URL", res.BaseURL},
+		{"Tools", fmt.Sprintf("%d enabled of %d registered", res.ToolsEnabled, res.ToolsRegistered)},
+	}
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: formatter.Table("Dash0 MCP Server Version", "", []string{"Property", "Value"}, rows, ""),
+		Data:     res,
+	}
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
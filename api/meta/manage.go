@@ -0,0 +1,250 @@
+package meta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+	"github.com/npcomplete777/dash0-mcp/internal/registry"
+	mcp "github.com/mark3labs/mcp-go/mcp"
+)
+
+// toolInfo is a tool in the result of dash0_tools_list_enabled.
+type toolInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ToolsListEnabled returns the dash0_tools_list_enabled tool definition.
+func (p *Tools) ToolsListEnabled() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_tools_list_enabled",
+		Description: `List the tools enabled in this session, with the summary of each description.
+
+With include_disabled, the registered tools that the profile or dash0_tools_disable turned
+off are listed too. Those turned off with dash0_tools_disable can be turned on again with
+dash0_tools_enable; those the tools config or profile disables cannot.
+
+Example: {"include_disabled": true}`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"include_disabled": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Also list the disabled tools (default: false)",
+				},
+			},
+		},
+	}
+}
+
+// ToolsEnable returns the dash0_tools_enable tool definition.
+func (p *Tools) ToolsEnable() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_tools_enable",
+		Description: `Enable tools again that dash0_tools_disable turned off earlier in this session.
+
+Only tools the operator's tools.yaml and profile enable can be turned on; tools they
+disable, such as the delete tools of the full profile, stay off until the operator changes
+the config. Clients are sent notifications/tools/list_changed. The change lasts until the server
+restarts or tools.yaml or the profile changes. Writes stay blocked in read-only mode
+(DASH0_MCP_READ_ONLY or a read_only profile) whichever tools are enabled.
+
+Example: {"tools": ["dash0_views_create", "dash0_views_update"]}`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"tools": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Names of the tools to enable",
+				},
+			},
+			Required: []string{"tools"},
+		},
+	}
+}
+
+// ToolsDisable returns the dash0_tools_disable tool definition.
+func (p *Tools) ToolsDisable() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_tools_disable",
+		Description: `Disable tools for the rest of this session, e.g. to tighten the tool surface before
+handing the session to an agent.
+
+Clients are sent notifications/tools/list_changed, and calls of a disabled tool fail.
+The change lasts until the server restarts or tools.yaml or the profile changes.
+Disabling dash0_tools_enable itself locks the tool surface until then.
+
+Example: {"tools": ["dash0_dashboards_delete", "dash0_views_delete"]}`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"tools": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Names of the tools to disable",
+				},
+			},
+			Required: []string{"tools"},
+		},
+	}
+}
+
+// ToolsListEnabledHandler handles the dash0_tools_list_enabled tool.
+func (p *Tools) ToolsListEnabledHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	includeDisabled, _ := args["include_disabled"].(bool)
+
+	enabled := []toolInfo{}
+	disabled := []toolInfo{}
+	var rows [][]string
+	for _, name := range p.reg.AllToolNames() {
+		tool, _ := p.reg.Tool(name)
+		info := toolInfo{Name: name, Description: firstParagraph(tool.Description)}
+		if p.reg.IsEnabled(name) {
+			enabled = append(enabled, info)
+			rows = append(rows, []string{name, "yes", formatter.Truncate(info.Description, 80)})
+		} else if includeDisabled {
+			disabled = append(disabled, info)
+			rows = append(rows, []string{name, "no", formatter.Truncate(info.Description, 80)})
+		}
+	}
+
+	headers := []string{"Tool", "Enabled", "Description"}
+	if !includeDisabled {
+		headers = []string{"Tool", "Description"}
+		for i, row := range rows {
+			rows[i] = []string{row[0], row[2]}
+		}
+	}
+	summary := fmt.Sprintf("**%d of %d tools** are enabled", len(enabled), p.reg.ToolCount())
+	data := map[string]interface{}{"enabled": enabled, "count": len(enabled), "total": p.reg.ToolCount()}
+	if includeDisabled {
+		data["disabled"] = disabled
+	}
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: formatter.Table("Enabled Tools", summary, headers, rows, ""),
+		Data:     data,
+	}
+}
+
+// ToolsEnableHandler handles the dash0_tools_enable tool.
+func (p *Tools) ToolsEnableHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	return p.toggleTools(ctx, args, true)
+}
+
+// ToolsDisableHandler handles the dash0_tools_disable tool.
+func (p *Tools) ToolsDisableHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	return p.toggleTools(ctx, args, false)
+}
+
+// toggleTools enables or disables the tools named by the tools argument. In
+// a dry run, it reports the change without making it.
+func (p *Tools) toggleTools(ctx context.Context, args map[string]interface{}, enable bool) *client.ToolResult {
+	names, err := stringList(args, "tools")
+	if err != nil {
+		return client.ErrorResult(http.StatusBadRequest, err.Error())
+	}
+	if len(names) == 0 {
+		return client.ErrorResult(http.StatusBadRequest, "tools is required")
+	}
+	verb := "disabled"
+	if enable {
+		verb = "enabled"
+	}
+
+	var unknown, denied, changed []string
+	for _, name := range names {
+		if _, ok := p.reg.Tool(name); !ok {
+			unknown = append(unknown, name)
+		} else if enable && !p.reg.IsAllowed(name) {
+			denied = append(denied, name)
+		} else if p.reg.IsEnabled(name) != enable {
+			changed = append(changed, name)
+		}
+	}
+	if len(unknown) > 0 {
+		result := client.ErrorResult(http.StatusNotFound, fmt.Sprintf("unknown tools: %s", strings.Join(unknown, ", ")))
+		result.Error.Hint = "call dash0_tools_list_enabled with include_disabled for the tool names"
+		return result
+	}
+	if len(denied) > 0 {
+		result := client.ErrorResult(http.StatusForbidden, fmt.Sprintf("%s: %s", registry.ErrNotAllowed, strings.Join(denied, ", ")))
+		result.Error.Hint = "only tools turned off with dash0_tools_disable can be enabled again; ask the operator to enable the others in tools.yaml or the profile"
+		return result
+	}
+
+	dryRun := p.client.DryRun(ctx)
+	if !dryRun {
+		if enable {
+			changed, err = p.reg.Enable(names...)
+		} else {
+			changed, err = p.reg.Disable(names...)
+		}
+		if errors.Is(err, registry.ErrNotAllowed) {
+			return client.ErrorResult(http.StatusForbidden, err.Error())
+		}
+		if err != nil {
+			return client.ErrorResult(http.StatusNotFound, err.Error())
+		}
+	}
+
+	var md strings.Builder
+	switch {
+	case len(changed) == 0:
+		fmt.Fprintf(&md, "All %d tools were already %s; nothing changed.\n", len(names), verb)
+	case dryRun:
+		fmt.Fprintf(&md, "**Dry run**: would have %s %d tools: %s\n", verb, len(changed), strings.Join(changed, ", "))
+	default:
+		fmt.Fprintf(&md, "%s %d tools: %s\n", strings.ToUpper(verb[:1])+verb[1:], len(changed), strings.Join(changed, ", "))
+	}
+	fmt.Fprintf(&md, "\n%d of %d tools are enabled.\n", p.reg.EnabledCount(), p.reg.ToolCount())
+
+	if changed == nil {
+		changed = []string{}
+	}
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: md.String(),
+		Data: map[string]interface{}{
+			verb:            changed,
+			"dry_run":       dryRun,
+			"tools_enabled": p.reg.EnabledCount(),
+		},
+	}
+}
+
+// firstParagraph returns the first paragraph of a tool description on one
+// line.
+func firstParagraph(s string) string {
+	paragraph, _, _ := strings.Cut(strings.TrimSpace(s), "\n\n")
+	return strings.Join(strings.Fields(paragraph), " ")
+}
+
+// stringList returns the non-empty strings of a string array argument.
+func stringList(args map[string]interface{}, name string) ([]string, error) {
+	raw, ok := args[name]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be an array of strings", name)
+	}
+	var values []string
+	for _, v := range list {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be an array of strings", name)
+		}
+		if s = strings.TrimSpace(s); s != "" {
+			values = append(values, s)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,317 @@
+package meta
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+	"github.com/npcomplete777/dash0-mcp/internal/otlp"
+	mcp "github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// selftestPrefix names the objects and telemetry the self-test creates.
+	selftestPrefix = "dash0-mcp-selftest"
+	// defaultSelftestVerifySeconds is how long the self-test waits for its
+	// log record to become queryable.
+	defaultSelftestVerifySeconds = 30
+	maxSelftestVerifySeconds     = 120
+)
+
+// Outcomes of a self-test capability.
+const (
+	selftestPass = "pass"
+	selftestFail = "fail"
+	selftestSkip = "skip"
+)
+
+// CapabilityResult is the outcome of one self-test step.
+type CapabilityResult struct {
+	Capability string `json:"capability"`
+	Tool       string `json:"tool"`
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+	Detail     string `json:"detail,omitempty"`
+}
+
+// SelftestResult is the result of dash0_selftest.
+type SelftestResult struct {
+	Passed       int                `json:"passed"`
+	Failed       int                `json:"failed"`
+	Skipped      int                `json:"skipped"`
+	Capabilities []CapabilityResult `json:"capabilities"`
+}
+
+// Selftest returns the dash0_selftest tool definition.
+func (p *Tools) Selftest() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_selftest",
+		Description: `Check that this server works against the configured Dash0 organization, e.g. after
+installing or upgrading it. Runs a safe sequence of real tool calls and reports
+pass, fail, or skip for each capability:
+
+1. API access: list views
+2. Query spans of the last 5 minutes
+3. Create a temporary view named dash0-mcp-selftest-<timestamp>, get it, and delete it
+4. Send a test log record (service dash0-mcp-selftest) and query it back
+
+Steps whose tool is not enabled in the current profile are skipped. With
+skip_writes or dry_run, only the read steps run; nothing is created or sent.`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"skip_writes": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Only run the read-only steps (default: false)",
+				},
+				"verify_timeout_seconds": map[string]interface{}{
+					"type":        "integer",
+					"description": "How long to wait for the test log record to become queryable (default: 30, max: 120)",
+				},
+			},
+		},
+	}
+}
+
+// SelftestHandler handles the dash0_selftest tool.
+func (p *Tools) SelftestHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	skipWrites, _ := args["skip_writes"].(bool)
+	verifySeconds := defaultSelftestVerifySeconds
+	if v, ok := args["verify_timeout_seconds"].(float64); ok {
+		if v < 0 {
+			return client.ErrorResult(400, "verify_timeout_seconds must not be negative")
+		}
+		if v > 0 {
+			verifySeconds = int(v)
+			if verifySeconds > maxSelftestVerifySeconds {
+				verifySeconds = maxSelftestVerifySeconds
+			}
+		}
+	}
+
+	t := &selftest{tools: p}
+	t.run(ctx, "API access", "dash0_views_list", map[string]interface{}{})
+	t.run(ctx, "Query spans", "dash0_spans_query", map[string]interface{}{"time_range_minutes": float64(5), "limit": float64(1)})
+
+	now := time.Now().UTC()
+	marker := fmt.Sprintf("%s-%s", selftestPrefix, now.Format("20060102-150405"))
+	writes := []struct{ capability, tool string }{
+		{"Create a view", "dash0_views_create"},
+		{"Get the view", "dash0_views_get"},
+		{"Delete the view", "dash0_views_delete"},
+		{"Send a log record", "dash0_logs_send"},
+		{"Query the log record back", "dash0_logs_send"},
+	}
+	if skipWrites || p.client.DryRun(ctx) {
+		reason := "skip_writes is set"
+		if !skipWrites {
+			reason = "dry run"
+		}
+		for _, w := range writes {
+			t.skip(w.capability, w.tool, reason)
+		}
+		return t.result()
+	}
+
+	created := t.run(ctx, "Create a view", "dash0_views_create", map[string]interface{}{
+		"body": map[string]interface{}{
+			"kind":     "Dash0View",
+			"metadata": map[string]interface{}{"name": marker},
+			"spec":     map[string]interface{}{"type": "resources"},
+		},
+	})
+	id := ""
+	if created != nil {
+		if id = createdID(created.Data); id == "" {
+			t.fail("the response has no view ID")
+		}
+	}
+	if id == "" {
+		t.skip("Get the view", "dash0_views_get", "no view was created")
+		t.skip("Delete the view", "dash0_views_delete", "no view was created")
+	} else {
+		t.run(ctx, "Get the view", "dash0_views_get", map[string]interface{}{"origin_or_id": id})
+		t.run(ctx, "Delete the view", "dash0_views_delete", map[string]interface{}{"origin_or_id": id})
+	}
+
+	start := time.Now()
+	sent := t.run(ctx, "Send a log record", "dash0_logs_send", map[string]interface{}{
+		"body":                   selftestLogs(marker, now),
+		"verify":                 true,
+		"verify_timeout_seconds": float64(verifySeconds),
+	})
+	if sent == nil {
+		t.skip("Query the log record back", "dash0_logs_send", "no log record was sent")
+		return t.result()
+	}
+	data, _ := sent.Data.(map[string]interface{})
+	verification, _ := data["verification"].(otlp.IngestVerification)
+	query := CapabilityResult{
+		Capability: "Query the log record back",
+		Tool:       "dash0_logs_send",
+		Status:     selftestPass,
+		DurationMs: verification.ElapsedMs,
+		Detail:     fmt.Sprintf("queryable after %d attempts", verification.Attempts),
+	}
+	if !verification.Verified {
+		query.Status = selftestFail
+		query.Detail = fmt.Sprintf("not queryable after %s", time.Since(start).Round(time.Second))
+		if verification.Error != "" {
+			query.Detail += ": " + verification.Error
+		}
+	}
+	t.add(query)
+	return t.result()
+}
+
+// selftest runs tool calls through the registry and collects their outcome.
+type selftest struct {
+	tools   *Tools
+	results []CapabilityResult
+}
+
+// run calls a tool and records the outcome. It returns the tool result when
+// the call succeeded and nil when it failed or was skipped.
+func (t *selftest) run(ctx context.Context, capability, tool string, args map[string]interface{}) *client.ToolResult {
+	if t.tools.reg.GetHandler(tool) == nil || !t.tools.reg.IsEnabled(tool) {
+		t.skip(capability, tool, "tool not enabled in the current profile")
+		return nil
+	}
+	start := time.Now()
+	result := t.tools.reg.Call(ctx, tool, args)
+	// With DASH0_MCP_CONFIRM_DELETES, the self-test confirms deleting its
+	// own objects right away.
+	if confirmation, ok := result.Data.(client.DeleteConfirmation); ok && result.Success {
+		args["confirm_token"] = confirmation.ConfirmToken
+		result = t.tools.reg.Call(ctx, tool, args)
+	}
+	r := CapabilityResult{
+		Capability: capability,
+		Tool:       tool,
+		Status:     selftestPass,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if !result.Success {
+		r.Status = selftestFail
+		if result.Error != nil {
+			r.Detail = result.Error.Message()
+		}
+		t.add(r)
+		return nil
+	}
+	t.add(r)
+	return result
+}
+
+// skip records a step that did not run.
+func (t *selftest) skip(capability, tool, reason string) {
+	t.add(CapabilityResult{Capability: capability, Tool: tool, Status: selftestSkip, Detail: reason})
+}
+
+// fail marks the last step as failed, for calls that succeeded but returned
+// something unusable.
+func (t *selftest) fail(detail string) {
+	last := &t.results[len(t.results)-1]
+	last.Status, last.Detail = selftestFail, detail
+}
+
+func (t *selftest) add(r CapabilityResult) {
+	t.results = append(t.results, r)
+}
+
+// result summarizes the steps as a tool result.
+func (t *selftest) result() *client.ToolResult {
+	res := SelftestResult{Capabilities: t.results}
+	var rows [][]string
+	for _, r := range t.results {
+		switch r.Status {
+		case selftestPass:
+			res.Passed++
+		case selftestFail:
+			res.Failed++
+		default:
+			res.Skipped++
+		}
+		duration := "-"
+		if r.Status != selftestSkip {
+			duration = strconv.FormatInt(r.DurationMs, 10) + "ms"
+		}
+		rows = append(rows, []string{r.Capability, "`" + r.Tool + "`", statusLabel(r.Status), duration, r.Detail})
+	}
+
+	summary := fmt.Sprintf("**%d passed**, %d failed, %d skipped", res.Passed, res.Failed, res.Skipped)
+	footer := ""
+	if res.Failed > 0 {
+		footer = "_Check DASH0_AUTH_TOKEN permissions, DASH0_REGION or DASH0_BASE_URL, and DASH0_DATASET for the failed steps._"
+	}
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: formatter.Table("Self-Test", summary, []string{"Capability", "Tool", "Status", "Duration", "Detail"}, rows, footer),
+		Data:     res,
+	}
+}
+
+// statusLabel renders a capability status.
+func statusLabel(status string) string {
+	switch status {
+	case selftestPass:
+		return "✅ pass"
+	case selftestFail:
+		return "❌ fail"
+	}
+	return "⏭ skip"
+}
+
+// createdID returns the origin or ID of a created object.
+func createdID(data interface{}) string {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if meta, ok := m["metadata"].(map[string]interface{}); ok {
+		if labels, ok := meta["labels"].(map[string]interface{}); ok {
+			for _, key := range []string{"dash0.com/origin", "dash0.com/id"} {
+				if s, ok := labels[key].(string); ok && s != "" {
+					return s
+				}
+			}
+		}
+	}
+	for _, key := range []string{"origin", "id"} {
+		if s, ok := m[key].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// selftestLogs returns an OTLP JSON payload with one log record whose body is
+// marker.
+func selftestLogs(marker string, t time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"resourceLogs": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": []interface{}{
+						map[string]interface{}{"key": "service.name", "value": map[string]interface{}{"stringValue": selftestPrefix}},
+					},
+				},
+				"scopeLogs": []interface{}{
+					map[string]interface{}{
+						"logRecords": []interface{}{
+							map[string]interface{}{
+								"timeUnixNano":   strconv.FormatInt(t.UnixNano(), 10),
+								"severityNumber": 9,
+								"severityText":   "INFO",
+								"body":           map[string]interface{}{"stringValue": marker},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
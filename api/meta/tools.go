@@ -0,0 +1,375 @@
+package meta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+	"github.com/npcomplete777/dash0-mcp/internal/registry"
+	"github.com/npcomplete777/dash0-mcp/internal/selftel"
+	mcp "github.com/mark3labs/mcp-go/mcp"
+)
+
+// Compile-time interface checks.
+var (
+	_ registry.ToolProvider    = (*Tools)(nil)
+	_ registry.ExampleProvider = (*Tools)(nil)
+)
+
+// Tools provides MCP tools that describe and toggle the registered tools,
+// report the session's API usage, and self-test the tools against the live
+// organization.
+type Tools struct {
+	reg    *registry.Registry
+	client *client.Client
+}
+
+// New creates a new Meta tools instance for the tools in reg and the API
+// usage of c.
+func New(reg *registry.Registry, c *client.Client) *Tools {
+	return &Tools{reg: reg, client: c}
+}
+
+// Tools returns all MCP tools in this package.
+func (p *Tools) Tools() []mcp.Tool {
+	return []mcp.Tool{
+		p.ListExamples(),
+		p.SessionUsage(),
+		p.ServerStats(),
+		p.Selftest(),
+		p.Ping(),
+		p.ServerVersion(),
+		p.ToolsListEnabled(),
+		p.ToolsEnable(),
+		p.ToolsDisable(),
+	}
+}
+
+// Handlers returns a map of tool name to handler function.
+func (p *Tools) Handlers() map[string]func(context.Context, map[string]interface{}) *client.ToolResult {
+	return map[string]func(context.Context, map[string]interface{}) *client.ToolResult{
+		"dash0_examples":           p.ListExamplesHandler,
+		"dash0_session_usage":      p.SessionUsageHandler,
+		"dash0_server_stats":       p.ServerStatsHandler,
+		"dash0_selftest":           p.SelftestHandler,
+		"dash0_ping":               p.PingHandler,
+		"dash0_server_version":     p.ServerVersionHandler,
+		"dash0_tools_list_enabled": p.ToolsListEnabledHandler,
+		"dash0_tools_enable":       p.ToolsEnableHandler,
+		"dash0_tools_disable":      p.ToolsDisableHandler,
+	}
+}
+
+// Examples returns example invocations for the meta tools.
+func (p *Tools) Examples() map[string][]registry.Example {
+	return map[string][]registry.Example{
+		"dash0_examples": {
+			{Title: "Which tools have examples", Arguments: map[string]interface{}{}},
+			{Title: "Examples for creating a dashboard", Arguments: map[string]interface{}{"tool": "dash0_dashboards_create"}},
+		},
+		"dash0_session_usage": {
+			{Title: "API usage of this session", Arguments: map[string]interface{}{}},
+		},
+		"dash0_server_stats": {
+			{Title: "Which endpoints are failing", Arguments: map[string]interface{}{}},
+		},
+		"dash0_selftest": {
+			{Title: "Check read access only", Arguments: map[string]interface{}{"skip_writes": true}},
+			{Title: "Full self-test after an upgrade", Arguments: map[string]interface{}{"verify_timeout_seconds": 60}},
+		},
+		"dash0_ping": {
+			{Title: "Check connectivity and the auth token", Arguments: map[string]interface{}{}},
+		},
+		"dash0_server_version": {
+			{Title: "Server version for a bug report", Arguments: map[string]interface{}{}},
+		},
+		"dash0_tools_list_enabled": {
+			{Title: "Enabled tools", Arguments: map[string]interface{}{}},
+			{Title: "Every registered tool", Arguments: map[string]interface{}{"include_disabled": true}},
+		},
+		"dash0_tools_enable": {
+			{Title: "Allow editing views", Arguments: map[string]interface{}{"tools": []interface{}{"dash0_views_create", "dash0_views_update"}}},
+		},
+		"dash0_tools_disable": {
+			{Title: "Turn off deletes", Arguments: map[string]interface{}{"tools": []interface{}{"dash0_dashboards_delete", "dash0_views_delete"}}},
+		},
+	}
+}
+
+// ListExamples returns the dash0_examples tool definition.
+func (p *Tools) ListExamples() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_examples",
+		Description: `Get curated, runnable example arguments for a Dash0 tool.
+
+Each example has a title and an arguments object that can be passed to the tool as-is
+(replace names, origins, and services with your own). Check the examples before calling
+tools that take CRD-shaped bodies, such as dash0_dashboards_create,
+dash0_synthetic_checks_create, or dash0_sampling_rules_create.
+
+Without a tool name, lists the enabled tools that have examples.
+
+Example: {"tool": "dash0_synthetic_checks_create"}`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"tool": map[string]interface{}{
+					"type":        "string",
+					"description": "Tool name, e.g. 'dash0_dashboards_create'. If omitted, lists the tools with examples.",
+				},
+			},
+		},
+	}
+}
+
+// ListExamplesHandler handles the dash0_examples tool.
+func (p *Tools) ListExamplesHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	name, _ := args["tool"].(string)
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return p.listTools()
+	}
+
+	if p.reg.GetHandler(name) == nil || !p.reg.IsEnabled(name) {
+		return client.ErrorResult(404, fmt.Sprintf("tool %s not found; call dash0_examples without arguments to list the tools", name))
+	}
+	examples := p.reg.Examples(name)
+	if len(examples) == 0 {
+		return client.ErrorResult(404, fmt.Sprintf("tool %s has no examples", name))
+	}
+
+	var md strings.Builder
+	fmt.Fprintf(&md, "## Examples: %s\n", name)
+	for _, ex := range examples {
+		raw, err := json.MarshalIndent(ex.Arguments, "", "  ")
+		if err != nil {
+			return client.ErrorResult(500, fmt.Sprintf("failed to encode example: %v", err))
+		}
+		fmt.Fprintf(&md, "\n### %s\n\n```json\n%s\n```\n", ex.Title, raw)
+	}
+
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: md.String(),
+		Data: map[string]interface{}{
+			"tool":     name,
+			"examples": examples,
+		},
+	}
+}
+
+// listTools lists the enabled tools that have examples.
+func (p *Tools) listTools() *client.ToolResult {
+	var rows [][]string
+	counts := map[string]int{}
+	for _, name := range p.reg.EnabledToolNames() {
+		examples := p.reg.Examples(name)
+		if len(examples) == 0 {
+			continue
+		}
+		titles := make([]string, len(examples))
+		for i, ex := range examples {
+			titles[i] = ex.Title
+		}
+		counts[name] = len(examples)
+		rows = append(rows, []string{name, strings.Join(titles, "; ")})
+	}
+
+	summary := fmt.Sprintf("**%d tools** have examples", len(rows))
+	footer := "_Get the arguments with: {\"tool\": \"<tool name>\"}_"
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: formatter.Table("Tool Examples", summary, []string{"Tool", "Examples"}, rows, footer),
+		Data:     map[string]interface{}{"tools": counts},
+	}
+}
+
+// SessionUsage returns the dash0_session_usage tool definition.
+func (p *Tools) SessionUsage() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_session_usage",
+		Description: `Report the Dash0 API usage of this session: requests sent, errors, bytes
+transferred, response cache hit rate, hedged requests, and time spent waiting for the
+client-side rate limiter, plus the call budget when DASH0_CALL_BUDGET is set. Also
+lists calls, errors, average latency, and result size per tool.
+
+Use it to understand the cost and latency profile of a workflow and to tune
+DASH0_CACHE_TTL, DASH0_RATE_LIMIT, and DASH0_MAX_CONCURRENCY.`,
+		InputSchema: mcp.ToolInputSchema{
+			Type:       "object",
+			Properties: map[string]interface{}{},
+		},
+	}
+}
+
+// SessionUsageHandler handles the dash0_session_usage tool.
+func (p *Tools) SessionUsageHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	u := p.client.Usage()
+
+	hitRate := "n/a"
+	if r := u.CacheHitRate(); r >= 0 {
+		hitRate = fmt.Sprintf("%.1f%% (%d hits, %d misses)", r*100, u.Cache.Hits, u.Cache.Misses)
+	}
+	budget := "none"
+	if u.Budget > 0 {
+		budget = fmt.Sprintf("%d of %d calls used", u.Calls, u.Budget)
+		if u.OverBudget() {
+			budget += " (over budget)"
+		}
+	}
+	rows := [][]string{
+		{"API calls", fmt.Sprintf("%d", u.Calls)},
+		{"Errors", fmt.Sprintf("%d", u.Errors)},
+		{"Bytes sent", formatBytes(u.BytesSent)},
+		{"Bytes received", formatBytes(u.BytesReceived)},
+		{"Cache hit rate", hitRate},
+		{"Hedged requests", fmt.Sprintf("%d (%d won)", u.Hedge.Hedged, u.Hedge.HedgeWins)},
+		{"Rate-limit waits", fmt.Sprintf("%d (%s total)", u.RateLimitWaits, u.RateLimitWait.Round(time.Millisecond))},
+		{"Call budget", budget},
+	}
+
+	summary := fmt.Sprintf("Since %s (%s)", u.Since.UTC().Format(time.RFC3339), time.Since(u.Since).Round(time.Second))
+	markdown := formatter.Table("Session Usage", summary, []string{"Metric", "Value"}, rows, "")
+
+	if len(u.Tools) > 0 {
+		var toolRows [][]string
+		for _, s := range u.Tools {
+			toolRows = append(toolRows, []string{
+				s.Tool,
+				fmt.Sprintf("%d", s.Calls),
+				fmt.Sprintf("%d", s.Errors),
+				s.AvgLatency().Round(time.Millisecond).String(),
+				formatBytes(s.ResultBytes),
+			})
+		}
+		markdown += "\n" + formatter.Table("Tool Calls", "", []string{"Tool", "Calls", "Errors", "Avg Latency", "Result Size"}, toolRows, "")
+	}
+
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: markdown,
+		Data:     u,
+	}
+}
+
+// ServerStats returns the dash0_server_stats tool definition.
+func (p *Tools) ServerStats() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_server_stats",
+		Description: `Report how the tools are used in this session and which Dash0 API endpoints
+are causing tool failures.
+
+Each called tool is listed with its calls, errors, and average and maximum latency.
+Failed upstream requests are counted per tool and per endpoint by class: rate limited
+(429), server error (5xx), timeout, network error, and client error (other 4xx).
+A call is flaky when it succeeded although one of its requests failed transiently and
+was retried. Endpoints are listed with the most failures first; object IDs in paths are
+shown as {id}. Enabled tools that were never called are listed last.
+
+Use it when tools fail intermittently, to tell rate limiting and upstream outages
+apart from bad requests, and to tune the enabled-tool profile: tools that are never
+called can be disabled in tools.yaml to shrink tools/list.`,
+		InputSchema: mcp.ToolInputSchema{
+			Type:       "object",
+			Properties: map[string]interface{}{},
+		},
+	}
+}
+
+// ServerStatsHandler handles the dash0_server_stats tool.
+func (p *Tools) ServerStatsHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	rec := p.client.Telemetry()
+	tools := rec.ToolStats()
+	endpoints := rec.EndpointStats()
+
+	failures := func(f selftel.FailureCounts) []string {
+		return []string{
+			fmt.Sprintf("%d", f.RateLimited),
+			fmt.Sprintf("%d", f.ServerError),
+			fmt.Sprintf("%d", f.Timeout),
+			fmt.Sprintf("%d", f.Network),
+			fmt.Sprintf("%d", f.ClientError),
+		}
+	}
+	classes := []string{"429", "5xx", "Timeout", "Network", "4xx"}
+
+	var toolRows [][]string
+	called := map[string]bool{}
+	for _, s := range tools {
+		called[s.Tool] = true
+		row := []string{
+			s.Tool,
+			fmt.Sprintf("%d", s.Calls),
+			fmt.Sprintf("%d", s.Errors),
+			s.AvgLatency().Round(time.Millisecond).String(),
+			s.MaxDuration.Round(time.Millisecond).String(),
+			fmt.Sprintf("%d", s.Flaky),
+		}
+		toolRows = append(toolRows, append(row, failures(s.Upstream)...))
+	}
+	unused := []string{}
+	for _, name := range p.reg.EnabledToolNames() {
+		if !called[name] {
+			unused = append(unused, name)
+		}
+	}
+	var endpointRows [][]string
+	var requests, failed int64
+	for _, s := range endpoints {
+		requests += s.Requests
+		failed += s.Failures.Total()
+		row := []string{s.Endpoint, s.Tool, fmt.Sprintf("%d", s.Requests)}
+		row = append(row, failures(s.Failures)...)
+		endpointRows = append(endpointRows, append(row, fmt.Sprintf("%.1f%%", s.FailureRate()*100)))
+	}
+
+	summary := fmt.Sprintf("%d of %d API requests failed", failed, requests)
+	markdown := formatter.Table("Tool Calls", summary,
+		append([]string{"Tool", "Calls", "Errors", "Avg Latency", "Max Latency", "Flaky"}, classes...), toolRows, "")
+	markdown += "\n" + formatter.Table("Endpoints", "",
+		append(append([]string{"Endpoint", "Tool", "Requests"}, classes...), "Failure Rate"), endpointRows, "")
+	if len(unused) > 0 {
+		markdown += fmt.Sprintf("\n## Unused Tools\n\n%d of %d enabled tools were not called: %s\n",
+			len(unused), len(p.reg.EnabledToolNames()), strings.Join(unused, ", "))
+	}
+
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: markdown,
+		Data: map[string]interface{}{
+			"tools":         tools,
+			"endpoints":     endpoints,
+			"unused_tools":  unused,
+			"dropped_spans": rec.Dropped(),
+		},
+	}
+}
+
+// formatBytes renders a byte count with a binary unit.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// Register registers the meta tools with the registry. Examples are looked up
+// when the tool is called, so the order of registration does not matter.
+func Register(reg *registry.Registry, c *client.Client) {
+	p := New(reg, c)
+	for _, tool := range p.Tools() {
+		handler := p.Handlers()[tool.Name]
+		reg.Register(tool, handler)
+		reg.AddExamples(tool.Name, p.Examples()[tool.Name]...)
+	}
+}
@@ -0,0 +1,511 @@
+package meta
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/api/logs"
+	"github.com/npcomplete777/dash0-mcp/api/spans"
+	"github.com/npcomplete777/dash0-mcp/api/views"
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/config"
+	"github.com/npcomplete777/dash0-mcp/internal/registry"
+	"github.com/npcomplete777/dash0-mcp/internal/selftel"
+	mcp "github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestNew(t *testing.T) {
+	reg := registry.New(nil)
+	c := &client.Client{}
+	pkg := New(reg, c)
+	if pkg == nil {
+		t.Fatal("New() returned nil")
+	}
+	if pkg.reg != reg || pkg.client != c {
+		t.Error("New() did not set registry and client correctly")
+	}
+}
+
+func TestTools(t *testing.T) {
+	pkg := New(registry.New(nil), &client.Client{})
+	tools := pkg.Tools()
+
+	expected := []string{"dash0_examples", "dash0_session_usage", "dash0_server_stats", "dash0_selftest",
+		"dash0_ping", "dash0_server_version", "dash0_tools_list_enabled", "dash0_tools_enable", "dash0_tools_disable"}
+	if len(tools) != len(expected) {
+		t.Fatalf("Tools() returned %d tools, want %d", len(tools), len(expected))
+	}
+	for i, name := range expected {
+		if tools[i].Name != name {
+			t.Errorf("tools[%d].Name = %q, want %q", i, tools[i].Name, name)
+		}
+		if _, ok := pkg.Handlers()[name]; !ok {
+			t.Errorf("Missing handler for: %s", name)
+		}
+	}
+}
+
+// setupRegistry registers a tool with examples, one without, and a disabled
+// tool with examples.
+func setupRegistry() *registry.Registry {
+	reg := registry.New(map[string]bool{"dash0_widgets_create": true, "dash0_widgets_list": true, "dash0_examples": true})
+	noop := func(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+		return &client.ToolResult{Success: true}
+	}
+	reg.Register(mcp.NewTool("dash0_widgets_create"), noop)
+	reg.AddExamples("dash0_widgets_create",
+		registry.Example{Title: "Create a blue widget", Arguments: map[string]interface{}{"body": map[string]interface{}{"color": "blue"}}},
+		registry.Example{Title: "Create a red widget", Arguments: map[string]interface{}{"body": map[string]interface{}{"color": "red"}}},
+	)
+	reg.Register(mcp.NewTool("dash0_widgets_list"), noop)
+	reg.Register(mcp.NewTool("dash0_widgets_delete"), noop)
+	reg.AddExamples("dash0_widgets_delete", registry.Example{Title: "Delete a widget", Arguments: map[string]interface{}{"origin_or_id": "w-1"}})
+	Register(reg, &client.Client{})
+	return reg
+}
+
+func TestListExamplesHandler(t *testing.T) {
+	reg := setupRegistry()
+
+	t.Run("list", func(t *testing.T) {
+		result := reg.Call(context.Background(), "dash0_examples", map[string]interface{}{})
+		if !result.Success {
+			t.Fatalf("expected success, got %+v", result.Error)
+		}
+		counts := result.Data.(map[string]interface{})["tools"].(map[string]int)
+		if counts["dash0_widgets_create"] != 2 || counts["dash0_examples"] != 2 {
+			t.Errorf("counts = %v, want widgets_create and examples listed", counts)
+		}
+		if _, ok := counts["dash0_widgets_list"]; ok {
+			t.Error("tool without examples was listed")
+		}
+		if _, ok := counts["dash0_widgets_delete"]; ok {
+			t.Error("disabled tool was listed")
+		}
+		if !strings.Contains(result.Markdown, "Create a blue widget; Create a red widget") {
+			t.Errorf("markdown missing example titles:\n%s", result.Markdown)
+		}
+	})
+
+	t.Run("one tool", func(t *testing.T) {
+		result := reg.Call(context.Background(), "dash0_examples", map[string]interface{}{"tool": " dash0_widgets_create "})
+		if !result.Success {
+			t.Fatalf("expected success, got %+v", result.Error)
+		}
+		if examples := result.Data.(map[string]interface{})["examples"].([]registry.Example); len(examples) != 2 {
+			t.Errorf("examples = %v, want 2", examples)
+		}
+		for _, s := range []string{"### Create a red widget", "\"color\": \"red\""} {
+			if !strings.Contains(result.Markdown, s) {
+				t.Errorf("markdown missing %q:\n%s", s, result.Markdown)
+			}
+		}
+	})
+
+	tests := []struct {
+		name    string
+		tool    string
+		wantErr string
+	}{
+		{"unknown tool", "dash0_nope", "tool dash0_nope not found"},
+		{"disabled tool", "dash0_widgets_delete", "tool dash0_widgets_delete not found"},
+		{"no examples", "dash0_widgets_list", "has no examples"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := reg.Call(context.Background(), "dash0_examples", map[string]interface{}{"tool": tt.tool})
+			if result.Success || !strings.Contains(result.Error.Detail, tt.wantErr) {
+				t.Errorf("expected error containing %q, got %+v", tt.wantErr, result.Error)
+			}
+		})
+	}
+}
+
+func TestSessionUsageHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		w.Write([]byte(`{"items":[]}`))
+	}))
+	defer server.Close()
+
+	c := client.NewWithBaseURL(server.URL, "test-token")
+	c.SetCacheTTL(time.Minute)
+	c.SetCallBudget(2)
+	ctx := context.Background()
+	c.Get(ctx, "/api/dashboards")
+	c.Get(ctx, "/api/dashboards")
+	c.Get(ctx, "/missing")
+	c.Post(ctx, "/api/views", map[string]interface{}{"kind": "Dash0View"})
+
+	pkg := New(registry.New(nil), c)
+	result := pkg.SessionUsageHandler(ctx, map[string]interface{}{})
+	if !result.Success {
+		t.Fatalf("expected success, got %+v", result.Error)
+	}
+	u := result.Data.(client.Usage)
+	if u.Calls != 3 || u.Errors != 1 || u.BytesReceived != 36 || u.BytesSent == 0 {
+		t.Errorf("usage = %+v, want 3 calls, 1 error, 36 bytes received", u)
+	}
+	if u.Cache.Hits != 1 || u.Cache.Misses != 2 {
+		t.Errorf("cache = %+v, want 1 hit and 2 misses", u.Cache)
+	}
+	for _, s := range []string{"## Session Usage", "| API calls | 3 |", "| Cache hit rate | 33.3% (1 hits, 2 misses) |", "3 of 2 calls used (over budget)"} {
+		if !strings.Contains(result.Markdown, s) {
+			t.Errorf("markdown missing %q:\n%s", s, result.Markdown)
+		}
+	}
+}
+
+func TestSessionUsageHandler_ToolStats(t *testing.T) {
+	rec := selftel.New("1.0.0", func(context.Context, interface{}) error { return nil })
+	c := client.NewWithBaseURL("http://localhost", "test-token")
+	c.SetTelemetry(rec)
+	for _, code := range []string{"", "not_found"} {
+		_, call := rec.StartTool(context.Background(), "dash0_views_get")
+		call.End(code, 2048)
+	}
+
+	pkg := New(registry.New(nil), c)
+	result := pkg.SessionUsageHandler(context.Background(), map[string]interface{}{})
+	if !result.Success {
+		t.Fatalf("expected success, got %+v", result.Error)
+	}
+	for _, s := range []string{"## Tool Calls", "| dash0_views_get | 2 | 1 |", "4.0 KiB"} {
+		if !strings.Contains(result.Markdown, s) {
+			t.Errorf("markdown missing %q:\n%s", s, result.Markdown)
+		}
+	}
+}
+
+func TestServerStatsHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	rec := selftel.New("1.0.0", nil)
+	c := client.NewWithBaseURL(server.URL, "test-token")
+	c.SetTelemetry(rec)
+	ctx, call := rec.StartTool(context.Background(), "dash0_views_get")
+	result := c.Get(ctx, "/api/views/v1")
+	call.End(result.Error.Code, 0)
+
+	reg := registry.New(nil)
+	views.Register(reg, c)
+	pkg := New(reg, c)
+	result = pkg.ServerStatsHandler(context.Background(), map[string]interface{}{})
+	if !result.Success {
+		t.Fatalf("expected success, got %+v", result.Error)
+	}
+	for _, s := range []string{"## Endpoints", "| GET /api/views/{id} | dash0_views_get |", "100.0%", "| Avg Latency | Max Latency |", "## Unused Tools", "dash0_views_list"} {
+		if !strings.Contains(result.Markdown, s) {
+			t.Errorf("markdown missing %q:\n%s", s, result.Markdown)
+		}
+	}
+	unused := result.Data.(map[string]interface{})["unused_tools"].([]string)
+	for _, name := range unused {
+		if name == "dash0_views_get" {
+			t.Errorf("unused_tools = %v, want the called dash0_views_get left out", unused)
+		}
+	}
+	if len(unused) != len(reg.EnabledToolNames())-1 {
+		t.Errorf("unused_tools = %v, want every enabled tool but dash0_views_get", unused)
+	}
+}
+
+// selftestServer mocks the endpoints dash0_selftest calls. Sent log records
+// are returned by later log queries; createStatus overrides the status of
+// view creation.
+func selftestServer(t *testing.T, createStatus int) (*httptest.Server, *[]string) {
+	t.Helper()
+	var calls []string
+	var sentLogs interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		_, isQuery := body["timeRange"]
+
+		switch {
+		case r.URL.Path == "/api/views" && r.Method == http.MethodPost:
+			if createStatus != 0 {
+				w.WriteHeader(createStatus)
+				w.Write([]byte(`{"error":"forbidden"}`))
+				return
+			}
+			body["metadata"].(map[string]interface{})["labels"] = map[string]interface{}{"dash0.com/origin": "view-123"}
+			json.NewEncoder(w).Encode(body)
+		case r.URL.Path == "/api/logs" && !isQuery:
+			sentLogs = body["resourceLogs"]
+			w.Write([]byte(`{}`))
+		case r.URL.Path == "/api/logs":
+			json.NewEncoder(w).Encode(map[string]interface{}{"resourceLogs": sentLogs})
+		default:
+			w.Write([]byte(`{"items":[]}`))
+		}
+	}))
+	return server, &calls
+}
+
+// selftestRegistry registers the meta tools and the tools the self-test
+// calls.
+func selftestRegistry(c *client.Client, enabled map[string]bool) (*registry.Registry, *Tools) {
+	reg := registry.New(enabled)
+	views.Register(reg, c)
+	spans.Register(reg, c)
+	logs.Register(reg, c)
+	return reg, New(reg, c)
+}
+
+func TestSelftestHandler(t *testing.T) {
+	server, calls := selftestServer(t, 0)
+	defer server.Close()
+
+	_, pkg := selftestRegistry(client.NewWithBaseURL(server.URL, "test-token"), nil)
+	result := pkg.SelftestHandler(context.Background(), map[string]interface{}{"verify_timeout_seconds": float64(5)})
+	if !result.Success {
+		t.Fatalf("expected success, got %+v", result.Error)
+	}
+	res := result.Data.(SelftestResult)
+	if res.Passed != 7 || res.Failed != 0 || res.Skipped != 0 {
+		t.Errorf("result = %+v, want 7 passed", res)
+	}
+	for _, call := range []string{"POST /api/views", "GET /api/views/view-123", "DELETE /api/views/view-123"} {
+		found := false
+		for _, c := range *calls {
+			found = found || c == call
+		}
+		if !found {
+			t.Errorf("missing call %s in %v", call, *calls)
+		}
+	}
+	for _, s := range []string{"## Self-Test", "**7 passed**, 0 failed, 0 skipped", "| Query the log record back | `dash0_logs_send` | ✅ pass |"} {
+		if !strings.Contains(result.Markdown, s) {
+			t.Errorf("markdown missing %q:\n%s", s, result.Markdown)
+		}
+	}
+}
+
+func TestSelftestHandler_Failures(t *testing.T) {
+	server, _ := selftestServer(t, http.StatusForbidden)
+	defer server.Close()
+
+	_, pkg := selftestRegistry(client.NewWithBaseURL(server.URL, "test-token"), nil)
+	result := pkg.SelftestHandler(context.Background(), map[string]interface{}{"verify_timeout_seconds": float64(5)})
+	res := result.Data.(SelftestResult)
+	if res.Failed != 1 || res.Skipped != 2 || res.Passed != 4 {
+		t.Errorf("result = %+v, want create to fail and get/delete to be skipped", res)
+	}
+	if r := res.Capabilities[2]; r.Capability != "Create a view" || r.Status != selftestFail || r.Detail == "" {
+		t.Errorf("create = %+v, want a failure with detail", r)
+	}
+	if !strings.Contains(result.Markdown, "Check DASH0_AUTH_TOKEN permissions") {
+		t.Errorf("markdown missing the troubleshooting footer:\n%s", result.Markdown)
+	}
+}
+
+func TestSelftestHandler_SkipsDisabledAndWrites(t *testing.T) {
+	server, calls := selftestServer(t, 0)
+	defer server.Close()
+
+	c := client.NewWithBaseURL(server.URL, "test-token")
+	_, pkg := selftestRegistry(c, map[string]bool{"dash0_views_list": true, "dash0_selftest": true})
+	res := pkg.SelftestHandler(context.Background(), map[string]interface{}{}).Data.(SelftestResult)
+	if res.Passed != 1 || res.Skipped != 6 {
+		t.Errorf("result = %+v, want spans query and writes skipped in a read-only profile", res)
+	}
+
+	_, pkg = selftestRegistry(c, nil)
+	*calls = nil
+	res = pkg.SelftestHandler(context.Background(), map[string]interface{}{"skip_writes": true}).Data.(SelftestResult)
+	if res.Passed != 2 || res.Skipped != 5 {
+		t.Errorf("result = %+v, want only the reads to run", res)
+	}
+	for _, call := range *calls {
+		if !strings.HasPrefix(call, "GET") && call != "POST /api/spans" {
+			t.Errorf("skip_writes made a write: %s", call)
+		}
+	}
+
+	if result := pkg.SelftestHandler(context.Background(), map[string]interface{}{"verify_timeout_seconds": float64(-1)}); result.Success {
+		t.Error("expected an error for a negative verify_timeout_seconds")
+	}
+}
+
+func TestSelftestHandler_ConfirmsDeletes(t *testing.T) {
+	server, calls := selftestServer(t, 0)
+	defer server.Close()
+
+	c := client.New(&config.Config{BaseURL: server.URL, AuthToken: "test-token", ConfirmDeletes: true})
+	_, pkg := selftestRegistry(c, nil)
+	res := pkg.SelftestHandler(context.Background(), map[string]interface{}{"verify_timeout_seconds": float64(5)}).Data.(SelftestResult)
+	if res.Passed != 7 {
+		t.Errorf("result = %+v, want 7 passed", res)
+	}
+	deleted := false
+	for _, call := range *calls {
+		deleted = deleted || call == "DELETE /api/views/view-123"
+	}
+	if !deleted {
+		t.Errorf("the view was not deleted: %v", *calls)
+	}
+}
+
+func TestPingHandler(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/api/dashboards" || r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"items":[]}`))
+	}))
+	defer server.Close()
+
+	c := client.NewWithBaseURL(server.URL, "test-token")
+	c.SetCacheTTL(time.Minute)
+	pkg := New(registry.New(nil), c)
+	ctx := context.Background()
+	pkg.PingHandler(ctx, map[string]interface{}{})
+	result := pkg.PingHandler(ctx, map[string]interface{}{})
+	if !result.Success {
+		t.Fatalf("expected success, got %+v", result.Error)
+	}
+	if requests != 2 {
+		t.Errorf("%d requests, want every ping to bypass the cache", requests)
+	}
+	res := result.Data.(PingResult)
+	if res.BaseURL != server.URL || res.Dataset != "default" {
+		t.Errorf("result = %+v", res)
+	}
+	for _, s := range []string{"## Dash0 Ping", "| Auth token | accepted |", "| Dataset | default |"} {
+		if !strings.Contains(result.Markdown, s) {
+			t.Errorf("markdown missing %q:\n%s", s, result.Markdown)
+		}
+	}
+
+	prod := pkg.PingHandler(client.WithDataset(ctx, "prod"), map[string]interface{}{})
+	if res := prod.Data.(PingResult); res.Dataset != "prod" {
+		t.Errorf("ping with the prod dataset = %+v", res)
+	}
+}
+
+func TestPingHandler_Unauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"message":"invalid token"}}`))
+	}))
+	defer server.Close()
+
+	pkg := New(registry.New(nil), client.NewWithBaseURL(server.URL, "bad-token"))
+	result := pkg.PingHandler(context.Background(), map[string]interface{}{})
+	if result.Success {
+		t.Fatal("expected failure")
+	}
+	if result.Error.Code != client.CodeAuth || !strings.Contains(result.Error.Message(), "ping of "+server.URL+" failed") ||
+		!strings.Contains(result.Error.Message(), "DASH0_AUTH_TOKEN") {
+		t.Errorf("error = %+v, want an auth error naming the base URL", result.Error)
+	}
+}
+
+func TestServerVersionHandler(t *testing.T) {
+	reg := setupRegistry()
+	pkg := New(reg, client.NewWithBaseURL("https://api.example.com", "test-token"))
+	result := pkg.ServerVersionHandler(context.Background(), map[string]interface{}{})
+	if !result.Success {
+		t.Fatalf("expected success, got %+v", result.Error)
+	}
+	res := result.Data.(ServerVersionResult)
+	if res.Version == "" || res.GoVersion == "" || res.MCPProtocolVersion != mcp.LATEST_PROTOCOL_VERSION || res.BaseURL != "https://api.example.com" {
+		t.Errorf("result = %+v", res)
+	}
+	if res.ToolsRegistered != reg.ToolCount() || res.ToolsEnabled != reg.EnabledCount() {
+		t.Errorf("tools = %d enabled of %d, want %d of %d", res.ToolsEnabled, res.ToolsRegistered, reg.EnabledCount(), reg.ToolCount())
+	}
+	if !strings.Contains(result.Markdown, "| Server version | "+res.Version+" |") {
+		t.Errorf("markdown missing the server version:\n%s", result.Markdown)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{0: "0 B", 1023: "1023 B", 1536: "1.5 KiB", 5 << 20: "5.0 MiB"}
+	for n, want := range tests {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestToolsEnableDisable(t *testing.T) {
+	reg := setupRegistry()
+	reg.SetEnabled(map[string]bool{
+		"dash0_widgets_create": true, "dash0_widgets_list": true, "dash0_examples": true,
+		"dash0_tools_list_enabled": true, "dash0_tools_enable": true, "dash0_tools_disable": true,
+	})
+	var notified int
+	reg.OnChange(func() { notified++ })
+
+	list := func(t *testing.T, args map[string]interface{}) map[string]interface{} {
+		t.Helper()
+		result := reg.Call(context.Background(), "dash0_tools_list_enabled", args)
+		if !result.Success {
+			t.Fatalf("expected success, got %+v", result.Error)
+		}
+		return result.Data.(map[string]interface{})
+	}
+	if data := list(t, map[string]interface{}{"include_disabled": true}); data["count"] != 6 || len(data["disabled"].([]toolInfo)) != 6 {
+		t.Fatalf("list = %v, want 6 enabled and 6 disabled", data)
+	}
+
+	// The config disables dash0_widgets_delete, so the agent cannot turn it on
+	result := reg.Call(context.Background(), "dash0_tools_enable", map[string]interface{}{"tools": []interface{}{"dash0_widgets_delete"}})
+	if result.Success || result.Error.StatusCode != http.StatusForbidden || reg.IsEnabled("dash0_widgets_delete") {
+		t.Fatalf("enable of a tool the config disables = %+v, want 403 and no change", result)
+	}
+
+	result = reg.Call(context.Background(), "dash0_tools_disable", map[string]interface{}{"tools": []interface{}{"dash0_widgets_list"}})
+	if !result.Success || reg.IsEnabled("dash0_widgets_list") || notified != 1 {
+		t.Errorf("disable: %+v, enabled %v, %d notifications", result.Error, reg.IsEnabled("dash0_widgets_list"), notified)
+	}
+
+	result = reg.Call(context.Background(), "dash0_tools_enable", map[string]interface{}{"tools": []interface{}{"dash0_widgets_list", "dash0_widgets_create"}})
+	if !result.Success {
+		t.Fatalf("enable failed: %+v", result.Error)
+	}
+	if got := result.Data.(map[string]interface{})["enabled"].([]string); len(got) != 1 || got[0] != "dash0_widgets_list" {
+		t.Errorf("enabled = %v, want only the disabled dash0_widgets_list", got)
+	}
+	if !reg.IsEnabled("dash0_widgets_list") || notified != 2 {
+		t.Errorf("dash0_widgets_list enabled %v, %d notifications; want enabled and 2", reg.IsEnabled("dash0_widgets_list"), notified)
+	}
+	if data := list(t, map[string]interface{}{}); data["count"] != 6 || data["disabled"] != nil {
+		t.Errorf("list = %v, want 6 enabled and no disabled list", data)
+	}
+
+	// Dry runs change nothing
+	result = reg.Call(client.WithDryRun(context.Background()), "dash0_tools_disable", map[string]interface{}{"tools": []interface{}{"dash0_widgets_list"}})
+	if !result.Success || !reg.IsEnabled("dash0_widgets_list") || !strings.Contains(result.Markdown, "would have disabled 1 tools") {
+		t.Errorf("dry run: %+v, enabled %v, markdown %q", result.Error, reg.IsEnabled("dash0_widgets_list"), result.Markdown)
+	}
+
+	for name, args := range map[string]map[string]interface{}{
+		"unknown": {"tools": []interface{}{"dash0_widgets_get"}},
+		"missing": {},
+		"invalid": {"tools": "dash0_widgets_delete"},
+	} {
+		result := reg.Call(context.Background(), "dash0_tools_disable", args)
+		if result.Success {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if notified != 2 {
+		t.Errorf("%d notifications after failed calls, want 2", notified)
+	}
+}
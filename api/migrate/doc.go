@@ -0,0 +1,5 @@
+// Package migrate provides the MCP tool that copies Dash0 configuration
+// between targets: from one organization or dataset to another, with origin
+// rewriting and dataset remapping, for org consolidation and promoting
+// monitoring config from staging to production.
+package migrate
@@ -0,0 +1,30 @@
+package migrate
+
+import "github.com/npcomplete777/dash0-mcp/internal/registry"
+
+// Compile-time interface check.
+var _ registry.ExampleProvider = (*Tools)(nil)
+
+// Examples returns example invocations for the migration tools.
+func (p *Tools) Examples() map[string][]registry.Example {
+	return map[string][]registry.Example{
+		"dash0_migrate": {
+			{
+				Title: "Preview promoting staging config to the prod target",
+				Arguments: map[string]interface{}{
+					"destination":    "prod",
+					"dataset_map":    map[string]interface{}{"staging": "production"},
+					"origin_rewrite": map[string]interface{}{"staging": "prod"},
+					"dry_run":        true,
+				},
+			},
+			{
+				Title: "Copy dashboards and check rules between datasets",
+				Arguments: map[string]interface{}{
+					"resources":   []interface{}{"dashboards", "check_rules"},
+					"dataset_map": map[string]interface{}{"staging": "production"},
+				},
+			},
+		},
+	}
+}
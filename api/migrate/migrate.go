@@ -0,0 +1,220 @@
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/npcomplete777/dash0-mcp/internal/canonical"
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+)
+
+// collection is a configuration object type that can be migrated.
+type collection struct {
+	// Name is the value accepted by the resources argument.
+	Name string
+	// Path is the Dash0 API path for listing, getting, and upserting objects.
+	Path string
+}
+
+// collections lists the migrated object types, in the order they are applied.
+var collections = []collection{
+	{Name: "dashboards", Path: "/api/dashboards"},
+	{Name: "views", Path: "/api/views"},
+	{Name: "check_rules", Path: "/api/alerting/check-rules"},
+	{Name: "synthetic_checks", Path: "/api/synthetic-checks"},
+	{Name: "sampling_rules", Path: "/api/sampling-rules"},
+}
+
+// Migration statuses.
+const (
+	statusApplied = "applied"
+	statusPlanned = "planned"
+	statusFailed  = "failed"
+)
+
+// Item is the outcome of migrating one object.
+type Item struct {
+	Kind        string `json:"kind"`
+	Name        string `json:"name,omitempty"`
+	Source      string `json:"source,omitempty"`
+	Origin      string `json:"origin,omitempty"`
+	FromDataset string `json:"from_dataset,omitempty"`
+	ToDataset   string `json:"to_dataset,omitempty"`
+	Status      string `json:"status"`
+	Detail      string `json:"detail,omitempty"`
+}
+
+// datasetPair maps a source dataset to a destination dataset. An empty
+// dataset means the target's configured dataset.
+type datasetPair struct {
+	from, to string
+}
+
+// serverFields are top-level fields assigned by Dash0 that must not be sent
+// when creating an object in another target.
+var serverFields = []string{"id", "createdAt", "updatedAt", "version", "status"}
+
+// serverMetadata are metadata fields assigned by Dash0 or Kubernetes.
+var serverMetadata = []string{"createdAt", "updatedAt", "version", "resourceVersion", "uid", "generation", "creationTimestamp"}
+
+// selectCollections returns the collections named in the resources argument,
+// or all of them when it is empty.
+func selectCollections(raw interface{}) ([]collection, error) {
+	list, _ := raw.([]interface{})
+	if len(list) == 0 {
+		return collections, nil
+	}
+	want := map[string]bool{}
+	for _, v := range list {
+		name, _ := v.(string)
+		name = strings.TrimSpace(name)
+		if !isCollection(name) {
+			return nil, fmt.Errorf("unknown resource type %q; valid types: %s", name, strings.Join(collectionNames(), ", "))
+		}
+		want[name] = true
+	}
+	var out []collection
+	for _, col := range collections {
+		if want[col.Name] {
+			out = append(out, col)
+		}
+	}
+	return out, nil
+}
+
+func isCollection(name string) bool {
+	for _, col := range collections {
+		if col.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func collectionNames() []string {
+	names := make([]string, len(collections))
+	for i, col := range collections {
+		names[i] = col.Name
+	}
+	return names
+}
+
+// stringMap reads an object argument of string values.
+func stringMap(raw interface{}, name string) (map[string]string, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be an object of strings", name)
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s.%s must be a string", name, k)
+		}
+		out[k] = s
+	}
+	return out, nil
+}
+
+// datasetPairs returns the datasets to migrate: every entry of datasetMap,
+// or the source's current dataset to the destination's configured one.
+func datasetPairs(datasetMap map[string]string, from, to string) []datasetPair {
+	if len(datasetMap) == 0 {
+		return []datasetPair{{from: from, to: to}}
+	}
+	keys := make([]string, 0, len(datasetMap))
+	for k := range datasetMap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]datasetPair, len(keys))
+	for i, k := range keys {
+		pairs[i] = datasetPair{from: k, to: datasetMap[k]}
+	}
+	return pairs
+}
+
+// datasetContext routes requests made with ctx to dataset, or to the
+// client's configured dataset when it is empty.
+func datasetContext(ctx context.Context, dataset string) context.Context {
+	if dataset == "" {
+		return client.WithoutDataset(ctx)
+	}
+	return client.WithDataset(ctx, dataset)
+}
+
+// rewriteOrigin applies the origin_rewrite replacements, longest match first
+// so overlapping patterns behave predictably.
+func rewriteOrigin(origin string, rewrites map[string]string) string {
+	keys := make([]string, 0, len(rewrites))
+	for k := range rewrites {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	for _, k := range keys {
+		if strings.Contains(origin, k) {
+			return strings.Replace(origin, k, rewrites[k], 1)
+		}
+	}
+	return origin
+}
+
+// prepareObject copies a source object for upserting into the destination:
+// server-assigned fields are dropped and the origin and dataset are replaced.
+func prepareObject(data interface{}, origin, dataset string) (map[string]interface{}, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(raw, &obj); err != nil || obj == nil {
+		return nil, fmt.Errorf("unexpected object format")
+	}
+
+	for _, f := range serverFields {
+		delete(obj, f)
+	}
+	if _, ok := obj["origin"]; ok {
+		obj["origin"] = origin
+	}
+	if _, ok := obj["dataset"]; ok {
+		setOrDelete(obj, "dataset", dataset)
+	}
+
+	if meta, ok := obj["metadata"].(map[string]interface{}); ok {
+		for _, f := range serverMetadata {
+			delete(meta, f)
+		}
+		if labels, ok := meta["labels"].(map[string]interface{}); ok {
+			canonical.StripServerLabels(labels)
+			if _, ok := labels["dash0.com/origin"]; ok {
+				labels["dash0.com/origin"] = origin
+			}
+			if _, ok := labels["dash0.com/dataset"]; ok {
+				setOrDelete(labels, "dash0.com/dataset", dataset)
+			}
+		}
+	}
+	return obj, nil
+}
+
+func setOrDelete(m map[string]interface{}, key, value string) {
+	if value == "" {
+		delete(m, key)
+		return
+	}
+	m[key] = value
+}
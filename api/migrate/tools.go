@@ -0,0 +1,300 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/config"
+	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+	"github.com/npcomplete777/dash0-mcp/internal/listing"
+	"github.com/npcomplete777/dash0-mcp/internal/registry"
+	mcp "github.com/mark3labs/mcp-go/mcp"
+)
+
+// Compile-time interface check.
+var _ registry.ToolProvider = (*Tools)(nil)
+
+// Tools provides the MCP tool for migrating configuration between targets.
+type Tools struct {
+	client *client.Client
+}
+
+// New creates a new Migrate tools instance.
+func New(c *client.Client) *Tools {
+	return &Tools{client: c}
+}
+
+// Tools returns all MCP tools in this package.
+func (p *Tools) Tools() []mcp.Tool {
+	return []mcp.Tool{
+		p.Migrate(),
+	}
+}
+
+// Handlers returns a map of tool name to handler function.
+func (p *Tools) Handlers() map[string]func(context.Context, map[string]interface{}) *client.ToolResult {
+	return map[string]func(context.Context, map[string]interface{}) *client.ToolResult{
+		"dash0_migrate": p.MigrateHandler,
+	}
+}
+
+// Migrate returns the dash0_migrate tool definition.
+func (p *Tools) Migrate() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_migrate",
+		Description: `Copy all configuration from one Dash0 target to another: dashboards, views,
+check rules, synthetic checks, and sampling rules.
+
+Targets are named in the config file under "targets" (each with its own region or base_url,
+auth_token, and dataset); "default" is the top-level configuration. Use it to consolidate
+organizations or to promote monitoring config between environments (staging → prod).
+
+- Every object is read from the source and upserted into the destination by origin, so
+  re-running a migration updates the objects it created instead of duplicating them
+- origin_rewrite replaces part of each origin, e.g. {"staging": "prod"}
+- dataset_map migrates each source dataset into a destination dataset, e.g.
+  {"staging": "production"}; without it, the source dataset (or the dataset argument)
+  is copied into the destination's configured dataset
+
+Set dry_run to true to list what would be written without changing the destination.`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"source": map[string]interface{}{
+					"type":        "string",
+					"description": "Target to read configuration from. Default: default",
+				},
+				"destination": map[string]interface{}{
+					"type":        "string",
+					"description": "Target to write configuration to. Default: default",
+				},
+				"resources": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string", "enum": collectionNames()},
+					"description": "Object types to migrate. Default: all",
+				},
+				"dataset_map": map[string]interface{}{
+					"type":        "object",
+					"description": "Source dataset to destination dataset, e.g. {\"staging\": \"production\"}",
+				},
+				"origin_rewrite": map[string]interface{}{
+					"type":        "object",
+					"description": "Substring replacements applied to each origin, e.g. {\"staging\": \"prod\"}",
+				},
+				"dry_run": map[string]interface{}{
+					"type":        "boolean",
+					"description": "List the objects that would be written without writing them. Default: false",
+				},
+			},
+		},
+	}
+}
+
+// MigrateHandler handles the dash0_migrate tool.
+func (p *Tools) MigrateHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	sourceName := targetArg(args, "source")
+	destName := targetArg(args, "destination")
+	source, err := p.client.Target(sourceName)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+	dest, err := p.client.Target(destName)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+
+	cols, err := selectCollections(args["resources"])
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+	datasetMap, err := stringMap(args["dataset_map"], "dataset_map")
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+	rewrites, err := stringMap(args["origin_rewrite"], "origin_rewrite")
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+	dryRun, _ := args["dry_run"].(bool)
+	dryRun = dryRun || dest.DryRun(ctx)
+
+	pairs := datasetPairs(datasetMap, source.Dataset(ctx), dest.GetDataset())
+	if sourceName == destName && len(rewrites) == 0 {
+		for _, pair := range pairs {
+			if pair.from == pair.to {
+				return client.ErrorResult(400, "source and destination are the same; set destination, dataset_map, or origin_rewrite")
+			}
+		}
+	}
+
+	items := []Item{}
+	for _, pair := range pairs {
+		srcCtx := datasetContext(ctx, pair.from)
+		dstCtx := datasetContext(ctx, pair.to)
+		for _, col := range cols {
+			items = append(items, p.migrateCollection(srcCtx, dstCtx, source, dest, col, pair, rewrites, dryRun)...)
+		}
+	}
+
+	applied, planned, failed := countItems(items)
+	return &client.ToolResult{
+		Success: true,
+		Data: map[string]interface{}{
+			"source":      sourceName,
+			"destination": destName,
+			"dry_run":     dryRun,
+			"applied":     applied,
+			"planned":     planned,
+			"failed":      failed,
+			"items":       items,
+		},
+		Markdown: formatMigration(sourceName, destName, items, dryRun),
+	}
+}
+
+// migrateCollection copies every object of one type for one dataset pair,
+// several objects at a time.
+func (p *Tools) migrateCollection(srcCtx, dstCtx context.Context, source, dest *client.Client, col collection, pair datasetPair, rewrites map[string]string, dryRun bool) []Item {
+	list := source.Get(srcCtx, col.Path)
+	if !list.Success {
+		return []Item{{Kind: col.Name, FromDataset: pair.from, ToDataset: pair.to, Status: statusFailed, Detail: "list failed: " + errorDetail(list)}}
+	}
+
+	var items []Item
+	for _, raw := range listing.FromResponse(list.Data).Items {
+		m, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id := listing.ItemID(m)
+		if id == "" {
+			continue
+		}
+		items = append(items, Item{
+			Kind:        col.Name,
+			Name:        listing.ItemName(m),
+			Source:      id,
+			Origin:      rewriteOrigin(id, rewrites),
+			FromDataset: pair.from,
+			ToDataset:   pair.to,
+		})
+	}
+
+	// Failures are recorded per item, so the fan-out itself never fails.
+	source.Parallel(srcCtx, len(items), func(_ context.Context, i int) *client.ToolResult {
+		p.migrateItem(srcCtx, dstCtx, source, dest, col, &items[i], dryRun)
+		return nil
+	})
+	return items
+}
+
+// migrateItem reads one object from the source and, unless dryRun, writes it
+// to the destination, recording the outcome on item.
+func (p *Tools) migrateItem(srcCtx, dstCtx context.Context, source, dest *client.Client, col collection, item *Item, dryRun bool) {
+	full := source.Get(srcCtx, fmt.Sprintf("%s/%s", col.Path, url.PathEscape(item.Source)))
+	if !full.Success {
+		item.Status, item.Detail = statusFailed, "get failed: "+errorDetail(full)
+		return
+	}
+	if item.Name == "" {
+		if fm, ok := full.Data.(map[string]interface{}); ok {
+			item.Name = listing.ItemName(fm)
+		}
+	}
+	obj, err := prepareObject(full.Data, item.Origin, item.ToDataset)
+	if err != nil {
+		item.Status, item.Detail = statusFailed, err.Error()
+		return
+	}
+
+	if dryRun {
+		item.Status = statusPlanned
+		return
+	}
+	result := dest.Put(dstCtx, fmt.Sprintf("%s/%s", col.Path, url.PathEscape(item.Origin)), obj)
+	if result.Success {
+		item.Status = statusApplied
+	} else {
+		item.Status, item.Detail = statusFailed, errorDetail(result)
+	}
+}
+
+// targetArg returns a target name argument, defaulting to the top-level
+// configuration.
+func targetArg(args map[string]interface{}, name string) string {
+	s, _ := args[name].(string)
+	if s = strings.TrimSpace(s); s == "" {
+		return config.DefaultTarget
+	}
+	return s
+}
+
+func errorDetail(result *client.ToolResult) string {
+	if result.Error == nil {
+		return "request failed"
+	}
+	if result.Error.Detail != "" {
+		return result.Error.Detail
+	}
+	if result.Error.Title != "" {
+		return result.Error.Title
+	}
+	return fmt.Sprintf("HTTP %d", result.Error.StatusCode)
+}
+
+func countItems(items []Item) (applied, planned, failed int) {
+	for _, it := range items {
+		switch it.Status {
+		case statusApplied:
+			applied++
+		case statusPlanned:
+			planned++
+		case statusFailed:
+			failed++
+		}
+	}
+	return applied, planned, failed
+}
+
+// formatMigration renders the per-object migration report.
+func formatMigration(source, dest string, items []Item, dryRun bool) string {
+	applied, planned, failed := countItems(items)
+	summary := fmt.Sprintf("%d applied, %d failed", applied, failed)
+	if dryRun {
+		summary = fmt.Sprintf("%d objects would be written, %d failed (dry run, nothing changed)", planned, failed)
+	}
+	if len(items) == 0 {
+		return formatter.Table(fmt.Sprintf("Migration: %s → %s", source, dest), summary+"\n\nNo objects found in the source.", nil, nil, "")
+	}
+
+	rows := make([][]string, 0, len(items))
+	for _, it := range items {
+		rows = append(rows, []string{
+			it.Kind, it.Name, it.Source, it.Origin,
+			datasetLabel(it.FromDataset) + " → " + datasetLabel(it.ToDataset),
+			it.Status, it.Detail,
+		})
+	}
+	return formatter.Table(fmt.Sprintf("Migration: %s → %s", source, dest), summary,
+		[]string{"Kind", "Name", "Source", "Origin", "Dataset", "Status", "Detail"}, rows, "")
+}
+
+func datasetLabel(ds string) string {
+	if ds == "" {
+		return "(default)"
+	}
+	return ds
+}
+
+// Register registers all migrate tools with the registry.
+func Register(reg *registry.Registry, c *client.Client) {
+	p := New(c)
+	for _, tool := range p.Tools() {
+		handler := p.Handlers()[tool.Name]
+		reg.Register(tool, handler)
+		reg.AddExamples(tool.Name, p.Examples()[tool.Name]...)
+	}
+}
@@ -0,0 +1,243 @@
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+)
+
+func TestNew(t *testing.T) {
+	c := &client.Client{}
+	pkg := New(c)
+	if pkg == nil {
+		t.Fatal("New() returned nil")
+	}
+	if pkg.client != c {
+		t.Error("New() did not set client correctly")
+	}
+}
+
+func TestTools(t *testing.T) {
+	pkg := New(&client.Client{})
+	tools := pkg.Tools()
+
+	if len(tools) != 1 || tools[0].Name != "dash0_migrate" {
+		t.Fatalf("Tools() = %v, want dash0_migrate", tools)
+	}
+	if _, ok := pkg.Handlers()["dash0_migrate"]; !ok {
+		t.Error("Missing handler for: dash0_migrate")
+	}
+}
+
+// sourceServer serves a staging dashboard and check rule; other collections
+// are empty.
+func sourceServer(t *testing.T) *httptest.Server {
+	objects := map[string]interface{}{
+		"/api/dashboards": []interface{}{
+			map[string]interface{}{"metadata": map[string]interface{}{
+				"name":   "Checkout",
+				"labels": map[string]interface{}{"dash0.com/origin": "staging-checkout", "dash0.com/id": "d-1"},
+			}},
+		},
+		"/api/dashboards/staging-checkout": map[string]interface{}{
+			"kind": "Dashboard",
+			"metadata": map[string]interface{}{
+				"name":      "Checkout",
+				"createdAt": "2026-01-01T00:00:00Z",
+				"labels": map[string]interface{}{
+					"dash0.com/origin":  "staging-checkout",
+					"dash0.com/id":      "d-1",
+					"dash0.com/dataset": "staging",
+				},
+			},
+			"spec": map[string]interface{}{"display": map[string]interface{}{"name": "Checkout"}},
+		},
+		"/api/alerting/check-rules": []interface{}{
+			map[string]interface{}{"id": "r-1", "name": "HighErrorRate"},
+		},
+		"/api/alerting/check-rules/r-1": map[string]interface{}{
+			"id": "r-1", "name": "HighErrorRate", "expression": "up == 0", "dataset": "staging",
+		},
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("source received %s %s, want only reads", r.Method, r.URL.Path)
+		}
+		if ds := r.URL.Query().Get("dataset"); ds != "staging" {
+			t.Errorf("source dataset = %q, want staging", ds)
+		}
+		if obj, ok := objects[r.URL.Path]; ok {
+			json.NewEncoder(w).Encode(obj)
+			return
+		}
+		if strings.Count(r.URL.Path, "/") == 2 || r.URL.Path == "/api/synthetic-checks" {
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+}
+
+func TestMigrateHandler(t *testing.T) {
+	src := sourceServer(t)
+	defer src.Close()
+
+	var mu sync.Mutex
+	written := map[string]map[string]interface{}{}
+	dst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("destination received %s %s, want PUT", r.Method, r.URL.Path)
+		}
+		if ds := r.URL.Query().Get("dataset"); ds != "production" {
+			t.Errorf("destination dataset = %q, want production", ds)
+		}
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		written[r.URL.Path] = body
+		mu.Unlock()
+		if strings.HasPrefix(r.URL.Path, "/api/alerting") {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": {"message": "invalid expression"}}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer dst.Close()
+
+	c := client.NewWithBaseURL(src.URL, "test-token")
+	c.SetTarget("prod", client.NewWithBaseURL(dst.URL, "prod-token"))
+	pkg := New(c)
+	args := func(extra map[string]interface{}) map[string]interface{} {
+		a := map[string]interface{}{
+			"destination":    "prod",
+			"dataset_map":    map[string]interface{}{"staging": "production"},
+			"origin_rewrite": map[string]interface{}{"staging": "prod"},
+		}
+		for k, v := range extra {
+			a[k] = v
+		}
+		return a
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		result := pkg.MigrateHandler(context.Background(), args(map[string]interface{}{"dry_run": true}))
+		if !result.Success {
+			t.Fatalf("expected success, got %v", result.Error)
+		}
+		if len(written) != 0 {
+			t.Errorf("dry run wrote %d objects", len(written))
+		}
+		if data := result.Data.(map[string]interface{}); data["planned"] != 2 {
+			t.Errorf("planned = %v, want 2", data["planned"])
+		}
+		if !strings.Contains(result.Markdown, "2 objects would be written") {
+			t.Errorf("markdown missing dry run summary:\n%s", result.Markdown)
+		}
+	})
+
+	t.Run("migrate", func(t *testing.T) {
+		result := pkg.MigrateHandler(context.Background(), args(nil))
+		if !result.Success {
+			t.Fatalf("expected success, got %v", result.Error)
+		}
+		data := result.Data.(map[string]interface{})
+		if data["applied"] != 1 || data["failed"] != 1 {
+			t.Errorf("applied/failed = %v/%v, want 1/1", data["applied"], data["failed"])
+		}
+
+		dashboard := written["/api/dashboards/prod-checkout"]
+		if dashboard == nil {
+			t.Fatalf("dashboard not upserted under the rewritten origin, got %v", written)
+		}
+		meta := dashboard["metadata"].(map[string]interface{})
+		labels := meta["labels"].(map[string]interface{})
+		if labels["dash0.com/origin"] != "prod-checkout" || labels["dash0.com/dataset"] != "production" {
+			t.Errorf("labels = %v, want rewritten origin and dataset", labels)
+		}
+		if _, ok := labels["dash0.com/id"]; ok {
+			t.Error("server-assigned id label was copied")
+		}
+		if _, ok := meta["createdAt"]; ok {
+			t.Error("server-assigned createdAt was copied")
+		}
+
+		rule := written["/api/alerting/check-rules/r-1"]
+		if rule == nil || rule["dataset"] != "production" {
+			t.Errorf("check rule = %v, want dataset production", rule)
+		}
+		if _, ok := rule["id"]; ok {
+			t.Error("check rule id was copied")
+		}
+
+		items := data["items"].([]Item)
+		var failed *Item
+		for i := range items {
+			if items[i].Status == statusFailed {
+				failed = &items[i]
+			}
+		}
+		if failed == nil || failed.Kind != "check_rules" || failed.Detail == "" {
+			t.Errorf("expected a failed check rule with detail, got %+v", items)
+		}
+		for _, s := range []string{"Migration: default → prod", "1 applied, 1 failed", "prod-checkout", "staging → production"} {
+			if !strings.Contains(result.Markdown, s) {
+				t.Errorf("markdown missing %q:\n%s", s, result.Markdown)
+			}
+		}
+	})
+
+	t.Run("resources filter", func(t *testing.T) {
+		result := pkg.MigrateHandler(context.Background(), args(map[string]interface{}{
+			"resources": []interface{}{"dashboards"},
+			"dry_run":   true,
+		}))
+		if items := result.Data.(map[string]interface{})["items"].([]Item); len(items) != 1 || items[0].Kind != "dashboards" {
+			t.Errorf("items = %+v, want only the dashboard", items)
+		}
+	})
+}
+
+func TestMigrateHandler_InvalidArguments(t *testing.T) {
+	c := client.NewWithBaseURL("http://localhost:0", "test-token")
+	pkg := New(c)
+
+	tests := []struct {
+		name    string
+		args    map[string]interface{}
+		wantErr string
+	}{
+		{"unknown target", map[string]interface{}{"destination": "nope"}, `unknown target "nope"`},
+		{"unknown resource", map[string]interface{}{"destination": "default", "origin_rewrite": map[string]interface{}{"a": "b"}, "resources": []interface{}{"widgets"}}, `unknown resource type "widgets"`},
+		{"bad dataset map", map[string]interface{}{"dataset_map": "staging"}, "dataset_map must be an object"},
+		{"same source and destination", map[string]interface{}{}, "source and destination are the same"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := pkg.MigrateHandler(context.Background(), tt.args)
+			if result.Success || !strings.Contains(result.Error.Detail, tt.wantErr) {
+				t.Errorf("expected error containing %q, got %+v", tt.wantErr, result.Error)
+			}
+		})
+	}
+}
+
+func TestRewriteOrigin(t *testing.T) {
+	rewrites := map[string]string{"staging": "prod", "staging-eu": "prod-eu"}
+	tests := map[string]string{
+		"staging-checkout":    "prod-checkout",
+		"staging-eu-checkout": "prod-eu-checkout",
+		"dash0-checkout":      "dash0-checkout",
+	}
+	for in, want := range tests {
+		if got := rewriteOrigin(in, rewrites); got != want {
+			t.Errorf("rewriteOrigin(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
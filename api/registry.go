@@ -1,14 +1,21 @@
 // Package api provides the unified registry for all Dash0 MCP tools.
 package api
 
+//go:generate go run ../cmd/docsgen -config ../config -out ../docs
+
 import (
 	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/config"
 	"github.com/npcomplete777/dash0-mcp/internal/registry"
 
 	"github.com/npcomplete777/dash0-mcp/api/alerting"
+	"github.com/npcomplete777/dash0-mcp/api/analysis"
 	"github.com/npcomplete777/dash0-mcp/api/dashboards"
+	"github.com/npcomplete777/dash0-mcp/api/export"
 	"github.com/npcomplete777/dash0-mcp/api/imports"
 	"github.com/npcomplete777/dash0-mcp/api/logs"
+	"github.com/npcomplete777/dash0-mcp/api/meta"
+	"github.com/npcomplete777/dash0-mcp/api/migrate"
 	"github.com/npcomplete777/dash0-mcp/api/samplingrules"
 	"github.com/npcomplete777/dash0-mcp/api/spans"
 	"github.com/npcomplete777/dash0-mcp/api/syntheticchecks"
@@ -17,18 +24,30 @@ import (
 
 // RegisterAllTools registers all tool handlers with the registry.
 // All handlers are registered, but only enabled tools are exposed.
-func RegisterAllTools(reg *registry.Registry, c *client.Client) {
+// panelTemplates are the templates of dash0_dashboards_add_panel, and
+// exportDir the directory the export tools write files to.
+func RegisterAllTools(reg *registry.Registry, c *client.Client, panelTemplates []config.PanelTemplate, exportDir string) {
 	// Telemetry data ingestion
 	logs.Register(reg, c)
 	spans.Register(reg, c)
 
 	// Configuration management
 	alerting.Register(reg, c)
-	dashboards.Register(reg, c)
+	dashboards.Register(reg, c, panelTemplates)
 	views.Register(reg, c)
 	syntheticchecks.Register(reg, c)
 	samplingrules.Register(reg, c)
 
 	// Migration/import
 	imports.Register(reg, c)
+	migrate.Register(reg, c)
+
+	// Local export
+	export.Register(reg, c, exportDir)
+
+	// Telemetry analysis
+	analysis.Register(reg, c)
+
+	// Tool examples and session usage
+	meta.Register(reg, c)
 }
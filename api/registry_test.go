@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/npcomplete777/dash0-mcp/internal/client"
@@ -17,7 +18,7 @@ func setupRegistry(t *testing.T) *registry.Registry {
 	}
 	c := client.New(cfg)
 	reg := registry.New(nil)
-	RegisterAllTools(reg, c)
+	RegisterAllTools(reg, c, nil, "")
 	return reg
 }
 
@@ -160,16 +161,20 @@ func TestRegistryExpectedToolCount(t *testing.T) {
 	reg := setupRegistry(t)
 
 	// Count expected tools:
-	// logs: 2 (send, query)
-	// spans: 2 (send, query)
-	// alerting: 5 (list, get, create, update, delete)
-	// dashboards: 5 (list, get, create, update, delete)
+	// logs: 3 (send, query, severity_trend)
+	// spans: 3 (send, query, stats)
+	// alerting: 8 (list, get, create, update, delete, active_alerts, test, backtest)
+	// dashboards: 7 (list, get, create, update, delete, diff, add_panel)
 	// views: 5 (list, get, create, update, delete)
-	// syntheticchecks: 5 (list, get, create, update, delete)
-	// samplingrules: 5 (list, get, create, update, delete)
-	// imports: 4 (check_rule, dashboard, synthetic_check, view)
-	// Total: 2 + 2 + 6 + 5 + 5 + 5 + 5 + 4 = 34
-	expectedCount := 34
+	// syntheticchecks: 10 (list, get, create, update, delete, enable, disable, generate_from_openapi, build_assertions, create_cert_check)
+	// samplingrules: 8 (list, get, create, update, delete, policy_export, policy_apply, simulate)
+	// imports: 5 (check_rule, dashboard, prometheus_rules, synthetic_check, view)
+	// migrate: 1 (migrate)
+	// export: 7 (table, all, dashboards, views, check_rules, synthetic_checks, sampling_rules)
+	// analysis: 7 (golden_signals, canary_analyze, services_compare, service_health, errors_summarize, wait_for, compare_windows)
+	// meta: 9 (examples, session_usage, server_stats, selftest, ping, server_version, tools_list_enabled, tools_enable, tools_disable)
+	// Total: 3 + 3 + 8 + 7 + 5 + 8 + 10 + 5 + 1 + 7 + 7 + 9 = 73
+	expectedCount := 73
 
 	actualCount := reg.ToolCount()
 	if actualCount != expectedCount {
@@ -183,6 +188,73 @@ func TestRegistryExpectedToolCount(t *testing.T) {
 	}
 }
 
+// jsonType returns the JSON schema type of an example argument value.
+func jsonType(v interface{}) string {
+	switch n := v.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int:
+		return "integer"
+	case float64:
+		if n == float64(int64(n)) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func TestRegistryToolsHaveValidExamples(t *testing.T) {
+	reg := setupRegistry(t)
+
+	for _, tool := range reg.GetEnabledTools() {
+		examples := reg.Examples(tool.Name)
+		if len(examples) == 0 {
+			t.Errorf("Tool %s has no examples", tool.Name)
+		}
+		for _, ex := range examples {
+			if ex.Title == "" {
+				t.Errorf("Tool %s has an example without a title", tool.Name)
+			}
+			for _, req := range tool.InputSchema.Required {
+				if _, ok := ex.Arguments[req]; !ok {
+					t.Errorf("%s example %q is missing required argument %s", tool.Name, ex.Title, req)
+				}
+			}
+			for name, value := range ex.Arguments {
+				prop, ok := tool.InputSchema.Properties[name].(map[string]interface{})
+				if !ok {
+					t.Errorf("%s example %q has unknown argument %s", tool.Name, ex.Title, name)
+					continue
+				}
+				got := jsonType(value)
+				var want []string
+				switch typ := prop["type"].(type) {
+				case string:
+					want = []string{typ}
+				case []string:
+					want = typ
+				}
+				matched := false
+				for _, w := range want {
+					if got == w || (got == "integer" && w == "number") {
+						matched = true
+					}
+				}
+				if !matched {
+					t.Errorf("%s example %q: %s is %s, want %v", tool.Name, ex.Title, name, got, want)
+				}
+			}
+		}
+	}
+}
+
 func TestRegistryToolNamingConvention(t *testing.T) {
 	reg := setupRegistry(t)
 	tools := reg.GetEnabledTools()
@@ -214,7 +286,7 @@ func setupRegistryWithProfile(t *testing.T, profileName string) *registry.Regist
 	}
 	c := client.New(cfg)
 	reg := registry.New(enabledTools)
-	RegisterAllTools(reg, c)
+	RegisterAllTools(reg, c, nil, "")
 	return reg
 }
 
@@ -227,6 +299,7 @@ func TestProfileFull_EnablesAllExceptDeletes(t *testing.T) {
 		"dash0_dashboards_get",
 		"dash0_dashboards_create",
 		"dash0_dashboards_update",
+		"dash0_dashboards_add_panel",
 		"dash0_alerting_check_rules_list",
 		"dash0_alerting_check_rules_get",
 		"dash0_alerting_check_rules_create",
@@ -240,6 +313,8 @@ func TestProfileFull_EnablesAllExceptDeletes(t *testing.T) {
 		"dash0_sampling_rules_get",
 		"dash0_sampling_rules_create",
 		"dash0_sampling_rules_update",
+		"dash0_sampling_policy_export",
+		"dash0_sampling_policy_apply",
 		"dash0_views_list",
 		"dash0_views_get",
 		"dash0_views_create",
@@ -248,6 +323,7 @@ func TestProfileFull_EnablesAllExceptDeletes(t *testing.T) {
 		"dash0_logs_send",
 		"dash0_spans_query",
 		"dash0_spans_send",
+		"dash0_spans_stats",
 		"dash0_import_dashboard",
 		"dash0_import_check_rule",
 		"dash0_import_synthetic_check",
@@ -350,6 +426,7 @@ func TestProfileReadonly_NoWriteTools(t *testing.T) {
 	shouldBeEnabled := []string{
 		"dash0_dashboards_list",
 		"dash0_dashboards_get",
+		"dash0_dashboards_diff",
 		"dash0_alerting_check_rules_list",
 		"dash0_alerting_check_rules_get",
 		"dash0_synthetic_checks_list",
@@ -367,6 +444,7 @@ func TestProfileReadonly_NoWriteTools(t *testing.T) {
 		"dash0_dashboards_create",
 		"dash0_dashboards_update",
 		"dash0_dashboards_delete",
+		"dash0_dashboards_add_panel",
 		"dash0_alerting_check_rules_create",
 		"dash0_alerting_check_rules_update",
 		"dash0_alerting_check_rules_delete",
@@ -377,6 +455,7 @@ func TestProfileReadonly_NoWriteTools(t *testing.T) {
 		"dash0_sampling_rules_create",
 		"dash0_sampling_rules_update",
 		"dash0_sampling_rules_delete",
+		"dash0_sampling_policy_apply",
 		"dash0_views_create",
 		"dash0_views_update",
 		"dash0_views_delete",
@@ -401,8 +480,8 @@ func TestProfileReadonly_NoWriteTools(t *testing.T) {
 	}
 
 	enabledCount := reg.EnabledCount()
-	if enabledCount != 12 {
-		t.Errorf("readonly profile: EnabledCount() = %d, want 12", enabledCount)
+	if enabledCount != 13 {
+		t.Errorf("readonly profile: EnabledCount() = %d, want 13", enabledCount)
 		t.Logf("Enabled tools: %v", reg.EnabledToolNames())
 	}
 }
@@ -415,6 +494,8 @@ func TestProfileDemo_WorkflowTools(t *testing.T) {
 		"dash0_dashboards_get",
 		"dash0_dashboards_create",
 		"dash0_dashboards_update",
+		"dash0_dashboards_diff",
+		"dash0_dashboards_add_panel",
 		"dash0_alerting_check_rules_list",
 		"dash0_alerting_check_rules_get",
 		"dash0_alerting_check_rules_create",
@@ -461,8 +542,8 @@ func TestProfileDemo_WorkflowTools(t *testing.T) {
 	}
 
 	enabledCount := reg.EnabledCount()
-	if enabledCount != 19 {
-		t.Errorf("demo profile: EnabledCount() = %d, want 19", enabledCount)
+	if enabledCount != 21 {
+		t.Errorf("demo profile: EnabledCount() = %d, want 21", enabledCount)
 		t.Logf("Enabled tools: %v", reg.EnabledToolNames())
 	}
 }
@@ -1,4 +1,5 @@
 // Package samplingrules provides MCP tools for Dash0 sampling rule operations.
 // This package enables creating, retrieving, updating, and deleting sampling rules
-// for controlling trace and log data ingestion.
+// for controlling trace and log data ingestion, and exporting and applying the
+// full set of rules as one ordered policy document with its expected keep rate.
 package samplingrules
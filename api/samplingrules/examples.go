@@ -0,0 +1,113 @@
+package samplingrules
+
+import "github.com/npcomplete777/dash0-mcp/internal/registry"
+
+// Compile-time interface check.
+var _ registry.ExampleProvider = (*Tools)(nil)
+
+// samplingRule returns a Dash0Sampling body with the given conditions.
+func samplingRule(name string, conditions map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"kind":     "Dash0Sampling",
+		"metadata": map[string]interface{}{"name": name},
+		"spec": map[string]interface{}{
+			"enabled":    true,
+			"conditions": conditions,
+		},
+	}
+}
+
+// Examples returns example invocations for the sampling rule tools.
+func (p *Tools) Examples() map[string][]registry.Example {
+	return map[string][]registry.Example{
+		"dash0_sampling_rules_list": {
+			{Title: "List all sampling rules", Arguments: map[string]interface{}{}},
+		},
+		"dash0_sampling_rules_get": {
+			{Title: "Get a sampling rule by origin", Arguments: map[string]interface{}{"origin_or_id": "capture-all-errors"}},
+		},
+		"dash0_sampling_rules_create": {
+			{
+				Title: "Keep every trace with an error",
+				Arguments: map[string]interface{}{
+					"body": samplingRule("capture-all-errors", map[string]interface{}{"kind": "error", "spec": map[string]interface{}{}}),
+				},
+			},
+			{
+				Title: "Keep 10% of traces",
+				Arguments: map[string]interface{}{
+					"body": samplingRule("sample-10-percent", map[string]interface{}{"kind": "probabilistic", "spec": map[string]interface{}{"rate": 0.1}}),
+				},
+			},
+			{
+				Title: "Keep slow requests with an OTTL condition",
+				Arguments: map[string]interface{}{
+					"body": samplingRule("slow-requests", map[string]interface{}{"kind": "ottl", "spec": map[string]interface{}{"ottl": "duration > 1000"}}),
+				},
+			},
+			{
+				Title: "Keep half of the traces with errors",
+				Arguments: map[string]interface{}{
+					"body": samplingRule("sampled-errors", map[string]interface{}{
+						"kind": "and",
+						"spec": map[string]interface{}{
+							"conditions": []interface{}{
+								map[string]interface{}{"kind": "error", "spec": map[string]interface{}{}},
+								map[string]interface{}{"kind": "probabilistic", "spec": map[string]interface{}{"rate": 0.5}},
+							},
+						},
+					}),
+				},
+			},
+		},
+		"dash0_sampling_rules_update": {
+			{
+				Title: "Raise a probabilistic rule to 20%",
+				Arguments: map[string]interface{}{
+					"origin_or_id": "sample-10-percent",
+					"body":         samplingRule("sample-10-percent", map[string]interface{}{"kind": "probabilistic", "spec": map[string]interface{}{"rate": 0.2}}),
+				},
+			},
+		},
+		"dash0_sampling_rules_delete": {
+			{Title: "Delete a sampling rule", Arguments: map[string]interface{}{"origin_or_id": "sample-10-percent"}},
+		},
+		"dash0_sampling_policy_export": {
+			{Title: "Export the sampling policy as YAML", Arguments: map[string]interface{}{}},
+			{Title: "Export the sampling policy as JSON", Arguments: map[string]interface{}{"format": "json"}},
+		},
+		"dash0_sampling_rules_simulate": {
+			{
+				Title: "How much would keeping 10% of traces save",
+				Arguments: map[string]interface{}{
+					"body": samplingRule("sample-10-percent", map[string]interface{}{"kind": "probabilistic", "spec": map[string]interface{}{"rate": 0.1}}),
+				},
+			},
+			{
+				Title: "Keeping only errors of one service over the last day",
+				Arguments: map[string]interface{}{
+					"body":               samplingRule("capture-all-errors", map[string]interface{}{"kind": "error", "spec": map[string]interface{}{}}),
+					"service_name":       "frontend",
+					"time_range_minutes": 1440,
+				},
+			},
+		},
+		"dash0_sampling_policy_apply": {
+			{
+				Title: "Keep all errors and 10% of other traces, removing every other rule",
+				Arguments: map[string]interface{}{
+					"policy": map[string]interface{}{
+						"kind":             PolicyKind,
+						"expectedKeepRate": 0.1,
+						"rules": []interface{}{
+							samplingRule("capture-all-errors", map[string]interface{}{"kind": "error", "spec": map[string]interface{}{}}),
+							samplingRule("sample-10-percent", map[string]interface{}{"kind": "probabilistic", "spec": map[string]interface{}{"rate": 0.1}}),
+						},
+					},
+					"prune":   true,
+					"dry_run": true,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,536 @@
+package samplingrules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/npcomplete777/dash0-mcp/internal/canonical"
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+	"github.com/npcomplete777/dash0-mcp/internal/listing"
+	"github.com/npcomplete777/dash0-mcp/internal/schema"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// PolicyKind is the kind of a sampling policy document.
+	PolicyKind = "Dash0SamplingPolicy"
+
+	// keepRateTolerance is how far a policy's expectedKeepRate may be from
+	// the rate its rules keep, so rates rounded to three decimals still
+	// match.
+	keepRateTolerance = 0.0005
+)
+
+// Policy is the full set of sampling rules of a dataset as one ordered
+// document, so it can be reviewed and versioned as a single artifact.
+type Policy struct {
+	Kind string `json:"kind"`
+	// ExpectedKeepRate is the share of traces the rules are expected to
+	// keep. When set, a policy whose rules keep a different share is
+	// rejected.
+	ExpectedKeepRate *float64 `json:"expectedKeepRate,omitempty"`
+	// Rules are Dash0Sampling rules, in the order they are applied.
+	Rules []map[string]interface{} `json:"rules"`
+}
+
+// KeepRate estimates the share of traces a set of sampling rules keeps.
+//
+// Probabilistic rules sample on the trace ID, so the traces kept by a lower
+// rate are a subset of those kept by a higher one: together, the enabled
+// probabilistic rules keep the largest of their rates, and an and of
+// probabilistic conditions keeps the product of its rates. Rules with an
+// error, OTTL, or mixed condition keep the traces they match on top of that,
+// which depends on the traffic and is not part of Rate. Without enabled
+// rules nothing is sampled away and Rate is 1.
+type KeepRate struct {
+	Rate float64 `json:"rate"`
+	// Conditional are the enabled rules whose traces are kept on top of
+	// Rate.
+	Conditional []string `json:"conditional_rules"`
+	// Disabled are the rules that are turned off.
+	Disabled []string `json:"disabled_rules"`
+}
+
+// estimateKeepRate returns the keep rate of rules.
+func estimateKeepRate(rules []map[string]interface{}) KeepRate {
+	k := KeepRate{Conditional: []string{}, Disabled: []string{}}
+	enabled := 0
+	for _, rule := range rules {
+		spec, _ := rule["spec"].(map[string]interface{})
+		if on, ok := spec["enabled"].(bool); ok && !on {
+			k.Disabled = append(k.Disabled, ruleName(rule))
+			continue
+		}
+		enabled++
+		if rate, ok := probabilisticRate(spec["conditions"]); ok {
+			k.Rate = math.Max(k.Rate, rate)
+		} else {
+			k.Conditional = append(k.Conditional, ruleName(rule))
+		}
+	}
+	if enabled == 0 {
+		k.Rate = 1
+	}
+	return k
+}
+
+// probabilisticRate returns the rate of a probabilistic condition, or of an
+// and made only of probabilistic conditions.
+func probabilisticRate(raw interface{}) (float64, bool) {
+	cond, _ := raw.(map[string]interface{})
+	spec, _ := cond["spec"].(map[string]interface{})
+	switch cond["kind"] {
+	case "probabilistic":
+		return toFloat(spec["rate"])
+	case "and":
+		list, _ := spec["conditions"].([]interface{})
+		if len(list) == 0 {
+			return 0, false
+		}
+		rate := 1.0
+		for _, sub := range list {
+			r, ok := probabilisticRate(sub)
+			if !ok {
+				return 0, false
+			}
+			rate *= r
+		}
+		return rate, true
+	}
+	return 0, false
+}
+
+// toFloat converts a decoded JSON or YAML number to a float64.
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// ruleName returns metadata.name of a rule.
+func ruleName(rule map[string]interface{}) string {
+	meta, _ := rule["metadata"].(map[string]interface{})
+	name, _ := meta["name"].(string)
+	return name
+}
+
+// ruleOrigin returns the origin a rule was created with, or "".
+func ruleOrigin(rule map[string]interface{}) string {
+	meta, _ := rule["metadata"].(map[string]interface{})
+	labels, _ := meta["labels"].(map[string]interface{})
+	for _, v := range []interface{}{labels["dash0.com/origin"], meta["origin"], rule["origin"]} {
+		if s, ok := v.(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// ruleID returns the identifier accepted by the get, update, and delete
+// endpoints (origin or ID).
+func ruleID(rule map[string]interface{}) string {
+	if origin := ruleOrigin(rule); origin != "" {
+		return origin
+	}
+	meta, _ := rule["metadata"].(map[string]interface{})
+	labels, _ := meta["labels"].(map[string]interface{})
+	for _, v := range []interface{}{labels["dash0.com/id"], rule["id"]} {
+		if s, ok := v.(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// fetchRules lists the sampling rules and fetches the full definition of
+// each, keeping the order of the list.
+func (p *Tools) fetchRules(ctx context.Context) ([]map[string]interface{}, *client.ToolResult) {
+	list := p.client.Get(ctx, basePath)
+	if !list.Success {
+		return nil, list
+	}
+	var rules []map[string]interface{}
+	for _, raw := range listing.FromResponse(list.Data).Items {
+		if m, ok := raw.(map[string]interface{}); ok {
+			rules = append(rules, m)
+		}
+	}
+
+	errResult := p.client.Parallel(ctx, len(rules), func(ctx context.Context, i int) *client.ToolResult {
+		id := ruleID(rules[i])
+		if id == "" {
+			// Without an identifier the list entry is all there is.
+			return nil
+		}
+		full := p.client.Get(ctx, basePath+"/"+url.PathEscape(id))
+		if !full.Success {
+			return full
+		}
+		if m, ok := full.Data.(map[string]interface{}); ok {
+			rules[i] = m
+		}
+		return nil
+	})
+	if errResult != nil {
+		return nil, errResult
+	}
+	return rules, nil
+}
+
+// exportPolicy returns the policy of rules, without server-maintained
+// fields and with the keep rate they are expected to have.
+func exportPolicy(rules []map[string]interface{}) (Policy, KeepRate, error) {
+	policy := Policy{Kind: PolicyKind, Rules: make([]map[string]interface{}, 0, len(rules))}
+	for _, rule := range rules {
+		c, err := canonical.Canonicalize(rule)
+		if err != nil {
+			return Policy{}, KeepRate{}, err
+		}
+		m, ok := c.(map[string]interface{})
+		if !ok {
+			return Policy{}, KeepRate{}, errors.New("unexpected sampling rule format")
+		}
+		policy.Rules = append(policy.Rules, m)
+	}
+	keep := estimateKeepRate(policy.Rules)
+	rate := math.Round(keep.Rate*1000) / 1000
+	policy.ExpectedKeepRate = &rate
+	return policy, keep, nil
+}
+
+// encodePolicy renders a policy as indented JSON or YAML.
+func encodePolicy(policy Policy, format string) (string, error) {
+	// Round-trip through JSON so numbers are plain values in both formats.
+	raw, err := json.Marshal(policy)
+	if err != nil {
+		return "", err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return "", err
+	}
+	if format == "yaml" {
+		out, err := yaml.Marshal(doc)
+		return string(out), err
+	}
+	out, err := json.MarshalIndent(doc, "", "  ")
+	return string(out) + "\n", err
+}
+
+// parsePolicy reads a policy given as an object or as a YAML or JSON string,
+// and normalizes and validates each of its rules. Problems are reported as
+// one validation error listing every field.
+func parsePolicy(raw interface{}) (*Policy, []schema.Fix, *client.ToolResult) {
+	if s, ok := raw.(string); ok {
+		if strings.TrimSpace(s) == "" {
+			return nil, nil, client.ErrorResult(http.StatusBadRequest, "policy must not be empty")
+		}
+		var doc interface{}
+		if err := yaml.Unmarshal([]byte(s), &doc); err != nil {
+			return nil, nil, client.ErrorResult(http.StatusBadRequest, fmt.Sprintf("failed to parse policy: %v", err))
+		}
+		raw = doc
+	}
+	if _, ok := raw.(map[string]interface{}); !ok {
+		return nil, nil, client.ErrorResult(http.StatusBadRequest, "policy must be an object or a YAML or JSON document")
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, nil, client.ErrorResult(http.StatusBadRequest, fmt.Sprintf("failed to read policy: %v", err))
+	}
+	var policy Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, nil, client.ErrorResult(http.StatusBadRequest, fmt.Sprintf("policy does not have the %s layout: %v", PolicyKind, err))
+	}
+
+	var problems []client.FieldError
+	if policy.Kind != PolicyKind {
+		problems = append(problems, client.FieldError{Pointer: "/kind", Detail: fmt.Sprintf("must be %q", PolicyKind)})
+	}
+	if len(policy.Rules) == 0 {
+		problems = append(problems, client.FieldError{Pointer: "/rules", Detail: "must list at least one sampling rule"})
+	}
+
+	var fixes []schema.Fix
+	names := map[string]int{}
+	for i, rule := range policy.Rules {
+		prefix := fmt.Sprintf("/rules/%d", i)
+		normalized, ruleFixes := schema.Normalize(schema.KindSamplingRule, rule)
+		for _, f := range ruleFixes {
+			fixes = append(fixes, schema.Fix{Pointer: prefix + f.Pointer, Detail: f.Detail})
+		}
+		if m, ok := normalized.(map[string]interface{}); ok {
+			policy.Rules[i] = m
+		}
+		for _, f := range schema.Validate(schema.KindSamplingRule, policy.Rules[i]) {
+			problems = append(problems, client.FieldError{Pointer: prefix + strings.TrimSuffix(f.Pointer, "/"), Detail: f.Detail})
+		}
+		name := ruleName(policy.Rules[i])
+		if first, ok := names[name]; ok && name != "" {
+			problems = append(problems, client.FieldError{Pointer: prefix + "/metadata/name", Detail: fmt.Sprintf("%q is also the name of rule %d", name, first)})
+		} else {
+			names[name] = i
+		}
+	}
+
+	if len(problems) == 0 && policy.ExpectedKeepRate != nil {
+		keep := estimateKeepRate(policy.Rules)
+		if math.Abs(keep.Rate-*policy.ExpectedKeepRate) > keepRateTolerance {
+			problems = append(problems, client.FieldError{
+				Pointer: "/expectedKeepRate",
+				Detail:  fmt.Sprintf("is %g, but the rules keep %g of traces", *policy.ExpectedKeepRate, math.Round(keep.Rate*1000)/1000),
+			})
+		}
+	}
+
+	if len(problems) > 0 {
+		result := client.ErrorResult(http.StatusBadRequest, "policy is not a valid "+PolicyKind+"; nothing was changed")
+		result.Error.Fields = problems
+		result.Error.Hint = "fix the listed fields; set expectedKeepRate to the new rate when a rate change is intended, or omit it to skip the check"
+		return nil, fixes, result
+	}
+	return &policy, fixes, nil
+}
+
+// Policy change actions.
+const (
+	actionCreate    = "create"
+	actionUpdate    = "update"
+	actionUnchanged = "unchanged"
+	actionDelete    = "delete"
+	actionKeep      = "keep"
+)
+
+// Policy change statuses.
+const (
+	statusApplied = "applied"
+	statusPlanned = "planned"
+	statusFailed  = "failed"
+	statusSkipped = "skipped"
+)
+
+// PolicyChange is what applying a policy does to one sampling rule.
+type PolicyChange struct {
+	Rule   string `json:"rule"`
+	ID     string `json:"id,omitempty"`
+	Action string `json:"action"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	// body is the rule to send for a create or update.
+	body map[string]interface{}
+}
+
+// planPolicy compares a policy with the current rules. Policy rules are
+// matched by origin, or by name when they have none; a matched rule is updated when its name
+// or spec differ. Current rules the policy does not list are deleted when
+// prune is set and kept otherwise.
+func planPolicy(policy *Policy, current []map[string]interface{}, prune bool) []PolicyChange {
+	byOrigin := map[string]int{}
+	byName := map[string]int{}
+	for i, rule := range current {
+		if origin := ruleOrigin(rule); origin != "" {
+			byOrigin[origin] = i
+		}
+		if name := ruleName(rule); name != "" {
+			if _, ok := byName[name]; !ok {
+				byName[name] = i
+			}
+		}
+	}
+
+	matched := make([]bool, len(current))
+	changes := make([]PolicyChange, 0, len(policy.Rules))
+	for _, rule := range policy.Rules {
+		change := PolicyChange{Rule: ruleName(rule), body: rule}
+		i, ok := byOrigin[ruleOrigin(rule)]
+		if ruleOrigin(rule) == "" {
+			i, ok = byName[change.Rule]
+		}
+		if ok && matched[i] {
+			ok = false
+		}
+		switch {
+		case !ok:
+			change.Action = actionCreate
+		case ruleName(current[i]) == change.Rule && canonical.Equal(current[i]["spec"], rule["spec"]):
+			matched[i] = true
+			change.ID = ruleID(current[i])
+			change.Action = actionUnchanged
+		default:
+			matched[i] = true
+			change.ID = ruleID(current[i])
+			change.Action = actionUpdate
+		}
+		changes = append(changes, change)
+	}
+
+	for i, rule := range current {
+		if matched[i] {
+			continue
+		}
+		change := PolicyChange{Rule: ruleName(rule), ID: ruleID(rule), Action: actionKeep}
+		if prune {
+			change.Action = actionDelete
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// applyChange makes one planned change, unless dryRun.
+func (p *Tools) applyChange(ctx context.Context, change *PolicyChange, dryRun bool) {
+	switch change.Action {
+	case actionUnchanged:
+		change.Status = statusSkipped
+		change.Detail = "matches the policy"
+		return
+	case actionKeep:
+		change.Status = statusSkipped
+		change.Detail = "not in the policy; set prune to delete it"
+		return
+	}
+	if (change.Action == actionUpdate || change.Action == actionDelete) && change.ID == "" {
+		change.Status = statusFailed
+		change.Detail = "the current rule has no origin or ID"
+		return
+	}
+	if dryRun {
+		change.Status = statusPlanned
+		return
+	}
+
+	var result *client.ToolResult
+	path := basePath + "/" + url.PathEscape(change.ID)
+	switch change.Action {
+	case actionCreate:
+		result = p.client.Post(ctx, basePath, change.body)
+	case actionUpdate:
+		result = p.client.Put(ctx, path, change.body)
+	case actionDelete:
+		result = p.client.Delete(ctx, path)
+		if _, ok := result.Data.(client.DeleteConfirmation); ok && result.Success {
+			change.Status = statusSkipped
+			change.Detail = "deletes need confirmation; delete it with dash0_sampling_rules_delete"
+			return
+		}
+	}
+	if !result.Success {
+		change.Status = statusFailed
+		change.Detail = errorDetail(result)
+		return
+	}
+	change.Status = statusApplied
+	if change.Action == actionCreate {
+		if m, ok := result.Data.(map[string]interface{}); ok {
+			change.ID = ruleID(m)
+		}
+	}
+}
+
+// errorDetail returns the most specific message of a failed result.
+func errorDetail(result *client.ToolResult) string {
+	if result.Error == nil {
+		return "request failed"
+	}
+	if result.Error.Detail != "" {
+		return result.Error.Detail
+	}
+	return result.Error.Title
+}
+
+// countChanges counts the changes by status.
+func countChanges(changes []PolicyChange) map[string]int {
+	counts := map[string]int{statusApplied: 0, statusPlanned: 0, statusFailed: 0, statusSkipped: 0}
+	for _, c := range changes {
+		counts[c.Status]++
+	}
+	return counts
+}
+
+// formatKeepRate describes a keep rate in one line.
+func formatKeepRate(k KeepRate) string {
+	s := fmt.Sprintf("Expected keep rate **%s** of traces", formatPercent(k.Rate))
+	if len(k.Conditional) > 0 {
+		s += fmt.Sprintf(", plus the traces matched by %s", strings.Join(k.Conditional, ", "))
+	}
+	return s
+}
+
+// formatPercent renders a rate as a percentage.
+func formatPercent(rate float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", rate*100), "0"), ".") + "%"
+}
+
+// conditionSummary describes the condition of a rule in a few words.
+func conditionSummary(raw interface{}) string {
+	cond, _ := raw.(map[string]interface{})
+	spec, _ := cond["spec"].(map[string]interface{})
+	switch kind, _ := cond["kind"].(string); kind {
+	case "probabilistic":
+		if rate, ok := toFloat(spec["rate"]); ok {
+			return "probabilistic " + formatPercent(rate)
+		}
+		return "probabilistic"
+	case "ottl":
+		ottl, _ := spec["ottl"].(string)
+		return "ottl `" + formatter.Truncate(ottl, 60) + "`"
+	case "and":
+		list, _ := spec["conditions"].([]interface{})
+		parts := make([]string, len(list))
+		for i, sub := range list {
+			parts[i] = conditionSummary(sub)
+		}
+		return strings.Join(parts, " and ")
+	default:
+		return kind
+	}
+}
+
+// formatPolicy renders the rules of an exported policy and the document.
+func formatPolicy(policy Policy, keep KeepRate, document, format string) string {
+	rows := make([][]string, len(policy.Rules))
+	for i, rule := range policy.Rules {
+		spec, _ := rule["spec"].(map[string]interface{})
+		enabled := "yes"
+		if on, ok := spec["enabled"].(bool); ok && !on {
+			enabled = "no"
+		}
+		rows[i] = []string{fmt.Sprintf("%d", i+1), ruleName(rule), enabled, conditionSummary(spec["conditions"])}
+	}
+	var sb strings.Builder
+	sb.WriteString(formatter.Table("Sampling Policy", fmt.Sprintf("%d rules · %s", len(policy.Rules), formatKeepRate(keep)),
+		[]string{"#", "Rule", "Enabled", "Condition"}, rows, ""))
+	fmt.Fprintf(&sb, "\n```%s\n%s```\n", format, document)
+	return sb.String()
+}
+
+// formatPolicyApply renders the per-rule report of applying a policy.
+func formatPolicyApply(changes []PolicyChange, keep KeepRate, dryRun bool) string {
+	counts := countChanges(changes)
+	summary := fmt.Sprintf("%d applied, %d failed, %d unchanged or kept", counts[statusApplied], counts[statusFailed], counts[statusSkipped])
+	if dryRun {
+		summary = fmt.Sprintf("%d changes would be made, %d failed, %d unchanged or kept (dry run, nothing changed)",
+			counts[statusPlanned], counts[statusFailed], counts[statusSkipped])
+	}
+	summary += " · " + formatKeepRate(keep)
+
+	rows := make([][]string, len(changes))
+	for i, c := range changes {
+		rows[i] = []string{c.Rule, c.ID, c.Action, c.Status, c.Detail}
+	}
+	return formatter.Table("Sampling Policy Apply", summary, []string{"Rule", "Origin/ID", "Action", "Status", "Detail"}, rows, "")
+}
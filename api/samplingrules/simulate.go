@@ -0,0 +1,315 @@
+package samplingrules
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/api/spans"
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+	"github.com/npcomplete777/dash0-mcp/internal/otlp"
+	"github.com/npcomplete777/dash0-mcp/internal/schema"
+	mcp "github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	defaultSimulateMaxSpans = 5000
+	maxSimulateMaxSpans     = 20000
+	maxSimulateMinutes      = 1440
+
+	// statusCodeError is the OTLP status code of a failed span.
+	statusCodeError = 2
+)
+
+// SimulateSamplingRule returns the dash0_sampling_rules_simulate tool definition.
+func (p *Tools) SimulateSamplingRule() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_sampling_rules_simulate",
+		Description: `Estimate how much a proposed sampling rule would reduce span ingestion, before creating it.
+
+Reads recent spans, groups them into traces, and applies the rule's conditions to each
+trace: error keeps traces with a failed span, probabilistic keeps a share of traces by
+trace ID as the collector does, and and combines them. Reports spans and traces kept
+and dropped, overall and by service. OTTL conditions cannot be evaluated locally.
+
+The spans read are already ingested, so existing sampling rules have sampled them;
+the estimate is relative to the current volume.
+
+Example:
+{
+  "body": {
+    "kind": "Dash0Sampling",
+    "metadata": {"name": "sample-10-percent"},
+    "spec": {"enabled": true, "conditions": {"kind": "probabilistic", "spec": {"rate": 0.1}}}
+  },
+  "time_range_minutes": 60
+}`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"body": map[string]interface{}{
+					"type":        "object",
+					"description": "The proposed sampling rule in Dash0Sampling CRD format, as for dash0_sampling_rules_create.",
+				},
+				"time_range_minutes": map[string]interface{}{
+					"type":        "integer",
+					"description": fmt.Sprintf("Minutes of recent spans to simulate against (default: 60, max: %d)", maxSimulateMinutes),
+				},
+				"service_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Only simulate against the spans of this service",
+				},
+				"max_spans": map[string]interface{}{
+					"type":        "integer",
+					"description": fmt.Sprintf("Max spans to read (default: %d, max: %d)", defaultSimulateMaxSpans, maxSimulateMaxSpans),
+				},
+			},
+			Required: []string{"body"},
+		},
+	}
+}
+
+// ServiceImpact is the estimated effect of a sampling rule on one service.
+type ServiceImpact struct {
+	Service  string  `json:"service"`
+	Spans    int     `json:"spans"`
+	Kept     int     `json:"kept"`
+	Dropped  int     `json:"dropped"`
+	KeepRate float64 `json:"keep_rate"`
+}
+
+// SimulateSamplingRuleHandler handles the dash0_sampling_rules_simulate tool.
+func (p *Tools) SimulateSamplingRuleHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	body, ok := args["body"]
+	if !ok {
+		return client.ErrorResult(400, "body is required")
+	}
+	body, fixes := schema.Normalize(schema.KindSamplingRule, body)
+	if result := schema.Check(schema.KindSamplingRule, body); result != nil {
+		return schema.Report(result, fixes)
+	}
+	rule, _ := body.(map[string]interface{})
+	spec, _ := rule["spec"].(map[string]interface{})
+	keep, err := compileCondition(spec["conditions"])
+	if err != nil {
+		return schema.Report(client.ErrorResult(400, err.Error()), fixes)
+	}
+
+	minutes := 60
+	if m, ok := args["time_range_minutes"].(float64); ok {
+		if m < 0 {
+			return client.ErrorResult(400, "time_range_minutes must not be negative")
+		}
+		if m > 0 {
+			minutes = int(m)
+			if minutes > maxSimulateMinutes {
+				minutes = maxSimulateMinutes
+			}
+		}
+	}
+	maxSpans := defaultSimulateMaxSpans
+	if m, ok := args["max_spans"].(float64); ok {
+		if m < 0 {
+			return client.ErrorResult(400, "max_spans must not be negative")
+		}
+		if m > 0 {
+			maxSpans = int(m)
+			if maxSpans > maxSimulateMaxSpans {
+				maxSpans = maxSimulateMaxSpans
+			}
+		}
+	}
+
+	dataset := ""
+	if ds, ok := args["dataset"].(string); ok && ds != "" {
+		dataset = ds
+	} else {
+		dataset = p.client.GetDataset()
+	}
+
+	now := time.Now().UTC()
+	from := now.Add(-time.Duration(minutes) * time.Minute)
+	req := spans.QuerySpansRequest{
+		Dataset: dataset,
+		TimeRange: otlp.TimeRange{
+			From: from.Format(time.RFC3339),
+			To:   now.Format(time.RFC3339),
+		},
+	}
+	service, _ := args["service_name"].(string)
+	service = strings.TrimSpace(service)
+	if service != "" {
+		req.Filter = []otlp.AttributeFilter{{Key: "service.name", Operator: "is", Value: &otlp.AttributeFilterValue{StringValue: &service}}}
+	}
+
+	ss, sampled, errResult := spans.Fetch(ctx, p.client, req, dataset, maxSpans)
+	if errResult != nil {
+		return errResult
+	}
+
+	// Sampling decides per trace, so every span of a trace shares its fate.
+	traceErrors := make(map[string]bool)
+	for _, s := range ss {
+		traceErrors[s.TraceID] = traceErrors[s.TraceID] || s.StatusCode == statusCodeError
+	}
+	kept := make(map[string]bool, len(traceErrors))
+	tracesKept := 0
+	for traceID, hasError := range traceErrors {
+		if keep(traceID, hasError) {
+			kept[traceID] = true
+			tracesKept++
+		}
+	}
+
+	byService := make(map[string]*ServiceImpact)
+	total := ServiceImpact{Service: "all"}
+	for _, s := range ss {
+		impact, ok := byService[s.ServiceName]
+		if !ok {
+			impact = &ServiceImpact{Service: s.ServiceName}
+			byService[s.ServiceName] = impact
+		}
+		for _, i := range []*ServiceImpact{impact, &total} {
+			i.Spans++
+			if kept[s.TraceID] {
+				i.Kept++
+			} else {
+				i.Dropped++
+			}
+		}
+	}
+	services := make([]ServiceImpact, 0, len(byService))
+	for _, i := range byService {
+		i.KeepRate = impactRate(i.Kept, i.Spans)
+		services = append(services, *i)
+	}
+	// The services losing the most spans first.
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].Dropped != services[j].Dropped {
+			return services[i].Dropped > services[j].Dropped
+		}
+		return services[i].Service < services[j].Service
+	})
+	total.KeepRate = impactRate(total.Kept, total.Spans)
+
+	name := ruleName(rule)
+	return schema.Report(&client.ToolResult{
+		Success: true,
+		Data: map[string]interface{}{
+			"rule":          name,
+			"spans":         total.Spans,
+			"spans_kept":    total.Kept,
+			"spans_dropped": total.Dropped,
+			"keep_rate":     total.KeepRate,
+			"traces":        len(traceErrors),
+			"traces_kept":   tracesKept,
+			"by_service":    services,
+			"sampled":       sampled,
+			"time_range": map[string]string{
+				"from": req.TimeRange.From,
+				"to":   req.TimeRange.To,
+			},
+		},
+		Markdown: formatSimulation(name, spec["conditions"], total, len(traceErrors), tracesKept, services, minutes, sampled),
+	}, fixes)
+}
+
+// compileCondition returns a function reporting whether a sampling condition
+// keeps a trace, given its ID and whether any of its spans failed.
+func compileCondition(raw interface{}) (func(traceID string, hasError bool) bool, error) {
+	cond, _ := raw.(map[string]interface{})
+	spec, _ := cond["spec"].(map[string]interface{})
+	switch kind, _ := cond["kind"].(string); kind {
+	case "error":
+		return func(_ string, hasError bool) bool { return hasError }, nil
+	case "probabilistic":
+		rate, ok := toFloat(spec["rate"])
+		if !ok || rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("probabilistic rate must be a number between 0 and 1")
+		}
+		return func(traceID string, _ bool) bool { return traceRatio(traceID) < rate }, nil
+	case "and":
+		list, _ := spec["conditions"].([]interface{})
+		if len(list) == 0 {
+			return nil, fmt.Errorf("and condition needs at least one condition")
+		}
+		subs := make([]func(string, bool) bool, len(list))
+		for i, sub := range list {
+			f, err := compileCondition(sub)
+			if err != nil {
+				return nil, err
+			}
+			subs[i] = f
+		}
+		return func(traceID string, hasError bool) bool {
+			for _, f := range subs {
+				if !f(traceID, hasError) {
+					return false
+				}
+			}
+			return true
+		}, nil
+	case "ottl":
+		return nil, fmt.Errorf("OTTL conditions cannot be simulated; simulate the error and probabilistic parts of the rule instead")
+	default:
+		return nil, fmt.Errorf("unsupported condition kind %q", kind)
+	}
+}
+
+// traceRatio maps a trace ID to [0, 1) the way trace ID ratio sampling
+// does: by its lowest 56 bits, which W3C trace IDs fill randomly. IDs that
+// are not hex fall back to a hash.
+func traceRatio(traceID string) float64 {
+	if len(traceID) >= 14 {
+		if n, err := strconv.ParseUint(traceID[len(traceID)-14:], 16, 64); err == nil {
+			return float64(n) / float64(uint64(1)<<56)
+		}
+	}
+	h := fnv.New64a()
+	h.Write([]byte(traceID))
+	return float64(h.Sum64()>>8) / float64(uint64(1)<<56)
+}
+
+// impactRate returns kept/total, or 1 when there is nothing to sample.
+func impactRate(kept, total int) float64 {
+	if total == 0 {
+		return 1
+	}
+	return float64(kept) / float64(total)
+}
+
+// formatSimulation renders the estimated impact of a sampling rule.
+func formatSimulation(name string, conditions interface{}, total ServiceImpact, traces, tracesKept int, services []ServiceImpact, minutes int, sampled bool) string {
+	if total.Spans == 0 {
+		return fmt.Sprintf("## Sampling Rule Simulation\n\nNo spans found in the last %d minutes, so there is nothing to simulate against.\n", minutes)
+	}
+
+	summary := fmt.Sprintf("**%s** (%s) would keep **%s** of spans: %d kept, %d dropped | Traces: %d of %d kept | Last %d minutes",
+		name, conditionSummary(conditions), formatPercent(total.KeepRate), total.Kept, total.Dropped, tracesKept, traces, minutes)
+
+	rows := make([][]string, len(services))
+	for i, s := range services {
+		service := s.Service
+		if service == "" {
+			service = "unknown"
+		}
+		rows[i] = []string{
+			formatter.Truncate(service, 40),
+			strconv.Itoa(s.Spans),
+			strconv.Itoa(s.Kept),
+			strconv.Itoa(s.Dropped),
+			formatPercent(s.KeepRate),
+		}
+	}
+
+	footer := "_Estimated from spans already ingested, after the current sampling rules._"
+	if sampled {
+		footer = fmt.Sprintf("_Estimated from the first %d spans of the time range, after the current sampling rules; raise max_spans to read more._", total.Spans)
+	}
+	return formatter.Table("Sampling Rule Simulation", summary, []string{"Service", "Spans", "Kept", "Dropped", "Keep Rate"}, rows, footer)
+}
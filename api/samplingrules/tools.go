@@ -7,7 +7,9 @@ import (
 
 	"github.com/npcomplete777/dash0-mcp/internal/client"
 	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+	"github.com/npcomplete777/dash0-mcp/internal/listing"
 	"github.com/npcomplete777/dash0-mcp/internal/registry"
+	"github.com/npcomplete777/dash0-mcp/internal/schema"
 	mcp "github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -36,17 +38,23 @@ func (p *Tools) Tools() []mcp.Tool {
 		p.CreateSamplingRule(),
 		p.UpdateSamplingRule(),
 		p.DeleteSamplingRule(),
+		p.ExportSamplingPolicy(),
+		p.ApplySamplingPolicy(),
+		p.SimulateSamplingRule(),
 	}
 }
 
 // Handlers returns a map of tool name to handler function.
 func (p *Tools) Handlers() map[string]func(context.Context, map[string]interface{}) *client.ToolResult {
 	return map[string]func(context.Context, map[string]interface{}) *client.ToolResult{
-		"dash0_sampling_rules_list":   p.ListSamplingRulesHandler,
-		"dash0_sampling_rules_get":    p.GetSamplingRuleHandler,
-		"dash0_sampling_rules_create": p.CreateSamplingRuleHandler,
-		"dash0_sampling_rules_update": p.UpdateSamplingRuleHandler,
-		"dash0_sampling_rules_delete": p.DeleteSamplingRuleHandler,
+		"dash0_sampling_rules_list":     p.ListSamplingRulesHandler,
+		"dash0_sampling_rules_get":      p.GetSamplingRuleHandler,
+		"dash0_sampling_rules_create":   p.CreateSamplingRuleHandler,
+		"dash0_sampling_rules_update":   p.UpdateSamplingRuleHandler,
+		"dash0_sampling_rules_delete":   p.DeleteSamplingRuleHandler,
+		"dash0_sampling_policy_export":  p.ExportSamplingPolicyHandler,
+		"dash0_sampling_policy_apply":   p.ApplySamplingPolicyHandler,
+		"dash0_sampling_rules_simulate": p.SimulateSamplingRuleHandler,
 	}
 }
 
@@ -54,21 +62,24 @@ func (p *Tools) Handlers() map[string]func(context.Context, map[string]interface
 func (p *Tools) ListSamplingRules() mcp.Tool {
 	return mcp.Tool{
 		Name:        "dash0_sampling_rules_list",
-		Description: "List all sampling rules in Dash0. Sampling rules control which traces and logs are ingested, helping manage data volume and costs.",
+		Description: "List all sampling rules in Dash0. Sampling rules control which traces and logs are ingested, helping manage data volume and costs. Rules have no priority: a trace is kept when any enabled rule keeps it, so no rule overrides another and their order does not matter.",
 		InputSchema: mcp.ToolInputSchema{
-			Type:       "object",
-			Properties: map[string]interface{}{},
+			Type: "object",
+			Properties: map[string]interface{}{
+				"page_token": listing.PageTokenProperty(),
+				"summary":    listing.SummaryProperty(),
+			},
 		},
 	}
 }
 
 // ListSamplingRulesHandler handles the dash0_sampling_rules_list tool.
 func (p *Tools) ListSamplingRulesHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
-	result := p.client.Get(ctx, basePath)
+	result := p.client.Get(ctx, listing.Path(basePath, args))
 	if result.Success {
 		result.Markdown = formatter.FormatListResponse("Sampling Rules", result.Data)
 	}
-	return result
+	return listing.Summarize(listing.Adapt(result), args, "Sampling Rules")
 }
 
 // GetSamplingRule returns the dash0_sampling_rules_get tool definition.
@@ -176,7 +187,7 @@ NOTE: Use "rate" (0.0-1.0), NOT "probability" or "percentage"!
 }`,
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
-			Properties: map[string]interface{}{
+			Properties: schema.WithBodyYAML(map[string]interface{}{
 				"body": map[string]interface{}{
 					"type":        "object",
 					"description": "The sampling rule configuration in Dash0Sampling CRD format.",
@@ -227,20 +238,23 @@ NOTE: Use "rate" (0.0-1.0), NOT "probability" or "percentage"!
 					},
 					"required": []interface{}{"kind", "metadata", "spec"},
 				},
-			},
-			Required: []string{"body"},
+			}),
 		},
 	}
 }
 
 // CreateSamplingRuleHandler handles the dash0_sampling_rules_create tool.
 func (p *Tools) CreateSamplingRuleHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
-	body, ok := args["body"]
-	if !ok {
-		return client.ErrorResult(400, "body is required")
+	body, errResult := schema.Body(args)
+	if errResult != nil {
+		return errResult
+	}
+	body, fixes := schema.Normalize(schema.KindSamplingRule, body)
+	if result := schema.Check(schema.KindSamplingRule, body); result != nil {
+		return schema.Report(result, fixes)
 	}
 
-	return p.client.Post(ctx, basePath, body)
+	return schema.Report(p.client.Post(ctx, basePath, body), fixes)
 }
 
 // UpdateSamplingRule returns the dash0_sampling_rules_update tool definition.
@@ -265,7 +279,7 @@ The body should follow the same Dash0Sampling CRD format as create:
 Remember: Use "rate" (0.0-1.0) for probabilistic sampling, NOT "probability"!`,
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
-			Properties: map[string]interface{}{
+			Properties: schema.WithBodyYAML(map[string]interface{}{
 				"origin_or_id": map[string]interface{}{
 					"type":        "string",
 					"description": "The origin or ID of the sampling rule to update.",
@@ -274,8 +288,8 @@ Remember: Use "rate" (0.0-1.0) for probabilistic sampling, NOT "probability"!`,
 					"type":        "object",
 					"description": "The updated sampling rule configuration in Dash0Sampling CRD format with conditions.kind and conditions.spec.",
 				},
-			},
-			Required: []string{"origin_or_id", "body"},
+			}),
+			Required: []string{"origin_or_id"},
 		},
 	}
 }
@@ -287,13 +301,17 @@ func (p *Tools) UpdateSamplingRuleHandler(ctx context.Context, args map[string]i
 		return client.ErrorResult(400, "origin_or_id is required")
 	}
 
-	body, ok := args["body"]
-	if !ok {
-		return client.ErrorResult(400, "body is required")
+	body, errResult := schema.Body(args)
+	if errResult != nil {
+		return errResult
+	}
+	body, fixes := schema.Normalize(schema.KindSamplingRule, body)
+	if result := schema.Check(schema.KindSamplingRule, body); result != nil {
+		return schema.Report(result, fixes)
 	}
 
 	path := fmt.Sprintf(basePath+"/%s", url.PathEscape(originOrID))
-	return p.client.Put(ctx, path, body)
+	return schema.Report(p.client.Put(ctx, path, body), fixes)
 }
 
 // DeleteSamplingRule returns the dash0_sampling_rules_delete tool definition.
@@ -325,11 +343,138 @@ func (p *Tools) DeleteSamplingRuleHandler(ctx context.Context, args map[string]i
 	return p.client.Delete(ctx, path)
 }
 
+// ExportSamplingPolicy returns the dash0_sampling_policy_export tool definition.
+func (p *Tools) ExportSamplingPolicy() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_sampling_policy_export",
+		Description: `Export all sampling rules as one ordered policy document, to review data-volume policy as a whole or keep it in version control.
+
+The document has kind "Dash0SamplingPolicy", the rules in their current order without server-maintained fields, and expectedKeepRate: the share of traces the rules keep. Probabilistic rules sample on the trace ID, so the enabled probabilistic rules together keep the largest of their rates; rules with error or OTTL conditions keep the traces they match on top of that and are listed separately.
+
+Apply an edited document with dash0_sampling_policy_apply.`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"format": map[string]interface{}{
+					"type":        "string",
+					"description": "Format of the policy document (default: yaml).",
+					"enum":        []string{"yaml", "json"},
+				},
+			},
+		},
+	}
+}
+
+// ExportSamplingPolicyHandler handles the dash0_sampling_policy_export tool.
+func (p *Tools) ExportSamplingPolicyHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	format := "yaml"
+	if f, ok := args["format"].(string); ok && f != "" {
+		if f != "yaml" && f != "json" {
+			return client.ErrorResult(400, "format must be yaml or json")
+		}
+		format = f
+	}
+
+	rules, errResult := p.fetchRules(ctx)
+	if errResult != nil {
+		return errResult
+	}
+	policy, keep, err := exportPolicy(rules)
+	if err != nil {
+		return client.ErrorResult(500, fmt.Sprintf("failed to export sampling policy: %v", err))
+	}
+	document, err := encodePolicy(policy, format)
+	if err != nil {
+		return client.ErrorResult(500, fmt.Sprintf("failed to encode sampling policy: %v", err))
+	}
+
+	return &client.ToolResult{
+		Success: true,
+		Data: map[string]interface{}{
+			"policy":    policy,
+			"keep_rate": keep,
+			"format":    format,
+			"document":  document,
+		},
+		Markdown: formatPolicy(policy, keep, document, format),
+	}
+}
+
+// ApplySamplingPolicy returns the dash0_sampling_policy_apply tool definition.
+func (p *Tools) ApplySamplingPolicy() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_sampling_policy_apply",
+		Description: `Apply a sampling policy document, as exported by dash0_sampling_policy_export, so the sampling rules match it.
+
+Every rule is validated before anything is changed. When the policy sets expectedKeepRate, the share of traces its rules keep must match it (to 0.0005), so an edit that changes the data volume by accident is rejected; update expectedKeepRate along with an intended rate change.
+
+Rules are matched to the current rules by origin, or by name when they have none. Matched rules whose name or spec differ are updated, the others are created, in document order. Current rules the policy does not list are kept unless prune is true, which deletes them.
+
+Set dry_run to true to see the changes without making them.`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"policy": map[string]interface{}{
+					"type":        []string{"object", "string"},
+					"description": "The Dash0SamplingPolicy document, as an object or as a YAML or JSON string: {kind: Dash0SamplingPolicy, expectedKeepRate: 0.1, rules: [Dash0Sampling rules]}.",
+				},
+				"prune": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Delete the current rules the policy does not list (default: false).",
+				},
+			},
+			Required: []string{"policy"},
+		},
+	}
+}
+
+// ApplySamplingPolicyHandler handles the dash0_sampling_policy_apply tool.
+func (p *Tools) ApplySamplingPolicyHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	raw, ok := args["policy"]
+	if !ok || raw == nil {
+		return client.ErrorResult(400, "policy is required")
+	}
+	prune, _ := args["prune"].(bool)
+	dryRun, _ := args["dry_run"].(bool)
+	dryRun = dryRun || p.client.DryRun(ctx)
+
+	policy, fixes, errResult := parsePolicy(raw)
+	if errResult != nil {
+		return schema.Report(errResult, fixes)
+	}
+	current, errResult := p.fetchRules(ctx)
+	if errResult != nil {
+		return errResult
+	}
+
+	changes := planPolicy(policy, current, prune)
+	for i := range changes {
+		p.applyChange(ctx, &changes[i], dryRun)
+	}
+
+	keep := estimateKeepRate(policy.Rules)
+	counts := countChanges(changes)
+	return schema.Report(&client.ToolResult{
+		Success: true,
+		Data: map[string]interface{}{
+			"applied":   counts[statusApplied],
+			"planned":   counts[statusPlanned],
+			"failed":    counts[statusFailed],
+			"skipped":   counts[statusSkipped],
+			"changes":   changes,
+			"keep_rate": keep,
+			"dry_run":   dryRun,
+		},
+		Markdown: formatPolicyApply(changes, keep, dryRun),
+	}, fixes)
+}
+
 // Register registers all sampling rules tools with the registry.
 func Register(reg *registry.Registry, c *client.Client) {
 	p := New(c)
 	for _, tool := range p.Tools() {
 		handler := p.Handlers()[tool.Name]
 		reg.Register(tool, handler)
+		reg.AddExamples(tool.Name, p.Examples()[tool.Name]...)
 	}
 }
@@ -3,6 +3,8 @@ package samplingrules
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -26,16 +28,19 @@ func TestTools(t *testing.T) {
 	pkg := New(&client.Client{})
 	tools := pkg.Tools()
 
-	if len(tools) != 5 {
-		t.Errorf("Tools() returned %d tools, expected 5", len(tools))
+	if len(tools) != 8 {
+		t.Errorf("Tools() returned %d tools, expected 8", len(tools))
 	}
 
 	expectedNames := map[string]bool{
-		"dash0_sampling_rules_list":   false,
-		"dash0_sampling_rules_get":    false,
-		"dash0_sampling_rules_create": false,
-		"dash0_sampling_rules_update": false,
-		"dash0_sampling_rules_delete": false,
+		"dash0_sampling_rules_list":     false,
+		"dash0_sampling_rules_get":      false,
+		"dash0_sampling_rules_create":   false,
+		"dash0_sampling_rules_update":   false,
+		"dash0_sampling_rules_delete":   false,
+		"dash0_sampling_policy_export":  false,
+		"dash0_sampling_policy_apply":   false,
+		"dash0_sampling_rules_simulate": false,
 	}
 
 	for _, tool := range tools {
@@ -62,6 +67,9 @@ func TestHandlers(t *testing.T) {
 		"dash0_sampling_rules_create",
 		"dash0_sampling_rules_update",
 		"dash0_sampling_rules_delete",
+		"dash0_sampling_policy_export",
+		"dash0_sampling_policy_apply",
+		"dash0_sampling_rules_simulate",
 	}
 
 	if len(handlers) != len(expectedHandlers) {
@@ -230,9 +238,12 @@ func TestCreateSamplingRuleToolDefinition(t *testing.T) {
 		t.Error("CreateSamplingRule() description should mention 'rate' for probabilistic sampling")
 	}
 
-	// Should require body
-	if len(tool.InputSchema.Required) != 1 || tool.InputSchema.Required[0] != "body" {
-		t.Error("CreateSamplingRule() should require 'body'")
+	// Takes body or body_yaml, so neither is required
+	if len(tool.InputSchema.Required) != 0 {
+		t.Errorf("CreateSamplingRule() Required = %v, expected none", tool.InputSchema.Required)
+	}
+	if _, ok := tool.InputSchema.Properties["body_yaml"]; !ok {
+		t.Error("CreateSamplingRule() should accept 'body_yaml'")
 	}
 
 	// Body should have properties for kind, metadata, spec
@@ -401,17 +412,12 @@ func TestUpdateSamplingRuleToolDefinition(t *testing.T) {
 		t.Error("UpdateSamplingRule() description should mention 'rate'")
 	}
 
-	// Should require origin_or_id and body
-	if len(tool.InputSchema.Required) != 2 {
-		t.Error("UpdateSamplingRule() should require 2 parameters")
-	}
-
-	required := make(map[string]bool)
-	for _, r := range tool.InputSchema.Required {
-		required[r] = true
+	// Should require origin_or_id; the body comes from body or body_yaml
+	if len(tool.InputSchema.Required) != 1 || tool.InputSchema.Required[0] != "origin_or_id" {
+		t.Errorf("UpdateSamplingRule() Required = %v, expected [origin_or_id]", tool.InputSchema.Required)
 	}
-	if !required["origin_or_id"] || !required["body"] {
-		t.Error("UpdateSamplingRule() should require origin_or_id and body")
+	if _, ok := tool.InputSchema.Properties["body_yaml"]; !ok {
+		t.Error("UpdateSamplingRule() should accept 'body_yaml'")
 	}
 }
 
@@ -578,9 +584,10 @@ func TestToolNamingConvention(t *testing.T) {
 	tools := pkg.Tools()
 
 	for _, tool := range tools {
-		// All sampling rule tools should start with dash0_sampling_rules_
-		if !strings.HasPrefix(tool.Name, "dash0_sampling_rules_") {
-			t.Errorf("Tool %s does not follow naming convention dash0_sampling_rules_*", tool.Name)
+		// Sampling rule tools start with dash0_sampling_rules_, policy tools
+		// with dash0_sampling_policy_
+		if !strings.HasPrefix(tool.Name, "dash0_sampling_rules_") && !strings.HasPrefix(tool.Name, "dash0_sampling_policy_") {
+			t.Errorf("Tool %s does not follow naming convention dash0_sampling_rules_* or dash0_sampling_policy_*", tool.Name)
 		}
 
 		// Should use underscores, not hyphens
@@ -667,3 +674,332 @@ func TestSamplingRuleConditionsSchema(t *testing.T) {
 		t.Error("conditions should have 'spec' property")
 	}
 }
+
+func TestEstimateKeepRate(t *testing.T) {
+	probabilistic := func(rate float64) map[string]interface{} {
+		return map[string]interface{}{"kind": "probabilistic", "spec": map[string]interface{}{"rate": rate}}
+	}
+	errorsOnly := map[string]interface{}{"kind": "error", "spec": map[string]interface{}{}}
+	disabled := samplingRule("off", probabilistic(0.9))
+	disabled["spec"].(map[string]interface{})["enabled"] = false
+
+	keep := estimateKeepRate([]map[string]interface{}{
+		samplingRule("errors", errorsOnly),
+		samplingRule("ten", probabilistic(0.1)),
+		samplingRule("quarter-of-half", map[string]interface{}{"kind": "and", "spec": map[string]interface{}{
+			"conditions": []interface{}{probabilistic(0.5), probabilistic(0.25)},
+		}}),
+		samplingRule("half-of-errors", map[string]interface{}{"kind": "and", "spec": map[string]interface{}{
+			"conditions": []interface{}{errorsOnly, probabilistic(0.5)},
+		}}),
+		disabled,
+	})
+	if keep.Rate != 0.125 {
+		t.Errorf("Rate = %v, want the largest probabilistic rate 0.125", keep.Rate)
+	}
+	if strings.Join(keep.Conditional, ",") != "errors,half-of-errors" || strings.Join(keep.Disabled, ",") != "off" {
+		t.Errorf("Conditional = %v, Disabled = %v", keep.Conditional, keep.Disabled)
+	}
+
+	if keep := estimateKeepRate([]map[string]interface{}{disabled}); keep.Rate != 1 {
+		t.Errorf("without enabled rules Rate = %v, want 1", keep.Rate)
+	}
+	if keep := estimateKeepRate([]map[string]interface{}{samplingRule("errors", errorsOnly)}); keep.Rate != 0 {
+		t.Errorf("with only conditional rules Rate = %v, want 0", keep.Rate)
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	policy, fixes, errResult := parsePolicy(`
+kind: Dash0SamplingPolicy
+expectedKeepRate: 0.1
+rules:
+  - kind: Dash0Sampling
+    metadata: {name: sample-10-percent}
+    spec:
+      enabled: true
+      conditions: {kind: probabilistic, spec: {probability: 10}}
+`)
+	if errResult != nil {
+		t.Fatalf("parsePolicy() error = %+v", errResult.Error)
+	}
+	if len(policy.Rules) != 1 || len(fixes) == 0 || !strings.HasPrefix(fixes[0].Pointer, "/rules/0/") {
+		t.Errorf("parsePolicy() = %+v, fixes %+v; want the rule normalized", policy, fixes)
+	}
+
+	tests := map[string]struct {
+		policy  interface{}
+		pointer string
+	}{
+		"wrong kind": {map[string]interface{}{"kind": "Dash0Sampling", "rules": []interface{}{samplingRule("a", map[string]interface{}{"kind": "error", "spec": map[string]interface{}{}})}}, "/kind"},
+		"no rules":   {map[string]interface{}{"kind": PolicyKind}, "/rules"},
+		"bad rule":   {map[string]interface{}{"kind": PolicyKind, "rules": []interface{}{map[string]interface{}{"kind": "Dash0Sampling"}}}, "/rules/0"},
+		"duplicate name": {map[string]interface{}{"kind": PolicyKind, "rules": []interface{}{
+			samplingRule("a", map[string]interface{}{"kind": "error", "spec": map[string]interface{}{}}),
+			samplingRule("a", map[string]interface{}{"kind": "error", "spec": map[string]interface{}{}}),
+		}}, "/rules/1/metadata/name"},
+		"keep rate mismatch": {map[string]interface{}{"kind": PolicyKind, "expectedKeepRate": 0.05, "rules": []interface{}{
+			samplingRule("ten", map[string]interface{}{"kind": "probabilistic", "spec": map[string]interface{}{"rate": 0.1}}),
+		}}, "/expectedKeepRate"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, _, errResult := parsePolicy(tt.policy)
+			if errResult == nil || errResult.Error.StatusCode != 400 {
+				t.Fatalf("parsePolicy() = %+v, want a 400", errResult)
+			}
+			found := false
+			for _, f := range errResult.Error.Fields {
+				found = found || strings.HasPrefix(f.Pointer, tt.pointer)
+			}
+			if !found {
+				t.Errorf("fields = %+v, want one at %s", errResult.Error.Fields, tt.pointer)
+			}
+		})
+	}
+
+	if _, _, errResult := parsePolicy("kind: [unclosed"); errResult == nil || !strings.Contains(errResult.Error.Detail, "failed to parse policy") {
+		t.Errorf("invalid YAML: %+v", errResult)
+	}
+}
+
+// policyServer serves the given sampling rules and records the writes made
+// to them.
+func policyServer(t *testing.T, rules map[string]map[string]interface{}, order []string, writes *[]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			*writes = append(*writes, r.Method+" "+r.URL.Path)
+			json.NewEncoder(w).Encode(map[string]interface{}{"metadata": map[string]interface{}{"labels": map[string]interface{}{"dash0.com/origin": "created"}}})
+			return
+		}
+		if r.URL.Path == "/api/sampling-rules" {
+			list := []interface{}{}
+			for _, origin := range order {
+				list = append(list, map[string]interface{}{"origin": origin})
+			}
+			json.NewEncoder(w).Encode(list)
+			return
+		}
+		rule, ok := rules[strings.TrimPrefix(r.URL.Path, "/api/sampling-rules/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(rule)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// storedRule returns a sampling rule as the API returns it.
+func storedRule(origin, name string, conditions map[string]interface{}) map[string]interface{} {
+	rule := samplingRule(name, conditions)
+	rule["metadata"] = map[string]interface{}{
+		"name":   name,
+		"labels": map[string]interface{}{"dash0.com/origin": origin, "dash0.com/id": "id-" + origin, "dash0.com/version": "3"},
+	}
+	return rule
+}
+
+func TestExportSamplingPolicyHandler(t *testing.T) {
+	rules := map[string]map[string]interface{}{
+		"errors": storedRule("errors", "capture-all-errors", map[string]interface{}{"kind": "error", "spec": map[string]interface{}{}}),
+		"ten":    storedRule("ten", "sample-10-percent", map[string]interface{}{"kind": "probabilistic", "spec": map[string]interface{}{"rate": 0.1}}),
+	}
+	var writes []string
+	server := policyServer(t, rules, []string{"ten", "errors"}, &writes)
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+
+	result := pkg.ExportSamplingPolicyHandler(context.Background(), map[string]interface{}{})
+	if !result.Success {
+		t.Fatalf("ExportSamplingPolicyHandler() error = %+v", result.Error)
+	}
+	data := result.Data.(map[string]interface{})
+	policy := data["policy"].(Policy)
+	if len(policy.Rules) != 2 || ruleName(policy.Rules[0]) != "sample-10-percent" || *policy.ExpectedKeepRate != 0.1 {
+		t.Errorf("policy = %+v, want both rules in list order and a keep rate of 0.1", policy)
+	}
+	document := data["document"].(string)
+	if !strings.Contains(document, "kind: Dash0SamplingPolicy") || strings.Contains(document, "dash0.com/version") || !strings.Contains(document, "dash0.com/origin: ten") {
+		t.Errorf("document =\n%s\nwant YAML with origins and without server fields", document)
+	}
+	if !strings.Contains(result.Markdown, "Expected keep rate **10%**") || !strings.Contains(result.Markdown, "plus the traces matched by capture-all-errors") {
+		t.Errorf("markdown:\n%s", result.Markdown)
+	}
+
+	// The exported document applies without changes
+	applied := pkg.ApplySamplingPolicyHandler(context.Background(), map[string]interface{}{"policy": document})
+	if !applied.Success || applied.Data.(map[string]interface{})["skipped"] != 2 || len(writes) != 0 {
+		t.Errorf("applying the export: %+v, writes %v; want 2 unchanged rules", applied.Data, writes)
+	}
+
+	if result := pkg.ExportSamplingPolicyHandler(context.Background(), map[string]interface{}{"format": "xml"}); result.Success {
+		t.Error("format xml was accepted")
+	}
+}
+
+func TestApplySamplingPolicyHandler(t *testing.T) {
+	rules := map[string]map[string]interface{}{
+		"errors": storedRule("errors", "capture-all-errors", map[string]interface{}{"kind": "error", "spec": map[string]interface{}{}}),
+		"ten":    storedRule("ten", "sample-10-percent", map[string]interface{}{"kind": "probabilistic", "spec": map[string]interface{}{"rate": 0.1}}),
+		"old":    storedRule("old", "legacy", map[string]interface{}{"kind": "ottl", "spec": map[string]interface{}{"ottl": "true"}}),
+	}
+	policy := map[string]interface{}{
+		"kind":             PolicyKind,
+		"expectedKeepRate": 0.2,
+		"rules": []interface{}{
+			samplingRule("capture-all-errors", map[string]interface{}{"kind": "error", "spec": map[string]interface{}{}}),
+			samplingRule("sample-10-percent", map[string]interface{}{"kind": "probabilistic", "spec": map[string]interface{}{"rate": 0.2}}),
+			samplingRule("slow-requests", map[string]interface{}{"kind": "ottl", "spec": map[string]interface{}{"ottl": "duration > 1000"}}),
+		},
+	}
+
+	tests := []struct {
+		name       string
+		args       map[string]interface{}
+		wantWrites []string
+		wantAction map[string]string
+	}{
+		{
+			name:       "apply",
+			args:       map[string]interface{}{"policy": policy},
+			wantWrites: []string{"PUT /api/sampling-rules/ten", "POST /api/sampling-rules"},
+			wantAction: map[string]string{"capture-all-errors": actionUnchanged, "sample-10-percent": actionUpdate, "slow-requests": actionCreate, "legacy": actionKeep},
+		},
+		{
+			name:       "prune",
+			args:       map[string]interface{}{"policy": policy, "prune": true},
+			wantWrites: []string{"PUT /api/sampling-rules/ten", "POST /api/sampling-rules", "DELETE /api/sampling-rules/old"},
+			wantAction: map[string]string{"legacy": actionDelete},
+		},
+		{
+			name:       "dry run",
+			args:       map[string]interface{}{"policy": policy, "prune": true, "dry_run": true},
+			wantAction: map[string]string{"sample-10-percent": actionUpdate, "legacy": actionDelete},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var writes []string
+			server := policyServer(t, rules, []string{"errors", "ten", "old"}, &writes)
+			pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+
+			result := pkg.ApplySamplingPolicyHandler(context.Background(), tt.args)
+			if !result.Success {
+				t.Fatalf("ApplySamplingPolicyHandler() error = %+v", result.Error)
+			}
+			if strings.Join(writes, ", ") != strings.Join(tt.wantWrites, ", ") {
+				t.Errorf("writes = %v, want %v", writes, tt.wantWrites)
+			}
+			changes := result.Data.(map[string]interface{})["changes"].([]PolicyChange)
+			for _, c := range changes {
+				if want, ok := tt.wantAction[c.Rule]; ok && c.Action != want {
+					t.Errorf("%s: action = %s, want %s", c.Rule, c.Action, want)
+				}
+				if c.Status == statusFailed {
+					t.Errorf("%s failed: %s", c.Rule, c.Detail)
+				}
+			}
+		})
+	}
+
+	var writes []string
+	server := policyServer(t, rules, []string{"errors", "ten", "old"}, &writes)
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	mismatch := map[string]interface{}{"kind": PolicyKind, "expectedKeepRate": 0.1, "rules": policy["rules"]}
+	result := pkg.ApplySamplingPolicyHandler(context.Background(), map[string]interface{}{"policy": mismatch})
+	if result.Success || len(writes) != 0 || !strings.Contains(result.Error.Fields[0].Detail, "the rules keep 0.2") {
+		t.Errorf("keep rate mismatch: %+v, writes %v", result.Error, writes)
+	}
+	if result := pkg.ApplySamplingPolicyHandler(context.Background(), map[string]interface{}{}); result.Success {
+		t.Error("missing policy was accepted")
+	}
+}
+
+// simulationSpans returns 100 two-span traces whose trace IDs spread evenly
+// over the sampling ratio: trace i samples at (i+0.5)%. Traces 0-49 are
+// cart's, 50-99 ad's, and traces 60 and 95 failed.
+func simulationSpans() map[string]interface{} {
+	bySvc := map[string][]interface{}{}
+	for i := 0; i < 100; i++ {
+		var ratio big.Int
+		ratio.Mul(big.NewInt(int64(2*i+1)), new(big.Int).Lsh(big.NewInt(1), 56))
+		ratio.Div(&ratio, big.NewInt(200))
+		traceID := fmt.Sprintf("%018x%014x", 0, ratio.Uint64())
+		svc := "cart"
+		if i >= 50 {
+			svc = "ad"
+		}
+		for j := 0; j < 2; j++ {
+			status := map[string]interface{}{}
+			if j == 1 && (i == 60 || i == 95) {
+				status["code"] = float64(2)
+			}
+			bySvc[svc] = append(bySvc[svc], map[string]interface{}{
+				"traceId": traceID,
+				"spanId":  fmt.Sprintf("%d-%d", i, j),
+				"name":    "GET /",
+				"status":  status,
+			})
+		}
+	}
+	var resourceSpans []interface{}
+	for _, svc := range []string{"cart", "ad"} {
+		resourceSpans = append(resourceSpans, map[string]interface{}{
+			"resource": map[string]interface{}{
+				"attributes": []interface{}{
+					map[string]interface{}{"key": "service.name", "value": map[string]interface{}{"stringValue": svc}},
+				},
+			},
+			"scopeSpans": []interface{}{map[string]interface{}{"spans": bySvc[svc]}},
+		})
+	}
+	return map[string]interface{}{"resourceSpans": resourceSpans}
+}
+
+func TestSimulateSamplingRuleHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/spans" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(simulationSpans())
+	}))
+	defer server.Close()
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+
+	probabilistic := samplingRule("ten", map[string]interface{}{"kind": "probabilistic", "spec": map[string]interface{}{"rate": 0.1}})
+	result := pkg.SimulateSamplingRuleHandler(context.Background(), map[string]interface{}{"body": probabilistic})
+	if !result.Success {
+		t.Fatalf("SimulateSamplingRuleHandler failed: %v", result.Error)
+	}
+	data := result.Data.(map[string]interface{})
+	if data["spans"] != 200 || data["spans_kept"] != 20 || data["traces"] != 100 || data["traces_kept"] != 10 {
+		t.Errorf("data = %+v, expected 10 of 100 traces kept", data)
+	}
+	services := data["by_service"].([]ServiceImpact)
+	if len(services) != 2 || services[0] != (ServiceImpact{Service: "ad", Spans: 100, Dropped: 100}) ||
+		services[1] != (ServiceImpact{Service: "cart", Spans: 100, Kept: 20, Dropped: 80, KeepRate: 0.2}) {
+		t.Errorf("by_service = %+v", services)
+	}
+	if !strings.Contains(result.Markdown, "would keep **10%** of spans") {
+		t.Errorf("Markdown = %s", result.Markdown)
+	}
+
+	errorsOnly := samplingRule("errors", map[string]interface{}{"kind": "error", "spec": map[string]interface{}{}})
+	result = pkg.SimulateSamplingRuleHandler(context.Background(), map[string]interface{}{"body": errorsOnly})
+	if data := result.Data.(map[string]interface{}); data["spans_kept"] != 4 || data["traces_kept"] != 2 {
+		t.Errorf("data = %+v, expected the 2 failed traces kept whole", data)
+	}
+
+	ottl := samplingRule("slow", map[string]interface{}{"kind": "ottl", "spec": map[string]interface{}{"ottl": "duration > 1000"}})
+	for _, args := range []map[string]interface{}{
+		{},
+		{"body": ottl},
+		{"body": probabilistic, "max_spans": float64(-1)},
+	} {
+		if bad := pkg.SimulateSamplingRuleHandler(context.Background(), args); bad.Success {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
@@ -1,3 +1,5 @@
 // Package spans provides MCP tools for Dash0 Span data operations.
-// This package enables sending and retrieving OTLP spans for distributed tracing.
+// This package enables sending and retrieving OTLP spans for distributed tracing,
+// and computes duration percentiles, error rates, and duration histograms per
+// group of spans.
 package spans
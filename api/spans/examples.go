@@ -0,0 +1,82 @@
+package spans
+
+import "github.com/npcomplete777/dash0-mcp/internal/registry"
+
+// Compile-time interface check.
+var _ registry.ExampleProvider = (*Tools)(nil)
+
+// Examples returns example invocations for the span tools.
+func (p *Tools) Examples() map[string][]registry.Example {
+	return map[string][]registry.Example{
+		"dash0_spans_query": {
+			{Title: "Spans for a service", Arguments: map[string]interface{}{"service_name": "cart"}},
+			{Title: "Error spans", Arguments: map[string]interface{}{"error_only": true}},
+			{Title: "Slow POST requests", Arguments: map[string]interface{}{"http_method": "POST", "min_duration_ms": 1000}},
+			{
+				Title: "Spans from pods with a name prefix",
+				Arguments: map[string]interface{}{
+					"attribute_filters": []interface{}{
+						map[string]interface{}{"key": "k8s.pod.name", "operator": "starts_with", "value": "cart-"},
+					},
+				},
+			},
+			{Title: "Order endpoints by regex", Arguments: map[string]interface{}{"span_name": "^(GET|POST) /api/orders", "span_name_match": "regex"}},
+			{Title: "Spans of a Kubernetes deployment", Arguments: map[string]interface{}{"k8s_namespace": "shop", "k8s_deployment_name": "cart"}},
+			{Title: "Spans of a trace", Arguments: map[string]interface{}{"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736"}},
+			{Title: "The 10 slowest spans of a service", Arguments: map[string]interface{}{"service_name": "cart", "order_by": "duration", "limit": 10}},
+			{Title: "Latency distribution per route", Arguments: map[string]interface{}{"service_name": "cart", "output": "histogram", "histogram_group_by": "route"}},
+			{Title: "Requests around a 300ms SLO", Arguments: map[string]interface{}{"service_name": "cart", "output": "histogram", "buckets": []interface{}{100, 300, 1000}}},
+		},
+		"dash0_spans_stats": {
+			{Title: "Latency per route of a service", Arguments: map[string]interface{}{"service_name": "checkout", "group_by": []interface{}{"route"}}},
+			{Title: "Slowest operations by P99", Arguments: map[string]interface{}{"group_by": []interface{}{"service", "span_name"}, "sort_by": "p99", "max_groups": 10}},
+			{Title: "Error rate per pod", Arguments: map[string]interface{}{"service_name": "cart", "group_by": []interface{}{"pod"}, "sort_by": "error_rate"}},
+		},
+		"dash0_spans_send": {
+			{
+				Title: "Send a server span and wait until it is queryable (set the timestamps to the current time)",
+				Arguments: map[string]interface{}{
+					"verify": true,
+					"body": map[string]interface{}{
+						"resourceSpans": []interface{}{
+							map[string]interface{}{
+								"resource": map[string]interface{}{
+									"attributes": []interface{}{
+										map[string]interface{}{"key": "service.name", "value": map[string]interface{}{"stringValue": "checkout"}},
+									},
+								},
+								"scopeSpans": []interface{}{
+									map[string]interface{}{
+										"scope": map[string]interface{}{"name": "dash0-mcp"},
+										"spans": []interface{}{
+											map[string]interface{}{
+												"traceId":           "5b8efff798038103d269b633813fc60c",
+												"spanId":            "eee19b7ec3c1b174",
+												"name":              "POST /api/checkout",
+												"kind":              2,
+												"startTimeUnixNano": "1760000000000000000",
+												"endTimeUnixNano":   "1760000000250000000",
+												"attributes": []interface{}{
+													map[string]interface{}{"key": "http.request.method", "value": map[string]interface{}{"stringValue": "POST"}},
+													map[string]interface{}{"key": "http.response.status_code", "value": map[string]interface{}{"intValue": "200"}},
+												},
+												"status": map[string]interface{}{"code": 1},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			{
+				Title: "Relay a binary OTLP protobuf batch, gzip-compressed before sending",
+				Arguments: map[string]interface{}{
+					"content_encoding": "protobuf_gzip",
+					"body":             "CgISAA==",
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,181 @@
+package spans
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+)
+
+// Outputs of dash0_spans_query.
+const (
+	outputSpans     = "spans"
+	outputHistogram = "histogram"
+)
+
+const (
+	// maxHistogramBuckets bounds the boundaries a histogram may have.
+	maxHistogramBuckets = 20
+	// histogramMaxGroups is how many groups a histogram lists.
+	histogramMaxGroups = 20
+)
+
+// defaultHistogramBounds are the bucket upper bounds in milliseconds of a
+// histogram without explicit buckets.
+var defaultHistogramBounds = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// HistogramGroup counts the spans of one group per duration bucket.
+type HistogramGroup struct {
+	// Group is the value of the group_by dimension; it is empty for the total.
+	Group string `json:"group,omitempty"`
+	Count int    `json:"count"`
+	// Counts holds the spans per bucket: Counts[i] those up to BoundsMs[i]
+	// and above the previous bound, the last entry those above every bound.
+	Counts []int `json:"counts"`
+}
+
+// SpanHistogram is the histogram output of dash0_spans_query.
+type SpanHistogram struct {
+	From     string    `json:"from"`
+	To       string    `json:"to"`
+	GroupBy  string    `json:"group_by"`
+	BoundsMs []float64 `json:"bounds_ms"`
+	// Sampled is true when more spans matched than were read, so the counts
+	// cover only the spans read.
+	Sampled bool             `json:"sampled"`
+	Total   HistogramGroup   `json:"total"`
+	Groups  []HistogramGroup `json:"groups"`
+	// GroupCount is the number of groups before the list was cut.
+	GroupCount int `json:"group_count"`
+}
+
+// parseBounds reads the buckets argument: ascending, positive upper bounds
+// in milliseconds.
+func parseBounds(raw interface{}) ([]float64, error) {
+	items, ok := raw.([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("buckets must be a non-empty array of upper bounds in milliseconds")
+	}
+	if len(items) > maxHistogramBuckets {
+		return nil, fmt.Errorf("buckets must have at most %d bounds, got %d", maxHistogramBuckets, len(items))
+	}
+	bounds := make([]float64, len(items))
+	for i, item := range items {
+		v, ok := item.(float64)
+		if !ok || v <= 0 {
+			return nil, fmt.Errorf("buckets[%d] must be a positive number of milliseconds", i)
+		}
+		if i > 0 && v <= bounds[i-1] {
+			return nil, fmt.Errorf("buckets must be in ascending order")
+		}
+		bounds[i] = v
+	}
+	return bounds, nil
+}
+
+// bucketIndex returns the bucket of a duration.
+func bucketIndex(bounds []float64, ms float64) int {
+	return sort.SearchFloat64s(bounds, ms)
+}
+
+// buildHistogram counts spans per bucket, in total and per value of the
+// groupBy dimension, largest group first.
+func buildHistogram(ss []FlatSpan, groupBy string, bounds []float64) (HistogramGroup, []HistogramGroup) {
+	total := HistogramGroup{Counts: make([]int, len(bounds)+1)}
+	groups := map[string]*HistogramGroup{}
+	for _, s := range ss {
+		value, _ := groupValue(s, groupBy)
+		if value == "" {
+			value = unsetValue
+		}
+		g, ok := groups[value]
+		if !ok {
+			g = &HistogramGroup{Group: value, Counts: make([]int, len(bounds)+1)}
+			groups[value] = g
+		}
+		i := bucketIndex(bounds, s.DurationMs)
+		g.Count++
+		g.Counts[i]++
+		total.Count++
+		total.Counts[i]++
+	}
+
+	out := make([]HistogramGroup, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, *g)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Group < out[j].Group
+	})
+	return total, out
+}
+
+// bucketLabels names the buckets of bounds, e.g. ≤100ms and >10s.
+func bucketLabels(bounds []float64) []string {
+	labels := make([]string, len(bounds)+1)
+	for i, b := range bounds {
+		labels[i] = "≤" + formatBound(b)
+	}
+	labels[len(bounds)] = ">" + formatBound(bounds[len(bounds)-1])
+	return labels
+}
+
+// formatBound renders a bucket bound as briefly as possible: 250ms, 2.5s.
+func formatBound(ms float64) string {
+	if ms >= 1000 {
+		return strconv.FormatFloat(ms/1000, 'f', -1, 64) + "s"
+	}
+	return strconv.FormatFloat(ms, 'f', -1, 64) + "ms"
+}
+
+// formatHistogram renders one row per group with its count per bucket and
+// a sparkline of the distribution.
+func formatHistogram(h SpanHistogram, filterDescs []string, maxSpans int) string {
+	summaryParts := []string{
+		fmt.Sprintf("**%d spans**", h.Total.Count),
+		fmt.Sprintf("Time: %s → %s", h.From, h.To),
+	}
+	if len(filterDescs) > 0 {
+		summaryParts = append(summaryParts, "Filters: "+strings.Join(filterDescs, ", "))
+	}
+	summaryParts = append(summaryParts, "Grouped by: "+h.GroupBy)
+	summary := strings.Join(summaryParts, " | ")
+	if h.Total.Count == 0 {
+		return formatter.Table("Span Duration Histogram", summary+"\n\nNo spans found.", nil, nil, "")
+	}
+
+	headers := append([]string{h.GroupBy, "Count"}, bucketLabels(h.BoundsMs)...)
+	headers = append(headers, "Distribution")
+	row := func(label string, g HistogramGroup) []string {
+		r := []string{label, fmt.Sprintf("%d", g.Count)}
+		for _, c := range g.Counts {
+			r = append(r, fmt.Sprintf("%d", c))
+		}
+		return append(r, formatter.Sparkline(g.Counts))
+	}
+	rows := make([][]string, 0, len(h.Groups)+1)
+	for _, g := range h.Groups {
+		rows = append(rows, row(formatter.Truncate(g.Group, 40), g))
+	}
+	if len(h.Groups) != 1 {
+		rows = append(rows, row("**All spans**", h.Total))
+	}
+
+	var notes []string
+	if h.GroupCount > len(h.Groups) {
+		notes = append(notes, fmt.Sprintf("Largest %d of %d groups; all spans are counted in the total.", len(h.Groups), h.GroupCount))
+	}
+	if h.Sampled {
+		notes = append(notes, fmt.Sprintf("More than %d spans matched; counts cover the first %d. Narrow the window or the filters for exact numbers.", maxSpans, maxSpans))
+	}
+	footer := ""
+	if len(notes) > 0 {
+		footer = "_" + strings.Join(notes, " ") + "_"
+	}
+	return formatter.Table("Span Duration Histogram", summary, headers, rows, footer)
+}
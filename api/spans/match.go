@@ -0,0 +1,130 @@
+package spans
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Match modes of the service_name and span_name filters.
+const (
+	matchExact    = "exact"
+	matchContains = "contains"
+	matchPrefix   = "prefix"
+	matchRegex    = "regex"
+)
+
+// matchModes are the values of the *_match arguments.
+var matchModes = []string{matchExact, matchContains, matchPrefix, matchRegex}
+
+// matchOperators maps the match modes the API supports to filter operators.
+var matchOperators = map[string]string{
+	matchExact:    "is",
+	matchContains: "contains",
+	matchPrefix:   "starts_with",
+}
+
+// MatchSchema returns the JSON schema of the match mode argument of a name
+// filter argument.
+func MatchSchema(arg string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"description": fmt.Sprintf("How %s matches: exact (default), contains, prefix, or regex (RE2 syntax, applied to the spans read)", arg),
+		"enum":        matchModes,
+	}
+}
+
+// Filter keys of the trace and span ID of a span.
+const (
+	traceIDKey = "otel.trace.id"
+	spanIDKey  = "otel.span.id"
+)
+
+// Matcher selects spans by a field, for filters the API cannot apply or
+// whose result is checked on the spans read.
+type Matcher struct {
+	field string
+	match func(string) bool
+}
+
+// value returns the field of s a matcher tests.
+func (m Matcher) value(s FlatSpan) string {
+	switch m.field {
+	case "service.name":
+		return s.ServiceName
+	case traceIDKey:
+		return s.TraceID
+	case spanIDKey:
+		return s.SpanID
+	}
+	return s.Name
+}
+
+// Matchers are the client-side filters of a query; a span must match all.
+type Matchers []Matcher
+
+// Match reports whether s matches every matcher.
+func (ms Matchers) Match(s FlatSpan) bool {
+	for _, m := range ms {
+		if !m.match(m.value(s)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the spans that match every matcher.
+func (ms Matchers) Filter(ss []FlatSpan) []FlatSpan {
+	if len(ms) == 0 {
+		return ss
+	}
+	var kept []FlatSpan
+	for _, s := range ss {
+		if ms.Match(s) {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
+// nameFilter builds the filter of a service_name or span_name argument in
+// the given match mode. A regex becomes a Matcher, narrowed on the server by
+// its literal prefix when it has one.
+func nameFilter(arg, key, label, value, mode string) (*AttributeFilter, *Matcher, string, error) {
+	mode = strings.TrimSpace(mode)
+	if mode == "" {
+		mode = matchExact
+	}
+	if op, ok := matchOperators[mode]; ok {
+		desc := label + "=" + value
+		if mode != matchExact {
+			desc = fmt.Sprintf("%s %s %s", label, op, value)
+		}
+		return &AttributeFilter{Key: key, Operator: op, Value: &AttributeFilterValue{StringValue: &value}}, nil, desc, nil
+	}
+	if mode != matchRegex {
+		return nil, nil, "", fmt.Errorf("%s_match must be one of %s, got %q", arg, strings.Join(matchModes, ", "), mode)
+	}
+
+	re, err := regexp.Compile(value)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("%s is not a valid regular expression: %v", arg, err)
+	}
+	m := &Matcher{field: key, match: re.MatchString}
+	desc := fmt.Sprintf("%s =~ /%s/", label, value)
+	// Every match contains the literal prefix, so the server can drop the
+	// spans without it.
+	prefix, _ := re.LiteralPrefix()
+	if prefix == "" {
+		return nil, m, desc, nil
+	}
+	return &AttributeFilter{Key: key, Operator: "contains", Value: &AttributeFilterValue{StringValue: &prefix}}, m, desc, nil
+}
+
+// idFilter builds the filter of a trace_id or span_id argument. The API
+// filters on the ID; the matcher also checks it on the spans read, ignoring
+// case.
+func idFilter(key, id string) (AttributeFilter, Matcher) {
+	filter := AttributeFilter{Key: key, Operator: "is", Value: &AttributeFilterValue{StringValue: &id}}
+	return filter, Matcher{field: key, match: func(v string) bool { return strings.EqualFold(v, id) }}
+}
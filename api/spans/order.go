@@ -0,0 +1,46 @@
+package spans
+
+import (
+	"sort"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/internal/otlp"
+)
+
+// Fields dash0_spans_query can order spans by.
+const (
+	orderTimestamp = "timestamp"
+	orderDuration  = "duration"
+)
+
+var orderFields = []string{orderTimestamp, orderDuration}
+
+// sortSpans sorts ss by o, keeping the API's order among equal spans.
+func sortSpans(ss []FlatSpan, o otlp.Order) {
+	key := func(s FlatSpan) float64 { return s.DurationMs }
+	if o.By == orderTimestamp {
+		key = func(s FlatSpan) float64 {
+			t, _ := time.Parse(time.RFC3339Nano, s.StartTime)
+			return float64(t.UnixMicro())
+		}
+	}
+	keys := make([]float64, len(ss))
+	for i, s := range ss {
+		keys[i] = key(s)
+	}
+	idx := make([]int, len(ss))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		if o.Desc {
+			return keys[idx[i]] > keys[idx[j]]
+		}
+		return keys[idx[i]] < keys[idx[j]]
+	})
+	sorted := make([]FlatSpan, len(ss))
+	for i, k := range idx {
+		sorted[i] = ss[k]
+	}
+	copy(ss, sorted)
+}
@@ -0,0 +1,44 @@
+package spans
+
+import (
+	"github.com/npcomplete777/dash0-mcp/internal/followup"
+	"github.com/npcomplete777/dash0-mcp/internal/registry"
+	"github.com/npcomplete777/dash0-mcp/internal/schema"
+	"github.com/npcomplete777/dash0-mcp/internal/truncate"
+)
+
+// Compile-time interface check.
+var _ registry.OutputSchemaProvider = (*Tools)(nil)
+
+// OutputSchemas returns the JSON Schemas of the structured results of the
+// span tools.
+func (p *Tools) OutputSchemas() map[string]map[string]interface{} {
+	return map[string]map[string]interface{}{
+		"dash0_spans_query": querySpansOutputSchema(),
+		"dash0_spans_stats": schema.Reflect(SpanStats{}),
+	}
+}
+
+// querySpansOutputSchema describes the result of dash0_spans_query. The
+// table output sets spans and next_cursor, order_by adds matched and sampled,
+// and the histogram output sets histogram instead of spans.
+func querySpansOutputSchema() map[string]interface{} {
+	spans := schema.Reflect([]FlatSpan{})
+	spans["description"] = "The matching spans, flattened"
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"spans":       spans,
+			"count":       map[string]interface{}{"type": "integer", "description": "Number of spans returned"},
+			"next_cursor": map[string]interface{}{"type": "string", "description": "Pass as cursor to fetch the next page; empty on the last page"},
+			"has_more":    map[string]interface{}{"type": "boolean"},
+			"matched":     map[string]interface{}{"type": "integer", "description": "With order_by, the number of matching spans read and sorted"},
+			"sampled":     map[string]interface{}{"type": "boolean", "description": "With order_by, true when more spans matched than max_spans"},
+			"histogram":   schema.Reflect(SpanHistogram{}),
+			"query":       map[string]interface{}{"type": "object", "description": "The time range, filters, and limit of the query"},
+			followup.Key:  schema.Reflect([]followup.Suggestion{}),
+			"truncated":   map[string]interface{}{"type": "boolean"},
+			"truncation":  schema.Reflect(truncate.Report{}),
+		},
+	}
+}
@@ -0,0 +1,367 @@
+package spans
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/npcomplete777/dash0-mcp/internal/followup"
+	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+)
+
+// Group-by dimensions with a meaning of their own. Any other group_by value
+// is read as a span attribute key.
+const (
+	groupService    = "service"
+	groupRoute      = "route"
+	groupSpanName   = "span_name"
+	groupSpanKind   = "span_kind"
+	groupStatusCode = "status_code"
+	groupPod        = "pod"
+)
+
+// Orders of the stats groups.
+const (
+	sortCount     = "count"
+	sortP50       = "p50"
+	sortP90       = "p90"
+	sortP99       = "p99"
+	sortErrorRate = "error_rate"
+)
+
+// unsetValue groups the spans without a value for a dimension.
+const unsetValue = "(unset)"
+
+// DurationStats summarizes the durations and errors of a group of spans.
+type DurationStats struct {
+	// Group holds the value of each group_by dimension; it is empty for the
+	// total.
+	Group  map[string]string `json:"group,omitempty"`
+	Count  int               `json:"count"`
+	Errors int               `json:"errors"`
+	// ErrorRate is the percentage of spans with an error status.
+	ErrorRate float64 `json:"error_rate"`
+	P50Ms     float64 `json:"p50_ms"`
+	P90Ms     float64 `json:"p90_ms"`
+	P99Ms     float64 `json:"p99_ms"`
+	MeanMs    float64 `json:"mean_ms"`
+	MaxMs     float64 `json:"max_ms"`
+	// SpanNameRoute is true when the route of the group is a span name,
+	// because its spans had no http.route.
+	SpanNameRoute bool `json:"span_name_route,omitempty"`
+}
+
+// SpanStats is the result of dash0_spans_stats.
+type SpanStats struct {
+	From    string   `json:"from"`
+	To      string   `json:"to"`
+	GroupBy []string `json:"group_by"`
+	SortBy  string   `json:"sort_by"`
+	// Sampled is true when more spans matched than were read, so the
+	// statistics cover only the spans read.
+	Sampled bool            `json:"sampled"`
+	Total   DurationStats   `json:"total"`
+	Groups  []DurationStats `json:"groups"`
+	// GroupCount is the number of groups before the list was cut to
+	// max_groups.
+	GroupCount int                   `json:"group_count"`
+	FollowUps  []followup.Suggestion `json:"suggested_follow_ups"`
+}
+
+// groupValue returns the value of a group_by dimension for a span, and
+// whether a route is the span name because the span has no http.route.
+func groupValue(s FlatSpan, dimension string) (string, bool) {
+	switch dimension {
+	case groupService:
+		return s.ServiceName, false
+	case groupRoute:
+		if route := attributeString(s.Attributes, "http.route"); route != "" {
+			return route, false
+		}
+		return s.Name, s.Name != ""
+	case groupSpanName:
+		return s.Name, false
+	case groupSpanKind:
+		return s.SpanKind, false
+	case groupStatusCode:
+		return attributeString(s.Attributes, "http.response.status_code"), false
+	case groupPod:
+		return s.K8sPodName, false
+	default:
+		return attributeString(s.Attributes, dimension), false
+	}
+}
+
+// attributeString returns an attribute as a string, or "".
+func attributeString(attrs map[string]interface{}, key string) string {
+	switch v := attrs[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// durationStats computes the statistics of a group of spans.
+func durationStats(ss []FlatSpan) DurationStats {
+	st := DurationStats{Count: len(ss)}
+	if len(ss) == 0 {
+		return st
+	}
+	durations := make([]float64, len(ss))
+	var total float64
+	for i, s := range ss {
+		durations[i] = s.DurationMs
+		total += s.DurationMs
+		if s.StatusCode == 2 {
+			st.Errors++
+		}
+	}
+	sort.Float64s(durations)
+	st.ErrorRate = float64(st.Errors) / float64(len(ss)) * 100
+	st.P50Ms = percentile(durations, 0.50)
+	st.P90Ms = percentile(durations, 0.90)
+	st.P99Ms = percentile(durations, 0.99)
+	st.MeanMs = total / float64(len(ss))
+	st.MaxMs = durations[len(durations)-1]
+	return st
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	idx := int(math.Ceil(p*float64(n))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= n {
+		idx = n - 1
+	}
+	return sorted[idx]
+}
+
+// computeStats groups spans by the groupBy dimensions and returns the
+// statistics of every group, ordered by sortBy, largest first.
+func computeStats(ss []FlatSpan, groupBy []string, sortBy string) (DurationStats, []DurationStats) {
+	type group struct {
+		values        map[string]string
+		spanNameRoute bool
+		spans         []FlatSpan
+	}
+	groups := map[string]*group{}
+	for _, s := range ss {
+		values := make(map[string]string, len(groupBy))
+		keyParts := make([]string, len(groupBy))
+		spanNameRoute := false
+		for i, dim := range groupBy {
+			v, fromName := groupValue(s, dim)
+			if v == "" {
+				v = unsetValue
+			}
+			values[dim] = v
+			keyParts[i] = v
+			spanNameRoute = spanNameRoute || fromName
+		}
+		key := strings.Join(keyParts, "\x00")
+		g, ok := groups[key]
+		if !ok {
+			g = &group{values: values, spanNameRoute: spanNameRoute}
+			groups[key] = g
+		}
+		g.spans = append(g.spans, s)
+	}
+
+	out := make([]DurationStats, 0, len(groups))
+	for _, g := range groups {
+		st := durationStats(g.spans)
+		st.Group = g.values
+		st.SpanNameRoute = g.spanNameRoute
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if a, b := sortValue(out[i], sortBy), sortValue(out[j], sortBy); a != b {
+			return a > b
+		}
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return groupLabel(out[i], groupBy) < groupLabel(out[j], groupBy)
+	})
+	return durationStats(ss), out
+}
+
+// sortValue returns the statistic a group is ordered by.
+func sortValue(st DurationStats, sortBy string) float64 {
+	switch sortBy {
+	case sortP50:
+		return st.P50Ms
+	case sortP90:
+		return st.P90Ms
+	case sortP99:
+		return st.P99Ms
+	case sortErrorRate:
+		return st.ErrorRate
+	default:
+		return float64(st.Count)
+	}
+}
+
+// groupLabel joins the dimension values of a group for display.
+func groupLabel(st DurationStats, groupBy []string) string {
+	parts := make([]string, len(groupBy))
+	for i, dim := range groupBy {
+		parts[i] = st.Group[dim]
+	}
+	return strings.Join(parts, " · ")
+}
+
+// groupQueryArgs returns dash0_spans_query arguments that select the spans
+// of a group, on top of the filters of the stats call.
+func groupQueryArgs(st DurationStats, groupBy []string, callArgs map[string]interface{}) map[string]interface{} {
+	args := map[string]interface{}{}
+	for _, key := range []string{"service_name", "service_name_match", "http_method", "http_status_code", "span_name", "span_name_match", "error_only", "time_range_minutes"} {
+		if v, ok := callArgs[key]; ok {
+			args[key] = v
+		}
+	}
+	var attrFilters []interface{}
+	if raw, ok := callArgs["attribute_filters"].([]interface{}); ok {
+		attrFilters = append(attrFilters, raw...)
+	}
+	for _, dim := range groupBy {
+		value := st.Group[dim]
+		if value == unsetValue {
+			continue
+		}
+		switch dim {
+		case groupService:
+			args["service_name"] = value
+			delete(args, "service_name_match")
+		case groupSpanName:
+			args["span_name"] = value
+			delete(args, "span_name_match")
+		case groupRoute:
+			if st.SpanNameRoute {
+				args["span_name"] = value
+				delete(args, "span_name_match")
+			} else {
+				attrFilters = append(attrFilters, map[string]interface{}{"key": "http.route", "value": value})
+			}
+		case groupStatusCode:
+			if code, err := strconv.Atoi(value); err == nil {
+				args["http_status_code"] = code
+			}
+		case groupPod:
+			attrFilters = append(attrFilters, map[string]interface{}{"key": "k8s.pod.name", "value": value})
+		case groupSpanKind:
+			// Span kind is not a query filter; the other dimensions narrow
+			// the query enough.
+		default:
+			attrFilters = append(attrFilters, map[string]interface{}{"key": dim, "value": value})
+		}
+	}
+	if len(attrFilters) > 0 {
+		args["attribute_filters"] = attrFilters
+	}
+	return args
+}
+
+// statsFollowUps suggests the slowest spans of the group with the highest
+// p99 and the failed spans of the group with the highest error rate.
+func statsFollowUps(stats SpanStats, callArgs map[string]interface{}) []followup.Suggestion {
+	suggestions := []followup.Suggestion{}
+	if len(stats.Groups) == 0 {
+		return suggestions
+	}
+
+	slowest := stats.Groups[0]
+	for _, g := range stats.Groups[1:] {
+		if g.P99Ms > slowest.P99Ms {
+			slowest = g
+		}
+	}
+	if slowest.P99Ms > 0 {
+		args := groupQueryArgs(slowest, stats.GroupBy, callArgs)
+		args["min_duration_ms"] = math.Floor(slowest.P90Ms)
+		suggestions = append(suggestions, followup.New("dash0_spans_query", args,
+			fmt.Sprintf("%s has the highest p99 (%s); inspect its slowest spans.",
+				groupLabel(slowest, stats.GroupBy), formatter.FormatDuration(slowest.P99Ms))))
+	}
+
+	var failing *DurationStats
+	for i, g := range stats.Groups {
+		if g.Errors > 0 && (failing == nil || g.ErrorRate > failing.ErrorRate) {
+			failing = &stats.Groups[i]
+		}
+	}
+	if failing != nil {
+		args := groupQueryArgs(*failing, stats.GroupBy, callArgs)
+		args["error_only"] = true
+		suggestions = append(suggestions, followup.New("dash0_spans_query", args,
+			fmt.Sprintf("%s has the highest error rate (%.1f%%); inspect its failed spans.",
+				groupLabel(*failing, stats.GroupBy), failing.ErrorRate)))
+	}
+	return suggestions
+}
+
+// formatSpanStats renders the statistics as one table row per group.
+func formatSpanStats(stats SpanStats, filterDescs []string, maxSpans int) string {
+	summaryParts := []string{
+		fmt.Sprintf("**%d spans**", stats.Total.Count),
+		fmt.Sprintf("Time: %s → %s", stats.From, stats.To),
+	}
+	if len(filterDescs) > 0 {
+		summaryParts = append(summaryParts, "Filters: "+strings.Join(filterDescs, ", "))
+	}
+	summaryParts = append(summaryParts, "Grouped by: "+strings.Join(stats.GroupBy, ", "))
+	summary := strings.Join(summaryParts, " | ")
+	if stats.Total.Count == 0 {
+		return formatter.Table("Span Duration Statistics", summary+"\n\nNo spans found.", nil, nil, "")
+	}
+	t := stats.Total
+	summary += fmt.Sprintf("\n\n> **All spans:** P50: %s | P90: %s | P99: %s | Max: %s | Error rate: %.1f%% (%d/%d)",
+		formatter.FormatDuration(t.P50Ms), formatter.FormatDuration(t.P90Ms), formatter.FormatDuration(t.P99Ms),
+		formatter.FormatDuration(t.MaxMs), t.ErrorRate, t.Errors, t.Count)
+
+	headers := make([]string, 0, len(stats.GroupBy)+7)
+	for _, dim := range stats.GroupBy {
+		headers = append(headers, dim)
+	}
+	headers = append(headers, "Count", "Errors", "Error %", "P50", "P90", "P99", "Max")
+	rows := make([][]string, len(stats.Groups))
+	for i, g := range stats.Groups {
+		row := make([]string, 0, len(headers))
+		for _, dim := range stats.GroupBy {
+			row = append(row, formatter.Truncate(g.Group[dim], 40))
+		}
+		rows[i] = append(row,
+			fmt.Sprintf("%d", g.Count),
+			fmt.Sprintf("%d", g.Errors),
+			fmt.Sprintf("%.1f", g.ErrorRate),
+			formatter.FormatDuration(g.P50Ms),
+			formatter.FormatDuration(g.P90Ms),
+			formatter.FormatDuration(g.P99Ms),
+			formatter.FormatDuration(g.MaxMs),
+		)
+	}
+
+	var notes []string
+	if stats.GroupCount > len(stats.Groups) {
+		notes = append(notes, fmt.Sprintf("Top %d of %d groups by %s.", len(stats.Groups), stats.GroupCount, stats.SortBy))
+	}
+	if stats.Sampled {
+		notes = append(notes, fmt.Sprintf("More than %d spans matched; statistics cover the first %d. Narrow the window or the filters for exact numbers.", maxSpans, maxSpans))
+	}
+	footer := ""
+	if len(notes) > 0 {
+		footer = "_" + strings.Join(notes, " ") + "_"
+	}
+	return formatter.Table("Span Duration Statistics", summary, headers, rows, footer)
+}
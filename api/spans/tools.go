@@ -10,16 +10,36 @@ import (
 	"time"
 
 	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/followup"
 	"github.com/npcomplete777/dash0-mcp/internal/formatter"
 	"github.com/npcomplete777/dash0-mcp/internal/otlp"
+	"github.com/npcomplete777/dash0-mcp/internal/progress"
 	"github.com/npcomplete777/dash0-mcp/internal/registry"
+	"github.com/npcomplete777/dash0-mcp/internal/truncate"
 	mcp "github.com/mark3labs/mcp-go/mcp"
 )
 
 const (
 	basePath = "/api/spans"
+
+	// queryPageSize is the largest page a spans query returns.
+	queryPageSize = 200
+
+	defaultVerifyTimeoutSeconds = 30
+	maxVerifyTimeoutSeconds     = 120
+
+	// verifyMaxPages bounds the query pages read per verification attempt.
+	verifyMaxPages = 5
+
+	defaultStatsMaxSpans  = 2000
+	maxStatsMaxSpans      = 10000
+	defaultStatsMaxGroups = 20
+	maxStatsMaxGroups     = 100
 )
 
+// verifyPollInterval is the delay between ingestion verification queries.
+var verifyPollInterval = 2 * time.Second
+
 // Compile-time interface check.
 var _ registry.ToolProvider = (*Tools)(nil)
 
@@ -38,6 +58,7 @@ func (p *Tools) Tools() []mcp.Tool {
 	return []mcp.Tool{
 		p.PostSpans(),
 		p.QuerySpans(),
+		p.SpanStats(),
 	}
 }
 
@@ -46,20 +67,43 @@ func (p *Tools) Handlers() map[string]func(context.Context, map[string]interface
 	return map[string]func(context.Context, map[string]interface{}) *client.ToolResult{
 		"dash0_spans_send":  p.PostSpansHandler,
 		"dash0_spans_query": p.QuerySpansHandler,
+		"dash0_spans_stats": p.SpanStatsHandler,
 	}
 }
 
 // PostSpans returns the dash0_spans_send tool definition.
 func (p *Tools) PostSpans() mcp.Tool {
 	return mcp.Tool{
-		Name:        "dash0_spans_send",
-		Description: "Send OTLP spans to Dash0. Accepts trace data in OTLP JSON format for distributed tracing analysis.",
+		Name: "dash0_spans_send",
+		Description: `Send OTLP spans to Dash0. Accepts trace data in OTLP JSON format for distributed tracing analysis,
+or as base64-encoded OTLP protobuf with "content_encoding": "protobuf".
+
+Posted to the ingestion endpoint when DASH0_INGRESS_URL is set, otherwise through the API.
+With content_encoding json_gzip or protobuf_gzip, the payload is gzip-compressed before
+it is sent, for large batches relayed from instrumentation pipelines.
+
+With "verify": true, polls the spans query API after sending until every sent
+span (by trace_id/span_id) is queryable or the timeout elapses, and reports
+which spans never appeared.`,
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
 				"body": map[string]interface{}{
-					"type":        "object",
-					"description": "OTLP spans in JSON format. Should follow the OpenTelemetry Protocol specification for traces.",
+					"type":        []string{"object", "string"},
+					"description": "OTLP spans in JSON format. Should follow the OpenTelemetry Protocol specification for traces. With content_encoding protobuf or protobuf_gzip, a base64 string of a binary ExportTraceServiceRequest.",
+				},
+				"content_encoding": map[string]interface{}{
+					"type":        "string",
+					"description": "Encoding of body: json (default), json_gzip, protobuf (a base64 string), or protobuf_gzip. verify requires json or json_gzip",
+					"enum":        client.IngestEncodings,
+				},
+				"verify": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Poll the query API until the sent spans are queryable (default: false)",
+				},
+				"verify_timeout_seconds": map[string]interface{}{
+					"type":        "integer",
+					"description": "How long to wait for the spans to become queryable (default: 30, max: 120)",
 				},
 			},
 			Required: []string{"body"},
@@ -74,14 +118,184 @@ func (p *Tools) PostSpansHandler(ctx context.Context, args map[string]interface{
 		return client.ErrorResult(400, "body is required")
 	}
 
-	return p.client.Post(ctx, basePath, body)
+	verify, _ := args["verify"].(bool)
+	timeoutSeconds := defaultVerifyTimeoutSeconds
+	if t, ok := args["verify_timeout_seconds"].(float64); ok {
+		if t < 0 {
+			return client.ErrorResult(400, "verify_timeout_seconds must not be negative")
+		}
+		if t > 0 {
+			timeoutSeconds = int(t)
+			if timeoutSeconds > maxVerifyTimeoutSeconds {
+				timeoutSeconds = maxVerifyTimeoutSeconds
+			}
+		}
+	}
+
+	encoding, _ := args["content_encoding"].(string)
+	if verify && (encoding == client.EncodingProtobuf || encoding == client.EncodingProtobufGzip) {
+		return client.ErrorResult(400, "verify needs an OTLP JSON body; use content_encoding json or json_gzip")
+	}
+	payload, err := client.EncodePayload(body, encoding)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+
+	result := p.client.Ingest(ctx, client.SignalSpans, payload)
+	if !result.Success || result.DryRun || !verify {
+		return result
+	}
+
+	sent := collectSentSpans(body)
+	if len(sent.keys) == 0 {
+		return client.ErrorResult(400, "verify requires spans with traceId and spanId in the body")
+	}
+
+	verification := otlp.VerifyIngestion(ctx, sent.keys, time.Duration(timeoutSeconds)*time.Second, verifyPollInterval,
+		func(ctx context.Context) (map[string]bool, error) {
+			return p.lookupSpans(ctx, sent)
+		},
+	)
+
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: otlp.FormatVerification("spans", verification),
+		Data: map[string]interface{}{
+			"response":     result.Data,
+			"verification": verification,
+		},
+	}
+}
+
+// allFound reports whether every key is present in found.
+func allFound(found map[string]bool, keys []string) bool {
+	for _, key := range keys {
+		if !found[key] {
+			return false
+		}
+	}
+	return true
+}
+
+// sentSpans describes the spans in a send payload for ingestion verification.
+type sentSpans struct {
+	keys     []string // "traceId/spanId"
+	services map[string]bool
+	minStart int64
+	maxStart int64
+}
+
+// collectSentSpans extracts span identities and time bounds from an OTLP payload.
+func collectSentSpans(body interface{}) sentSpans {
+	sent := sentSpans{services: make(map[string]bool)}
+
+	bodyMap, ok := body.(map[string]interface{})
+	if !ok {
+		return sent
+	}
+	resourceSpans, _ := bodyMap["resourceSpans"].([]interface{})
+	for _, rs := range resourceSpans {
+		rsMap, ok := rs.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		sent.services[otlp.ExtractServiceName(rsMap)] = true
+
+		scopeSpans, _ := rsMap["scopeSpans"].([]interface{})
+		for _, ss := range scopeSpans {
+			ssMap, ok := ss.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			spanList, _ := ssMap["spans"].([]interface{})
+			for _, sp := range spanList {
+				spanMap, ok := sp.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				traceID, _ := spanMap["traceId"].(string)
+				spanID, _ := spanMap["spanId"].(string)
+				if traceID == "" || spanID == "" {
+					continue
+				}
+				sent.keys = append(sent.keys, traceID+"/"+spanID)
+
+				if start, ok := otlp.UnixNanoValue(spanMap["startTimeUnixNano"]); ok {
+					if sent.minStart == 0 || start < sent.minStart {
+						sent.minStart = start
+					}
+					if start > sent.maxStart {
+						sent.maxStart = start
+					}
+				}
+			}
+		}
+	}
+	return sent
+}
+
+// lookupSpans queries the window around the sent spans and returns the keys found.
+func (p *Tools) lookupSpans(ctx context.Context, sent sentSpans) (map[string]bool, error) {
+	now := time.Now().UTC()
+	from, to := now.Add(-15*time.Minute), now.Add(time.Minute)
+	if sent.minStart > 0 {
+		from = time.Unix(0, sent.minStart).UTC().Add(-time.Minute)
+		to = time.Unix(0, sent.maxStart).UTC().Add(time.Minute)
+	}
+
+	var filters []AttributeFilter
+	if len(sent.services) == 1 {
+		for service := range sent.services {
+			if service != "" {
+				service := service
+				filters = append(filters, AttributeFilter{
+					Key:      "service.name",
+					Operator: "is",
+					Value:    &AttributeFilterValue{StringValue: &service},
+				})
+			}
+		}
+	}
+
+	dataset := p.client.Dataset(ctx)
+	found := make(map[string]bool)
+	cursor := ""
+	for page := 0; page < verifyMaxPages; page++ {
+		req := QuerySpansRequest{
+			Dataset: dataset,
+			TimeRange: TimeRange{
+				From: from.Format(time.RFC3339),
+				To:   to.Format(time.RFC3339),
+			},
+			Filter:     filters,
+			Pagination: Pagination{Limit: 200, Cursor: cursor},
+		}
+		result := p.client.PostWithDataset(ctx, basePath, req, dataset)
+		if !result.Success {
+			if result.Error != nil {
+				return nil, fmt.Errorf("%d %s", result.Error.StatusCode, result.Error.Detail)
+			}
+			return nil, fmt.Errorf("span query failed")
+		}
+		for _, span := range flattenSpansResponse(result.Data) {
+			found[span.TraceID+"/"+span.SpanID] = true
+		}
+		if allFound(found, sent.keys) {
+			break
+		}
+		cursor = otlp.NextCursor(result.Data)
+		if cursor == "" {
+			break
+		}
+	}
+	return found, nil
 }
 
 // QuerySpans returns the dash0_spans_query tool definition.
 func (p *Tools) QuerySpans() mcp.Tool {
 	return mcp.Tool{
 		Name: "dash0_spans_query",
-		Description: `Query spans from Dash0 with filtering by service, HTTP method, status code, and errors.
+		Description: `Query spans from Dash0 with filtering by service, Kubernetes namespace/deployment/pod, HTTP method, status code, and errors.
 
 Returns spans as a formatted markdown table with duration, status, and key attributes.
 
@@ -89,14 +303,43 @@ Example queries:
 - Get spans for a service: {"service_name": "cart"}
 - Get error spans: {"error_only": true}
 - Get slow POST requests: {"http_method": "POST", "min_duration_ms": 1000}
-- Get 5xx errors: {"http_status_code": 500}`,
+- Get 5xx errors: {"http_status_code": 500}
+- Get spans of a Kubernetes deployment: {"k8s_namespace": "shop", "k8s_deployment_name": "cart"}
+- Filter on any attribute: {"attribute_filters": [{"key": "k8s.pod.name", "operator": "starts_with", "value": "cart-"}]}
+- Span names by pattern: {"span_name": "^(GET|POST) /api/orders", "span_name_match": "regex"}
+- Every span of a trace: {"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736"}
+
+trace_id and span_id look up the spans of one trace or one span, e.g. the trace_id of a log
+line; the result suggests dash0_logs_query for the logs of the same trace. Widen
+time_range_minutes for traces older than an hour.
+
+service_name and span_name match exactly unless service_name_match or span_name_match is
+contains, prefix, or regex. A regex is applied to the spans read, so a page may hold fewer
+than limit spans; the server still narrows the query by the regex's literal prefix.
+
+Large result sets are paged: when more spans are available the result includes
+next_cursor. Repeat the query with the same filters and {"cursor": "<next_cursor>"}
+to fetch the next page; the cursor keeps the time window of the first page.
+
+With {"output": "histogram"} the spans are not listed; instead up to max_spans matching
+spans are read and counted per duration bucket, per service (default) or per the
+histogram_group_by dimension (route, span_name, span_kind, status_code, pod, or an attribute
+key). Buckets default to 5ms, 10ms, 25ms, ... 10s; pass buckets for other upper bounds.
+- Latency distribution per route: {"service_name": "cart", "output": "histogram", "histogram_group_by": "route"}
+- Around an SLO of 300ms: {"service_name": "cart", "output": "histogram", "buckets": [100, 300, 1000]}
+
+Spans come back in the API's order. order_by (timestamp or duration) with direction (desc by
+default) reads up to max_spans matching spans and returns the first limit of them in that order,
+without paging.
+- The 10 slowest spans of a service: {"service_name": "cart", "order_by": "duration", "limit": 10}`,
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
-			Properties: map[string]interface{}{
+			Properties: truncate.WithSchemaProperties(map[string]interface{}{
 				"service_name": map[string]interface{}{
 					"type":        "string",
-					"description": "Filter by service name (exact match)",
+					"description": "Filter by service name (exact match unless service_name_match is set)",
 				},
+				"service_name_match": MatchSchema("service_name"),
 				"time_range_minutes": map[string]interface{}{
 					"type":        "integer",
 					"description": "Minutes back to search (default: 60, max: 1440)",
@@ -119,17 +362,63 @@ Example queries:
 				},
 				"span_name": map[string]interface{}{
 					"type":        "string",
-					"description": "Filter by span name (exact match)",
+					"description": "Filter by span name (exact match unless span_name_match is set)",
+				},
+				"span_name_match": MatchSchema("span_name"),
+				"trace_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Only spans of this trace, e.g. the trace_id of a log line",
+				},
+				"span_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Only the span with this ID",
+				},
+				"k8s_namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter by Kubernetes namespace (k8s.namespace.name, exact match)",
 				},
+				"k8s_deployment_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter by Kubernetes deployment (k8s.deployment.name, exact match)",
+				},
+				"k8s_pod_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter by Kubernetes pod (k8s.pod.name, exact match)",
+				},
+				"attribute_filters": otlp.AttributeFiltersSchema("Arbitrary attribute filters, combined with AND. Use for custom attributes such as k8s.container.name or deployment.environment."),
 				"limit": map[string]interface{}{
 					"type":        "integer",
 					"description": "Max spans to return (default: 100, max: 200)",
 				},
+				"cursor": map[string]interface{}{
+					"type":        "string",
+					"description": "Pagination cursor from a previous result's next_cursor, to fetch the next page of the same time window",
+				},
+				"output": map[string]interface{}{
+					"type":        "string",
+					"description": "spans (default) lists the spans; histogram counts them per duration bucket",
+					"enum":        []string{outputSpans, outputHistogram},
+				},
+				"buckets": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "number"},
+					"description": "Histogram bucket upper bounds in milliseconds, ascending (default: 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000)",
+				},
+				"histogram_group_by": map[string]interface{}{
+					"type":        "string",
+					"description": "Histogram rows: service (default), route, span_name, span_kind, status_code, pod, or a span attribute key",
+				},
+				"max_spans": map[string]interface{}{
+					"type":        "integer",
+					"description": "Histogram and order_by only: maximum spans to read (default: 2000, max: 10000)",
+				},
+				"order_by":  otlp.OrderBySchema(orderFields),
+				"direction": otlp.DirectionSchema(),
 				"dataset": map[string]interface{}{
 					"type":        "string",
 					"description": "Dash0 dataset to query (e.g., 'astronomy-demo'). If omitted, uses the globally configured dataset or 'default'.",
 				},
-			},
+			}),
 		},
 	}
 }
@@ -170,64 +459,14 @@ type FlatSpan struct {
 
 // QuerySpansHandler handles the dash0_spans_query tool.
 func (p *Tools) QuerySpansHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
-	// Build filters
-	var filters []AttributeFilter
-	var filterDescs []string
-
-	if serviceName, ok := args["service_name"].(string); ok {
-		serviceName = strings.TrimSpace(serviceName)
-		if serviceName != "" {
-			filters = append(filters, AttributeFilter{
-				Key:      "service.name",
-				Operator: "is",
-				Value:    &AttributeFilterValue{StringValue: &serviceName},
-			})
-			filterDescs = append(filterDescs, "service="+serviceName)
-		}
-	}
-
-	if httpMethod, ok := args["http_method"].(string); ok {
-		httpMethod = strings.TrimSpace(httpMethod)
-		if httpMethod != "" {
-			filters = append(filters, AttributeFilter{
-				Key:      "http.request.method",
-				Operator: "is",
-				Value:    &AttributeFilterValue{StringValue: &httpMethod},
-			})
-			filterDescs = append(filterDescs, "method="+httpMethod)
-		}
-	}
-
-	if statusCode, ok := args["http_status_code"].(float64); ok {
-		statusStr := strconv.Itoa(int(statusCode))
-		filters = append(filters, AttributeFilter{
-			Key:      "http.response.status_code",
-			Operator: "is",
-			Value:    &AttributeFilterValue{IntValue: &statusStr},
-		})
-		filterDescs = append(filterDescs, "status="+statusStr)
+	limits, err := truncate.ParseLimits(args, p.client.ResponseLimits())
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
 	}
 
-	if spanName, ok := args["span_name"].(string); ok {
-		spanName = strings.TrimSpace(spanName)
-		if spanName != "" {
-			filters = append(filters, AttributeFilter{
-				Key:      "name",
-				Operator: "is",
-				Value:    &AttributeFilterValue{StringValue: &spanName},
-			})
-			filterDescs = append(filterDescs, "name="+spanName)
-		}
-	}
-
-	if errorOnly, ok := args["error_only"].(bool); ok && errorOnly {
-		errorCode := "2" // OTLP error status code
-		filters = append(filters, AttributeFilter{
-			Key:      "status.code",
-			Operator: "is",
-			Value:    &AttributeFilterValue{IntValue: &errorCode},
-		})
-		filterDescs = append(filterDescs, "errors_only")
+	filters, filterDescs, matchers, err := parseFilters(args)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
 	}
 
 	// Calculate time range
@@ -246,6 +485,20 @@ func (p *Tools) QuerySpansHandler(ctx context.Context, args map[string]interface
 	}
 	from := now.Add(-time.Duration(minutes) * time.Minute)
 
+	output, _ := args["output"].(string)
+	output = strings.TrimSpace(output)
+	if output == "" {
+		output = outputSpans
+	}
+	if output != outputSpans && output != outputHistogram {
+		return client.ErrorResult(400, fmt.Sprintf("output must be %s or %s, got %q", outputSpans, outputHistogram, output))
+	}
+
+	order, err := otlp.ParseOrder(args, orderFields)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+
 	// Set limit
 	limit := 100
 	if l, ok := args["limit"].(float64); ok {
@@ -268,6 +521,20 @@ func (p *Tools) QuerySpansHandler(ctx context.Context, args map[string]interface
 		dataset = p.client.GetDataset()
 	}
 
+	if output == outputHistogram {
+		return p.queryHistogram(ctx, args, filters, filterDescs, matchers, from, now, dataset)
+	}
+	if order.By != "" {
+		return p.queryOrdered(ctx, args, order, filters, filterDescs, matchers, from, now, dataset, limit, limits)
+	}
+
+	// A cursor of an earlier page carries the time window of its query
+	cursor, _ := args["cursor"].(string)
+	cursor = strings.TrimSpace(cursor)
+	if after, cursorFrom, cursorTo, ok := otlp.DecodeCursor(cursor); ok {
+		cursor, from, now = after, cursorFrom, cursorTo
+	}
+
 	// Build request
 	req := QuerySpansRequest{
 		Dataset: dataset,
@@ -276,7 +543,7 @@ func (p *Tools) QuerySpansHandler(ctx context.Context, args map[string]interface
 			To:   now.Format(time.RFC3339),
 		},
 		Filter:     filters,
-		Pagination: Pagination{Limit: limit},
+		Pagination: Pagination{Limit: limit, Cursor: cursor},
 	}
 
 	// Execute query
@@ -287,43 +554,567 @@ func (p *Tools) QuerySpansHandler(ctx context.Context, args map[string]interface
 
 	// Flatten the OTLP response
 	flatSpans := flattenSpansResponse(result.Data)
+	nextCursor := otlp.EncodeCursor(otlp.NextCursor(result.Data), from, now)
 
 	// Derive HasChildren for each span
 	deriveHasChildren(flatSpans)
 
-	// Apply client-side duration filter if specified
-	if minDuration, ok := args["min_duration_ms"].(float64); ok && minDuration > 0 {
-		var filtered []FlatSpan
-		for _, span := range flatSpans {
-			if span.DurationMs >= minDuration {
-				filtered = append(filtered, span)
+	// Apply client-side regex and duration filters if specified
+	flatSpans = matchers.Filter(flatSpans)
+	flatSpans, filterDescs = filterMinDuration(flatSpans, args, filterDescs)
+
+	var report truncate.Report
+	flatSpans = truncate.Items(flatSpans, limits, &report)
+	progress.ReportItems(ctx, "spans", flatSpans, limits)
+
+	// Build markdown table
+	md := formatSpansMarkdown(flatSpans, from, now, filterDescs, limit, nextCursor)
+
+	fields := map[string]interface{}{
+		"spans":       flatSpans,
+		"count":       len(flatSpans),
+		"next_cursor": nextCursor,
+		"has_more":    nextCursor != "",
+		"query": map[string]interface{}{
+			"time_range": map[string]string{
+				"from": from.Format(time.RFC3339),
+				"to":   now.Format(time.RFC3339),
+			},
+			"filters": filters,
+			"limit":   limit,
+		},
+	}
+	if suggestions := traceFollowUps(args, minutes); len(suggestions) > 0 {
+		datasetArg, _ := args["dataset"].(string)
+		fields[followup.Key] = followup.WithDataset(suggestions, datasetArg)
+		md += followup.Markdown(suggestions)
+	}
+	var data interface{} = fields
+	data, md = truncate.Apply(data, md, limits, &report)
+
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: md,
+		Data:     data,
+	}
+}
+
+// traceFollowUps suggests the logs of the trace a trace_id query looked up.
+func traceFollowUps(args map[string]interface{}, minutes int) []followup.Suggestion {
+	traceID, _ := args["trace_id"].(string)
+	traceID = strings.TrimSpace(traceID)
+	if traceID == "" {
+		return nil
+	}
+	return []followup.Suggestion{followup.New("dash0_logs_query", map[string]interface{}{
+		"trace_id":           traceID,
+		"time_range_minutes": minutes,
+	}, "See the logs emitted during this trace.")}
+}
+
+// filterMinDuration keeps the spans of at least min_duration_ms, which the
+// API cannot filter on, and adds the filter to filterDescs.
+func filterMinDuration(flatSpans []FlatSpan, args map[string]interface{}, filterDescs []string) ([]FlatSpan, []string) {
+	minDuration, ok := args["min_duration_ms"].(float64)
+	if !ok || minDuration <= 0 {
+		return flatSpans, filterDescs
+	}
+	var filtered []FlatSpan
+	for _, span := range flatSpans {
+		if span.DurationMs >= minDuration {
+			filtered = append(filtered, span)
+		}
+	}
+	return filtered, append(filterDescs, fmt.Sprintf("min_duration>=%.0fms", minDuration))
+}
+
+// parseMaxSpans reads the max_spans argument of the tools that read more
+// spans than one page holds.
+func parseMaxSpans(args map[string]interface{}) (int, error) {
+	maxSpans := defaultStatsMaxSpans
+	if v, ok := args["max_spans"].(float64); ok {
+		if v < 0 {
+			return 0, fmt.Errorf("max_spans must not be negative")
+		}
+		if v > 0 {
+			maxSpans = int(v)
+			if maxSpans > maxStatsMaxSpans {
+				maxSpans = maxStatsMaxSpans
 			}
 		}
-		flatSpans = filtered
-		filterDescs = append(filterDescs, fmt.Sprintf("min_duration>=%.0fms", minDuration))
+	}
+	return maxSpans, nil
+}
+
+// queryOrdered reads the matching spans up to max_spans and returns the
+// first limit of them in the given order. The API returns spans in its own
+// order, so sorting a single page would not find e.g. the slowest spans.
+func (p *Tools) queryOrdered(ctx context.Context, args map[string]interface{}, order otlp.Order, filters []AttributeFilter, filterDescs []string, matchers Matchers, from, to time.Time, dataset string, limit int, limits truncate.Limits) *client.ToolResult {
+	if cursor, _ := args["cursor"].(string); strings.TrimSpace(cursor) != "" {
+		return client.ErrorResult(400, "cursor does not apply with order_by, which reads up to max_spans spans at once")
+	}
+	maxSpans, err := parseMaxSpans(args)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
 	}
 
-	// Build markdown table
-	md := formatSpansMarkdown(flatSpans, from, now, filterDescs, limit)
+	req := QuerySpansRequest{
+		Dataset: dataset,
+		TimeRange: TimeRange{
+			From: from.Format(time.RFC3339),
+			To:   to.Format(time.RFC3339),
+		},
+		Filter: filters,
+	}
+	flatSpans, sampled, errResult := Fetch(ctx, p.client, req, dataset, maxSpans)
+	if errResult != nil {
+		return errResult
+	}
+	flatSpans = matchers.Filter(flatSpans)
+	flatSpans, filterDescs = filterMinDuration(flatSpans, args, filterDescs)
+
+	matched := len(flatSpans)
+	sortSpans(flatSpans, order)
+	if len(flatSpans) > limit {
+		flatSpans = flatSpans[:limit]
+	}
+
+	var report truncate.Report
+	flatSpans = truncate.Items(flatSpans, limits, &report)
+	progress.ReportItems(ctx, "spans", flatSpans, limits)
+
+	md := formatSpansMarkdown(flatSpans, from, to, filterDescs, limit, "")
+	md += fmt.Sprintf("\n_Sorted by %s across the %d matching spans read._", order, matched)
+	if sampled {
+		md += fmt.Sprintf(" _More than %d spans matched; raise max_spans or narrow the filters to sort them all._", maxSpans)
+	}
+	md += "\n"
+
+	var data interface{} = map[string]interface{}{
+		"spans":   flatSpans,
+		"count":   len(flatSpans),
+		"matched": matched,
+		"sampled": sampled,
+		"query": map[string]interface{}{
+			"time_range": map[string]string{
+				"from": from.Format(time.RFC3339),
+				"to":   to.Format(time.RFC3339),
+			},
+			"filters":  filters,
+			"limit":    limit,
+			"order_by": order.String(),
+		},
+	}
+	data, md = truncate.Apply(data, md, limits, &report)
 
 	return &client.ToolResult{
 		Success:  true,
 		Markdown: md,
+		Data:     data,
+	}
+}
+
+// queryHistogram reads the matching spans up to max_spans and counts them
+// per duration bucket, for dash0_spans_query's histogram output.
+func (p *Tools) queryHistogram(ctx context.Context, args map[string]interface{}, filters []AttributeFilter, filterDescs []string, matchers Matchers, from, to time.Time, dataset string) *client.ToolResult {
+	if cursor, _ := args["cursor"].(string); strings.TrimSpace(cursor) != "" {
+		return client.ErrorResult(400, "cursor does not apply to the histogram output, which reads up to max_spans spans at once")
+	}
+
+	bounds := defaultHistogramBounds
+	if raw, ok := args["buckets"]; ok && raw != nil {
+		var err error
+		if bounds, err = parseBounds(raw); err != nil {
+			return client.ErrorResult(400, err.Error())
+		}
+	}
+
+	groupBy := groupService
+	if v, ok := args["histogram_group_by"].(string); ok && strings.TrimSpace(v) != "" {
+		groupBy = strings.TrimSpace(v)
+	}
+
+	maxSpans, err := parseMaxSpans(args)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+
+	req := QuerySpansRequest{
+		Dataset: dataset,
+		TimeRange: TimeRange{
+			From: from.Format(time.RFC3339),
+			To:   to.Format(time.RFC3339),
+		},
+		Filter: filters,
+	}
+	flatSpans, sampled, errResult := Fetch(ctx, p.client, req, dataset, maxSpans)
+	if errResult != nil {
+		return errResult
+	}
+	flatSpans = matchers.Filter(flatSpans)
+	flatSpans, filterDescs = filterMinDuration(flatSpans, args, filterDescs)
+
+	total, groups := buildHistogram(flatSpans, groupBy, bounds)
+	h := SpanHistogram{
+		From:       from.Format(time.RFC3339),
+		To:         to.Format(time.RFC3339),
+		GroupBy:    groupBy,
+		BoundsMs:   bounds,
+		Sampled:    sampled,
+		Total:      total,
+		GroupCount: len(groups),
+	}
+	if len(groups) > histogramMaxGroups {
+		groups = groups[:histogramMaxGroups]
+	}
+	h.Groups = groups
+
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: formatHistogram(h, filterDescs, maxSpans),
 		Data: map[string]interface{}{
-			"spans": flatSpans,
-			"count": len(flatSpans),
+			"histogram": h,
 			"query": map[string]interface{}{
 				"time_range": map[string]string{
-					"from": from.Format(time.RFC3339),
-					"to":   now.Format(time.RFC3339),
+					"from": h.From,
+					"to":   h.To,
 				},
 				"filters": filters,
-				"limit":   limit,
 			},
 		},
 	}
 }
 
+// SpanStats returns the dash0_spans_stats tool definition.
+func (p *Tools) SpanStats() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_spans_stats",
+		Description: `Compute duration statistics of spans per group: count, errors, error rate, and p50/p90/p99/max duration.
+
+Reads the spans matching the same filters as dash0_spans_query and groups them by the group_by
+dimensions, so latency and errors per service or route can be compared without reading raw spans.
+Dimensions: service, route (http.route, or the span name when unset), span_name, span_kind,
+status_code (http.response.status_code), pod (k8s.pod.name), or any span attribute key.
+Spans without a value are grouped under "(unset)".
+
+At most max_spans spans are read; when more match, the statistics cover only the spans read and
+the result says so. Narrow the window or the filters for exact numbers.
+
+Example queries:
+- Latency per route of a service: {"service_name": "checkout", "group_by": ["route"]}
+- Slowest services: {"group_by": ["service"], "sort_by": "p99"}
+- Error rate per status code and route: {"service_name": "checkout", "group_by": ["route", "status_code"], "sort_by": "error_rate"}
+- Services of a family: {"service_name": "payment-", "service_name_match": "prefix", "group_by": ["service"]}`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"service_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter by service name (exact match unless service_name_match is set)",
+				},
+				"service_name_match": MatchSchema("service_name"),
+				"time_range_minutes": map[string]interface{}{
+					"type":        "integer",
+					"description": "Minutes back to read (default: 60, max: 1440)",
+				},
+				"http_method": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter by HTTP method (GET, POST, PUT, DELETE, etc)",
+				},
+				"http_status_code": map[string]interface{}{
+					"type":        "integer",
+					"description": "Filter by HTTP response status code (e.g., 200, 404, 500)",
+				},
+				"error_only": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Only read error spans (status.code = 2)",
+				},
+				"span_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter by span name (exact match unless span_name_match is set)",
+				},
+				"span_name_match": MatchSchema("span_name"),
+				"k8s_namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter by Kubernetes namespace (k8s.namespace.name, exact match)",
+				},
+				"k8s_deployment_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter by Kubernetes deployment (k8s.deployment.name, exact match)",
+				},
+				"k8s_pod_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter by Kubernetes pod (k8s.pod.name, exact match)",
+				},
+				"attribute_filters": otlp.AttributeFiltersSchema("Arbitrary attribute filters, combined with AND."),
+				"group_by": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Dimensions to group by: service, route, span_name, span_kind, status_code, pod, or a span attribute key (default: [\"service\", \"route\"])",
+				},
+				"sort_by": map[string]interface{}{
+					"type":        "string",
+					"description": "Order of the groups, largest first (default: count)",
+					"enum":        []string{sortCount, sortP50, sortP90, sortP99, sortErrorRate},
+				},
+				"max_groups": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum groups to list (default: 20, max: 100)",
+				},
+				"max_spans": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum spans to read (default: 2000, max: 10000)",
+				},
+				"dataset": map[string]interface{}{
+					"type":        "string",
+					"description": "Dash0 dataset to query (e.g., 'astronomy-demo'). If omitted, uses the globally configured dataset or 'default'.",
+				},
+			},
+		},
+	}
+}
+
+// SpanStatsHandler handles the dash0_spans_stats tool.
+func (p *Tools) SpanStatsHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	filters, filterDescs, matchers, err := parseFilters(args)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+
+	groupBy := []string{groupService, groupRoute}
+	if raw, ok := args["group_by"].([]interface{}); ok && len(raw) > 0 {
+		groupBy = groupBy[:0]
+		seen := map[string]bool{}
+		for _, v := range raw {
+			dim, _ := v.(string)
+			dim = strings.TrimSpace(dim)
+			if dim == "" {
+				return client.ErrorResult(400, "group_by must list non-empty dimension names")
+			}
+			if !seen[dim] {
+				seen[dim] = true
+				groupBy = append(groupBy, dim)
+			}
+		}
+	}
+
+	sortBy := sortCount
+	if v, ok := args["sort_by"].(string); ok && v != "" {
+		switch v {
+		case sortCount, sortP50, sortP90, sortP99, sortErrorRate:
+			sortBy = v
+		default:
+			return client.ErrorResult(400, fmt.Sprintf("sort_by must be one of %s, %s, %s, %s, %s", sortCount, sortP50, sortP90, sortP99, sortErrorRate))
+		}
+	}
+
+	minutes := 60
+	if m, ok := args["time_range_minutes"].(float64); ok {
+		if m < 0 {
+			return client.ErrorResult(400, "time_range_minutes must not be negative")
+		}
+		if m > 0 {
+			minutes = int(m)
+			if minutes > 1440 {
+				minutes = 1440 // Max 24 hours
+			}
+		}
+	}
+
+	maxGroups := defaultStatsMaxGroups
+	if v, ok := args["max_groups"].(float64); ok {
+		if v < 0 {
+			return client.ErrorResult(400, "max_groups must not be negative")
+		}
+		if v > 0 {
+			maxGroups = int(v)
+			if maxGroups > maxStatsMaxGroups {
+				maxGroups = maxStatsMaxGroups
+			}
+		}
+	}
+
+	maxSpans, err := parseMaxSpans(args)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+
+	dataset := ""
+	if ds, ok := args["dataset"].(string); ok && ds != "" {
+		dataset = ds
+	} else {
+		dataset = p.client.GetDataset()
+	}
+
+	now := time.Now().UTC()
+	from := now.Add(-time.Duration(minutes) * time.Minute)
+	req := QuerySpansRequest{
+		Dataset: dataset,
+		TimeRange: TimeRange{
+			From: from.Format(time.RFC3339),
+			To:   now.Format(time.RFC3339),
+		},
+		Filter: filters,
+	}
+	flatSpans, sampled, errResult := Fetch(ctx, p.client, req, dataset, maxSpans)
+	if errResult != nil {
+		return errResult
+	}
+	flatSpans = matchers.Filter(flatSpans)
+
+	total, groups := computeStats(flatSpans, groupBy, sortBy)
+	stats := SpanStats{
+		From:       from.Format(time.RFC3339),
+		To:         now.Format(time.RFC3339),
+		GroupBy:    groupBy,
+		SortBy:     sortBy,
+		Sampled:    sampled,
+		Total:      total,
+		GroupCount: len(groups),
+	}
+	if len(groups) > maxGroups {
+		groups = groups[:maxGroups]
+	}
+	stats.Groups = groups
+	datasetArg, _ := args["dataset"].(string)
+	stats.FollowUps = followup.WithDataset(statsFollowUps(stats, args), datasetArg)
+
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: formatSpanStats(stats, filterDescs, maxSpans) + followup.Markdown(stats.FollowUps),
+		Data:     stats,
+	}
+}
+
+// k8sFilterArgs maps the Kubernetes filter arguments of the span query tools
+// to resource attributes.
+var k8sFilterArgs = []struct {
+	arg, key, label string
+}{
+	{"k8s_namespace", "k8s.namespace.name", "namespace"},
+	{"k8s_deployment_name", "k8s.deployment.name", "deployment"},
+	{"k8s_pod_name", "k8s.pod.name", "pod"},
+}
+
+// parseFilters builds the server-side span filters of the service_name,
+// http_method, http_status_code, span_name, Kubernetes, trace_id, span_id,
+// attribute_filters, and error_only arguments, with a short description of
+// each. Regex matches of service_name and span_name, and checks of the
+// trace and span IDs, are returned as Matchers for the caller to apply to
+// the spans read.
+func parseFilters(args map[string]interface{}) ([]AttributeFilter, []string, Matchers, error) {
+	var filters []AttributeFilter
+	var filterDescs []string
+	var matchers Matchers
+
+	addName := func(arg, key, label string) error {
+		value, _ := args[arg].(string)
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return nil
+		}
+		mode, _ := args[arg+"_match"].(string)
+		filter, matcher, desc, err := nameFilter(arg, key, label, value, mode)
+		if err != nil {
+			return err
+		}
+		if filter != nil {
+			filters = append(filters, *filter)
+		}
+		if matcher != nil {
+			matchers = append(matchers, *matcher)
+		}
+		filterDescs = append(filterDescs, desc)
+		return nil
+	}
+
+	if err := addName("service_name", "service.name", "service"); err != nil {
+		return nil, nil, nil, err
+	}
+
+	if httpMethod, ok := args["http_method"].(string); ok {
+		httpMethod = strings.TrimSpace(httpMethod)
+		if httpMethod != "" {
+			filters = append(filters, AttributeFilter{
+				Key:      "http.request.method",
+				Operator: "is",
+				Value:    &AttributeFilterValue{StringValue: &httpMethod},
+			})
+			filterDescs = append(filterDescs, "method="+httpMethod)
+		}
+	}
+
+	if statusCode, ok := args["http_status_code"].(float64); ok {
+		statusStr := strconv.Itoa(int(statusCode))
+		filters = append(filters, AttributeFilter{
+			Key:      "http.response.status_code",
+			Operator: "is",
+			Value:    &AttributeFilterValue{IntValue: &statusStr},
+		})
+		filterDescs = append(filterDescs, "status="+statusStr)
+	}
+
+	if err := addName("span_name", "name", "name"); err != nil {
+		return nil, nil, nil, err
+	}
+
+	for _, rf := range k8sFilterArgs {
+		if value, ok := args[rf.arg].(string); ok {
+			value = strings.TrimSpace(value)
+			if value != "" {
+				filters = append(filters, AttributeFilter{
+					Key:      rf.key,
+					Operator: "is",
+					Value:    &AttributeFilterValue{StringValue: &value},
+				})
+				filterDescs = append(filterDescs, rf.label+"="+value)
+			}
+		}
+	}
+
+	for _, id := range []struct{ arg, key, label string }{{"trace_id", traceIDKey, "trace"}, {"span_id", spanIDKey, "span"}} {
+		value, _ := args[id.arg].(string)
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if strings.ContainsAny(value, " \t") {
+			return nil, nil, nil, fmt.Errorf("%s must be a single ID, got %q", id.arg, value)
+		}
+		filter, matcher := idFilter(id.key, value)
+		filters = append(filters, filter)
+		matchers = append(matchers, matcher)
+		filterDescs = append(filterDescs, id.label+"="+value)
+	}
+
+	if raw, ok := args["attribute_filters"]; ok && raw != nil {
+		attrFilters, attrDescs, err := otlp.ParseAttributeFilters(raw)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		filters = append(filters, attrFilters...)
+		filterDescs = append(filterDescs, attrDescs...)
+	}
+
+	if errorOnly, ok := args["error_only"].(bool); ok && errorOnly {
+		errorCode := "2" // OTLP error status code
+		filters = append(filters, AttributeFilter{
+			Key:      "status.code",
+			Operator: "is",
+			Value:    &AttributeFilterValue{IntValue: &errorCode},
+		})
+		filterDescs = append(filterDescs, "errors_only")
+	}
+
+	return filters, filterDescs, matchers, nil
+}
+
+// ParseFilters builds the span filters of dash0_spans_query's filter
+// arguments, for tools that run the same query.
+func ParseFilters(args map[string]interface{}) ([]AttributeFilter, []string, Matchers, error) {
+	return parseFilters(args)
+}
+
 // deriveHasChildren sets HasChildren on each span by checking if its SpanID
 // appears as a ParentSpanID in any other span.
 func deriveHasChildren(spans []FlatSpan) {
@@ -438,7 +1229,7 @@ func topN(counts map[string]int, n int) []kvPair {
 }
 
 // formatSpansMarkdown renders spans as a markdown table.
-func formatSpansMarkdown(spans []FlatSpan, from, to time.Time, filterDescs []string, limit int) string {
+func formatSpansMarkdown(spans []FlatSpan, from, to time.Time, filterDescs []string, limit int, nextCursor string) string {
 	// Build summary
 	summaryParts := []string{fmt.Sprintf("**Found %d spans**", len(spans))}
 	summaryParts = append(summaryParts, fmt.Sprintf("Time: %s → %s", from.Format("15:04:05"), to.Format("15:04:05 2006-01-02")))
@@ -501,13 +1292,58 @@ func formatSpansMarkdown(spans []FlatSpan, from, to time.Time, filterDescs []str
 	}
 
 	footer := ""
-	if len(spans) >= limit {
+	if nextCursor != "" {
+		footer = fmt.Sprintf("_More spans available. Repeat the query with `cursor=%q` for the next page._", nextCursor)
+	} else if len(spans) >= limit {
 		footer = fmt.Sprintf("_Showing %d of %d+ spans (limit reached). Use `limit=%d` for more, or narrow filters._", len(spans), len(spans), limit*2)
 	}
 
 	return formatter.Table("Span Query Results", summary, headers, rows, footer)
 }
 
+// Fetch pages through a spans query until maxSpans spans are collected or
+// no further pages are available, for tools that analyze more spans than
+// one page holds. The returned flag reports whether more spans matched than
+// were collected.
+func Fetch(ctx context.Context, c *client.Client, req QuerySpansRequest, dataset string, maxSpans int) ([]FlatSpan, bool, *client.ToolResult) {
+	var collected []FlatSpan
+	cursor := ""
+
+	for len(collected) < maxSpans {
+		limit := queryPageSize
+		if remaining := maxSpans - len(collected); remaining < limit {
+			limit = remaining
+		}
+		req.Pagination = Pagination{Limit: limit, Cursor: cursor}
+
+		result := c.PostWithDataset(ctx, basePath, req, dataset)
+		if !result.Success {
+			return nil, false, result
+		}
+
+		page := FlattenResponse(result.Data)
+		collected = append(collected, page...)
+
+		cursor = otlp.NextCursor(result.Data)
+		if cursor == "" || len(page) == 0 {
+			return collected, false, nil
+		}
+	}
+
+	if len(collected) > maxSpans {
+		collected = collected[:maxSpans]
+	}
+	return collected, cursor != "", nil
+}
+
+// FlattenResponse extracts spans from an OTLP query response and derives
+// parent-child relationships, for tools that page through span queries themselves.
+func FlattenResponse(data interface{}) []FlatSpan {
+	flatSpans := flattenSpansResponse(data)
+	deriveHasChildren(flatSpans)
+	return flatSpans
+}
+
 // flattenSpansResponse extracts spans from nested OTLP response structure.
 func flattenSpansResponse(data interface{}) []FlatSpan {
 	var spans []FlatSpan
@@ -612,6 +1448,7 @@ func flattenSpansResponse(data interface{}) []FlatSpan {
 
 				// Extract key attributes
 				flat.Attributes = extractSpanAttributes(spanMap)
+				addExceptionAttributes(flat.Attributes, spanMap)
 
 				spans = append(spans, flat)
 			}
@@ -706,11 +1543,35 @@ func extractSpanAttributes(spanMap map[string]interface{}) map[string]interface{
 	return result
 }
 
+// addExceptionAttributes copies exception.type and exception.message from
+// the first exception event of a span, where OpenTelemetry SDKs record
+// them, unless the span itself has them.
+func addExceptionAttributes(attrs map[string]interface{}, spanMap map[string]interface{}) {
+	events, _ := spanMap["events"].([]interface{})
+	for _, e := range events {
+		eventMap, ok := e.(map[string]interface{})
+		if !ok || eventMap["name"] != "exception" {
+			continue
+		}
+		for _, key := range []string{"exception.type", "exception.message"} {
+			if _, ok := attrs[key]; ok {
+				continue
+			}
+			if v, ok := extractSpanAttributes(eventMap)[key]; ok {
+				attrs[key] = v
+			}
+		}
+		return
+	}
+}
+
 // Register registers all spans tools with the registry.
 func Register(reg *registry.Registry, c *client.Client) {
 	p := New(c)
 	for _, tool := range p.Tools() {
 		handler := p.Handlers()[tool.Name]
 		reg.Register(tool, handler)
+		reg.AddExamples(tool.Name, p.Examples()[tool.Name]...)
+		reg.SetOutputSchema(tool.Name, p.OutputSchemas()[tool.Name])
 	}
 }
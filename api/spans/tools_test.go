@@ -8,8 +8,13 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/followup"
+	"github.com/npcomplete777/dash0-mcp/internal/otlp"
+	"github.com/npcomplete777/dash0-mcp/internal/progress"
+	"github.com/npcomplete777/dash0-mcp/internal/truncate"
 )
 
 func TestNew(t *testing.T) {
@@ -27,13 +32,14 @@ func TestTools(t *testing.T) {
 	pkg := New(&client.Client{})
 	tools := pkg.Tools()
 
-	if len(tools) != 2 {
-		t.Errorf("Tools() returned %d tools, expected 2", len(tools))
+	if len(tools) != 3 {
+		t.Errorf("Tools() returned %d tools, expected 3", len(tools))
 	}
 
 	expectedNames := map[string]bool{
 		"dash0_spans_send":  false,
 		"dash0_spans_query": false,
+		"dash0_spans_stats": false,
 	}
 
 	for _, tool := range tools {
@@ -57,6 +63,7 @@ func TestHandlers(t *testing.T) {
 	expectedHandlers := []string{
 		"dash0_spans_send",
 		"dash0_spans_query",
+		"dash0_spans_stats",
 	}
 
 	if len(handlers) != len(expectedHandlers) {
@@ -160,6 +167,171 @@ func TestPostSpansHandler(t *testing.T) {
 	}
 }
 
+func TestPostSpansHandler_ContentEncoding(t *testing.T) {
+	var contentType, contentEncoding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType, contentEncoding = r.Header.Get("Content-Type"), r.Header.Get("Content-Encoding")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	ctx := context.Background()
+
+	result := pkg.PostSpansHandler(ctx, map[string]interface{}{"body": "CgISAA==", "content_encoding": "protobuf_gzip"})
+	if !result.Success || contentType != "application/x-protobuf" || contentEncoding != "gzip" {
+		t.Errorf("protobuf_gzip: success = %v, sent %s (%s)", result.Success, contentType, contentEncoding)
+	}
+
+	for _, tt := range []struct {
+		args map[string]interface{}
+		want string
+	}{
+		{map[string]interface{}{"body": map[string]interface{}{}, "content_encoding": "protobuf"}, "must be a base64 string"},
+		{map[string]interface{}{"body": "CgISAA==", "content_encoding": "protobuf", "verify": true}, "verify needs an OTLP JSON body"},
+	} {
+		result := pkg.PostSpansHandler(ctx, tt.args)
+		if result.Success || !strings.Contains(result.Error.Detail, tt.want) {
+			t.Errorf("args %v: got %+v, want an error containing %q", tt.args, result.Error, tt.want)
+		}
+	}
+}
+
+func TestPostSpansHandler_Verify(t *testing.T) {
+	verifyPollInterval = 10 * time.Millisecond
+	defer func() { verifyPollInterval = 2 * time.Second }()
+
+	sentBody := map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": []interface{}{
+						map[string]interface{}{"key": "service.name", "value": map[string]interface{}{"stringValue": "cart"}},
+					},
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"spans": []interface{}{
+							map[string]interface{}{"traceId": "t1", "spanId": "s1", "name": "a", "startTimeUnixNano": "1700000000000000000"},
+							map[string]interface{}{"traceId": "t1", "spanId": "s2", "name": "b", "startTimeUnixNano": "1700000000500000000"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		visibleAfter    int // query attempt from which s2 becomes visible; 0 = never
+		timeout         float64
+		expectVerified  bool
+		expectMissing   string
+		expectMarkdown  string
+		minimumAttempts int
+	}{
+		{
+			name:            "becomes queryable",
+			visibleAfter:    2,
+			timeout:         5,
+			expectVerified:  true,
+			expectMarkdown:  "all 2 spans are queryable",
+			minimumAttempts: 2,
+		},
+		{
+			name:            "dropped span",
+			visibleAfter:    0,
+			timeout:         0.05,
+			expectVerified:  false,
+			expectMissing:   "t1/s2",
+			expectMarkdown:  "1 of 2 spans",
+			minimumAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queries := 0
+			var queryFilter []AttributeFilter
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body map[string]interface{}
+				json.NewDecoder(r.Body).Decode(&body)
+				if _, isQuery := body["timeRange"]; !isQuery {
+					json.NewEncoder(w).Encode(map[string]interface{}{"partialSuccess": map[string]interface{}{}})
+					return
+				}
+
+				queries++
+				raw, _ := json.Marshal(body["filter"])
+				json.Unmarshal(raw, &queryFilter)
+
+				spanList := []interface{}{
+					map[string]interface{}{"traceId": "t1", "spanId": "s1", "startTimeUnixNano": "1700000000000000000", "endTimeUnixNano": "1700000000100000000"},
+				}
+				if tt.visibleAfter > 0 && queries >= tt.visibleAfter {
+					spanList = append(spanList, map[string]interface{}{"traceId": "t1", "spanId": "s2", "startTimeUnixNano": "1700000000500000000", "endTimeUnixNano": "1700000000600000000"})
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"resourceSpans": []interface{}{
+						map[string]interface{}{"scopeSpans": []interface{}{map[string]interface{}{"spans": spanList}}},
+					},
+				})
+			}))
+			defer server.Close()
+
+			pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+			result := pkg.PostSpansHandler(context.Background(), map[string]interface{}{
+				"body":                   sentBody,
+				"verify":                 true,
+				"verify_timeout_seconds": tt.timeout,
+			})
+			if !result.Success {
+				t.Fatalf("PostSpansHandler failed: %v", result.Error)
+			}
+
+			data := result.Data.(map[string]interface{})
+			v := data["verification"].(otlp.IngestVerification)
+			if v.Verified != tt.expectVerified {
+				t.Errorf("Verified = %v, expected %v", v.Verified, tt.expectVerified)
+			}
+			if v.Expected != 2 {
+				t.Errorf("Expected = %d, expected 2", v.Expected)
+			}
+			if v.Attempts < tt.minimumAttempts {
+				t.Errorf("Attempts = %d, expected at least %d", v.Attempts, tt.minimumAttempts)
+			}
+			if tt.expectMissing != "" && (len(v.Missing) != 1 || v.Missing[0] != tt.expectMissing) {
+				t.Errorf("Missing = %v, expected [%s]", v.Missing, tt.expectMissing)
+			}
+			if !strings.Contains(result.Markdown, tt.expectMarkdown) {
+				t.Errorf("Markdown = %q, expected to contain %q", result.Markdown, tt.expectMarkdown)
+			}
+			if len(queryFilter) != 1 || queryFilter[0].Key != "service.name" {
+				t.Errorf("expected verification query filtered by service.name, got %+v", queryFilter)
+			}
+		})
+	}
+}
+
+func TestPostSpansHandler_VerifyRequiresIDs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{})
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.PostSpansHandler(context.Background(), map[string]interface{}{
+		"body":   map[string]interface{}{"resourceSpans": []interface{}{}},
+		"verify": true,
+	})
+	if result.Success {
+		t.Fatal("expected error when no span IDs can be verified")
+	}
+	if !strings.Contains(result.Error.Detail, "traceId and spanId") {
+		t.Errorf("Error = %q", result.Error.Detail)
+	}
+}
+
 func TestQuerySpansToolDefinition(t *testing.T) {
 	pkg := New(&client.Client{})
 	tool := pkg.QuerySpans()
@@ -181,7 +353,9 @@ func TestQuerySpansToolDefinition(t *testing.T) {
 		"error_only",
 		"min_duration_ms",
 		"span_name",
+		"attribute_filters",
 		"limit",
+		"cursor",
 	}
 
 	for _, prop := range expectedProps {
@@ -287,6 +461,280 @@ func TestQuerySpansHandler_Filters(t *testing.T) {
 	}
 }
 
+func TestNameFilter(t *testing.T) {
+	tests := []struct {
+		mode, value      string
+		operator, server string // empty operator: no server-side filter
+		desc             string
+		matches          map[string]bool
+	}{
+		{"", "cart", "is", "cart", "service=cart", nil},
+		{"contains", "cart", "contains", "cart", "service contains cart", nil},
+		{"prefix", "cart-", "starts_with", "cart-", "service starts_with cart-", nil},
+		{"regex", "cart-v\\d+$", "contains", "cart-v", "service =~ /cart-v\\d+$/", map[string]bool{"cart-v2": true, "cart-v2-canary": false, "cart": false}},
+		{"regex", "(?i)^CART", "", "", "service =~ /(?i)^CART/", map[string]bool{"cart-v2": true, "shop-cart": false}},
+	}
+	for _, tt := range tests {
+		filter, matcher, desc, err := nameFilter("service_name", "service.name", "service", tt.value, tt.mode)
+		if err != nil {
+			t.Fatalf("nameFilter(%s, %s) error = %v", tt.mode, tt.value, err)
+		}
+		if tt.operator == "" && filter != nil || tt.operator != "" && (filter == nil || filter.Operator != tt.operator || *filter.Value.StringValue != tt.server) {
+			t.Errorf("nameFilter(%s, %s) filter = %+v, want %s %s", tt.mode, tt.value, filter, tt.operator, tt.server)
+		}
+		if desc != tt.desc {
+			t.Errorf("nameFilter(%s, %s) desc = %q, want %q", tt.mode, tt.value, desc, tt.desc)
+		}
+		if (matcher != nil) != (tt.mode == "regex") {
+			t.Errorf("nameFilter(%s, %s) matcher = %v", tt.mode, tt.value, matcher)
+		}
+		for service, want := range tt.matches {
+			if got := (Matchers{*matcher}).Match(FlatSpan{ServiceName: service}); got != want {
+				t.Errorf("/%s/ matches %s = %v, want %v", tt.value, service, got, want)
+			}
+		}
+	}
+
+	for _, mode := range []string{"glob", "regex"} {
+		if _, _, _, err := nameFilter("span_name", "name", "name", "GET (", mode); err == nil {
+			t.Errorf("nameFilter(%s, \"GET (\") error = nil, want an error", mode)
+		}
+	}
+}
+
+func TestQuerySpansHandler_RegexMatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req QuerySpansRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Filter) != 1 || req.Filter[0].Key != "name" || req.Filter[0].Operator != "contains" || *req.Filter[0].Value.StringValue != "GET /api/orders" {
+			t.Errorf("unexpected filters: %+v", req.Filter)
+		}
+		var otlpSpans []interface{}
+		for i, name := range []string{"GET /api/orders", "GET /api/orders/{id}", "GET /api/cart"} {
+			otlpSpans = append(otlpSpans, map[string]interface{}{
+				"traceId":           fmt.Sprintf("trace-%d", i),
+				"spanId":            fmt.Sprintf("span-%d", i),
+				"name":              name,
+				"startTimeUnixNano": "1000000000",
+				"endTimeUnixNano":   "2000000000",
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"resourceSpans": []interface{}{map[string]interface{}{
+				"scopeSpans": []interface{}{map[string]interface{}{"spans": otlpSpans}},
+			}},
+		})
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.QuerySpansHandler(context.Background(), map[string]interface{}{
+		"span_name":       "GET /api/orders",
+		"span_name_match": "regex",
+	})
+	if !result.Success {
+		t.Fatalf("QuerySpansHandler failed: %v", result.Error)
+	}
+	data := result.Data.(map[string]interface{})
+	if data["count"] != 2 {
+		t.Errorf("count = %v, want the 2 order spans", data["count"])
+	}
+	if !strings.Contains(result.Markdown, "name =~ /GET /api/orders/") {
+		t.Errorf("markdown missing the regex filter:\n%s", result.Markdown)
+	}
+
+	bad := pkg.QuerySpansHandler(context.Background(), map[string]interface{}{"service_name": "cart", "service_name_match": "fuzzy"})
+	if bad.Success || bad.Error.StatusCode != 400 || !strings.Contains(bad.Error.Detail, "service_name_match must be one of") {
+		t.Errorf("result = %+v, want a 400 for the match mode", bad)
+	}
+}
+
+func TestQuerySpansHandler_K8sFilters(t *testing.T) {
+	var received QuerySpansRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		json.NewEncoder(w).Encode(map[string]interface{}{"resourceSpans": []interface{}{}})
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.QuerySpansHandler(context.Background(), map[string]interface{}{
+		"k8s_namespace":       "shop",
+		"k8s_deployment_name": " cart ",
+		"k8s_pod_name":        "cart-7d9f-abcde",
+	})
+	if !result.Success {
+		t.Fatalf("QuerySpansHandler failed: %v", result.Error)
+	}
+
+	expected := []struct{ key, value string }{
+		{"k8s.namespace.name", "shop"},
+		{"k8s.deployment.name", "cart"},
+		{"k8s.pod.name", "cart-7d9f-abcde"},
+	}
+	if len(received.Filter) != len(expected) {
+		t.Fatalf("filters = %+v, want %d", received.Filter, len(expected))
+	}
+	for i, want := range expected {
+		f := received.Filter[i]
+		if f.Key != want.key || f.Operator != "is" || *f.Value.StringValue != want.value {
+			t.Errorf("filter[%d] = %+v, want %s is %s", i, f, want.key, want.value)
+		}
+	}
+	if !strings.Contains(result.Markdown, "namespace=shop, deployment=cart, pod=cart-7d9f-abcde") {
+		t.Errorf("markdown missing the Kubernetes filters:\n%s", result.Markdown)
+	}
+}
+
+func TestQuerySpansHandler_TraceID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req QuerySpansRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Filter) != 1 || req.Filter[0].Key != "otel.trace.id" || *req.Filter[0].Value.StringValue != "4BF92F35" {
+			t.Errorf("unexpected filters: %+v", req.Filter)
+		}
+		var otlpSpans []interface{}
+		for i, traceID := range []string{"4bf92f35", "4bf92f35", "0af76519"} {
+			otlpSpans = append(otlpSpans, map[string]interface{}{
+				"traceId":           traceID,
+				"spanId":            fmt.Sprintf("span-%d", i),
+				"name":              "GET /api/orders",
+				"startTimeUnixNano": "1000000000",
+				"endTimeUnixNano":   "2000000000",
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"resourceSpans": []interface{}{map[string]interface{}{
+				"scopeSpans": []interface{}{map[string]interface{}{"spans": otlpSpans}},
+			}},
+		})
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.QuerySpansHandler(context.Background(), map[string]interface{}{
+		"trace_id":           " 4BF92F35 ",
+		"time_range_minutes": float64(180),
+	})
+	if !result.Success {
+		t.Fatalf("QuerySpansHandler failed: %v", result.Error)
+	}
+	data := result.Data.(map[string]interface{})
+	if data["count"] != 2 {
+		t.Errorf("count = %v, want the 2 spans of the trace", data["count"])
+	}
+	suggestions, _ := data["suggested_follow_ups"].([]followup.Suggestion)
+	if len(suggestions) != 1 || suggestions[0].Tool != "dash0_logs_query" ||
+		suggestions[0].Arguments["trace_id"] != "4BF92F35" || suggestions[0].Arguments["time_range_minutes"] != 180 {
+		t.Errorf("follow-ups = %+v, want dash0_logs_query for the trace", suggestions)
+	}
+	if !strings.Contains(result.Markdown, "trace=4BF92F35") {
+		t.Errorf("markdown missing the trace filter:\n%s", result.Markdown)
+	}
+
+	bad := pkg.QuerySpansHandler(context.Background(), map[string]interface{}{"span_id": "a b"})
+	if bad.Success || bad.Error.StatusCode != 400 || !strings.Contains(bad.Error.Detail, "span_id must be a single ID") {
+		t.Errorf("result = %+v, want a 400 for the span ID", bad)
+	}
+}
+
+func TestQuerySpansHandler_OrderBy(t *testing.T) {
+	pages := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req QuerySpansRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		pages++
+		// Two pages, the slowest span on the second.
+		durations := []int{120, 40, 300}
+		next := "page-2"
+		if req.Pagination.Cursor == "page-2" {
+			durations, next = []int{900, 80}, ""
+		}
+		var otlpSpans []interface{}
+		for i, ms := range durations {
+			otlpSpans = append(otlpSpans, map[string]interface{}{
+				"traceId":           fmt.Sprintf("trace-%d-%d", pages, i),
+				"spanId":            fmt.Sprintf("span-%d-%d", pages, i),
+				"name":              "GET /api/cart",
+				"startTimeUnixNano": fmt.Sprintf("%d", int64(pages*10+i)*1e9),
+				"endTimeUnixNano":   fmt.Sprintf("%d", int64(pages*10+i)*1e9+int64(ms)*1e6),
+			})
+		}
+		resp := map[string]interface{}{
+			"resourceSpans": []interface{}{map[string]interface{}{
+				"scopeSpans": []interface{}{map[string]interface{}{"spans": otlpSpans}},
+			}},
+		}
+		if next != "" {
+			resp["cursors"] = map[string]interface{}{"after": next}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.QuerySpansHandler(context.Background(), map[string]interface{}{
+		"order_by": "duration",
+		"limit":    float64(3),
+	})
+	if !result.Success {
+		t.Fatalf("QuerySpansHandler failed: %v", result.Error)
+	}
+	data := result.Data.(map[string]interface{})
+	got := data["spans"].([]FlatSpan)
+	var durations []float64
+	for _, s := range got {
+		durations = append(durations, s.DurationMs)
+	}
+	if fmt.Sprint(durations) != "[900 300 120]" || data["matched"] != 5 {
+		t.Errorf("durations = %v of %v matched, want [900 300 120] of 5", durations, data["matched"])
+	}
+	if !strings.Contains(result.Markdown, "Sorted by duration desc across the 5 matching spans read") {
+		t.Errorf("markdown missing the order note:\n%s", result.Markdown)
+	}
+
+	pages = 0
+	result = pkg.QuerySpansHandler(context.Background(), map[string]interface{}{
+		"order_by":  "timestamp",
+		"direction": "asc",
+		"limit":     float64(1),
+	})
+	if got := result.Data.(map[string]interface{})["spans"].([]FlatSpan); len(got) != 1 || got[0].DurationMs != 120 {
+		t.Errorf("spans = %+v, want the earliest span", got)
+	}
+
+	// The spans of the result are streamed as a progress batch once sorted
+	// and cut to limit and max_items
+	type update struct {
+		progress float64
+		message  string
+		batch    []FlatSpan
+	}
+	var updates []update
+	ctx := progress.WithReporter(context.Background(), func(p, _ float64, message string, batch interface{}) {
+		spans, _ := batch.([]FlatSpan)
+		updates = append(updates, update{p, message, spans})
+	})
+	pages = 0
+	pkg.QuerySpansHandler(ctx, map[string]interface{}{"order_by": "duration", "limit": float64(2), "max_items": float64(1)})
+	if len(updates) != 1 || updates[0].progress != 1 || len(updates[0].batch) != 1 || updates[0].batch[0].DurationMs != 900 {
+		t.Errorf("progress updates = %+v, want one batch with the slowest span", updates)
+	} else if updates[0].message != "1 spans (1 so far)" {
+		t.Errorf("message = %q", updates[0].message)
+	}
+
+	for _, args := range []map[string]interface{}{
+		{"order_by": "severity"},
+		{"order_by": "duration", "direction": "up"},
+		{"direction": "asc"},
+		{"order_by": "duration", "cursor": "page-2"},
+	} {
+		if bad := pkg.QuerySpansHandler(context.Background(), args); bad.Success || bad.Error.StatusCode != 400 {
+			t.Errorf("%v: result = %+v, want a 400", args, bad)
+		}
+	}
+}
+
 func TestQuerySpansHandler_TimeRange(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -392,6 +840,238 @@ func TestQuerySpansHandler_Limit(t *testing.T) {
 	}
 }
 
+func TestQuerySpansHandler_AttributeFilters(t *testing.T) {
+	var receivedRequest QuerySpansRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&receivedRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"resourceSpans": []interface{}{},
+		})
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.QuerySpansHandler(context.Background(), map[string]interface{}{
+		"attribute_filters": []interface{}{
+			map[string]interface{}{"key": "k8s.pod.name", "operator": "starts_with", "value": "cart-"},
+			map[string]interface{}{"key": "http.response.status_code", "operator": "gt", "value": float64(499)},
+			map[string]interface{}{"key": "app.cache_ratio", "operator": "lt", "value": 0.5},
+			map[string]interface{}{"key": "app.canary", "value": true},
+		},
+	})
+	if !result.Success {
+		t.Fatalf("QuerySpansHandler failed: %v", result.Error)
+	}
+
+	if len(receivedRequest.Filter) != 4 {
+		t.Fatalf("Filter count = %d, expected 4", len(receivedRequest.Filter))
+	}
+
+	pod := receivedRequest.Filter[0]
+	if pod.Key != "k8s.pod.name" || pod.Operator != "starts_with" || pod.Value.StringValue == nil || *pod.Value.StringValue != "cart-" {
+		t.Errorf("unexpected pod filter: %+v", pod)
+	}
+	status := receivedRequest.Filter[1]
+	if status.Operator != "gt" || status.Value.IntValue == nil || *status.Value.IntValue != "499" {
+		t.Errorf("unexpected status filter: %+v", status)
+	}
+	ratio := receivedRequest.Filter[2]
+	if ratio.Value.DoubleValue == nil || *ratio.Value.DoubleValue != 0.5 {
+		t.Errorf("unexpected ratio filter: %+v", ratio)
+	}
+	canary := receivedRequest.Filter[3]
+	if canary.Operator != "is" || canary.Value.BoolValue == nil || !*canary.Value.BoolValue {
+		t.Errorf("unexpected canary filter: %+v", canary)
+	}
+
+	if !strings.Contains(result.Markdown, "k8s.pod.name starts_with cart-") {
+		t.Errorf("Markdown missing filter description: %s", result.Markdown)
+	}
+}
+
+func TestQuerySpansHandler_AttributeFiltersValidation(t *testing.T) {
+	pkg := New(client.NewWithBaseURL("http://example.com", "test-token"))
+
+	tests := []struct {
+		name        string
+		filters     interface{}
+		expectError string
+	}{
+		{
+			name:        "not an array",
+			filters:     "k8s.pod.name=cart",
+			expectError: "must be an array",
+		},
+		{
+			name:        "missing key",
+			filters:     []interface{}{map[string]interface{}{"value": "x"}},
+			expectError: "key is required",
+		},
+		{
+			name:        "unknown operator",
+			filters:     []interface{}{map[string]interface{}{"key": "a", "operator": "regex", "value": "x"}},
+			expectError: "operator must be one of",
+		},
+		{
+			name:        "missing value",
+			filters:     []interface{}{map[string]interface{}{"key": "a"}},
+			expectError: "value is required",
+		},
+		{
+			name:        "numeric contains",
+			filters:     []interface{}{map[string]interface{}{"key": "a", "operator": "contains", "value": float64(1)}},
+			expectError: "must be a string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := pkg.QuerySpansHandler(context.Background(), map[string]interface{}{
+				"attribute_filters": tt.filters,
+			})
+			if result.Success {
+				t.Fatal("Expected error, got success")
+			}
+			if !strings.Contains(result.Error.Detail, tt.expectError) {
+				t.Errorf("Error = %q, expected to contain %q", result.Error.Detail, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestQuerySpansHandler_ResponseLimits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		spanList := make([]interface{}, 20)
+		for i := range spanList {
+			spanList[i] = map[string]interface{}{
+				"traceId":           fmt.Sprintf("trace-%d", i),
+				"spanId":            fmt.Sprintf("span-%d", i),
+				"name":              "GET /api/cart",
+				"startTimeUnixNano": "1700000000000000000",
+				"endTimeUnixNano":   "1700000000100000000",
+				"attributes": []interface{}{
+					map[string]interface{}{"key": "http.route", "value": map[string]interface{}{"stringValue": strings.Repeat("/x", 100)}},
+				},
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"resourceSpans": []interface{}{
+				map[string]interface{}{"scopeSpans": []interface{}{map[string]interface{}{"spans": spanList}}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	c := client.NewWithBaseURL(server.URL, "test-token")
+	pkg := New(c)
+
+	t.Run("max_items", func(t *testing.T) {
+		result := pkg.QuerySpansHandler(context.Background(), map[string]interface{}{"max_items": float64(5)})
+		if !result.Success {
+			t.Fatalf("QuerySpansHandler failed: %v", result.Error)
+		}
+		data := result.Data.(map[string]interface{})
+		if data["count"] != 5 || data["truncated"] != true {
+			t.Errorf("count = %v, truncated = %v, expected 5/true", data["count"], data["truncated"])
+		}
+		report := data["truncation"].(truncate.Report)
+		if report.OriginalItems != 20 || report.ReturnedItems != 5 {
+			t.Errorf("report = %+v", report)
+		}
+	})
+
+	t.Run("max_response_bytes", func(t *testing.T) {
+		result := pkg.QuerySpansHandler(context.Background(), map[string]interface{}{"max_response_bytes": float64(2000)})
+		if !result.Success {
+			t.Fatalf("QuerySpansHandler failed: %v", result.Error)
+		}
+		if len(result.Markdown) > 2000 {
+			t.Errorf("markdown length = %d, expected <= 2000", len(result.Markdown))
+		}
+		encoded, _ := json.Marshal(result.Data)
+		if len(encoded) > 2500 {
+			t.Errorf("data length = %d, expected roughly <= 2000", len(encoded))
+		}
+		if result.Data.(map[string]interface{})["truncated"] != true {
+			t.Error("expected truncated = true")
+		}
+	})
+
+	t.Run("server default", func(t *testing.T) {
+		c.SetResponseLimits(truncate.Limits{MaxItems: 3})
+		defer c.SetResponseLimits(truncate.Limits{})
+
+		result := pkg.QuerySpansHandler(context.Background(), map[string]interface{}{})
+		if data := result.Data.(map[string]interface{}); data["count"] != 3 {
+			t.Errorf("count = %v, expected server default of 3", data["count"])
+		}
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		result := pkg.QuerySpansHandler(context.Background(), map[string]interface{}{"max_items": float64(-1)})
+		if result.Success {
+			t.Error("expected error for negative max_items")
+		}
+	})
+}
+
+func TestQuerySpansHandler_Cursor(t *testing.T) {
+	var requests []QuerySpansRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req QuerySpansRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"resourceSpans": []interface{}{},
+			"cursors":       map[string]interface{}{"after": "page-3"},
+		})
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.QuerySpansHandler(context.Background(), map[string]interface{}{
+		"cursor":             "page-2",
+		"time_range_minutes": float64(30),
+	})
+	if !result.Success {
+		t.Fatalf("QuerySpansHandler failed: %v", result.Error)
+	}
+
+	if requests[0].Pagination.Cursor != "page-2" {
+		t.Errorf("Cursor = %q, expected an API cursor to be sent as is", requests[0].Pagination.Cursor)
+	}
+
+	data := result.Data.(map[string]interface{})
+	nextCursor, _ := data["next_cursor"].(string)
+	if nextCursor == "" {
+		t.Fatal("expected next_cursor")
+	}
+	if data["has_more"] != true {
+		t.Error("expected has_more = true")
+	}
+	if !strings.Contains(result.Markdown, fmt.Sprintf("cursor=%q", nextCursor)) {
+		t.Errorf("Markdown missing next page hint: %s", result.Markdown)
+	}
+
+	// The next page is read from the first page's window, whatever
+	// time_range_minutes says
+	result = pkg.QuerySpansHandler(context.Background(), map[string]interface{}{
+		"cursor":             nextCursor,
+		"time_range_minutes": float64(60),
+	})
+	if !result.Success {
+		t.Fatalf("QuerySpansHandler failed: %v", result.Error)
+	}
+	if requests[1].Pagination.Cursor != "page-3" {
+		t.Errorf("Cursor = %q, expected page-3", requests[1].Pagination.Cursor)
+	}
+	if requests[1].TimeRange != requests[0].TimeRange {
+		t.Errorf("TimeRange = %+v, expected the first page's %+v", requests[1].TimeRange, requests[0].TimeRange)
+	}
+}
+
 func TestFlattenSpansResponse(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -892,6 +1572,34 @@ func TestExtractSpanAttributes(t *testing.T) {
 	}
 }
 
+func TestAddExceptionAttributes(t *testing.T) {
+	attr := func(key, value string) map[string]interface{} {
+		return map[string]interface{}{"key": key, "value": map[string]interface{}{"stringValue": value}}
+	}
+	spanMap := map[string]interface{}{
+		"events": []interface{}{
+			map[string]interface{}{"name": "retry", "attributes": []interface{}{attr("exception.type", "Ignored")}},
+			map[string]interface{}{"name": "exception", "attributes": []interface{}{
+				attr("exception.type", "java.net.SocketTimeoutException"),
+				attr("exception.message", "Read timed out"),
+			}},
+			map[string]interface{}{"name": "exception", "attributes": []interface{}{attr("exception.type", "Second")}},
+		},
+	}
+
+	attrs := map[string]interface{}{}
+	addExceptionAttributes(attrs, spanMap)
+	if attrs["exception.type"] != "java.net.SocketTimeoutException" || attrs["exception.message"] != "Read timed out" {
+		t.Errorf("attributes = %v, want the first exception event", attrs)
+	}
+
+	attrs = map[string]interface{}{"exception.type": "OnSpan"}
+	addExceptionAttributes(attrs, spanMap)
+	if attrs["exception.type"] != "OnSpan" || attrs["exception.message"] != "Read timed out" {
+		t.Errorf("attributes = %v, want the span's own type kept", attrs)
+	}
+}
+
 func TestQuerySpansHandler_DurationFilter(t *testing.T) {
 	// Test that min_duration_ms filter is applied client-side
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1221,6 +1929,284 @@ func TestMarkdownOutput_HasChildren(t *testing.T) {
 }
 
 // Helper function to create formatted errors
+func TestComputeStats(t *testing.T) {
+	span := func(service, route, name string, durationMs float64, status int) FlatSpan {
+		s := FlatSpan{ServiceName: service, Name: name, DurationMs: durationMs, StatusCode: status, Attributes: map[string]interface{}{}}
+		if route != "" {
+			s.Attributes["http.route"] = route
+		}
+		return s
+	}
+	var ss []FlatSpan
+	for i := 1; i <= 10; i++ {
+		status := 0
+		if i == 10 {
+			status = 2
+		}
+		ss = append(ss, span("checkout", "/cart", "GET /cart", float64(i*10), status))
+	}
+	ss = append(ss,
+		span("checkout", "", "SELECT orders", 500, 2),
+		span("checkout", "", "SELECT orders", 700, 0),
+		span("", "/pay", "POST /pay", 5, 0),
+	)
+
+	total, groups := computeStats(ss, []string{"service", "route"}, sortCount)
+	if total.Count != 13 || total.Errors != 2 {
+		t.Errorf("total = %+v, want 13 spans with 2 errors", total)
+	}
+	if len(groups) != 3 {
+		t.Fatalf("groups = %+v, want 3", groups)
+	}
+	cart := groups[0]
+	if cart.Group["route"] != "/cart" || cart.Count != 10 || cart.P50Ms != 50 || cart.P90Ms != 90 || cart.P99Ms != 100 || cart.ErrorRate != 10 || cart.MaxMs != 100 {
+		t.Errorf("cart = %+v", cart)
+	}
+	if db := groups[1]; db.Group["route"] != "SELECT orders" || !db.SpanNameRoute || db.ErrorRate != 50 {
+		t.Errorf("groups[1] = %+v, want the span name as route", db)
+	}
+	if pay := groups[2]; pay.Group["service"] != "(unset)" {
+		t.Errorf("groups[2] = %+v, want an unset service", pay)
+	}
+
+	_, byP99 := computeStats(ss, []string{"route"}, sortP99)
+	if byP99[0].Group["route"] != "SELECT orders" {
+		t.Errorf("sorted by p99: first = %+v", byP99[0])
+	}
+}
+
+func TestSpanStatsHandler(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var req QuerySpansRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Pagination.Limit != 200 {
+			t.Errorf("page limit = %d, want 200", req.Pagination.Limit)
+		}
+		var otlpSpans []interface{}
+		for i := 0; i < 200; i++ {
+			route, status := "/cart", 0
+			if i%4 == 0 {
+				route, status = "/pay", 2
+			}
+			otlpSpans = append(otlpSpans, map[string]interface{}{
+				"traceId":           fmt.Sprintf("trace%d-%d", requests, i),
+				"spanId":            fmt.Sprintf("span%d-%d", requests, i),
+				"name":              "HTTP",
+				"startTimeUnixNano": "1000000000",
+				"endTimeUnixNano":   fmt.Sprintf("%d", 1000000000+(i+1)*1000000),
+				"status":            map[string]interface{}{"code": status},
+				"attributes": []interface{}{
+					map[string]interface{}{"key": "http.route", "value": map[string]interface{}{"stringValue": route}},
+				},
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"resourceSpans": []interface{}{map[string]interface{}{
+				"resource": map[string]interface{}{"attributes": []interface{}{
+					map[string]interface{}{"key": "service.name", "value": map[string]interface{}{"stringValue": "checkout"}},
+				}},
+				"scopeSpans": []interface{}{map[string]interface{}{"spans": otlpSpans}},
+			}},
+			"cursors": map[string]interface{}{"after": "next"},
+		})
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.SpanStatsHandler(context.Background(), map[string]interface{}{
+		"service_name": "checkout",
+		"group_by":     []interface{}{"route"},
+		"sort_by":      "error_rate",
+		"max_spans":    float64(400),
+	})
+	if !result.Success {
+		t.Fatalf("SpanStatsHandler failed: %v", result.Error)
+	}
+	stats := result.Data.(SpanStats)
+	if requests != 2 || stats.Total.Count != 400 || !stats.Sampled {
+		t.Errorf("requests = %d, total = %d, sampled = %v; want 2 pages of 400 sampled spans", requests, stats.Total.Count, stats.Sampled)
+	}
+	if len(stats.Groups) != 2 || stats.Groups[0].Group["route"] != "/pay" || stats.Groups[0].ErrorRate != 100 {
+		t.Errorf("groups = %+v, want /pay first by error rate", stats.Groups)
+	}
+	if len(stats.FollowUps) != 2 || stats.FollowUps[1].Arguments["error_only"] != true || stats.FollowUps[1].Arguments["service_name"] != "checkout" {
+		t.Errorf("follow-ups = %+v", stats.FollowUps)
+	}
+	for _, s := range []string{"Span Duration Statistics", "| route | Count |", "/pay", "statistics cover the first 400"} {
+		if !strings.Contains(result.Markdown, s) {
+			t.Errorf("markdown missing %q:\n%s", s, result.Markdown)
+		}
+	}
+}
+
+func TestSpanStatsHandler_Validation(t *testing.T) {
+	pkg := New(client.NewWithBaseURL("http://localhost", "test-token"))
+	for name, args := range map[string]map[string]interface{}{
+		"sort_by":            {"sort_by": "p95"},
+		"empty group_by":     {"group_by": []interface{}{""}},
+		"negative max_spans": {"max_spans": float64(-1)},
+		"negative minutes":   {"time_range_minutes": float64(-5)},
+	} {
+		if result := pkg.SpanStatsHandler(context.Background(), args); result.Success || result.Error.StatusCode != 400 {
+			t.Errorf("%s: result = %+v, want a 400", name, result)
+		}
+	}
+}
+
+func TestBuildHistogram(t *testing.T) {
+	span := func(service string, durationMs float64) FlatSpan {
+		return FlatSpan{ServiceName: service, DurationMs: durationMs}
+	}
+	ss := []FlatSpan{span("cart", 5), span("cart", 10), span("cart", 80), span("cart", 2000), span("pay", 100), span("", 50)}
+
+	total, groups := buildHistogram(ss, "service", []float64{10, 100, 1000})
+	if total.Count != 6 || fmt.Sprint(total.Counts) != "[2 3 0 1]" {
+		t.Errorf("total = %+v, want [2 3 0 1]", total)
+	}
+	if len(groups) != 3 || groups[0].Group != "cart" || fmt.Sprint(groups[0].Counts) != "[2 1 0 1]" {
+		t.Fatalf("groups = %+v", groups)
+	}
+	if groups[1].Group != "(unset)" || groups[2].Group != "pay" || fmt.Sprint(groups[2].Counts) != "[0 1 0 0]" {
+		t.Errorf("groups = %+v, want (unset) and pay with one span each", groups)
+	}
+
+	if labels := bucketLabels([]float64{10, 1000}); strings.Join(labels, " ") != "≤10ms ≤1s >1s" {
+		t.Errorf("bucketLabels() = %v", labels)
+	}
+}
+
+func TestQuerySpansHandler_Histogram(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req QuerySpansRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Pagination.Limit != 200 {
+			t.Errorf("page limit = %d, want 200", req.Pagination.Limit)
+		}
+		var otlpSpans []interface{}
+		for i := 0; i < 10; i++ {
+			route := "/cart"
+			if i >= 8 {
+				route = "/pay"
+			}
+			otlpSpans = append(otlpSpans, map[string]interface{}{
+				"traceId":           fmt.Sprintf("trace-%d", i),
+				"spanId":            fmt.Sprintf("span-%d", i),
+				"name":              "HTTP",
+				"startTimeUnixNano": "1000000000",
+				"endTimeUnixNano":   fmt.Sprintf("%d", 1000000000+(i+1)*100000000),
+				"attributes": []interface{}{
+					map[string]interface{}{"key": "http.route", "value": map[string]interface{}{"stringValue": route}},
+				},
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"resourceSpans": []interface{}{map[string]interface{}{
+				"scopeSpans": []interface{}{map[string]interface{}{"spans": otlpSpans}},
+			}},
+		})
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.QuerySpansHandler(context.Background(), map[string]interface{}{
+		"output":             "histogram",
+		"histogram_group_by": "route",
+		"buckets":            []interface{}{float64(300), float64(800)},
+		"min_duration_ms":    float64(200),
+	})
+	if !result.Success {
+		t.Fatalf("QuerySpansHandler failed: %v", result.Error)
+	}
+	h := result.Data.(map[string]interface{})["histogram"].(SpanHistogram)
+	// Durations are 100ms to 1s; the 100ms span is below min_duration_ms.
+	if h.Total.Count != 9 || fmt.Sprint(h.Total.Counts) != "[2 5 2]" || h.Sampled {
+		t.Errorf("total = %+v", h.Total)
+	}
+	if len(h.Groups) != 2 || h.Groups[0].Group != "/cart" || fmt.Sprint(h.Groups[1].Counts) != "[0 0 2]" {
+		t.Errorf("groups = %+v", h.Groups)
+	}
+	for _, s := range []string{"Span Duration Histogram", "| route | Count | ≤300ms | ≤800ms | >800ms | Distribution |", "| /pay | 2 | 0 | 0 | 2 | ··█ |", "min_duration>=200ms"} {
+		if !strings.Contains(result.Markdown, s) {
+			t.Errorf("markdown missing %q:\n%s", s, result.Markdown)
+		}
+	}
+}
+
+func TestQuerySpansHandler_HistogramValidation(t *testing.T) {
+	pkg := New(client.NewWithBaseURL("http://localhost", "test-token"))
+	for name, args := range map[string]map[string]interface{}{
+		"unknown output":     {"output": "chart"},
+		"cursor":             {"output": "histogram", "cursor": "abc"},
+		"empty buckets":      {"output": "histogram", "buckets": []interface{}{}},
+		"unsorted buckets":   {"output": "histogram", "buckets": []interface{}{float64(100), float64(50)}},
+		"negative bucket":    {"output": "histogram", "buckets": []interface{}{float64(-1)}},
+		"negative max_spans": {"output": "histogram", "max_spans": float64(-1)},
+	} {
+		if result := pkg.QuerySpansHandler(context.Background(), args); result.Success || result.Error.StatusCode != 400 {
+			t.Errorf("%s: result = %+v, want a 400", name, result)
+		}
+	}
+}
+
+func TestOutputSchemas(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"resourceSpans": []interface{}{map[string]interface{}{
+				"resource": map[string]interface{}{"attributes": []interface{}{
+					map[string]interface{}{"key": "service.name", "value": map[string]interface{}{"stringValue": "checkout"}},
+				}},
+				"scopeSpans": []interface{}{map[string]interface{}{"spans": []interface{}{map[string]interface{}{
+					"traceId":           "trace1",
+					"spanId":            "span1",
+					"name":              "GET /cart",
+					"startTimeUnixNano": "1000000000",
+					"endTimeUnixNano":   "1005000000",
+					"status":            map[string]interface{}{"code": 2},
+				}}}},
+			}},
+		})
+	}))
+	defer server.Close()
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	schemas := pkg.OutputSchemas()
+
+	for _, tt := range []struct {
+		tool string
+		args map[string]interface{}
+	}{
+		{"dash0_spans_query", map[string]interface{}{}},
+		{"dash0_spans_query", map[string]interface{}{"order_by": "duration"}},
+		{"dash0_spans_query", map[string]interface{}{"output": "histogram"}},
+		{"dash0_spans_stats", map[string]interface{}{}},
+	} {
+		result := pkg.Handlers()[tt.tool](context.Background(), tt.args)
+		if !result.Success {
+			t.Fatalf("%s %v failed: %v", tt.tool, tt.args, result.Error)
+		}
+		data, _ := json.Marshal(result.Data)
+		var got map[string]interface{}
+		json.Unmarshal(data, &got)
+
+		props := schemas[tt.tool]["properties"].(map[string]interface{})
+		for key := range got {
+			if _, ok := props[key]; !ok {
+				t.Errorf("%s %v: field %q is not in the output schema", tt.tool, tt.args, key)
+			}
+		}
+		spans, _ := got["spans"].([]interface{})
+		for _, span := range spans {
+			items := props["spans"].(map[string]interface{})["items"].(map[string]interface{})["properties"].(map[string]interface{})
+			for key := range span.(map[string]interface{}) {
+				if _, ok := items[key]; !ok {
+					t.Errorf("span field %q is not in the output schema", key)
+				}
+			}
+		}
+	}
+}
+
 func errorf(format string, args ...interface{}) error {
 	return fmt.Errorf(format, args...)
 }
@@ -0,0 +1,271 @@
+package syntheticchecks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+	mcp "github.com/mark3labs/mcp-go/mcp"
+)
+
+// BuildAssertions returns the dash0_synthetic_checks_build_assertions tool definition.
+func (p *Tools) BuildAssertions() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_synthetic_checks_build_assertions",
+		Description: `Build the assertions block of an HTTP synthetic check from simple named arguments.
+
+Nothing is sent to Dash0: the block is built and validated locally. Put the result in
+spec.plugin.spec.assertions of an http check (or in a step of a multistep check) and
+create or update the check with dash0_synthetic_checks_create or _update.
+
+Example:
+{
+  "status_code": "2xx",
+  "body_contains": ["\"status\":\"ok\""],
+  "json_path": {"$.database": "up"},
+  "max_latency": "500ms",
+  "tls_cert_min_days": 14
+}`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"status_code": map[string]interface{}{
+					"type":        "string",
+					"description": "Expected status code: a code ('200'), a class ('2xx'), or a range ('200-299')",
+				},
+				"body_contains": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Strings the response body must contain",
+				},
+				"body_not_contains": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Strings the response body must not contain (e.g., 'error')",
+				},
+				"json_path": map[string]interface{}{
+					"type":        "object",
+					"description": "JSONPath expressions and the value each must equal (e.g., {\"$.status\": \"ok\"})",
+				},
+				"json_path_exists": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "JSONPath expressions that must match a value (e.g., ['$.data.id'])",
+				},
+				"max_latency": map[string]interface{}{
+					"type":        "string",
+					"description": "Maximum response time (e.g., '500ms', '2s')",
+				},
+				"tls_cert_min_days": map[string]interface{}{
+					"type":        "integer",
+					"description": "Minimum days until the TLS certificate expires",
+				},
+			},
+		},
+	}
+}
+
+// BuildAssertionsHandler handles the dash0_synthetic_checks_build_assertions tool.
+func (p *Tools) BuildAssertionsHandler(_ context.Context, args map[string]interface{}) *client.ToolResult {
+	assertions, err := buildAssertions(args)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+
+	doc, err := json.MarshalIndent(assertions, "", "  ")
+	if err != nil {
+		return client.ErrorResult(500, fmt.Sprintf("failed to marshal assertions: %v", err))
+	}
+	rows := make([][]string, len(assertions))
+	for i, a := range assertions {
+		rows[i] = []string{a["kind"].(string), formatter.Truncate(describeAssertion(a), 80)}
+	}
+	summary := fmt.Sprintf("**%d assertions** for spec.plugin.spec.assertions", len(assertions))
+	md := formatter.Table("Synthetic Check Assertions", summary, []string{"Kind", "Asserts"}, rows, "") +
+		"\n```json\n" + string(doc) + "\n```\n"
+
+	return &client.ToolResult{
+		Success:  true,
+		Markdown: md,
+		Data:     map[string]interface{}{"assertions": assertions},
+	}
+}
+
+// buildAssertions converts the named arguments of
+// dash0_synthetic_checks_build_assertions into assertions, in argument order.
+func buildAssertions(args map[string]interface{}) ([]map[string]interface{}, error) {
+	var assertions []map[string]interface{}
+	add := func(kind string, spec map[string]interface{}) {
+		assertions = append(assertions, map[string]interface{}{"kind": kind, "spec": spec})
+	}
+
+	if raw, ok := args["status_code"]; ok {
+		spec, err := parseStatusCode(raw)
+		if err != nil {
+			return nil, err
+		}
+		add("status_code", spec)
+	}
+
+	for _, arg := range []struct{ name, operator string }{
+		{"body_contains", "contains"},
+		{"body_not_contains", "not_contains"},
+	} {
+		values, err := stringList(args, arg.name)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range values {
+			add("body", map[string]interface{}{"operator": arg.operator, "value": v})
+		}
+	}
+
+	if raw, ok := args["json_path"]; ok {
+		paths, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("json_path must be an object of JSONPath expressions and expected values")
+		}
+		keys := make([]string, 0, len(paths))
+		for path := range paths {
+			keys = append(keys, path)
+		}
+		sort.Strings(keys)
+		for _, path := range keys {
+			if err := checkJSONPath(path); err != nil {
+				return nil, err
+			}
+			value := paths[path]
+			if _, ok := value.(string); !ok {
+				// Compare as JSON, so 1 and true match the response's 1 and true.
+				b, _ := json.Marshal(value)
+				value = string(b)
+			}
+			add("json_path", map[string]interface{}{"path": path, "operator": "is", "value": value})
+		}
+	}
+	paths, err := stringList(args, "json_path_exists")
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		if err := checkJSONPath(path); err != nil {
+			return nil, err
+		}
+		add("json_path", map[string]interface{}{"path": path, "operator": "exists"})
+	}
+
+	if raw, ok := args["max_latency"]; ok {
+		s, _ := raw.(string)
+		d, err := time.ParseDuration(strings.TrimSpace(s))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("max_latency must be a positive duration such as '500ms' or '2s', got %v", raw)
+		}
+		add("response_time", map[string]interface{}{"max": d.String()})
+	}
+
+	if raw, ok := args["tls_cert_min_days"]; ok {
+		days, ok := raw.(float64)
+		if !ok || days < 1 || days != float64(int(days)) {
+			return nil, fmt.Errorf("tls_cert_min_days must be a whole number of days of at least 1, got %v", raw)
+		}
+		add("tls_certificate", map[string]interface{}{"minDaysUntilExpiry": int(days)})
+	}
+
+	if len(assertions) == 0 {
+		return nil, errors.New("set at least one of status_code, body_contains, body_not_contains, json_path, json_path_exists, max_latency, or tls_cert_min_days")
+	}
+	return assertions, nil
+}
+
+var statusRange = regexp.MustCompile(`^([1-5][0-9]{2})\s*-\s*([1-5][0-9]{2})$`)
+
+// parseStatusCode converts '200', '2xx', or '200-299' to a status_code
+// assertion spec.
+func parseStatusCode(raw interface{}) (map[string]interface{}, error) {
+	s := strings.ToLower(strings.TrimSpace(fmt.Sprint(raw)))
+	invalid := fmt.Errorf("status_code must be a code ('200'), a class ('2xx'), or a range ('200-299'), got %q", s)
+
+	if len(s) == 3 && s[1:] == "xx" && s[0] >= '1' && s[0] <= '5' {
+		min := int(s[0]-'0') * 100
+		return map[string]interface{}{"operator": "in_range", "min": min, "max": min + 99}, nil
+	}
+	if m := statusRange.FindStringSubmatch(s); m != nil {
+		min, _ := strconv.Atoi(m[1])
+		max, _ := strconv.Atoi(m[2])
+		if min > max {
+			return nil, invalid
+		}
+		return map[string]interface{}{"operator": "in_range", "min": min, "max": max}, nil
+	}
+	code, err := strconv.Atoi(s)
+	if err != nil || code < 100 || code > 599 {
+		return nil, invalid
+	}
+	return map[string]interface{}{"operator": "is", "value": code}, nil
+}
+
+// checkJSONPath rejects expressions that are not rooted JSONPath.
+func checkJSONPath(path string) error {
+	if path != "$" && !strings.HasPrefix(path, "$.") && !strings.HasPrefix(path, "$[") {
+		return fmt.Errorf("JSONPath %q must start with '$.' or '$['", path)
+	}
+	return nil
+}
+
+// stringList returns the non-empty strings of a string array argument.
+func stringList(args map[string]interface{}, name string) ([]string, error) {
+	raw, ok := args[name]
+	if !ok {
+		return nil, nil
+	}
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be an array of strings", name)
+	}
+	var values []string
+	for _, v := range list {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be an array of strings", name)
+		}
+		if s != "" {
+			values = append(values, s)
+		}
+	}
+	return values, nil
+}
+
+// describeAssertion renders an assertion as a short sentence.
+func describeAssertion(a map[string]interface{}) string {
+	spec, _ := a["spec"].(map[string]interface{})
+	switch a["kind"] {
+	case "status_code":
+		if spec["operator"] == "in_range" {
+			return fmt.Sprintf("status code between %v and %v", spec["min"], spec["max"])
+		}
+		return fmt.Sprintf("status code is %v", spec["value"])
+	case "body":
+		if spec["operator"] == "not_contains" {
+			return fmt.Sprintf("body does not contain %q", spec["value"])
+		}
+		return fmt.Sprintf("body contains %q", spec["value"])
+	case "json_path":
+		if spec["operator"] == "exists" {
+			return fmt.Sprintf("%v exists", spec["path"])
+		}
+		return fmt.Sprintf("%v is %v", spec["path"], spec["value"])
+	case "response_time":
+		return fmt.Sprintf("responds within %v", spec["max"])
+	case "tls_certificate":
+		return fmt.Sprintf("TLS certificate valid for at least %v more days", spec["minDaysUntilExpiry"])
+	}
+	return ""
+}
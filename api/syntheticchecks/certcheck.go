@@ -0,0 +1,148 @@
+package syntheticchecks
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/schema"
+	mcp "github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultCertWarningDays is how long before expiry a certificate check fails
+// by default.
+const defaultCertWarningDays = 30
+
+// CreateCertCheck returns the dash0_synthetic_checks_create_cert_check tool definition.
+func (p *Tools) CreateCertCheck() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_synthetic_checks_create_cert_check",
+		Description: `Create a synthetic check that fails when a host's TLS certificate is about to expire.
+
+Only the hostname is needed: the full Dash0SyntheticCheck is generated, an HTTPS check of
+the host with a tls_certificate assertion that fails warning_days before the certificate
+expires. Certificates change rarely, so the check runs hourly by default.
+
+Example:
+{"hostname": "api.example.com", "warning_days": 21}`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"hostname": map[string]interface{}{
+					"type":        "string",
+					"description": "Host whose certificate to check (e.g., 'api.example.com')",
+				},
+				"port": map[string]interface{}{
+					"type":        "integer",
+					"description": "HTTPS port (default: 443)",
+				},
+				"path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to request (default: /)",
+				},
+				"warning_days": map[string]interface{}{
+					"type":        "integer",
+					"description": fmt.Sprintf("Fail this many days before the certificate expires (default: %d)", defaultCertWarningDays),
+				},
+				"name": map[string]interface{}{
+					"type":        "string",
+					"description": "Check name (default: tls-expiry-<hostname>)",
+				},
+				"interval": map[string]interface{}{
+					"type":        "string",
+					"description": "Check frequency (default: 1h)",
+				},
+				"locations": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Check locations (default: ['eu-west-1'])",
+				},
+			},
+			Required: []string{"hostname"},
+		},
+	}
+}
+
+// CreateCertCheckHandler handles the dash0_synthetic_checks_create_cert_check tool.
+func (p *Tools) CreateCertCheckHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	hostname, _ := args["hostname"].(string)
+	hostname = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(hostname), "https://"), "/")
+	if hostname == "" {
+		return client.ErrorResult(400, "hostname is required")
+	}
+	if strings.ContainsAny(hostname, "/:?# ") {
+		return client.ErrorResult(400, fmt.Sprintf("hostname %q must be a host name only; set port and path separately", hostname))
+	}
+
+	port := 443
+	if v, ok := args["port"].(float64); ok {
+		if v < 1 || v > 65535 || v != float64(int(v)) {
+			return client.ErrorResult(400, fmt.Sprintf("port must be between 1 and 65535, got %v", v))
+		}
+		port = int(v)
+	}
+	days := defaultCertWarningDays
+	if v, ok := args["warning_days"].(float64); ok {
+		if v < 1 || v != float64(int(v)) {
+			return client.ErrorResult(400, fmt.Sprintf("warning_days must be a whole number of at least 1, got %v", v))
+		}
+		days = int(v)
+	}
+	path, _ := args["path"].(string)
+	if path = strings.TrimSpace(path); path == "" {
+		path = "/"
+	} else if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	host := hostname
+	if port != 443 {
+		host = net.JoinHostPort(hostname, strconv.Itoa(port))
+	}
+	target := (&url.URL{Scheme: "https", Host: host}).String() + path
+
+	name, _ := args["name"].(string)
+	if name = strings.TrimSpace(name); name == "" {
+		name = checkSlug("tls-expiry-" + hostname)
+	}
+	schedule, err := generatedSchedule(args, "1h")
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+
+	check := map[string]interface{}{
+		"kind":     "Dash0SyntheticCheck",
+		"metadata": map[string]interface{}{"name": name},
+		"spec": map[string]interface{}{
+			"enabled": true,
+			"plugin": map[string]interface{}{
+				"kind": "http",
+				"spec": map[string]interface{}{
+					"request": map[string]interface{}{
+						"method":    "get",
+						"url":       target,
+						"redirects": "follow",
+					},
+					"assertions": []interface{}{
+						map[string]interface{}{"kind": "tls_certificate", "spec": map[string]interface{}{"minDaysUntilExpiry": days}},
+					},
+				},
+			},
+			"schedule": schedule,
+		},
+	}
+	if result := schema.Check(schema.KindSyntheticCheck, check); result != nil {
+		return result
+	}
+
+	result := p.client.Post(ctx, basePath, check)
+	if result.Success && !result.DryRun {
+		result.Markdown = fmt.Sprintf("## TLS Certificate Check Created\n\n`%s` requests %s every %s and fails when the certificate expires in less than %d days.\n",
+			name, target, schedule["interval"], days)
+	}
+	return result
+}
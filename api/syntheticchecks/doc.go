@@ -1,4 +1,6 @@
 // Package syntheticchecks provides MCP tools for Dash0 synthetic check operations.
-// This package enables creating, retrieving, updating, and deleting synthetic checks
-// for proactive monitoring of applications and services.
+// This package enables creating, retrieving, updating, pausing, and deleting
+// synthetic checks for proactive monitoring of applications and services,
+// generating HTTP checks from an OpenAPI document, building their response
+// assertions, and monitoring TLS certificate expiry.
 package syntheticchecks
@@ -0,0 +1,157 @@
+package syntheticchecks
+
+import "github.com/npcomplete777/dash0-mcp/internal/registry"
+
+// Compile-time interface check.
+var _ registry.ExampleProvider = (*Tools)(nil)
+
+// httpCheck returns a Dash0SyntheticCheck body for an HTTP GET check.
+func httpCheck(name, target, interval string, locations ...interface{}) map[string]interface{} {
+	return pluginCheck(name, "http", map[string]interface{}{
+		"request": map[string]interface{}{
+			"method":    "get",
+			"url":       target,
+			"redirects": "follow",
+		},
+	}, interval, locations...)
+}
+
+// pluginCheck returns a Dash0SyntheticCheck body for a plugin of any kind.
+func pluginCheck(name, kind string, pluginSpec map[string]interface{}, interval string, locations ...interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"kind":     "Dash0SyntheticCheck",
+		"metadata": map[string]interface{}{"name": name},
+		"spec": map[string]interface{}{
+			"enabled": true,
+			"plugin": map[string]interface{}{
+				"kind": kind,
+				"spec": pluginSpec,
+			},
+			"schedule": map[string]interface{}{
+				"interval":  interval,
+				"locations": locations,
+				"strategy":  "all_locations",
+			},
+		},
+	}
+}
+
+// Examples returns example invocations for the synthetic check tools.
+func (p *Tools) Examples() map[string][]registry.Example {
+	withRetries := httpCheck("authenticated-api-check", "https://api.example.com/v1/status", "1m", "eu-west-1", "us-east-1")
+	spec := withRetries["spec"].(map[string]interface{})
+	request := spec["plugin"].(map[string]interface{})["spec"].(map[string]interface{})["request"].(map[string]interface{})
+	request["headers"] = map[string]interface{}{"Accept": "application/json"}
+	spec["retries"] = map[string]interface{}{"count": 2, "delay": "5s"}
+
+	withAssertions := httpCheck("api-health-assertions", "https://api.example.com/health", "1m", "eu-west-1")
+	pluginSpec := withAssertions["spec"].(map[string]interface{})["plugin"].(map[string]interface{})["spec"].(map[string]interface{})
+	pluginSpec["assertions"] = []interface{}{
+		map[string]interface{}{"kind": "status_code", "spec": map[string]interface{}{"operator": "in_range", "min": 200, "max": 299}},
+		map[string]interface{}{"kind": "json_path", "spec": map[string]interface{}{"path": "$.status", "operator": "is", "value": "ok"}},
+		map[string]interface{}{"kind": "response_time", "spec": map[string]interface{}{"max": "500ms"}},
+	}
+
+	return map[string][]registry.Example{
+		"dash0_synthetic_checks_list": {
+			{Title: "List all synthetic checks", Arguments: map[string]interface{}{}},
+		},
+		"dash0_synthetic_checks_get": {
+			{Title: "Get a synthetic check by origin", Arguments: map[string]interface{}{"origin_or_id": "api-health-check"}},
+		},
+		"dash0_synthetic_checks_create": {
+			{
+				Title:     "Check a health endpoint every 5 minutes",
+				Arguments: map[string]interface{}{"body": httpCheck("api-health-check", "https://api.example.com/health", "5m", "eu-west-1")},
+			},
+			{
+				Title:     "Check an API from two regions with headers and retries",
+				Arguments: map[string]interface{}{"body": withRetries},
+			},
+			{
+				Title:     "Check a health endpoint's status, payload, and latency",
+				Arguments: map[string]interface{}{"body": withAssertions},
+			},
+			{
+				Title: "Check that a database port accepts TLS connections",
+				Arguments: map[string]interface{}{"body": pluginCheck("postgres-port", "tcp", map[string]interface{}{
+					"request": map[string]interface{}{"host": "db.example.com", "port": 5432, "tls": true},
+				}, "1m", "eu-west-1")},
+			},
+			{
+				Title: "Check that a domain resolves",
+				Arguments: map[string]interface{}{"body": pluginCheck("api-dns", "dns", map[string]interface{}{
+					"request": map[string]interface{}{"name": "api.example.com", "recordType": "A"},
+				}, "5m", "eu-west-1", "us-east-1")},
+			},
+			{
+				Title: "Ping a host",
+				Arguments: map[string]interface{}{"body": pluginCheck("edge-router-ping", "icmp", map[string]interface{}{
+					"request": map[string]interface{}{"host": "203.0.113.10", "count": 3},
+				}, "1m", "eu-west-1")},
+			},
+			{
+				Title: "Log in and read the account in one multi-step check",
+				Arguments: map[string]interface{}{"body": pluginCheck("login-flow", "multistep", map[string]interface{}{
+					"steps": []interface{}{
+						map[string]interface{}{"name": "login", "request": map[string]interface{}{"method": "post", "url": "https://api.example.com/login"}},
+						map[string]interface{}{"name": "account", "request": map[string]interface{}{"method": "get", "url": "https://api.example.com/account"}},
+					},
+				}, "5m", "eu-west-1")},
+			},
+		},
+		"dash0_synthetic_checks_update": {
+			{
+				Title: "Check a health endpoint every minute",
+				Arguments: map[string]interface{}{
+					"origin_or_id": "api-health-check",
+					"body":         httpCheck("api-health-check", "https://api.example.com/health", "1m", "eu-west-1"),
+				},
+			},
+		},
+		"dash0_synthetic_checks_delete": {
+			{Title: "Delete a synthetic check", Arguments: map[string]interface{}{"origin_or_id": "api-health-check"}},
+		},
+		"dash0_synthetic_checks_enable": {
+			{Title: "Resume a synthetic check", Arguments: map[string]interface{}{"origin_or_id": "api-health-check"}},
+		},
+		"dash0_synthetic_checks_disable": {
+			{Title: "Pause a synthetic check", Arguments: map[string]interface{}{"origin_or_id": "api-health-check"}},
+		},
+		"dash0_synthetic_checks_build_assertions": {
+			{Title: "A healthy JSON health endpoint", Arguments: map[string]interface{}{
+				"status_code":       "2xx",
+				"json_path":         map[string]interface{}{"$.status": "ok"},
+				"max_latency":       "500ms",
+				"tls_cert_min_days": 14,
+			}},
+			{Title: "A page that must not show an error", Arguments: map[string]interface{}{
+				"status_code":       "200",
+				"body_contains":     []interface{}{"Welcome"},
+				"body_not_contains": []interface{}{"Internal Server Error"},
+			}},
+		},
+		"dash0_synthetic_checks_create_cert_check": {
+			{Title: "Warn 30 days before a certificate expires", Arguments: map[string]interface{}{"hostname": "api.example.com"}},
+			{Title: "Check a non-standard port from two regions", Arguments: map[string]interface{}{
+				"hostname":     "admin.example.com",
+				"port":         8443,
+				"warning_days": 14,
+				"locations":    []interface{}{"eu-west-1", "us-east-1"},
+			}},
+		},
+		"dash0_synthetic_checks_generate_from_openapi": {
+			{Title: "Checks for every GET endpoint of a published spec", Arguments: map[string]interface{}{
+				"spec_url":    "https://api.example.com/openapi.json",
+				"name_prefix": "payments-api",
+				"interval":    "5m",
+				"locations":   []interface{}{"eu-west-1", "us-east-1"},
+			}},
+			{Title: "Preview the checks of an inline spec against staging", Arguments: map[string]interface{}{
+				"spec":     "openapi: 3.0.0\ninfo:\n  title: Payments\npaths:\n  /health:\n    get: {}\n",
+				"base_url": "https://staging.example.com",
+				"dry_run":  true,
+			}},
+		},
+	}
+}
@@ -0,0 +1,517 @@
+package syntheticchecks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+	mcp "github.com/mark3labs/mcp-go/mcp"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultOpenAPIMaxChecks = 50
+	maxOpenAPIMaxChecks     = 200
+
+	// maxOpenAPISpecBytes bounds the size of a spec fetched from spec_url.
+	maxOpenAPISpecBytes = 10 << 20
+)
+
+// Generated check statuses.
+const (
+	checkCreated = "created"
+	checkFailed  = "failed"
+	checkSkipped = "skipped"
+	// checkReady marks a check that a dry run would create.
+	checkReady = "ready"
+)
+
+// specFetcher downloads OpenAPI documents given by spec_url.
+var specFetcher = &http.Client{Timeout: 30 * time.Second}
+
+// GenerateFromOpenAPI returns the dash0_synthetic_checks_generate_from_openapi tool definition.
+func (p *Tools) GenerateFromOpenAPI() mcp.Tool {
+	return mcp.Tool{
+		Name: "dash0_synthetic_checks_generate_from_openapi",
+		Description: `Create one HTTP synthetic check per GET endpoint of an OpenAPI 3 or Swagger 2 document.
+
+Each check requests the endpoint's URL (the first server, or base_url) with method get and
+redirects follow, on a schedule shared by all generated checks. Endpoints with path
+parameters or required query or header parameters are skipped, since there is no value to
+call them with. Check names are the name_prefix followed by the operationId or the path.
+
+Returns a per-endpoint report of created, failed, and skipped checks; one failing check
+does not stop the others. Set dry_run to true to see the checks without creating them.
+
+Example:
+{
+  "spec_url": "https://api.example.com/openapi.json",
+  "name_prefix": "payments-api",
+  "interval": "5m",
+  "locations": ["eu-west-1", "us-east-1"]
+}`,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"type":        []string{"string", "object"},
+					"description": "The OpenAPI or Swagger document as a JSON or YAML string, or as an object. Either spec or spec_url is required.",
+				},
+				"spec_url": map[string]interface{}{
+					"type":        "string",
+					"description": "URL to download the OpenAPI or Swagger document from. Relative server URLs in the document are resolved against it.",
+				},
+				"base_url": map[string]interface{}{
+					"type":        "string",
+					"description": "Base URL of the API to check (e.g., 'https://api.example.com/v1'). Overrides the document's servers.",
+				},
+				"name_prefix": map[string]interface{}{
+					"type":        "string",
+					"description": "Prefix of the generated check names (default: the document's info.title)",
+				},
+				"interval": map[string]interface{}{
+					"type":        "string",
+					"description": "Check frequency of every generated check (default: 5m)",
+				},
+				"locations": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Check locations (default: ['eu-west-1'])",
+				},
+				"strategy": map[string]interface{}{
+					"type":        "string",
+					"description": "Execution strategy (default: all_locations)",
+				},
+				"headers": map[string]interface{}{
+					"type":        "object",
+					"description": "HTTP headers sent by every generated check (e.g., {\"Accept\": \"application/json\"})",
+				},
+				"max_checks": map[string]interface{}{
+					"type":        "integer",
+					"description": fmt.Sprintf("Max checks to generate (default: %d, max: %d); further endpoints are skipped", defaultOpenAPIMaxChecks, maxOpenAPIMaxChecks),
+				},
+				"dry_run": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Return the generated checks without creating them. Default: false",
+				},
+			},
+		},
+	}
+}
+
+// GeneratedCheck is the outcome of generating a check for one endpoint.
+type GeneratedCheck struct {
+	Name   string `json:"name,omitempty"`
+	Path   string `json:"path"`
+	URL    string `json:"url,omitempty"`
+	Status string `json:"status"`
+	ID     string `json:"id,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// GenerateFromOpenAPIHandler handles the dash0_synthetic_checks_generate_from_openapi tool.
+func (p *Tools) GenerateFromOpenAPIHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	dryRun, _ := args["dry_run"].(bool)
+	dryRun = dryRun || p.client.DryRun(ctx)
+
+	specURL, _ := args["spec_url"].(string)
+	specURL = strings.TrimSpace(specURL)
+	raw, hasSpec := args["spec"]
+	switch {
+	case hasSpec && specURL != "":
+		return client.ErrorResult(400, "set either spec or spec_url, not both")
+	case !hasSpec && specURL == "":
+		return client.ErrorResult(400, "spec or spec_url is required")
+	case specURL != "":
+		fetched, err := fetchOpenAPISpec(ctx, specURL)
+		if err != nil {
+			return client.ErrorResult(400, err.Error())
+		}
+		raw = fetched
+	}
+	doc, err := parseOpenAPISpec(raw)
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+
+	baseURL, _ := args["base_url"].(string)
+	baseURL = strings.TrimSpace(baseURL)
+	if baseURL == "" {
+		if baseURL, err = openAPIBaseURL(doc, specURL); err != nil {
+			return client.ErrorResult(400, err.Error())
+		}
+	}
+	if u, err := url.Parse(baseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return client.ErrorResult(400, fmt.Sprintf("base URL %q must be an absolute http or https URL", baseURL))
+	}
+
+	schedule, err := generatedSchedule(args, "5m")
+	if err != nil {
+		return client.ErrorResult(400, err.Error())
+	}
+	var headers map[string]interface{}
+	if h, ok := args["headers"]; ok {
+		if headers, ok = h.(map[string]interface{}); !ok {
+			return client.ErrorResult(400, "headers must be an object")
+		}
+	}
+	maxChecks := defaultOpenAPIMaxChecks
+	if m, ok := args["max_checks"].(float64); ok {
+		if m < 0 {
+			return client.ErrorResult(400, "max_checks must not be negative")
+		}
+		if m > 0 {
+			maxChecks = int(m)
+			if maxChecks > maxOpenAPIMaxChecks {
+				maxChecks = maxOpenAPIMaxChecks
+			}
+		}
+	}
+	prefix, _ := args["name_prefix"].(string)
+	if strings.TrimSpace(prefix) == "" {
+		info, _ := doc["info"].(map[string]interface{})
+		prefix, _ = info["title"].(string)
+	}
+	prefix = checkSlug(prefix)
+
+	endpoints := openAPIEndpoints(doc)
+	if len(endpoints) == 0 {
+		return client.ErrorResult(400, "the document has no GET endpoints")
+	}
+
+	results := make([]GeneratedCheck, 0, len(endpoints))
+	var checks []interface{}
+	names := make(map[string]bool)
+	generated := 0
+	for _, e := range endpoints {
+		res := GeneratedCheck{Path: e.path, Status: checkSkipped, Detail: e.skip}
+		if res.Detail == "" && generated >= maxChecks {
+			res.Detail = fmt.Sprintf("max_checks (%d) reached", maxChecks)
+		}
+		if res.Detail != "" {
+			results = append(results, res)
+			continue
+		}
+		generated++
+
+		res.Name = uniqueCheckName(prefix, e, names)
+		res.URL = strings.TrimRight(baseURL, "/") + e.path
+		request := map[string]interface{}{
+			"method":    "get",
+			"url":       res.URL,
+			"redirects": "follow",
+		}
+		if len(headers) > 0 {
+			request["headers"] = headers
+		}
+		check := map[string]interface{}{
+			"kind":     "Dash0SyntheticCheck",
+			"metadata": map[string]interface{}{"name": res.Name},
+			"spec": map[string]interface{}{
+				"enabled": true,
+				"plugin": map[string]interface{}{
+					"kind": "http",
+					"spec": map[string]interface{}{"request": request},
+				},
+				"schedule": schedule,
+			},
+		}
+
+		if dryRun {
+			res.Status, res.Detail = checkReady, ""
+			checks = append(checks, check)
+			results = append(results, res)
+			continue
+		}
+		created := p.client.Post(ctx, basePath, check)
+		if created.Success {
+			res.Status = checkCreated
+			if m, ok := created.Data.(map[string]interface{}); ok {
+				res.ID = extractNestedField(m, "metadata", "labels", "dash0.com/id")
+				if res.ID == "" {
+					res.ID, _ = m["id"].(string)
+				}
+			}
+		} else {
+			res.Status = checkFailed
+			res.Detail = created.Error.Detail
+			if res.Detail == "" {
+				res.Detail = created.Error.Title
+			}
+		}
+		results = append(results, res)
+	}
+
+	created, failed, skipped := generatedCounts(results)
+	data := map[string]interface{}{
+		"created":  created,
+		"failed":   failed,
+		"skipped":  skipped,
+		"base_url": baseURL,
+		"results":  results,
+	}
+	if dryRun {
+		data["checks"] = checks
+	}
+	return &client.ToolResult{
+		Success:  true,
+		Data:     data,
+		Markdown: formatGeneratedChecks(results, baseURL, dryRun),
+	}
+}
+
+// fetchOpenAPISpec downloads the document at specURL.
+func fetchOpenAPISpec(ctx context.Context, specURL string) (string, error) {
+	u, err := url.Parse(specURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", fmt.Errorf("spec_url %q must be an http or https URL", specURL)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, specURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid spec_url: %v", err)
+	}
+	req.Header.Set("Accept", "application/json, application/yaml;q=0.9, */*;q=0.8")
+	resp, err := specFetcher.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download spec: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download spec: %s returned %s", specURL, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxOpenAPISpecBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to download spec: %v", err)
+	}
+	if len(body) > maxOpenAPISpecBytes {
+		return "", fmt.Errorf("spec is larger than %d MB", maxOpenAPISpecBytes>>20)
+	}
+	return string(body), nil
+}
+
+// parseOpenAPISpec decodes an OpenAPI 3 or Swagger 2 document given as a
+// JSON or YAML string, or as the decoded object.
+func parseOpenAPISpec(raw interface{}) (map[string]interface{}, error) {
+	if s, ok := raw.(string); ok {
+		if strings.TrimSpace(s) == "" {
+			return nil, errors.New("spec must not be empty")
+		}
+		var decoded interface{}
+		if err := yaml.Unmarshal([]byte(s), &decoded); err != nil {
+			return nil, fmt.Errorf("failed to parse spec: %v", err)
+		}
+		raw = decoded
+	}
+	doc, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("spec must be an OpenAPI document")
+	}
+	if doc["openapi"] == nil && doc["swagger"] == nil {
+		return nil, errors.New("spec is not an OpenAPI or Swagger document: it has no openapi or swagger field")
+	}
+	if _, ok := doc["paths"].(map[string]interface{}); !ok {
+		return nil, errors.New("spec has no paths")
+	}
+	return doc, nil
+}
+
+// openAPIBaseURL returns the URL the document's paths are relative to: the
+// first OpenAPI 3 server with its variables set to their defaults, or the
+// Swagger 2 scheme, host, and basePath. Relative URLs are resolved against
+// specURL, the location the document was downloaded from.
+func openAPIBaseURL(doc map[string]interface{}, specURL string) (string, error) {
+	base := ""
+	if servers, ok := doc["servers"].([]interface{}); ok && len(servers) > 0 {
+		server, _ := servers[0].(map[string]interface{})
+		base, _ = server["url"].(string)
+		vars, _ := server["variables"].(map[string]interface{})
+		for name, v := range vars {
+			variable, _ := v.(map[string]interface{})
+			if def, ok := variable["default"]; ok {
+				base = strings.ReplaceAll(base, "{"+name+"}", fmt.Sprint(def))
+			}
+		}
+	} else if host, ok := doc["host"].(string); ok && host != "" {
+		scheme := "https"
+		if schemes, ok := doc["schemes"].([]interface{}); ok && len(schemes) > 0 {
+			if s, ok := schemes[0].(string); ok {
+				scheme = s
+			}
+		}
+		basePath, _ := doc["basePath"].(string)
+		base = scheme + "://" + host + basePath
+	} else if doc["swagger"] != nil {
+		base, _ = doc["basePath"].(string)
+	}
+
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL %q: %v", base, err)
+	}
+	if u.IsAbs() {
+		return base, nil
+	}
+	if specURL == "" {
+		return "", errors.New("the document has no absolute server URL; set base_url")
+	}
+	from, err := url.Parse(specURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid spec_url: %v", err)
+	}
+	return from.ResolveReference(u).String(), nil
+}
+
+// openAPIEndpoint is a GET operation of an OpenAPI document. skip says why
+// no check can be generated for it.
+type openAPIEndpoint struct {
+	path        string
+	operationID string
+	skip        string
+}
+
+// openAPIEndpoints returns the GET operations of doc, sorted by path.
+func openAPIEndpoints(doc map[string]interface{}) []openAPIEndpoint {
+	paths, _ := doc["paths"].(map[string]interface{})
+	keys := make([]string, 0, len(paths))
+	for path := range paths {
+		keys = append(keys, path)
+	}
+	sort.Strings(keys)
+
+	var endpoints []openAPIEndpoint
+	for _, path := range keys {
+		item, _ := paths[path].(map[string]interface{})
+		op, ok := item["get"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		e := openAPIEndpoint{path: path}
+		e.operationID, _ = op["operationId"].(string)
+		if strings.Contains(path, "{") {
+			e.skip = "path parameters have no value to call the endpoint with"
+		} else if name := requiredParameter(item["parameters"], op["parameters"]); name != "" {
+			e.skip = fmt.Sprintf("required parameter %q has no value to call the endpoint with", name)
+		}
+		endpoints = append(endpoints, e)
+	}
+	return endpoints
+}
+
+// requiredParameter returns the name of the first required query or header
+// parameter among the path item and operation parameter lists.
+func requiredParameter(lists ...interface{}) string {
+	for _, list := range lists {
+		params, _ := list.([]interface{})
+		for _, raw := range params {
+			param, _ := raw.(map[string]interface{})
+			in, _ := param["in"].(string)
+			if required, _ := param["required"].(bool); required && (in == "query" || in == "header") {
+				name, _ := param["name"].(string)
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// generatedSchedule returns the schedule of generated checks from the
+// interval, locations, and strategy arguments.
+func generatedSchedule(args map[string]interface{}, defaultInterval string) (map[string]interface{}, error) {
+	interval, _ := args["interval"].(string)
+	if interval = strings.TrimSpace(interval); interval == "" {
+		interval = defaultInterval
+	}
+	locations := []interface{}{"eu-west-1"}
+	if raw, ok := args["locations"]; ok {
+		list, ok := raw.([]interface{})
+		if !ok || len(list) == 0 {
+			return nil, errors.New("locations must be a non-empty array of strings")
+		}
+		for _, l := range list {
+			if s, ok := l.(string); !ok || s == "" {
+				return nil, errors.New("locations must be a non-empty array of strings")
+			}
+		}
+		locations = list
+	}
+	strategy, _ := args["strategy"].(string)
+	if strategy = strings.TrimSpace(strategy); strategy == "" {
+		strategy = "all_locations"
+	}
+	return map[string]interface{}{
+		"interval":  interval,
+		"locations": locations,
+		"strategy":  strategy,
+	}, nil
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// checkSlug turns s into a check name: lowercase alphanumerics and hyphens.
+func checkSlug(s string) string {
+	return strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+// uniqueCheckName names the check of e after its operationId, or its path,
+// and appends a counter when the name is already taken.
+func uniqueCheckName(prefix string, e openAPIEndpoint, taken map[string]bool) string {
+	name := checkSlug(e.operationID)
+	if name == "" {
+		name = checkSlug(e.path)
+	}
+	if name == "" {
+		name = "root"
+	}
+	if prefix != "" {
+		name = prefix + "-" + name
+	}
+	if len(name) > 60 {
+		name = strings.TrimRight(name[:60], "-")
+	}
+	unique := name
+	for i := 2; taken[unique]; i++ {
+		unique = fmt.Sprintf("%s-%d", name, i)
+	}
+	taken[unique] = true
+	return unique
+}
+
+// generatedCounts tallies results by status.
+func generatedCounts(results []GeneratedCheck) (created, failed, skipped int) {
+	for _, r := range results {
+		switch r.Status {
+		case checkCreated:
+			created++
+		case checkFailed:
+			failed++
+		case checkSkipped:
+			skipped++
+		}
+	}
+	return created, failed, skipped
+}
+
+// formatGeneratedChecks renders the per-endpoint report of a generation.
+func formatGeneratedChecks(results []GeneratedCheck, baseURL string, dryRun bool) string {
+	created, failed, skipped := generatedCounts(results)
+	summary := fmt.Sprintf("%d created, %d failed, %d skipped | Base URL: %s", created, failed, skipped, baseURL)
+	if dryRun {
+		summary = fmt.Sprintf("%d checks would be created, %d skipped | Base URL: %s (dry run, nothing created)",
+			len(results)-skipped, skipped, baseURL)
+	}
+
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		rows = append(rows, []string{r.Path, r.Name, r.Status, formatter.Truncate(r.Detail, 80)})
+	}
+	return formatter.Table("Synthetic Checks from OpenAPI", summary, []string{"Path", "Check", "Status", "Detail"}, rows, "")
+}
@@ -0,0 +1,96 @@
+package syntheticchecks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/npcomplete777/dash0-mcp/internal/canonical"
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	mcp "github.com/mark3labs/mcp-go/mcp"
+)
+
+// EnableSyntheticCheck returns the dash0_synthetic_checks_enable tool definition.
+func (p *Tools) EnableSyntheticCheck() mcp.Tool {
+	return toggleTool("dash0_synthetic_checks_enable",
+		"Resume a paused synthetic check by its origin or ID. Only spec.enabled is changed, so no check body is needed.")
+}
+
+// EnableSyntheticCheckHandler handles the dash0_synthetic_checks_enable tool.
+func (p *Tools) EnableSyntheticCheckHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	return p.setEnabled(ctx, args, true)
+}
+
+// DisableSyntheticCheck returns the dash0_synthetic_checks_disable tool definition.
+func (p *Tools) DisableSyntheticCheck() mcp.Tool {
+	return toggleTool("dash0_synthetic_checks_disable",
+		"Pause a synthetic check by its origin or ID, e.g. while its target is down for maintenance. Only spec.enabled is changed, so no check body is needed; resume it with dash0_synthetic_checks_enable.")
+}
+
+// DisableSyntheticCheckHandler handles the dash0_synthetic_checks_disable tool.
+func (p *Tools) DisableSyntheticCheckHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+	return p.setEnabled(ctx, args, false)
+}
+
+func toggleTool(name, description string) mcp.Tool {
+	return mcp.Tool{
+		Name:        name,
+		Description: description,
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"origin_or_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The origin or ID of the synthetic check.",
+				},
+			},
+			Required: []string{"origin_or_id"},
+		},
+	}
+}
+
+// setEnabled sets spec.enabled of a check with a merge patch. When the API
+// does not accept PATCH, it reads the check and writes it back with only
+// the flag changed.
+func (p *Tools) setEnabled(ctx context.Context, args map[string]interface{}, enabled bool) *client.ToolResult {
+	originOrID, ok := args["origin_or_id"].(string)
+	if !ok || originOrID == "" {
+		return client.ErrorResult(400, "origin_or_id is required")
+	}
+
+	path := fmt.Sprintf(basePath+"/%s", url.PathEscape(originOrID))
+	patch := map[string]interface{}{"spec": map[string]interface{}{"enabled": enabled}}
+	result := p.client.Patch(ctx, path, patch)
+	if !result.Success && result.Error != nil && result.Error.StatusCode == http.StatusMethodNotAllowed {
+		result = p.putEnabled(ctx, path, enabled)
+	}
+	if result.Success && !result.DryRun {
+		title, state := "Paused", "no longer runs"
+		if enabled {
+			title, state = "Resumed", "runs on its schedule again"
+		}
+		result.Markdown = fmt.Sprintf("## Synthetic Check %s\n\n`%s` %s (spec.enabled: %t).\n", title, originOrID, state, enabled)
+	}
+	return result
+}
+
+// putEnabled replaces the check at path with a copy whose spec.enabled is
+// set, without the fields the server maintains.
+func (p *Tools) putEnabled(ctx context.Context, path string, enabled bool) *client.ToolResult {
+	current := p.client.Get(ctx, path)
+	if !current.Success {
+		return current
+	}
+	c, err := canonical.Canonicalize(current.Data)
+	if err != nil {
+		return client.ErrorResult(500, fmt.Sprintf("failed to read synthetic check: %v", err))
+	}
+	check, _ := c.(map[string]interface{})
+	spec, _ := check["spec"].(map[string]interface{})
+	if spec == nil {
+		return client.ErrorResult(500, "unexpected synthetic check format: missing spec")
+	}
+	spec["enabled"] = enabled
+	return p.client.Put(ctx, path, check)
+}
@@ -7,7 +7,9 @@ import (
 
 	"github.com/npcomplete777/dash0-mcp/internal/client"
 	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+	"github.com/npcomplete777/dash0-mcp/internal/listing"
 	"github.com/npcomplete777/dash0-mcp/internal/registry"
+	"github.com/npcomplete777/dash0-mcp/internal/schema"
 	mcp "github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -36,17 +38,27 @@ func (p *Tools) Tools() []mcp.Tool {
 		p.CreateSyntheticCheck(),
 		p.UpdateSyntheticCheck(),
 		p.DeleteSyntheticCheck(),
+		p.EnableSyntheticCheck(),
+		p.DisableSyntheticCheck(),
+		p.GenerateFromOpenAPI(),
+		p.BuildAssertions(),
+		p.CreateCertCheck(),
 	}
 }
 
 // Handlers returns a map of tool name to handler function.
 func (p *Tools) Handlers() map[string]func(context.Context, map[string]interface{}) *client.ToolResult {
 	return map[string]func(context.Context, map[string]interface{}) *client.ToolResult{
-		"dash0_synthetic_checks_list":   p.ListSyntheticChecksHandler,
-		"dash0_synthetic_checks_get":    p.GetSyntheticCheckHandler,
-		"dash0_synthetic_checks_create": p.CreateSyntheticCheckHandler,
-		"dash0_synthetic_checks_update": p.UpdateSyntheticCheckHandler,
-		"dash0_synthetic_checks_delete": p.DeleteSyntheticCheckHandler,
+		"dash0_synthetic_checks_list":                  p.ListSyntheticChecksHandler,
+		"dash0_synthetic_checks_get":                   p.GetSyntheticCheckHandler,
+		"dash0_synthetic_checks_create":                p.CreateSyntheticCheckHandler,
+		"dash0_synthetic_checks_update":                p.UpdateSyntheticCheckHandler,
+		"dash0_synthetic_checks_delete":                p.DeleteSyntheticCheckHandler,
+		"dash0_synthetic_checks_enable":                p.EnableSyntheticCheckHandler,
+		"dash0_synthetic_checks_disable":               p.DisableSyntheticCheckHandler,
+		"dash0_synthetic_checks_generate_from_openapi": p.GenerateFromOpenAPIHandler,
+		"dash0_synthetic_checks_build_assertions":      p.BuildAssertionsHandler,
+		"dash0_synthetic_checks_create_cert_check":     p.CreateCertCheckHandler,
 	}
 }
 
@@ -56,29 +68,32 @@ func (p *Tools) ListSyntheticChecks() mcp.Tool {
 		Name:        "dash0_synthetic_checks_list",
 		Description: "List all synthetic checks in Dash0. Synthetic checks proactively monitor application availability and performance from multiple locations.",
 		InputSchema: mcp.ToolInputSchema{
-			Type:       "object",
-			Properties: map[string]interface{}{},
+			Type: "object",
+			Properties: map[string]interface{}{
+				"page_token": listing.PageTokenProperty(),
+				"summary":    listing.SummaryProperty(),
+			},
 		},
 	}
 }
 
 // ListSyntheticChecksHandler handles the dash0_synthetic_checks_list tool.
 func (p *Tools) ListSyntheticChecksHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
-	result := p.client.Get(ctx, basePath)
+	result := p.client.Get(ctx, listing.Path(basePath, args))
 	if result.Success {
 		result.Markdown = formatSyntheticChecksList(result.Data)
 	}
-	return result
+	return listing.Summarize(listing.Adapt(result), args, "Synthetic Checks")
 }
 
 // formatSyntheticChecksList formats synthetic checks as a markdown table.
 func formatSyntheticChecksList(data interface{}) string {
-	items := extractItems(data)
+	items := listing.FromResponse(data).Items
 	if len(items) == 0 {
 		return "## Synthetic Checks\n\nNo synthetic checks found.\n"
 	}
 
-	headers := []string{"#", "Name", "Kind", "Enabled", "Interval", "Locations", "URL", "Origin"}
+	headers := []string{"#", "Name", "Kind", "Enabled", "Interval", "Locations", "Target", "Origin"}
 	var rows [][]string
 
 	for i, item := range items {
@@ -92,7 +107,7 @@ func formatSyntheticChecksList(data interface{}) string {
 		enabled := ""
 		interval := ""
 		locations := ""
-		checkURL := ""
+		target := ""
 		origin := extractNestedField(m, "metadata", "origin")
 
 		if spec, ok := m["spec"].(map[string]interface{}); ok {
@@ -114,11 +129,10 @@ func formatSyntheticChecksList(data interface{}) string {
 				}
 			}
 			if plugin, ok := spec["plugin"].(map[string]interface{}); ok {
-				if ps, ok := plugin["spec"].(map[string]interface{}); ok {
-					if req, ok := ps["request"].(map[string]interface{}); ok {
-						checkURL = fmt.Sprintf("%v", req["url"])
-					}
+				if k, ok := plugin["kind"].(string); ok && k != "" {
+					kind = k
 				}
+				target = pluginTarget(plugin)
 			}
 		}
 
@@ -129,7 +143,7 @@ func formatSyntheticChecksList(data interface{}) string {
 			enabled,
 			interval,
 			locations,
-			formatter.Truncate(checkURL, 40),
+			formatter.Truncate(target, 40),
 			formatter.Truncate(origin, 25),
 		})
 	}
@@ -138,22 +152,31 @@ func formatSyntheticChecksList(data interface{}) string {
 	return formatter.Table("Synthetic Checks", summary, headers, rows, "")
 }
 
-// extractItems tries to get a slice of items from various response shapes.
-func extractItems(data interface{}) []interface{} {
-	if data == nil {
-		return nil
+// pluginTarget describes what a check's plugin probes: the URL of an HTTP
+// check, host:port of a TCP check, the name and record type of a DNS
+// check, the host of an ICMP check, or the steps of a multistep check.
+func pluginTarget(plugin map[string]interface{}) string {
+	ps, _ := plugin["spec"].(map[string]interface{})
+	if steps, ok := ps["steps"].([]interface{}); ok {
+		return fmt.Sprintf("%d steps", len(steps))
 	}
-	if arr, ok := data.([]interface{}); ok {
-		return arr
+	req, ok := ps["request"].(map[string]interface{})
+	if !ok {
+		return ""
 	}
-	if m, ok := data.(map[string]interface{}); ok {
-		for _, key := range []string{"items", "data", "results"} {
-			if arr, ok := m[key].([]interface{}); ok {
-				return arr
-			}
+	switch kind, _ := plugin["kind"].(string); kind {
+	case "tcp":
+		return fmt.Sprintf("%v:%v", req["host"], req["port"])
+	case "dns":
+		recordType, _ := req["recordType"].(string)
+		if recordType == "" {
+			recordType = "A"
 		}
+		return fmt.Sprintf("%v (%s)", req["name"], recordType)
+	case "icmp", "ping":
+		return fmt.Sprintf("%v", req["host"])
 	}
-	return nil
+	return fmt.Sprintf("%v", req["url"])
 }
 
 func extractNestedField(m map[string]interface{}, keys ...string) string {
@@ -215,8 +238,23 @@ Required structure:
 - kind: Must be "Dash0SyntheticCheck"
 - metadata.name: Check identifier (lowercase, alphanumeric, hyphens)
 - spec.enabled: Boolean to enable/disable the check
-- spec.plugin.kind: Plugin type (e.g., "http")
+- spec.plugin.kind: Plugin type: "http", "tcp", "dns", "icmp" (or "ping"), or "multistep"
 - spec.plugin.spec.request: Request configuration (CRITICAL: nested inside plugin.spec!)
+  - http: method, url, and optionally redirects and headers
+  - tcp: host and port, and optionally tls and timeout
+  - dns: name, and optionally recordType (A, AAAA, CNAME, MX, NS, PTR, SOA, SRV, TXT, CAA),
+    nameserver, and protocol (udp, tcp)
+  - icmp: host, and optionally count (1-10 packets) and timeout
+- spec.plugin.spec.steps: For multistep checks instead of request: an array of
+  {"name", "request"} HTTP steps run in order
+- spec.plugin.spec.assertions: Optional for http checks and multistep steps: an array of
+  {"kind", "spec"} assertions on the response. Build it with
+  dash0_synthetic_checks_build_assertions, or write it directly:
+  - status_code: {"operator": "is", "value": 200} or {"operator": "in_range", "min": 200, "max": 299}
+  - body: {"operator": "contains" or "not_contains", "value": "..."}
+  - json_path: {"path": "$.status", "operator": "is", "value": "ok"} (also is_not, contains, exists, not_exists)
+  - response_time: {"max": "500ms"}
+  - tls_certificate: {"minDaysUntilExpiry": 14}
 - spec.schedule.interval: Check frequency (e.g., "1m", "5m")
 - spec.schedule.locations: Array of locations (e.g., ["eu-west-1"])
 - spec.schedule.strategy: Execution strategy (e.g., "all_locations")
@@ -276,10 +314,51 @@ Example with headers and retries:
   }
 }
 
+Example TCP check (port reachable, with TLS handshake):
+{
+  "kind": "Dash0SyntheticCheck",
+  "metadata": {"name": "postgres-port"},
+  "spec": {
+    "enabled": true,
+    "plugin": {"kind": "tcp", "spec": {"request": {"host": "db.example.com", "port": 5432, "tls": true}}},
+    "schedule": {"interval": "1m", "locations": ["eu-west-1"], "strategy": "all_locations"}
+  }
+}
+
+Example DNS check:
+{
+  "kind": "Dash0SyntheticCheck",
+  "metadata": {"name": "api-dns"},
+  "spec": {
+    "enabled": true,
+    "plugin": {"kind": "dns", "spec": {"request": {"name": "api.example.com", "recordType": "A"}}},
+    "schedule": {"interval": "5m", "locations": ["eu-west-1"], "strategy": "all_locations"}
+  }
+}
+
+Example multi-step check (log in, then read the account):
+{
+  "kind": "Dash0SyntheticCheck",
+  "metadata": {"name": "login-flow"},
+  "spec": {
+    "enabled": true,
+    "plugin": {
+      "kind": "multistep",
+      "spec": {
+        "steps": [
+          {"name": "login", "request": {"method": "post", "url": "https://api.example.com/login"}},
+          {"name": "account", "request": {"method": "get", "url": "https://api.example.com/account"}}
+        ]
+      }
+    },
+    "schedule": {"interval": "5m", "locations": ["eu-west-1"], "strategy": "all_locations"}
+  }
+}
+
 Available locations: eu-west-1, us-east-1, us-west-2, ap-southeast-1, etc.`,
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
-			Properties: map[string]interface{}{
+			Properties: schema.WithBodyYAML(map[string]interface{}{
 				"body": map[string]interface{}{
 					"type":        "object",
 					"description": "The synthetic check configuration in Dash0SyntheticCheck CRD format.",
@@ -310,11 +389,11 @@ Available locations: eu-west-1, us-east-1, us-west-2, ap-southeast-1, etc.`,
 								},
 								"plugin": map[string]interface{}{
 									"type":        "object",
-									"description": "Plugin configuration with kind and nested spec.request",
+									"description": "Plugin configuration with kind and nested spec.request (or spec.steps for multistep)",
 									"properties": map[string]interface{}{
 										"kind": map[string]interface{}{
 											"type":        "string",
-											"description": "Plugin type (e.g., 'http')",
+											"description": "Plugin type: http, tcp, dns, icmp (or ping), or multistep",
 										},
 										"spec": map[string]interface{}{
 											"type":        "object",
@@ -322,26 +401,67 @@ Available locations: eu-west-1, us-east-1, us-west-2, ap-southeast-1, etc.`,
 											"properties": map[string]interface{}{
 												"request": map[string]interface{}{
 													"type":        "object",
-													"description": "HTTP request configuration",
+													"description": "Request configuration; its fields depend on the plugin kind",
 													"properties": map[string]interface{}{
 														"method": map[string]interface{}{
 															"type":        "string",
-															"description": "HTTP method (get, post, put, delete)",
+															"description": "http: HTTP method (get, post, put, delete)",
 														},
 														"url": map[string]interface{}{
 															"type":        "string",
-															"description": "URL to check",
+															"description": "http: URL to check",
 														},
 														"redirects": map[string]interface{}{
 															"type":        "string",
-															"description": "Redirect handling (follow, reject)",
+															"description": "http: Redirect handling (follow, reject)",
 														},
 														"headers": map[string]interface{}{
 															"type":        "object",
-															"description": "HTTP headers",
+															"description": "http: HTTP headers",
+														},
+														"host": map[string]interface{}{
+															"type":        "string",
+															"description": "tcp, icmp: Host name or IP address to connect to",
+														},
+														"port": map[string]interface{}{
+															"type":        "integer",
+															"description": "tcp: Port to connect to (1-65535)",
+														},
+														"tls": map[string]interface{}{
+															"type":        "boolean",
+															"description": "tcp: Perform a TLS handshake after connecting",
+														},
+														"name": map[string]interface{}{
+															"type":        "string",
+															"description": "dns: Domain name to resolve",
+														},
+														"recordType": map[string]interface{}{
+															"type":        "string",
+															"description": "dns: Record type to query (default: A)",
+															"enum":        []string{"A", "AAAA", "CNAME", "MX", "NS", "PTR", "SOA", "SRV", "TXT", "CAA"},
+														},
+														"nameserver": map[string]interface{}{
+															"type":        "string",
+															"description": "dns: Name server to query instead of the default resolver",
+														},
+														"count": map[string]interface{}{
+															"type":        "integer",
+															"description": "icmp: Number of packets to send (1-10)",
+														},
+														"timeout": map[string]interface{}{
+															"type":        "string",
+															"description": "tcp, icmp: Timeout (e.g., '5s')",
 														},
 													},
 												},
+												"assertions": map[string]interface{}{
+													"type":        "array",
+													"description": "http: Assertions on the response, each {\"kind\", \"spec\"}; see dash0_synthetic_checks_build_assertions",
+												},
+												"steps": map[string]interface{}{
+													"type":        "array",
+													"description": "multistep: HTTP steps run in order, each {\"name\", \"request\"} with an http request and optional assertions",
+												},
 											},
 										},
 									},
@@ -385,20 +505,23 @@ Available locations: eu-west-1, us-east-1, us-west-2, ap-southeast-1, etc.`,
 					},
 					"required": []interface{}{"kind", "metadata", "spec"},
 				},
-			},
-			Required: []string{"body"},
+			}),
 		},
 	}
 }
 
 // CreateSyntheticCheckHandler handles the dash0_synthetic_checks_create tool.
 func (p *Tools) CreateSyntheticCheckHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
-	body, ok := args["body"]
-	if !ok {
-		return client.ErrorResult(400, "body is required")
+	body, errResult := schema.Body(args)
+	if errResult != nil {
+		return errResult
+	}
+	body, fixes := schema.Normalize(schema.KindSyntheticCheck, body)
+	if result := schema.Check(schema.KindSyntheticCheck, body); result != nil {
+		return schema.Report(result, fixes)
 	}
 
-	return p.client.Post(ctx, basePath, body)
+	return schema.Report(p.client.Post(ctx, basePath, body), fixes)
 }
 
 // UpdateSyntheticCheck returns the dash0_synthetic_checks_update tool definition.
@@ -432,7 +555,7 @@ The body should follow the same Dash0SyntheticCheck CRD format as create:
 }`,
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
-			Properties: map[string]interface{}{
+			Properties: schema.WithBodyYAML(map[string]interface{}{
 				"origin_or_id": map[string]interface{}{
 					"type":        "string",
 					"description": "The origin or ID of the synthetic check to update.",
@@ -441,8 +564,8 @@ The body should follow the same Dash0SyntheticCheck CRD format as create:
 					"type":        "object",
 					"description": "The updated synthetic check configuration in Dash0SyntheticCheck CRD format with nested plugin.spec.request structure.",
 				},
-			},
-			Required: []string{"origin_or_id", "body"},
+			}),
+			Required: []string{"origin_or_id"},
 		},
 	}
 }
@@ -454,13 +577,17 @@ func (p *Tools) UpdateSyntheticCheckHandler(ctx context.Context, args map[string
 		return client.ErrorResult(400, "origin_or_id is required")
 	}
 
-	body, ok := args["body"]
-	if !ok {
-		return client.ErrorResult(400, "body is required")
+	body, errResult := schema.Body(args)
+	if errResult != nil {
+		return errResult
+	}
+	body, fixes := schema.Normalize(schema.KindSyntheticCheck, body)
+	if result := schema.Check(schema.KindSyntheticCheck, body); result != nil {
+		return schema.Report(result, fixes)
 	}
 
 	path := fmt.Sprintf(basePath+"/%s", url.PathEscape(originOrID))
-	return p.client.Put(ctx, path, body)
+	return schema.Report(p.client.Put(ctx, path, body), fixes)
 }
 
 // DeleteSyntheticCheck returns the dash0_synthetic_checks_delete tool definition.
@@ -498,5 +625,6 @@ func Register(reg *registry.Registry, c *client.Client) {
 	for _, tool := range p.Tools() {
 		handler := p.Handlers()[tool.Name]
 		reg.Register(tool, handler)
+		reg.AddExamples(tool.Name, p.Examples()[tool.Name]...)
 	}
 }
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/schema"
 )
 
 func TestNew(t *testing.T) {
@@ -26,16 +27,21 @@ func TestTools(t *testing.T) {
 	pkg := New(&client.Client{})
 	tools := pkg.Tools()
 
-	if len(tools) != 5 {
-		t.Errorf("Tools() returned %d tools, expected 5", len(tools))
+	if len(tools) != 10 {
+		t.Errorf("Tools() returned %d tools, expected 10", len(tools))
 	}
 
 	expectedNames := map[string]bool{
-		"dash0_synthetic_checks_list":   false,
-		"dash0_synthetic_checks_get":    false,
-		"dash0_synthetic_checks_create": false,
-		"dash0_synthetic_checks_update": false,
-		"dash0_synthetic_checks_delete": false,
+		"dash0_synthetic_checks_list":                  false,
+		"dash0_synthetic_checks_get":                   false,
+		"dash0_synthetic_checks_create":                false,
+		"dash0_synthetic_checks_update":                false,
+		"dash0_synthetic_checks_delete":                false,
+		"dash0_synthetic_checks_enable":                false,
+		"dash0_synthetic_checks_disable":               false,
+		"dash0_synthetic_checks_generate_from_openapi": false,
+		"dash0_synthetic_checks_build_assertions":      false,
+		"dash0_synthetic_checks_create_cert_check":     false,
 	}
 
 	for _, tool := range tools {
@@ -62,6 +68,11 @@ func TestHandlers(t *testing.T) {
 		"dash0_synthetic_checks_create",
 		"dash0_synthetic_checks_update",
 		"dash0_synthetic_checks_delete",
+		"dash0_synthetic_checks_enable",
+		"dash0_synthetic_checks_disable",
+		"dash0_synthetic_checks_generate_from_openapi",
+		"dash0_synthetic_checks_build_assertions",
+		"dash0_synthetic_checks_create_cert_check",
 	}
 
 	if len(handlers) != len(expectedHandlers) {
@@ -230,9 +241,12 @@ func TestCreateSyntheticCheckToolDefinition(t *testing.T) {
 		t.Error("CreateSyntheticCheck() description should emphasize nested plugin structure")
 	}
 
-	// Should require body
-	if len(tool.InputSchema.Required) != 1 || tool.InputSchema.Required[0] != "body" {
-		t.Error("CreateSyntheticCheck() should require 'body'")
+	// Takes body or body_yaml, so neither is required
+	if len(tool.InputSchema.Required) != 0 {
+		t.Errorf("CreateSyntheticCheck() Required = %v, expected none", tool.InputSchema.Required)
+	}
+	if _, ok := tool.InputSchema.Properties["body_yaml"]; !ok {
+		t.Error("CreateSyntheticCheck() should accept 'body_yaml'")
 	}
 
 	// Body should have properties for kind, metadata, spec
@@ -266,6 +280,42 @@ func TestCreateSyntheticCheckHandler(t *testing.T) {
 			args:        map[string]interface{}{},
 			expectError: "body is required",
 		},
+		{
+			name: "flat request is normalized",
+			args: map[string]interface{}{
+				"body": map[string]interface{}{
+					"kind":     "Dash0SyntheticCheck",
+					"metadata": map[string]interface{}{"name": "flat"},
+					"spec": map[string]interface{}{
+						"enabled": true,
+						"plugin": map[string]interface{}{
+							"kind": "http",
+							"spec": map[string]interface{}{"method": "get", "url": "https://example.com"},
+						},
+						"schedule": map[string]interface{}{"interval": "1m", "locations": []interface{}{"eu-west-1"}},
+					},
+				},
+			},
+			expectSuccess: true,
+		},
+		{
+			name: "request without url is rejected before sending",
+			args: map[string]interface{}{
+				"body": map[string]interface{}{
+					"kind":     "Dash0SyntheticCheck",
+					"metadata": map[string]interface{}{"name": "no-url"},
+					"spec": map[string]interface{}{
+						"enabled": true,
+						"plugin": map[string]interface{}{
+							"kind": "http",
+							"spec": map[string]interface{}{"method": "get"},
+						},
+						"schedule": map[string]interface{}{"interval": "1m", "locations": []interface{}{"eu-west-1"}},
+					},
+				},
+			},
+			expectError: "/spec/plugin/spec/request/url",
+		},
 		{
 			name: "valid body with HTTP check",
 			args: map[string]interface{}{
@@ -322,6 +372,8 @@ func TestCreateSyntheticCheckHandler(t *testing.T) {
 			if tt.expectError != "" {
 				if result.Success {
 					t.Error("Expected error, got success")
+				} else if !strings.Contains(result.Error.Message(), tt.expectError) {
+					t.Errorf("error = %q, want it to mention %q", result.Error.Message(), tt.expectError)
 				}
 				return
 			}
@@ -329,6 +381,15 @@ func TestCreateSyntheticCheckHandler(t *testing.T) {
 			if tt.expectSuccess && !result.Success {
 				t.Errorf("Expected success, got failure: %v", result.Error)
 			}
+			if tt.name == "flat request is normalized" {
+				plugin := receivedBody["spec"].(map[string]interface{})["plugin"].(map[string]interface{})
+				if _, ok := plugin["spec"].(map[string]interface{})["request"]; !ok {
+					t.Errorf("sent plugin = %v, want the request nested", plugin)
+				}
+				if !strings.Contains(result.Markdown, "## Normalized") {
+					t.Errorf("markdown does not report the normalization:\n%s", result.Markdown)
+				}
+			}
 		})
 	}
 }
@@ -341,17 +402,12 @@ func TestUpdateSyntheticCheckToolDefinition(t *testing.T) {
 		t.Errorf("UpdateSyntheticCheck() name = %s, expected dash0_synthetic_checks_update", tool.Name)
 	}
 
-	// Should require origin_or_id and body
-	if len(tool.InputSchema.Required) != 2 {
-		t.Error("UpdateSyntheticCheck() should require 2 parameters")
-	}
-
-	required := make(map[string]bool)
-	for _, r := range tool.InputSchema.Required {
-		required[r] = true
+	// Should require origin_or_id; the body comes from body or body_yaml
+	if len(tool.InputSchema.Required) != 1 || tool.InputSchema.Required[0] != "origin_or_id" {
+		t.Errorf("UpdateSyntheticCheck() Required = %v, expected [origin_or_id]", tool.InputSchema.Required)
 	}
-	if !required["origin_or_id"] || !required["body"] {
-		t.Error("UpdateSyntheticCheck() should require origin_or_id and body")
+	if _, ok := tool.InputSchema.Properties["body_yaml"]; !ok {
+		t.Error("UpdateSyntheticCheck() should accept 'body_yaml'")
 	}
 }
 
@@ -618,3 +674,350 @@ func TestSyntheticCheckPluginStructure(t *testing.T) {
 		t.Error("spec should have 'enabled' property")
 	}
 }
+
+func TestToggleSyntheticCheckHandlers(t *testing.T) {
+	var patched map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.EscapedPath() != "/api/synthetic-checks/api%20check" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.EscapedPath())
+		}
+		json.NewDecoder(r.Body).Decode(&patched)
+		json.NewEncoder(w).Encode(map[string]interface{}{})
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.DisableSyntheticCheckHandler(context.Background(), map[string]interface{}{"origin_or_id": "api check"})
+	if !result.Success {
+		t.Fatalf("DisableSyntheticCheckHandler failed: %v", result.Error)
+	}
+	if spec, _ := patched["spec"].(map[string]interface{}); len(patched) != 1 || spec["enabled"] != false || len(spec) != 1 {
+		t.Errorf("patch = %v, expected only spec.enabled=false", patched)
+	}
+	if !strings.Contains(result.Markdown, "Paused") {
+		t.Errorf("Markdown = %s", result.Markdown)
+	}
+
+	if result := pkg.EnableSyntheticCheckHandler(context.Background(), map[string]interface{}{}); result.Success {
+		t.Error("expected a missing origin_or_id to fail")
+	}
+}
+
+func TestToggleSyntheticCheckHandlers_PutFallback(t *testing.T) {
+	var put map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPatch:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"kind": "Dash0SyntheticCheck",
+				"metadata": map[string]interface{}{
+					"name":   "api-health-check",
+					"labels": map[string]interface{}{"dash0.com/id": "abc", "dash0.com/version": "3"},
+				},
+				"spec": map[string]interface{}{"enabled": false, "plugin": map[string]interface{}{"kind": "http"}},
+			})
+		case http.MethodPut:
+			json.NewDecoder(r.Body).Decode(&put)
+			json.NewEncoder(w).Encode(put)
+		}
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.EnableSyntheticCheckHandler(context.Background(), map[string]interface{}{"origin_or_id": "api-health-check"})
+	if !result.Success {
+		t.Fatalf("EnableSyntheticCheckHandler failed: %v", result.Error)
+	}
+	spec, _ := put["spec"].(map[string]interface{})
+	if spec["enabled"] != true || spec["plugin"] == nil {
+		t.Errorf("put = %v, expected the check with spec.enabled=true", put)
+	}
+	meta, _ := put["metadata"].(map[string]interface{})
+	if labels, _ := meta["labels"].(map[string]interface{}); labels["dash0.com/id"] != nil || labels["dash0.com/version"] != nil {
+		t.Errorf("metadata = %v, expected the server-maintained labels dropped", meta)
+	}
+}
+
+const openAPIFixture = `
+openapi: 3.0.0
+info:
+  title: Payments API
+servers:
+  - url: https://{env}.example.com/v1
+    variables:
+      env:
+        default: api
+paths:
+  /health:
+    get:
+      operationId: getHealth
+  /payments:
+    get:
+      parameters:
+        - name: status
+          in: query
+    post:
+      operationId: createPayment
+  /payments/{id}:
+    get:
+      operationId: getPayment
+  /search:
+    get:
+      parameters:
+        - name: q
+          in: query
+          required: true
+`
+
+func TestGenerateFromOpenAPIHandler(t *testing.T) {
+	var received []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/openapi.yaml" {
+			w.Write([]byte("swagger: '2.0'\nbasePath: /v2\npaths:\n  /status:\n    get: {}\n"))
+			return
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/api/synthetic-checks" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var check map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&check)
+		received = append(received, check)
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": "check-1"})
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+
+	t.Run("inline spec", func(t *testing.T) {
+		received = nil
+		result := pkg.GenerateFromOpenAPIHandler(context.Background(), map[string]interface{}{
+			"spec":      openAPIFixture,
+			"interval":  "1m",
+			"locations": []interface{}{"us-east-1"},
+		})
+		if !result.Success {
+			t.Fatalf("expected success, got %v", result.Error)
+		}
+		if len(received) != 2 {
+			t.Fatalf("expected 2 checks created, got %d", len(received))
+		}
+		meta := received[0]["metadata"].(map[string]interface{})
+		if meta["name"] != "payments-api-gethealth" {
+			t.Errorf("name = %v, want payments-api-gethealth", meta["name"])
+		}
+		spec := received[0]["spec"].(map[string]interface{})
+		request := spec["plugin"].(map[string]interface{})["spec"].(map[string]interface{})["request"].(map[string]interface{})
+		if request["url"] != "https://api.example.com/v1/health" || request["method"] != "get" {
+			t.Errorf("request = %v", request)
+		}
+		schedule := spec["schedule"].(map[string]interface{})
+		if schedule["interval"] != "1m" || schedule["locations"].([]interface{})[0] != "us-east-1" {
+			t.Errorf("schedule = %v", schedule)
+		}
+		data := result.Data.(map[string]interface{})
+		if data["created"] != 2 || data["skipped"] != 2 {
+			t.Errorf("created/skipped = %v/%v, want 2/2", data["created"], data["skipped"])
+		}
+		for _, s := range []string{"2 created, 0 failed, 2 skipped", "path parameters", `required parameter "q"`} {
+			if !strings.Contains(result.Markdown, s) {
+				t.Errorf("markdown missing %q:\n%s", s, result.Markdown)
+			}
+		}
+	})
+
+	t.Run("spec url dry run", func(t *testing.T) {
+		received = nil
+		result := pkg.GenerateFromOpenAPIHandler(context.Background(), map[string]interface{}{
+			"spec_url": server.URL + "/openapi.yaml",
+			"dry_run":  true,
+		})
+		if !result.Success {
+			t.Fatalf("expected success, got %v", result.Error)
+		}
+		if len(received) != 0 {
+			t.Errorf("dry run must not create checks, got %d", len(received))
+		}
+		data := result.Data.(map[string]interface{})
+		if data["base_url"] != server.URL+"/v2" {
+			t.Errorf("base_url = %v, want the relative basePath resolved against spec_url", data["base_url"])
+		}
+		if checks := data["checks"].([]interface{}); len(checks) != 1 {
+			t.Errorf("expected 1 check in dry run, got %d", len(checks))
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for name, args := range map[string]map[string]interface{}{
+			"no spec":       {},
+			"not openapi":   {"spec": "foo: bar"},
+			"no server":     {"spec": "openapi: 3.0.0\npaths:\n  /health:\n    get: {}\n"},
+			"bad locations": {"spec": openAPIFixture, "locations": []interface{}{}},
+		} {
+			if result := pkg.GenerateFromOpenAPIHandler(context.Background(), args); result.Success {
+				t.Errorf("%s: expected failure", name)
+			}
+		}
+	})
+}
+
+func TestPluginTarget(t *testing.T) {
+	tests := []struct {
+		plugin string
+		want   string
+	}{
+		{`{"kind": "http", "spec": {"request": {"method": "get", "url": "https://example.com"}}}`, "https://example.com"},
+		{`{"kind": "tcp", "spec": {"request": {"host": "db.example.com", "port": 5432}}}`, "db.example.com:5432"},
+		{`{"kind": "dns", "spec": {"request": {"name": "example.com"}}}`, "example.com (A)"},
+		{`{"kind": "icmp", "spec": {"request": {"host": "10.0.0.1"}}}`, "10.0.0.1"},
+		{`{"kind": "multistep", "spec": {"steps": [{}, {}]}}`, "2 steps"},
+		{`{"kind": "browser", "spec": {}}`, ""},
+	}
+	for _, tt := range tests {
+		var plugin map[string]interface{}
+		if err := json.Unmarshal([]byte(tt.plugin), &plugin); err != nil {
+			t.Fatal(err)
+		}
+		if got := pluginTarget(plugin); got != tt.want {
+			t.Errorf("pluginTarget(%s) = %q, want %q", tt.plugin, got, tt.want)
+		}
+	}
+}
+
+func TestBuildAssertionsHandler(t *testing.T) {
+	pkg := New(&client.Client{})
+
+	result := pkg.BuildAssertionsHandler(context.Background(), map[string]interface{}{
+		"status_code":       "2xx",
+		"body_contains":     []interface{}{"ok"},
+		"body_not_contains": []interface{}{"error"},
+		"json_path":         map[string]interface{}{"$.status": "up", "$.count": float64(3)},
+		"json_path_exists":  []interface{}{"$.data.id"},
+		"max_latency":       "1500ms",
+		"tls_cert_min_days": float64(14),
+	})
+	if !result.Success {
+		t.Fatalf("BuildAssertionsHandler failed: %v", result.Error)
+	}
+	assertions := result.Data.(map[string]interface{})["assertions"].([]map[string]interface{})
+	want := []string{
+		`{"kind":"status_code","spec":{"max":299,"min":200,"operator":"in_range"}}`,
+		`{"kind":"body","spec":{"operator":"contains","value":"ok"}}`,
+		`{"kind":"body","spec":{"operator":"not_contains","value":"error"}}`,
+		`{"kind":"json_path","spec":{"operator":"is","path":"$.count","value":"3"}}`,
+		`{"kind":"json_path","spec":{"operator":"is","path":"$.status","value":"up"}}`,
+		`{"kind":"json_path","spec":{"operator":"exists","path":"$.data.id"}}`,
+		`{"kind":"response_time","spec":{"max":"1.5s"}}`,
+		`{"kind":"tls_certificate","spec":{"minDaysUntilExpiry":14}}`,
+	}
+	if len(assertions) != len(want) {
+		t.Fatalf("got %d assertions, want %d", len(assertions), len(want))
+	}
+	for i, a := range assertions {
+		b, _ := json.Marshal(a)
+		if string(b) != want[i] {
+			t.Errorf("assertions[%d] = %s, want %s", i, b, want[i])
+		}
+	}
+
+	// The built block is valid in a check.
+	check := httpCheck("api", "https://example.com", "1m", "eu-west-1")
+	pluginSpec := check["spec"].(map[string]interface{})["plugin"].(map[string]interface{})["spec"].(map[string]interface{})
+	var block []interface{}
+	b, _ := json.Marshal(assertions)
+	json.Unmarshal(b, &block)
+	pluginSpec["assertions"] = block
+	var body interface{}
+	b, _ = json.Marshal(check)
+	json.Unmarshal(b, &body)
+	if errs := schema.Validate(schema.KindSyntheticCheck, body); len(errs) != 0 {
+		t.Errorf("built assertions are not valid in a check: %+v", errs)
+	}
+
+	for _, tt := range []struct {
+		code string
+		want string
+	}{
+		{"200", `{"operator":"is","value":200}`},
+		{"500-504", `{"max":504,"min":500,"operator":"in_range"}`},
+	} {
+		spec, err := parseStatusCode(tt.code)
+		if err != nil {
+			t.Errorf("parseStatusCode(%q) failed: %v", tt.code, err)
+			continue
+		}
+		if b, _ := json.Marshal(spec); string(b) != tt.want {
+			t.Errorf("parseStatusCode(%q) = %s, want %s", tt.code, b, tt.want)
+		}
+	}
+
+	for name, args := range map[string]map[string]interface{}{
+		"no assertions":   {},
+		"bad status code": {"status_code": "6xx"},
+		"reversed range":  {"status_code": "299-200"},
+		"relative path":   {"json_path_exists": []interface{}{"status"}},
+		"bad latency":     {"max_latency": "fast"},
+		"fractional days": {"tls_cert_min_days": float64(1.5)},
+		"body not a list": {"body_contains": "ok"},
+	} {
+		if result := pkg.BuildAssertionsHandler(context.Background(), args); result.Success {
+			t.Errorf("%s: expected failure", name)
+		}
+	}
+}
+
+func TestCreateCertCheckHandler(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/synthetic-checks" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+		json.NewEncoder(w).Encode(received)
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	result := pkg.CreateCertCheckHandler(context.Background(), map[string]interface{}{
+		"hostname":     "https://admin.example.com/",
+		"port":         float64(8443),
+		"warning_days": float64(14),
+	})
+	if !result.Success {
+		t.Fatalf("CreateCertCheckHandler failed: %v", result.Error)
+	}
+	if errs := schema.Validate(schema.KindSyntheticCheck, received); len(errs) != 0 {
+		t.Errorf("created check is not valid: %+v", errs)
+	}
+	if name := received["metadata"].(map[string]interface{})["name"]; name != "tls-expiry-admin-example-com" {
+		t.Errorf("name = %v, want tls-expiry-admin-example-com", name)
+	}
+	spec := received["spec"].(map[string]interface{})
+	pluginSpec := spec["plugin"].(map[string]interface{})["spec"].(map[string]interface{})
+	if url := pluginSpec["request"].(map[string]interface{})["url"]; url != "https://admin.example.com:8443/" {
+		t.Errorf("url = %v, want https://admin.example.com:8443/", url)
+	}
+	assertion := pluginSpec["assertions"].([]interface{})[0].(map[string]interface{})
+	if assertion["kind"] != "tls_certificate" || assertion["spec"].(map[string]interface{})["minDaysUntilExpiry"] != float64(14) {
+		t.Errorf("assertion = %v, want tls_certificate with 14 days", assertion)
+	}
+	if interval := spec["schedule"].(map[string]interface{})["interval"]; interval != "1h" {
+		t.Errorf("interval = %v, want the 1h default", interval)
+	}
+	if !strings.Contains(result.Markdown, "less than 14 days") {
+		t.Errorf("Markdown = %s", result.Markdown)
+	}
+
+	for name, args := range map[string]map[string]interface{}{
+		"no hostname":  {},
+		"url path":     {"hostname": "example.com/health"},
+		"bad port":     {"hostname": "example.com", "port": float64(70000)},
+		"zero days":    {"hostname": "example.com", "warning_days": float64(0)},
+		"no locations": {"hostname": "example.com", "locations": []interface{}{}},
+	} {
+		if result := pkg.CreateCertCheckHandler(context.Background(), args); result.Success {
+			t.Errorf("%s: expected failure", name)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package views
+
+import "github.com/npcomplete777/dash0-mcp/internal/registry"
+
+// Compile-time interface check.
+var _ registry.ExampleProvider = (*Tools)(nil)
+
+// Examples returns example invocations for the view tools.
+func (p *Tools) Examples() map[string][]registry.Example {
+	return map[string][]registry.Example{
+		"dash0_views_list": {
+			{Title: "List all views", Arguments: map[string]interface{}{}},
+		},
+		"dash0_views_get": {
+			{Title: "Get a view by origin", Arguments: map[string]interface{}{"origin_or_id": "production-services"}},
+		},
+		"dash0_views_create": {
+			{
+				Title: "Create a resources view",
+				Arguments: map[string]interface{}{
+					"body": map[string]interface{}{
+						"kind":     "Dash0View",
+						"metadata": map[string]interface{}{"name": "production-services"},
+						"spec":     map[string]interface{}{"type": "resources"},
+					},
+				},
+			},
+			{
+				Title: "Create a view of checkout errors with columns and a chart",
+				Arguments: map[string]interface{}{
+					"body": map[string]interface{}{
+						"kind":     "Dash0View",
+						"metadata": map[string]interface{}{"name": "checkout-errors"},
+						"spec": map[string]interface{}{
+							"type":    "spans",
+							"display": map[string]interface{}{"name": "Checkout errors", "folder": []interface{}{"Payments"}},
+							"filter": []interface{}{
+								map[string]interface{}{"key": "service.name", "operator": "is", "value": "checkout"},
+								map[string]interface{}{"key": "otel.span.status_code", "operator": "is", "value": "ERROR"},
+							},
+							"timeRange": map[string]interface{}{"from": "now-1h", "to": "now"},
+							"table": map[string]interface{}{
+								"columns": []interface{}{
+									map[string]interface{}{"key": "otel.span.name", "label": "Operation"},
+									map[string]interface{}{"key": "otel.span.duration", "label": "Duration"},
+								},
+								"sort": []interface{}{map[string]interface{}{"key": "otel.span.duration", "direction": "descending"}},
+							},
+							"visualizations": []interface{}{
+								map[string]interface{}{"renderedAs": "time_series", "metric": "error_rate", "groupBy": []interface{}{"otel.span.name"}},
+							},
+						},
+					},
+				},
+			},
+			{
+				Title: "Create a view from Kubernetes-style YAML",
+				Arguments: map[string]interface{}{
+					"body_yaml": "kind: Dash0View\nmetadata:\n  name: payment-logs\nspec:\n  type: logs\n  filter:\n    - key: service.name\n      operator: is\n      value: payment\n",
+				},
+			},
+			{
+				Title: "Create a view of one service's warning and error logs",
+				Arguments: map[string]interface{}{
+					"body": map[string]interface{}{
+						"kind":     "Dash0View",
+						"metadata": map[string]interface{}{"name": "api-problem-logs"},
+						"spec": map[string]interface{}{
+							"type":    "logs",
+							"query":   "timeout",
+							"filter":  []interface{}{map[string]interface{}{"key": "service.name", "operator": "is", "value": "api"}, map[string]interface{}{"key": "otel.log.severity.range", "operator": "is_not", "value": "INFO"}},
+							"groupBy": []interface{}{"k8s.pod.name"},
+						},
+					},
+				},
+			},
+		},
+		"dash0_views_update": {
+			{
+				Title: "Replace a view",
+				Arguments: map[string]interface{}{
+					"origin_or_id": "production-services",
+					"body": map[string]interface{}{
+						"kind":     "Dash0View",
+						"metadata": map[string]interface{}{"name": "production-services"},
+						"spec":     map[string]interface{}{"type": "resources"},
+					},
+				},
+			},
+		},
+		"dash0_views_delete": {
+			{Title: "Delete a view", Arguments: map[string]interface{}{"origin_or_id": "production-services"}},
+		},
+	}
+}
@@ -7,7 +7,9 @@ import (
 
 	"github.com/npcomplete777/dash0-mcp/internal/client"
 	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+	"github.com/npcomplete777/dash0-mcp/internal/listing"
 	"github.com/npcomplete777/dash0-mcp/internal/registry"
+	"github.com/npcomplete777/dash0-mcp/internal/schema"
 	mcp "github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -15,6 +17,12 @@ const (
 	basePath = "/api/views"
 )
 
+// viewTypes are the values of spec.type.
+var viewTypes = []string{"resources", "spans", "logs", "metrics", "web_events"}
+
+// filterOperators are the operators of spec.filter items.
+var filterOperators = []string{"is", "is_not", "contains", "starts_with", "gt", "lt", "is_set", "is_not_set"}
+
 // Compile-time interface check.
 var _ registry.ToolProvider = (*Tools)(nil)
 
@@ -56,19 +64,22 @@ func (p *Tools) ListViews() mcp.Tool {
 		Name:        "dash0_views_list",
 		Description: "List all saved views in Dash0. Views are saved queries and filters for logs, traces, and metrics exploration.",
 		InputSchema: mcp.ToolInputSchema{
-			Type:       "object",
-			Properties: map[string]interface{}{},
+			Type: "object",
+			Properties: map[string]interface{}{
+				"page_token": listing.PageTokenProperty(),
+				"summary":    listing.SummaryProperty(),
+			},
 		},
 	}
 }
 
 // ListViewsHandler handles the dash0_views_list tool.
 func (p *Tools) ListViewsHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
-	result := p.client.Get(ctx, basePath)
+	result := p.client.Get(ctx, listing.Path(basePath, args))
 	if result.Success {
 		result.Markdown = formatter.FormatListResponse("Views", result.Data)
 	}
-	return result
+	return listing.Summarize(listing.Adapt(result), args, "Views")
 }
 
 // GetView returns the dash0_views_get tool definition.
@@ -111,24 +122,54 @@ IMPORTANT: Views use Kubernetes CRD format (Dash0View).
 Required structure:
 - kind: Must be "Dash0View"
 - metadata.name: View identifier (lowercase, alphanumeric, hyphens)
-- spec.type: Must be "resources" (currently the only supported type)
+- spec.type: What the view shows: "resources", "spans", "logs", "metrics", or "web_events"
+
+Optional spec fields:
+- display: {"name", "description", "folder": ["Team", "Service"]} shown in the Dash0 UI
+- query: Free-text search applied with the filters
+- filter: Attribute filters, each {"key", "operator", "value"}; operators are is, is_not,
+  contains, starts_with, gt, lt, and is_set / is_not_set (without value)
+- timeRange: {"from": "now-1h", "to": "now"}
+- table.columns: Columns to show, each {"key", "label", "colSize"}
+- table.sort: Sort order, each {"key", "direction": "ascending" | "descending"}
+- groupBy: Attribute keys to group rows by
+- visualizations: Charts above the table, each {"renderedAs": "time_series" | "bar" |
+  "stacked_bar" | "heatmap" | "table", "metric": "count" | "error_rate" | "duration_p50" |
+  "duration_p95" | "duration_p99", "groupBy": [...]}
 
-Example body:
+Example body (minimal):
 {
   "kind": "Dash0View",
   "metadata": {"name": "production-services"},
   "spec": {"type": "resources"}
 }
 
-Another example:
+Example body (checkout errors in the last hour):
 {
   "kind": "Dash0View",
-  "metadata": {"name": "error-traces"},
-  "spec": {"type": "resources"}
+  "metadata": {"name": "checkout-errors"},
+  "spec": {
+    "type": "spans",
+    "display": {"name": "Checkout errors", "folder": ["Payments"]},
+    "filter": [
+      {"key": "service.name", "operator": "is", "value": "checkout"},
+      {"key": "otel.span.status_code", "operator": "is", "value": "ERROR"}
+    ],
+    "timeRange": {"from": "now-1h", "to": "now"},
+    "table": {
+      "columns": [
+        {"key": "otel.span.name", "label": "Operation"},
+        {"key": "otel.span.duration", "label": "Duration"},
+        {"key": "http.status_code"}
+      ],
+      "sort": [{"key": "otel.span.duration", "direction": "descending"}]
+    },
+    "visualizations": [{"renderedAs": "time_series", "metric": "error_rate", "groupBy": ["otel.span.name"]}]
+  }
 }`,
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
-			Properties: map[string]interface{}{
+			Properties: schema.WithBodyYAML(map[string]interface{}{
 				"body": map[string]interface{}{
 					"type":        "object",
 					"description": "The view configuration in Dash0View CRD format.",
@@ -155,8 +196,46 @@ Another example:
 							"properties": map[string]interface{}{
 								"type": map[string]interface{}{
 									"type":        "string",
-									"description": "View type (currently only 'resources' is supported)",
-									"enum":        []string{"resources"},
+									"description": "What the view shows",
+									"enum":        viewTypes,
+								},
+								"display": map[string]interface{}{
+									"type":        "object",
+									"description": "Display name, description, and folder path in the Dash0 UI",
+								},
+								"query": map[string]interface{}{
+									"type":        "string",
+									"description": "Free-text search applied with the filters",
+								},
+								"filter": map[string]interface{}{
+									"type":        "array",
+									"description": "Attribute filters, each {key, operator, value}",
+									"items": map[string]interface{}{
+										"type": "object",
+										"properties": map[string]interface{}{
+											"key":      map[string]interface{}{"type": "string"},
+											"operator": map[string]interface{}{"type": "string", "enum": filterOperators},
+											"value":    map[string]interface{}{"type": []string{"string", "number", "boolean"}},
+										},
+										"required": []interface{}{"key", "operator"},
+									},
+								},
+								"timeRange": map[string]interface{}{
+									"type":        "object",
+									"description": "Default time range, e.g. {\"from\": \"now-1h\", \"to\": \"now\"}",
+								},
+								"table": map[string]interface{}{
+									"type":        "object",
+									"description": "Table layout: columns ({key, label, colSize}) and sort ({key, direction})",
+								},
+								"groupBy": map[string]interface{}{
+									"type":        "array",
+									"items":       map[string]interface{}{"type": "string"},
+									"description": "Attribute keys to group rows by",
+								},
+								"visualizations": map[string]interface{}{
+									"type":        "array",
+									"description": "Charts above the table, each {renderedAs, metric, groupBy}",
 								},
 							},
 							"required": []interface{}{"type"},
@@ -164,17 +243,19 @@ Another example:
 					},
 					"required": []interface{}{"kind", "metadata", "spec"},
 				},
-			},
-			Required: []string{"body"},
+			}),
 		},
 	}
 }
 
 // CreateViewHandler handles the dash0_views_create tool.
 func (p *Tools) CreateViewHandler(ctx context.Context, args map[string]interface{}) *client.ToolResult {
-	body, ok := args["body"]
-	if !ok {
-		return client.ErrorResult(400, "body is required")
+	body, errResult := schema.Body(args)
+	if errResult != nil {
+		return errResult
+	}
+	if result := schema.Check(schema.KindView, body); result != nil {
+		return result
 	}
 
 	return p.client.Post(ctx, basePath, body)
@@ -186,15 +267,17 @@ func (p *Tools) UpdateView() mcp.Tool {
 		Name:        "dash0_views_update",
 		Description: `Update an existing view by its origin or ID.
 
-The body should follow the same Dash0View CRD format as create:
+The body should follow the same Dash0View CRD format as create, including the optional
+display, query, filter, timeRange, table, groupBy, and visualizations fields. The whole
+view is replaced, so send every field to keep:
 {
   "kind": "Dash0View",
   "metadata": {"name": "updated-view"},
-  "spec": {"type": "resources"}
+  "spec": {"type": "logs", "filter": [{"key": "service.name", "operator": "is", "value": "api"}]}
 }`,
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
-			Properties: map[string]interface{}{
+			Properties: schema.WithBodyYAML(map[string]interface{}{
 				"origin_or_id": map[string]interface{}{
 					"type":        "string",
 					"description": "The origin or ID of the view to update.",
@@ -213,13 +296,13 @@ The body should follow the same Dash0View CRD format as create:
 						},
 						"spec": map[string]interface{}{
 							"type":        "object",
-							"description": "View specification with type",
+							"description": "View specification with type and the optional fields of dash0_views_create",
 						},
 					},
 					"required": []interface{}{"kind", "metadata", "spec"},
 				},
-			},
-			Required: []string{"origin_or_id", "body"},
+			}),
+			Required: []string{"origin_or_id"},
 		},
 	}
 }
@@ -231,9 +314,12 @@ func (p *Tools) UpdateViewHandler(ctx context.Context, args map[string]interface
 		return client.ErrorResult(400, "origin_or_id is required")
 	}
 
-	body, ok := args["body"]
-	if !ok {
-		return client.ErrorResult(400, "body is required")
+	body, errResult := schema.Body(args)
+	if errResult != nil {
+		return errResult
+	}
+	if result := schema.Check(schema.KindView, body); result != nil {
+		return result
 	}
 
 	path := fmt.Sprintf(basePath+"/%s", url.PathEscape(originOrID))
@@ -275,5 +361,6 @@ func Register(reg *registry.Registry, c *client.Client) {
 	for _, tool := range p.Tools() {
 		handler := p.Handlers()[tool.Name]
 		reg.Register(tool, handler)
+		reg.AddExamples(tool.Name, p.Examples()[tool.Name]...)
 	}
 }
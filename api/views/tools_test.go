@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/listing"
 )
 
 func TestNew(t *testing.T) {
@@ -120,6 +121,58 @@ func TestListViewsHandler(t *testing.T) {
 	if !result.Success {
 		t.Errorf("ListViewsHandler failed: %v", result.Error)
 	}
+	if page, ok := result.Data.(listing.Page); !ok || page.Count != 2 || page.NextPageToken != "" {
+		t.Errorf("ListViewsHandler data = %+v, want a page of 2 views", result.Data)
+	}
+}
+
+func TestListViewsHandler_PageToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("cursor") == "" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"items": []interface{}{map[string]interface{}{"name": "a"}}, "nextCursor": "p2"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": []interface{}{map[string]interface{}{"name": "b"}}})
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	first := pkg.ListViewsHandler(context.Background(), map[string]interface{}{})
+	page := first.Data.(listing.Page)
+	if page.NextPageToken != "p2" || !strings.Contains(first.Markdown, `page_token: "p2"`) {
+		t.Fatalf("first page = %+v, markdown:\n%s", page, first.Markdown)
+	}
+	second := pkg.ListViewsHandler(context.Background(), map[string]interface{}{"page_token": page.NextPageToken})
+	if page := second.Data.(listing.Page); page.Count != 1 || page.NextPageToken != "" {
+		t.Errorf("second page = %+v, want the last page", page)
+	}
+}
+
+func TestListViewsHandler_Summary(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]interface{}{{
+			"kind":     "Dash0View",
+			"metadata": map[string]interface{}{"name": "production-errors", "labels": map[string]interface{}{"dash0.com/id": "view-1"}},
+			"spec":     map[string]interface{}{"type": "spans", "query": "status = error"},
+		}})
+	}))
+	defer server.Close()
+
+	pkg := New(client.NewWithBaseURL(server.URL, "test-token"))
+	if _, ok := pkg.ListViews().InputSchema.Properties["summary"]; !ok {
+		t.Error("dash0_views_list should have a summary property")
+	}
+	result := pkg.ListViewsHandler(context.Background(), map[string]interface{}{"summary": true})
+	page, ok := result.Data.(listing.Page)
+	if !ok || page.Count != 1 {
+		t.Fatalf("data = %+v, want a page of 1 view", result.Data)
+	}
+	if s, ok := page.Items[0].(listing.Summary); !ok || s.ID != "view-1" || s.Name != "production-errors" || s.Type != "spans" {
+		t.Errorf("item = %+v, want the view's summary", page.Items[0])
+	}
+	if strings.Contains(result.Markdown, "status = error") || !strings.Contains(result.Markdown, "| view-1 | production-errors | spans |") {
+		t.Errorf("markdown should list only the summary:\n%s", result.Markdown)
+	}
 }
 
 func TestGetViewToolDefinition(t *testing.T) {
@@ -225,9 +278,12 @@ func TestCreateViewToolDefinition(t *testing.T) {
 		t.Error("CreateView() description should mention 'Dash0View'")
 	}
 
-	// Should require body
-	if len(tool.InputSchema.Required) != 1 || tool.InputSchema.Required[0] != "body" {
-		t.Error("CreateView() should require 'body'")
+	// Takes body or body_yaml, so neither is required
+	if len(tool.InputSchema.Required) != 0 {
+		t.Errorf("CreateView() Required = %v, expected none", tool.InputSchema.Required)
+	}
+	if _, ok := tool.InputSchema.Properties["body_yaml"]; !ok {
+		t.Error("CreateView() should accept 'body_yaml'")
 	}
 
 	// Body should have properties for kind, metadata, spec
@@ -276,6 +332,20 @@ func TestCreateViewHandler(t *testing.T) {
 			},
 			expectSuccess: true,
 		},
+		{
+			name: "valid body_yaml",
+			args: map[string]interface{}{
+				"body_yaml": "kind: Dash0View\nmetadata:\n  name: my-view\nspec:\n  type: logs\n",
+			},
+			expectSuccess: true,
+		},
+		{
+			name: "invalid body_yaml",
+			args: map[string]interface{}{
+				"body_yaml": "kind: Dash0View\nmetadata:\n  name: my: view\n",
+			},
+			expectError: "line 3",
+		},
 	}
 
 	for _, tt := range tests {
@@ -321,17 +391,12 @@ func TestUpdateViewToolDefinition(t *testing.T) {
 		t.Errorf("UpdateView() name = %s, expected dash0_views_update", tool.Name)
 	}
 
-	// Should require origin_or_id and body
-	if len(tool.InputSchema.Required) != 2 {
-		t.Error("UpdateView() should require 2 parameters")
-	}
-
-	required := make(map[string]bool)
-	for _, r := range tool.InputSchema.Required {
-		required[r] = true
+	// Should require origin_or_id; the body comes from body or body_yaml
+	if len(tool.InputSchema.Required) != 1 || tool.InputSchema.Required[0] != "origin_or_id" {
+		t.Errorf("UpdateView() Required = %v, expected [origin_or_id]", tool.InputSchema.Required)
 	}
-	if !required["origin_or_id"] || !required["body"] {
-		t.Error("UpdateView() should require origin_or_id and body")
+	if _, ok := tool.InputSchema.Properties["body_yaml"]; !ok {
+		t.Error("UpdateView() should accept 'body_yaml'")
 	}
 }
 
@@ -0,0 +1,364 @@
+// Command docsgen generates the tool reference from the tool registry: a
+// markdown page per tool with its arguments and examples, an index page,
+// and tools.json, a machine-readable manifest of every tool for teams that
+// keep agent prompts or internal docs in sync with the server.
+//
+// It is run by go generate in the api package:
+//
+//	go generate ./api
+//
+// With -check, it writes nothing and exits with status 1 when the files in
+// the output directory are missing or out of date, e.g. in CI.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/npcomplete777/dash0-mcp/api"
+	"github.com/npcomplete777/dash0-mcp/internal/audit"
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/config"
+	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+	"github.com/npcomplete777/dash0-mcp/internal/registry"
+	mcp "github.com/mark3labs/mcp-go/mcp"
+)
+
+// Manifest is the content of tools.json.
+type Manifest struct {
+	Tools []ToolDoc `json:"tools"`
+}
+
+// ToolDoc documents one tool.
+type ToolDoc struct {
+	Name string `json:"name"`
+	// Category is the group of the tool in tools.yaml, e.g. "dashboards".
+	Category    string `json:"category"`
+	Description string `json:"description"`
+	// Mutating tools write to Dash0 and accept dry_run.
+	Mutating bool `json:"mutating"`
+	// Dangerous is the dangerous flag of the tool in tools.yaml.
+	Dangerous   bool                `json:"dangerous"`
+	Arguments   []Argument          `json:"arguments"`
+	InputSchema mcp.ToolInputSchema `json:"input_schema"`
+	Examples    []registry.Example  `json:"examples"`
+}
+
+// Argument documents one property of a tool's input schema.
+type Argument struct {
+	Name        string        `json:"name"`
+	Type        string        `json:"type"`
+	Required    bool          `json:"required"`
+	Description string        `json:"description,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
+	Default     interface{}   `json:"default,omitempty"`
+}
+
+func main() {
+	configDir := flag.String("config", "config", "directory with tools.yaml")
+	outDir := flag.String("out", "docs", "directory to write the reference to")
+	check := flag.Bool("check", false, "report whether the reference is up to date instead of writing it")
+	flag.Parse()
+
+	tc, _, err := config.LoadToolsConfig(*configDir, "full")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "docsgen: %v\n", err)
+		os.Exit(1)
+	}
+	templates, err := config.LoadPanelTemplates(*configDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "docsgen: %v\n", err)
+		os.Exit(1)
+	}
+	// The handlers are never called, so the client needs no credentials
+	reg := registry.New(nil)
+	api.RegisterAllTools(reg, client.NewWithBaseURL("http://localhost", ""), templates, "")
+
+	files, err := generate(reg, tc)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "docsgen: %v\n", err)
+		os.Exit(1)
+	}
+	if *check {
+		stale := staleFiles(*outDir, files)
+		for _, name := range stale {
+			fmt.Fprintf(os.Stderr, "docsgen: %s is out of date\n", filepath.Join(*outDir, name))
+		}
+		if len(stale) > 0 {
+			fmt.Fprintln(os.Stderr, "docsgen: run go generate ./api")
+			os.Exit(1)
+		}
+		return
+	}
+	if err := writeFiles(*outDir, files); err != nil {
+		fmt.Fprintf(os.Stderr, "docsgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// generate returns the reference files by their path relative to the
+// output directory.
+func generate(reg *registry.Registry, tc *config.ToolsConfig) (map[string][]byte, error) {
+	categories := map[string]string{}
+	dangerous := map[string]bool{}
+	for category, tools := range tc.Tools {
+		for name, def := range tools {
+			categories[name] = category
+			dangerous[name] = def.Dangerous
+		}
+	}
+
+	manifest := Manifest{Tools: []ToolDoc{}}
+	for _, tool := range reg.GetEnabledTools() {
+		examples := reg.Examples(tool.Name)
+		if examples == nil {
+			examples = []registry.Example{}
+		}
+		manifest.Tools = append(manifest.Tools, ToolDoc{
+			Name:        tool.Name,
+			Category:    categories[tool.Name],
+			Description: tool.Description,
+			Mutating:    audit.Mutating(tool.Name),
+			Dangerous:   dangerous[tool.Name],
+			Arguments:   arguments(tool.InputSchema),
+			InputSchema: tool.InputSchema,
+			Examples:    examples,
+		})
+	}
+
+	files := map[string][]byte{}
+	data, err := indentJSON(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	files["tools.json"] = data
+
+	for _, doc := range manifest.Tools {
+		page, err := toolPage(doc)
+		if err != nil {
+			return nil, err
+		}
+		files[filepath.Join("tools", doc.Name+".md")] = page
+	}
+	files[filepath.Join("tools", "README.md")] = indexPage(manifest.Tools)
+	return files, nil
+}
+
+// arguments lists the properties of a schema, required ones first, each
+// group sorted by name.
+func arguments(schema mcp.ToolInputSchema) []Argument {
+	required := map[string]bool{}
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	args := []Argument{}
+	for name, prop := range schema.Properties {
+		def, _ := prop.(map[string]interface{})
+		arg := Argument{Name: name, Type: propertyType(def), Required: required[name], Default: def["default"]}
+		arg.Description, _ = def["description"].(string)
+		arg.Enum, _ = def["enum"].([]interface{})
+		if strs, ok := def["enum"].([]string); ok {
+			for _, s := range strs {
+				arg.Enum = append(arg.Enum, s)
+			}
+		}
+		args = append(args, arg)
+	}
+	sort.Slice(args, func(i, j int) bool {
+		if args[i].Required != args[j].Required {
+			return args[i].Required
+		}
+		return args[i].Name < args[j].Name
+	})
+	return args
+}
+
+// propertyType returns the JSON Schema type of a property, with the item
+// type of an array, e.g. "array of string".
+func propertyType(def map[string]interface{}) string {
+	typ, _ := def["type"].(string)
+	if typ == "" {
+		return "any"
+	}
+	if items, ok := def["items"].(map[string]interface{}); ok && typ == "array" {
+		if itemType, ok := items["type"].(string); ok {
+			return "array of " + itemType
+		}
+	}
+	return typ
+}
+
+// toolPage renders the reference page of a tool.
+func toolPage(doc ToolDoc) ([]byte, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", doc.Name)
+	fmt.Fprintf(&b, "%s\n\n", traits(doc))
+	fmt.Fprintf(&b, "%s\n\n", strings.TrimSpace(doc.Description))
+
+	rows := make([][]string, len(doc.Arguments))
+	for i, arg := range doc.Arguments {
+		required := "no"
+		if arg.Required {
+			required = "yes"
+		}
+		rows[i] = []string{"`" + arg.Name + "`", arg.Type, required, argumentDescription(arg)}
+	}
+	b.WriteString(formatter.Table("Arguments", "", []string{"Name", "Type", "Required", "Description"}, rows, ""))
+
+	if len(doc.Examples) > 0 {
+		b.WriteString("\n## Examples\n")
+		for _, ex := range doc.Examples {
+			args, err := indentJSON(ex.Arguments)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode example %q of %s: %w", ex.Title, doc.Name, err)
+			}
+			fmt.Fprintf(&b, "\n### %s\n\n```json\n%s```\n", ex.Title, args)
+		}
+	}
+	return []byte(b.String()), nil
+}
+
+// traits summarizes the category and the effects of a tool.
+func traits(doc ToolDoc) string {
+	var parts []string
+	if doc.Category != "" {
+		parts = append(parts, "Category: `"+doc.Category+"`")
+	}
+	switch {
+	case doc.Dangerous && !doc.Mutating:
+		parts = append(parts, "writes local files (dangerous)")
+	case doc.Dangerous:
+		parts = append(parts, "writes to Dash0 (dangerous)")
+	case doc.Mutating:
+		parts = append(parts, "writes to Dash0")
+	default:
+		parts = append(parts, "read-only")
+	}
+	return strings.Join(parts, " · ")
+}
+
+// argumentDescription returns the description of an argument on one line,
+// with its allowed values and default.
+func argumentDescription(arg Argument) string {
+	desc := strings.Join(strings.Fields(arg.Description), " ")
+	if len(arg.Enum) > 0 {
+		values := make([]string, len(arg.Enum))
+		for i, v := range arg.Enum {
+			values[i] = fmt.Sprintf("`%v`", v)
+		}
+		desc += " One of: " + strings.Join(values, ", ") + "."
+	}
+	if arg.Default != nil {
+		desc += fmt.Sprintf(" Default: `%v`.", arg.Default)
+	}
+	return strings.TrimSpace(desc)
+}
+
+// indexPage renders the list of all tools by category.
+func indexPage(tools []ToolDoc) []byte {
+	byCategory := map[string][]ToolDoc{}
+	var categories []string
+	for _, doc := range tools {
+		if _, ok := byCategory[doc.Category]; !ok {
+			categories = append(categories, doc.Category)
+		}
+		byCategory[doc.Category] = append(byCategory[doc.Category], doc)
+	}
+	sort.Strings(categories)
+
+	var b strings.Builder
+	b.WriteString("# Tool Reference\n\n")
+	b.WriteString("Generated by `go generate ./api` from the tool registry; do not edit. ")
+	b.WriteString("`../tools.json` has the same content as a JSON manifest.\n")
+	for _, category := range categories {
+		title := category
+		if title == "" {
+			title = "other"
+		}
+		rows := make([][]string, 0, len(byCategory[category]))
+		for _, doc := range byCategory[category] {
+			rows = append(rows, []string{fmt.Sprintf("[`%s`](%s.md)", doc.Name, doc.Name), firstLine(doc.Description)})
+		}
+		b.WriteString("\n")
+		b.WriteString(formatter.Table(title, "", []string{"Tool", "Description"}, rows, ""))
+	}
+	return []byte(b.String())
+}
+
+// indentJSON encodes v as indented JSON without HTML escaping, so queries
+// such as rate(x[5m]) > 0 read as written.
+func indentJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// firstLine returns the first line of a description.
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return s
+}
+
+// writeFiles writes the reference files to dir and removes the pages of
+// tools that no longer exist.
+func writeFiles(dir string, files map[string][]byte) error {
+	for _, name := range orphans(dir, files) {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// staleFiles returns the sorted names of the files that are missing in dir,
+// differ from the generated ones, or document tools that no longer exist.
+func staleFiles(dir string, files map[string][]byte) []string {
+	stale := orphans(dir, files)
+	for name, data := range files {
+		existing, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || !bytes.Equal(existing, data) {
+			stale = append(stale, name)
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// orphans returns the tool pages in dir that are not among files.
+func orphans(dir string, files map[string][]byte) []string {
+	pages, _ := filepath.Glob(filepath.Join(dir, "tools", "*.md"))
+	var names []string
+	for _, page := range pages {
+		name, err := filepath.Rel(dir, page)
+		if err != nil {
+			continue
+		}
+		if _, ok := files[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
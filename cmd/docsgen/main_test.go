@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/npcomplete777/dash0-mcp/api"
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/config"
+	"github.com/npcomplete777/dash0-mcp/internal/registry"
+	mcp "github.com/mark3labs/mcp-go/mcp"
+)
+
+func testRegistry() *registry.Registry {
+	reg := registry.New(nil)
+	noop := func(context.Context, map[string]interface{}) *client.ToolResult { return nil }
+	reg.Register(mcp.Tool{
+		Name:        "dash0_widgets_list",
+		Description: "List widgets.\n\nReturns | separated names.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"state": map[string]interface{}{"type": "string", "description": "Filter by state.", "enum": []string{"on", "off"}, "default": "on"},
+				"tags":  map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+				"name":  map[string]interface{}{"type": "string", "description": "Widget name."},
+			},
+			Required: []string{"name"},
+		},
+	}, noop)
+	reg.AddExamples("dash0_widgets_list", registry.Example{Title: "Busy widgets", Arguments: map[string]interface{}{"name": "rate > 0"}})
+	reg.Register(mcp.Tool{Name: "dash0_widgets_delete", Description: "Delete a widget."}, noop)
+	return reg
+}
+
+func TestGenerate(t *testing.T) {
+	tc := &config.ToolsConfig{Tools: map[string]map[string]config.ToolDef{
+		"widgets": {"dash0_widgets_list": {}, "dash0_widgets_delete": {Dangerous: true}},
+	}}
+	files, err := generate(testRegistry(), tc)
+	if err != nil {
+		t.Fatalf("generate() error = %v", err)
+	}
+	if len(files) != 4 {
+		t.Errorf("generated %d files, want tools.json, README.md, and 2 pages", len(files))
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(files["tools.json"], &manifest); err != nil {
+		t.Fatalf("tools.json: %v", err)
+	}
+	if len(manifest.Tools) != 2 || manifest.Tools[1].Name != "dash0_widgets_list" {
+		t.Fatalf("manifest tools = %+v, want the 2 tools sorted by name", manifest.Tools)
+	}
+	del, list := manifest.Tools[0], manifest.Tools[1]
+	if !del.Mutating || !del.Dangerous || list.Mutating || list.Category != "widgets" {
+		t.Errorf("traits: delete = %+v, list = %+v", del, list)
+	}
+	// name is required; dataset, bypass_cache, timeout_seconds, and output_format come from the registry
+	if list.Arguments[0].Name != "name" || !list.Arguments[0].Required || len(list.Arguments) != 7 {
+		t.Errorf("arguments = %+v, want name first of 7", list.Arguments)
+	}
+	if !strings.Contains(string(files["tools.json"]), `"rate > 0"`) {
+		t.Error("tools.json escapes HTML in examples")
+	}
+
+	page := string(files[filepath.Join("tools", "dash0_widgets_list.md")])
+	for _, s := range []string{
+		"# dash0_widgets_list",
+		"Category: `widgets` · read-only",
+		"| `name` | string | yes | Widget name. |",
+		"| `state` | string | no | Filter by state. One of: `on`, `off`. Default: `on`. |",
+		"| `tags` | array of string | no |  |",
+		"### Busy widgets",
+		`"name": "rate > 0"`,
+	} {
+		if !strings.Contains(page, s) {
+			t.Errorf("page missing %q:\n%s", s, page)
+		}
+	}
+	if !strings.Contains(string(files[filepath.Join("tools", "dash0_widgets_delete.md")]), "writes to Dash0 (dangerous)") {
+		t.Error("delete page does not say it is dangerous")
+	}
+	index := string(files[filepath.Join("tools", "README.md")])
+	if !strings.Contains(index, "| [`dash0_widgets_list`](dash0_widgets_list.md) | List widgets. |") {
+		t.Errorf("index:\n%s", index)
+	}
+}
+
+func TestWriteFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]byte{"tools.json": []byte("{}\n"), filepath.Join("tools", "a.md"): []byte("# a\n")}
+	if stale := staleFiles(dir, files); len(stale) != 2 {
+		t.Errorf("staleFiles() before writing = %v, want both", stale)
+	}
+	os.MkdirAll(filepath.Join(dir, "tools"), 0o755)
+	os.WriteFile(filepath.Join(dir, "tools", "removed.md"), []byte("# removed\n"), 0o644)
+
+	if err := writeFiles(dir, files); err != nil {
+		t.Fatalf("writeFiles() error = %v", err)
+	}
+	if stale := staleFiles(dir, files); len(stale) != 0 {
+		t.Errorf("staleFiles() after writing = %v, want none", stale)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tools", "removed.md")); !os.IsNotExist(err) {
+		t.Error("the page of a removed tool was kept")
+	}
+}
+
+// TestReferenceUpToDate fails when a tool changed without regenerating the
+// reference in docs/.
+func TestReferenceUpToDate(t *testing.T) {
+	tc, _, err := config.LoadToolsConfig("../../config", "full")
+	if err != nil {
+		t.Fatalf("LoadToolsConfig() error = %v", err)
+	}
+	reg := registry.New(nil)
+	api.RegisterAllTools(reg, client.NewWithBaseURL("http://localhost", ""), nil, "")
+	files, err := generate(reg, tc)
+	if err != nil {
+		t.Fatalf("generate() error = %v", err)
+	}
+	if stale := staleFiles("../../docs", files); len(stale) > 0 {
+		t.Errorf("docs/ is out of date (%s); run go generate ./api", strings.Join(stale, ", "))
+	}
+}
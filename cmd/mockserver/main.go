@@ -0,0 +1,74 @@
+// Command mockserver serves a fake Dash0 API with in-memory state and demo
+// data, so the MCP server can be run end to end without a Dash0 account:
+//
+//	go run ./cmd/mockserver -addr 127.0.0.1:8090
+//	DASH0_BASE_URL=http://127.0.0.1:8090 DASH0_AUTH_TOKEN=mock go run ./cmd/server
+//
+// Any bearer token is accepted. The state is lost when the server stops.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/internal/mockapi"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8090", "address to listen on")
+	flag.Parse()
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	slog.SetDefault(logger)
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           logRequests(mockapi.New()),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("mock Dash0 API listening", "addr", *addr,
+		"DASH0_BASE_URL", "http://"+*addr, "DASH0_AUTH_TOKEN", "any value, e.g. mock")
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("mock server failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// logRequests logs each request with its status.
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(rec, r)
+		slog.Info("request", "method", r.Method, "path", r.URL.Path, "status", rec.status,
+			"duration", time.Since(start).Round(time.Microsecond))
+	})
+}
+
+// statusRecorder remembers the status code written to a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
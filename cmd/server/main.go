@@ -2,33 +2,50 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/npcomplete777/dash0-mcp/api"
+	"github.com/npcomplete777/dash0-mcp/internal/audit"
 	"github.com/npcomplete777/dash0-mcp/internal/client"
 	"github.com/npcomplete777/dash0-mcp/internal/config"
+	"github.com/npcomplete777/dash0-mcp/internal/mcpresources"
+	"github.com/npcomplete777/dash0-mcp/internal/progress"
+	"github.com/npcomplete777/dash0-mcp/internal/prompts"
 	"github.com/npcomplete777/dash0-mcp/internal/registry"
+	"github.com/npcomplete777/dash0-mcp/internal/selftel"
+	"github.com/npcomplete777/dash0-mcp/internal/tokencheck"
+	"github.com/npcomplete777/dash0-mcp/internal/transport"
+	"github.com/npcomplete777/dash0-mcp/internal/version"
+	"github.com/npcomplete777/dash0-mcp/internal/webhook"
 	mcp "github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
 const (
 	serverName    = "Dash0 MCP Server"
-	serverVersion = "1.0.0"
+	serverVersion = version.Version
+	// startupCheckTimeout bounds the auth token check at startup.
+	startupCheckTimeout = 15 * time.Second
 )
 
 func main() {
 	// Set up structured logging
-	level := slog.LevelInfo
+	level := new(slog.LevelVar)
 	if debug := os.Getenv("DASH0_DEBUG"); debug == "true" || debug == "1" || debug == "yes" {
-		level = slog.LevelDebug
+		level.Set(slog.LevelDebug)
 	}
 	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
 	slog.SetDefault(logger)
@@ -39,6 +56,10 @@ func main() {
 		slog.Error("configuration error", "error", err)
 		os.Exit(1)
 	}
+	if cfg.Debug {
+		// The config file may enable debug logging too
+		level.Set(slog.LevelDebug)
+	}
 
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
@@ -47,18 +68,57 @@ func main() {
 			"DASH0_AUTH_TOKEN", "Bearer token for API authentication",
 		)
 		slog.Info("optional environment variables",
+			"DASH0_AUTH_TOKEN_FILE", "File to read the auth token from instead, read again on a 401 response or SIGHUP",
 			"DASH0_REGION", "Region (us-west-2, us-east-1, eu-west-1), default: us-west-2",
 			"DASH0_BASE_URL", "Custom base URL (overrides region)",
+			"DASH0_INGRESS_URL", "OTLP ingestion endpoint for the send tools, default: the API",
+			"DASH0_INGRESS_TOKEN", "Token for DASH0_INGRESS_URL, default: DASH0_AUTH_TOKEN",
 			"DASH0_DATASET", "Dataset to use for all API calls",
 			"DASH0_DEBUG", "Enable debug logging (true/false)",
+			"DASH0_MAX_RESPONSE_BYTES", "Default size cap for query tool responses (0 = unlimited)",
+			"DASH0_MAX_ITEMS", "Default record cap for query tool responses (0 = unlimited)",
+			"DASH0_HTTP_TIMEOUT", "HTTP timeout per API request (e.g. 30s; DASH0_TIMEOUT also works), default: 60s",
+			"DASH0_MAX_RETRIES", "Retries on 429/503 responses, default: 3",
+			"DASH0_MAX_CONCURRENCY", "Parallel API requests per tool call, default: 4",
+			"DASH0_TOOL_TIMEOUT", "Hard limit on a single tool call (e.g. 2m), default: 5m",
+			"DASH0_FORCE_IPV4", "Only connect to the API over IPv4 (true/false)",
+			"DASH0_DNS_SERVER", "DNS server (host or host:port) used instead of the system resolver",
+			"DASH0_HEDGE_DELAY", "Hedge slow read-only requests after a duration (e.g. 500ms) or auto (recent p95), default: off",
+			"DASH0_RATE_LIMIT", "Client-side cap on API requests per second, default: unlimited",
+			"DASH0_RATE_BURST", "Requests sent at once under the rate limit, default: one second's worth",
+			"DASH0_CACHE_TTL", "How long GET responses are cached (e.g. 1m, off), default: 30s",
+			"DASH0_CALL_BUDGET", "Soft limit on API requests per session; exceeding it logs a warning, default: none",
+			"DASH0_SELF_TELEMETRY", "Send spans for this server's tool calls and API requests to Dash0 (true/false)",
+			"DASH0_SELF_TELEMETRY_INTERVAL", "How often self-telemetry spans are sent (e.g. 1m), default: 30s",
+			"DASH0_MCP_STATS_INTERVAL", "Log the calls, errors, and latency of each tool to stderr this often (e.g. 10m), default: off",
 			"DASH0_MCP_PROFILE", "Tool profile (full, demo, readonly, minimal)",
 			"DASH0_MCP_CONFIG_DIR", "Path to config directory",
+			"DASH0_MCP_AUDIT_LOG", "JSONL file (or stderr) recording every create, update, delete, import, and send, default: off",
+			"DASH0_MCP_EXPORT_DIR", "Directory dash0_export_table and dash0_export_all may write files to, default: writing files disabled",
+			"DASH0_MCP_TRACE_FILE", "JSONL file (or stderr) recording every API request and response, redacted, default: off",
+			"DASH0_MCP_FIXTURES", "Directory of recorded API responses, served instead of calling the API, default: off",
+			"DASH0_MCP_FIXTURE_MODE", "replay (default) the fixtures, or record the API's responses to them",
+			"DASH0_MCP_DRY_RUN", "Report the request of every create, update, delete, and send instead of sending it (true/false)",
+			"DASH0_MCP_READ_ONLY", "Reject every create, update, delete, and send in the API client (true/false)",
+			"DASH0_MCP_CONFIRM_DELETES", "Deletes return a confirmation token first and only run when called again with it (true/false)",
+			"DASH0_MCP_STARTUP_CHECK", "Validate the auth token at startup (true/false), default: true",
+			"DASH0_MCP_DISABLE_DENIED_WRITES", "Probe write access at startup and disable the create, update, delete, and send tools when the token may only read (true/false)",
+			"DASH0_MCP_HTTP_ADDR", "Serve MCP over HTTP (SSE) on this address, e.g. 127.0.0.1:8080, instead of stdio",
+			"DASH0_MCP_HTTP_TOKEN", "Bearer token the HTTP transport requires, default: none",
+			"DASH0_WEBHOOK_TOKEN", "Accept Dash0 alert webhooks on /webhooks/dash0 with this token and forward them to the sessions",
+			"DASH0_WEBHOOK_LABELS", "Only forward alerts with these labels (e.g. severity=critical|warning,team=checkout)",
+			"DASH0_FAULT_INJECTION", "Probability (0-1) that an API request fails with a simulated 429, 500, or timeout, for testing, default: off",
+			"DASH0_CONFIG_FILE", "Config file path, default: ~/.dash0-mcp/config.yaml",
 		)
 		os.Exit(1)
 	}
 
+	if cfg.ConfigFile != "" {
+		slog.Info("loaded config file", "path", cfg.ConfigFile)
+	}
+
 	// Determine config directory for tools
-	configDir := os.Getenv("DASH0_MCP_CONFIG_DIR")
+	configDir := cfg.ConfigDir
 	if configDir == "" {
 		// Try relative to executable
 		if exe, err := os.Executable(); err == nil {
@@ -71,7 +131,7 @@ func main() {
 	}
 
 	// Load tools config and profile
-	profileName := os.Getenv("DASH0_MCP_PROFILE")
+	profileName := cfg.Profile
 	var toolsConfig *config.ToolsConfig
 	var profile *config.Profile
 	var enabledTools map[string]bool
@@ -87,14 +147,108 @@ func main() {
 		}
 	}
 
+	// A read-only profile makes the client reject writes as well
+	if profile != nil && profile.ReadOnly {
+		cfg.ReadOnly = true
+	}
+
 	// Create API client
 	c := client.New(cfg)
+	if profile != nil && len(profile.Scope) > 0 {
+		c.SetQueryScope(profile.Scope)
+		slog.Info("telemetry queries scoped by profile", "profile", profile.Name, "scope", profile.Scope)
+	}
+
+	// Count our own tool calls and API requests, and with self-telemetry
+	// record them as spans too. Spans are sent with a separate client so
+	// that exporting is neither traced itself nor counted in the session's
+	// usage.
+	var send selftel.Sender
+	if cfg.SelfTelemetry {
+		exportCfg := *cfg
+		exportCfg.Targets, exportCfg.CacheTTL, exportCfg.Hedge, exportCfg.CallBudget = nil, 0, false, 0
+		exportCfg.DryRun, exportCfg.ReadOnly, exportCfg.FaultInjection = false, false, 0
+		exportCfg.Fixtures, exportCfg.FixtureMode = "", ""
+		exporter := client.New(&exportCfg)
+		send = func(ctx context.Context, body interface{}) error {
+			if result := exporter.Ingest(ctx, client.SignalSpans, body); !result.Success {
+				return errors.New(result.Error.Message())
+			}
+			return nil
+		}
+	}
+	telemetry := selftel.New(serverVersion, send)
+	c.SetTelemetry(telemetry)
+
+	// Open the audit log of mutating tool calls
+	auditLog, err := audit.Open(cfg.AuditLog)
+	if err != nil {
+		slog.Error("configuration error", "error", err)
+		os.Exit(1)
+	}
+	defer auditLog.Close()
 
-	// Create registry with enabled tools filter
+	// Record every API request and response, with the correlation IDs that
+	// failed tool calls report
+	trace, err := client.OpenTrace(cfg.TraceFile)
+	if err != nil {
+		slog.Error("configuration error", "error", err)
+		os.Exit(1)
+	}
+	defer trace.Close()
+	c.SetTrace(trace)
+
+	// Check the token now rather than with the first failed tool call.
+	// Replayed fixtures have no API to check it against.
+	writesDenied := false
+	if cfg.StartupCheck && cfg.FixtureMode != config.FixtureReplay {
+		writesDenied = checkToken(c, cfg)
+	}
+	hideWrites := writesDenied && cfg.DisableDeniedWrites
+
+	// Create registry with enabled tools filter. Every handler is audited
+	// and aborted after the tool timeout.
 	reg := registry.New(enabledTools)
+	if trace != nil {
+		reg.Use(withCorrelationID)
+	}
+	reg.Use(withAudit(auditLog), withToolTimeout(cfg.ToolTimeout))
+
+	// Load the panel templates of dash0_dashboards_add_panel
+	panelTemplates, err := config.LoadPanelTemplates(configDir)
+	if err != nil {
+		slog.Warn("could not load panel templates", "config_dir", configDir, "error", err)
+	}
 
 	// Register ALL tool handlers (registry filters by enabled)
-	api.RegisterAllTools(reg, c)
+	api.RegisterAllTools(reg, c, panelTemplates, cfg.ExportDir)
+
+	// A token that may only read gets 403 from every write
+	if hideWrites {
+		reg.SetEnabled(withoutWrites(reg, enabledTools))
+		slog.Warn("auth token may only read; create, update, delete, and send tools are disabled", "tools_enabled", reg.EnabledCount())
+	}
+
+	// The query scope only applies to span and log queries, so a scoped
+	// profile leaves only the tools that read nothing else
+	scoped := len(c.QueryScope()) > 0
+	if scoped {
+		enabled := enabledTools
+		if hideWrites {
+			enabled = withoutWrites(reg, enabled)
+		}
+		reg.SetEnabled(withoutUnscoped(reg, enabled))
+		slog.Info("tools that read data the query scope does not apply to are disabled", "profile", profile.Name, "tools_enabled", reg.EnabledCount())
+	}
+
+	// Fill in the profile's argument defaults when a caller omits them
+	if profile != nil && len(profile.Defaults) > 0 {
+		if err := reg.SetDefaults(profile.Defaults); err != nil {
+			slog.Error("configuration error", "profile", profile.Name, "error", err)
+			os.Exit(1)
+		}
+		slog.Info("argument defaults set by profile", "profile", profile.Name, "defaults", profile.Defaults)
+	}
 
 	// Log enabled tools if configured
 	if toolsConfig != nil && toolsConfig.Settings.LogEnabledTools {
@@ -103,53 +257,88 @@ func main() {
 		}
 	}
 
+	// Forward alerts posted to the webhook to the connected sessions
+	hooks := &server.Hooks{}
+	var receiver *webhook.Receiver
+	if cfg.WebhookToken != "" {
+		filter, err := webhook.ParseFilter(cfg.WebhookLabels)
+		if err != nil {
+			slog.Error("configuration error", "error", fmt.Errorf("DASH0_WEBHOOK_LABELS: %w", err))
+			os.Exit(1)
+		}
+		receiver = webhook.New(cfg.WebhookToken, filter)
+		receiver.AddHooks(hooks)
+	}
+
 	// Create MCP server
 	s := server.NewMCPServer(
 		serverName,
 		serverVersion,
 		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(false, true),
+		server.WithPromptCapabilities(false),
 		server.WithLogging(),
+		server.WithElicitation(),
+		server.WithHooks(hooks),
 	)
 
-	// Register enabled tools with MCP
-	for _, tool := range reg.GetEnabledTools() {
-		t := tool // Capture loop variable
-		handler := reg.GetHandler(t.Name)
-		if handler == nil {
-			slog.Warn("no handler for tool", "tool", t.Name)
-			continue
-		}
-
-		s.AddTool(t, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			// Extract arguments
-			var args map[string]interface{}
-			if req.Params.Arguments != nil {
-				args = req.Params.Arguments
-			} else {
-				args = make(map[string]interface{})
+	// Register enabled tools with MCP. serverTools is evaluated again when
+	// the tools config changes.
+	serverTools := func() []server.ServerTool {
+		var tools []server.ServerTool
+		for _, tool := range reg.GetEnabledTools() {
+			t := tool // Capture loop variable
+			handler := reg.GetHandler(t.Name)
+			if handler == nil {
+				slog.Warn("no handler for tool", "tool", t.Name)
+				continue
+			}
+			if schema := reg.OutputSchema(t.Name); schema != nil {
+				t.RawOutputSchema, _ = json.Marshal(schema)
 			}
 
-			// Execute handler
-			result := handler(ctx, args)
+			tools = append(tools, server.ServerTool{Tool: t, Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				// Extract arguments
+				args := req.GetArguments()
+				if args == nil {
+					args = make(map[string]interface{})
+				}
 
-			// Convert result to MCP format
-			if result.Error != nil {
-				return mcp.NewToolResultError(result.Error.Detail), nil
-			}
+				// Report progress of long calls when the client asked for it
+				if req.Params.Meta != nil && req.Params.Meta.ProgressToken != nil {
+					ctx = progress.WithReporter(ctx, progressReporter(ctx, s, req.Params.Meta.ProgressToken))
+				}
 
-			// Use pre-formatted markdown if available, otherwise JSON
-			if result.Markdown != "" {
-				return mcp.NewToolResultText(result.Markdown), nil
-			}
+				ctx, call := telemetry.StartTool(ctx, t.Name)
+				result := handler(ctx, args)
+				res := toolResult(result)
+				call.End(errorCode(result, res), textSize(res))
+				return res, nil
+			}})
+		}
+		return tools
+	}
+	s.AddTools(serverTools()...)
+	// SetTools sends notifications/tools/list_changed to the clients
+	reg.OnChange(func() { s.SetTools(serverTools()...) })
 
-			// Marshal data to JSON
-			data, err := json.Marshal(result.Data)
-			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
-			}
+	// Expose dashboards, views, check rules, and synthetic checks as
+	// resources while the tools that read them are enabled
+	mcpresources.Register(s, c, reg.IsEnabled)
+	reg.OnChange(func() { mcpresources.Register(s, c, reg.IsEnabled) })
 
-			return mcp.NewToolResultText(string(data)), nil
-		})
+	// Register workflow prompts whose tools are enabled
+	promptDefs, err := config.LoadPrompts(configDir)
+	if err == nil {
+		var parsed []prompts.Prompt
+		if parsed, err = prompts.Parse(promptDefs); err == nil {
+			for _, name := range prompts.Register(s, parsed, reg.IsEnabled) {
+				slog.Debug("prompt registered", "name", name)
+			}
+		}
+	}
+	if err != nil {
+		slog.Warn("could not load prompts", "config_dir", configDir, "error", err)
 	}
 
 	// Log startup information
@@ -160,13 +349,37 @@ func main() {
 		"tools_enabled", reg.EnabledCount(),
 		"tools_total", reg.ToolCount(),
 	}
+	if cfg.AuthTokenFile != "" {
+		attrs = append(attrs, "auth_token_file", cfg.AuthTokenFile)
+	}
+	if cfg.IngressURL != "" {
+		attrs = append(attrs, "ingress_url", cfg.IngressURL)
+	}
 	if cfg.Dataset != "" {
 		attrs = append(attrs, "dataset", cfg.Dataset)
 	}
 	if cfg.Debug {
 		attrs = append(attrs, "debug", true)
 	}
+	if cfg.DryRun {
+		attrs = append(attrs, "dry_run", true)
+	}
+	if cfg.ReadOnly {
+		attrs = append(attrs, "read_only", true)
+	}
+	if cfg.ConfirmDeletes {
+		attrs = append(attrs, "confirm_deletes", true)
+	}
+	if hideWrites {
+		attrs = append(attrs, "write_tools_disabled", true)
+	}
+	if cfg.Fixtures != "" {
+		attrs = append(attrs, "fixtures", cfg.Fixtures, "fixture_mode", cfg.FixtureMode)
+	}
 	slog.Info(serverName+" starting", attrs...)
+	if cfg.FaultInjection > 0 {
+		slog.Warn("fault injection enabled; API requests fail at random", "probability", cfg.FaultInjection)
+	}
 
 	// Set up graceful shutdown
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
@@ -177,9 +390,348 @@ func main() {
 		slog.Info("shutdown signal received")
 	}()
 
-	// Start the server
-	if err := server.ServeStdio(s); err != nil {
+	// Read the token file again on SIGHUP; a 401 response rereads it too
+	if cfg.AuthTokenFile != "" {
+		go reloadTokenOnHangup(ctx, c, cfg.AuthTokenFile)
+	}
+
+	if cfg.SelfTelemetry {
+		go telemetry.Run(ctx, cfg.SelfTelemetryInterval)
+		slog.Info("self-telemetry enabled", "service", selftel.ServiceName, "interval", cfg.SelfTelemetryInterval)
+	}
+	if cfg.StatsInterval > 0 {
+		go telemetry.RunSummary(ctx, cfg.StatsInterval, slog.Default())
+	}
+
+	// Re-evaluate the enabled tools when tools.yaml or the profile changes.
+	// Profile settings that need a restart are warned about once per edit:
+	// when they differ from both the running and the last loaded profile.
+	lastProfile := profile
+	reload := func() {
+		tc, p, err := config.LoadToolsConfig(configDir, profileName)
+		if err != nil {
+			slog.Warn("could not reload tools config, keeping the enabled tools", "config_dir", configDir, "error", err)
+			return
+		}
+		if profileSettingsChanged(profile, p) && profileSettingsChanged(lastProfile, p) {
+			slog.Warn("profile read_only, scope, and defaults only change on restart", "config_dir", configDir)
+		}
+		lastProfile = p
+		enabled := config.GetEnabledTools(tc, p)
+		if hideWrites {
+			enabled = withoutWrites(reg, enabled)
+		}
+		if scoped {
+			enabled = withoutUnscoped(reg, enabled)
+		}
+		if reg.SetEnabled(enabled) {
+			slog.Info("tools config reloaded", "tools_enabled", reg.EnabledCount())
+		}
+	}
+	if err := config.WatchTools(ctx, configDir, reload); err != nil {
+		slog.Warn("tools config changes need a restart", "error", err)
+	}
+
+	// Start the server; the stdio transport cancels in-flight tool calls on
+	// notifications/cancelled and on shutdown
+	if cfg.HTTPAddr != "" {
+		httpTransport := transport.NewHTTP(s, cfg.HTTPAddr, cfg.HTTPToken)
+		if receiver != nil {
+			httpTransport.Handle(webhook.Path, receiver)
+			httpTransport.Use(receiver.SessionFilter)
+			slog.Info("alert webhook enabled", "path", webhook.Path, "labels", cfg.WebhookLabels)
+		}
+		if cfg.HTTPToken == "" {
+			slog.Warn("DASH0_MCP_HTTP_TOKEN is not set; every local process can call tools", "addr", cfg.HTTPAddr)
+		}
+		slog.Info("serving MCP over HTTP", "addr", cfg.HTTPAddr, "sse", "/sse")
+		err = httpTransport.Listen(ctx)
+	} else {
+		stdio := transport.NewStdio(s)
+		err = stdio.Listen(ctx, os.Stdin, os.Stdout)
+	}
+
+	// Send the spans recorded since the last flush
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if flushErr := telemetry.Flush(flushCtx); flushErr != nil {
+		slog.Warn("could not send self-telemetry", "error", flushErr)
+	}
+	cancel()
+
+	if err != nil {
 		slog.Error("server error", "error", err)
 		os.Exit(1)
 	}
 }
+
+// toolResult converts a tool result to MCP format. Errors are structured
+// JSON with a machine-readable code. Successful results carry their data as
+// structured content, and as text for clients without structured content
+// support: the tool's Markdown when it has some, the data as JSON otherwise.
+// Data that is not a JSON object is structured as {"result": data}.
+func toolResult(result *client.ToolResult) *mcp.CallToolResult {
+	if result.Error != nil {
+		return mcp.NewToolResultError(result.Error.JSON())
+	}
+	data, err := json.Marshal(result.Data)
+	if err != nil {
+		if result.Markdown != "" {
+			return mcp.NewToolResultText(result.Markdown)
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err))
+	}
+	res := mcp.NewToolResultText(string(data))
+	if result.Markdown != "" {
+		res = mcp.NewToolResultText(result.Markdown)
+	}
+	// Decode numbers as json.Number so large integers keep their precision
+	var structured interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&structured); err == nil && structured != nil {
+		if _, ok := structured.(map[string]interface{}); !ok {
+			structured = map[string]interface{}{"result": structured}
+		}
+		res.StructuredContent = structured
+	}
+	return res
+}
+
+// errorCode returns the machine-readable error code of a tool result and
+// its MCP form, or "" if the call succeeded.
+func errorCode(result *client.ToolResult, res *mcp.CallToolResult) string {
+	if result.Error != nil {
+		return result.Error.Code
+	}
+	if res.IsError {
+		return "internal_error"
+	}
+	return ""
+}
+
+// withToolTimeout runs handlers with a hard deadline; cancellation from the
+// client or the deadline aborts their upstream requests.
+func withToolTimeout(timeout time.Duration) registry.Middleware {
+	return func(tool mcp.Tool, next registry.Handler) registry.Handler {
+		return func(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			result := next(ctx, args)
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return client.ErrorResult(http.StatusGatewayTimeout, fmt.Sprintf("%s timed out after %s", tool.Name, timeout))
+			}
+			return result
+		}
+	}
+}
+
+// withCorrelationID gives the API requests of each call one correlation
+// ID, which the trace file records and a failed call reports.
+func withCorrelationID(tool mcp.Tool, next registry.Handler) registry.Handler {
+	return func(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+		id := client.NewCorrelationID()
+		result := next(client.WithCorrelationID(ctx, id), args)
+		if result != nil && result.Error != nil {
+			result.Error.CorrelationID = id
+		}
+		return result
+	}
+}
+
+// withAudit writes the calls of mutating tools to the audit log. A nil log
+// leaves the handlers unchanged.
+func withAudit(log *audit.Logger) registry.Middleware {
+	return func(tool mcp.Tool, next registry.Handler) registry.Handler {
+		if log == nil || !audit.Mutating(tool.Name) {
+			return next
+		}
+		return func(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+			start := time.Now()
+			result := next(ctx, args)
+			if err := log.Log(auditEntry(ctx, tool.Name, args, start, result)); err != nil {
+				slog.Error("could not write audit log", "tool", tool.Name, "error", err)
+			}
+			return result
+		}
+	}
+}
+
+// checkToken validates the auth token against the API and logs the result.
+// With DASH0_MCP_DISABLE_DENIED_WRITES it also probes whether the token may
+// write, and reports whether it may only read. A rejected token is
+// logged, not fatal, so the server still starts.
+func checkToken(c *client.Client, cfg *config.Config) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), startupCheckTimeout)
+	defer cancel()
+
+	res, failed := tokencheck.Check(ctx, c, cfg.DisableDeniedWrites)
+	if failed != nil {
+		attrs := []any{"base_url", cfg.BaseURL}
+		if failed.Error != nil {
+			attrs = append(attrs, "error", failed.Error.Message())
+			if failed.Error.Hint != "" {
+				attrs = append(attrs, "hint", failed.Error.Hint)
+			}
+		}
+		slog.Error("auth token check failed; tool calls will fail until this is fixed", attrs...)
+		return false
+	}
+
+	slog.Info("auth token accepted", "dataset", res.Dataset, "write", res.Write)
+	return res.Write == client.AccessDenied
+}
+
+// reloadTokenOnHangup reads the auth token file again on every SIGHUP until
+// ctx is done.
+func reloadTokenOnHangup(ctx context.Context, c *client.Client, file string) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			changed, err := c.ReloadToken()
+			switch {
+			case err != nil:
+				slog.Warn("could not reload auth token, keeping the current one", "file", file, "error", err)
+			case changed:
+				slog.Info("auth token reloaded", "file", file)
+			default:
+				slog.Info("auth token file unchanged", "file", file)
+			}
+		}
+	}
+}
+
+// withoutWrites returns the enabled tools filter without the tools that
+// write to Dash0. dash0_tools_enable and dash0_tools_disable only change
+// this server's tools and stay enabled.
+func withoutWrites(reg *registry.Registry, enabled map[string]bool) map[string]bool {
+	filtered := make(map[string]bool)
+	for _, name := range reg.AllToolNames() {
+		if enabled != nil && !enabled[name] {
+			continue
+		}
+		if audit.Mutating(name) && !strings.HasPrefix(name, "dash0_tools_") {
+			continue
+		}
+		filtered[name] = true
+	}
+	return filtered
+}
+
+// scopedTools are the tools a scoped profile leaves enabled: those whose
+// reads are all span and log queries, which the client adds the scope's
+// filters to, and those that read nothing from Dash0. The other tools read
+// configuration, alerts, or PromQL results, which the scope does not apply
+// to.
+var scopedTools = map[string]bool{
+	"dash0_spans_query":         true,
+	"dash0_spans_stats":         true,
+	"dash0_spans_send":          true,
+	"dash0_logs_query":          true,
+	"dash0_logs_severity_trend": true,
+	"dash0_logs_send":           true,
+	"dash0_golden_signals":      true,
+	"dash0_canary_analyze":      true,
+	"dash0_services_compare":    true,
+	"dash0_errors_summarize":    true,
+	"dash0_wait_for":            true,
+	"dash0_compare_windows":     true,
+	"dash0_export_table":        true,
+	"dash0_examples":            true,
+	"dash0_session_usage":       true,
+	"dash0_server_stats":        true,
+	"dash0_server_version":      true,
+	"dash0_ping":                true,
+	"dash0_tools_list_enabled":  true,
+	"dash0_tools_enable":        true,
+	"dash0_tools_disable":       true,
+}
+
+// withoutUnscoped returns the enabled tools filter without the tools that
+// are not in scopedTools.
+func withoutUnscoped(reg *registry.Registry, enabled map[string]bool) map[string]bool {
+	filtered := make(map[string]bool)
+	for _, name := range reg.AllToolNames() {
+		if enabled != nil && !enabled[name] {
+			continue
+		}
+		if scopedTools[name] {
+			filtered[name] = true
+		}
+	}
+	return filtered
+}
+
+// profileSettingsChanged reports whether the settings of a profile that are
+// applied at startup differ between before and after.
+func profileSettingsChanged(before, after *config.Profile) bool {
+	settings := func(p *config.Profile) config.Profile {
+		if p == nil {
+			return config.Profile{}
+		}
+		return config.Profile{ReadOnly: p.ReadOnly, Scope: p.Scope, Defaults: p.Defaults}
+	}
+	return !reflect.DeepEqual(settings(before), settings(after))
+}
+
+// progressReporter sends notifications/progress for token to the client
+// session of ctx. A partial batch of the result goes in the batch field of
+// the notification's _meta, which clients that do not know it ignore.
+func progressReporter(ctx context.Context, s *server.MCPServer, token mcp.ProgressToken) progress.Reporter {
+	return func(done, total float64, message string, batch interface{}) {
+		params := map[string]interface{}{"progressToken": token, "progress": done}
+		if total > 0 {
+			params["total"] = total
+		}
+		if message != "" {
+			params["message"] = message
+		}
+		if batch != nil {
+			params["_meta"] = map[string]interface{}{"batch": batch}
+		}
+		if err := s.SendNotificationToClient(ctx, "notifications/progress", params); err != nil {
+			slog.Debug("could not send progress notification", "error", err)
+		}
+	}
+}
+
+// auditEntry describes a finished tool call for the audit log. A failed
+// call records the code of its error, as its span does.
+func auditEntry(ctx context.Context, tool string, args map[string]interface{}, start time.Time, result *client.ToolResult) audit.Entry {
+	entry := audit.Entry{
+		Time:       start.UTC(),
+		DurationMs: time.Since(start).Milliseconds(),
+		Tool:       tool,
+		Arguments:  audit.Redact(args),
+		Status:     "ok",
+		DryRun:     result.DryRun,
+		// Set when the trace file is on
+		CorrelationID: client.CorrelationID(ctx),
+	}
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		entry.Session = session.SessionID()
+		if info, ok := session.(interface{ ClientInfo() string }); ok {
+			entry.Client = info.ClientInfo()
+		}
+	}
+	if result.Error != nil {
+		entry.Status, entry.ErrorCode = "error", result.Error.Code
+		entry.StatusCode, entry.Error = result.Error.StatusCode, result.Error.Message()
+	}
+	return entry
+}
+
+// textSize returns the number of bytes of text in a tool result.
+func textSize(res *mcp.CallToolResult) int {
+	n := 0
+	for _, content := range res.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			n += len(text.Text)
+		}
+	}
+	return n
+}
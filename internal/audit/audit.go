@@ -0,0 +1,171 @@
+// Package audit writes an append-only JSONL record of every tool call that
+// changes something in Dash0: creates, updates, deletes, imports,
+// migrations, telemetry sends, and self-tests. Secrets in the arguments,
+// such as tokens and authorization headers of synthetic checks, are redacted
+// before they are written.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Redacted replaces the value of a secret argument.
+const Redacted = "[REDACTED]"
+
+// Stderr is the destination that writes the audit log to standard error.
+const Stderr = "stderr"
+
+// Entry is one audited tool call.
+type Entry struct {
+	// Time is when the call started.
+	Time       time.Time `json:"time"`
+	DurationMs int64     `json:"duration_ms"`
+	Tool       string    `json:"tool"`
+	// Arguments are the call's arguments with secrets redacted.
+	Arguments map[string]interface{} `json:"arguments"`
+	// Session identifies the client connection, and Client the client's
+	// name and version from initialize.
+	Session string `json:"session,omitempty"`
+	Client  string `json:"client,omitempty"`
+	// DryRun marks a call whose request was reported but not sent.
+	DryRun bool `json:"dry_run,omitempty"`
+	// Status is "ok" or "error".
+	Status     string `json:"status"`
+	ErrorCode  string `json:"error_code,omitempty"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// CorrelationID finds the call's API requests in the trace file
+	// (DASH0_MCP_TRACE_FILE).
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+// Logger appends entries to the audit log. A nil Logger writes nothing.
+type Logger struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// Open opens the audit log at dest: Stderr (or "-") for standard error,
+// otherwise a file path, which is created if needed and only ever appended
+// to. An empty dest disables the audit log and returns a nil Logger.
+func Open(dest string) (*Logger, error) {
+	switch dest = strings.TrimSpace(dest); dest {
+	case "":
+		return nil, nil
+	case Stderr, "-":
+		return New(os.Stderr), nil
+	}
+	f, err := os.OpenFile(dest, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &Logger{w: f, closer: f}, nil
+}
+
+// New creates a Logger that writes to w.
+func New(w io.Writer) *Logger {
+	return &Logger{w: w}
+}
+
+// Log appends e as one JSON line.
+func (l *Logger) Log(e Entry) error {
+	if l == nil {
+		return nil
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.w.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the audit log file.
+func (l *Logger) Close() error {
+	if l == nil || l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
+
+// mutatingSuffixes and mutatingPrefixes name the tools that write to Dash0.
+var (
+	mutatingSuffixes = []string{"_create", "_update", "_delete", "_send", "_add_panel", "_apply", "_enable", "_disable"}
+	mutatingPrefixes = []string{"dash0_import_", "dash0_migrate", "dash0_selftest", "dash0_synthetic_checks_generate", "dash0_synthetic_checks_create_"}
+)
+
+// Mutating reports whether a tool creates, updates, or deletes something in
+// Dash0 or sends telemetry to it.
+func Mutating(tool string) bool {
+	for _, suffix := range mutatingSuffixes {
+		if strings.HasSuffix(tool, suffix) {
+			return true
+		}
+	}
+	for _, prefix := range mutatingPrefixes {
+		if strings.HasPrefix(tool, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// secretWords are the key fragments that mark a value as secret.
+var secretWords = []string{"token", "password", "passwd", "secret", "authorization", "api_key", "apikey", "api-key", "credential", "private_key", "cookie"}
+
+// SecretKey reports whether a key or header name holds a secret.
+func SecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, word := range secretWords {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact returns a copy of args with the values of secret keys replaced by
+// Redacted. Name/value pairs, as used for HTTP headers, are redacted when
+// the name is secret.
+func Redact(args map[string]interface{}) map[string]interface{} {
+	redacted, _ := redact(args).(map[string]interface{})
+	return redacted
+}
+
+func redact(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			if SecretKey(k) {
+				out[k] = Redacted
+			} else {
+				out[k] = redact(val)
+			}
+		}
+		for _, nameKey := range []string{"name", "key"} {
+			if name, ok := v[nameKey].(string); ok && SecretKey(name) {
+				if _, ok := out["value"]; ok {
+					out["value"] = Redacted
+				}
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, val := range v {
+			out[i] = redact(val)
+		}
+		return out
+	}
+	return v
+}
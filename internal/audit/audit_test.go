@@ -0,0 +1,136 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMutating(t *testing.T) {
+	tests := map[string]bool{
+		"dash0_dashboards_create":                      true,
+		"dash0_views_update":                           true,
+		"dash0_alerting_check_rules_delete":            true,
+		"dash0_logs_send":                              true,
+		"dash0_dashboards_add_panel":                   true,
+		"dash0_sampling_policy_apply":                  true,
+		"dash0_synthetic_checks_disable":               true,
+		"dash0_import_prometheus_rules":                true,
+		"dash0_synthetic_checks_generate_from_openapi": true,
+		"dash0_synthetic_checks_create_cert_check":     true,
+		"dash0_migrate":                                true,
+		"dash0_selftest":                               true,
+		"dash0_dashboards_list":                        false,
+		"dash0_spans_query":                            false,
+		"dash0_export_all":                             false,
+		"dash0_sampling_policy_export":                 false,
+		"dash0_alerting_check_rules_test":              false,
+	}
+	for tool, want := range tests {
+		if got := Mutating(tool); got != want {
+			t.Errorf("Mutating(%q) = %v, want %v", tool, got, want)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+	args := map[string]interface{}{
+		"auth_token": "abc",
+		"origin":     "checkout",
+		"body": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"headers": []interface{}{
+					map[string]interface{}{"name": "Authorization", "value": "Bearer xyz"},
+					map[string]interface{}{"name": "Accept", "value": "application/json"},
+				},
+				"basicAuth": map[string]interface{}{"username": "bot", "password": "hunter2"},
+			},
+		},
+	}
+
+	got := Redact(args)
+	data, _ := json.Marshal(got)
+	for _, secret := range []string{"abc", "xyz", "hunter2"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("redacted args still contain %q: %s", secret, data)
+		}
+	}
+	for _, kept := range []string{"checkout", "application/json", `"username":"bot"`, `"name":"Authorization"`} {
+		if !strings.Contains(string(data), kept) {
+			t.Errorf("redacted args lost %s: %s", kept, data)
+		}
+	}
+
+	// The caller's arguments are left alone
+	if args["auth_token"] != "abc" {
+		t.Error("Redact modified its input")
+	}
+}
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf)
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, e := range []Entry{
+		{Time: start, Tool: "dash0_views_create", Status: "ok", Session: "stdio-1"},
+		{Time: start, Tool: "dash0_views_delete", Status: "error", ErrorCode: "not_found", StatusCode: 404},
+	} {
+		if err := l.Log(e); err != nil {
+			t.Fatalf("Log() error = %v", err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+	var e Entry
+	if err := json.Unmarshal([]byte(lines[1]), &e); err != nil {
+		t.Fatalf("line is not JSON: %v", err)
+	}
+	if e.Tool != "dash0_views_delete" || e.ErrorCode != "not_found" || !e.Time.Equal(start) {
+		t.Errorf("entry = %+v", e)
+	}
+
+	var nilLogger *Logger
+	if err := nilLogger.Log(Entry{}); err != nil {
+		t.Errorf("nil Logger Log() error = %v", err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	if l, err := Open(""); l != nil || err != nil {
+		t.Errorf("Open(\"\") = %v, %v; want disabled", l, err)
+	}
+	if l, err := Open("stderr"); err != nil || l.w != os.Stderr {
+		t.Errorf("Open(stderr) = %v, %v", l, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	for i := 0; i < 2; i++ {
+		l, err := Open(path)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		l.Log(Entry{Tool: "dash0_logs_send", Status: "ok"})
+		l.Close()
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "\n"); n != 2 {
+		t.Errorf("file has %d lines, want 2 (appended across opens)", n)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	if _, err := Open(filepath.Join(t.TempDir(), "missing", "audit.jsonl")); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
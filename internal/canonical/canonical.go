@@ -0,0 +1,157 @@
+// Package canonical brings API objects into a canonical JSON form, so two
+// bodies that mean the same thing compare, diff, and hash the same no matter
+// the key order or number formatting an agent used, and no matter which
+// server-maintained fields a fetched object carries.
+//
+// The canonical form of a value is its JSON decoding with:
+//
+//   - object keys sorted (as encoding/json writes maps)
+//   - numbers in their shortest form, so 1, 1.0, and 1e0 are all 1
+//   - the server-maintained fields of the object and its metadata removed:
+//     IDs, timestamps, versions, and status
+//
+// No HTML escaping is applied, and there is no trailing newline.
+package canonical
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// serverFields are top-level and metadata fields assigned by Dash0 or
+// Kubernetes rather than by whoever wrote the object.
+var serverFields = []string{"id", "uid", "createdAt", "updatedAt", "version", "resourceVersion", "generation", "creationTimestamp", "status"}
+
+// serverLabels are metadata labels assigned by Dash0.
+var serverLabels = []string{"dash0.com/id", "dash0.com/version", "dash0.com/created-at", "dash0.com/updated-at"}
+
+// Canonicalize returns the canonical form of v as decoded JSON: maps,
+// slices, strings, booleans, nil, and json.Number. v can be any value that
+// encodes to JSON; it is not modified.
+func Canonicalize(v interface{}) (interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode value: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var out interface{}
+	if err := dec.Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode value: %w", err)
+	}
+	strip(out)
+	return normalizeNumbers(out), nil
+}
+
+// Marshal returns the canonical JSON encoding of v.
+func Marshal(v interface{}) ([]byte, error) {
+	c, err := Canonicalize(v)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(c); err != nil {
+		return nil, fmt.Errorf("failed to encode value: %w", err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// Hash returns the SHA-256 of the canonical encoding of v, as
+// "sha256:<hex>".
+func Hash(v interface{}) (string, error) {
+	data, err := Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// Equal reports whether a and b have the same canonical form. Values that
+// do not encode to JSON are never equal.
+func Equal(a, b interface{}) bool {
+	ja, errA := Marshal(a)
+	jb, errB := Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+// strip removes the server-maintained fields of an object and its metadata.
+func strip(v interface{}) {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return
+	}
+	for _, f := range serverFields {
+		delete(obj, f)
+	}
+	meta, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	for _, f := range serverFields {
+		delete(meta, f)
+	}
+	if labels, ok := meta["labels"].(map[string]interface{}); ok {
+		StripServerLabels(labels)
+	}
+}
+
+// StripServerLabels removes the metadata labels Dash0 assigns, such as
+// dash0.com/id, from labels.
+func StripServerLabels(labels map[string]interface{}) {
+	for _, l := range serverLabels {
+		delete(labels, l)
+	}
+}
+
+// normalizeNumbers rewrites every json.Number in v in its shortest form.
+func normalizeNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			v[k] = normalizeNumbers(val)
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = normalizeNumbers(val)
+		}
+		return v
+	case json.Number:
+		return Number(v)
+	}
+	return v
+}
+
+// Number returns the shortest form of a JSON number. Integers without a
+// fraction or exponent keep all their digits, so IDs beyond the precision
+// of a float64 survive; other numbers are formatted as the float64 they
+// denote, without an exponent below 1e21.
+func Number(n json.Number) json.Number {
+	s := n.String()
+	if !strings.ContainsAny(s, ".eE") {
+		if s == "-0" {
+			return "0"
+		}
+		return n
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return n
+	}
+	if f == 0 {
+		return "0"
+	}
+	if math.Abs(f) < 1e21 {
+		return json.Number(strconv.FormatFloat(f, 'f', -1, 64))
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+}
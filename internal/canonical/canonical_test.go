@@ -0,0 +1,82 @@
+package canonical
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+	a := map[string]interface{}{
+		"spec":     map[string]interface{}{"rate": 0.5, "enabled": true, "limit": 10},
+		"kind":     "Dash0Sampling",
+		"metadata": map[string]interface{}{"name": "r", "createdAt": "2026-01-01T00:00:00Z"},
+		"id":       "abc",
+	}
+	var b interface{}
+	json.Unmarshal([]byte(`{"kind":"Dash0Sampling","metadata":{"name":"r","labels":{"dash0.com/version":"3"}},"spec":{"limit":1e1,"enabled":true,"rate":5.0E-1},"status":{}}`), &b)
+
+	ja, err := Marshal(a)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	jb, _ := Marshal(b)
+	want := `{"kind":"Dash0Sampling","metadata":{"name":"r"},"spec":{"enabled":true,"limit":10,"rate":0.5}}`
+	if string(ja) != want {
+		t.Errorf("Marshal(a) = %s, want %s", ja, want)
+	}
+	if string(jb) != `{"kind":"Dash0Sampling","metadata":{"labels":{},"name":"r"},"spec":{"enabled":true,"limit":10,"rate":0.5}}` {
+		t.Errorf("Marshal(b) = %s", jb)
+	}
+
+	// The input is not modified
+	if _, ok := a["id"]; !ok {
+		t.Error("Marshal modified its input")
+	}
+}
+
+func TestHashAndEqual(t *testing.T) {
+	a := map[string]interface{}{"name": "x", "threshold": 1, "url": "https://example.com/?a=1&b=2"}
+	b := map[string]interface{}{"threshold": 1.0, "url": "https://example.com/?a=1&b=2", "name": "x", "updatedAt": "now"}
+
+	ha, err := Hash(a)
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	hb, _ := Hash(b)
+	if ha != hb || !strings.HasPrefix(ha, "sha256:") || len(ha) != len("sha256:")+64 {
+		t.Errorf("Hash(a) = %s, Hash(b) = %s; want the same sha256", ha, hb)
+	}
+	if !Equal(a, b) {
+		t.Error("Equal(a, b) = false, want true")
+	}
+	if Equal(a, map[string]interface{}{"name": "y"}) {
+		t.Error("Equal() = true for different objects")
+	}
+	if Equal(a, func() {}) {
+		t.Error("Equal() = true for a value that does not encode")
+	}
+
+	data, _ := Marshal(a)
+	if strings.Contains(string(data), `\u0026`) {
+		t.Errorf("Marshal() escaped HTML: %s", data)
+	}
+}
+
+func TestNumber(t *testing.T) {
+	tests := map[string]string{
+		"1":                    "1",
+		"-0":                   "0",
+		"1.0":                  "1",
+		"1e2":                  "100",
+		"0.50":                 "0.5",
+		"-0.0":                 "0",
+		"12345678901234567890": "12345678901234567890",
+		"1.5e300":              "1.5e+300",
+	}
+	for in, want := range tests {
+		if got := Number(json.Number(in)); string(got) != want {
+			t.Errorf("Number(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
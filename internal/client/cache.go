@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// cacheMaxEntries bounds the number of cached responses.
+const cacheMaxEntries = 256
+
+// CacheStats counts response cache lookups.
+type CacheStats struct {
+	// Hits is the number of GET requests answered from the cache.
+	Hits int64 `json:"hits"`
+	// Misses is the number of cacheable GET requests sent upstream.
+	Misses int64 `json:"misses"`
+}
+
+// responseCache keeps successful GET responses for a short TTL so agents
+// that list or fetch the same objects repeatedly within a session don't pay
+// for a round trip each time. Any write clears it, so a list following a
+// create or update through this client always sees the change.
+type responseCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// cacheEntry is a cached response body, stored encoded so every hit hands
+// out its own copy of the data.
+type cacheEntry struct {
+	data    []byte
+	expires time.Time
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{ttl: ttl, entries: make(map[string]cacheEntry)}
+}
+
+// cacheKey identifies a response by method, path, and dataset.
+func cacheKey(method, path, dataset string) string {
+	return method + " " + path + " " + dataset
+}
+
+// get returns a copy of the cached data for key, if it has not expired.
+func (rc *responseCache) get(key string) (interface{}, bool) {
+	rc.mu.Lock()
+	entry, ok := rc.entries[key]
+	if ok && time.Now().After(entry.expires) {
+		delete(rc.entries, key)
+		ok = false
+	}
+	rc.mu.Unlock()
+
+	if ok {
+		var data interface{}
+		if err := json.Unmarshal(entry.data, &data); err == nil {
+			rc.hits.Add(1)
+			return data, true
+		}
+	}
+	rc.misses.Add(1)
+	return nil, false
+}
+
+// put caches data under key.
+func (rc *responseCache) put(key string, data interface{}) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	now := time.Now()
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if len(rc.entries) >= cacheMaxEntries {
+		for k, e := range rc.entries {
+			if now.After(e.expires) {
+				delete(rc.entries, k)
+			}
+		}
+	}
+	if len(rc.entries) >= cacheMaxEntries {
+		// Still full: drop an arbitrary entry.
+		for k := range rc.entries {
+			delete(rc.entries, k)
+			break
+		}
+	}
+	rc.entries[key] = cacheEntry{data: encoded, expires: now.Add(rc.ttl)}
+}
+
+// clear drops every cached response.
+func (rc *responseCache) clear() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	clear(rc.entries)
+}
+
+func (rc *responseCache) stats() CacheStats {
+	return CacheStats{Hits: rc.hits.Load(), Misses: rc.misses.Load()}
+}
+
+// bypassCacheKey is the context key that disables the response cache.
+type bypassCacheKey struct{}
+
+// WithoutCache returns a context whose GET requests skip the response cache
+// and always go upstream. Their fresh responses still replace cached ones.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassCacheKey{}, true)
+}
+
+// CacheBypassed reports whether ctx was created with WithoutCache.
+func CacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassCacheKey{}).(bool)
+	return bypass
+}
+
+// cachedRequest serves GET requests from the cache and clears it after
+// writes that were sent, as classified by isReadOnly; send performs the
+// request itself.
+func (c *Client) cachedRequest(ctx context.Context, method, path string, send func() *ToolResult) *ToolResult {
+	if method != http.MethodGet {
+		result := send()
+		if !isReadOnly(ctx, method, path) && !result.DryRun {
+			c.cache.clear()
+		}
+		return result
+	}
+
+	key := cacheKey(method, path, c.Dataset(ctx))
+	if !CacheBypassed(ctx) {
+		if data, ok := c.cache.get(key); ok {
+			return SuccessResult(data)
+		}
+	}
+	result := send()
+	if result.Success {
+		c.cache.put(key, result.Data)
+	}
+	return result
+}
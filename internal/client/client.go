@@ -5,49 +5,174 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/npcomplete777/dash0-mcp/internal/config"
+	"github.com/npcomplete777/dash0-mcp/internal/otlp"
+	"github.com/npcomplete777/dash0-mcp/internal/selftel"
+	"github.com/npcomplete777/dash0-mcp/internal/truncate"
 )
 
 // Client handles authenticated HTTP requests to the Dash0 API.
 type Client struct {
-	baseURL    string
-	authToken  string
+	baseURL string
+	// token is the auth token, which may be reloaded from a file.
+	token      *tokenSource
 	dataset    string
 	httpClient *http.Client
 	debug      bool
 	maxRetries int
+	limits     truncate.Limits
+	scope      []otlp.AttributeFilter
+	// hedge sends duplicates of slow read-only requests; nil disables it.
+	hedge *hedger
+	// limiter spaces out requests client-side; nil disables it.
+	limiter *rateLimiter
+	// cache keeps recent GET responses; nil disables it.
+	cache *responseCache
+	// usage counts upstream calls; targets share their parent's counters.
+	usage *usageCounters
+	// telemetry records a span per request; nil disables it.
+	telemetry *selftel.Recorder
+	// targets are clients for the named targets in the configuration.
+	targets map[string]*Client
+	// ingress sends telemetry to a separate ingestion endpoint; nil sends it
+	// through the API.
+	ingress *Client
+	// ingest marks the client of an ingestion endpoint, which reads the
+	// dataset from the Dash0-Dataset header.
+	ingest bool
+	// dryRun describes writes instead of sending them (DASH0_MCP_DRY_RUN).
+	dryRun bool
+	// readOnly rejects writes before they are sent (DASH0_MCP_READ_ONLY).
+	readOnly bool
+	// confirm makes deletes wait for a confirmation token; nil deletes
+	// right away.
+	confirm *confirmations
+	// faults fails a share of requests with simulated errors
+	// (DASH0_FAULT_INJECTION); nil sends every request.
+	faults *faultInjector
+	// concurrency bounds the requests a Parallel fan-out issues at once.
+	concurrency int
+	// trace records every request and response (DASH0_MCP_TRACE_FILE); nil
+	// records nothing.
+	trace *Trace
 }
 
 // New creates a new Dash0 API client from configuration.
 func New(cfg *config.Config) *Client {
-	return &Client{
-		baseURL:    cfg.BaseURL,
-		authToken:  cfg.AuthToken,
-		dataset:    cfg.Dataset,
-		debug:      cfg.Debug,
-		maxRetries: 3,
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = config.DefaultTimeout
+	}
+	c := &Client{
+		baseURL:     cfg.BaseURL,
+		token:       newTokenSource(cfg.AuthToken, cfg.AuthTokenFile),
+		dataset:     cfg.Dataset,
+		debug:       cfg.Debug,
+		dryRun:      cfg.DryRun,
+		readOnly:    cfg.ReadOnly,
+		maxRetries:  cfg.MaxRetries,
+		concurrency: cfg.MaxConcurrency,
+		usage:       newUsageCounters(cfg.CallBudget),
+		limits: truncate.Limits{
+			MaxBytes: cfg.MaxResponseBytes,
+			MaxItems: cfg.MaxItems,
+		},
 		httpClient: &http.Client{
-			Timeout: 60 * time.Second,
+			Timeout:   timeout,
+			Transport: newTransport(cfg),
 		},
 	}
+	if cfg.Fixtures != "" {
+		c.httpClient.Transport = newFixtureTransport(cfg.Fixtures, cfg.FixtureMode == config.FixtureRecord, c.httpClient.Transport)
+	}
+	if cfg.Hedge {
+		c.hedge = newHedger(cfg.HedgeDelay)
+	}
+	if cfg.RateLimit > 0 {
+		c.limiter = newRateLimiter(cfg.RateLimit, cfg.RateBurst)
+	}
+	if cfg.CacheTTL > 0 {
+		c.cache = newResponseCache(cfg.CacheTTL)
+	}
+	if cfg.ConfirmDeletes {
+		c.confirm = newConfirmations()
+	}
+	c.SetFaultInjection(cfg.FaultInjection)
+	if cfg.IngressURL != "" {
+		c.ingress = newIngressClient(cfg)
+		c.ingress.usage = c.usage
+		if cfg.AuthTokenFile != "" && cfg.IngressToken == cfg.AuthToken {
+			c.ingress.token = c.token
+		}
+	}
+	for name, t := range cfg.Targets {
+		tc := *cfg
+		tc.BaseURL, tc.AuthToken, tc.AuthTokenFile, tc.Dataset, tc.Targets = t.BaseURL, t.AuthToken, "", t.Dataset, nil
+		tc.IngressURL, tc.IngressToken = "", ""
+		tclient := New(&tc)
+		tclient.usage = c.usage
+		// A target without a token of its own uses the rotated one
+		if cfg.AuthTokenFile != "" && t.AuthToken == cfg.AuthToken {
+			tclient.token = c.token
+		}
+		c.SetTarget(name, tclient)
+	}
+	return c
+}
+
+// Target returns the client for a named target. An empty name or
+// config.DefaultTarget returns c itself.
+func (c *Client) Target(name string) (*Client, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == config.DefaultTarget {
+		return c, nil
+	}
+	if t, ok := c.targets[name]; ok {
+		return t, nil
+	}
+	return nil, fmt.Errorf("unknown target %q; configured targets: %s", name, strings.Join(c.TargetNames(), ", "))
+}
+
+// TargetNames returns the configured target names, starting with
+// config.DefaultTarget.
+func (c *Client) TargetNames() []string {
+	names := make([]string, 0, len(c.targets))
+	for name := range c.targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{config.DefaultTarget}, names...)
+}
+
+// SetTarget registers the client used for a named target.
+func (c *Client) SetTarget(name string, t *Client) {
+	if c.targets == nil {
+		c.targets = make(map[string]*Client)
+	}
+	c.targets[name] = t
 }
 
 // NewWithBaseURL creates a new Dash0 API client with a custom base URL.
 // This is primarily used for testing with mock servers.
 func NewWithBaseURL(baseURL, authToken string) *Client {
 	return &Client{
-		baseURL:    baseURL,
-		authToken:  authToken,
-		debug:      false,
-		maxRetries: 3,
+		baseURL:     baseURL,
+		token:       newTokenSource(authToken, ""),
+		debug:       false,
+		maxRetries:  3,
+		concurrency: config.DefaultMaxConcurrency,
+		usage:       newUsageCounters(0),
 		httpClient: &http.Client{
 			Timeout: 60 * time.Second,
 		},
@@ -60,6 +185,8 @@ type ToolResult struct {
 	Data    interface{} `json:"data,omitempty"`
 	Error   *APIError   `json:"error,omitempty"`
 	Meta    interface{} `json:"meta,omitempty"`
+	// DryRun marks the result of a write that was described but not sent.
+	DryRun bool `json:"dry_run,omitempty"`
 	// Markdown is pre-formatted markdown text for the MCP response.
 	// When set, it is used instead of JSON-marshaling Data.
 	Markdown string `json:"-"`
@@ -67,9 +194,24 @@ type ToolResult struct {
 
 // APIError represents a Dash0 API error.
 type APIError struct {
+	// Code classifies the error so callers can branch on it; see ErrorCode.
+	Code       string `json:"code"`
 	StatusCode int    `json:"status_code"`
 	Title      string `json:"title,omitempty"`
 	Detail     string `json:"detail,omitempty"`
+	// Pointer is the JSON pointer of the first offending field, when the
+	// API names one.
+	Pointer string       `json:"pointer,omitempty"`
+	Fields  []FieldError `json:"fields,omitempty"`
+	// Hint is actionable guidance for fixing the request.
+	Hint string `json:"hint,omitempty"`
+	// RequestID is the upstream request ID, for support requests.
+	RequestID string `json:"request_id,omitempty"`
+	// Body is the raw upstream response body, capped at maxRawErrorBodyLen.
+	Body string `json:"body,omitempty"`
+	// CorrelationID identifies the tool call's requests in the trace file
+	// (DASH0_MCP_TRACE_FILE).
+	CorrelationID string `json:"correlation_id,omitempty"`
 }
 
 // ErrorResult creates an error ToolResult.
@@ -77,6 +219,7 @@ func ErrorResult(statusCode int, message string) *ToolResult {
 	return &ToolResult{
 		Success: false,
 		Error: &APIError{
+			Code:       ErrorCode(statusCode),
 			StatusCode: statusCode,
 			Detail:     message,
 		},
@@ -88,6 +231,330 @@ func (c *Client) GetDataset() string {
 	return c.dataset
 }
 
+// BaseURL returns the API base URL requests are sent to.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// datasetKey is the context key for a per-call dataset override.
+type datasetKey struct{}
+
+// WithDataset returns a context whose requests are sent to dataset instead of
+// the configured one. An empty dataset leaves ctx unchanged.
+func WithDataset(ctx context.Context, dataset string) context.Context {
+	dataset = strings.TrimSpace(dataset)
+	if dataset == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, datasetKey{}, dataset)
+}
+
+// WithoutDataset returns a context whose requests go to the client's
+// configured dataset, dropping any per-call override.
+func WithoutDataset(ctx context.Context) context.Context {
+	if DatasetOverride(ctx) == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, datasetKey{}, "")
+}
+
+// DatasetOverride returns the per-call dataset set with WithDataset, if any.
+func DatasetOverride(ctx context.Context) string {
+	dataset, _ := ctx.Value(datasetKey{}).(string)
+	return dataset
+}
+
+// Dataset returns the dataset requests made with ctx are sent to: the
+// per-call override if one is set, otherwise the configured dataset.
+func (c *Client) Dataset(ctx context.Context) string {
+	if dataset := DatasetOverride(ctx); dataset != "" {
+		return dataset
+	}
+	return c.dataset
+}
+
+// ResponseLimits returns the configured default response size limits.
+func (c *Client) ResponseLimits() truncate.Limits {
+	return c.limits
+}
+
+// SetResponseLimits overrides the default response size limits.
+func (c *Client) SetResponseLimits(l truncate.Limits) {
+	c.limits = l
+}
+
+// SetQueryScope restricts every telemetry query sent through the client to
+// spans, logs, and metrics whose attributes equal the given values.
+func (c *Client) SetQueryScope(attrs map[string]string) {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	c.scope = nil
+	for _, k := range keys {
+		v := attrs[k]
+		c.scope = append(c.scope, otlp.AttributeFilter{
+			Key:      k,
+			Operator: "is",
+			Value:    &otlp.AttributeFilterValue{StringValue: &v},
+		})
+	}
+}
+
+// QueryScope returns the filters added to every telemetry query.
+func (c *Client) QueryScope() []otlp.AttributeFilter {
+	return c.scope
+}
+
+// SetHedging enables hedging of read-only requests with the given delay;
+// 0 derives the delay from the p95 of recent read latencies.
+func (c *Client) SetHedging(delay time.Duration) {
+	c.hedge = newHedger(delay)
+}
+
+// SetTelemetry records a span for every API request with rec, as a child of
+// the tool call span in the request context. Targets and the ingestion
+// endpoint record with it too; nil turns recording off.
+func (c *Client) SetTelemetry(rec *selftel.Recorder) {
+	c.telemetry = rec
+	for _, t := range c.targets {
+		t.telemetry = rec
+	}
+	if c.ingress != nil {
+		c.ingress.telemetry = rec
+	}
+}
+
+// SetTrace records every API request and its response with t. Targets and
+// the ingestion endpoint record with it too; nil turns recording off.
+func (c *Client) SetTrace(t *Trace) {
+	c.trace = t
+	for _, target := range c.targets {
+		target.trace = t
+	}
+	if c.ingress != nil {
+		c.ingress.trace = t
+	}
+}
+
+// Telemetry returns the recorder set with SetTelemetry, or nil.
+func (c *Client) Telemetry() *selftel.Recorder {
+	return c.telemetry
+}
+
+// SetRateLimit limits requests to rps per second with bursts of up to burst
+// requests; a burst below 1 defaults to one second's worth. rps <= 0 removes
+// the limit.
+func (c *Client) SetRateLimit(rps float64, burst int) {
+	if rps <= 0 {
+		c.limiter = nil
+		return
+	}
+	c.limiter = newRateLimiter(rps, burst)
+}
+
+// SetCacheTTL caches GET responses for ttl; ttl <= 0 disables the cache.
+func (c *Client) SetCacheTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		c.cache = nil
+		return
+	}
+	c.cache = newResponseCache(ttl)
+}
+
+// CacheStats returns the response cache counters; they are zero when the
+// cache is off.
+func (c *Client) CacheStats() CacheStats {
+	if c.cache == nil {
+		return CacheStats{}
+	}
+	return c.cache.stats()
+}
+
+// HedgeStats returns the hedging counters; they are zero when hedging is off.
+func (c *Client) HedgeStats() HedgeStats {
+	if c.hedge == nil {
+		return HedgeStats{}
+	}
+	return c.hedge.stats()
+}
+
+// telemetryQuery decodes a POST body that is a telemetry query, i.e. a JSON
+// object with a timeRange. Other requests, such as OTLP ingestion payloads,
+// return nil.
+func telemetryQuery(method string, bodyBytes []byte) map[string]interface{} {
+	if method != http.MethodPost || bodyBytes == nil {
+		return nil
+	}
+	var query map[string]interface{}
+	if err := json.Unmarshal(bodyBytes, &query); err != nil {
+		return nil
+	}
+	if _, ok := query["timeRange"]; !ok {
+		return nil
+	}
+	return query
+}
+
+// queryPaths are the endpoints a POST queries rather than writes to.
+var queryPaths = map[string]bool{
+	"/api/spans":                         true,
+	"/api/logs":                          true,
+	"/api/prometheus/api/v1/query":       true,
+	"/api/prometheus/api/v1/query_range": true,
+}
+
+// isReadOnly reports whether a request only reads data and may be hedged: a
+// GET, or a POST to one of queryPaths. Ingest posts OTLP payloads to the
+// spans and logs paths too, so its requests are never reads.
+func isReadOnly(ctx context.Context, method, path string) bool {
+	path, _, _ = strings.Cut(path, "?")
+	switch method {
+	case http.MethodGet:
+		return true
+	case http.MethodPost:
+		return queryPaths[path] && !isIngestion(ctx)
+	}
+	return false
+}
+
+// rejectWrite returns the error for a write made by a read-only client, or
+// nil when the request may be sent. Reads and telemetry queries are always
+// allowed.
+func (c *Client) rejectWrite(ctx context.Context, method, path string) *ToolResult {
+	if !c.readOnly || isReadOnly(ctx, method, path) {
+		return nil
+	}
+	result := ErrorResult(http.StatusForbidden, fmt.Sprintf("read-only mode: %s %s is not allowed", method, path))
+	result.Error.Hint = "This server is read-only (DASH0_MCP_READ_ONLY or a read_only profile), so creates, updates, deletes, and sends are rejected before they reach Dash0."
+	return result
+}
+
+// do sends req, and sends it once more with a reloaded token when the API
+// rejects the token and the token file holds a new one.
+func (c *Client) do(req *http.Request, readOnly bool) (*http.Response, error) {
+	resp, err := c.send(req, readOnly)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	token := c.token.refresh(usedToken(req))
+	if token == "" {
+		return resp, nil
+	}
+	retry := withToken(req, token)
+	if retry == nil {
+		return resp, nil
+	}
+	resp.Body.Close()
+	slog.Info("auth token reloaded after a 401; retrying the request", "method", req.Method, "path", req.URL.Path)
+	return c.send(retry, readOnly)
+}
+
+// send sends req once the rate limiter allows it, hedging it when it is
+// read-only and hedging is enabled. Hedges are only sent when the limiter has
+// room for them right away.
+func (c *Client) send(req *http.Request, readOnly bool) (*http.Response, error) {
+	var allowHedge func() bool
+	if c.limiter != nil {
+		waited, err := c.limiter.wait(req.Context())
+		c.usage.waited(waited)
+		if err != nil {
+			return nil, err
+		}
+		allowHedge = c.limiter.tryTake
+	}
+
+	if c.ingest {
+		if dataset := req.URL.Query().Get("dataset"); dataset != "" {
+			req.Header.Set("Dash0-Dataset", dataset)
+		}
+	}
+
+	c.usage.call(req)
+	if c.debug {
+		logRequest(req)
+	}
+	start := time.Now()
+	var resp *http.Response
+	var err error
+	if c.faults != nil {
+		resp, err = c.faults.inject(req)
+	}
+	switch {
+	case resp != nil || err != nil:
+		// A simulated fault; nothing was sent
+	case c.hedge == nil:
+		resp, err = c.httpClient.Do(req)
+	default:
+		resp, err = c.hedge.do(c.httpClient, req, readOnly, allowHedge)
+	}
+	c.usage.response(resp, err)
+	c.telemetry.ObserveRequest(req, resp, err, start)
+	if err == nil {
+		logRateLimit(req, resp)
+	}
+	if c.debug || c.trace != nil {
+		var body []byte
+		if err == nil {
+			body = bufferBody(resp)
+		}
+		if c.debug && err == nil {
+			logResponse(req, resp, body, start)
+		}
+		c.trace.record(req, resp, body, err, start)
+	}
+	return resp, err
+}
+
+// applyQueryScope appends the scope filters to a telemetry query body. A body
+// is treated as a query when it is a JSON object with a timeRange; other
+// requests, such as OTLP ingestion payloads, are returned unchanged.
+func (c *Client) applyQueryScope(method string, bodyBytes []byte) ([]byte, error) {
+	if len(c.scope) == 0 {
+		return bodyBytes, nil
+	}
+	query := telemetryQuery(method, bodyBytes)
+	if query == nil {
+		return bodyBytes, nil
+	}
+
+	filters, _ := query["filter"].([]interface{})
+	for _, f := range c.scope {
+		filters = append(filters, f)
+	}
+	query["filter"] = filters
+	return json.Marshal(query)
+}
+
+// encodeBody returns the bytes and content headers of a request body: a
+// RawBody as is, anything else as JSON with the query scope applied.
+func (c *Client) encodeBody(method string, body interface{}) ([]byte, http.Header, *ToolResult) {
+	headers := http.Header{"Content-Type": {"application/json"}}
+	if raw, ok := body.(RawBody); ok {
+		if raw.ContentType != "" {
+			headers.Set("Content-Type", raw.ContentType)
+		}
+		if raw.ContentEncoding != "" {
+			headers.Set("Content-Encoding", raw.ContentEncoding)
+		}
+		return raw.Data, headers, nil
+	}
+	if body == nil {
+		return nil, headers, nil
+	}
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, nil, ErrorResult(http.StatusBadRequest, fmt.Sprintf("failed to marshal request body: %v", err))
+	}
+	bodyBytes, err = c.applyQueryScope(method, bodyBytes)
+	if err != nil {
+		return nil, nil, ErrorResult(http.StatusBadRequest, fmt.Sprintf("failed to apply query scope: %v", err))
+	}
+	return bodyBytes, headers, nil
+}
+
 // PostWithDataset performs a POST request with a specific dataset override.
 // If dataset is non-empty, it overrides the global dataset for this request.
 func (c *Client) PostWithDataset(ctx context.Context, path string, body interface{}, dataset string) *ToolResult {
@@ -99,6 +566,10 @@ func (c *Client) PostWithDataset(ctx context.Context, path string, body interfac
 
 // requestWithDataset performs an HTTP request with a specific dataset, overriding the global one.
 func (c *Client) requestWithDataset(ctx context.Context, method, path string, body interface{}, dataset string) *ToolResult {
+	if ctx.Err() != nil {
+		return contextErrorResult(ctx)
+	}
+
 	requestURL := c.baseURL + path
 
 	if strings.Contains(requestURL, "?") {
@@ -107,14 +578,18 @@ func (c *Client) requestWithDataset(ctx context.Context, method, path string, bo
 		requestURL = requestURL + "?dataset=" + url.QueryEscape(dataset)
 	}
 
-	var bodyBytes []byte
-	if body != nil {
-		var err error
-		bodyBytes, err = json.Marshal(body)
-		if err != nil {
-			return ErrorResult(http.StatusBadRequest, fmt.Sprintf("failed to marshal request body: %v", err))
-		}
+	bodyBytes, headers, failed := c.encodeBody(method, body)
+	if failed != nil {
+		return failed
+	}
+	if result := c.rejectWrite(ctx, method, path); result != nil {
+		return result
 	}
+	if c.skipWrite(ctx, method, path) {
+		return c.dryRunResult(method, requestURL, bodyBytes, headers)
+	}
+
+	readOnly := c.hedge != nil && isReadOnly(ctx, method, path)
 
 	var resp *http.Response
 	var respBody []byte
@@ -130,12 +605,17 @@ func (c *Client) requestWithDataset(ctx context.Context, method, path string, bo
 			return ErrorResult(http.StatusInternalServerError, fmt.Sprintf("failed to create request: %v", err))
 		}
 
-		req.Header.Set("Authorization", "Bearer "+c.authToken)
-		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+c.authToken())
+		for name, values := range headers {
+			req.Header[name] = values
+		}
 		req.Header.Set("Accept", "application/json")
 
-		resp, err = c.httpClient.Do(req)
+		resp, err = c.do(req, readOnly)
 		if err != nil {
+			if ctx.Err() != nil {
+				return contextErrorResult(ctx)
+			}
 			return ErrorResult(http.StatusInternalServerError, fmt.Sprintf("request failed: %v", err))
 		}
 
@@ -159,7 +639,7 @@ func (c *Client) requestWithDataset(ctx context.Context, method, path string, bo
 
 			select {
 			case <-ctx.Done():
-				return ErrorResult(http.StatusInternalServerError, fmt.Sprintf("request cancelled during retry: %v", ctx.Err()))
+				return contextErrorResult(ctx)
 			case <-time.After(waitDuration):
 			}
 			continue
@@ -171,6 +651,9 @@ func (c *Client) requestWithDataset(ctx context.Context, method, path string, bo
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
+		if ctx.Err() != nil {
+			return contextErrorResult(ctx)
+		}
 		return ErrorResult(http.StatusInternalServerError, fmt.Sprintf("failed to read response: %v", err))
 	}
 
@@ -184,12 +667,8 @@ func (c *Client) requestWithDataset(ctx context.Context, method, path string, bo
 	if resp.StatusCode >= 400 {
 		return &ToolResult{
 			Success: false,
-			Error: &APIError{
-				StatusCode: resp.StatusCode,
-				Title:      resp.Status,
-				Detail:     extractErrorDetail(result),
-			},
-			Data: result,
+			Error:   newAPIError(resp, respBody, result),
+			Data:    result,
 		}
 	}
 
@@ -204,7 +683,8 @@ func SuccessResult(data interface{}) *ToolResult {
 	}
 }
 
-// Get performs a GET request.
+// Get performs a GET request. Get, Post, Put, Patch, and Delete honour a dataset
+// override set on ctx with WithDataset.
 func (c *Client) Get(ctx context.Context, path string) *ToolResult {
 	return c.Request(ctx, http.MethodGet, path, nil)
 }
@@ -219,13 +699,45 @@ func (c *Client) Put(ctx context.Context, path string, body interface{}) *ToolRe
 	return c.Request(ctx, http.MethodPut, path, body)
 }
 
-// Delete performs a DELETE request.
+// Patch performs a PATCH request with a JSON merge patch body.
+func (c *Client) Patch(ctx context.Context, path string, body interface{}) *ToolResult {
+	return c.Request(ctx, http.MethodPatch, path, body)
+}
+
+// Delete performs a DELETE request. When deletes need confirmation
+// (DASH0_MCP_CONFIRM_DELETES), a call without a token from WithConfirmToken
+// only returns a DeleteConfirmation for the object.
 func (c *Client) Delete(ctx context.Context, path string) *ToolResult {
+	if c.confirm != nil && !c.readOnly && !c.DryRun(ctx) {
+		if result := c.confirmDelete(ctx, path); result != nil {
+			return result
+		}
+	}
 	return c.Request(ctx, http.MethodDelete, path, nil)
 }
 
-// Request performs an HTTP request to the Dash0 API.
+// Request performs an HTTP request to the Dash0 API. GET responses are
+// served from the response cache when it is enabled.
 func (c *Client) Request(ctx context.Context, method, path string, body interface{}) *ToolResult {
+	// Don't start requests for a call that was already cancelled or timed out.
+	if ctx.Err() != nil {
+		return contextErrorResult(ctx)
+	}
+	if c.cache != nil {
+		return c.cachedRequest(ctx, method, path, func() *ToolResult {
+			return c.request(ctx, method, path, body)
+		})
+	}
+	return c.request(ctx, method, path, body)
+}
+
+// request performs an HTTP request to the Dash0 API, bypassing the cache.
+func (c *Client) request(ctx context.Context, method, path string, body interface{}) *ToolResult {
+	// A per-call dataset takes precedence over the configured one
+	if dataset := DatasetOverride(ctx); dataset != "" {
+		return c.requestWithDataset(ctx, method, path, body, dataset)
+	}
+
 	requestURL := c.baseURL + path
 
 	// Add dataset as query parameter for all request methods
@@ -234,15 +746,19 @@ func (c *Client) Request(ctx context.Context, method, path string, body interfac
 	}
 
 	// Marshal the body once so we can re-use it across retries.
-	var bodyBytes []byte
-	if body != nil {
-		var err error
-		bodyBytes, err = json.Marshal(body)
-		if err != nil {
-			return ErrorResult(http.StatusBadRequest, fmt.Sprintf("failed to marshal request body: %v", err))
-		}
+	bodyBytes, headers, failed := c.encodeBody(method, body)
+	if failed != nil {
+		return failed
+	}
+	if result := c.rejectWrite(ctx, method, path); result != nil {
+		return result
+	}
+	if c.skipWrite(ctx, method, path) {
+		return c.dryRunResult(method, requestURL, bodyBytes, headers)
 	}
 
+	readOnly := c.hedge != nil && isReadOnly(ctx, method, path)
+
 	var resp *http.Response
 	var respBody []byte
 
@@ -259,13 +775,18 @@ func (c *Client) Request(ctx context.Context, method, path string, body interfac
 		}
 
 		// Set headers
-		req.Header.Set("Authorization", "Bearer "+c.authToken)
-		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+c.authToken())
+		for name, values := range headers {
+			req.Header[name] = values
+		}
 		req.Header.Set("Accept", "application/json")
 
 		// Execute request
-		resp, err = c.httpClient.Do(req)
+		resp, err = c.do(req, readOnly)
 		if err != nil {
+			if ctx.Err() != nil {
+				return contextErrorResult(ctx)
+			}
 			return ErrorResult(http.StatusInternalServerError, fmt.Sprintf("request failed: %v", err))
 		}
 
@@ -296,7 +817,7 @@ func (c *Client) Request(ctx context.Context, method, path string, body interfac
 			// Wait, but respect context cancellation.
 			select {
 			case <-ctx.Done():
-				return ErrorResult(http.StatusInternalServerError, fmt.Sprintf("request cancelled during retry: %v", ctx.Err()))
+				return contextErrorResult(ctx)
 			case <-time.After(waitDuration):
 			}
 			continue
@@ -310,6 +831,9 @@ func (c *Client) Request(ctx context.Context, method, path string, body interfac
 	// Read response body
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
+		if ctx.Err() != nil {
+			return contextErrorResult(ctx)
+		}
 		return ErrorResult(http.StatusInternalServerError, fmt.Sprintf("failed to read response: %v", err))
 	}
 
@@ -326,18 +850,27 @@ func (c *Client) Request(ctx context.Context, method, path string, body interfac
 	if resp.StatusCode >= 400 {
 		return &ToolResult{
 			Success: false,
-			Error: &APIError{
-				StatusCode: resp.StatusCode,
-				Title:      resp.Status,
-				Detail:     extractErrorDetail(result),
-			},
-			Data: result,
+			Error:   newAPIError(resp, respBody, result),
+			Data:    result,
 		}
 	}
 
 	return SuccessResult(result)
 }
 
+// StatusClientClosedRequest is reported when the MCP client cancelled the
+// tool call before the upstream request completed.
+const StatusClientClosedRequest = 499
+
+// contextErrorResult reports a request aborted because ctx was cancelled or
+// its deadline passed.
+func contextErrorResult(ctx context.Context) *ToolResult {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrorResult(http.StatusGatewayTimeout, "request aborted: tool call timed out")
+	}
+	return ErrorResult(StatusClientClosedRequest, "request aborted: tool call was cancelled")
+}
+
 // extractErrorDetail attempts to extract error details from the response.
 func extractErrorDetail(result interface{}) string {
 	if m, ok := result.(map[string]interface{}); ok {
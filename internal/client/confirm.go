@@ -0,0 +1,164 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/internal/listing/fields"
+)
+
+// confirmTokenTTL is how long a delete confirmation token stays valid.
+const confirmTokenTTL = 5 * time.Minute
+
+type confirmTokenKey struct{}
+
+// WithConfirmToken returns a context whose deletes are confirmed by token, a
+// token returned by an earlier delete of the same object. An empty token
+// leaves ctx unchanged.
+func WithConfirmToken(ctx context.Context, token string) context.Context {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, confirmTokenKey{}, token)
+}
+
+// ConfirmToken returns the confirmation token set on ctx, or "".
+func ConfirmToken(ctx context.Context) string {
+	token, _ := ctx.Value(confirmTokenKey{}).(string)
+	return token
+}
+
+// DeleteConfirmation is the result of a delete that waits for confirmation:
+// the object that would be deleted and the token that confirms it.
+type DeleteConfirmation struct {
+	ConfirmToken string    `json:"confirm_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	Path         string    `json:"path"`
+	Dataset      string    `json:"dataset,omitempty"`
+	Kind         string    `json:"kind,omitempty"`
+	Name         string    `json:"name,omitempty"`
+	ID           string    `json:"id,omitempty"`
+}
+
+// pendingDelete is a delete a token was issued for.
+type pendingDelete struct {
+	path, dataset string
+	expires       time.Time
+}
+
+// confirmations holds the tokens of deletes waiting for confirmation.
+type confirmations struct {
+	mu      sync.Mutex
+	pending map[string]pendingDelete
+	now     func() time.Time
+}
+
+func newConfirmations() *confirmations {
+	return &confirmations{pending: make(map[string]pendingDelete), now: time.Now}
+}
+
+// issue returns a new token for deleting path in dataset and drops expired
+// ones.
+func (c *confirmations) issue(path, dataset string) (string, time.Time) {
+	b := make([]byte, 16)
+	rand.Read(b)
+	token := hex.EncodeToString(b)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for t, p := range c.pending {
+		if now.After(p.expires) {
+			delete(c.pending, t)
+		}
+	}
+	expires := now.Add(confirmTokenTTL)
+	c.pending[token] = pendingDelete{path: path, dataset: dataset, expires: expires}
+	return token, expires
+}
+
+// take reports whether token confirms deleting path in dataset. A token is
+// used up by the first attempt to confirm with it.
+func (c *confirmations) take(token, path, dataset string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.pending[token]
+	if !ok {
+		return false
+	}
+	delete(c.pending, token)
+	return p.path == path && p.dataset == dataset && !c.now().After(p.expires)
+}
+
+// confirmDelete runs the first phase of a two-phase delete. Without a token
+// on ctx, it fetches the object and returns a summary with a new token
+// instead of deleting it. With a valid token it returns nil, and the delete
+// goes ahead.
+func (c *Client) confirmDelete(ctx context.Context, path string) *ToolResult {
+	dataset := c.Dataset(ctx)
+	if token := ConfirmToken(ctx); token != "" {
+		if !c.confirm.take(token, path, dataset) {
+			return ErrorResult(http.StatusBadRequest, "confirm_token is invalid, expired, or for another object; call the tool again without it to get a new token")
+		}
+		return nil
+	}
+
+	current := c.Get(WithoutCache(ctx), path)
+	if !current.Success {
+		return current
+	}
+	token, expires := c.confirm.issue(path, dataset)
+	confirmation := DeleteConfirmation{ConfirmToken: token, ExpiresAt: expires.UTC(), Path: path, Dataset: dataset}
+	confirmation.Kind, confirmation.Name, confirmation.ID = describeObject(current.Data)
+
+	object := "`" + path + "`"
+	if confirmation.Name != "" {
+		object = fmt.Sprintf("**%s** (%s)", confirmation.Name, object)
+	}
+	if confirmation.Kind != "" {
+		object = confirmation.Kind + " " + object
+	}
+	if dataset != "" {
+		object += " in dataset `" + dataset + "`"
+	}
+	var sb strings.Builder
+	sb.WriteString("## Confirm Delete\n\n")
+	fmt.Fprintf(&sb, "Nothing was deleted yet. This call would delete %s.\n\n", object)
+	fmt.Fprintf(&sb, "To delete it, call the tool again with the same arguments and `confirm_token: %q` before %s.\n",
+		token, confirmation.ExpiresAt.Format(time.RFC3339))
+	return &ToolResult{Success: true, Data: confirmation, Markdown: sb.String()}
+}
+
+// describeObject returns the kind, name, and origin or ID of an API object.
+func describeObject(data interface{}) (kind, name, id string) {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return "", "", ""
+	}
+	kind, _ = m["kind"].(string)
+	name, _ = m["name"].(string)
+	id = fields.String(m, "origin", "id")
+	if meta, ok := m["metadata"].(map[string]interface{}); ok {
+		if s, _ := meta["name"].(string); s != "" {
+			name = s
+		}
+		if labels, ok := meta["labels"].(map[string]interface{}); ok {
+			if s := fields.String(labels, "dash0.com/origin", "dash0.com/id"); s != "" {
+				id = s
+			}
+		}
+	}
+	if spec, ok := m["spec"].(map[string]interface{}); ok && name == "" {
+		if display, ok := spec["display"].(map[string]interface{}); ok {
+			name, _ = display["name"].(string)
+		}
+	}
+	return kind, name, id
+}
@@ -0,0 +1,218 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/internal/audit"
+)
+
+// debugBodyLimit caps the bytes of a request or response body written to
+// the debug log.
+const debugBodyLimit = 4096
+
+// secretParams are query parameter names that carry a webhook secret
+// without a secret-looking name (e.g. Azure's code, signed URLs' sig).
+var secretParams = map[string]bool{"key": true, "sig": true, "signature": true, "code": true}
+
+// logRequest writes a request to the debug log with its secrets redacted.
+func logRequest(req *http.Request) {
+	attrs := []any{"method", req.Method, "url", redactURL(req.URL.String(), false), "headers", redactHeaders(req.Header)}
+	if body := requestBody(req); len(body) > 0 {
+		attrs = append(attrs, "body", redactBody(body, req.Header))
+	}
+	slog.Debug("dash0 api request", attrs...)
+}
+
+// logResponse writes a response and its body to the debug log with their
+// secrets redacted.
+func logResponse(req *http.Request, resp *http.Response, body []byte, start time.Time) {
+	attrs := []any{
+		"method", req.Method,
+		"url", redactURL(req.URL.String(), false),
+		"status", resp.StatusCode,
+		"duration", time.Since(start).Round(time.Millisecond),
+		"headers", redactHeaders(resp.Header),
+	}
+	if len(body) > 0 {
+		attrs = append(attrs, "body", redactBody(body, resp.Header))
+	}
+	slog.Debug("dash0 api response", attrs...)
+}
+
+// requestBody returns a copy of the body of req, or nil.
+func requestBody(req *http.Request) []byte {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil
+	}
+	defer body.Close()
+	data, _ := io.ReadAll(body)
+	return data
+}
+
+// bufferBody reads the body of resp and replaces it with a copy for the
+// caller. A failed read is left to the caller to report.
+func bufferBody(resp *http.Response) []byte {
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	var body io.Reader = bytes.NewReader(data)
+	if err != nil {
+		body = io.MultiReader(body, errReader{err})
+	}
+	resp.Body = io.NopCloser(body)
+	return data
+}
+
+// errReader returns err once the data before it was read.
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
+
+// redactHeaders returns the headers for logging, with the values of secret
+// headers such as Authorization replaced.
+func redactHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for name, values := range h {
+		if audit.SecretKey(name) {
+			out[name] = audit.Redacted
+		} else {
+			out[name] = strings.Join(values, ", ")
+		}
+	}
+	return out
+}
+
+// redactBody returns a body for the debug log: redactedBody as JSON, cut
+// at debugBodyLimit.
+func redactBody(body []byte, h http.Header) string {
+	v := redactedBody(body, h)
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%d bytes of %s", len(body), h.Get("Content-Type"))
+	}
+	if len(data) > debugBodyLimit {
+		return fmt.Sprintf("%s… (%d bytes)", data[:debugBodyLimit], len(data))
+	}
+	return string(data)
+}
+
+// redactedBody decodes a JSON body and replaces its secrets, see
+// redactValue. Other bodies, such as protobuf or gzip payloads, are
+// described by their size.
+func redactedBody(body []byte, h http.Header) interface{} {
+	var v interface{}
+	if h.Get("Content-Encoding") != "" || json.Unmarshal(body, &v) != nil {
+		return fmt.Sprintf("%d bytes of %s", len(body), h.Get("Content-Type"))
+	}
+	return redactValue("", v)
+}
+
+// redactValue returns a copy of a decoded JSON value with its secrets
+// replaced: the values of secret keys, every header value of a headers
+// object or name/value list, as in synthetic check requests, and the
+// secrets of URLs. key is the key v was found under.
+func redactValue(key string, v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			switch {
+			case audit.SecretKey(k):
+				out[k] = audit.Redacted
+			case strings.EqualFold(k, "headers"):
+				out[k] = redactHeaderValues(val)
+			default:
+				out[k] = redactValue(k, val)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, val := range v {
+			out[i] = redactValue(key, val)
+		}
+		return out
+	case string:
+		if strings.HasPrefix(v, "http://") || strings.HasPrefix(v, "https://") {
+			return redactURL(v, strings.Contains(strings.ToLower(key), "webhook"))
+		}
+	}
+	return v
+}
+
+// redactHeaderValues replaces the values of a headers object, or of a list
+// of {name, value} objects, whatever their names.
+func redactHeaderValues(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k := range v {
+			out[k] = audit.Redacted
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, val := range v {
+			header, ok := val.(map[string]interface{})
+			if !ok {
+				out[i] = audit.Redacted
+				continue
+			}
+			redacted := make(map[string]interface{}, len(header))
+			for k, hv := range header {
+				redacted[k] = hv
+			}
+			if _, ok := redacted["value"]; ok {
+				redacted["value"] = audit.Redacted
+			}
+			out[i] = redacted
+		}
+		return out
+	}
+	return redactValue("headers", v)
+}
+
+// redactURL returns a URL for logging with its password and the values of
+// secret query parameters replaced. For a webhook URL, whose path often is
+// the token (e.g. https://hooks.slack.com/services/T0/B0/XXXX), the path is
+// replaced too. Strings that are not absolute URLs are returned unchanged.
+func redactURL(rawURL string, webhook bool) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), "REDACTED")
+	}
+	if webhook && u.Path != "" && u.Path != "/" {
+		u.Path, u.RawPath = "/"+audit.Redacted, "/"+audit.Redacted
+	}
+	if u.RawQuery != "" {
+		params := strings.Split(u.RawQuery, "&")
+		for i, param := range params {
+			name, _, _ := strings.Cut(param, "=")
+			if decoded, err := url.QueryUnescape(name); err == nil {
+				name = decoded
+			}
+			if audit.SecretKey(name) || secretParams[strings.ToLower(name)] {
+				params[i] = url.QueryEscape(name) + "=" + audit.Redacted
+			}
+		}
+		u.RawQuery = strings.Join(params, "&")
+	}
+	return u.String()
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/internal/config"
+)
+
+// dialFunc matches http.Transport.DialContext.
+type dialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// newTransport returns the HTTP transport for API requests. It behaves like
+// http.DefaultTransport unless the configuration asks for IPv4-only dialing or
+// a custom DNS server, which some air-gapped and enterprise networks need.
+func newTransport(cfg *config.Config) http.RoundTripper {
+	if !cfg.ForceIPv4 && cfg.DNSServer == "" {
+		return http.DefaultTransport
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = newDialer(cfg.ForceIPv4, cfg.DNSServer)
+	return transport
+}
+
+// newDialer returns a dial function that optionally restricts connections to
+// IPv4 and resolves host names through dnsServer instead of the system
+// resolver.
+func newDialer(forceIPv4 bool, dnsServer string) dialFunc {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if dnsServer != "" {
+		dialer.Resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, dnsServer)
+			},
+		}
+	}
+
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if forceIPv4 && network == "tcp" {
+			network = "tcp4"
+		}
+		return dialer.DialContext(ctx, network, address)
+	}
+}
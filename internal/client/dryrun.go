@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+type dryRunKey struct{}
+
+// WithDryRun returns a context whose writes are not sent: requests other than
+// GETs and telemetry queries return a description of the HTTP request they
+// would have made instead.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// DryRun reports whether writes made with ctx are only described, because
+// ctx was created with WithDryRun or the client runs in dry-run mode
+// (DASH0_MCP_DRY_RUN).
+func (c *Client) DryRun(ctx context.Context) bool {
+	if c.dryRun {
+		return true
+	}
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
+// DryRunRequest describes a request that was not sent because of a dry run.
+type DryRunRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Body    interface{}       `json:"body,omitempty"`
+}
+
+// dryRunResult returns the result of a write that a dry run did not send.
+// The authorization header is redacted.
+func (c *Client) dryRunResult(method, requestURL string, bodyBytes []byte, headers http.Header) *ToolResult {
+	req := DryRunRequest{
+		Method: method,
+		URL:    requestURL,
+		Headers: map[string]string{
+			"Authorization": "Bearer [REDACTED]",
+			"Accept":        "application/json",
+		},
+	}
+	for name := range headers {
+		req.Headers[name] = headers.Get(name)
+	}
+	if c.ingest {
+		if u, err := url.Parse(requestURL); err == nil {
+			if dataset := u.Query().Get("dataset"); dataset != "" {
+				req.Headers["Dash0-Dataset"] = dataset
+			}
+		}
+	}
+	switch {
+	case bodyBytes == nil:
+	case headers.Get("Content-Type") != "application/json" || headers.Get("Content-Encoding") != "":
+		// Binary or compressed bodies are described, not shown
+		req.Body = fmt.Sprintf("%d bytes of %s", len(bodyBytes), headers.Get("Content-Type"))
+		if encoding := headers.Get("Content-Encoding"); encoding != "" {
+			req.Body = fmt.Sprintf("%s, %s-encoded", req.Body, encoding)
+		}
+	default:
+		if err := json.Unmarshal(bodyBytes, &req.Body); err != nil {
+			req.Body = string(bodyBytes)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## Dry Run\n\n")
+	sb.WriteString("Nothing was changed. This request would have been sent:\n\n")
+	fmt.Fprintf(&sb, "```http\n%s %s\n", method, requestURL)
+	names := make([]string, 0, len(req.Headers))
+	for name := range req.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&sb, "%s: %s\n", name, req.Headers[name])
+	}
+	sb.WriteString("```\n")
+	if req.Body != nil {
+		body, _ := json.MarshalIndent(req.Body, "", "  ")
+		fmt.Fprintf(&sb, "\n```json\n%s\n```\n", body)
+	}
+
+	return &ToolResult{
+		Success:  true,
+		DryRun:   true,
+		Data:     map[string]interface{}{"dry_run": true, "request": req},
+		Markdown: sb.String(),
+	}
+}
+
+// skipWrite reports whether a request is a write that a dry run must not
+// send.
+func (c *Client) skipWrite(ctx context.Context, method, path string) bool {
+	return !isReadOnly(ctx, method, path) && c.DryRun(ctx)
+}
@@ -0,0 +1,378 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/npcomplete777/dash0-mcp/internal/listing/fields"
+)
+
+const (
+	// maxErrorBodyLen caps how much of a non-JSON error body is used as the
+	// error detail.
+	maxErrorBodyLen = 500
+
+	// maxRawErrorBodyLen caps the raw upstream body kept on an APIError.
+	maxRawErrorBodyLen = 4096
+)
+
+// Error codes classify failed tool calls so agents can branch on the kind of
+// failure rather than parse messages.
+const (
+	// CodeValidation means the request was rejected as invalid; fix the
+	// arguments before retrying.
+	CodeValidation = "validation_error"
+	// CodeNotFound means the resource or tool does not exist.
+	CodeNotFound = "not_found"
+	// CodeAuth means the auth token is missing, invalid, or lacks
+	// permission.
+	CodeAuth = "auth_error"
+	// CodeRateLimited means the API rate limit was hit; retry later.
+	CodeRateLimited = "rate_limited"
+	// CodeUpstream means the API or the connection to it failed, or the
+	// call timed out; retrying may succeed.
+	CodeUpstream = "upstream_error"
+)
+
+// ErrorCode returns the error code for an HTTP status.
+func ErrorCode(statusCode int) string {
+	switch {
+	case statusCode == http.StatusUnauthorized, statusCode == http.StatusForbidden:
+		return CodeAuth
+	case statusCode == http.StatusNotFound, statusCode == http.StatusGone:
+		return CodeNotFound
+	case statusCode == http.StatusTooManyRequests:
+		return CodeRateLimited
+	case statusCode == StatusClientClosedRequest, statusCode == http.StatusRequestTimeout:
+		return CodeUpstream
+	case statusCode >= 400 && statusCode < 500:
+		return CodeValidation
+	}
+	return CodeUpstream
+}
+
+// requestIDHeaders are the response headers that may carry the upstream
+// request ID, in order of preference.
+var requestIDHeaders = []string{"X-Request-Id", "X-Correlation-Id", "X-Amzn-Requestid", "X-Amzn-Trace-Id", "Traceparent"}
+
+// requestID returns the upstream request ID of a response, or "".
+func requestID(resp *http.Response) string {
+	for _, h := range requestIDHeaders {
+		if v := strings.TrimSpace(resp.Header.Get(h)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// FieldError is a problem with one field of a request body.
+type FieldError struct {
+	// Pointer is the RFC 6901 JSON pointer of the offending field, such as
+	// "/spec/plugin/kind".
+	Pointer string `json:"pointer,omitempty"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// Message returns the error as shown to the caller: the detail, the
+// offending field when known, and a hint on how to fix the request.
+func (e *APIError) Message() string {
+	msg := e.Detail
+	if msg == "" {
+		msg = e.Title
+	}
+	if e.Pointer != "" && !strings.Contains(msg, e.Pointer) {
+		msg += fmt.Sprintf(" (field %s)", e.Pointer)
+	}
+	for _, f := range e.Fields {
+		if f.Pointer == e.Pointer && strings.Contains(msg, f.Detail) {
+			continue
+		}
+		msg += fmt.Sprintf("\n- %s: %s", f.Pointer, f.Detail)
+	}
+	if e.Hint != "" {
+		msg += "\nHint: " + e.Hint
+	}
+	return msg
+}
+
+// JSON returns the error as the JSON object shown to MCP clients:
+// {"error": {"code": ..., "status_code": ..., "message": ..., ...}}, where
+// message is the Message text.
+func (e *APIError) JSON() string {
+	code := e.Code
+	if code == "" {
+		code = ErrorCode(e.StatusCode)
+	}
+	payload := map[string]interface{}{
+		"error": struct {
+			Code          string       `json:"code"`
+			StatusCode    int          `json:"status_code"`
+			Message       string       `json:"message"`
+			Pointer       string       `json:"pointer,omitempty"`
+			Fields        []FieldError `json:"fields,omitempty"`
+			Hint          string       `json:"hint,omitempty"`
+			RequestID     string       `json:"request_id,omitempty"`
+			Body          string       `json:"body,omitempty"`
+			CorrelationID string       `json:"correlation_id,omitempty"`
+		}{code, e.StatusCode, e.Message(), e.Pointer, e.Fields, e.Hint, e.RequestID, e.Body, e.CorrelationID},
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return e.Message()
+	}
+	return string(data)
+}
+
+// newAPIError normalizes an error response into an APIError. It understands
+// the payload shapes the API and its admission webhooks return (plain
+// messages, RFC 7807 problems, JSON:API error lists and Kubernetes Status
+// objects), extracts the offending field, and adds a hint for messages that
+// are hard to act on as they stand. body is the raw response body.
+func newAPIError(resp *http.Response, body []byte, result interface{}) *APIError {
+	e := &APIError{
+		Code:       ErrorCode(resp.StatusCode),
+		StatusCode: resp.StatusCode,
+		Title:      resp.Status,
+		Detail:     extractErrorDetail(result),
+		RequestID:  requestID(resp),
+		Body:       shorten(string(body), maxRawErrorBodyLen),
+	}
+	if s, ok := result.(string); ok && e.Detail == "" {
+		e.Detail = shorten(strings.TrimSpace(s), maxErrorBodyLen)
+	}
+	e.Fields = extractFieldErrors(result)
+	if len(e.Fields) == 0 {
+		e.Fields = fieldErrorsFromMessage(e.Detail)
+	}
+	if len(e.Fields) > 0 {
+		e.Pointer = e.Fields[0].Pointer
+	}
+	e.Hint = errorHint(e)
+	return e
+}
+
+// extractFieldErrors collects per-field errors from structured payloads.
+func extractFieldErrors(result interface{}) []FieldError {
+	m, ok := result.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	var found []FieldError
+	add := func(item map[string]interface{}) {
+		if p := fieldPointer(item); p != "" {
+			found = append(found, FieldError{Pointer: p, Detail: fields.String(item, "detail", "message", "msg", "title", "reason")})
+		}
+	}
+
+	// JSON:API, RFC 7807 and FastAPI style lists.
+	for _, key := range []string{"errors", "detail"} {
+		if list, ok := m[key].([]interface{}); ok {
+			for _, v := range list {
+				if item, ok := v.(map[string]interface{}); ok {
+					add(item)
+				}
+			}
+		}
+	}
+	// Kubernetes Status objects returned by admission webhooks.
+	if details, ok := m["details"].(map[string]interface{}); ok {
+		if causes, ok := details["causes"].([]interface{}); ok {
+			for _, v := range causes {
+				if item, ok := v.(map[string]interface{}); ok {
+					add(item)
+				}
+			}
+		}
+	}
+	// A single error object, nested or at the top level.
+	if item, ok := m["error"].(map[string]interface{}); ok {
+		add(item)
+	}
+	if len(found) == 0 {
+		add(m)
+	}
+	return found
+}
+
+// fieldPointer returns the JSON pointer of the field an error item refers
+// to, or "" if it names none.
+func fieldPointer(item map[string]interface{}) string {
+	if source, ok := item["source"].(map[string]interface{}); ok {
+		if p, ok := source["pointer"].(string); ok && p != "" {
+			return toPointer(p)
+		}
+		if p, ok := source["parameter"].(string); ok && p != "" {
+			return toPointer(p)
+		}
+	}
+	if loc, ok := item["loc"].([]interface{}); ok && len(loc) > 0 {
+		var parts []string
+		for i, v := range loc {
+			s := fmt.Sprint(v)
+			if i == 0 && s == "body" {
+				continue
+			}
+			parts = append(parts, escapePointer(s))
+		}
+		if len(parts) > 0 {
+			return "/" + strings.Join(parts, "/")
+		}
+	}
+	if p := fields.String(item, "pointer", "field", "path"); p != "" {
+		return toPointer(p)
+	}
+	return ""
+}
+
+// pathSegment splits a dotted field path into its names and indexes.
+var pathSegment = regexp.MustCompile(`[^.\[\]]+`)
+
+// toPointer converts a field reference to an RFC 6901 JSON pointer. It
+// accepts pointers ("/spec/kind"), URI fragments ("#/spec/kind") and dotted
+// paths with indexes ("spec.panels[0].kind").
+func toPointer(path string) string {
+	path = strings.TrimPrefix(path, "#")
+	if strings.HasPrefix(path, "/") {
+		return path
+	}
+	var parts []string
+	for _, seg := range pathSegment.FindAllString(path, -1) {
+		parts = append(parts, escapePointer(seg))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "/" + strings.Join(parts, "/")
+}
+
+// escapePointer escapes a reference token per RFC 6901.
+func escapePointer(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
+
+// k8sFieldError matches field errors in Kubernetes validation messages, such
+// as `spec.enabled: Invalid value: "yes": spec.enabled in body must be of
+// type boolean`.
+var k8sFieldError = regexp.MustCompile(`((?:metadata|spec|kind|apiVersion)(?:\.[\w-]+|\[\d+\])*): ((?:Invalid|Unsupported|Required|Duplicate) value|Forbidden|Not found|Too long|Too many)\b([^,\]]*)`)
+
+// goFieldError matches encoding/json type errors, such as `json: cannot
+// unmarshal string into Go struct field CheckRule.spec.interval of type int`.
+var goFieldError = regexp.MustCompile(`Go struct field \w+\.([\w.]+) of type`)
+
+// fieldErrorsFromMessage extracts field errors embedded in a message.
+func fieldErrorsFromMessage(msg string) []FieldError {
+	var fields []FieldError
+	for _, m := range k8sFieldError.FindAllStringSubmatch(msg, -1) {
+		fields = append(fields, FieldError{Pointer: toPointer(m[1]), Detail: strings.TrimSpace(m[2] + m[3])})
+	}
+	if m := goFieldError.FindStringSubmatch(msg); m != nil {
+		fields = append(fields, FieldError{Pointer: toPointer(m[1]), Detail: "wrong type"})
+	}
+	return fields
+}
+
+// renamedFields maps field names agents commonly borrow from other tools to
+// the names the Dash0 API uses.
+var renamedFields = map[string]string{
+	"alert":       "name",
+	"expr":        "expression",
+	"probability": "rate",
+	"percentage":  "rate",
+	"title":       "display.name",
+}
+
+var (
+	unknownFieldMsg     = regexp.MustCompile(`unknown field "?([\w.-]+)"?`)
+	unmarshalTypeMsg    = regexp.MustCompile(`cannot unmarshal (\w+) into Go (?:struct field|value of type) \S+(?: of type (\S+))?`)
+	unsupportedValueMsg = regexp.MustCompile(`Unsupported value: "?([^":]*)"?: supported values: (.*)`)
+	invalidNameMsg      = regexp.MustCompile(`RFC 1123|must consist of lower case alphanumeric`)
+	promqlParseMsg      = regexp.MustCompile(`(?i)(promql|parse error|bad_data|unexpected (?:character|identifier|end of input))`)
+	admissionMsg        = regexp.MustCompile(`admission webhook .* denied the request`)
+)
+
+// errorHint returns actionable guidance for an error, or "" if the detail
+// speaks for itself.
+func errorHint(e *APIError) string {
+	msg := e.Detail
+	if m := unknownFieldMsg.FindStringSubmatch(msg); m != nil {
+		name := m[1]
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name = name[i+1:]
+		}
+		if to, ok := renamedFields[name]; ok {
+			return fmt.Sprintf("the API does not accept %q; use %q instead", name, to)
+		}
+		return fmt.Sprintf("remove %q: it is not part of the resource schema. Call dash0_examples for a valid body", name)
+	}
+	if m := unmarshalTypeMsg.FindStringSubmatch(msg); m != nil {
+		want := jsonTypeName(m[2])
+		if want == "" {
+			return fmt.Sprintf("a field has the wrong type: the API cannot read a JSON %s there", m[1])
+		}
+		return fmt.Sprintf("a field has the wrong type: send a JSON %s instead of a %s", want, m[1])
+	}
+	if m := unsupportedValueMsg.FindStringSubmatch(msg); m != nil {
+		return fmt.Sprintf("%q is not supported here; use one of %s", m[1], strings.TrimSpace(m[2]))
+	}
+	if invalidNameMsg.MatchString(msg) {
+		return "metadata.name must be lowercase letters, digits and '-', starting and ending with a letter or digit (for example \"checkout-latency\")"
+	}
+	for _, f := range e.Fields {
+		if strings.HasPrefix(f.Detail, "Required value") {
+			return fmt.Sprintf("add the missing field %s", f.Pointer)
+		}
+	}
+	if promqlParseMsg.MatchString(msg) {
+		return "the PromQL expression does not parse; check it with dash0_alerting_check_rules_test before saving"
+	}
+	if admissionMsg.MatchString(msg) {
+		return "the resource failed server-side validation; compare it with dash0_examples for this tool"
+	}
+
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return "the auth token was rejected; check DASH0_AUTH_TOKEN"
+	case http.StatusForbidden:
+		return "the auth token lacks permission for this resource or dataset; check the token's permissions and DASH0_DATASET"
+	case http.StatusNotFound:
+		return "no resource with that origin or ID; list the resources to find it"
+	case http.StatusConflict:
+		return "a resource with that name already exists; update it instead or pick another name"
+	case http.StatusRequestEntityTooLarge:
+		return "the request body is too large; split it into smaller requests"
+	case http.StatusTooManyRequests:
+		return "the API rate limit was hit; lower DASH0_RATE_LIMIT to space out requests"
+	}
+	return ""
+}
+
+// jsonTypeName maps a Go type from an encoding/json error to the JSON type
+// the API expects.
+func jsonTypeName(goType string) string {
+	switch {
+	case goType == "":
+		return ""
+	case goType == "string":
+		return "string"
+	case goType == "bool":
+		return "boolean"
+	case strings.HasPrefix(goType, "[]"):
+		return "array"
+	case strings.HasPrefix(goType, "map["), strings.HasPrefix(goType, "struct"):
+		return "object"
+	case strings.HasPrefix(goType, "int"), strings.HasPrefix(goType, "uint"), strings.HasPrefix(goType, "float"):
+		return "number"
+	}
+	return ""
+}
+
+// shorten cuts s to at most n bytes, marking the cut.
+func shorten(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "…"
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Simulated upstream faults.
+const (
+	faultRateLimited = "rate_limited"
+	faultServerError = "server_error"
+	faultTimeout     = "timeout"
+)
+
+var faultKinds = []string{faultRateLimited, faultServerError, faultTimeout}
+
+// faultInjector fails a share of requests with a simulated 429, 500, or
+// timeout instead of sending them (DASH0_FAULT_INJECTION), to test how
+// agents and the retry logic cope with a flaky backend.
+type faultInjector struct {
+	probability float64
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+func newFaultInjector(probability float64, seed int64) *faultInjector {
+	return &faultInjector{probability: probability, rnd: rand.New(rand.NewSource(seed))}
+}
+
+// SetFaultInjection makes a share of requests, given as a probability
+// between 0 and 1, fail with a simulated 429, 500, or timeout instead of
+// being sent. 0 turns fault injection off.
+func (c *Client) SetFaultInjection(probability float64) {
+	c.faults = nil
+	if probability > 0 {
+		c.faults = newFaultInjector(probability, rand.Int63())
+	}
+}
+
+// next returns the fault to inject into the next request, or "".
+func (f *faultInjector) next() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.rnd.Float64() >= f.probability {
+		return ""
+	}
+	return faultKinds[f.rnd.Intn(len(faultKinds))]
+}
+
+// inject returns the simulated outcome of req, or a nil response and error
+// when the request is to be sent.
+func (f *faultInjector) inject(req *http.Request) (*http.Response, error) {
+	switch f.next() {
+	case faultRateLimited:
+		resp := faultResponse(req, http.StatusTooManyRequests, "rate limited")
+		resp.Header.Set("Retry-After", "1")
+		return resp, nil
+	case faultServerError:
+		return faultResponse(req, http.StatusInternalServerError, "internal server error"), nil
+	case faultTimeout:
+		return nil, &faultTimeoutError{method: req.Method, url: req.URL.Redacted()}
+	}
+	return nil, nil
+}
+
+// faultResponse returns a JSON error response as the API would send it.
+func faultResponse(req *http.Request, status int, message string) *http.Response {
+	body := fmt.Sprintf(`{"error":{"code":%d,"message":"injected fault: %s"}}`, status, message)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// faultTimeoutError is a simulated timeout. Like the http.Client's own
+// timeouts, it is a net.Error whose Timeout method reports true.
+type faultTimeoutError struct {
+	method, url string
+}
+
+func (e *faultTimeoutError) Error() string {
+	return fmt.Sprintf("%s %q: injected fault: timeout awaiting response headers", e.method, e.url)
+}
+
+func (e *faultTimeoutError) Timeout() bool   { return true }
+func (e *faultTimeoutError) Temporary() bool { return true }
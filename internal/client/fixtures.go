@@ -0,0 +1,218 @@
+package client
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// fixture is a recorded response, saved as one JSON file per request in
+// the fixture directory (DASH0_MCP_FIXTURES). Secrets are redacted as in
+// the debug log.
+type fixture struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	// RequestBody is the decoded request body, for reading the fixture.
+	RequestBody interface{}       `json:"request_body,omitempty"`
+	Status      int               `json:"status"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	// Body is the decoded JSON response body, or the raw text of another
+	// one.
+	Body interface{} `json:"body,omitempty"`
+}
+
+// fixtureTransport saves the responses of the API to a directory, or
+// serves them from it instead of sending requests, for demos, integration
+// tests, and development without a Dash0 account.
+type fixtureTransport struct {
+	dir    string
+	record bool
+	// next sends the requests that are recorded.
+	next http.RoundTripper
+}
+
+// newFixtureTransport returns a transport that records the responses of
+// next to dir, or with record unset replays them from dir.
+func newFixtureTransport(dir string, record bool, next http.RoundTripper) *fixtureTransport {
+	return &fixtureTransport{dir: dir, record: record, next: next}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *fixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := requestBody(req)
+	name := fixtureName(req, body)
+	if !t.record {
+		return t.replay(req, name)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	data := bufferBody(resp)
+	if err := t.save(name, req, body, resp, data); err != nil {
+		slog.Warn("could not record fixture", "fixture", name, "error", err)
+	}
+	return resp, nil
+}
+
+// save writes the fixture of a response.
+func (t *fixtureTransport) save(name string, req *http.Request, body []byte, resp *http.Response, data []byte) error {
+	f := fixture{
+		Method:  req.Method,
+		URL:     redactURL(req.URL.String(), false),
+		Status:  resp.StatusCode,
+		Headers: map[string]string{},
+	}
+	if len(body) > 0 {
+		f.RequestBody = redactedBody(body, req.Header)
+	}
+	for _, h := range []string{"Content-Type", "Retry-After"} {
+		if v := resp.Header.Get(h); v != "" {
+			f.Headers[h] = v
+		}
+	}
+	if len(data) > 0 {
+		var v interface{}
+		if json.Unmarshal(data, &v) == nil {
+			f.Body = redactValue("", v)
+		} else {
+			f.Body = string(data)
+		}
+	}
+
+	out, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(t.dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(t.dir, name), append(out, '\n'), 0o600)
+}
+
+// replay returns the recorded response of a request: the fixture with the
+// same method, path, query, and body, or else the first one with the same
+// method and path.
+func (t *fixtureTransport) replay(req *http.Request, name string) (*http.Response, error) {
+	data, err := os.ReadFile(filepath.Join(t.dir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		matches, _ := filepath.Glob(filepath.Join(t.dir, fixturePrefix(req)+"*.json"))
+		sort.Strings(matches)
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no recorded response for %s %s in %s", req.Method, req.URL.Path, t.dir)
+		}
+		data, err = os.ReadFile(matches[0])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid fixture for %s %s: %w", req.Method, req.URL.Path, err)
+	}
+
+	header := make(http.Header, len(f.Headers))
+	for k, v := range f.Headers {
+		header.Set(k, v)
+	}
+	var body []byte
+	switch b := f.Body.(type) {
+	case nil:
+	case string:
+		if strings.Contains(header.Get("Content-Type"), "json") {
+			body, _ = json.Marshal(b)
+		} else {
+			body = []byte(b)
+		}
+	default:
+		body, _ = json.Marshal(b)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// fixtureUnsafe matches the characters of a path that are not used in
+// fixture file names.
+var fixtureUnsafe = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+// fixturePrefix returns the part of a fixture file name taken from the
+// method and path of a request, e.g. "get_api-dashboards_".
+func fixturePrefix(req *http.Request) string {
+	path := fixtureUnsafe.ReplaceAllString(strings.Trim(req.URL.Path, "/"), "-")
+	return strings.ToLower(req.Method) + "_" + path + "_"
+}
+
+// fixtureName returns the file name of the fixture of a request: the
+// prefix and a hash of the method, path, query, and body. Timestamps in
+// the query and body are left out of the hash, so a query for the last
+// hour matches its recording later on.
+func fixtureName(req *http.Request, body []byte) string {
+	query := req.URL.Query()
+	for _, values := range query {
+		for i, v := range values {
+			values[i] = withoutTimestamp(v)
+		}
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s?%s\n", req.Method, req.URL.Path, query.Encode())
+	var v interface{}
+	if json.Unmarshal(body, &v) == nil {
+		normalized, _ := json.Marshal(withoutTimestamps(v))
+		h.Write(normalized)
+	} else {
+		h.Write(body)
+	}
+	return fmt.Sprintf("%s%x.json", fixturePrefix(req), h.Sum(nil)[:6])
+}
+
+// withoutTimestamps returns a decoded JSON value with its RFC 3339
+// timestamps blanked out.
+func withoutTimestamps(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			out[k] = withoutTimestamps(val)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, val := range v {
+			out[i] = withoutTimestamps(val)
+		}
+		return out
+	case string:
+		return withoutTimestamp(v)
+	}
+	return v
+}
+
+// withoutTimestamp returns "" for an RFC 3339 timestamp and s otherwise.
+func withoutTimestamp(s string) string {
+	if _, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,229 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// hedgeSamples is the number of recent read latencies kept for the
+	// adaptive hedge delay.
+	hedgeSamples = 100
+	// hedgeMinSamples is the number of samples needed before adaptive
+	// hedging starts.
+	hedgeMinSamples = 20
+	// hedgeMinDelay is the lower bound of the adaptive hedge delay.
+	hedgeMinDelay = 50 * time.Millisecond
+	// hedgeThrottleCooldown is how long hedging stays off after the API
+	// answered 429, so duplicates don't eat into an exhausted rate budget.
+	hedgeThrottleCooldown = 30 * time.Second
+)
+
+// HedgeStats counts hedged requests.
+type HedgeStats struct {
+	// Hedged is the number of duplicate requests sent.
+	Hedged int64 `json:"hedged"`
+	// HedgeWins is the number of hedged requests answered by the duplicate.
+	HedgeWins int64 `json:"hedge_wins"`
+}
+
+// hedger sends a duplicate of a slow read request after a delay and returns
+// whichever response arrives first, cutting tail latency from sporadic slow
+// upstream responses.
+type hedger struct {
+	// fixed is the hedge delay; 0 means the p95 of recent read latencies.
+	fixed time.Duration
+
+	mu             sync.Mutex
+	samples        []time.Duration
+	next           int
+	throttledUntil time.Time
+
+	hedged    atomic.Int64
+	hedgeWins atomic.Int64
+}
+
+func newHedger(delay time.Duration) *hedger {
+	return &hedger{fixed: delay}
+}
+
+// delay returns the current hedge delay, or false if requests should not be
+// hedged right now.
+func (h *hedger) delay() (time.Duration, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if time.Now().Before(h.throttledUntil) {
+		return 0, false
+	}
+	if h.fixed > 0 {
+		return h.fixed, true
+	}
+	if len(h.samples) < hedgeMinSamples {
+		return 0, false
+	}
+
+	sorted := append([]time.Duration(nil), h.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	p95 := sorted[(len(sorted)*95)/100]
+	if p95 < hedgeMinDelay {
+		p95 = hedgeMinDelay
+	}
+	return p95, true
+}
+
+// observe records the outcome of a request.
+func (h *hedger) observe(resp *http.Response, err error, elapsed time.Duration, readOnly bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		h.throttledUntil = time.Now().Add(hedgeThrottleCooldown)
+	}
+	if !readOnly || err != nil {
+		return
+	}
+	if len(h.samples) < hedgeSamples {
+		h.samples = append(h.samples, elapsed)
+		return
+	}
+	h.samples[h.next] = elapsed
+	h.next = (h.next + 1) % hedgeSamples
+}
+
+// stats returns the hedging counters.
+func (h *hedger) stats() HedgeStats {
+	return HedgeStats{Hedged: h.hedged.Load(), HedgeWins: h.hedgeWins.Load()}
+}
+
+// hedgeAttempt is the outcome of one of the racing requests.
+type hedgeAttempt struct {
+	resp    *http.Response
+	err     error
+	elapsed time.Duration
+	index   int
+	cancel  context.CancelFunc
+}
+
+// do executes req, hedging it when it is read-only and hedging is currently
+// enabled. Requests with a body that cannot be replayed are never hedged.
+// allow, if non-nil, is asked right before the duplicate is sent and can
+// veto it, e.g. when the client-side rate limit has no room.
+func (h *hedger) do(hc *http.Client, req *http.Request, readOnly bool, allow func() bool) (*http.Response, error) {
+	delay, ok := h.delay()
+	if !readOnly || !ok || (req.Body != nil && req.GetBody == nil) {
+		start := time.Now()
+		resp, err := hc.Do(req)
+		h.observe(resp, err, time.Since(start), readOnly)
+		return resp, err
+	}
+
+	results := make(chan hedgeAttempt, 2)
+	var cancels []context.CancelFunc
+	launch := func(r *http.Request) {
+		ctx, cancel := context.WithCancel(req.Context())
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		start := time.Now()
+		go func() {
+			resp, err := hc.Do(r.WithContext(ctx))
+			results <- hedgeAttempt{resp: resp, err: err, elapsed: time.Since(start), index: index, cancel: cancel}
+		}()
+	}
+
+	launch(req)
+	pending := 1
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case a := <-results:
+		// Answered before the hedge delay: no duplicate needed.
+		h.observe(a.resp, a.err, a.elapsed, true)
+		return finishAttempt(a)
+	case <-timer.C:
+		if dup, err := cloneRequest(req); err == nil && (allow == nil || allow()) {
+			h.hedged.Add(1)
+			launch(dup)
+			pending++
+		}
+	}
+
+	var last hedgeAttempt
+	for pending > 0 {
+		a := <-results
+		pending--
+		h.observe(a.resp, a.err, a.elapsed, true)
+		if a.err != nil {
+			a.cancel()
+			last = a
+			continue
+		}
+
+		// Abort the slower request and release its response if it arrives.
+		for i, cancel := range cancels {
+			if i != a.index {
+				cancel()
+			}
+		}
+		if pending > 0 {
+			go discardAttempt(results)
+		}
+		if a.index > 0 {
+			h.hedgeWins.Add(1)
+		}
+		return finishAttempt(a)
+	}
+	return nil, last.err
+}
+
+// finishAttempt returns the attempt's response, releasing its context when
+// the response body is closed.
+func finishAttempt(a hedgeAttempt) (*http.Response, error) {
+	if a.err != nil {
+		a.cancel()
+		return nil, a.err
+	}
+	a.resp.Body = &cancelOnClose{ReadCloser: a.resp.Body, cancel: a.cancel}
+	return a.resp, nil
+}
+
+// discardAttempt closes the response of a request that lost the race.
+func discardAttempt(results <-chan hedgeAttempt) {
+	a := <-results
+	if a.resp != nil {
+		io.Copy(io.Discard, a.resp.Body)
+		a.resp.Body.Close()
+	}
+	a.cancel()
+}
+
+// cloneRequest copies req with a fresh body for a duplicate send.
+func cloneRequest(req *http.Request) (*http.Request, error) {
+	dup := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		dup.Body = body
+	}
+	return dup, nil
+}
+
+// cancelOnClose cancels the request context once the body has been consumed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
@@ -0,0 +1,166 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/npcomplete777/dash0-mcp/internal/config"
+)
+
+// Telemetry signals accepted by Ingest.
+const (
+	SignalSpans = "spans"
+	SignalLogs  = "logs"
+)
+
+// RawBody is a request body that is sent as is instead of being marshaled as
+// JSON, such as an OTLP protobuf payload or a gzip-compressed one.
+type RawBody struct {
+	Data        []byte
+	ContentType string
+	// ContentEncoding is the Content-Encoding header, e.g. "gzip", or empty
+	// for an uncompressed body.
+	ContentEncoding string
+}
+
+// Content encodings of the payload of a send tool, accepted by
+// EncodePayload.
+const (
+	EncodingJSON         = "json"
+	EncodingJSONGzip     = "json_gzip"
+	EncodingProtobuf     = "protobuf"
+	EncodingProtobufGzip = "protobuf_gzip"
+)
+
+// IngestEncodings lists the content encodings, in the order they are
+// documented.
+var IngestEncodings = []string{EncodingJSON, EncodingJSONGzip, EncodingProtobuf, EncodingProtobufGzip}
+
+// gzipMagic starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// EncodePayload prepares the body of a send tool for Ingest. With json (or
+// no encoding) the OTLP JSON body is returned unchanged. With protobuf, body
+// is a base64 string of a binary OTLP export request, sent as
+// application/x-protobuf. The _gzip encodings compress the payload before it
+// is sent; a protobuf payload that is already gzip-compressed is sent as is.
+func EncodePayload(body interface{}, encoding string) (interface{}, error) {
+	var raw RawBody
+	switch encoding {
+	case "", EncodingJSON:
+		return body, nil
+	case EncodingJSONGzip:
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal body: %w", err)
+		}
+		raw = RawBody{Data: data, ContentType: "application/json"}
+	case EncodingProtobuf, EncodingProtobufGzip:
+		s, ok := body.(string)
+		if !ok {
+			return nil, fmt.Errorf("with content_encoding %s, body must be a base64 string of an OTLP protobuf payload", encoding)
+		}
+		data, err := decodeBase64(s)
+		if err != nil {
+			return nil, fmt.Errorf("body is not valid base64: %v", err)
+		}
+		if len(data) == 0 {
+			return nil, fmt.Errorf("body is an empty protobuf payload")
+		}
+		raw = RawBody{Data: data, ContentType: "application/x-protobuf"}
+		if bytes.HasPrefix(data, gzipMagic) {
+			raw.ContentEncoding = "gzip"
+			return raw, nil
+		}
+	default:
+		return nil, fmt.Errorf("content_encoding must be one of %s, got %q", strings.Join(IngestEncodings, ", "), encoding)
+	}
+
+	if strings.HasSuffix(encoding, "_gzip") {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(raw.Data); err != nil {
+			return nil, fmt.Errorf("failed to compress body: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return nil, fmt.Errorf("failed to compress body: %w", err)
+		}
+		raw.Data, raw.ContentEncoding = buf.Bytes(), "gzip"
+	}
+	return raw, nil
+}
+
+// decodeBase64 decodes standard or URL-safe base64, padded or not, ignoring
+// line breaks.
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.Join(strings.Fields(s), "")
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err == nil {
+		return data, nil
+	}
+	for _, enc := range []*base64.Encoding{base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if data, err := enc.DecodeString(s); err == nil {
+			return data, nil
+		}
+	}
+	return nil, err
+}
+
+type ingestionKey struct{}
+
+// isIngestion reports whether ctx is that of a request sent by Ingest.
+func isIngestion(ctx context.Context) bool {
+	ingestion, _ := ctx.Value(ingestionKey{}).(bool)
+	return ingestion
+}
+
+// ingestPaths maps a signal to its path on the API and its OTLP/HTTP path on
+// a separate ingestion endpoint.
+var ingestPaths = map[string]struct{ api, otlp string }{
+	SignalSpans: {api: "/api/spans", otlp: "/v1/traces"},
+	SignalLogs:  {api: "/api/logs", otlp: "/v1/logs"},
+}
+
+// newIngressClient creates the client for the ingestion endpoint of cfg. It
+// shares nothing with the API client but the usage counters, which the
+// caller sets.
+func newIngressClient(cfg *config.Config) *Client {
+	ic := *cfg
+	ic.BaseURL, ic.AuthToken, ic.AuthTokenFile = cfg.IngressURL, cfg.IngressToken, ""
+	ic.IngressURL, ic.IngressToken, ic.Targets = "", "", nil
+	ic.CacheTTL, ic.Hedge, ic.CallBudget = 0, false, 0
+	c := New(&ic)
+	c.ingest = true
+	return c
+}
+
+// Ingest sends an OTLP payload of the given signal to Dash0: a JSON value,
+// or a RawBody for protobuf or compressed payloads. With an
+// ingestion endpoint configured (DASH0_INGRESS_URL), it is posted to the
+// endpoint's OTLP/HTTP path with the ingestion token; otherwise it goes to
+// the API's /api/spans or /api/logs.
+func (c *Client) Ingest(ctx context.Context, signal string, body interface{}) *ToolResult {
+	paths, ok := ingestPaths[signal]
+	if !ok {
+		return ErrorResult(http.StatusBadRequest, fmt.Sprintf("unknown telemetry signal %q", signal))
+	}
+	if c.ingress != nil {
+		return c.ingress.Post(ctx, paths.otlp, body)
+	}
+	return c.Post(context.WithValue(ctx, ingestionKey{}, true), paths.api, body)
+}
+
+// IngressURL returns the ingestion endpoint, or "" when telemetry is sent
+// through the API.
+func (c *Client) IngressURL() string {
+	if c.ingress == nil {
+		return ""
+	}
+	return c.ingress.baseURL
+}
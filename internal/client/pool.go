@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"sync"
+)
+
+// Parallel calls fn for each index in [0, n), running at most the client's
+// configured concurrency at once. Results are the caller's to collect, e.g.
+// into a slice indexed by i. It returns the first failed result, or nil when
+// every call succeeded; after a failure, calls not yet started are skipped
+// and the context passed to running calls is cancelled.
+func (c *Client) Parallel(ctx context.Context, n int, fn func(ctx context.Context, i int) *ToolResult) *ToolResult {
+	return Parallel(ctx, n, c.concurrency, fn)
+}
+
+// Parallel calls fn for each index in [0, n) with at most limit calls in
+// flight; a limit below 1 runs the calls one at a time. See Client.Parallel.
+func Parallel(ctx context.Context, n, limit int, fn func(ctx context.Context, i int) *ToolResult) *ToolResult {
+	if limit < 1 {
+		limit = 1
+	}
+	if limit > n {
+		limit = n
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu     sync.Mutex
+		next   int
+		failed *ToolResult
+		wg     sync.WaitGroup
+	)
+	// claim hands out the next index, or -1 when all are taken or a call
+	// has failed.
+	claim := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		if failed != nil || next >= n || runCtx.Err() != nil {
+			return -1
+		}
+		i := next
+		next++
+		return i
+	}
+
+	for w := 0; w < limit; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := claim(); i >= 0; i = claim() {
+				result := fn(runCtx, i)
+				if result == nil || result.Success {
+					continue
+				}
+				mu.Lock()
+				if failed == nil {
+					failed = result
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if failed == nil && next < n {
+		// The tool call ended before every request was started.
+		return contextErrorResult(ctx)
+	}
+	return failed
+}
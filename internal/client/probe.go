@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// Access is whether the auth token may write to Dash0, as found by
+// ProbeWrite.
+type Access string
+
+const (
+	// AccessAllowed means the token may create, update, and delete objects.
+	AccessAllowed Access = "allowed"
+	// AccessDenied means the token may only read.
+	AccessDenied Access = "denied"
+	// AccessUnknown means the probe failed or got an answer that does not
+	// tell, such as a 404.
+	AccessUnknown Access = "unknown"
+	// AccessUnchecked means no probe was sent, because the client is
+	// read-only or in dry-run mode, or because none was asked for.
+	AccessUnchecked Access = "unchecked"
+)
+
+// writeProbePath is the prefix of the view ProbeWrite deletes; a random
+// suffix makes sure it does not exist.
+const writeProbePath = "/api/views/dash0-mcp-write-probe-"
+
+// ProbeWrite finds out whether the auth token may write by deleting a view
+// that does not exist. A 401 or 403 means the token may only read. A 404 is
+// unknown: nothing documents that the API checks permissions before it
+// looks the view up, so it does not show that the token may write. Nothing
+// is changed either way. The probe is sent once, bypassing the
+// cache and delete confirmation. Read-only and dry-run clients send nothing.
+func (c *Client) ProbeWrite(ctx context.Context) Access {
+	if c.readOnly || c.DryRun(ctx) {
+		return AccessUnchecked
+	}
+
+	requestURL := c.addDatasetQueryParam(c.baseURL + writeProbePath + NewCorrelationID())
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, requestURL, nil)
+	if err != nil {
+		return AccessUnknown
+	}
+	req.Header.Set("Authorization", "Bearer "+c.authToken())
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.do(req, false)
+	if err != nil {
+		return AccessUnknown
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return AccessDenied
+	case resp.StatusCode < 300:
+		return AccessAllowed
+	}
+	return AccessUnknown
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// rateLimitHeaders are the response headers that report the remaining API
+// quota, in the order they are logged.
+var rateLimitHeaders = []string{
+	"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset",
+	"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy",
+	"Retry-After",
+}
+
+// rateLimiter is a token bucket that spaces out API requests so aggressive
+// agent loops stay below the API's rate limits instead of running into 429s.
+type rateLimiter struct {
+	// rate is the number of tokens added per second.
+	rate float64
+	// burst is the bucket size: how many requests may be sent at once after
+	// a quiet period.
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter returns a limiter for rps requests per second. A burst
+// below 1 defaults to one second's worth of requests.
+func newRateLimiter(rps float64, burst int) *rateLimiter {
+	b := float64(burst)
+	if b < 1 {
+		b = math.Max(1, math.Ceil(rps))
+	}
+	return &rateLimiter{rate: rps, burst: b, tokens: b, last: time.Now()}
+}
+
+// refill adds the tokens accrued since the last call. mu must be held.
+func (l *rateLimiter) refill(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens = math.Min(l.burst, l.tokens+elapsed.Seconds()*l.rate)
+		l.last = now
+	}
+}
+
+// reserve takes a token and returns how long to wait before it may be used.
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(now)
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// release returns an unused token.
+func (l *rateLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens = math.Min(l.burst, l.tokens+1)
+}
+
+// wait blocks until a request may be sent or ctx ends, and returns how long
+// it waited.
+func (l *rateLimiter) wait(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+	delay := l.reserve(start)
+	if delay <= 0 {
+		return 0, nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.release()
+		return time.Since(start), ctx.Err()
+	case <-timer.C:
+		return time.Since(start), nil
+	}
+}
+
+// tryTake takes a token only if one is available now. It gates optional
+// requests, such as hedges, that are not worth waiting for.
+func (l *rateLimiter) tryTake() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(time.Now())
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// logRateLimit logs the quota headers of a response at debug level.
+func logRateLimit(req *http.Request, resp *http.Response) {
+	if resp == nil || !slog.Default().Enabled(req.Context(), slog.LevelDebug) {
+		return
+	}
+	var attrs []any
+	for _, h := range rateLimitHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			attrs = append(attrs, h, v)
+		}
+	}
+	if len(attrs) == 0 {
+		return
+	}
+	attrs = append([]any{"method", req.Method, "path", req.URL.Path, "status", resp.StatusCode}, attrs...)
+	slog.Debug("dash0 api rate limit", attrs...)
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/npcomplete777/dash0-mcp/internal/config"
+)
+
+// tokenSource holds the auth token of a client. A token read from a file
+// (DASH0_AUTH_TOKEN_FILE) is read again when the API rejects it and on
+// ReloadToken, so tokens rotated by a secrets manager are picked up without
+// a restart. Targets and the ingestion client that inherit the token share
+// the source.
+type tokenSource struct {
+	mu    sync.RWMutex
+	token string
+	// file is the token file; empty for a fixed token.
+	file string
+}
+
+func newTokenSource(token, file string) *tokenSource {
+	return &tokenSource{token: token, file: file}
+}
+
+// get returns the current token.
+func (s *tokenSource) get() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.token
+}
+
+// reload reads the token file again and reports whether the token changed.
+// A fixed token never changes; a file that cannot be read keeps the current
+// token.
+func (s *tokenSource) reload() (bool, error) {
+	if s.file == "" {
+		return false, nil
+	}
+	token, err := config.ReadTokenFile(s.file)
+	if err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := token != s.token
+	s.token = token
+	return changed, nil
+}
+
+// refresh reloads the token after a request sent with used was rejected. It
+// returns the token to retry with, or "" when there is none: the token file
+// holds the same token, or there is no file.
+func (s *tokenSource) refresh(used string) string {
+	if s.file == "" {
+		return ""
+	}
+	if _, err := s.reload(); err != nil {
+		slog.Warn("could not reload auth token", "file", s.file, "error", err)
+	}
+	// Another request may have reloaded it already
+	if token := s.get(); token != used {
+		return token
+	}
+	return ""
+}
+
+// authToken returns the token requests are sent with.
+func (c *Client) authToken() string {
+	return c.token.get()
+}
+
+// ReloadToken reads the auth token file again, e.g. on SIGHUP, and reports
+// whether the token changed. Without a token file it does nothing.
+func (c *Client) ReloadToken() (bool, error) {
+	return c.token.reload()
+}
+
+// withToken returns a copy of a rejected request with another token, or nil
+// if its body cannot be sent again.
+func withToken(req *http.Request, token string) *http.Request {
+	retry := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil
+		}
+		retry.Body = body
+	}
+	retry.Header.Set("Authorization", "Bearer "+token)
+	return retry
+}
+
+// usedToken returns the bearer token a request was sent with.
+func usedToken(req *http.Request) string {
+	return strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+}
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceStderr is the DASH0_MCP_TRACE_FILE value that writes the trace to
+// standard error.
+const TraceStderr = "stderr"
+
+// Trace appends every API request and its response to a JSONL file, with
+// secrets redacted as in the debug log, so failed tool calls can be
+// inspected and replayed offline. A nil Trace records nothing.
+type Trace struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// TraceEntry is one line of the trace: a request and its response, or the
+// error that prevented one.
+type TraceEntry struct {
+	Time time.Time `json:"time"`
+	// CorrelationID is shared by the requests of one tool call and
+	// reported in its error.
+	CorrelationID  string            `json:"correlation_id"`
+	Method         string            `json:"method"`
+	URL            string            `json:"url"`
+	RequestHeaders map[string]string `json:"request_headers"`
+	// RequestBody is the decoded JSON body, or a description of a binary
+	// one.
+	RequestBody     interface{}       `json:"request_body,omitempty"`
+	Status          int               `json:"status,omitempty"`
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+	ResponseBody    interface{}       `json:"response_body,omitempty"`
+	DurationMs      int64             `json:"duration_ms"`
+	Error           string            `json:"error,omitempty"`
+}
+
+// OpenTrace opens the trace at dest: TraceStderr (or "-") for standard
+// error, otherwise a file path, which is created if needed and only ever
+// appended to. An empty dest disables the trace and returns a nil Trace.
+func OpenTrace(dest string) (*Trace, error) {
+	switch dest = strings.TrimSpace(dest); dest {
+	case "":
+		return nil, nil
+	case TraceStderr, "-":
+		return NewTrace(os.Stderr), nil
+	}
+	f, err := os.OpenFile(dest, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trace file: %w", err)
+	}
+	return &Trace{w: f, closer: f}, nil
+}
+
+// NewTrace creates a Trace that writes to w.
+func NewTrace(w io.Writer) *Trace {
+	return &Trace{w: w}
+}
+
+// Close closes the trace file.
+func (t *Trace) Close() error {
+	if t == nil || t.closer == nil {
+		return nil
+	}
+	return t.closer.Close()
+}
+
+// record appends a request and its response, or err, to the trace. body is
+// the response body, already read.
+func (t *Trace) record(req *http.Request, resp *http.Response, body []byte, err error, start time.Time) {
+	if t == nil {
+		return
+	}
+	id := CorrelationID(req.Context())
+	if id == "" {
+		id = NewCorrelationID()
+	}
+	entry := TraceEntry{
+		Time:           start.UTC(),
+		CorrelationID:  id,
+		Method:         req.Method,
+		URL:            redactURL(req.URL.String(), false),
+		RequestHeaders: redactHeaders(req.Header),
+		DurationMs:     time.Since(start).Milliseconds(),
+	}
+	if data := requestBody(req); len(data) > 0 {
+		entry.RequestBody = redactedBody(data, req.Header)
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	} else {
+		entry.Status = resp.StatusCode
+		entry.ResponseHeaders = redactHeaders(resp.Header)
+		if len(body) > 0 {
+			entry.ResponseBody = redactedBody(body, resp.Header)
+		}
+	}
+
+	line, mErr := json.Marshal(entry)
+	if mErr != nil {
+		slog.Error("could not encode trace entry", "error", mErr)
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, wErr := t.w.Write(append(line, '\n')); wErr != nil {
+		slog.Error("could not write trace file", "error", wErr)
+	}
+}
+
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a context whose API requests are recorded in
+// the trace under id.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID returns the correlation ID set with WithCorrelationID, or
+// "".
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// NewCorrelationID returns a random correlation ID.
+func NewCorrelationID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,156 @@
+package client
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/internal/selftel"
+)
+
+// Usage reports the upstream API usage of a session.
+type Usage struct {
+	// Since is when the counters started.
+	Since time.Time `json:"since"`
+	// Calls is the number of HTTP requests sent, including retries. Hedged
+	// duplicates are counted in Hedge.
+	Calls int64 `json:"calls"`
+	// Errors is the number of requests that failed or answered with a 4xx
+	// or 5xx status.
+	Errors int64 `json:"errors"`
+	// BytesSent and BytesReceived count request and response bodies.
+	BytesSent     int64 `json:"bytes_sent"`
+	BytesReceived int64 `json:"bytes_received"`
+	// RateLimitWaits is the number of requests the client-side rate limiter
+	// delayed, and RateLimitWait the total time they waited.
+	RateLimitWaits int64         `json:"rate_limit_waits"`
+	RateLimitWait  time.Duration `json:"rate_limit_wait_ns"`
+	// Budget is the soft limit on Calls; 0 means none.
+	Budget int64 `json:"budget,omitempty"`
+
+	Cache CacheStats `json:"cache"`
+	Hedge HedgeStats `json:"hedge"`
+	// Tools counts the session's tool calls; it is only set when the
+	// client has a recorder (SetTelemetry).
+	Tools []selftel.ToolStats `json:"tools,omitempty"`
+}
+
+// OverBudget reports whether the session made more calls than its budget.
+func (u Usage) OverBudget() bool {
+	return u.Budget > 0 && u.Calls > u.Budget
+}
+
+// CacheHitRate returns the share of cache lookups answered from the cache,
+// or -1 if there were none.
+func (u Usage) CacheHitRate() float64 {
+	total := u.Cache.Hits + u.Cache.Misses
+	if total == 0 {
+		return -1
+	}
+	return float64(u.Cache.Hits) / float64(total)
+}
+
+// usageCounters accumulates Usage. A client and its targets share one set of
+// counters, so the session's usage covers every organization it talks to.
+type usageCounters struct {
+	since  time.Time
+	budget atomic.Int64
+	warned atomic.Bool
+
+	calls          atomic.Int64
+	errors         atomic.Int64
+	bytesSent      atomic.Int64
+	bytesReceived  atomic.Int64
+	rateLimitWaits atomic.Int64
+	rateLimitWait  atomic.Int64
+}
+
+func newUsageCounters(budget int) *usageCounters {
+	u := &usageCounters{since: time.Now()}
+	u.budget.Store(int64(budget))
+	return u
+}
+
+// call records a request being sent. When the session goes over its budget
+// a warning is logged once; requests are not blocked.
+func (u *usageCounters) call(req *http.Request) {
+	if u == nil {
+		return
+	}
+	n := u.calls.Add(1)
+	if req.ContentLength > 0 {
+		u.bytesSent.Add(req.ContentLength)
+	}
+	if budget := u.budget.Load(); budget > 0 && n > budget && u.warned.CompareAndSwap(false, true) {
+		slog.Warn("session API call budget exceeded", "budget", budget, "calls", n)
+	}
+}
+
+// response records the outcome of a request and counts the bytes read from
+// the response body.
+func (u *usageCounters) response(resp *http.Response, err error) {
+	if u == nil {
+		return
+	}
+	if err != nil {
+		u.errors.Add(1)
+		return
+	}
+	if resp.StatusCode >= 400 {
+		u.errors.Add(1)
+	}
+	resp.Body = &countingBody{ReadCloser: resp.Body, n: &u.bytesReceived}
+}
+
+// waited records a delay imposed by the rate limiter.
+func (u *usageCounters) waited(d time.Duration) {
+	if u != nil && d > 0 {
+		u.rateLimitWaits.Add(1)
+		u.rateLimitWait.Add(int64(d))
+	}
+}
+
+// countingBody adds the bytes read from a response body to n.
+type countingBody struct {
+	io.ReadCloser
+	n *atomic.Int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n.Add(int64(n))
+	return n, err
+}
+
+// Usage returns the upstream API usage since the client was created.
+func (c *Client) Usage() Usage {
+	u := c.usage
+	if u == nil {
+		return Usage{Cache: c.CacheStats(), Hedge: c.HedgeStats(), Tools: c.telemetry.ToolStats()}
+	}
+	return Usage{
+		Since:          u.since,
+		Calls:          u.calls.Load(),
+		Errors:         u.errors.Load(),
+		BytesSent:      u.bytesSent.Load(),
+		BytesReceived:  u.bytesReceived.Load(),
+		RateLimitWaits: u.rateLimitWaits.Load(),
+		RateLimitWait:  time.Duration(u.rateLimitWait.Load()),
+		Budget:         u.budget.Load(),
+		Cache:          c.CacheStats(),
+		Hedge:          c.HedgeStats(),
+		Tools:          c.telemetry.ToolStats(),
+	}
+}
+
+// SetCallBudget sets the soft limit on the session's upstream calls; 0
+// removes it. Exceeding the budget logs a warning but does not block calls.
+func (c *Client) SetCallBudget(budget int) {
+	if c.usage == nil {
+		c.usage = newUsageCounters(0)
+	}
+	c.usage.budget.Store(int64(budget))
+	c.usage.warned.Store(false)
+}
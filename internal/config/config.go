@@ -4,8 +4,13 @@ package config
 import (
 	"errors"
 	"fmt"
+	"math"
+	"net"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Region represents a Dash0 deployment region.
@@ -20,30 +25,225 @@ const (
 	RegionUSWest2 Region = "us-west-2"
 )
 
+const (
+	// DefaultTimeout is the HTTP timeout for Dash0 API requests.
+	DefaultTimeout = 60 * time.Second
+	// DefaultMaxRetries is the number of retries on 429 and 503 responses.
+	DefaultMaxRetries = 3
+	// DefaultMaxConcurrency is the number of API requests a tool issues at
+	// once when it fans out over many objects.
+	DefaultMaxConcurrency = 4
+	// DefaultToolTimeout is the hard limit on a single tool call, including
+	// all upstream requests it makes.
+	DefaultToolTimeout = 5 * time.Minute
+	// DefaultCacheTTL is how long GET responses are cached.
+	DefaultCacheTTL = 30 * time.Second
+	// DefaultSelfTelemetryInterval is how often self-telemetry spans are sent.
+	DefaultSelfTelemetryInterval = 30 * time.Second
+	// DefaultTarget names the top-level configuration among the targets.
+	DefaultTarget = "default"
+)
+
+// Fixture modes (DASH0_MCP_FIXTURE_MODE).
+const (
+	// FixtureReplay serves API responses from the fixture directory
+	// without sending any request.
+	FixtureReplay = "replay"
+	// FixtureRecord sends requests to the API and saves their responses to
+	// the fixture directory.
+	FixtureRecord = "record"
+)
+
 // Config holds the Dash0 MCP server configuration.
 type Config struct {
 	// BaseURL is the Dash0 API base URL.
 	BaseURL string
 	// AuthToken is the Bearer token for authentication.
 	AuthToken string
+	// AuthTokenFile is a file the auth token is read from, and read again
+	// when the API rejects it or on SIGHUP, so that short-lived tokens can
+	// be rotated without a restart. It takes precedence over AuthToken.
+	AuthTokenFile string
+	// IngressURL is the OTLP ingestion endpoint the dash0_*_send tools post
+	// to; empty means they use the API's ingestion paths on BaseURL.
+	IngressURL string
+	// IngressToken authenticates against IngressURL; it defaults to
+	// AuthToken.
+	IngressToken string
 	// Region is the Dash0 deployment region.
 	Region Region
 	// Dataset is the Dash0 dataset to use for all API calls.
 	Dataset string
 	// Debug enables debug logging.
 	Debug bool
+	// MaxResponseBytes caps query tool responses by size; 0 means unlimited.
+	MaxResponseBytes int
+	// MaxItems caps the records returned by query tools; 0 means unlimited.
+	MaxItems int
+	// Profile is the tool profile name; empty selects the default profile.
+	Profile string
+	// ConfigDir is the directory holding tools.yaml, profiles, and prompts;
+	// empty means it is resolved relative to the executable.
+	ConfigDir string
+	// Timeout is the HTTP timeout for each Dash0 API request.
+	Timeout time.Duration
+	// MaxRetries is the number of retries on 429 and 503 responses.
+	MaxRetries int
+	// MaxConcurrency bounds the parallel API requests of a single tool call.
+	MaxConcurrency int
+	// ToolTimeout is the hard limit on a single tool call.
+	ToolTimeout time.Duration
+	// ForceIPv4 restricts API connections to IPv4.
+	ForceIPv4 bool
+	// DNSServer is a host:port DNS server used instead of the system resolver;
+	// empty means the system resolver.
+	DNSServer string
+	// Hedge enables hedging of slow read-only API requests.
+	Hedge bool
+	// HedgeDelay is how long a read waits before a duplicate is sent; 0 means
+	// the p95 of recent read latencies.
+	HedgeDelay time.Duration
+	// RateLimit caps API requests per second client-side; 0 means unlimited.
+	RateLimit float64
+	// RateBurst is the number of requests that may be sent at once under the
+	// rate limit; 0 means one second's worth.
+	RateBurst int
+	// CacheTTL is how long GET responses are cached; 0 disables the cache.
+	CacheTTL time.Duration
+	// CallBudget is a soft limit on the API requests of a session: exceeding
+	// it logs a warning. 0 means no budget.
+	CallBudget int
+	// SelfTelemetry enables spans for the server's own tool calls and API
+	// requests, sent to the spans ingestion endpoint.
+	SelfTelemetry bool
+	// SelfTelemetryInterval is how often self-telemetry spans are sent.
+	SelfTelemetryInterval time.Duration
+	// StatsInterval is how often a summary of the tool calls is logged to
+	// stderr; 0 disables the summary.
+	StatsInterval time.Duration
+	// AuditLog is where mutating tool calls are recorded: a file path, or
+	// "stderr". Empty disables the audit log.
+	AuditLog string
+	// ExportDir is the directory the export tools write files to; their
+	// paths are relative to it. Empty disables writing files.
+	ExportDir string
+	// TraceFile is where every API request and response is recorded, with
+	// secrets redacted: a file path, or "stderr". Empty disables the trace.
+	TraceFile string
+	// Fixtures is a directory of recorded API responses; empty sends every
+	// request to the API.
+	Fixtures string
+	// FixtureMode is FixtureReplay or FixtureRecord when Fixtures is set.
+	FixtureMode string
+	// DryRun makes create, update, delete, and send tools report the HTTP
+	// request they would send instead of sending it.
+	DryRun bool
+	// ReadOnly makes the client reject creates, updates, deletes, and sends
+	// regardless of which tools are enabled.
+	ReadOnly bool
+	// ConfirmDeletes makes delete tools return a confirmation token and a
+	// summary of the object first, and delete only when called again with
+	// the token.
+	ConfirmDeletes bool
+	// StartupCheck validates the auth token against the API at startup. It
+	// is on by default.
+	StartupCheck bool
+	// DisableDeniedWrites makes the startup check also probe whether the
+	// token may write, and disables the tools that create, update, delete,
+	// or send when it may only read.
+	DisableDeniedWrites bool
+	// FaultInjection is the probability, between 0 and 1, that an API
+	// request fails with a simulated 429, 500, or timeout instead of being
+	// sent. 0 disables fault injection.
+	FaultInjection float64
+	// HTTPAddr is the address the MCP server listens on over HTTP, e.g.
+	// 127.0.0.1:8080; empty means it is served over stdio.
+	HTTPAddr string
+	// HTTPToken is the bearer token the HTTP transport requires; empty means
+	// it accepts every request, which is only allowed on a loopback address.
+	HTTPToken string
+	// WebhookToken enables the alert webhook of the HTTP transport and is
+	// the token its senders authenticate with.
+	WebhookToken string
+	// WebhookLabels is a "key=value,..." filter on the labels of the alerts
+	// the webhook forwards; empty forwards every alert.
+	WebhookLabels string
+	// Targets are additional named Dash0 targets from the config file, used
+	// by tools that work across organizations or datasets.
+	Targets map[string]Target
+	// ConfigFile is the path of the config file that was loaded, if any.
+	ConfigFile string
+}
+
+// Target is a named Dash0 organization and dataset.
+type Target struct {
+	// BaseURL is the Dash0 API base URL.
+	BaseURL string
+	// AuthToken is the Bearer token for authentication.
+	AuthToken string
+	// Dataset is the dataset requests are sent to; empty means the default.
+	Dataset string
 }
 
-// Load reads configuration from environment variables.
+// Load reads configuration from the optional config file (see ConfigFilePath)
+// and environment variables, with environment variables taking precedence.
 // Environment variables:
 //   - DASH0_AUTH_TOKEN (required): Bearer token for API authentication
+//   - DASH0_AUTH_TOKEN_FILE (optional): File to read the token from instead, re-read on 401 and SIGHUP
 //   - DASH0_REGION (optional): Region (us-west-2, us-east-1, eu-west-1), defaults to us-west-2
 //   - DASH0_BASE_URL (optional): Override the base URL (for custom deployments)
+//   - DASH0_INGRESS_URL (optional): OTLP ingestion endpoint for the send tools, e.g.
+//     https://ingress.eu-west-1.aws.dash0.com; DASH0_INGESS_URL is accepted too
+//   - DASH0_INGRESS_TOKEN (optional): Token for the ingestion endpoint, defaults to DASH0_AUTH_TOKEN
 //   - DASH0_DATASET (optional): Dataset to use for all API calls
 //   - DASH0_DEBUG (optional): Enable debug logging
+//   - DASH0_MAX_RESPONSE_BYTES (optional): Default size cap for query tool responses
+//   - DASH0_MAX_ITEMS (optional): Default record cap for query tool responses
+//   - DASH0_HTTP_TIMEOUT (optional): HTTP timeout as a duration (e.g. 30s) or seconds;
+//     DASH0_TIMEOUT is accepted as an older name
+//   - DASH0_MAX_RETRIES (optional): Retries on 429 and 503 responses
+//   - DASH0_MAX_CONCURRENCY (optional): Parallel API requests per tool call
+//   - DASH0_TOOL_TIMEOUT (optional): Hard limit on a single tool call, e.g. 2m
+//   - DASH0_FORCE_IPV4 (optional): Only dial IPv4 addresses
+//   - DASH0_DNS_SERVER (optional): DNS server (host or host:port) to resolve the API host with
+//   - DASH0_HEDGE_DELAY (optional): Hedge slow reads after a duration, or "auto" for the recent p95
+//   - DASH0_RATE_LIMIT (optional): Client-side cap on API requests per second
+//   - DASH0_RATE_BURST (optional): Requests that may be sent at once under the rate limit
+//   - DASH0_CACHE_TTL (optional): How long GET responses are cached, e.g. 1m, or "off"
+//   - DASH0_CALL_BUDGET (optional): Soft limit on API requests per session
+//   - DASH0_SELF_TELEMETRY (optional): Send spans for the server's own tool calls and API requests
+//   - DASH0_SELF_TELEMETRY_INTERVAL (optional): How often those spans are sent, e.g. 1m
+//   - DASH0_MCP_STATS_INTERVAL (optional): Log a summary of the tool calls to stderr this often, e.g. 10m
+//   - DASH0_MCP_PROFILE (optional): Tool profile name
+//   - DASH0_MCP_CONFIG_DIR (optional): Directory with tools.yaml and profiles
+//   - DASH0_MCP_AUDIT_LOG (optional): JSONL file, or "stderr", recording mutating tool calls
+//   - DASH0_MCP_EXPORT_DIR (optional): Directory the export tools may write files to
+//   - DASH0_MCP_TRACE_FILE (optional): JSONL file, or "stderr", recording every API request and response
+//   - DASH0_MCP_FIXTURES (optional): Directory of recorded API responses to replay instead of calling the API
+//   - DASH0_MCP_FIXTURE_MODE (optional): "replay" (default) or "record" responses to DASH0_MCP_FIXTURES
+//   - DASH0_MCP_DRY_RUN (optional): Report writes instead of sending them
+//   - DASH0_MCP_READ_ONLY (optional): Reject writes in the client
+//   - DASH0_MCP_CONFIRM_DELETES (optional): Require a confirmation token for deletes
+//   - DASH0_MCP_STARTUP_CHECK (optional): Validate the token against the API at startup, default true
+//   - DASH0_MCP_DISABLE_DENIED_WRITES (optional): Disable mutating tools when the token may only read
+//   - DASH0_MCP_HTTP_ADDR (optional): Serve MCP over HTTP on this address instead of stdio
+//   - DASH0_MCP_HTTP_TOKEN (optional): Bearer token required by the HTTP transport
+//   - DASH0_WEBHOOK_TOKEN (optional): Enables the /webhooks/dash0 alert receiver with this token
+//   - DASH0_WEBHOOK_LABELS (optional): Only forward alerts with these labels, e.g. severity=critical
+//   - DASH0_FAULT_INJECTION (optional): Probability (0-1) of simulated upstream failures, for testing
+//   - DASH0_CONFIG_FILE (optional): Config file path, default ~/.dash0-mcp/config.yaml
 func Load() (*Config, error) {
-	regionEnv := coalesce(os.Getenv("DASH0_REGION"), string(RegionUSWest2))
-	baseURL := os.Getenv("DASH0_BASE_URL")
+	path := ConfigFilePath()
+	fc, err := LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if fc == nil {
+		fc, path = &FileConfig{}, ""
+	}
+
+	regionEnv := coalesce(os.Getenv("DASH0_REGION"), fc.Region, string(RegionUSWest2))
+	baseURL := coalesce(os.Getenv("DASH0_BASE_URL"), fc.BaseURL)
 
 	// Handle case where full URL is passed as DASH0_REGION
 	if strings.HasPrefix(regionEnv, "api.") || strings.HasPrefix(regionEnv, "https://") {
@@ -64,11 +264,132 @@ func Load() (*Config, error) {
 	}
 
 	cfg := &Config{
-		AuthToken: coalesce(os.Getenv("DASH0_AUTH_TOKEN"), os.Getenv("DASH0_TOKEN")),
-		Region:    Region(regionEnv),
-		BaseURL:   baseURL,
-		Dataset:   os.Getenv("DASH0_DATASET"),
-		Debug:     parseBool(os.Getenv("DASH0_DEBUG")),
+		AuthToken:  coalesce(os.Getenv("DASH0_AUTH_TOKEN"), os.Getenv("DASH0_TOKEN"), fc.AuthToken),
+		Region:     Region(regionEnv),
+		BaseURL:    baseURL,
+		Dataset:    coalesce(os.Getenv("DASH0_DATASET"), fc.Dataset),
+		Profile:    coalesce(os.Getenv("DASH0_MCP_PROFILE"), fc.Profile),
+		ConfigDir:  coalesce(os.Getenv("DASH0_MCP_CONFIG_DIR"), fc.ConfigDir),
+		AuditLog:   coalesce(os.Getenv("DASH0_MCP_AUDIT_LOG"), fc.AuditLog),
+		ExportDir:  coalesce(os.Getenv("DASH0_MCP_EXPORT_DIR"), fc.ExportDir),
+		TraceFile:  coalesce(os.Getenv("DASH0_MCP_TRACE_FILE"), fc.TraceFile),
+		Fixtures:   coalesce(os.Getenv("DASH0_MCP_FIXTURES"), fc.Fixtures),
+		ConfigFile: path,
+	}
+
+	cfg.AuthTokenFile = coalesce(os.Getenv("DASH0_AUTH_TOKEN_FILE"), fc.AuthTokenFile)
+	if cfg.AuthTokenFile != "" {
+		if cfg.AuthToken, err = ReadTokenFile(cfg.AuthTokenFile); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg.HTTPAddr = coalesce(os.Getenv("DASH0_MCP_HTTP_ADDR"), fc.HTTPAddr)
+	cfg.HTTPToken = coalesce(os.Getenv("DASH0_MCP_HTTP_TOKEN"), fc.HTTPToken)
+	cfg.WebhookToken = coalesce(os.Getenv("DASH0_WEBHOOK_TOKEN"), fc.WebhookToken)
+	cfg.WebhookLabels = coalesce(os.Getenv("DASH0_WEBHOOK_LABELS"), fc.WebhookLabels)
+
+	cfg.IngressURL = strings.TrimSuffix(coalesce(os.Getenv("DASH0_INGRESS_URL"), os.Getenv("DASH0_INGESS_URL"), fc.IngressURL), "/")
+	if cfg.IngressURL != "" {
+		cfg.IngressToken = coalesce(os.Getenv("DASH0_INGRESS_TOKEN"), fc.IngressToken, cfg.AuthToken)
+	}
+
+	if debug := os.Getenv("DASH0_DEBUG"); debug != "" || fc.Debug == nil {
+		cfg.Debug = parseBool(debug)
+	} else {
+		cfg.Debug = *fc.Debug
+	}
+	if force := os.Getenv("DASH0_FORCE_IPV4"); force != "" || fc.ForceIPv4 == nil {
+		cfg.ForceIPv4 = parseBool(force)
+	} else {
+		cfg.ForceIPv4 = *fc.ForceIPv4
+	}
+	if cfg.DNSServer, err = parseDNSServer(coalesce(os.Getenv("DASH0_DNS_SERVER"), fc.DNSServer)); err != nil {
+		return nil, err
+	}
+
+	if cfg.Hedge, cfg.HedgeDelay, err = parseHedgeDelay(coalesce(os.Getenv("DASH0_HEDGE_DELAY"), fc.HedgeDelay)); err != nil {
+		return nil, err
+	}
+
+	if cfg.RateLimit, err = parseRateLimit(coalesce(os.Getenv("DASH0_RATE_LIMIT"), fc.RateLimit)); err != nil {
+		return nil, err
+	}
+	if cfg.RateBurst, err = parseNonNegativeInt("DASH0_RATE_BURST", fc.RateBurst, 0); err != nil {
+		return nil, err
+	}
+
+	if cfg.CacheTTL, err = parseCacheTTL(coalesce(os.Getenv("DASH0_CACHE_TTL"), fc.CacheTTL)); err != nil {
+		return nil, err
+	}
+
+	if cfg.CallBudget, err = parseNonNegativeInt("DASH0_CALL_BUDGET", fc.CallBudget, 0); err != nil {
+		return nil, err
+	}
+
+	if self := os.Getenv("DASH0_SELF_TELEMETRY"); self != "" || fc.SelfTelemetry == nil {
+		cfg.SelfTelemetry = parseBool(self)
+	} else {
+		cfg.SelfTelemetry = *fc.SelfTelemetry
+	}
+	if dryRun := os.Getenv("DASH0_MCP_DRY_RUN"); dryRun != "" || fc.DryRun == nil {
+		cfg.DryRun = parseBool(dryRun)
+	} else {
+		cfg.DryRun = *fc.DryRun
+	}
+	if readOnly := os.Getenv("DASH0_MCP_READ_ONLY"); readOnly != "" || fc.ReadOnly == nil {
+		cfg.ReadOnly = parseBool(readOnly)
+	} else {
+		cfg.ReadOnly = *fc.ReadOnly
+	}
+	if confirm := os.Getenv("DASH0_MCP_CONFIRM_DELETES"); confirm != "" || fc.ConfirmDeletes == nil {
+		cfg.ConfirmDeletes = parseBool(confirm)
+	} else {
+		cfg.ConfirmDeletes = *fc.ConfirmDeletes
+	}
+	if check := os.Getenv("DASH0_MCP_STARTUP_CHECK"); check != "" || fc.StartupCheck == nil {
+		cfg.StartupCheck = check == "" || parseBool(check)
+	} else {
+		cfg.StartupCheck = *fc.StartupCheck
+	}
+	if disable := os.Getenv("DASH0_MCP_DISABLE_DENIED_WRITES"); disable != "" || fc.DisableDeniedWrites == nil {
+		cfg.DisableDeniedWrites = parseBool(disable)
+	} else {
+		cfg.DisableDeniedWrites = *fc.DisableDeniedWrites
+	}
+	if cfg.FaultInjection, err = parseFaultInjection(coalesce(os.Getenv("DASH0_FAULT_INJECTION"), fc.FaultInjection)); err != nil {
+		return nil, err
+	}
+	if cfg.FixtureMode, err = parseFixtureMode(cfg.Fixtures, coalesce(os.Getenv("DASH0_MCP_FIXTURE_MODE"), fc.FixtureMode)); err != nil {
+		return nil, err
+	}
+	if cfg.SelfTelemetryInterval, err = parseTimeout("self_telemetry_interval", coalesce(os.Getenv("DASH0_SELF_TELEMETRY_INTERVAL"), fc.SelfTelemetryInterval), DefaultSelfTelemetryInterval); err != nil {
+		return nil, err
+	}
+	if cfg.StatsInterval, err = parseTimeout("stats_interval", coalesce(os.Getenv("DASH0_MCP_STATS_INTERVAL"), fc.StatsInterval), 0); err != nil {
+		return nil, err
+	}
+
+	if cfg.MaxResponseBytes, err = parseNonNegativeInt("DASH0_MAX_RESPONSE_BYTES", fc.MaxResponseBytes, 0); err != nil {
+		return nil, err
+	}
+	if cfg.MaxItems, err = parseNonNegativeInt("DASH0_MAX_ITEMS", fc.MaxItems, 0); err != nil {
+		return nil, err
+	}
+	if cfg.MaxRetries, err = parseNonNegativeInt("DASH0_MAX_RETRIES", fc.MaxRetries, DefaultMaxRetries); err != nil {
+		return nil, err
+	}
+	if cfg.MaxConcurrency, err = parseNonNegativeInt("DASH0_MAX_CONCURRENCY", fc.MaxConcurrency, DefaultMaxConcurrency); err != nil {
+		return nil, err
+	}
+	if cfg.MaxConcurrency == 0 {
+		cfg.MaxConcurrency = 1
+	}
+	if cfg.Timeout, err = parseTimeout("timeout", coalesce(os.Getenv("DASH0_HTTP_TIMEOUT"), os.Getenv("DASH0_TIMEOUT"), fc.HTTPTimeout, fc.Timeout), DefaultTimeout); err != nil {
+		return nil, err
+	}
+	if cfg.ToolTimeout, err = parseTimeout("tool_timeout", coalesce(os.Getenv("DASH0_TOOL_TIMEOUT"), fc.ToolTimeout), DefaultToolTimeout); err != nil {
+		return nil, err
 	}
 
 	// Derive base URL from region if not explicitly set
@@ -76,23 +397,66 @@ func Load() (*Config, error) {
 		cfg.BaseURL = cfg.deriveBaseURL()
 	}
 
+	if cfg.Targets, err = loadTargets(fc.Targets, cfg); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
+// ReadTokenFile reads an auth token from a file, without surrounding
+// whitespace. An empty file is an error.
+func ReadTokenFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("auth token file: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("auth token file %s is empty", path)
+	}
+	return token, nil
+}
+
 // Validate checks that all required configuration is present and valid.
 func (c *Config) Validate() error {
-	if c.AuthToken == "" {
-		return errors.New("DASH0_AUTH_TOKEN is required")
+	// Replayed responses need no account
+	if c.AuthToken == "" && c.FixtureMode != FixtureReplay {
+		return errors.New("DASH0_AUTH_TOKEN is required (or DASH0_AUTH_TOKEN_FILE, or auth_token in the config file)")
+	}
+	if c.FixtureMode == FixtureReplay {
+		if info, err := os.Stat(c.Fixtures); err != nil || !info.IsDir() {
+			return fmt.Errorf("fixture directory %s does not exist", c.Fixtures)
+		}
 	}
 
 	if c.BaseURL == "" {
 		return errors.New("unable to determine base URL: set DASH0_REGION or DASH0_BASE_URL")
 	}
 
-	if !strings.HasPrefix(c.BaseURL, "https://") {
+	if !secureURL(c.BaseURL) {
 		return fmt.Errorf("base URL must use HTTPS: %s", c.BaseURL)
 	}
 
+	if c.IngressURL != "" && !secureURL(c.IngressURL) {
+		return fmt.Errorf("ingress URL must use HTTPS: %s", c.IngressURL)
+	}
+
+	if c.WebhookToken != "" && c.HTTPAddr == "" {
+		return errors.New("the alert webhook is served by the HTTP transport: set DASH0_MCP_HTTP_ADDR with DASH0_WEBHOOK_TOKEN")
+	}
+
+	// Without a token, anyone who can reach the address can call tools
+	if c.HTTPAddr != "" && c.HTTPToken == "" && !loopbackAddr(c.HTTPAddr) {
+		return fmt.Errorf("DASH0_MCP_HTTP_TOKEN is required to serve MCP on %s, which is not a loopback address", c.HTTPAddr)
+	}
+
+	for name, t := range c.Targets {
+		if !secureURL(t.BaseURL) {
+			return fmt.Errorf("target %s base URL must use HTTPS: %s", name, t.BaseURL)
+		}
+	}
+
 	switch c.Region {
 	case RegionEUWest1, RegionUSEast1, RegionUSWest2:
 		// Valid regions
@@ -103,6 +467,36 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// secureURL reports whether an API URL uses HTTPS or, as the mock API of
+// cmd/mockserver does, plain HTTP on a loopback address.
+func secureURL(rawURL string) bool {
+	if strings.HasPrefix(rawURL, "https://") {
+		return true
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "http" {
+		return false
+	}
+	return loopbackHost(u.Hostname())
+}
+
+// loopbackAddr reports whether a listen address such as 127.0.0.1:8080 only
+// accepts connections from this host. An address without a host, such as
+// :8080, listens on every interface.
+func loopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	return err == nil && loopbackHost(host)
+}
+
+// loopbackHost reports whether host is localhost or a loopback IP.
+func loopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 // deriveBaseURL returns the API base URL for the configured region.
 func (c *Config) deriveBaseURL() string {
 	switch c.Region {
@@ -117,6 +511,35 @@ func (c *Config) deriveBaseURL() string {
 	}
 }
 
+// loadTargets resolves the named targets of the config file. A target
+// without a base URL or region uses the top-level base URL, and one without
+// a token uses the top-level token.
+func loadTargets(targets map[string]FileTarget, cfg *Config) (map[string]Target, error) {
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]Target, len(targets))
+	for name, ft := range targets {
+		name = strings.TrimSpace(name)
+		if name == "" || name == DefaultTarget {
+			return nil, fmt.Errorf("targets: %q is not a valid target name", name)
+		}
+		baseURL := ft.BaseURL
+		if baseURL == "" && ft.Region != "" {
+			baseURL = (&Config{Region: Region(ft.Region)}).deriveBaseURL()
+			if baseURL == "" {
+				return nil, fmt.Errorf("targets.%s: unknown region %q", name, ft.Region)
+			}
+		}
+		out[name] = Target{
+			BaseURL:   coalesce(baseURL, cfg.BaseURL),
+			AuthToken: coalesce(ft.AuthToken, cfg.AuthToken),
+			Dataset:   ft.Dataset,
+		}
+	}
+	return out, nil
+}
+
 // coalesce returns the first non-empty string.
 func coalesce(values ...string) string {
 	for _, v := range values {
@@ -132,3 +555,142 @@ func parseBool(s string) bool {
 	s = strings.ToLower(strings.TrimSpace(s))
 	return s == "true" || s == "1" || s == "yes"
 }
+
+// parseNonNegativeInt reads a non-negative integer from an environment
+// variable, falling back to the config file value and then to def when it
+// is unset.
+func parseNonNegativeInt(name string, file *int, def int) (int, error) {
+	s := strings.TrimSpace(os.Getenv(name))
+	if s == "" {
+		if file != nil {
+			return *file, nil
+		}
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer, got %q", name, s)
+	}
+	return n, nil
+}
+
+// parseTimeout parses a duration such as "30s" or a number of seconds,
+// returning def when s is empty.
+func parseTimeout(name, s string, def time.Duration) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		seconds, convErr := strconv.Atoi(s)
+		if convErr != nil {
+			return 0, fmt.Errorf("%s must be a duration such as 30s, got %q", name, s)
+		}
+		d = time.Duration(seconds) * time.Second
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%s must be positive, got %q", name, s)
+	}
+	return d, nil
+}
+
+// parseFixtureMode returns the fixture mode for a fixture directory: ""
+// without one, FixtureReplay unless mode says otherwise.
+func parseFixtureMode(dir, mode string) (string, error) {
+	if strings.TrimSpace(dir) == "" {
+		return "", nil
+	}
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case "", FixtureReplay:
+		return FixtureReplay, nil
+	case FixtureRecord:
+		return FixtureRecord, nil
+	}
+	return "", fmt.Errorf("fixture_mode must be %s or %s, got %q", FixtureReplay, FixtureRecord, mode)
+}
+
+// parseDNSServer normalizes a DNS server address to host:port, defaulting
+// to port 53. An empty value selects the system resolver.
+func parseDNSServer(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", nil
+	}
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		// No port: a hostname, an IPv4 address, or a bare IPv6 address
+		host, port = strings.Trim(s, "[]"), "53"
+	}
+	if host == "" {
+		return "", fmt.Errorf("dns_server must be a host or host:port, got %q", s)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n <= 0 || n > 65535 {
+		return "", fmt.Errorf("dns_server port must be between 1 and 65535, got %q", s)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// parseHedgeDelay parses the hedge_delay setting: empty or "off" disables
+// hedging, "auto" derives the delay from recent latencies, and a duration
+// such as 500ms sets a fixed delay.
+func parseHedgeDelay(s string) (bool, time.Duration, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "off", "false", "0":
+		return false, 0, nil
+	case "auto", "true":
+		return true, 0, nil
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return false, 0, fmt.Errorf("hedge_delay must be a duration such as 500ms, \"auto\", or \"off\", got %q", s)
+	}
+	if d <= 0 {
+		return false, 0, fmt.Errorf("hedge_delay must be positive, got %q", s)
+	}
+	return true, d, nil
+}
+
+// parseRateLimit parses the rate_limit setting in requests per second: empty,
+// "off", or 0 disables the limit.
+func parseRateLimit(s string) (float64, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "off", "false":
+		return 0, nil
+	}
+	rps, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || rps < 0 || math.IsInf(rps, 0) || math.IsNaN(rps) {
+		return 0, fmt.Errorf("rate_limit must be a non-negative number of requests per second, got %q", s)
+	}
+	return rps, nil
+}
+
+// parseFaultInjection parses the fault_injection setting, a probability
+// between 0 and 1: empty or "off" disables fault injection.
+func parseFaultInjection(s string) (float64, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "off", "false":
+		return 0, nil
+	}
+	p, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || p < 0 || p > 1 || math.IsNaN(p) {
+		return 0, fmt.Errorf("fault_injection must be a probability between 0 and 1, got %q", s)
+	}
+	return p, nil
+}
+
+// parseCacheTTL parses the cache_ttl setting: empty selects DefaultCacheTTL,
+// "off" or 0 disables the cache, and a duration such as 1m sets the TTL.
+func parseCacheTTL(s string) (time.Duration, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "":
+		return DefaultCacheTTL, nil
+	case "off", "false", "0":
+		return 0, nil
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("cache_ttl must be a duration such as 30s, or \"off\", got %q", s)
+	}
+	return d, nil
+}
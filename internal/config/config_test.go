@@ -4,6 +4,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoad(t *testing.T) {
@@ -245,6 +246,101 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "base URL must use HTTPS",
 		},
+		{
+			name: "HTTP base URL on loopback",
+			config: &Config{
+				AuthToken:  "mock",
+				BaseURL:    "http://127.0.0.1:8090",
+				IngressURL: "http://localhost:8090",
+				Region:     RegionEUWest1,
+			},
+			wantErr: false,
+		},
+		{
+			name: "HTTP base URL on a host named like loopback",
+			config: &Config{
+				AuthToken: "test-token",
+				BaseURL:   "http://localhost.example.com",
+				Region:    RegionEUWest1,
+			},
+			wantErr: true,
+			errMsg:  "base URL must use HTTPS",
+		},
+		{
+			name: "non-HTTPS ingress URL",
+			config: &Config{
+				AuthToken:  "test-token",
+				BaseURL:    "https://api.eu-west-1.aws.dash0.com",
+				IngressURL: "http://ingress.eu-west-1.aws.dash0.com",
+				Region:     RegionEUWest1,
+			},
+			wantErr: true,
+			errMsg:  "ingress URL must use HTTPS",
+		},
+		{
+			name: "webhook without HTTP transport",
+			config: &Config{
+				AuthToken:    "test-token",
+				BaseURL:      "https://api.eu-west-1.aws.dash0.com",
+				Region:       RegionEUWest1,
+				WebhookToken: "webhook-token",
+			},
+			wantErr: true,
+			errMsg:  "DASH0_MCP_HTTP_ADDR",
+		},
+		{
+			name: "HTTP transport on all interfaces without a token",
+			config: &Config{
+				AuthToken: "test-token",
+				BaseURL:   "https://api.eu-west-1.aws.dash0.com",
+				Region:    RegionEUWest1,
+				HTTPAddr:  ":8080",
+			},
+			wantErr: true,
+			errMsg:  "DASH0_MCP_HTTP_TOKEN is required",
+		},
+		{
+			name: "HTTP transport on all interfaces with a token",
+			config: &Config{
+				AuthToken: "test-token",
+				BaseURL:   "https://api.eu-west-1.aws.dash0.com",
+				Region:    RegionEUWest1,
+				HTTPAddr:  "0.0.0.0:8080",
+				HTTPToken: "http-token",
+			},
+			wantErr: false,
+		},
+		{
+			name: "HTTP transport on loopback without a token",
+			config: &Config{
+				AuthToken: "test-token",
+				BaseURL:   "https://api.eu-west-1.aws.dash0.com",
+				Region:    RegionEUWest1,
+				HTTPAddr:  "[::1]:8080",
+			},
+			wantErr: false,
+		},
+		{
+			name: "replay without auth token",
+			config: &Config{
+				BaseURL:     "https://api.eu-west-1.aws.dash0.com",
+				Region:      RegionEUWest1,
+				Fixtures:    ".",
+				FixtureMode: FixtureReplay,
+			},
+			wantErr: false,
+		},
+		{
+			name: "replay from a missing directory",
+			config: &Config{
+				BaseURL:     "https://api.eu-west-1.aws.dash0.com",
+				Region:      RegionEUWest1,
+				Fixtures:    "testdata/no-such-fixtures",
+				FixtureMode: FixtureReplay,
+			},
+			wantErr: true,
+			errMsg:  "fixture directory",
+		},
 		{
 			name: "custom region with base URL is valid",
 			config: &Config{
@@ -379,6 +475,36 @@ func TestParseBool(t *testing.T) {
 	}
 }
 
+func TestParseDNSServer(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "", want: ""},
+		{input: "10.0.0.53", want: "10.0.0.53:53"},
+		{input: " 10.0.0.53:5353 ", want: "10.0.0.53:5353"},
+		{input: "dns.corp.internal", want: "dns.corp.internal:53"},
+		{input: "fd00::53", want: "[fd00::53]:53"},
+		{input: "[fd00::53]:5353", want: "[fd00::53]:5353"},
+		{input: ":53", wantErr: true},
+		{input: "10.0.0.53:0", wantErr: true},
+		{input: "10.0.0.53:70000", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseDNSServer(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDNSServer(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseDNSServer(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestLoad_Dataset(t *testing.T) {
 	// Save and clear environment
 	savedDataset := os.Getenv("DASH0_DATASET")
@@ -426,7 +552,185 @@ func TestLoad_Dataset(t *testing.T) {
 	}
 }
 
+func TestLoad_Ingress(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       map[string]string
+		wantURL   string
+		wantToken string
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name:      "token defaults to the API token",
+			env:       map[string]string{"DASH0_INGRESS_URL": "https://ingress.eu-west-1.aws.dash0.com/"},
+			wantURL:   "https://ingress.eu-west-1.aws.dash0.com",
+			wantToken: "api-token",
+		},
+		{
+			name:      "separate token",
+			env:       map[string]string{"DASH0_INGRESS_URL": "https://ingress.eu-west-1.aws.dash0.com", "DASH0_INGRESS_TOKEN": "ingest-token"},
+			wantURL:   "https://ingress.eu-west-1.aws.dash0.com",
+			wantToken: "ingest-token",
+		},
+		{
+			name:      "misspelled name",
+			env:       map[string]string{"DASH0_INGESS_URL": "https://ingress.us-west-2.aws.dash0.com"},
+			wantURL:   "https://ingress.us-west-2.aws.dash0.com",
+			wantToken: "api-token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearConfigEnv(t)
+			t.Setenv("DASH0_AUTH_TOKEN", "api-token")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.IngressURL != tt.wantURL || cfg.IngressToken != tt.wantToken {
+				t.Errorf("ingress = %q/%q, want %q/%q", cfg.IngressURL, cfg.IngressToken, tt.wantURL, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestLoad_ResponseLimits(t *testing.T) {
+	savedBytes := os.Getenv("DASH0_MAX_RESPONSE_BYTES")
+	savedItems := os.Getenv("DASH0_MAX_ITEMS")
+	defer func() {
+		os.Setenv("DASH0_MAX_RESPONSE_BYTES", savedBytes)
+		os.Setenv("DASH0_MAX_ITEMS", savedItems)
+	}()
+
+	tests := []struct {
+		name      string
+		maxBytes  string
+		maxItems  string
+		wantBytes int
+		wantItems int
+		wantErr   string
+	}{
+		{name: "unset means unlimited"},
+		{name: "configured", maxBytes: "65536", maxItems: "50", wantBytes: 65536, wantItems: 50},
+		{name: "invalid bytes", maxBytes: "64k", wantErr: "DASH0_MAX_RESPONSE_BYTES"},
+		{name: "negative items", maxItems: "-1", wantErr: "DASH0_MAX_ITEMS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Setenv("DASH0_MAX_RESPONSE_BYTES", tt.maxBytes)
+			os.Setenv("DASH0_MAX_ITEMS", tt.maxItems)
+
+			cfg, err := Load()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.MaxResponseBytes != tt.wantBytes || cfg.MaxItems != tt.wantItems {
+				t.Errorf("limits = (%d, %d), want (%d, %d)", cfg.MaxResponseBytes, cfg.MaxItems, tt.wantBytes, tt.wantItems)
+			}
+		})
+	}
+}
+
 // contains checks if substr is in s
 func contains(s, substr string) bool {
 	return strings.Contains(s, substr)
 }
+
+func TestParseHedgeDelay(t *testing.T) {
+	tests := []struct {
+		input     string
+		wantHedge bool
+		wantDelay time.Duration
+		wantErr   bool
+	}{
+		{input: ""},
+		{input: "off"},
+		{input: "auto", wantHedge: true},
+		{input: " AUTO ", wantHedge: true},
+		{input: "250ms", wantHedge: true, wantDelay: 250 * time.Millisecond},
+		{input: "-1s", wantErr: true},
+		{input: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			hedge, delay, err := parseHedgeDelay(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseHedgeDelay(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if hedge != tt.wantHedge || delay != tt.wantDelay {
+				t.Errorf("parseHedgeDelay(%q) = %v, %v; want %v, %v", tt.input, hedge, delay, tt.wantHedge, tt.wantDelay)
+			}
+		})
+	}
+}
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    float64
+		wantErr bool
+	}{
+		{input: ""},
+		{input: "off"},
+		{input: "0"},
+		{input: "10", want: 10},
+		{input: " 2.5 ", want: 2.5},
+		{input: "-1", wantErr: true},
+		{input: "fast", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseRateLimit(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRateLimit(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseRateLimit(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCacheTTL(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "", want: DefaultCacheTTL},
+		{input: "off"},
+		{input: "0"},
+		{input: "0s"},
+		{input: "2m", want: 2 * time.Minute},
+		{input: "-1s", wantErr: true},
+		{input: "briefly", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseCacheTTL(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCacheTTL(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseCacheTTL(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
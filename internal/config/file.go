@@ -0,0 +1,116 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileConfig is the optional YAML configuration file. Every field is
+// optional; environment variables override the values set here.
+type FileConfig struct {
+	AuthToken             string `yaml:"auth_token"`
+	AuthTokenFile         string `yaml:"auth_token_file"`
+	Region                string `yaml:"region"`
+	BaseURL               string `yaml:"base_url"`
+	IngressURL            string `yaml:"ingress_url"`
+	IngressToken          string `yaml:"ingress_token"`
+	Dataset               string `yaml:"dataset"`
+	Profile               string `yaml:"profile"`
+	ConfigDir             string `yaml:"config_dir"`
+	Debug                 *bool  `yaml:"debug"`
+	Timeout               string `yaml:"timeout"`
+	HTTPTimeout           string `yaml:"http_timeout"`
+	ToolTimeout           string `yaml:"tool_timeout"`
+	MaxRetries            *int   `yaml:"max_retries"`
+	MaxConcurrency        *int   `yaml:"max_concurrency"`
+	MaxResponseBytes      *int   `yaml:"max_response_bytes"`
+	MaxItems              *int   `yaml:"max_items"`
+	ForceIPv4             *bool  `yaml:"force_ipv4"`
+	DNSServer             string `yaml:"dns_server"`
+	HedgeDelay            string `yaml:"hedge_delay"`
+	RateLimit             string `yaml:"rate_limit"`
+	RateBurst             *int   `yaml:"rate_burst"`
+	CacheTTL              string `yaml:"cache_ttl"`
+	CallBudget            *int   `yaml:"call_budget"`
+	SelfTelemetry         *bool  `yaml:"self_telemetry"`
+	SelfTelemetryInterval string `yaml:"self_telemetry_interval"`
+	StatsInterval         string `yaml:"stats_interval"`
+	AuditLog              string `yaml:"audit_log"`
+	ExportDir             string `yaml:"export_dir"`
+	TraceFile             string `yaml:"trace_file"`
+	Fixtures              string `yaml:"fixtures"`
+	FixtureMode           string `yaml:"fixture_mode"`
+	DryRun                *bool  `yaml:"dry_run"`
+	ReadOnly              *bool  `yaml:"read_only"`
+	ConfirmDeletes        *bool  `yaml:"confirm_deletes"`
+	StartupCheck          *bool  `yaml:"startup_check"`
+	DisableDeniedWrites   *bool  `yaml:"disable_denied_writes"`
+	FaultInjection        string `yaml:"fault_injection"`
+	HTTPAddr              string `yaml:"http_addr"`
+	HTTPToken             string `yaml:"http_token"`
+	WebhookToken          string `yaml:"webhook_token"`
+	WebhookLabels         string `yaml:"webhook_labels"`
+	// Targets are additional Dash0 organizations or datasets, by name.
+	Targets map[string]FileTarget `yaml:"targets"`
+}
+
+// FileTarget is a named Dash0 target in the config file. Unset fields fall
+// back to the top-level settings.
+type FileTarget struct {
+	Region    string `yaml:"region"`
+	BaseURL   string `yaml:"base_url"`
+	AuthToken string `yaml:"auth_token"`
+	Dataset   string `yaml:"dataset"`
+}
+
+// ConfigFilePath returns the config file location: DASH0_CONFIG_FILE if set,
+// otherwise ~/.dash0-mcp/config.yaml. It returns "" if the home directory
+// cannot be determined.
+func ConfigFilePath() string {
+	if path := os.Getenv("DASH0_CONFIG_FILE"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".dash0-mcp", "config.yaml")
+}
+
+// LoadFile reads the config file at path. A missing file is not an error
+// and yields a nil FileConfig.
+func LoadFile(path string) (*FileConfig, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var fc FileConfig
+	if err := yaml.Unmarshal(data, &fc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	for name, v := range map[string]*int{
+		"max_retries":        fc.MaxRetries,
+		"max_response_bytes": fc.MaxResponseBytes,
+		"max_items":          fc.MaxItems,
+		"max_concurrency":    fc.MaxConcurrency,
+		"rate_burst":         fc.RateBurst,
+		"call_budget":        fc.CallBudget,
+	} {
+		if v != nil && *v < 0 {
+			return nil, fmt.Errorf("config file %s: %s must not be negative", path, name)
+		}
+	}
+	return &fc, nil
+}
@@ -0,0 +1,398 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearConfigEnv unsets every environment variable Load reads.
+func clearConfigEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{
+		"DASH0_AUTH_TOKEN", "DASH0_AUTH_TOKEN_FILE", "DASH0_TOKEN", "DASH0_REGION", "DASH0_BASE_URL", "DASH0_DATASET",
+		"DASH0_DEBUG", "DASH0_MAX_RESPONSE_BYTES", "DASH0_MAX_ITEMS", "DASH0_TIMEOUT",
+		"DASH0_MAX_RETRIES", "DASH0_MCP_PROFILE", "DASH0_MCP_CONFIG_DIR", "DASH0_CONFIG_FILE",
+		"DASH0_TOOL_TIMEOUT", "DASH0_FORCE_IPV4", "DASH0_DNS_SERVER", "DASH0_HEDGE_DELAY",
+		"DASH0_MAX_CONCURRENCY", "DASH0_RATE_LIMIT", "DASH0_RATE_BURST",
+		"DASH0_CACHE_TTL", "DASH0_CALL_BUDGET", "DASH0_HTTP_TIMEOUT",
+		"DASH0_SELF_TELEMETRY", "DASH0_SELF_TELEMETRY_INTERVAL", "DASH0_MCP_STATS_INTERVAL", "DASH0_MCP_AUDIT_LOG", "DASH0_MCP_EXPORT_DIR", "DASH0_MCP_TRACE_FILE",
+		"DASH0_MCP_FIXTURES", "DASH0_MCP_FIXTURE_MODE",
+		"DASH0_MCP_DRY_RUN", "DASH0_MCP_READ_ONLY", "DASH0_MCP_CONFIRM_DELETES", "DASH0_FAULT_INJECTION",
+		"DASH0_MCP_STARTUP_CHECK", "DASH0_MCP_DISABLE_DENIED_WRITES",
+		"DASH0_INGRESS_URL", "DASH0_INGESS_URL", "DASH0_INGRESS_TOKEN",
+		"DASH0_MCP_HTTP_ADDR", "DASH0_MCP_HTTP_TOKEN", "DASH0_WEBHOOK_TOKEN", "DASH0_WEBHOOK_LABELS",
+	} {
+		t.Setenv(name, "")
+	}
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+const testConfigFile = `
+auth_token: file-token
+region: eu-west-1
+dataset: otel-demo
+profile: readonly
+config_dir: /etc/dash0-mcp
+debug: true
+timeout: 15s
+tool_timeout: 2m
+max_retries: 5
+max_concurrency: 8
+max_response_bytes: 32768
+max_items: 25
+force_ipv4: true
+dns_server: 10.0.0.53
+hedge_delay: 750ms
+rate_limit: 5
+rate_burst: 10
+cache_ttl: 2m
+call_budget: 500
+self_telemetry: true
+self_telemetry_interval: 10s
+stats_interval: 15m
+audit_log: /var/log/dash0-mcp/audit.jsonl
+export_dir: /var/lib/dash0-mcp/exports
+trace_file: /var/log/dash0-mcp/trace.jsonl
+fixtures: /var/lib/dash0-mcp/fixtures
+fixture_mode: record
+dry_run: true
+read_only: true
+confirm_deletes: true
+startup_check: false
+disable_denied_writes: true
+fault_injection: 0.1
+ingress_url: https://ingress.eu-west-1.aws.dash0.com
+http_addr: 127.0.0.1:8080
+http_token: http-token
+webhook_token: webhook-token
+webhook_labels: severity=critical
+`
+
+func TestLoad_ConfigFile(t *testing.T) {
+	clearConfigEnv(t)
+	path := writeConfigFile(t, testConfigFile)
+	t.Setenv("DASH0_CONFIG_FILE", path)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.AuthToken != "file-token" || cfg.Region != RegionEUWest1 || cfg.Dataset != "otel-demo" {
+		t.Errorf("unexpected auth/region/dataset: %q %q %q", cfg.AuthToken, cfg.Region, cfg.Dataset)
+	}
+	if cfg.BaseURL != "https://api.eu-west-1.aws.dash0.com" {
+		t.Errorf("BaseURL = %q, want derived from file region", cfg.BaseURL)
+	}
+	if cfg.Profile != "readonly" || cfg.ConfigDir != "/etc/dash0-mcp" {
+		t.Errorf("Profile/ConfigDir = %q/%q", cfg.Profile, cfg.ConfigDir)
+	}
+	if !cfg.Debug || cfg.Timeout != 15*time.Second || cfg.MaxRetries != 5 {
+		t.Errorf("Debug/Timeout/MaxRetries = %v/%v/%d", cfg.Debug, cfg.Timeout, cfg.MaxRetries)
+	}
+	if cfg.ToolTimeout != 2*time.Minute {
+		t.Errorf("ToolTimeout = %v, want 2m", cfg.ToolTimeout)
+	}
+	if !cfg.ForceIPv4 || cfg.DNSServer != "10.0.0.53:53" {
+		t.Errorf("ForceIPv4/DNSServer = %v/%q, want true/10.0.0.53:53", cfg.ForceIPv4, cfg.DNSServer)
+	}
+	if !cfg.Hedge || cfg.HedgeDelay != 750*time.Millisecond {
+		t.Errorf("Hedge/HedgeDelay = %v/%v, want true/750ms", cfg.Hedge, cfg.HedgeDelay)
+	}
+	if cfg.MaxResponseBytes != 32768 || cfg.MaxItems != 25 {
+		t.Errorf("limits = (%d, %d), want (32768, 25)", cfg.MaxResponseBytes, cfg.MaxItems)
+	}
+	if cfg.RateLimit != 5 || cfg.RateBurst != 10 {
+		t.Errorf("RateLimit/RateBurst = %v/%d, want 5/10", cfg.RateLimit, cfg.RateBurst)
+	}
+	if cfg.MaxConcurrency != 8 {
+		t.Errorf("MaxConcurrency = %d, want 8", cfg.MaxConcurrency)
+	}
+	if cfg.CacheTTL != 2*time.Minute || cfg.CallBudget != 500 {
+		t.Errorf("CacheTTL/CallBudget = %v/%d, want 2m/500", cfg.CacheTTL, cfg.CallBudget)
+	}
+	if !cfg.SelfTelemetry || cfg.SelfTelemetryInterval != 10*time.Second {
+		t.Errorf("SelfTelemetry/SelfTelemetryInterval = %v/%v, want true/10s", cfg.SelfTelemetry, cfg.SelfTelemetryInterval)
+	}
+	if cfg.StatsInterval != 15*time.Minute {
+		t.Errorf("StatsInterval = %v, want 15m", cfg.StatsInterval)
+	}
+	if cfg.IngressURL != "https://ingress.eu-west-1.aws.dash0.com" || cfg.IngressToken != "file-token" {
+		t.Errorf("IngressURL/IngressToken = %q/%q, want the file URL and the API token", cfg.IngressURL, cfg.IngressToken)
+	}
+	if cfg.AuditLog != "/var/log/dash0-mcp/audit.jsonl" {
+		t.Errorf("AuditLog = %q, want /var/log/dash0-mcp/audit.jsonl", cfg.AuditLog)
+	}
+	if cfg.ExportDir != "/var/lib/dash0-mcp/exports" {
+		t.Errorf("ExportDir = %q, want /var/lib/dash0-mcp/exports", cfg.ExportDir)
+	}
+	if cfg.TraceFile != "/var/log/dash0-mcp/trace.jsonl" {
+		t.Errorf("TraceFile = %q, want /var/log/dash0-mcp/trace.jsonl", cfg.TraceFile)
+	}
+	if cfg.Fixtures != "/var/lib/dash0-mcp/fixtures" || cfg.FixtureMode != FixtureRecord {
+		t.Errorf("Fixtures = %q, FixtureMode = %q; want /var/lib/dash0-mcp/fixtures, record", cfg.Fixtures, cfg.FixtureMode)
+	}
+	if !cfg.DryRun || !cfg.ReadOnly || !cfg.ConfirmDeletes {
+		t.Errorf("DryRun/ReadOnly/ConfirmDeletes = %v/%v/%v, want true", cfg.DryRun, cfg.ReadOnly, cfg.ConfirmDeletes)
+	}
+	if cfg.StartupCheck || !cfg.DisableDeniedWrites {
+		t.Errorf("StartupCheck/DisableDeniedWrites = %v/%v, want false/true", cfg.StartupCheck, cfg.DisableDeniedWrites)
+	}
+	if cfg.FaultInjection != 0.1 {
+		t.Errorf("FaultInjection = %v, want 0.1", cfg.FaultInjection)
+	}
+	if cfg.HTTPAddr != "127.0.0.1:8080" || cfg.HTTPToken != "http-token" || cfg.WebhookToken != "webhook-token" || cfg.WebhookLabels != "severity=critical" {
+		t.Errorf("HTTPAddr/HTTPToken/WebhookToken/WebhookLabels = %q/%q/%q/%q", cfg.HTTPAddr, cfg.HTTPToken, cfg.WebhookToken, cfg.WebhookLabels)
+	}
+	if cfg.ConfigFile != path {
+		t.Errorf("ConfigFile = %q, want %q", cfg.ConfigFile, path)
+	}
+}
+
+func TestLoad_EnvOverridesConfigFile(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("DASH0_CONFIG_FILE", writeConfigFile(t, testConfigFile))
+	t.Setenv("DASH0_AUTH_TOKEN", "env-token")
+	t.Setenv("DASH0_REGION", "us-east-1")
+	t.Setenv("DASH0_DATASET", "production")
+	t.Setenv("DASH0_MCP_PROFILE", "full")
+	t.Setenv("DASH0_DEBUG", "false")
+	t.Setenv("DASH0_TIMEOUT", "90")
+	t.Setenv("DASH0_MAX_RETRIES", "0")
+	t.Setenv("DASH0_MAX_ITEMS", "10")
+	t.Setenv("DASH0_FORCE_IPV4", "false")
+	t.Setenv("DASH0_DNS_SERVER", "[fd00::53]:5353")
+	t.Setenv("DASH0_MCP_STARTUP_CHECK", "true")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.AuthToken != "env-token" || cfg.Region != RegionUSEast1 || cfg.Dataset != "production" || cfg.Profile != "full" {
+		t.Errorf("env did not override file: %+v", cfg)
+	}
+	if cfg.Debug {
+		t.Error("DASH0_DEBUG=false should override debug: true in the file")
+	}
+	if cfg.ForceIPv4 || cfg.DNSServer != "[fd00::53]:5353" {
+		t.Errorf("ForceIPv4/DNSServer = %v/%q, want env values", cfg.ForceIPv4, cfg.DNSServer)
+	}
+	if !cfg.StartupCheck {
+		t.Error("DASH0_MCP_STARTUP_CHECK=true should override startup_check: false in the file")
+	}
+	if cfg.Timeout != 90*time.Second || cfg.MaxRetries != 0 || cfg.MaxItems != 10 {
+		t.Errorf("Timeout/MaxRetries/MaxItems = %v/%d/%d", cfg.Timeout, cfg.MaxRetries, cfg.MaxItems)
+	}
+	// Unset env vars still fall back to the file.
+	if cfg.MaxResponseBytes != 32768 || cfg.ConfigDir != "/etc/dash0-mcp" {
+		t.Errorf("MaxResponseBytes/ConfigDir = %d/%q, want file values", cfg.MaxResponseBytes, cfg.ConfigDir)
+	}
+}
+
+func TestLoad_AuthTokenFile(t *testing.T) {
+	clearConfigEnv(t)
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("file-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DASH0_CONFIG_FILE", writeConfigFile(t, "ingress_url: https://ingress.example.com\nauth_token_file: "+tokenFile))
+	t.Setenv("DASH0_AUTH_TOKEN", "env-token")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.AuthTokenFile != tokenFile || cfg.AuthToken != "file-token" {
+		t.Errorf("AuthTokenFile/AuthToken = %q/%q, want the file and its trimmed token", cfg.AuthTokenFile, cfg.AuthToken)
+	}
+	if cfg.IngressToken != "file-token" {
+		t.Errorf("IngressToken = %q, want the token of the file", cfg.IngressToken)
+	}
+
+	if err := os.WriteFile(tokenFile, []byte(" \n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "is empty") {
+		t.Errorf("Load() error = %v, want an empty token file error", err)
+	}
+	t.Setenv("DASH0_AUTH_TOKEN_FILE", filepath.Join(t.TempDir(), "missing"))
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "auth token file") {
+		t.Errorf("Load() error = %v, want a missing token file error", err)
+	}
+}
+
+func TestLoad_StartupCheck(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("DASH0_CONFIG_FILE", writeConfigFile(t, "auth_token: file-token"))
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.StartupCheck || cfg.DisableDeniedWrites {
+		t.Errorf("StartupCheck/DisableDeniedWrites = %v/%v, want the check on by default and writes left enabled", cfg.StartupCheck, cfg.DisableDeniedWrites)
+	}
+
+	t.Setenv("DASH0_MCP_STARTUP_CHECK", "false")
+	if cfg, err = Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.StartupCheck {
+		t.Error("DASH0_MCP_STARTUP_CHECK=false should turn the check off")
+	}
+}
+
+func TestLoad_HTTPTimeout(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("DASH0_CONFIG_FILE", writeConfigFile(t, "http_timeout: 20s\ntimeout: 10s"))
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Timeout != 20*time.Second {
+		t.Errorf("Timeout = %v, want http_timeout to win over timeout", cfg.Timeout)
+	}
+
+	t.Setenv("DASH0_TIMEOUT", "40s")
+	t.Setenv("DASH0_HTTP_TIMEOUT", "45s")
+	if cfg, err = Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Timeout != 45*time.Second {
+		t.Errorf("Timeout = %v, want DASH0_HTTP_TIMEOUT to win over DASH0_TIMEOUT", cfg.Timeout)
+	}
+}
+
+func TestLoad_NoConfigFile(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("DASH0_CONFIG_FILE", filepath.Join(t.TempDir(), "missing.yaml"))
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.ConfigFile != "" {
+		t.Errorf("ConfigFile = %q, want empty", cfg.ConfigFile)
+	}
+	if cfg.Timeout != DefaultTimeout || cfg.MaxRetries != DefaultMaxRetries {
+		t.Errorf("Timeout/MaxRetries = %v/%d, want defaults", cfg.Timeout, cfg.MaxRetries)
+	}
+	if cfg.CacheTTL != DefaultCacheTTL {
+		t.Errorf("CacheTTL = %v, want %v", cfg.CacheTTL, DefaultCacheTTL)
+	}
+}
+
+func TestLoad_InvalidConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "malformed yaml", content: "auth_token: [", wantErr: "failed to parse config file"},
+		{name: "negative retries", content: "max_retries: -1", wantErr: "max_retries must not be negative"},
+		{name: "bad timeout", content: "timeout: soon", wantErr: "timeout must be a duration"},
+		{name: "zero timeout", content: "timeout: 0s", wantErr: "timeout must be positive"},
+		{name: "bad dns server port", content: "dns_server: 10.0.0.53:dns", wantErr: "dns_server port must be"},
+		{name: "reserved target name", content: "targets:\n  default:\n    dataset: x", wantErr: `"default" is not a valid target name`},
+		{name: "bad target region", content: "targets:\n  prod:\n    region: mars-1", wantErr: "targets.prod: unknown region"},
+		{name: "bad hedge delay", content: "hedge_delay: sometimes", wantErr: "hedge_delay must be a duration"},
+		{name: "bad tool timeout", content: "tool_timeout: forever", wantErr: "tool_timeout must be a duration"},
+		{name: "bad env tool timeout", content: "", env: map[string]string{"DASH0_TOOL_TIMEOUT": "-5s"}, wantErr: "tool_timeout must be positive"},
+		{name: "negative concurrency", content: "max_concurrency: -2", wantErr: "max_concurrency must not be negative"},
+		{name: "bad rate limit", content: "rate_limit: lots", wantErr: "rate_limit must be a non-negative number"},
+		{name: "bad fault injection", content: "fault_injection: 5", wantErr: "fault_injection must be a probability"},
+		{name: "negative rate burst", content: "rate_burst: -1", wantErr: "rate_burst must not be negative"},
+		{name: "negative call budget", content: "call_budget: -5", wantErr: "call_budget must not be negative"},
+		{name: "bad cache ttl", content: "cache_ttl: briefly", wantErr: "cache_ttl must be a duration"},
+		{name: "bad self telemetry interval", content: "self_telemetry_interval: often", wantErr: "self_telemetry_interval must be a duration"},
+		{name: "bad env stats interval", content: "", env: map[string]string{"DASH0_MCP_STATS_INTERVAL": "hourly"}, wantErr: "stats_interval must be a duration"},
+		{name: "bad fixture mode", content: "fixtures: fixtures\nfixture_mode: rewind", wantErr: "fixture_mode must be replay or record"},
+		{name: "bad env retries", content: "", env: map[string]string{"DASH0_MAX_RETRIES": "many"}, wantErr: "DASH0_MAX_RETRIES"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearConfigEnv(t)
+			t.Setenv("DASH0_CONFIG_FILE", writeConfigFile(t, tt.content))
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			_, err := Load()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_Targets(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("DASH0_CONFIG_FILE", writeConfigFile(t, `
+auth_token: file-token
+region: eu-west-1
+targets:
+  staging:
+    dataset: staging
+  prod:
+    region: us-east-1
+    auth_token: prod-token
+    dataset: production
+  onprem:
+    base_url: https://dash0.internal
+`))
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := map[string]Target{
+		"staging": {BaseURL: "https://api.eu-west-1.aws.dash0.com", AuthToken: "file-token", Dataset: "staging"},
+		"prod":    {BaseURL: "https://api.us-east-1.aws.dash0.com", AuthToken: "prod-token", Dataset: "production"},
+		"onprem":  {BaseURL: "https://dash0.internal", AuthToken: "file-token"},
+	}
+	if len(cfg.Targets) != len(want) {
+		t.Fatalf("Targets = %v, want %v", cfg.Targets, want)
+	}
+	for name, w := range want {
+		if got := cfg.Targets[name]; got != w {
+			t.Errorf("Targets[%s] = %+v, want %+v", name, got, w)
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	cfg.Targets["insecure"] = Target{BaseURL: "http://dash0.internal", AuthToken: "x"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "target insecure") {
+		t.Errorf("Validate() error = %v, want HTTPS error for target", err)
+	}
+}
+
+func TestConfigFilePath(t *testing.T) {
+	t.Setenv("DASH0_CONFIG_FILE", "/tmp/custom.yaml")
+	if got := ConfigFilePath(); got != "/tmp/custom.yaml" {
+		t.Errorf("ConfigFilePath() = %q, want DASH0_CONFIG_FILE", got)
+	}
+
+	t.Setenv("DASH0_CONFIG_FILE", "")
+	t.Setenv("HOME", "/home/tester")
+	if got := ConfigFilePath(); got != filepath.Join("/home/tester", ".dash0-mcp", "config.yaml") {
+		t.Errorf("ConfigFilePath() = %q, want ~/.dash0-mcp/config.yaml", got)
+	}
+}
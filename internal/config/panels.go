@@ -0,0 +1,94 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// PanelVariablePattern matches a variable in the string values of a panel
+// template, e.g. {{.service_name}}. Placeholders without the leading dot,
+// such as {{route}} in a seriesNameFormat, belong to Perses and are kept.
+var PanelVariablePattern = regexp.MustCompile(`\{\{\s*\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// PanelTemplate is a reusable Perses panel loaded from the panel-templates
+// directory.
+type PanelTemplate struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Variables   []PanelVariable `json:"variables"`
+	// Panel is the Perses panel. Its string values may contain variables.
+	Panel map[string]interface{} `json:"panel"`
+}
+
+// PanelVariable defines one variable of a panel template.
+type PanelVariable struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Required    bool   `json:"required"`
+	Default     string `json:"default"`
+}
+
+// LoadPanelTemplates loads every *.json file in configDir/panel-templates,
+// sorted by file name. A missing directory is not an error and yields no
+// templates.
+func LoadPanelTemplates(configDir string) ([]PanelTemplate, error) {
+	paths, err := filepath.Glob(filepath.Join(configDir, "panel-templates", "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list panel templates: %w", err)
+	}
+	sort.Strings(paths)
+
+	var templates []PanelTemplate
+	seen := make(map[string]string)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read panel template %s: %w", filepath.Base(path), err)
+		}
+
+		var t PanelTemplate
+		if err := json.Unmarshal(data, &t); err != nil {
+			return nil, fmt.Errorf("failed to parse panel template %s: %w", filepath.Base(path), err)
+		}
+		if err := t.validate(); err != nil {
+			return nil, fmt.Errorf("invalid panel template %s: %w", filepath.Base(path), err)
+		}
+		if other, ok := seen[t.Name]; ok {
+			return nil, fmt.Errorf("panel template %q is defined in both %s and %s", t.Name, other, filepath.Base(path))
+		}
+		seen[t.Name] = filepath.Base(path)
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+func (t PanelTemplate) validate() error {
+	if !promptNamePattern.MatchString(t.Name) {
+		return fmt.Errorf("name %q must be lowercase kebab-case", t.Name)
+	}
+	if len(t.Panel) == 0 {
+		return fmt.Errorf("panel is required")
+	}
+	vars := make(map[string]bool)
+	for _, v := range t.Variables {
+		if v.Name == "" {
+			return fmt.Errorf("variable name is required")
+		}
+		if vars[v.Name] {
+			return fmt.Errorf("variable %q is defined twice", v.Name)
+		}
+		vars[v.Name] = true
+	}
+	// Every variable the panel uses must be declared
+	data, _ := json.Marshal(t.Panel)
+	for _, m := range PanelVariablePattern.FindAllStringSubmatch(string(data), -1) {
+		if !vars[m[1]] {
+			return fmt.Errorf("panel uses undeclared variable %q", m[1])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePanelTemplate(t *testing.T, dir, file, content string) {
+	t.Helper()
+	templatesDir := filepath.Join(dir, "panel-templates")
+	if err := os.MkdirAll(templatesDir, 0755); err != nil {
+		t.Fatalf("failed to create panel-templates dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(templatesDir, file), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", file, err)
+	}
+}
+
+func TestLoadPanelTemplates(t *testing.T) {
+	tmpDir := t.TempDir()
+	writePanelTemplate(t, tmpDir, "rate.json", `{
+		"name": "rate",
+		"variables": [{"name": "service_name", "required": true}, {"name": "window", "default": "5m"}],
+		"panel": {"kind": "Panel", "spec": {"queries": [{"query": "rate(x{service=\"{{ .service_name }}\"}[{{.window}}])", "seriesNameFormat": "{{route}}"}]}}
+	}`)
+	writePanelTemplate(t, tmpDir, "notes.txt", "ignored")
+
+	templates, err := LoadPanelTemplates(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadPanelTemplates() error = %v", err)
+	}
+	if len(templates) != 1 || templates[0].Name != "rate" || len(templates[0].Variables) != 2 || templates[0].Variables[1].Default != "5m" {
+		t.Errorf("LoadPanelTemplates() = %+v", templates)
+	}
+
+	// The shipped templates are valid
+	shipped, err := LoadPanelTemplates("../../config")
+	if err != nil || len(shipped) != 4 {
+		t.Errorf("shipped templates: %d, error = %v; want 4", len(shipped), err)
+	}
+
+	if templates, err := LoadPanelTemplates(filepath.Join(tmpDir, "missing")); err != nil || len(templates) != 0 {
+		t.Errorf("missing dir: %v, %v; want no templates and no error", templates, err)
+	}
+}
+
+func TestLoadPanelTemplates_Invalid(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		want    string
+	}{
+		"bad name":            {`{"name": "Rate", "panel": {"kind": "Panel"}}`, "kebab-case"},
+		"no panel":            {`{"name": "rate"}`, "panel is required"},
+		"undeclared variable": {`{"name": "rate", "panel": {"spec": "{{.service_name}}"}}`, `undeclared variable "service_name"`},
+		"duplicate variable":  {`{"name": "rate", "variables": [{"name": "a"}, {"name": "a"}], "panel": {"kind": "Panel"}}`, "defined twice"},
+		"not JSON":            {`name: rate`, "failed to parse"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			writePanelTemplate(t, tmpDir, "rate.json", tt.content)
+			_, err := LoadPanelTemplates(tmpDir)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadPanelTemplates() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	tmpDir := t.TempDir()
+	writePanelTemplate(t, tmpDir, "a.json", `{"name": "rate", "panel": {"kind": "Panel"}}`)
+	writePanelTemplate(t, tmpDir, "b.json", `{"name": "rate", "panel": {"kind": "Panel"}}`)
+	if _, err := LoadPanelTemplates(tmpDir); err == nil || !strings.Contains(err.Error(), "both a.json and b.json") {
+		t.Errorf("duplicate name: error = %v", err)
+	}
+}
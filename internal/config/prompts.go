@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// promptNamePattern restricts prompt names to lowercase kebab-case.
+var promptNamePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// PromptDef defines an MCP prompt template loaded from the prompts directory.
+type PromptDef struct {
+	Name        string           `yaml:"name"`
+	Description string           `yaml:"description"`
+	Arguments   []PromptArgument `yaml:"arguments"`
+	// Tools lists the tools the prompt's instructions rely on. The prompt is
+	// only offered when all of them are enabled.
+	Tools []string `yaml:"tools"`
+	// Template is a Go text/template rendered with the prompt arguments.
+	Template string `yaml:"template"`
+}
+
+// PromptArgument defines one argument of a prompt template.
+type PromptArgument struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Required    bool   `yaml:"required"`
+	Default     string `yaml:"default"`
+}
+
+// LoadPrompts loads every *.yaml file in configDir/prompts, sorted by file name.
+// A missing prompts directory is not an error and yields no prompts.
+func LoadPrompts(configDir string) ([]PromptDef, error) {
+	paths, err := filepath.Glob(filepath.Join(configDir, "prompts", "*.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompts: %w", err)
+	}
+	sort.Strings(paths)
+
+	var prompts []PromptDef
+	seen := make(map[string]string)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompt %s: %w", filepath.Base(path), err)
+		}
+
+		var p PromptDef
+		if err := yaml.Unmarshal(data, &p); err != nil {
+			return nil, fmt.Errorf("failed to parse prompt %s: %w", filepath.Base(path), err)
+		}
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("invalid prompt %s: %w", filepath.Base(path), err)
+		}
+		if other, ok := seen[p.Name]; ok {
+			return nil, fmt.Errorf("prompt %q is defined in both %s and %s", p.Name, other, filepath.Base(path))
+		}
+		seen[p.Name] = filepath.Base(path)
+		prompts = append(prompts, p)
+	}
+	return prompts, nil
+}
+
+func (p PromptDef) validate() error {
+	if !promptNamePattern.MatchString(p.Name) {
+		return fmt.Errorf("name %q must be lowercase kebab-case", p.Name)
+	}
+	if p.Template == "" {
+		return fmt.Errorf("template is required")
+	}
+	args := make(map[string]bool)
+	for _, a := range p.Arguments {
+		if a.Name == "" {
+			return fmt.Errorf("argument name is required")
+		}
+		if args[a.Name] {
+			return fmt.Errorf("argument %q is defined twice", a.Name)
+		}
+		args[a.Name] = true
+	}
+	return nil
+}
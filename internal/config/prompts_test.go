@@ -0,0 +1,111 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePrompt(t *testing.T, dir, file, content string) {
+	t.Helper()
+	promptsDir := filepath.Join(dir, "prompts")
+	if err := os.MkdirAll(promptsDir, 0755); err != nil {
+		t.Fatalf("failed to create prompts dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(promptsDir, file), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", file, err)
+	}
+}
+
+func TestLoadPrompts(t *testing.T) {
+	tmpDir := t.TempDir()
+	writePrompt(t, tmpDir, "b.yaml", `
+name: check-service
+description: "Check a service"
+arguments:
+  - name: service_name
+    required: true
+  - name: minutes
+    default: "60"
+tools:
+  - dash0_spans_query
+template: "Check {{.service_name}}"
+`)
+	writePrompt(t, tmpDir, "a.yaml", `
+name: another-prompt
+template: "Static text"
+`)
+	writePrompt(t, tmpDir, "notes.txt", "ignored")
+
+	prompts, err := LoadPrompts(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadPrompts() error = %v", err)
+	}
+	if len(prompts) != 2 {
+		t.Fatalf("LoadPrompts() returned %d prompts, want 2", len(prompts))
+	}
+	if prompts[0].Name != "another-prompt" || prompts[1].Name != "check-service" {
+		t.Errorf("prompts not sorted by file name: %s, %s", prompts[0].Name, prompts[1].Name)
+	}
+	p := prompts[1]
+	if len(p.Arguments) != 2 || !p.Arguments[0].Required || p.Arguments[1].Default != "60" {
+		t.Errorf("unexpected arguments: %+v", p.Arguments)
+	}
+	if len(p.Tools) != 1 || p.Tools[0] != "dash0_spans_query" {
+		t.Errorf("unexpected tools: %v", p.Tools)
+	}
+}
+
+func TestLoadPrompts_MissingDir(t *testing.T) {
+	prompts, err := LoadPrompts(t.TempDir())
+	if err != nil {
+		t.Fatalf("LoadPrompts() error = %v", err)
+	}
+	if len(prompts) != 0 {
+		t.Errorf("LoadPrompts() returned %d prompts, want 0", len(prompts))
+	}
+}
+
+func TestLoadPrompts_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{"bad name", map[string]string{"x.yaml": "name: Bad_Name\ntemplate: x"}, "kebab-case"},
+		{"missing template", map[string]string{"x.yaml": "name: ok"}, "template is required"},
+		{"duplicate argument", map[string]string{"x.yaml": "name: ok\ntemplate: x\narguments:\n  - name: a\n  - name: a"}, "defined twice"},
+		{"duplicate name", map[string]string{"x.yaml": "name: ok\ntemplate: x", "y.yaml": "name: ok\ntemplate: y"}, "defined in both"},
+		{"invalid yaml", map[string]string{"x.yaml": "name: [unclosed"}, "failed to parse"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			for file, content := range tt.files {
+				writePrompt(t, tmpDir, file, content)
+			}
+			_, err := LoadPrompts(tmpDir)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadPrompts() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadPrompts_Shipped(t *testing.T) {
+	prompts, err := LoadPrompts(filepath.Join("..", "..", "config"))
+	if err != nil {
+		t.Fatalf("LoadPrompts() error = %v", err)
+	}
+
+	names := make(map[string]bool)
+	for _, p := range prompts {
+		names[p.Name] = true
+	}
+	for _, want := range []string{"investigate-error-spike", "create-uptime-check", "latency-regression-analysis"} {
+		if !names[want] {
+			t.Errorf("shipped prompt %q not found", want)
+		}
+	}
+}
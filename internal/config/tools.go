@@ -4,7 +4,10 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -38,6 +41,25 @@ type Profile struct {
 	Disable         []string `yaml:"disable"`
 	EnableAll       bool     `yaml:"enable_all"`
 	DisableUnlisted bool     `yaml:"disable_unlisted"`
+	// Allow enables the tools matching any of these patterns, as if they
+	// were listed under enable. Deny disables the tools matching any of its
+	// patterns in every mode, overriding enable and allow. A pattern is a
+	// glob such as "dash0_*_list", or a regular expression between slashes
+	// such as "/^dash0_(logs|spans)_/"; both must match the whole name.
+	Allow []string `yaml:"allow"`
+	Deny  []string `yaml:"deny"`
+	// Scope pins every telemetry query to these attribute values (e.g.
+	// team: checkout). The filters are added server-side and cannot be
+	// removed by tool arguments.
+	Scope map[string]string `yaml:"scope"`
+	// ReadOnly makes the client reject every write while the profile is
+	// active, even through tools the profile enables by mistake.
+	ReadOnly bool `yaml:"read_only"`
+	// Defaults are argument values passed to a tool when the caller omits
+	// them, by tool name and argument name (e.g. dash0_golden_signals:
+	// service_name: checkout). Defaults under "*" apply to every tool with
+	// that argument, such as dataset.
+	Defaults map[string]map[string]interface{} `yaml:"defaults"`
 }
 
 // LoadToolsConfig loads tools.yaml and the specified profile.
@@ -78,6 +100,23 @@ func LoadToolsConfig(configDir, profileName string) (*ToolsConfig, *Profile, err
 	if err := yaml.Unmarshal(profileData, &profile); err != nil {
 		return nil, nil, fmt.Errorf("failed to parse profile %s: %w", profileName, err)
 	}
+	for key, value := range profile.Scope {
+		if key == "" || value == "" {
+			return nil, nil, fmt.Errorf("profile %s: scope keys and values must not be empty", profileName)
+		}
+	}
+	for _, pattern := range append(append([]string{}, profile.Allow...), profile.Deny...) {
+		if _, err := matchPattern(pattern, ""); err != nil {
+			return nil, nil, fmt.Errorf("profile %s: %w", profileName, err)
+		}
+	}
+	for tool, args := range profile.Defaults {
+		for arg, value := range args {
+			if tool == "" || arg == "" || value == nil {
+				return nil, nil, fmt.Errorf("profile %s: defaults need a tool, an argument, and a value", profileName)
+			}
+		}
+	}
 
 	return &toolsConfig, &profile, nil
 }
@@ -112,6 +151,9 @@ func GetEnabledTools(tc *ToolsConfig, p *Profile) map[string]bool {
 	// Evaluate each tool
 	for _, tools := range tc.Tools {
 		for toolName, toolDef := range tools {
+			if matchAny(p.Allow, toolName) {
+				profileEnabled[toolName] = true
+			}
 			shouldEnable := false
 
 			if p.EnableAll {
@@ -131,7 +173,7 @@ func GetEnabledTools(tc *ToolsConfig, p *Profile) map[string]bool {
 				}
 			}
 
-			if shouldEnable {
+			if shouldEnable && !matchAny(p.Deny, toolName) {
 				enabled[toolName] = true
 			}
 		}
@@ -140,6 +182,34 @@ func GetEnabledTools(tc *ToolsConfig, p *Profile) map[string]bool {
 	return enabled
 }
 
+// matchAny reports whether name matches one of the patterns. Invalid
+// patterns, which LoadToolsConfig rejects, match nothing.
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := matchPattern(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPattern reports whether name matches a glob, or a regular expression
+// between slashes, as a whole.
+func matchPattern(pattern, name string) (bool, error) {
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile("^(?:" + pattern[1:len(pattern)-1] + ")$")
+		if err != nil {
+			return false, fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+		}
+		return re.MatchString(name), nil
+	}
+	ok, err := path.Match(pattern, name)
+	if err != nil {
+		return false, fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+	}
+	return ok, nil
+}
+
 // AllToolNames returns all tool names defined in the config.
 func AllToolNames(tc *ToolsConfig) []string {
 	var names []string
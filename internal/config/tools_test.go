@@ -216,6 +216,66 @@ func TestGetEnabledTools(t *testing.T) {
 			t.Error("expected dash0_dashboards_get to be enabled (default)")
 		}
 	})
+
+	t.Run("PatternProfile", func(t *testing.T) {
+		profile := &Profile{
+			Allow:           []string{"dash0_dashboards_*", "/dash0_logs_(query|send)/"},
+			Deny:            []string{"dash0_*_delete"},
+			DisableUnlisted: true,
+		}
+		enabled := GetEnabledTools(tc, profile)
+
+		for _, name := range []string{"dash0_dashboards_list", "dash0_dashboards_get", "dash0_logs_query", "dash0_logs_send"} {
+			if !enabled[name] {
+				t.Errorf("expected %s to be enabled (allow)", name)
+			}
+		}
+		if enabled["dash0_dashboards_delete"] {
+			t.Error("expected dash0_dashboards_delete to be disabled (deny)")
+		}
+	})
+
+	t.Run("DenyOverridesEnableAll", func(t *testing.T) {
+		profile := &Profile{
+			EnableAll: true,
+			Enable:    []string{"dash0_logs_send"},
+			Deny:      []string{"/.*_(send|delete)/"},
+		}
+		enabled := GetEnabledTools(tc, profile)
+
+		if enabled["dash0_logs_send"] || enabled["dash0_dashboards_delete"] {
+			t.Error("expected denied tools to be disabled")
+		}
+		if !enabled["dash0_logs_query"] {
+			t.Error("expected dash0_logs_query to be enabled (enable_all)")
+		}
+	})
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+		wantErr bool
+	}{
+		{"dash0_*_list", "dash0_dashboards_list", true, false},
+		{"dash0_*_list", "dash0_dashboards_list_all", false, false},
+		{"dash0_logs_query", "dash0_logs_query", true, false},
+		{"/dash0_(logs|spans)_.*/", "dash0_spans_query", true, false},
+		{"/logs/", "dash0_logs_query", false, false},
+		{"[", "dash0_logs_query", false, true},
+		{"/(/", "dash0_logs_query", false, true},
+	}
+	for _, tt := range tests {
+		got, err := matchPattern(tt.pattern, tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("matchPattern(%q, %q) error = %v, wantErr %v", tt.pattern, tt.name, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("matchPattern(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
 }
 
 func TestAllToolNames(t *testing.T) {
@@ -247,3 +307,66 @@ func TestAllToolNames(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadToolsConfig_Scope(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "tools.yaml"), []byte("version: \"1.0\"\n"), 0644); err != nil {
+		t.Fatalf("failed to write tools.yaml: %v", err)
+	}
+	profilesDir := filepath.Join(tmpDir, "profiles")
+	if err := os.MkdirAll(profilesDir, 0755); err != nil {
+		t.Fatalf("failed to create profiles dir: %v", err)
+	}
+
+	scoped := `
+name: checkout
+enable_all: true
+read_only: true
+scope:
+  team: checkout
+  deployment.environment: prod
+defaults:
+  "*":
+    dataset: checkout
+  dash0_golden_signals:
+    service_name: checkout-api
+`
+	if err := os.WriteFile(filepath.Join(profilesDir, "checkout.yaml"), []byte(scoped), 0644); err != nil {
+		t.Fatalf("failed to write profile: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(profilesDir, "bad.yaml"), []byte("name: bad\nscope:\n  team: \"\"\n"), 0644); err != nil {
+		t.Fatalf("failed to write profile: %v", err)
+	}
+
+	_, profile, err := LoadToolsConfig(tmpDir, "checkout")
+	if err != nil {
+		t.Fatalf("LoadToolsConfig() error = %v", err)
+	}
+	if len(profile.Scope) != 2 || profile.Scope["team"] != "checkout" || profile.Scope["deployment.environment"] != "prod" {
+		t.Errorf("Scope = %v, want team=checkout and deployment.environment=prod", profile.Scope)
+	}
+	if !profile.ReadOnly {
+		t.Error("ReadOnly = false, want true")
+	}
+	if profile.Defaults["*"]["dataset"] != "checkout" || profile.Defaults["dash0_golden_signals"]["service_name"] != "checkout-api" {
+		t.Errorf("Defaults = %v, want dataset and service_name", profile.Defaults)
+	}
+
+	if _, _, err := LoadToolsConfig(tmpDir, "bad"); err == nil {
+		t.Error("expected error for empty scope value")
+	}
+
+	if err := os.WriteFile(filepath.Join(profilesDir, "nodefault.yaml"), []byte("name: nodefault\ndefaults:\n  dash0_spans_query:\n    limit:\n"), 0644); err != nil {
+		t.Fatalf("failed to write profile: %v", err)
+	}
+	if _, _, err := LoadToolsConfig(tmpDir, "nodefault"); err == nil {
+		t.Error("expected error for a default without a value")
+	}
+
+	if err := os.WriteFile(filepath.Join(profilesDir, "badpattern.yaml"), []byte("name: badpattern\ndeny:\n  - \"/(/\"\n"), 0644); err != nil {
+		t.Fatalf("failed to write profile: %v", err)
+	}
+	if _, _, err := LoadToolsConfig(tmpDir, "badpattern"); err == nil {
+		t.Error("expected error for an invalid deny pattern")
+	}
+}
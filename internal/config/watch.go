@@ -0,0 +1,79 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce collects the events of one save, which editors often split
+// into a truncate and a write or a rename and a create.
+const watchDebounce = 200 * time.Millisecond
+
+// WatchTools calls onChange after tools.yaml or a profile in configDir
+// changes, until ctx is done. Changes within watchDebounce of each other
+// cause a single call. It returns an error if the directory cannot be
+// watched.
+func WatchTools(ctx context.Context, configDir string, onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch config directory: %w", err)
+	}
+	// Watch the directories rather than the files, so files replaced by a
+	// rename, as many editors save them, stay watched.
+	if err := watcher.Add(configDir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch %s: %w", configDir, err)
+	}
+	profilesDir := filepath.Join(configDir, "profiles")
+	if info, err := os.Stat(profilesDir); err == nil && info.IsDir() {
+		if err := watcher.Add(profilesDir); err != nil {
+			watcher.Close()
+			return fmt.Errorf("failed to watch %s: %w", profilesDir, err)
+		}
+	}
+
+	go func() {
+		defer watcher.Close()
+		var pending <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if isToolsConfig(configDir, event.Name) {
+					pending = time.After(watchDebounce)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				slog.Warn("config watch error", "config_dir", configDir, "error", err)
+			case <-pending:
+				pending = nil
+				onChange()
+			}
+		}
+	}()
+	return nil
+}
+
+// isToolsConfig reports whether path is tools.yaml or a profile in
+// configDir.
+func isToolsConfig(configDir, path string) bool {
+	dir, name := filepath.Split(path)
+	dir = filepath.Clean(dir)
+	if dir == filepath.Clean(configDir) {
+		return name == "tools.yaml"
+	}
+	return dir == filepath.Join(configDir, "profiles") && strings.HasSuffix(name, ".yaml")
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchTools(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "profiles"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan struct{}, 10)
+	if err := WatchTools(ctx, dir, func() { changes <- struct{}{} }); err != nil {
+		t.Fatalf("WatchTools() error = %v", err)
+	}
+
+	expect := func(t *testing.T, want bool) {
+		t.Helper()
+		select {
+		case <-changes:
+			if !want {
+				t.Error("onChange called for an unrelated file")
+			}
+		case <-time.After(watchDebounce + 500*time.Millisecond):
+			if want {
+				t.Error("onChange not called")
+			}
+		}
+	}
+	write := func(t *testing.T, name string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("version: \"1.0\"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("ToolsYAML", func(t *testing.T) {
+		write(t, "tools.yaml")
+		write(t, "tools.yaml")
+		expect(t, true)
+		// Both writes are one change
+		expect(t, false)
+	})
+	t.Run("Profile", func(t *testing.T) {
+		write(t, filepath.Join("profiles", "minimal.yaml"))
+		expect(t, true)
+	})
+	t.Run("Unrelated", func(t *testing.T) {
+		write(t, "notes.yaml")
+		expect(t, false)
+	})
+}
+
+func TestWatchTools_MissingDir(t *testing.T) {
+	if err := WatchTools(context.Background(), filepath.Join(t.TempDir(), "missing"), func() {}); err == nil {
+		t.Error("WatchTools() on a missing directory: want an error")
+	}
+}
+
+func TestIsToolsConfig(t *testing.T) {
+	dir := filepath.Join("etc", "dash0-mcp")
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{filepath.Join(dir, "tools.yaml"), true},
+		{filepath.Join(dir, "profiles", "readonly.yaml"), true},
+		{filepath.Join(dir, "profiles", "readonly.yaml.swp"), false},
+		{filepath.Join(dir, "prompts", "triage.yaml"), false},
+		{filepath.Join(dir, "other.yaml"), false},
+	}
+	for _, tt := range tests {
+		if got := isToolsConfig(dir, tt.path); got != tt.want {
+			t.Errorf("isToolsConfig(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
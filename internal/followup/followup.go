@@ -0,0 +1,59 @@
+// Package followup describes suggested next tool calls that analysis tools
+// attach to their results, so agents can chain into the right query without
+// rebuilding the investigation playbook each session.
+package followup
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Key is the result data key that holds the suggestions.
+const Key = "suggested_follow_ups"
+
+// Suggestion is a tool call worth making next, with ready-to-use arguments.
+type Suggestion struct {
+	Tool      string                 `json:"tool"`
+	Arguments map[string]interface{} `json:"arguments"`
+	Reason    string                 `json:"reason"`
+}
+
+// New creates a suggestion. A nil args map is replaced by an empty one so the
+// arguments always marshal as an object.
+func New(tool string, args map[string]interface{}, reason string) Suggestion {
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	return Suggestion{Tool: tool, Arguments: args, Reason: reason}
+}
+
+// WithDataset sets the dataset argument on every suggestion, so follow-ups
+// query the same dataset as the call that produced them. An empty dataset
+// leaves the suggestions unchanged.
+func WithDataset(suggestions []Suggestion, dataset string) []Suggestion {
+	if dataset == "" {
+		return suggestions
+	}
+	for i := range suggestions {
+		suggestions[i].Arguments["dataset"] = dataset
+	}
+	return suggestions
+}
+
+// Markdown renders the suggestions as a list, or "" when there are none.
+func Markdown(suggestions []Suggestion) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n\n**Suggested follow-ups**\n")
+	for _, s := range suggestions {
+		args, err := json.Marshal(s.Arguments)
+		if err != nil {
+			args = []byte("{}")
+		}
+		fmt.Fprintf(&sb, "- `%s` `%s` — %s\n", s.Tool, args, s.Reason)
+	}
+	return sb.String()
+}
@@ -0,0 +1,46 @@
+package followup
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	s := New("dash0_spans_query", nil, "look closer")
+	if s.Arguments == nil {
+		t.Error("expected empty arguments map, got nil")
+	}
+}
+
+func TestWithDataset(t *testing.T) {
+	list := []Suggestion{
+		New("dash0_spans_query", map[string]interface{}{"service_name": "cart"}, "a"),
+		New("dash0_logs_query", nil, "b"),
+	}
+
+	WithDataset(list, "")
+	if _, ok := list[0].Arguments["dataset"]; ok {
+		t.Error("empty dataset should not be set")
+	}
+
+	WithDataset(list, "otel-demo")
+	for _, s := range list {
+		if s.Arguments["dataset"] != "otel-demo" {
+			t.Errorf("%s: dataset = %v, want otel-demo", s.Tool, s.Arguments["dataset"])
+		}
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	if got := Markdown(nil); got != "" {
+		t.Errorf("Markdown(nil) = %q, want empty", got)
+	}
+
+	got := Markdown([]Suggestion{
+		New("dash0_spans_query", map[string]interface{}{"service_name": "cart", "errors_only": true}, "Errors are up."),
+	})
+	want := "- `dash0_spans_query` `{\"errors_only\":true,\"service_name\":\"cart\"}` — Errors are up."
+	if !strings.Contains(got, "Suggested follow-ups") || !strings.Contains(got, want) {
+		t.Errorf("Markdown() = %q, want it to contain %q", got, want)
+	}
+}
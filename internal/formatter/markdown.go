@@ -82,6 +82,30 @@ func FormatDuration(ms float64) string {
 	return fmt.Sprintf("%.2fs", ms/1000)
 }
 
+// sparkBlocks are the bar glyphs of Sparkline, lowest first.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders counts as a row of bar glyphs scaled to the largest
+// count. Zero counts are drawn as "·" so they stand apart from small ones.
+func Sparkline(counts []int) string {
+	peak := 0
+	for _, c := range counts {
+		if c > peak {
+			peak = c
+		}
+	}
+	var b strings.Builder
+	for _, c := range counts {
+		if c <= 0 {
+			b.WriteRune('·')
+			continue
+		}
+		level := (c*len(sparkBlocks) + peak - 1) / peak
+		b.WriteRune(sparkBlocks[level-1])
+	}
+	return b.String()
+}
+
 // SpanKindName converts an OTLP span kind number to a human-readable name.
 func SpanKindName(kind int) string {
 	switch kind {
@@ -124,6 +124,26 @@ func TestFormatDuration(t *testing.T) {
 	}
 }
 
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		counts   []int
+		expected string
+	}{
+		{nil, ""},
+		{[]int{0, 0}, "··"},
+		{[]int{5}, "█"},
+		{[]int{0, 1, 2, 4, 8}, "·▁▂▄█"},
+		{[]int{1, 100}, "▁█"},
+	}
+
+	for _, tt := range tests {
+		result := Sparkline(tt.counts)
+		if result != tt.expected {
+			t.Errorf("Sparkline(%v) = %q, want %q", tt.counts, result, tt.expected)
+		}
+	}
+}
+
 func TestSpanKindName(t *testing.T) {
 	tests := []struct {
 		kind     int
@@ -0,0 +1,188 @@
+package formatter
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats accepted by the output_format argument of every tool.
+const (
+	OutputJSON     = "json"
+	OutputYAML     = "yaml"
+	OutputMarkdown = "markdown"
+)
+
+// OutputFormats lists the output formats, in the order they are documented.
+var OutputFormats = []string{OutputJSON, OutputYAML, OutputMarkdown}
+
+// maxOutputColumns caps the columns of a generic Markdown table, so wide
+// objects stay readable in a chat window.
+const maxOutputColumns = 8
+
+// preferredColumns come first in a generic Markdown table, in this order.
+var preferredColumns = []string{"id", "metadata.name", "name", "kind", "type", "enabled", "spec.enabled"}
+
+// Output renders a tool result's data in the given format. JSON and YAML
+// render the data itself; Markdown keeps the tool's own rendering when it
+// has one and otherwise draws a table of the list items, or of the fields of
+// a single object. An empty format returns markdown unchanged.
+func Output(format string, data interface{}, markdown string) (string, error) {
+	switch format {
+	case "":
+		return markdown, nil
+	case OutputJSON:
+		b, err := json.Marshal(data)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal result as JSON: %w", err)
+		}
+		return string(b), nil
+	}
+
+	plain, err := plainValue(data)
+	if err != nil {
+		return "", err
+	}
+	switch format {
+	case OutputYAML:
+		b, err := yaml.Marshal(plain)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal result as YAML: %w", err)
+		}
+		return string(b), nil
+	case OutputMarkdown:
+		if markdown != "" {
+			return markdown, nil
+		}
+		return genericTable(plain), nil
+	}
+	return "", fmt.Errorf("output_format must be one of %s, got %q", strings.Join(OutputFormats, ", "), format)
+}
+
+// plainValue converts data to the maps, slices, and scalars JSON decodes to,
+// so structs render with their JSON field names in every format.
+func plainValue(data interface{}) (interface{}, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	var plain interface{}
+	if err := json.Unmarshal(b, &plain); err != nil {
+		return nil, fmt.Errorf("failed to decode result: %w", err)
+	}
+	return plain, nil
+}
+
+// genericTable renders list items as a table with a column per field, or a
+// single object as a table of its fields.
+func genericTable(data interface{}) string {
+	items := extractItems(data)
+	if items == nil {
+		m, ok := data.(map[string]interface{})
+		if !ok {
+			return fmt.Sprintf("`%s`\n", cellValue(data))
+		}
+		fields := map[string]string{}
+		flatten("", m, fields)
+		keys := orderedColumns([]map[string]string{fields}, len(fields))
+		rows := make([][]string, len(keys))
+		for i, k := range keys {
+			rows[i] = []string{k, fields[k]}
+		}
+		return Table("", "", []string{"Field", "Value"}, rows, "")
+	}
+
+	if len(items) == 0 {
+		return "No items found.\n"
+	}
+	flat := make([]map[string]string, len(items))
+	for i, item := range items {
+		flat[i] = map[string]string{}
+		if m, ok := item.(map[string]interface{}); ok {
+			flatten("", m, flat[i])
+		} else {
+			flat[i]["value"] = cellValue(item)
+		}
+	}
+	columns := orderedColumns(flat, maxOutputColumns)
+	rows := make([][]string, len(flat))
+	for i, fields := range flat {
+		rows[i] = make([]string, len(columns))
+		for j, c := range columns {
+			rows[i][j] = fields[c]
+		}
+	}
+	summary := fmt.Sprintf("**%d items**", len(items))
+	return Table("", summary, columns, rows, "")
+}
+
+// flatten collects the scalar fields of m into fields, naming nested fields
+// with dotted paths (e.g. metadata.name). Arrays are kept whole.
+func flatten(prefix string, m map[string]interface{}, fields map[string]string) {
+	for k, v := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if nested, ok := v.(map[string]interface{}); ok && len(nested) > 0 {
+			flatten(key, nested, fields)
+			continue
+		}
+		fields[key] = cellValue(v)
+	}
+}
+
+// orderedColumns returns up to max field names: the preferred columns first,
+// then the rest by how many rows have them and by name.
+func orderedColumns(rows []map[string]string, max int) []string {
+	counts := map[string]int{}
+	for _, fields := range rows {
+		for k := range fields {
+			counts[k]++
+		}
+	}
+	rank := func(k string) int {
+		for i, p := range preferredColumns {
+			if k == p {
+				return i
+			}
+		}
+		return len(preferredColumns)
+	}
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if rank(a) != rank(b) {
+			return rank(a) < rank(b)
+		}
+		if counts[a] != counts[b] {
+			return counts[a] > counts[b]
+		}
+		return a < b
+	})
+	if len(keys) > max {
+		keys = keys[:max]
+	}
+	return keys
+}
+
+// cellValue renders a value for a table cell: scalars as text, anything else
+// as compact JSON, shortened to fit.
+func cellValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return Truncate(strings.ReplaceAll(v, "\n", " "), 60)
+	case float64, bool:
+		return fmt.Sprint(v)
+	}
+	b, _ := json.Marshal(v)
+	return Truncate(string(b), 60)
+}
@@ -0,0 +1,87 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOutput_JSONAndYAML(t *testing.T) {
+	data := struct {
+		Items []interface{} `json:"items"`
+		Count int           `json:"count"`
+	}{
+		Items: []interface{}{map[string]interface{}{"name": "a"}},
+		Count: 1,
+	}
+
+	got, err := Output(OutputJSON, data, "## Summary")
+	if err != nil {
+		t.Fatalf("Output(json): %v", err)
+	}
+	if got != `{"items":[{"name":"a"}],"count":1}` {
+		t.Errorf("Output(json) = %s", got)
+	}
+
+	got, err = Output(OutputYAML, data, "## Summary")
+	if err != nil {
+		t.Fatalf("Output(yaml): %v", err)
+	}
+	// Struct fields keep their JSON names.
+	if got != "count: 1\nitems:\n    - name: a\n" {
+		t.Errorf("Output(yaml) = %q", got)
+	}
+}
+
+func TestOutput_Markdown(t *testing.T) {
+	got, err := Output(OutputMarkdown, nil, "## Tool Summary\n")
+	if err != nil || got != "## Tool Summary\n" {
+		t.Errorf("tool markdown should be kept, got %q, %v", got, err)
+	}
+
+	list := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{
+				"kind":     "Dash0View",
+				"metadata": map[string]interface{}{"name": "errors", "labels": map[string]interface{}{}},
+				"spec":     map[string]interface{}{"type": "spans"},
+			},
+			map[string]interface{}{
+				"kind":     "Dash0View",
+				"metadata": map[string]interface{}{"name": "slow|requests"},
+			},
+		},
+	}
+	got, err = Output(OutputMarkdown, list, "")
+	if err != nil {
+		t.Fatalf("Output(markdown): %v", err)
+	}
+	for _, want := range []string{
+		"**2 items**",
+		"| metadata.name | kind | metadata.labels | spec.type |",
+		"| errors | Dash0View | {} | spans |",
+		"| slow\\|requests | Dash0View |  |  |",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+
+	got, _ = Output(OutputMarkdown, map[string]interface{}{"id": "x1", "spec": map[string]interface{}{"enabled": true}}, "")
+	if !strings.Contains(got, "| Field | Value |") || !strings.Contains(got, "| id | x1 |") || !strings.Contains(got, "| spec.enabled | true |") {
+		t.Errorf("expected a field table, got:\n%s", got)
+	}
+
+	got, _ = Output(OutputMarkdown, []interface{}{}, "")
+	if got != "No items found.\n" {
+		t.Errorf("empty list = %q", got)
+	}
+}
+
+func TestOutput_Unknown(t *testing.T) {
+	if got, err := Output("", nil, "md"); err != nil || got != "md" {
+		t.Errorf("empty format should keep markdown, got %q, %v", got, err)
+	}
+	if _, err := Output("xml", nil, ""); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
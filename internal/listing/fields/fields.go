@@ -0,0 +1,32 @@
+// Package fields reads fields of decoded JSON API objects that endpoints
+// name differently. It imports nothing of this module, so the API client can
+// use it as well as the list helpers built on the client.
+package fields
+
+import "strconv"
+
+// String returns the first non-empty string value of m among keys.
+func String(m map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if s, ok := m[key].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// Number returns the first numeric value of m among keys, a JSON number or
+// a string holding one.
+func Number(m map[string]interface{}, keys ...string) (float64, bool) {
+	for _, key := range keys {
+		switch v := m[key].(type) {
+		case float64:
+			return v, true
+		case string:
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				return f, true
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,23 @@
+package fields
+
+import "testing"
+
+func TestString(t *testing.T) {
+	m := map[string]interface{}{"empty": "", "number": 1.0, "name": "checkout"}
+	if got := String(m, "missing", "empty", "number", "name"); got != "checkout" {
+		t.Errorf("String() = %q, want checkout", got)
+	}
+	if got := String(m, "missing"); got != "" {
+		t.Errorf("String() = %q, want empty", got)
+	}
+}
+
+func TestNumber(t *testing.T) {
+	m := map[string]interface{}{"name": "checkout", "text": "12.5", "value": 3.0}
+	if got, ok := Number(m, "name", "text", "value"); !ok || got != 12.5 {
+		t.Errorf("Number() = %v, %v; want 12.5", got, ok)
+	}
+	if _, ok := Number(m, "name", "missing"); ok {
+		t.Error("Number() found a number among non-numeric fields")
+	}
+}
@@ -0,0 +1,128 @@
+// Package listing gives the results of the list tools one shape, so agents
+// can handle any list tool the same way no matter how the endpoint wraps its
+// items or names its pagination cursor.
+package listing
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/listing/fields"
+)
+
+// Page is the data of every list tool's result.
+type Page struct {
+	Items []interface{} `json:"items"`
+	Count int           `json:"count"`
+	// NextPageToken is passed as page_token to fetch the next page. It is
+	// empty on the last page.
+	NextPageToken string `json:"next_page_token"`
+}
+
+// itemKeys are the fields endpoints wrap their items in.
+var itemKeys = []string{"items", "data", "results", "rules"}
+
+// FromResponse converts an API list response, a bare array or an object
+// wrapping one, into a Page. A response without items is an empty page.
+func FromResponse(data interface{}) Page {
+	page := Page{Items: []interface{}{}}
+	switch d := data.(type) {
+	case []interface{}:
+		page.Items = d
+	case map[string]interface{}:
+		for _, key := range itemKeys {
+			if arr, ok := d[key].([]interface{}); ok {
+				page.Items = arr
+				break
+			}
+		}
+		page.NextPageToken = nextToken(d)
+	}
+	page.Count = len(page.Items)
+	return page
+}
+
+// ItemID returns the identifier the get endpoints accept for a list item,
+// plain or CRD-style: its origin, or else its ID.
+func ItemID(m map[string]interface{}) string {
+	if s := fields.String(m, "origin"); s != "" {
+		return s
+	}
+	if meta, ok := m["metadata"].(map[string]interface{}); ok {
+		if s := fields.String(meta, "origin"); s != "" {
+			return s
+		}
+		if labels, ok := meta["labels"].(map[string]interface{}); ok {
+			if s := fields.String(labels, "dash0.com/origin", "dash0.com/id"); s != "" {
+				return s
+			}
+		}
+	}
+	return fields.String(m, "id")
+}
+
+// ItemName returns the display name of a list item, plain or CRD-style.
+func ItemName(m map[string]interface{}) string {
+	if meta, ok := m["metadata"].(map[string]interface{}); ok {
+		if s := fields.String(meta, "name"); s != "" {
+			return s
+		}
+	}
+	return fields.String(m, "name")
+}
+
+// nextToken returns the cursor of the next page of a wrapped response.
+func nextToken(m map[string]interface{}) string {
+	if cursors, ok := m["cursors"].(map[string]interface{}); ok {
+		if after, ok := cursors["after"].(string); ok && after != "" {
+			return after
+		}
+	}
+	for _, key := range []string{"nextPageToken", "next_page_token", "nextCursor", "next_cursor"} {
+		if s, ok := m[key].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// Adapt replaces the data of a successful list result with its Page and
+// notes a next page in the markdown. Failed results are returned unchanged.
+func Adapt(result *client.ToolResult) *client.ToolResult {
+	if result == nil || !result.Success || result.DryRun {
+		return result
+	}
+	page := FromResponse(result.Data)
+	result.Data = page
+	if page.NextPageToken != "" && result.Markdown != "" {
+		result.Markdown = strings.TrimRight(result.Markdown, "\n") + "\n" + nextPageNote(page.NextPageToken)
+	}
+	return result
+}
+
+// nextPageNote tells the reader of a list result how to fetch the next page.
+func nextPageNote(token string) string {
+	return "\n_More results available. Pass `page_token: \"" + token + "\"` for the next page._\n"
+}
+
+// PageTokenProperty is the page_token argument of the list tools.
+func PageTokenProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"description": "The next_page_token of a previous result, to fetch the next page.",
+	}
+}
+
+// Path adds the page_token argument to the path of a list request.
+func Path(path string, args map[string]interface{}) string {
+	token, _ := args["page_token"].(string)
+	if token == "" {
+		return path
+	}
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return path + sep + "cursor=" + url.QueryEscape(token)
+}
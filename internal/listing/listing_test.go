@@ -0,0 +1,173 @@
+package listing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+)
+
+func TestFromResponse(t *testing.T) {
+	items := []interface{}{map[string]interface{}{"name": "a"}, map[string]interface{}{"name": "b"}}
+	tests := map[string]struct {
+		data      interface{}
+		wantCount int
+		wantToken string
+	}{
+		"array":         {items, 2, ""},
+		"items":         {map[string]interface{}{"items": items, "nextPageToken": "p2"}, 2, "p2"},
+		"rules":         {map[string]interface{}{"rules": items}, 2, ""},
+		"cursors":       {map[string]interface{}{"data": items, "cursors": map[string]interface{}{"after": "c2"}}, 2, "c2"},
+		"next_cursor":   {map[string]interface{}{"results": items[:1], "next_cursor": "n2"}, 1, "n2"},
+		"no items":      {map[string]interface{}{"name": "x"}, 0, ""},
+		"not a listing": {"text", 0, ""},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			page := FromResponse(tt.data)
+			if page.Count != tt.wantCount || len(page.Items) != tt.wantCount || page.NextPageToken != tt.wantToken {
+				t.Errorf("FromResponse() = %+v, want %d items and token %q", page, tt.wantCount, tt.wantToken)
+			}
+			if page.Items == nil {
+				t.Error("Items is nil, want an empty list")
+			}
+		})
+	}
+}
+
+func TestItemIDAndName(t *testing.T) {
+	tests := map[string]struct {
+		item     map[string]interface{}
+		wantID   string
+		wantName string
+	}{
+		"plain":         {map[string]interface{}{"id": "r-1", "name": "HighErrorRate"}, "r-1", "HighErrorRate"},
+		"plain origin":  {map[string]interface{}{"id": "r-1", "origin": "team-rule"}, "team-rule", ""},
+		"metadata":      {map[string]interface{}{"metadata": map[string]interface{}{"name": "Checkout", "origin": "checkout"}}, "checkout", "Checkout"},
+		"labels":        {map[string]interface{}{"metadata": map[string]interface{}{"labels": map[string]interface{}{"dash0.com/id": "d-1"}}, "name": "Cart"}, "d-1", "Cart"},
+		"label origin":  {map[string]interface{}{"metadata": map[string]interface{}{"labels": map[string]interface{}{"dash0.com/id": "d-1", "dash0.com/origin": "cart"}}}, "cart", ""},
+		"no identifier": {map[string]interface{}{"kind": "Dashboard"}, "", ""},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := ItemID(tt.item); got != tt.wantID {
+				t.Errorf("ItemID() = %q, want %q", got, tt.wantID)
+			}
+			if got := ItemName(tt.item); got != tt.wantName {
+				t.Errorf("ItemName() = %q, want %q", got, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestAdapt(t *testing.T) {
+	result := Adapt(&client.ToolResult{
+		Success:  true,
+		Data:     map[string]interface{}{"items": []interface{}{"a"}, "nextCursor": "c2"},
+		Markdown: "## Views\n",
+	})
+	if page, ok := result.Data.(Page); !ok || page.Count != 1 || page.NextPageToken != "c2" {
+		t.Errorf("Data = %+v, want a page with 1 item and token c2", result.Data)
+	}
+	if result.Markdown != "## Views\n\n_More results available. Pass `page_token: \"c2\"` for the next page._\n" {
+		t.Errorf("Markdown = %q", result.Markdown)
+	}
+
+	failed := client.ErrorResult(404, "not found")
+	if Adapt(failed) != failed || failed.Data != nil {
+		t.Error("Adapt() changed a failed result")
+	}
+}
+
+func TestPath(t *testing.T) {
+	tests := []struct {
+		path string
+		args map[string]interface{}
+		want string
+	}{
+		{"/api/views", map[string]interface{}{}, "/api/views"},
+		{"/api/views", map[string]interface{}{"page_token": "a b"}, "/api/views?cursor=a+b"},
+		{"/api/views?dataset=x", map[string]interface{}{"page_token": "c2"}, "/api/views?dataset=x&cursor=c2"},
+	}
+	for _, tt := range tests {
+		if got := Path(tt.path, tt.args); got != tt.want {
+			t.Errorf("Path(%q, %v) = %q, want %q", tt.path, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestProject(t *testing.T) {
+	enabled := false
+	tests := map[string]struct {
+		item interface{}
+		want Summary
+	}{
+		"crd": {
+			map[string]interface{}{
+				"kind": "Dash0SyntheticCheck",
+				"metadata": map[string]interface{}{
+					"name":   "api-health",
+					"labels": map[string]interface{}{"dash0.com/id": "sc-1", "dash0.com/updated-at": "2026-01-02T03:04:05Z"},
+				},
+				"spec": map[string]interface{}{"enabled": false, "plugin": map[string]interface{}{"kind": "http"}},
+			},
+			Summary{ID: "sc-1", Name: "api-health", Type: "http", Enabled: &enabled, LastModified: "2026-01-02T03:04:05Z"},
+		},
+		"view": {
+			map[string]interface{}{"kind": "Dash0View", "metadata": map[string]interface{}{"name": "errors"}, "spec": map[string]interface{}{"type": "spans"}},
+			Summary{Name: "errors", Type: "spans"},
+		},
+		"plain": {
+			map[string]interface{}{"id": "ds-1", "name": "production", "createdAt": "2026-01-01"},
+			Summary{ID: "ds-1", Name: "production", LastModified: "2026-01-01"},
+		},
+		"scalar": {"default", Summary{Name: "default"}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := Project(tt.item)
+			if (got.Enabled == nil) != (tt.want.Enabled == nil) || (got.Enabled != nil && *got.Enabled != *tt.want.Enabled) {
+				t.Errorf("Enabled = %v, want %v", got.Enabled, tt.want.Enabled)
+			}
+			got.Enabled, tt.want.Enabled = nil, nil
+			if got != tt.want {
+				t.Errorf("Project() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	newResult := func() *client.ToolResult {
+		return Adapt(&client.ToolResult{
+			Success: true,
+			Data: map[string]interface{}{"items": []interface{}{
+				map[string]interface{}{"kind": "Dash0View", "metadata": map[string]interface{}{"name": "errors"}, "spec": map[string]interface{}{"type": "spans", "filter": []interface{}{"x"}}},
+			}, "nextCursor": "c2"},
+			Markdown: "## Views\n",
+		})
+	}
+
+	if result := Summarize(newResult(), map[string]interface{}{}, "Views"); result.Markdown == "" || result.Data.(Page).Items[0].(map[string]interface{})["spec"] == nil {
+		t.Error("Summarize() without summary changed the result")
+	}
+
+	result := Summarize(newResult(), map[string]interface{}{"summary": true}, "Views")
+	page := result.Data.(Page)
+	if s, ok := page.Items[0].(Summary); !ok || s.Name != "errors" || s.Type != "spans" {
+		t.Errorf("Items = %+v, want a Summary of the view", page.Items)
+	}
+	if page.Count != 1 || page.NextPageToken != "c2" {
+		t.Errorf("page = %+v, want 1 item and token c2", page)
+	}
+	for _, want := range []string{"## Views", "| ID | Name | Type | Enabled | Last Modified |", "|  | errors | spans |  |  |", "`page_token: \"c2\"`"} {
+		if !strings.Contains(result.Markdown, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, result.Markdown)
+		}
+	}
+
+	failed := client.ErrorResult(404, "not found")
+	if Summarize(failed, map[string]interface{}{"summary": true}, "Views") != failed {
+		t.Error("Summarize() changed a failed result")
+	}
+}
@@ -0,0 +1,98 @@
+package listing
+
+import (
+	"fmt"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/formatter"
+)
+
+// Summary is a list item reduced to the fields needed to pick one, for the
+// summary mode of the list tools.
+type Summary struct {
+	ID           string `json:"id,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Type         string `json:"type,omitempty"`
+	Enabled      *bool  `json:"enabled,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// SummaryProperty is the summary argument of the list tools.
+func SummaryProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "boolean",
+		"description": "Return only the id, name, type, enabled state, and last-modified time of each item instead of the full objects (default: false). Use it to browse large organizations with far fewer tokens, then get the objects you need.",
+	}
+}
+
+// Summarize reduces the items of an adapted list result to Summaries when
+// the summary argument is set, and renders them as a table titled title.
+// Other results are returned unchanged.
+func Summarize(result *client.ToolResult, args map[string]interface{}, title string) *client.ToolResult {
+	if on, _ := args["summary"].(bool); !on || result == nil || !result.Success || result.DryRun {
+		return result
+	}
+	page, ok := result.Data.(Page)
+	if !ok {
+		return result
+	}
+
+	rows := make([][]string, len(page.Items))
+	for i, item := range page.Items {
+		s := Project(item)
+		page.Items[i] = s
+		enabled := ""
+		if s.Enabled != nil {
+			enabled = "no"
+			if *s.Enabled {
+				enabled = "yes"
+			}
+		}
+		rows[i] = []string{s.ID, s.Name, s.Type, enabled, s.LastModified}
+	}
+	result.Data = page
+	summary := fmt.Sprintf("**%d items** (summary)", page.Count)
+	result.Markdown = formatter.Table(title, summary, []string{"ID", "Name", "Type", "Enabled", "Last Modified"}, rows, "")
+	if page.NextPageToken != "" {
+		result.Markdown += nextPageNote(page.NextPageToken)
+	}
+	return result
+}
+
+// Project reduces a list item, a Dash0 CRD-style object or a plain API
+// object, to its Summary. Fields the item does not have are left empty.
+func Project(item interface{}) Summary {
+	m, ok := item.(map[string]interface{})
+	if !ok {
+		return Summary{Name: fmt.Sprint(item)}
+	}
+	metadata, _ := m["metadata"].(map[string]interface{})
+	labels, _ := metadata["labels"].(map[string]interface{})
+	spec, _ := m["spec"].(map[string]interface{})
+	plugin, _ := spec["plugin"].(map[string]interface{})
+	display, _ := spec["display"].(map[string]interface{})
+
+	s := Summary{
+		ID:           firstNonEmpty(labels["dash0.com/id"], metadata["id"], m["id"], labels["dash0.com/origin"], m["origin"]),
+		Name:         firstNonEmpty(metadata["name"], m["name"], display["name"]),
+		Type:         firstNonEmpty(plugin["kind"], spec["type"], m["type"], m["kind"]),
+		LastModified: firstNonEmpty(labels["dash0.com/updated-at"], metadata["updatedAt"], m["updatedAt"], labels["dash0.com/created-at"], metadata["createdAt"], m["createdAt"]),
+	}
+	for _, v := range []interface{}{spec["enabled"], m["enabled"]} {
+		if b, ok := v.(bool); ok {
+			s.Enabled = &b
+			break
+		}
+	}
+	return s
+}
+
+// firstNonEmpty returns the first non-empty string among values.
+func firstNonEmpty(values ...interface{}) string {
+	for _, v := range values {
+		if s, ok := v.(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
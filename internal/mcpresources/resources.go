@@ -0,0 +1,181 @@
+// Package mcpresources exposes Dash0 configuration objects as MCP resources,
+// so clients can browse dashboards, views, check rules, and synthetic checks
+// with resources/list and resources/read alongside the tools that manage them.
+package mcpresources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/listing"
+	mcp "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	uriScheme    = "dash0://"
+	jsonMIMEType = "application/json"
+)
+
+// Collection is a Dash0 API object type exposed as MCP resources.
+type Collection struct {
+	// Name is the URI segment, e.g. "dashboards" in dash0://dashboards/{id}.
+	Name string
+	// Title is the human-readable resource name.
+	Title string
+	// Path is the Dash0 API path for listing and getting objects.
+	Path string
+	// ListTool and GetTool are the tools whose reads the index resource and
+	// the item template repeat; each is served only while its tool is enabled.
+	ListTool string
+	GetTool  string
+}
+
+// Collections lists the object types exposed as resources.
+var Collections = []Collection{
+	{Name: "dashboards", Title: "Dash0 Dashboards", Path: "/api/dashboards", ListTool: "dash0_dashboards_list", GetTool: "dash0_dashboards_get"},
+	{Name: "views", Title: "Dash0 Views", Path: "/api/views", ListTool: "dash0_views_list", GetTool: "dash0_views_get"},
+	{Name: "check-rules", Title: "Dash0 Check Rules", Path: "/api/alerting/check-rules", ListTool: "dash0_alerting_check_rules_list", GetTool: "dash0_alerting_check_rules_get"},
+	{Name: "synthetic-checks", Title: "Dash0 Synthetic Checks", Path: "/api/synthetic-checks", ListTool: "dash0_synthetic_checks_list", GetTool: "dash0_synthetic_checks_get"},
+}
+
+// IndexEntry is one object in a collection index.
+type IndexEntry struct {
+	URI  string `json:"uri"`
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// Register sets the server's resources to a static index resource
+// (dash0://{collection}) for every collection whose list tool is enabled and
+// an item template (dash0://{collection}/{id}) for every collection whose get
+// tool is enabled, replacing those of an earlier call. Call it again when the
+// enabled tools change.
+func Register(s *server.MCPServer, c *client.Client, isEnabled func(string) bool) {
+	var resources []server.ServerResource
+	var templates []server.ServerResourceTemplate
+	for _, col := range Collections {
+		if isEnabled(col.ListTool) {
+			resources = append(resources, server.ServerResource{
+				Resource: mcp.NewResource(uriScheme+col.Name, col.Title,
+					mcp.WithResourceDescription(fmt.Sprintf("Index of all %s with their resource URIs.", strings.ReplaceAll(col.Name, "-", " "))),
+					mcp.WithMIMEType(jsonMIMEType),
+				),
+				Handler: collectionHandler(c, col),
+			})
+		}
+		if isEnabled(col.GetTool) {
+			templates = append(templates, server.ServerResourceTemplate{
+				Template: mcp.NewResourceTemplate(uriScheme+col.Name+"/{id}", strings.TrimSuffix(col.Title, "s"),
+					mcp.WithTemplateDescription(fmt.Sprintf("A single object from %s by origin or ID.", uriScheme+col.Name)),
+					mcp.WithTemplateMIMEType(jsonMIMEType),
+				),
+				Handler: itemHandler(c, col),
+			})
+		}
+	}
+	s.SetResources(resources...)
+	s.SetResourceTemplates(templates...)
+}
+
+// collectionHandler lists the collection and returns an index of item URIs.
+func collectionHandler(c *client.Client, col Collection) server.ResourceHandlerFunc {
+	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		result := c.Get(ctx, col.Path)
+		if !result.Success {
+			return nil, resultError(result)
+		}
+
+		entries := []IndexEntry{}
+		for _, item := range listing.FromResponse(result.Data).Items {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			id := listing.ItemID(m)
+			if id == "" {
+				continue
+			}
+			entries = append(entries, IndexEntry{
+				URI:  uriScheme + col.Name + "/" + url.PathEscape(id),
+				ID:   id,
+				Name: listing.ItemName(m),
+			})
+		}
+
+		return jsonContents(req.Params.URI, map[string]interface{}{
+			"collection": col.Name,
+			"count":      len(entries),
+			"items":      entries,
+		})
+	}
+}
+
+// itemHandler fetches a single object by the {id} in the URI.
+func itemHandler(c *client.Client, col Collection) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		id := templateArg(req.Params.Arguments, "id")
+		if id == "" {
+			id = strings.TrimPrefix(req.Params.URI, uriScheme+col.Name+"/")
+		}
+		if unescaped, err := url.PathUnescape(id); err == nil {
+			id = unescaped
+		}
+		if id == "" {
+			return nil, fmt.Errorf("resource URI %q is missing an id", req.Params.URI)
+		}
+
+		result := c.Get(ctx, fmt.Sprintf("%s/%s", col.Path, url.PathEscape(id)))
+		if !result.Success {
+			return nil, resultError(result)
+		}
+		return jsonContents(req.Params.URI, result.Data)
+	}
+}
+
+// templateArg reads a URI template variable, which mcp-go passes as a string
+// or a list of strings depending on the template expression.
+func templateArg(args map[string]interface{}, name string) string {
+	switch v := args[name].(type) {
+	case string:
+		return v
+	case []string:
+		if len(v) > 0 {
+			return v[0]
+		}
+	case []interface{}:
+		if len(v) > 0 {
+			s, _ := v[0].(string)
+			return s
+		}
+	}
+	return ""
+}
+
+func jsonContents(uri string, v interface{}) ([]mcp.ResourceContents, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal resource: %w", err)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      uri,
+			MIMEType: jsonMIMEType,
+			Text:     string(data),
+		},
+	}, nil
+}
+
+func resultError(result *client.ToolResult) error {
+	if result.Error == nil {
+		return fmt.Errorf("request failed")
+	}
+	if result.Error.Title != "" {
+		return fmt.Errorf("%s: %s", result.Error.Title, result.Error.Detail)
+	}
+	return fmt.Errorf("%d: %s", result.Error.StatusCode, result.Error.Detail)
+}
@@ -0,0 +1,189 @@
+package mcpresources
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func newTestServer(t *testing.T) *server.MCPServer {
+	t.Helper()
+	return newTestServerWith(t, func(string) bool { return true })
+}
+
+func newTestServerWith(t *testing.T, isEnabled func(string) bool) *server.MCPServer {
+	t.Helper()
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/dashboards":
+			w.Write([]byte(`[
+				{"metadata": {"name": "Checkout", "labels": {"dash0.com/origin": "checkout-overview"}}},
+				{"id": "dash-2", "name": "Payments"},
+				{"name": "no identifier"}
+			]`))
+		case "/api/dashboards/checkout-overview":
+			w.Write([]byte(`{"kind": "Dashboard", "metadata": {"name": "Checkout"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"message": "not found"}}`))
+		}
+	}))
+	t.Cleanup(api.Close)
+
+	s := server.NewMCPServer("test", "0.0.0", server.WithResourceCapabilities(false, false))
+	Register(s, client.NewWithBaseURL(api.URL, "test-token"), isEnabled)
+	return s
+}
+
+func call(t *testing.T, s *server.MCPServer, method string, params interface{}) map[string]interface{} {
+	t.Helper()
+	msg, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.Marshal(s.HandleMessage(context.Background(), msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp map[string]interface{}
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func readText(t *testing.T, resp map[string]interface{}) string {
+	t.Helper()
+	result, ok := resp["result"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected result, got %v", resp)
+	}
+	contents := result["contents"].([]interface{})
+	if len(contents) != 1 {
+		t.Fatalf("expected 1 content, got %d", len(contents))
+	}
+	c := contents[0].(map[string]interface{})
+	if c["mimeType"] != "application/json" {
+		t.Errorf("mimeType = %v, want application/json", c["mimeType"])
+	}
+	return c["text"].(string)
+}
+
+func TestRegister_ListsCollections(t *testing.T) {
+	s := newTestServer(t)
+
+	resp := call(t, s, "resources/list", map[string]interface{}{})
+	resources := resp["result"].(map[string]interface{})["resources"].([]interface{})
+	if len(resources) != len(Collections) {
+		t.Fatalf("expected %d resources, got %d", len(Collections), len(resources))
+	}
+
+	resp = call(t, s, "resources/templates/list", map[string]interface{}{})
+	templates := resp["result"].(map[string]interface{})["resourceTemplates"].([]interface{})
+	if len(templates) != len(Collections) {
+		t.Fatalf("expected %d templates, got %d", len(Collections), len(templates))
+	}
+	found := false
+	for _, tmpl := range templates {
+		if tmpl.(map[string]interface{})["uriTemplate"] == "dash0://dashboards/{id}" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected dash0://dashboards/{id} template")
+	}
+}
+
+func TestRegister_ReadCollectionIndex(t *testing.T) {
+	s := newTestServer(t)
+
+	text := readText(t, call(t, s, "resources/read", map[string]interface{}{"uri": "dash0://dashboards"}))
+
+	var index struct {
+		Collection string       `json:"collection"`
+		Count      int          `json:"count"`
+		Items      []IndexEntry `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(text), &index); err != nil {
+		t.Fatalf("invalid index JSON: %v", err)
+	}
+	if index.Collection != "dashboards" || index.Count != 2 {
+		t.Fatalf("unexpected index: %+v", index)
+	}
+	if index.Items[0].URI != "dash0://dashboards/checkout-overview" || index.Items[0].Name != "Checkout" {
+		t.Errorf("unexpected first entry: %+v", index.Items[0])
+	}
+	if index.Items[1].URI != "dash0://dashboards/dash-2" || index.Items[1].Name != "Payments" {
+		t.Errorf("unexpected second entry: %+v", index.Items[1])
+	}
+}
+
+func TestRegister_ReadItem(t *testing.T) {
+	s := newTestServer(t)
+
+	text := readText(t, call(t, s, "resources/read", map[string]interface{}{"uri": "dash0://dashboards/checkout-overview"}))
+	if !strings.Contains(text, `"kind": "Dashboard"`) {
+		t.Errorf("expected dashboard JSON, got %s", text)
+	}
+}
+
+func TestRegister_ReadItemNotFound(t *testing.T) {
+	s := newTestServer(t)
+
+	resp := call(t, s, "resources/read", map[string]interface{}{"uri": "dash0://views/missing"})
+	if _, ok := resp["error"]; !ok {
+		t.Fatalf("expected error response, got %v", resp)
+	}
+}
+
+func TestRegister_OnlyEnabledTools(t *testing.T) {
+	enabled := map[string]bool{"dash0_dashboards_list": true, "dash0_views_get": true}
+	s := newTestServerWith(t, func(name string) bool { return enabled[name] })
+
+	resp := call(t, s, "resources/list", map[string]interface{}{})
+	resources := resp["result"].(map[string]interface{})["resources"].([]interface{})
+	if len(resources) != 1 || resources[0].(map[string]interface{})["uri"] != "dash0://dashboards" {
+		t.Fatalf("expected only dash0://dashboards, got %v", resources)
+	}
+
+	resp = call(t, s, "resources/templates/list", map[string]interface{}{})
+	templates := resp["result"].(map[string]interface{})["resourceTemplates"].([]interface{})
+	if len(templates) != 1 || templates[0].(map[string]interface{})["uriTemplate"] != "dash0://views/{id}" {
+		t.Fatalf("expected only dash0://views/{id}, got %v", templates)
+	}
+
+	resp = call(t, s, "resources/read", map[string]interface{}{"uri": "dash0://dashboards/checkout-overview"})
+	if _, ok := resp["error"]; !ok {
+		t.Errorf("expected error reading a dashboard without dash0_dashboards_get, got %v", resp)
+	}
+}
+
+func TestRegister_ReplacesResources(t *testing.T) {
+	s := newTestServer(t)
+	Register(s, client.NewWithBaseURL("http://localhost", "test-token"), func(name string) bool {
+		return name == "dash0_views_list"
+	})
+
+	resp := call(t, s, "resources/list", map[string]interface{}{})
+	resources := resp["result"].(map[string]interface{})["resources"].([]interface{})
+	if len(resources) != 1 || resources[0].(map[string]interface{})["uri"] != "dash0://views" {
+		t.Fatalf("expected only dash0://views, got %v", resources)
+	}
+
+	resp = call(t, s, "resources/templates/list", map[string]interface{}{})
+	templates := resp["result"].(map[string]interface{})["resourceTemplates"].([]interface{})
+	if len(templates) != 0 {
+		t.Fatalf("expected no templates, got %v", templates)
+	}
+}
@@ -0,0 +1,316 @@
+// Package mockapi is an in-memory fake of the Dash0 API, serving the
+// endpoints the tools use so that the MCP server can be run end to end
+// without a Dash0 account: CRUD of dashboards, views, check rules,
+// synthetic checks, and sampling rules, span and log queries over ingested
+// and seeded telemetry, alerts, and PromQL range queries.
+//
+// It is a development aid, not a reference implementation. Queries support
+// the is, is_not, contains, starts_with, gt, and lt filter operators over
+// attributes and the trace and span IDs; datasets are accepted but not kept
+// apart; ingestion accepts OTLP JSON only; and metrics are synthetic.
+package mockapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxBodySize caps the request bodies the server reads.
+const maxBodySize = 16 << 20
+
+// collectionPaths are the endpoints of the objects kept by the server. Each
+// serves list and create on the path and get, update, and delete on
+// path/{id}.
+var collectionPaths = []string{
+	"/api/dashboards",
+	"/api/views",
+	"/api/alerting/check-rules",
+	"/api/synthetic-checks",
+	"/api/sampling-rules",
+}
+
+// importPaths map the import endpoints to the collection they create in.
+var importPaths = map[string]string{
+	"/api/import/dashboard":       "/api/dashboards",
+	"/api/import/view":            "/api/views",
+	"/api/import/check-rule":      "/api/alerting/check-rules",
+	"/api/import/synthetic-check": "/api/synthetic-checks",
+}
+
+// Labels of the ID and origin of a Kubernetes-style object.
+const (
+	idLabel     = "dash0.com/id"
+	originLabel = "dash0.com/origin"
+)
+
+// Server is the fake API. It is an http.Handler; its zero value is not
+// usable, create one with New.
+type Server struct {
+	mu          sync.Mutex
+	collections map[string][]map[string]interface{}
+	spans       []record
+	logs        []record
+	alerts      []interface{}
+	nextID      int
+	now         func() time.Time
+}
+
+// New returns a server seeded with demo data: a few services with traces
+// and logs from the last minutes, a dashboard, a view, a check rule with a
+// firing alert, and a synthetic check.
+func New() *Server {
+	s := &Server{collections: map[string][]map[string]interface{}{}, now: time.Now}
+	for _, path := range collectionPaths {
+		s.collections[path] = []map[string]interface{}{}
+	}
+	s.seed()
+	return s
+}
+
+// ServeHTTP implements http.Handler. Any bearer token is accepted.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		writeError(w, http.StatusUnauthorized, "missing bearer token")
+		return
+	}
+	var body interface{}
+	if r.Method == http.MethodPost || r.Method == http.MethodPut {
+		var err error
+		if body, err = readBody(r); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := r.URL.Path
+	switch {
+	case path == "/api/spans" || path == "/v1/traces":
+		s.telemetry(w, r, spanSignal, &s.spans, body)
+	case path == "/api/logs" || path == "/v1/logs":
+		s.telemetry(w, r, logSignal, &s.logs, body)
+	case path == "/api/alerting/alerts":
+		s.listAlerts(w, r)
+	case path == "/api/prometheus/api/v1/query_range":
+		s.queryRange(w, r)
+	case importPaths[path] != "":
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		s.create(w, importPaths[path], body)
+	default:
+		s.collection(w, r, body)
+	}
+}
+
+// collection serves the CRUD endpoints of collectionPaths.
+func (s *Server) collection(w http.ResponseWriter, r *http.Request, body interface{}) {
+	for _, base := range collectionPaths {
+		if r.URL.Path == base {
+			switch r.Method {
+			case http.MethodGet:
+				writeJSON(w, http.StatusOK, map[string]interface{}{"items": s.collections[base]})
+			case http.MethodPost:
+				s.create(w, base, body)
+			default:
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			}
+			return
+		}
+		if !strings.HasPrefix(r.URL.Path, base+"/") {
+			continue
+		}
+		id, err := url.PathUnescape(strings.TrimPrefix(r.URL.EscapedPath(), base+"/"))
+		if err != nil || id == "" || strings.Contains(id, "/") {
+			break
+		}
+		i := s.find(base, id)
+		if i < 0 {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("%s %s not found", strings.TrimPrefix(base, "/api/"), id))
+			return
+		}
+		items := s.collections[base]
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, items[i])
+		case http.MethodPut:
+			obj, ok := body.(map[string]interface{})
+			if !ok {
+				writeError(w, http.StatusBadRequest, "body must be a JSON object")
+				return
+			}
+			setID(obj, objectID(items[i]))
+			items[i] = obj
+			writeJSON(w, http.StatusOK, obj)
+		case http.MethodDelete:
+			s.collections[base] = append(items[:i], items[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+	writeError(w, http.StatusNotFound, "no such endpoint: "+r.Method+" "+r.URL.Path)
+}
+
+// create adds an object to a collection with a new ID and returns it.
+func (s *Server) create(w http.ResponseWriter, base string, body interface{}) {
+	obj, ok := body.(map[string]interface{})
+	if !ok {
+		writeError(w, http.StatusBadRequest, "body must be a JSON object")
+		return
+	}
+	s.add(base, obj)
+	writeJSON(w, http.StatusOK, obj)
+}
+
+// add gives obj a new ID and appends it to a collection.
+func (s *Server) add(base string, obj map[string]interface{}) {
+	s.nextID++
+	setID(obj, fmt.Sprintf("mock-%04d", s.nextID))
+	s.collections[base] = append(s.collections[base], obj)
+}
+
+// find returns the index of the object of a collection with the ID or
+// origin id, or -1.
+func (s *Server) find(base, id string) int {
+	for i, obj := range s.collections[base] {
+		if objectID(obj) == id || objectOrigin(obj) == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// setID sets the ID of an object: the dash0.com/id label of a
+// Kubernetes-style object with metadata, otherwise its id field.
+func setID(obj map[string]interface{}, id string) {
+	meta, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		obj["id"] = id
+		return
+	}
+	labels, ok := meta["labels"].(map[string]interface{})
+	if !ok {
+		labels = map[string]interface{}{}
+		meta["labels"] = labels
+	}
+	labels[idLabel] = id
+}
+
+// objectID returns the ID set with setID.
+func objectID(obj map[string]interface{}) string {
+	if id := metadataLabel(obj, idLabel); id != "" {
+		return id
+	}
+	id, _ := obj["id"].(string)
+	return id
+}
+
+// objectOrigin returns the origin an object was created with, if any.
+func objectOrigin(obj map[string]interface{}) string {
+	if origin := metadataLabel(obj, originLabel); origin != "" {
+		return origin
+	}
+	origin, _ := obj["origin"].(string)
+	return origin
+}
+
+func metadataLabel(obj map[string]interface{}, key string) string {
+	meta, _ := obj["metadata"].(map[string]interface{})
+	labels, _ := meta["labels"].(map[string]interface{})
+	v, _ := labels[key].(string)
+	return v
+}
+
+// listAlerts serves the active alerts, filtered by the state parameter.
+func (s *Server) listAlerts(w http.ResponseWriter, r *http.Request) {
+	state := r.URL.Query().Get("state")
+	alerts := []interface{}{}
+	for _, a := range s.alerts {
+		if state == "" || a.(map[string]interface{})["state"] == state {
+			alerts = append(alerts, a)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"items": alerts})
+}
+
+// queryRange serves a PromQL range query with one synthetic series, a wave
+// around 0.05, whatever the query.
+func (s *Server) queryRange(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	start, err1 := strconv.ParseFloat(q.Get("start"), 64)
+	end, err2 := strconv.ParseFloat(q.Get("end"), 64)
+	step, err3 := strconv.ParseFloat(q.Get("step"), 64)
+	if err1 != nil || err2 != nil || err3 != nil || step <= 0 || end < start {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"status": "error", "errorType": "bad_data", "error": "start, end, and step must be unix seconds with start <= end and step > 0"})
+		return
+	}
+	if (end-start)/step > 11000 {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"status": "error", "errorType": "bad_data", "error": "exceeded maximum resolution of 11,000 points per timeseries"})
+		return
+	}
+	values := []interface{}{}
+	for t := start; t <= end; t += step {
+		v := 0.05 + 0.04*math.Sin(t/600)
+		values = append(values, []interface{}{t, strconv.FormatFloat(v, 'f', 4, 64)})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"data": map[string]interface{}{
+			"resultType": "matrix",
+			"result": []interface{}{map[string]interface{}{
+				"metric": map[string]interface{}{"service_name": "checkout"},
+				"values": values,
+			}},
+		},
+	})
+}
+
+// readBody decodes the JSON body of a request, or returns nil for an empty
+// one.
+func readBody(r *http.Request) (interface{}, error) {
+	if enc := r.Header.Get("Content-Encoding"); enc != "" && enc != "identity" {
+		return nil, fmt.Errorf("the mock API does not accept Content-Encoding %s; send uncompressed JSON", enc)
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "" && !strings.Contains(ct, "json") {
+		return nil, fmt.Errorf("the mock API accepts JSON only, got Content-Type %s", ct)
+	}
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("invalid JSON body: %w", err)
+	}
+	return v, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("could not write mock API response", "error", err)
+	}
+}
+
+// writeError writes an error in the API's {"error": {...}} format.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{"error": map[string]interface{}{"code": status, "message": message}})
+}
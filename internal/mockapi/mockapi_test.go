@@ -0,0 +1,169 @@
+package mockapi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/api/alerting"
+	"github.com/npcomplete777/dash0-mcp/api/dashboards"
+	"github.com/npcomplete777/dash0-mcp/api/spans"
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/listing"
+	"github.com/npcomplete777/dash0-mcp/internal/mockapi"
+)
+
+func newClient(t *testing.T) *client.Client {
+	t.Helper()
+	server := httptest.NewServer(mockapi.New())
+	t.Cleanup(server.Close)
+	return client.NewWithBaseURL(server.URL, "mock")
+}
+
+func TestServer_Unauthorized(t *testing.T) {
+	server := httptest.NewServer(mockapi.New())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/dashboards")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", resp.StatusCode)
+	}
+}
+
+func TestServer_DashboardCRUD(t *testing.T) {
+	ctx := context.Background()
+	tools := dashboards.New(newClient(t))
+
+	created := tools.CreateDashboardHandler(ctx, map[string]interface{}{"body": map[string]interface{}{
+		"kind":     "PersesDashboard",
+		"metadata": map[string]interface{}{"name": "checkout"},
+		"spec":     map[string]interface{}{"display": map[string]interface{}{"name": "Checkout"}, "panels": []interface{}{}},
+	}})
+	if !created.Success {
+		t.Fatalf("create failed: %+v", created.Error)
+	}
+	meta := created.Data.(map[string]interface{})["metadata"].(map[string]interface{})
+	id := meta["labels"].(map[string]interface{})["dash0.com/id"].(string)
+
+	list := tools.ListDashboardsHandler(ctx, map[string]interface{}{})
+	if !list.Success {
+		t.Fatalf("list failed: %+v", list.Error)
+	}
+	if got := list.Data.(listing.Page).Count; got != 2 {
+		t.Errorf("listed %d dashboards, want the seeded one and the created one", got)
+	}
+
+	updated := tools.UpdateDashboardHandler(ctx, map[string]interface{}{"origin_or_id": id, "body": map[string]interface{}{
+		"kind":     "PersesDashboard",
+		"metadata": map[string]interface{}{"name": "checkout"},
+		"spec":     map[string]interface{}{"display": map[string]interface{}{"name": "Checkout v2"}, "panels": []interface{}{}},
+	}})
+	if !updated.Success {
+		t.Fatalf("update failed: %+v", updated.Error)
+	}
+	got := tools.GetDashboardHandler(ctx, map[string]interface{}{"origin_or_id": id})
+	if !got.Success {
+		t.Fatalf("get failed: %+v", got.Error)
+	}
+	spec := got.Data.(map[string]interface{})["spec"].(map[string]interface{})
+	if name := spec["display"].(map[string]interface{})["name"]; name != "Checkout v2" {
+		t.Errorf("name = %v, want the updated one", name)
+	}
+
+	if deleted := tools.DeleteDashboardHandler(ctx, map[string]interface{}{"origin_or_id": id}); !deleted.Success {
+		t.Fatalf("delete failed: %+v", deleted.Error)
+	}
+	if missing := tools.GetDashboardHandler(ctx, map[string]interface{}{"origin_or_id": id}); missing.Success || missing.Error.StatusCode != http.StatusNotFound {
+		t.Errorf("get after delete = %+v, want 404", missing.Error)
+	}
+}
+
+func TestServer_SpansIngestAndQuery(t *testing.T) {
+	ctx := context.Background()
+	tools := spans.New(newClient(t))
+
+	now := time.Now()
+	traceID := "0af7651916cd43dd8448eb211c80319c"
+	ingested := tools.PostSpansHandler(ctx, map[string]interface{}{"body": map[string]interface{}{
+		"resourceSpans": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{"attributes": []interface{}{
+				map[string]interface{}{"key": "service.name", "value": map[string]interface{}{"stringValue": "payments"}},
+			}},
+			"scopeSpans": []interface{}{map[string]interface{}{
+				"spans": []interface{}{map[string]interface{}{
+					"traceId":           traceID,
+					"spanId":            "b7ad6b7169203331",
+					"name":              "Charge",
+					"kind":              2,
+					"startTimeUnixNano": strconv.FormatInt(now.Add(-time.Second).UnixNano(), 10),
+					"endTimeUnixNano":   strconv.FormatInt(now.UnixNano(), 10),
+					"status":            map[string]interface{}{"code": 2},
+				}},
+			}},
+		}},
+	}})
+	if !ingested.Success {
+		t.Fatalf("ingest failed: %+v", ingested.Error)
+	}
+
+	byTrace := tools.QuerySpansHandler(ctx, map[string]interface{}{"trace_id": traceID})
+	if !byTrace.Success {
+		t.Fatalf("query failed: %+v", byTrace.Error)
+	}
+	found := spanNames(t, byTrace)
+	if len(found) != 1 || found[0] != "Charge" {
+		t.Errorf("spans of the trace = %v, want [Charge]", found)
+	}
+
+	errorsOnly := tools.QuerySpansHandler(ctx, map[string]interface{}{"service_name": "checkout", "error_only": true})
+	if !errorsOnly.Success {
+		t.Fatalf("query failed: %+v", errorsOnly.Error)
+	}
+	for _, name := range spanNames(t, errorsOnly) {
+		if name != "PlaceOrder" {
+			t.Errorf("error span %q of checkout, want only the seeded PlaceOrder failures", name)
+		}
+	}
+	if len(spanNames(t, errorsOnly)) == 0 {
+		t.Error("no error spans of checkout, want the seeded failures")
+	}
+}
+
+func spanNames(t *testing.T, result *client.ToolResult) []string {
+	t.Helper()
+	data, ok := result.Data.(map[string]interface{})
+	if !ok {
+		t.Fatalf("unexpected query data %#v", result.Data)
+	}
+	list, ok := data["spans"].([]spans.FlatSpan)
+	if !ok {
+		t.Fatalf("unexpected spans %#v", data["spans"])
+	}
+	var names []string
+	for _, s := range list {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+func TestServer_Alerts(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t)
+
+	alerts := alerting.New(c).ActiveAlertsHandler(ctx, map[string]interface{}{"state": "firing"})
+	if !alerts.Success {
+		t.Fatalf("alerts failed: %+v", alerts.Error)
+	}
+	if md := alerts.Markdown; !strings.Contains(md, "CheckoutErrorRate") {
+		t.Errorf("alerts markdown = %q, want the seeded alert", md)
+	}
+}
+
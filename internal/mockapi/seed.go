@@ -0,0 +1,154 @@
+package mockapi
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// seedTraces is the number of traces seeded, one every 20 seconds.
+const seedTraces = 30
+
+// seed fills the server with the demo data described at New.
+func (s *Server) seed() {
+	now := s.now()
+	resources := map[string]map[string]interface{}{}
+	for _, service := range []string{"frontend", "cart", "checkout"} {
+		resources[service] = map[string]interface{}{"attributes": attributes(
+			"service.name", service,
+			"service.namespace", "shop",
+			"service.version", "1.4.2",
+			"deployment.environment.name", "dev",
+			"k8s.namespace.name", "shop",
+			"k8s.deployment.name", service,
+			"k8s.pod.name", service+"-7d9f8b-x2k4q",
+		)}
+	}
+	scope := map[string]interface{}{"name": "mockapi", "version": "1.0.0"}
+
+	// Each trace is a frontend request calling cart or, every third
+	// trace, checkout, which fails every fifth time it is called.
+	for i := 0; i < seedTraces; i++ {
+		traceID := fmt.Sprintf("4bf92f3577b34da6a3ce929d0e0e%04x", i)
+		start := now.Add(-time.Duration(seedTraces-i) * 20 * time.Second)
+		route, service, name := "/api/cart", "cart", "GetCart"
+		if i%3 == 2 {
+			route, service, name = "/api/checkout", "checkout", "PlaceOrder"
+		}
+		failed := service == "checkout" && i%15 == 14
+		status, code := 200, 1
+		if failed {
+			status, code = 500, 2
+		}
+
+		root := span(traceID, fmt.Sprintf("%016x", 2*i+1), "", "POST "+route, 2, start, 80*time.Millisecond, code,
+			"http.request.method", "POST", "http.route", route, "http.response.status_code", status)
+		child := span(traceID, fmt.Sprintf("%016x", 2*i+2), fmt.Sprintf("%016x", 2*i+1), name, 2, start.Add(5*time.Millisecond), 60*time.Millisecond, code,
+			"rpc.method", name)
+		s.spans = append(s.spans,
+			record{resource: resources["frontend"], scope: scope, item: root, time: start.UnixNano()},
+			record{resource: resources[service], scope: scope, item: child, time: start.Add(5 * time.Millisecond).UnixNano()},
+		)
+
+		severity, text, message := 9, "INFO", name+" completed"
+		if failed {
+			severity, text, message = 17, "ERROR", name+" failed: payment provider timed out"
+		}
+		s.logs = append(s.logs, record{resource: resources[service], scope: scope, time: start.Add(60 * time.Millisecond).UnixNano(), item: map[string]interface{}{
+			"timeUnixNano":   nanos(start.Add(60 * time.Millisecond)),
+			"severityNumber": float64(severity),
+			"severityText":   text,
+			"body":           map[string]interface{}{"stringValue": message},
+			"traceId":        traceID,
+			"spanId":         fmt.Sprintf("%016x", 2*i+2),
+			"attributes":     attributes("log.logger", service),
+		}})
+	}
+
+	s.add("/api/dashboards", map[string]interface{}{
+		"kind":     "PersesDashboard",
+		"metadata": map[string]interface{}{"name": "shop-overview"},
+		"spec": map[string]interface{}{
+			"display": map[string]interface{}{"name": "Shop Overview"},
+			"panels":  []interface{}{},
+		},
+	})
+	s.add("/api/views", map[string]interface{}{
+		"kind":     "Dash0View",
+		"metadata": map[string]interface{}{"name": "checkout-errors"},
+		"spec": map[string]interface{}{
+			"type":    "spans",
+			"display": map[string]interface{}{"name": "Checkout errors"},
+			"filter": []interface{}{
+				map[string]interface{}{"key": "service.name", "operator": "is", "value": "checkout"},
+				map[string]interface{}{"key": "otel.span.status_code", "operator": "is", "value": "ERROR"},
+			},
+		},
+	})
+	s.add("/api/alerting/check-rules", map[string]interface{}{
+		"name":        "CheckoutErrorRate",
+		"expression":  `sum(rate(dash0_spans_total{service_name="checkout",otel_span_status_code="ERROR"}[5m])) / sum(rate(dash0_spans_total{service_name="checkout"}[5m])) > 0.05`,
+		"interval":    "1m",
+		"for":         "5m",
+		"labels":      map[string]interface{}{"severity": "critical"},
+		"annotations": map[string]interface{}{"summary": "Checkout error rate above 5%"},
+	})
+	s.add("/api/synthetic-checks", map[string]interface{}{
+		"kind":     "Dash0SyntheticCheck",
+		"metadata": map[string]interface{}{"name": "shop-homepage"},
+		"spec": map[string]interface{}{
+			"enabled":  true,
+			"schedule": map[string]interface{}{"interval": "1m", "locations": []interface{}{"eu-west-1"}},
+			"plugin": map[string]interface{}{
+				"kind": "http",
+				"spec": map[string]interface{}{"request": map[string]interface{}{"method": "get", "url": "https://shop.example.com/"}},
+			},
+		},
+	})
+
+	firingSince := now.Add(-12 * time.Minute).UTC().Format(time.RFC3339)
+	labels := map[string]interface{}{"alertname": "CheckoutErrorRate", "severity": "critical", "service_name": "checkout"}
+	s.alerts = []interface{}{map[string]interface{}{
+		"name":        "CheckoutErrorRate",
+		"state":       "firing",
+		"activeAt":    firingSince,
+		"labels":      labels,
+		"annotations": map[string]interface{}{"summary": "Checkout error rate above 5%"},
+	}}
+}
+
+// span returns an OTLP JSON span. attrs are key/value pairs.
+func span(traceID, spanID, parentID, name string, kind int, start time.Time, d time.Duration, code int, attrs ...interface{}) map[string]interface{} {
+	sp := map[string]interface{}{
+		"traceId":           traceID,
+		"spanId":            spanID,
+		"name":              name,
+		"kind":              float64(kind),
+		"startTimeUnixNano": nanos(start),
+		"endTimeUnixNano":   nanos(start.Add(d)),
+		"attributes":        attributes(attrs...),
+		"status":            map[string]interface{}{"code": float64(code)},
+	}
+	if parentID != "" {
+		sp["parentSpanId"] = parentID
+	}
+	return sp
+}
+
+// attributes returns an OTLP key/value list of key/value pairs, with
+// string and int values.
+func attributes(kv ...interface{}) []interface{} {
+	attrs := make([]interface{}, 0, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		value := map[string]interface{}{"stringValue": fmt.Sprint(kv[i+1])}
+		if n, ok := kv[i+1].(int); ok {
+			value = map[string]interface{}{"intValue": strconv.Itoa(n)}
+		}
+		attrs = append(attrs, map[string]interface{}{"key": kv[i], "value": value})
+	}
+	return attrs
+}
+
+func nanos(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
@@ -0,0 +1,300 @@
+package mockapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/npcomplete777/dash0-mcp/internal/otlp"
+)
+
+// signal names the fields of the OTLP JSON of a telemetry signal.
+type signal struct {
+	resources, scopes, items string
+	// timeKey is the field of an item with its unix-nano timestamp.
+	timeKey string
+}
+
+var (
+	spanSignal = signal{resources: "resourceSpans", scopes: "scopeSpans", items: "spans", timeKey: "startTimeUnixNano"}
+	logSignal  = signal{resources: "resourceLogs", scopes: "scopeLogs", items: "logRecords", timeKey: "timeUnixNano"}
+)
+
+// record is a stored span or log record with its resource and scope.
+type record struct {
+	resource map[string]interface{}
+	scope    map[string]interface{}
+	item     map[string]interface{}
+	// time is the unix-nano timestamp of the item.
+	time int64
+}
+
+// query is the body of a span, log, or resource query.
+type query struct {
+	TimeRange  otlp.TimeRange         `json:"timeRange"`
+	Filter     []otlp.AttributeFilter `json:"filter"`
+	Pagination otlp.Pagination        `json:"pagination"`
+}
+
+// telemetry serves the span or log endpoint: a body with resourceSpans or
+// resourceLogs is ingested, any other is a query.
+func (s *Server) telemetry(w http.ResponseWriter, r *http.Request, sig signal, records *[]record, body interface{}) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	m, _ := body.(map[string]interface{})
+	if _, ok := m[sig.resources]; ok {
+		*records = append(*records, flattenRecords(sig, m)...)
+		writeJSON(w, http.StatusOK, map[string]interface{}{"partialSuccess": map[string]interface{}{}})
+		return
+	}
+
+	q, err := decodeQuery(body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var matched []record
+	for _, rec := range *records {
+		if q.matches(rec) {
+			matched = append(matched, rec)
+		}
+	}
+	// Newest first, as the API returns them.
+	sort.SliceStable(matched, func(i, j int) bool { return matched[i].time > matched[j].time })
+	page, next := q.page(len(matched))
+
+	out := []interface{}{}
+	for _, rec := range matched[page[0]:page[1]] {
+		scope := map[string]interface{}{sig.items: []interface{}{rec.item}}
+		if rec.scope != nil {
+			scope["scope"] = rec.scope
+		}
+		out = append(out, map[string]interface{}{
+			"resource": rec.resource,
+			sig.scopes: []interface{}{scope},
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		sig.resources: out,
+		"cursors":     map[string]interface{}{"after": next},
+	})
+}
+
+// flattenRecords returns the records of an OTLP JSON payload.
+func flattenRecords(sig signal, payload map[string]interface{}) []record {
+	var records []record
+	resources, _ := payload[sig.resources].([]interface{})
+	for _, rr := range resources {
+		rm, _ := rr.(map[string]interface{})
+		resource, _ := rm["resource"].(map[string]interface{})
+		scopes, _ := rm[sig.scopes].([]interface{})
+		for _, sr := range scopes {
+			sm, _ := sr.(map[string]interface{})
+			scope, _ := sm["scope"].(map[string]interface{})
+			items, _ := sm[sig.items].([]interface{})
+			for _, it := range items {
+				item, ok := it.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				t, ok := otlp.UnixNanoValue(item[sig.timeKey])
+				if !ok {
+					t, _ = otlp.UnixNanoValue(item["observedTimeUnixNano"])
+				}
+				records = append(records, record{resource: resource, scope: scope, item: item, time: t})
+			}
+		}
+	}
+	return records
+}
+
+// decodeQuery reads a query body.
+func decodeQuery(body interface{}) (query, error) {
+	var q query
+	if body == nil {
+		return q, nil
+	}
+	data, err := json.Marshal(body)
+	if err == nil {
+		err = json.Unmarshal(data, &q)
+	}
+	if err != nil {
+		return q, fmt.Errorf("invalid query: %v", err)
+	}
+	return q, nil
+}
+
+// matches reports whether a record is in the time range of q and matches
+// all its filters.
+func (q query) matches(rec record) bool {
+	if !q.inTimeRange(rec) {
+		return false
+	}
+	for _, f := range q.Filter {
+		if !filterMatches(f, rec) {
+			return false
+		}
+	}
+	return true
+}
+
+// inTimeRange reports whether a record is in the time range of q. A bound
+// that is not an RFC 3339 time is ignored.
+func (q query) inTimeRange(rec record) bool {
+	if from, err := time.Parse(time.RFC3339Nano, q.TimeRange.From); err == nil && rec.time < from.UnixNano() {
+		return false
+	}
+	if to, err := time.Parse(time.RFC3339Nano, q.TimeRange.To); err == nil && rec.time > to.UnixNano() {
+		return false
+	}
+	return true
+}
+
+// page returns the bounds of the page of n results that q asks for, and
+// the cursor of the next page, or "".
+func (q query) page(n int) ([2]int, string) {
+	start, _ := strconv.Atoi(q.Pagination.Cursor)
+	if start < 0 || start > n {
+		start = n
+	}
+	limit := q.Pagination.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	end := start + limit
+	if end >= n {
+		return [2]int{start, n}, ""
+	}
+	return [2]int{start, end}, strconv.Itoa(end)
+}
+
+// filterMatches reports whether a record matches a filter.
+func filterMatches(f otlp.AttributeFilter, rec record) bool {
+	got, ok := rec.value(f.Key)
+	want := filterValue(f.Value)
+	switch f.Operator {
+	case "", "is":
+		return ok && got == want
+	case "is_not":
+		return !ok || got != want
+	case "contains":
+		return ok && strings.Contains(got, want)
+	case "starts_with":
+		return ok && strings.HasPrefix(got, want)
+	case "gt", "lt":
+		g, err1 := strconv.ParseFloat(got, 64)
+		w, err2 := strconv.ParseFloat(want, 64)
+		if !ok || err1 != nil || err2 != nil {
+			return false
+		}
+		if f.Operator == "gt" {
+			return g > w
+		}
+		return g < w
+	}
+	return false
+}
+
+// filterValue returns the value of a filter as a string.
+func filterValue(v *otlp.AttributeFilterValue) string {
+	switch {
+	case v == nil:
+		return ""
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.IntValue != nil:
+		return *v.IntValue
+	case v.DoubleValue != nil:
+		return strconv.FormatFloat(*v.DoubleValue, 'f', -1, 64)
+	case v.BoolValue != nil:
+		return strconv.FormatBool(*v.BoolValue)
+	}
+	return ""
+}
+
+// value returns the value of a filter key for a record as a string: the
+// trace ID, span ID, span name, or status code for their keys, otherwise
+// the attribute of the item or, failing that, of its resource.
+func (rec record) value(key string) (string, bool) {
+	var v interface{}
+	switch key {
+	case "otel.trace.id":
+		v = rec.item["traceId"]
+	case "otel.span.id":
+		v = rec.item["spanId"]
+	case "otel.span.name":
+		v = rec.item["name"]
+	case "status.code":
+		status, _ := rec.item["status"].(map[string]interface{})
+		return statusCode(status["code"]), status != nil
+	default:
+		if s, ok := attribute(rec.item, key); ok {
+			return s, true
+		}
+		return attribute(rec.resource, key)
+	}
+	if v == nil {
+		return "", false
+	}
+	return valueString(v), true
+}
+
+// statusCode returns an OTLP span status code as its number: "0", "1", or
+// "2".
+func statusCode(v interface{}) string {
+	switch c := v.(type) {
+	case float64:
+		return strconv.Itoa(int(c))
+	case string:
+		switch c {
+		case "STATUS_CODE_OK":
+			return "1"
+		case "STATUS_CODE_ERROR":
+			return "2"
+		case "", "STATUS_CODE_UNSET":
+			return "0"
+		}
+		return c
+	}
+	return "0"
+}
+
+// attribute returns the attribute key of an OTLP item or resource as a
+// string.
+func attribute(m map[string]interface{}, key string) (string, bool) {
+	attrs, _ := m["attributes"].([]interface{})
+	for _, a := range attrs {
+		am, _ := a.(map[string]interface{})
+		if am["key"] != key {
+			continue
+		}
+		value, _ := am["value"].(map[string]interface{})
+		for _, k := range []string{"stringValue", "intValue", "doubleValue", "boolValue"} {
+			if v, ok := value[k]; ok {
+				return valueString(v), true
+			}
+		}
+		return "", true
+	}
+	return "", false
+}
+
+// valueString formats a decoded JSON value, numbers without an exponent.
+func valueString(v interface{}) string {
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// attributeString returns a string attribute, or "".
+func attributeString(m map[string]interface{}, key string) string {
+	s, _ := attribute(m, key)
+	return s
+}
@@ -0,0 +1,52 @@
+package otlp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+// pageCursor is the content of a cursor returned by the query tools: the
+// API's cursor and the time window of the query it belongs to.
+type pageCursor struct {
+	After string `json:"after"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// EncodeCursor returns the next_cursor of a query tool for the API's cursor
+// after, or "" on the last page. The cursor carries the time window from-to,
+// so that the next page is read from the same window rather than one that
+// moved with the clock between the calls.
+func EncodeCursor(after string, from, to time.Time) string {
+	if after == "" {
+		return ""
+	}
+	data, _ := json.Marshal(pageCursor{
+		After: after,
+		From:  from.Format(time.RFC3339Nano),
+		To:    to.Format(time.RFC3339Nano),
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor returns the API's cursor and the time window of a cursor made
+// by EncodeCursor. ok is false for any other cursor, which is sent to the
+// API as is.
+func DecodeCursor(cursor string) (after string, from, to time.Time, ok bool) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", time.Time{}, time.Time{}, false
+	}
+	var c pageCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.After == "" {
+		return "", time.Time{}, time.Time{}, false
+	}
+	if from, err = time.Parse(time.RFC3339Nano, c.From); err != nil {
+		return "", time.Time{}, time.Time{}, false
+	}
+	if to, err = time.Parse(time.RFC3339Nano, c.To); err != nil {
+		return "", time.Time{}, time.Time{}, false
+	}
+	return c.After, from, to, true
+}
@@ -28,3 +28,20 @@ func ExtractServiceName(resourceMap map[string]interface{}) string {
 
 	return ""
 }
+
+// NextCursor returns the cursor for the next page of a query response,
+// or an empty string when the response has no further pages.
+func NextCursor(data interface{}) string {
+	dataMap, ok := data.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	cursors, ok := dataMap["cursors"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if after, ok := cursors["after"].(string); ok {
+		return after
+	}
+	return ""
+}
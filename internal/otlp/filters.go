@@ -0,0 +1,116 @@
+package otlp
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// FilterOperators lists the operators accepted in user-supplied attribute filters.
+var FilterOperators = []string{"is", "is_not", "contains", "starts_with", "gt", "lt"}
+
+// AttributeFiltersSchema returns the JSON schema of an attribute_filters
+// tool argument: a list of {key, operator, value} objects.
+func AttributeFiltersSchema(description string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "array",
+		"description": description,
+		"items": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"key": map[string]interface{}{
+					"type":        "string",
+					"description": "Attribute key (e.g., k8s.pod.name)",
+				},
+				"operator": map[string]interface{}{
+					"type":        "string",
+					"description": "Comparison operator (default: is)",
+					"enum":        FilterOperators,
+				},
+				"value": map[string]interface{}{
+					"description": "Value to compare against (string, number, or boolean)",
+				},
+			},
+			"required": []string{"key", "value"},
+		},
+	}
+}
+
+// ParseAttributeFilters converts a list of {key, operator, value} objects into
+// AttributeFilter entries. It also returns a short description of each filter
+// for display. Numbers become intValue (or doubleValue when fractional) and
+// booleans become boolValue; contains and starts_with require a string value.
+func ParseAttributeFilters(raw interface{}) ([]AttributeFilter, []string, error) {
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("attribute_filters must be an array of {key, operator, value} objects")
+	}
+
+	var filters []AttributeFilter
+	var descs []string
+	for i, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("attribute_filters[%d] must be an object", i)
+		}
+
+		key, _ := m["key"].(string)
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, nil, fmt.Errorf("attribute_filters[%d].key is required", i)
+		}
+
+		operator, _ := m["operator"].(string)
+		if operator == "" {
+			operator = "is"
+		}
+		if !isFilterOperator(operator) {
+			return nil, nil, fmt.Errorf("attribute_filters[%d].operator must be one of: %s", i, strings.Join(FilterOperators, ", "))
+		}
+
+		value := &AttributeFilterValue{}
+		var display string
+		switch v := m["value"].(type) {
+		case string:
+			value.StringValue = &v
+			display = v
+		case float64:
+			if operator == "contains" || operator == "starts_with" {
+				return nil, nil, fmt.Errorf("attribute_filters[%d].value must be a string for operator %s", i, operator)
+			}
+			if v == math.Trunc(v) {
+				intStr := strconv.FormatInt(int64(v), 10)
+				value.IntValue = &intStr
+				display = intStr
+			} else {
+				value.DoubleValue = &v
+				display = strconv.FormatFloat(v, 'f', -1, 64)
+			}
+		case bool:
+			if operator != "is" && operator != "is_not" {
+				return nil, nil, fmt.Errorf("attribute_filters[%d].value must not be a boolean for operator %s", i, operator)
+			}
+			value.BoolValue = &v
+			display = strconv.FormatBool(v)
+		case nil:
+			return nil, nil, fmt.Errorf("attribute_filters[%d].value is required", i)
+		default:
+			return nil, nil, fmt.Errorf("attribute_filters[%d].value must be a string, number, or boolean", i)
+		}
+
+		filters = append(filters, AttributeFilter{Key: key, Operator: operator, Value: value})
+		descs = append(descs, fmt.Sprintf("%s %s %s", key, operator, display))
+	}
+
+	return filters, descs, nil
+}
+
+func isFilterOperator(op string) bool {
+	for _, o := range FilterOperators {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package otlp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Sort directions of the direction argument.
+const (
+	DirectionAsc  = "asc"
+	DirectionDesc = "desc"
+)
+
+// Order is the sort order of a query's order_by and direction arguments.
+// The zero Order keeps the API's order.
+type Order struct {
+	By   string
+	Desc bool
+}
+
+// String describes the order, e.g. "duration desc".
+func (o Order) String() string {
+	if o.Desc {
+		return o.By + " " + DirectionDesc
+	}
+	return o.By + " " + DirectionAsc
+}
+
+// OrderBySchema returns the JSON schema of the order_by argument of a query
+// that can sort by fields.
+func OrderBySchema(fields []string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"description": "Sort the results by " + strings.Join(fields, " or ") + " (default: the API's order)",
+		"enum":        fields,
+	}
+}
+
+// DirectionSchema returns the JSON schema of the direction argument that
+// goes with order_by.
+func DirectionSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"description": "Sort direction of order_by (default: desc, e.g. the slowest or newest first)",
+		"enum":        []string{DirectionAsc, DirectionDesc},
+	}
+}
+
+// ParseOrder reads the order_by and direction arguments. order_by must be
+// one of fields; direction defaults to descending.
+func ParseOrder(args map[string]interface{}, fields []string) (Order, error) {
+	by, _ := args["order_by"].(string)
+	by = strings.TrimSpace(by)
+	direction, _ := args["direction"].(string)
+	direction = strings.ToLower(strings.TrimSpace(direction))
+	if by == "" {
+		if direction != "" {
+			return Order{}, fmt.Errorf("direction requires order_by")
+		}
+		return Order{}, nil
+	}
+
+	valid := false
+	for _, f := range fields {
+		if by == f {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return Order{}, fmt.Errorf("order_by must be one of %s, got %q", strings.Join(fields, ", "), by)
+	}
+
+	switch direction {
+	case "", DirectionDesc:
+		return Order{By: by, Desc: true}, nil
+	case DirectionAsc:
+		return Order{By: by}, nil
+	}
+	return Order{}, fmt.Errorf("direction must be %s or %s, got %q", DirectionAsc, DirectionDesc, direction)
+}
@@ -10,9 +10,10 @@ type AttributeFilter struct {
 
 // AttributeFilterValue represents the value in a filter condition.
 type AttributeFilterValue struct {
-	StringValue *string `json:"stringValue,omitempty"`
-	IntValue    *string `json:"intValue,omitempty"`
-	BoolValue   *bool   `json:"boolValue,omitempty"`
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
 }
 
 // TimeRange represents a time range for queries.
@@ -23,5 +24,6 @@ type TimeRange struct {
 
 // Pagination represents pagination settings.
 type Pagination struct {
-	Limit int `json:"limit,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+	Cursor string `json:"cursor,omitempty"`
 }
@@ -0,0 +1,123 @@
+package otlp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxMissingReported caps the number of missing keys listed in a verification.
+const maxMissingReported = 20
+
+// IngestVerification reports whether telemetry sent to Dash0 became queryable.
+type IngestVerification struct {
+	Verified  bool     `json:"verified"`
+	Expected  int      `json:"expected"`
+	Found     int      `json:"found"`
+	Missing   []string `json:"missing,omitempty"`
+	Attempts  int      `json:"attempts"`
+	ElapsedMs int64    `json:"elapsed_ms"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// VerifyIngestion polls lookup until every expected key has been seen, the
+// timeout elapses, or ctx is cancelled. lookup returns the subset of keys
+// visible in the query API; keys found on earlier attempts stay found.
+func VerifyIngestion(ctx context.Context, expected []string, timeout, interval time.Duration, lookup func(context.Context) (map[string]bool, error)) IngestVerification {
+	start := time.Now()
+	deadline := start.Add(timeout)
+	seen := make(map[string]bool, len(expected))
+	v := IngestVerification{Expected: len(expected)}
+
+	for {
+		v.Attempts++
+		found, err := lookup(ctx)
+		if err != nil {
+			v.Error = err.Error()
+		} else {
+			v.Error = ""
+			for _, key := range expected {
+				if found[key] {
+					seen[key] = true
+				}
+			}
+		}
+
+		if len(seen) == len(expected) || !time.Now().Add(interval).Before(deadline) {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			v.Error = fmt.Sprintf("verification cancelled: %v", ctx.Err())
+			return finishVerification(v, expected, seen, start)
+		case <-time.After(interval):
+		}
+	}
+
+	return finishVerification(v, expected, seen, start)
+}
+
+func finishVerification(v IngestVerification, expected []string, seen map[string]bool, start time.Time) IngestVerification {
+	v.Found = len(seen)
+	v.Verified = v.Found == v.Expected
+	v.ElapsedMs = time.Since(start).Milliseconds()
+
+	var missing []string
+	for _, key := range expected {
+		if !seen[key] {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	if len(missing) > maxMissingReported {
+		missing = missing[:maxMissingReported]
+	}
+	v.Missing = missing
+	return v
+}
+
+// FormatVerification renders an ingestion verification as markdown.
+// noun names the verified items, e.g. "spans" or "log records".
+func FormatVerification(noun string, v IngestVerification) string {
+	var sb strings.Builder
+	sb.WriteString("## Ingestion Verification\n\n")
+	if v.Verified {
+		sb.WriteString(fmt.Sprintf("**Verified:** all %d %s are queryable (%d attempts, %dms).\n", v.Expected, noun, v.Attempts, v.ElapsedMs))
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("**Not verified:** %d of %d %s became queryable after %d attempts (%dms).\n", v.Found, v.Expected, noun, v.Attempts, v.ElapsedMs))
+	if v.Error != "" {
+		sb.WriteString(fmt.Sprintf("\nLast query error: %s\n", v.Error))
+	}
+	if len(v.Missing) > 0 {
+		sb.WriteString("\nMissing:\n")
+		for _, key := range v.Missing {
+			sb.WriteString(fmt.Sprintf("- `%s`\n", key))
+		}
+		if v.Expected-v.Found > len(v.Missing) {
+			sb.WriteString(fmt.Sprintf("- _...and %d more_\n", v.Expected-v.Found-len(v.Missing)))
+		}
+	}
+	sb.WriteString("\nIngestion can lag; retry with a longer verify_timeout_seconds, or check that timestamps fall within the queryable retention window.\n")
+	return sb.String()
+}
+
+// UnixNanoValue parses an OTLP unix-nano timestamp encoded as a string or number.
+func UnixNanoValue(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case string:
+		if out, err := strconv.ParseInt(n, 10, 64); err == nil && out > 0 {
+			return out, true
+		}
+	case float64:
+		if n > 0 {
+			return int64(n), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,68 @@
+// Package progress lets long-running tool handlers report their progress to
+// the MCP client (notifications/progress), when the client asked for it with
+// a progress token.
+package progress
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/npcomplete777/dash0-mcp/internal/truncate"
+)
+
+// Reporter sends one progress update. total is 0 when it is unknown. batch
+// is a partial result of the call sent with the update, or nil.
+type Reporter func(progress, total float64, message string, batch interface{})
+
+type reporterKey struct{}
+
+// reporter is the Reporter of a call with the number of items it streamed
+// so far.
+type reporter struct {
+	report Reporter
+	mu     sync.Mutex
+	items  int
+}
+
+// WithReporter returns a context whose tool calls report progress with r.
+func WithReporter(ctx context.Context, r Reporter) context.Context {
+	return context.WithValue(ctx, reporterKey{}, &reporter{report: r})
+}
+
+// Without returns a context that reports no progress, for work whose own
+// updates would interleave with the caller's.
+func Without(ctx context.Context) context.Context {
+	return context.WithValue(ctx, reporterKey{}, (*reporter)(nil))
+}
+
+// Report sends a progress update through the context's Reporter. It does
+// nothing when the client did not ask for progress. progress must increase
+// with every update of a call.
+func Report(ctx context.Context, progress, total float64, message string) {
+	if r, ok := ctx.Value(reporterKey{}).(*reporter); ok && r != nil && r.report != nil {
+		r.report(progress, total, message, nil)
+	}
+}
+
+// ReportItems sends items of a tool's result as a progress batch, so the
+// client sees them before the call completes. Callers pass items as they end
+// up in the result, after client-side filters, sorting, and limits; the batch
+// is bounded by limits (max_items, max_response_bytes) like the result.
+// Progress is the number of items the call streamed so far, so concurrent
+// queries of one call still report increasing progress. The final result of
+// the call still holds every item.
+func ReportItems[T any](ctx context.Context, noun string, items []T, limits truncate.Limits) {
+	r, ok := ctx.Value(reporterKey{}).(*reporter)
+	if !ok || r == nil || r.report == nil || len(items) == 0 {
+		return
+	}
+	var report truncate.Report
+	items = truncate.Items(items, limits, &report)
+	batch := truncate.Data(items, limits, &report)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.items += len(items)
+	r.report(float64(r.items), 0, fmt.Sprintf("%d %s (%d so far)", len(items), noun, r.items), batch)
+}
@@ -0,0 +1,107 @@
+// Package prompts registers MCP prompts for common SRE workflows. Prompts are
+// defined as templates in the config directory (see config.LoadPrompts) and
+// expand into step-by-step instructions that reference the server's tools.
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/npcomplete777/dash0-mcp/internal/config"
+	mcp "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Prompt is a parsed prompt definition ready to be rendered.
+type Prompt struct {
+	def  config.PromptDef
+	tmpl *template.Template
+}
+
+// Parse compiles the template of each definition.
+func Parse(defs []config.PromptDef) ([]Prompt, error) {
+	prompts := make([]Prompt, 0, len(defs))
+	for _, def := range defs {
+		tmpl, err := template.New(def.Name).Option("missingkey=zero").Parse(def.Template)
+		if err != nil {
+			return nil, fmt.Errorf("prompt %q: %w", def.Name, err)
+		}
+		prompts = append(prompts, Prompt{def: def, tmpl: tmpl})
+	}
+	return prompts, nil
+}
+
+// Name returns the prompt name.
+func (p Prompt) Name() string {
+	return p.def.Name
+}
+
+// Available reports whether every tool the prompt relies on is enabled.
+func (p Prompt) Available(isEnabled func(string) bool) bool {
+	for _, tool := range p.def.Tools {
+		if !isEnabled(tool) {
+			return false
+		}
+	}
+	return true
+}
+
+// Render expands the template with args, applying defaults and checking that
+// required arguments are present.
+func (p Prompt) Render(args map[string]string) (string, error) {
+	values := make(map[string]string, len(p.def.Arguments))
+	for _, a := range p.def.Arguments {
+		v := strings.TrimSpace(args[a.Name])
+		if v == "" {
+			v = a.Default
+		}
+		if v == "" && a.Required {
+			return "", fmt.Errorf("argument %q is required", a.Name)
+		}
+		values[a.Name] = v
+	}
+
+	var b strings.Builder
+	if err := p.tmpl.Execute(&b, values); err != nil {
+		return "", fmt.Errorf("failed to render prompt %q: %w", p.def.Name, err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// mcpPrompt converts the definition to an MCP prompt.
+func (p Prompt) mcpPrompt() mcp.Prompt {
+	opts := []mcp.PromptOption{mcp.WithPromptDescription(p.def.Description)}
+	for _, a := range p.def.Arguments {
+		argOpts := []mcp.ArgumentOption{mcp.ArgumentDescription(a.Description)}
+		if a.Required {
+			argOpts = append(argOpts, mcp.RequiredArgument())
+		}
+		opts = append(opts, mcp.WithArgument(a.Name, argOpts...))
+	}
+	return mcp.NewPrompt(p.def.Name, opts...)
+}
+
+// Register adds the available prompts to the MCP server and returns the
+// names of those registered.
+func Register(s *server.MCPServer, prompts []Prompt, isEnabled func(string) bool) []string {
+	var names []string
+	for _, p := range prompts {
+		if !p.Available(isEnabled) {
+			continue
+		}
+		p := p
+		s.AddPrompt(p.mcpPrompt(), func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			text, err := p.Render(req.Params.Arguments)
+			if err != nil {
+				return nil, err
+			}
+			return mcp.NewGetPromptResult(p.def.Description, []mcp.PromptMessage{
+				mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+			}), nil
+		})
+		names = append(names, p.def.Name)
+	}
+	return names
+}
@@ -0,0 +1,129 @@
+package prompts
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/npcomplete777/dash0-mcp/internal/config"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func testPrompts(t *testing.T) []Prompt {
+	t.Helper()
+	parsed, err := Parse([]config.PromptDef{
+		{
+			Name:        "check-service",
+			Description: "Check a service",
+			Arguments: []config.PromptArgument{
+				{Name: "service_name", Required: true},
+				{Name: "minutes", Default: "60"},
+				{Name: "operation"},
+			},
+			Tools:    []string{"dash0_spans_query"},
+			Template: `Check {{.service_name}} over {{.minutes}}m{{if .operation}} for {{.operation}}{{end}}.`,
+		},
+		{
+			Name:     "needs-export",
+			Tools:    []string{"dash0_export_table"},
+			Template: "Export things.",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return parsed
+}
+
+func TestParse_InvalidTemplate(t *testing.T) {
+	_, err := Parse([]config.PromptDef{{Name: "broken", Template: "{{.x"}})
+	if err == nil || !strings.Contains(err.Error(), `prompt "broken"`) {
+		t.Errorf("Parse() error = %v, want template error", err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	p := testPrompts(t)[0]
+
+	tests := []struct {
+		name    string
+		args    map[string]string
+		want    string
+		wantErr string
+	}{
+		{"defaults", map[string]string{"service_name": "cart"}, "Check cart over 60m.", ""},
+		{"all args", map[string]string{"service_name": "cart", "minutes": "15", "operation": "GET /"}, "Check cart over 15m for GET /.", ""},
+		{"missing required", map[string]string{"minutes": "15"}, "", `argument "service_name" is required`},
+		{"blank required", map[string]string{"service_name": "  "}, "", `argument "service_name" is required`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.Render(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Render() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	s := server.NewMCPServer("test", "0.0.0", server.WithPromptCapabilities(false))
+	enabled := map[string]bool{"dash0_spans_query": true}
+
+	names := Register(s, testPrompts(t), func(name string) bool { return enabled[name] })
+	if len(names) != 1 || names[0] != "check-service" {
+		t.Fatalf("Register() = %v, want [check-service]", names)
+	}
+
+	msg := []byte(`{"jsonrpc": "2.0", "id": 1, "method": "prompts/get", "params": {"name": "check-service", "arguments": {"service_name": "cart"}}}`)
+	raw, err := json.Marshal(s.HandleMessage(context.Background(), msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(raw), "Check cart over 60m.") {
+		t.Errorf("prompts/get response missing rendered text: %s", raw)
+	}
+
+	msg = []byte(`{"jsonrpc": "2.0", "id": 2, "method": "prompts/get", "params": {"name": "needs-export"}}`)
+	raw, _ = json.Marshal(s.HandleMessage(context.Background(), msg))
+	if !strings.Contains(string(raw), `"error"`) {
+		t.Errorf("expected error for prompt with disabled tools: %s", raw)
+	}
+}
+
+func TestShippedPrompts(t *testing.T) {
+	defs, err := config.LoadPrompts(filepath.Join("..", "..", "config"))
+	if err != nil {
+		t.Fatalf("LoadPrompts() error = %v", err)
+	}
+	parsed, err := Parse(defs)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	args := map[string]string{"service_name": "cart", "url": "https://example.com/health"}
+	for _, p := range parsed {
+		text, err := p.Render(args)
+		if err != nil {
+			t.Errorf("%s: Render() error = %v", p.Name(), err)
+			continue
+		}
+		if !strings.Contains(text, "dash0_") {
+			t.Errorf("%s: rendered prompt does not reference any tool", p.Name())
+		}
+		if strings.Contains(text, "<no value>") {
+			t.Errorf("%s: rendered prompt has unset values:\n%s", p.Name(), text)
+		}
+	}
+}
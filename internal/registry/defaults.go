@@ -0,0 +1,158 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	mcp "github.com/mark3labs/mcp-go/mcp"
+)
+
+// AllTools is the key of the defaults that apply to every tool declaring
+// the argument.
+const AllTools = "*"
+
+// SetDefaults sets argument values that are passed to a tool when the
+// caller omits them, keyed by tool name and then argument name. Defaults
+// under AllTools apply to every tool with that argument; a tool's own
+// defaults take precedence. Values are used as they would arrive in a
+// tools/call, so numbers become float64.
+//
+// It returns an error, and keeps the previous defaults, if a tool is not
+// registered or does not declare one of the arguments.
+func (r *Registry) SetDefaults(defaults map[string]map[string]interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	normalized := make(map[string]map[string]interface{}, len(defaults))
+	for name, args := range defaults {
+		for arg := range args {
+			if !r.declares(name, arg) {
+				if name == AllTools {
+					return fmt.Errorf("default for %s: no tool has this argument", arg)
+				}
+				return fmt.Errorf("default for %s.%s: tool not registered or has no such argument", name, arg)
+			}
+		}
+		// Round-trip through JSON so that YAML integers and nested maps
+		// look like tool arguments
+		data, err := json.Marshal(args)
+		if err != nil {
+			return fmt.Errorf("defaults for %s: %w", name, err)
+		}
+		var values map[string]interface{}
+		if err := json.Unmarshal(data, &values); err != nil {
+			return fmt.Errorf("defaults for %s: %w", name, err)
+		}
+		normalized[name] = values
+	}
+	r.defaults = normalized
+	return nil
+}
+
+// declares reports whether tool, or with AllTools any tool, has the
+// argument in its input schema. The caller must hold r.mu.
+func (r *Registry) declares(tool, arg string) bool {
+	if tool != AllTools {
+		_, ok := r.tools[tool].Tool.InputSchema.Properties[arg]
+		return ok
+	}
+	for _, def := range r.tools {
+		if _, ok := def.Tool.InputSchema.Properties[arg]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultsFor returns the defaults of a tool, merged from AllTools and its
+// own, or nil. The caller must hold r.mu.
+func (r *Registry) defaultsFor(name string) map[string]interface{} {
+	if len(r.defaults) == 0 {
+		return nil
+	}
+	props := r.tools[name].Tool.InputSchema.Properties
+	var out map[string]interface{}
+	add := func(args map[string]interface{}) {
+		for k, v := range args {
+			if _, ok := props[k]; !ok {
+				continue
+			}
+			if out == nil {
+				out = map[string]interface{}{}
+			}
+			out[k] = v
+		}
+	}
+	add(r.defaults[AllTools])
+	add(r.defaults[name])
+	return out
+}
+
+// withDefaults passes the configured defaults of a tool for the arguments
+// the caller omitted. It runs before everything else, so a default dataset
+// routes the call like an explicit one, and a default for a required
+// argument is not elicited.
+func (r *Registry) withDefaults(tool mcp.Tool, handler Handler) Handler {
+	if handler == nil {
+		return nil
+	}
+	return func(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+		r.mu.RLock()
+		defaults := r.defaultsFor(tool.Name)
+		r.mu.RUnlock()
+		if len(defaults) == 0 {
+			return handler(ctx, args)
+		}
+		merged := make(map[string]interface{}, len(args)+len(defaults))
+		for k, v := range defaults {
+			merged[k] = v
+		}
+		for k, v := range args {
+			if v != nil {
+				merged[k] = v
+			}
+		}
+		return handler(ctx, merged)
+	}
+}
+
+// withDefaultsDocumented returns the tool as listed to clients: each
+// argument with a default says so in its schema and description, and is no
+// longer required.
+func withDefaultsDocumented(tool mcp.Tool, defaults map[string]interface{}) mcp.Tool {
+	if len(defaults) == 0 {
+		return tool
+	}
+	props := make(map[string]interface{}, len(tool.InputSchema.Properties))
+	for k, v := range tool.InputSchema.Properties {
+		props[k] = v
+	}
+	for k, v := range defaults {
+		def, ok := props[k].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		prop := make(map[string]interface{}, len(def)+1)
+		for pk, pv := range def {
+			prop[pk] = pv
+		}
+		prop["default"] = v
+		desc, _ := prop["description"].(string)
+		value, _ := json.Marshal(v)
+		prop["description"] = strings.TrimSpace(fmt.Sprintf("%s (profile default: %s)", desc, value))
+		props[k] = prop
+	}
+	tool.InputSchema.Properties = props
+
+	var required []string
+	for _, k := range tool.InputSchema.Required {
+		if _, ok := defaults[k]; !ok {
+			required = append(required, k)
+		}
+	}
+	tool.InputSchema.Required = required
+	return tool
+}
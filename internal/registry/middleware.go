@@ -0,0 +1,52 @@
+package registry
+
+import (
+	mcp "github.com/mark3labs/mcp-go/mcp"
+)
+
+// Middleware wraps the handler of a tool with behavior shared by many
+// tools, such as logging, auditing, or rate limiting, so that it does not
+// have to be repeated in each handler. It is given the tool as its package
+// declares it and returns the handler to call instead of next. It may
+// return next unchanged for tools it does not apply to.
+type Middleware func(tool mcp.Tool, next Handler) Handler
+
+// Chain composes middleware into one. The first middleware runs first and
+// sees the result of all the others last.
+func Chain(middleware ...Middleware) Middleware {
+	return func(tool mcp.Tool, next Handler) Handler {
+		for i := len(middleware) - 1; i >= 0; i-- {
+			next = middleware[i](tool, next)
+		}
+		return next
+	}
+}
+
+// Use adds middleware to the handlers of all tools, including those
+// already registered. Middleware run in the order they were added, after
+// the profile's defaults are filled in and before the common arguments
+// (dataset, bypass_cache, dry_run, confirm_token, timeout_seconds, and
+// output_format) are applied, so they see the arguments the tool will get
+// and the result as the caller will get it.
+func (r *Registry) Use(middleware ...Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middleware = append(r.middleware, middleware...)
+	for name, def := range r.tools {
+		def.Handler = r.wrap(def.tool, def.handler)
+		r.tools[name] = def
+	}
+}
+
+// wrap returns the handler of a tool with the built-in middleware and
+// those added with Use. The caller must hold r.mu.
+func (r *Registry) wrap(tool mcp.Tool, handler Handler) Handler {
+	if handler == nil {
+		return nil
+	}
+	chain := make([]Middleware, 0, len(r.middleware)+4)
+	chain = append(chain, r.withDefaults)
+	chain = append(chain, r.middleware...)
+	chain = append(chain, withOutputFormat, withCallOptions, withElicitation)
+	return Chain(chain...)(tool, handler)
+}
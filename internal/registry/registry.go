@@ -3,14 +3,25 @@ package registry
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"slices"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/npcomplete777/dash0-mcp/internal/audit"
 	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/formatter"
 	mcp "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 )
 
+// ErrNotAllowed is the error of Enable for tools the tools config disables.
+var ErrNotAllowed = errors.New("tools disabled by the tools config or profile cannot be enabled")
+
 // Handler is the function signature for tool handlers.
 type Handler func(ctx context.Context, args map[string]interface{}) *client.ToolResult
 
@@ -20,10 +31,39 @@ type ToolProvider interface {
 	Handlers() map[string]func(context.Context, map[string]interface{}) *client.ToolResult
 }
 
+// ExampleProvider is implemented by tool packages that ship example
+// invocations for their tools.
+type ExampleProvider interface {
+	Examples() map[string][]Example
+}
+
+// OutputSchemaProvider is implemented by tool packages whose tools declare
+// the JSON Schema of their structured results.
+type OutputSchemaProvider interface {
+	OutputSchemas() map[string]map[string]interface{}
+}
+
+// Example is a runnable argument set for a tool.
+type Example struct {
+	// Title says what the example does.
+	Title string `json:"title"`
+	// Arguments are the tool arguments, ready to pass to a tools/call.
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
 // ToolDef contains the complete definition of a tool.
 type ToolDef struct {
-	Tool    mcp.Tool
-	Handler Handler
+	Tool     mcp.Tool
+	Handler  Handler
+	Examples []Example
+	// OutputSchema is the JSON Schema of the tool's structured result, or
+	// nil when the tool does not declare one.
+	OutputSchema map[string]interface{}
+
+	// tool and handler are the tool and handler as registered, before the
+	// common arguments and middleware were added.
+	tool    mcp.Tool
+	handler Handler
 }
 
 // Registry manages tool registration and enablement filtering.
@@ -31,6 +71,16 @@ type Registry struct {
 	mu      sync.RWMutex
 	tools   map[string]ToolDef
 	enabled map[string]bool
+	// allowed is the filter of the operator's tools config, which Enable
+	// cannot go beyond; nil allows every tool.
+	allowed map[string]bool
+	// defaults are the argument defaults of the profile, by tool name or
+	// AllTools.
+	defaults map[string]map[string]interface{}
+	// listeners are called after the set of enabled tools changed.
+	listeners []func()
+	// middleware wrap every handler, in the order added with Use.
+	middleware []Middleware
 }
 
 // New creates a new Registry with the given enabled tools filter.
@@ -39,20 +89,320 @@ func New(enabledTools map[string]bool) *Registry {
 	return &Registry{
 		tools:   make(map[string]ToolDef),
 		enabled: enabledTools,
+		allowed: enabledTools,
 	}
 }
 
+// datasetDescription documents the dataset argument added to every tool.
+const datasetDescription = "Dash0 dataset to use for this call (e.g., 'otel-demo'). If omitted, uses the globally configured dataset or 'default'."
+
+// bypassCacheDescription documents the bypass_cache argument added to every
+// tool.
+const bypassCacheDescription = "Skip the response cache and fetch fresh data from the API (default: false)."
+
+// timeoutDescription documents the timeout_seconds argument added to every
+// tool.
+const timeoutDescription = "Abort this call and its API requests after this many seconds. Cannot exceed the server's DASH0_TOOL_TIMEOUT (default: 300)."
+
+// confirmTokenDescription documents the confirm_token argument added to every
+// delete tool.
+const confirmTokenDescription = "When the server requires confirmation for deletes (DASH0_MCP_CONFIRM_DELETES), the token returned by the first call of this tool for the same object. Without it, the call only returns what would be deleted and a new token."
+
+// dryRunDescription documents the dry_run argument added to every mutating
+// tool.
+const dryRunDescription = "Validate the input and return the HTTP request this call would send, without sending it (default: false)."
+
+// outputFormatDescription documents the output_format argument added to
+// every tool.
+const outputFormatDescription = "Render the result as compact JSON, YAML (e.g. for GitOps export), or a Markdown table for chat display. If omitted, the tool's own Markdown summary is returned when it has one, and JSON otherwise."
+
 // Register adds a tool to the registry.
 // The tool will only be exposed if it's in the enabled set (or if no filter is set).
+// Every tool accepts an optional dataset argument that overrides the configured
+// dataset for the requests made by that call, a bypass_cache argument that
+// skips the response cache, and a timeout_seconds argument that shortens the
+// call's deadline, and an output_format argument that picks how the result
+// is rendered. Tools that write to Dash0 also accept a dry_run argument
+// that reports their requests instead of sending them, and delete tools a
+// confirm_token argument for two-phase deletes. Omitted arguments are
+// filled from the defaults set with SetDefaults, and missing required
+// arguments are asked of the user when the client supports elicitation.
+// The handler is wrapped by the middleware added with Use.
 func (r *Registry) Register(tool mcp.Tool, handler Handler) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.tools[tool.Name] = ToolDef{
-		Tool:    tool,
-		Handler: handler,
+		Tool:    withCallArguments(tool),
+		Handler: r.wrap(tool, handler),
+		tool:    tool,
+		handler: handler,
+	}
+}
+
+// AddExamples attaches example invocations to a registered tool. Examples
+// for unknown tools are ignored.
+func (r *Registry) AddExamples(name string, examples ...Example) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	def, ok := r.tools[name]
+	if !ok {
+		return
+	}
+	def.Examples = append(def.Examples, examples...)
+	r.tools[name] = def
+}
+
+// Examples returns the example invocations of a tool.
+func (r *Registry) Examples(name string) []Example {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.tools[name].Examples
+}
+
+// SetOutputSchema declares the JSON Schema of a registered tool's
+// structured result. Schemas for unknown tools and nil schemas are ignored.
+func (r *Registry) SetOutputSchema(name string, schema map[string]interface{}) {
+	if schema == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	def, ok := r.tools[name]
+	if !ok {
+		return
+	}
+	def.OutputSchema = schema
+	r.tools[name] = def
+}
+
+// OutputSchema returns the JSON Schema of an enabled tool's structured
+// result, or nil when it has none.
+func (r *Registry) OutputSchema(name string) map[string]interface{} {
+	if !r.IsEnabled(name) {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.tools[name].OutputSchema
+}
+
+// withCallArguments adds the optional dataset, bypass_cache,
+// timeout_seconds, and output_format properties, dry_run for mutating tools,
+// and confirm_token for delete tools, to a tool's input schema unless the
+// tool already declares them.
+func withCallArguments(tool mcp.Tool) mcp.Tool {
+	common := map[string]interface{}{
+		"dataset":      map[string]interface{}{"type": "string", "description": datasetDescription},
+		"bypass_cache": map[string]interface{}{"type": "boolean", "description": bypassCacheDescription},
+		"timeout_seconds": map[string]interface{}{
+			"type":        "integer",
+			"description": timeoutDescription,
+			"minimum":     1,
+		},
+		"output_format": map[string]interface{}{
+			"type":        "string",
+			"description": outputFormatDescription,
+			"enum":        formatter.OutputFormats,
+		},
+	}
+	if audit.Mutating(tool.Name) {
+		common["dry_run"] = map[string]interface{}{"type": "boolean", "description": dryRunDescription}
+	}
+	if strings.HasSuffix(tool.Name, "_delete") {
+		common["confirm_token"] = map[string]interface{}{"type": "string", "description": confirmTokenDescription}
+	}
+	props := make(map[string]interface{}, len(tool.InputSchema.Properties)+len(common))
+	for k, v := range common {
+		props[k] = v
+	}
+	for k, v := range tool.InputSchema.Properties {
+		props[k] = v
+	}
+	tool.InputSchema.Properties = props
+	if tool.InputSchema.Type == "" {
+		tool.InputSchema.Type = "object"
+	}
+	return tool
+}
+
+// withCallOptions routes the handler's requests to the dataset argument,
+// when one is given, past the response cache when bypass_cache is set, and
+// aborts them when timeout_seconds elapses. With dry_run, writes are
+// described instead of sent, and confirm_token confirms a delete.
+func withCallOptions(tool mcp.Tool, handler Handler) Handler {
+	if handler == nil {
+		return nil
+	}
+	return func(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+		if ds, ok := args["dataset"].(string); ok {
+			ctx = client.WithDataset(ctx, ds)
+		}
+		if bypass, _ := args["bypass_cache"].(bool); bypass {
+			ctx = client.WithoutCache(ctx)
+		}
+		if dryRun, _ := args["dry_run"].(bool); dryRun {
+			ctx = client.WithDryRun(ctx)
+		}
+		if token, ok := args["confirm_token"].(string); ok {
+			ctx = client.WithConfirmToken(ctx, token)
+		}
+
+		secs, ok := args["timeout_seconds"].(float64)
+		if !ok {
+			return handler(ctx, args)
+		}
+		if secs <= 0 {
+			return client.ErrorResult(400, "timeout_seconds must be positive")
+		}
+		timeout := time.Duration(secs * float64(time.Second))
+		callCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		result := handler(callCtx, args)
+		// Report the per-call deadline, unless the server's own deadline or
+		// a cancellation ended the call first.
+		if ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) && (result == nil || !result.Success) {
+			return client.ErrorResult(http.StatusGatewayTimeout, fmt.Sprintf("%s timed out after %s (timeout_seconds)", tool.Name, timeout))
+		}
+		return result
+	}
+}
+
+// withOutputFormat renders a successful result in the format named by the
+// output_format argument. Failed results keep their typed error.
+func withOutputFormat(_ mcp.Tool, handler Handler) Handler {
+	if handler == nil {
+		return nil
+	}
+	return func(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+		format, _ := args["output_format"].(string)
+		if raw, ok := args["output_format"]; ok && !validOutputFormat(raw) {
+			return client.ErrorResult(400, fmt.Sprintf("output_format must be one of %s, got %v", strings.Join(formatter.OutputFormats, ", "), raw))
+		}
+		result := handler(ctx, args)
+		if format == "" || result == nil || !result.Success {
+			return result
+		}
+		text, err := formatter.Output(format, result.Data, result.Markdown)
+		if err != nil {
+			return client.ErrorResult(500, err.Error())
+		}
+		result.Markdown = text
+		return result
 	}
 }
 
+// validOutputFormat reports whether v names one of the output formats.
+func validOutputFormat(v interface{}) bool {
+	s, _ := v.(string)
+	for _, f := range formatter.OutputFormats {
+		if s == f {
+			return true
+		}
+	}
+	return false
+}
+
+// elicitableTypes are the property types an elicitation schema may contain.
+var elicitableTypes = map[string]bool{"string": true, "number": true, "integer": true, "boolean": true}
+
+// withElicitation asks the user for missing required arguments before the
+// handler runs, with the MCP server's RequestElicitation. Without client
+// support, when a missing argument cannot be elicited (e.g. an object body),
+// or when the user declines, the handler runs with the original arguments
+// and reports the missing value itself.
+func withElicitation(tool mcp.Tool, handler Handler) Handler {
+	if handler == nil || len(tool.InputSchema.Required) == 0 {
+		return handler
+	}
+	return func(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+		srv := server.ServerFromContext(ctx)
+		if srv == nil || !canElicit(ctx) {
+			return handler(ctx, args)
+		}
+		missing := missingArguments(tool, args)
+		if len(missing) == 0 {
+			return handler(ctx, args)
+		}
+
+		schema, ok := elicitationSchema(tool, missing)
+		if !ok {
+			return handler(ctx, args)
+		}
+		message := fmt.Sprintf("%s needs a value for %s.", tool.Name, strings.Join(missing, ", "))
+		res, err := srv.RequestElicitation(ctx, mcp.ElicitationRequest{
+			Params: mcp.ElicitationParams{Message: message, RequestedSchema: schema},
+		})
+		if err != nil || res.Action != mcp.ElicitationResponseActionAccept {
+			return handler(ctx, args)
+		}
+
+		content, _ := res.Content.(map[string]interface{})
+		merged := make(map[string]interface{}, len(args)+len(content))
+		for k, v := range args {
+			merged[k] = v
+		}
+		for _, name := range missing {
+			if v, ok := content[name]; ok {
+				merged[name] = v
+			}
+		}
+		return handler(ctx, merged)
+	}
+}
+
+// canElicit reports whether the session of a call can send elicitation
+// requests and its client declared the elicitation capability.
+func canElicit(ctx context.Context) bool {
+	session := server.ClientSessionFromContext(ctx)
+	if _, ok := session.(server.SessionWithElicitation); !ok {
+		return false
+	}
+	info, ok := session.(server.SessionWithClientInfo)
+	return ok && info.GetClientCapabilities().Elicitation != nil
+}
+
+// missingArguments returns the required arguments that are absent or blank.
+func missingArguments(tool mcp.Tool, args map[string]interface{}) []string {
+	var missing []string
+	for _, name := range tool.InputSchema.Required {
+		switch v := args[name].(type) {
+		case nil:
+			missing = append(missing, name)
+		case string:
+			if strings.TrimSpace(v) == "" {
+				missing = append(missing, name)
+			}
+		}
+	}
+	return missing
+}
+
+// elicitationSchema builds the requested schema for the missing arguments
+// from the tool's own property definitions. It reports false if any of them
+// is not a primitive type.
+func elicitationSchema(tool mcp.Tool, missing []string) (map[string]interface{}, bool) {
+	props := make(map[string]interface{}, len(missing))
+	for _, name := range missing {
+		def, _ := tool.InputSchema.Properties[name].(map[string]interface{})
+		typ, _ := def["type"].(string)
+		if !elicitableTypes[typ] {
+			return nil, false
+		}
+		prop := map[string]interface{}{"type": typ, "title": name}
+		for _, key := range []string{"description", "enum", "minimum", "maximum"} {
+			if v, ok := def[key]; ok {
+				prop[key] = v
+			}
+		}
+		props[name] = prop
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": props,
+		"required":   missing,
+	}, true
+}
+
 // IsEnabled checks if a tool is enabled.
 func (r *Registry) IsEnabled(name string) bool {
 	r.mu.RLock()
@@ -65,6 +415,106 @@ func (r *Registry) IsEnabled(name string) bool {
 	return r.enabled[name]
 }
 
+// IsAllowed reports whether the tools config allows a tool, i.e. whether
+// Enable may turn it on.
+func (r *Registry) IsAllowed(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.allowed == nil || r.allowed[name]
+}
+
+// SetEnabled replaces the enabled tools filter, e.g. after the tools config
+// changed; nil enables every tool. The filter is also the limit of what
+// Enable may turn on. It reports whether the set of enabled registered
+// tools changed.
+func (r *Registry) SetEnabled(enabledTools map[string]bool) bool {
+	before := r.EnabledToolNames()
+	r.mu.Lock()
+	r.enabled = enabledTools
+	r.allowed = enabledTools
+	r.mu.Unlock()
+	return r.changed(before)
+}
+
+// Enable enables registered tools in addition to the current ones and
+// returns the names that were disabled before. Only tools the tools config
+// allows can be enabled, i.e. those turned off with Disable; unknown names
+// and tools the config disables are an error and change nothing.
+func (r *Registry) Enable(names ...string) ([]string, error) {
+	return r.toggle(names, true)
+}
+
+// Disable disables registered tools and returns the names that were enabled
+// before. Unknown names are an error and change nothing.
+func (r *Registry) Disable(names ...string) ([]string, error) {
+	return r.toggle(names, false)
+}
+
+// toggle sets the enabled state of the named tools.
+func (r *Registry) toggle(names []string, enable bool) ([]string, error) {
+	before := r.EnabledToolNames()
+	r.mu.Lock()
+	var unknown, denied, changed []string
+	for _, name := range names {
+		if _, ok := r.tools[name]; !ok {
+			unknown = append(unknown, name)
+		} else if enable && r.allowed != nil && !r.allowed[name] {
+			denied = append(denied, name)
+		} else if enabled := r.enabled == nil || r.enabled[name]; enabled != enable {
+			changed = append(changed, name)
+		}
+	}
+	if len(unknown) > 0 {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("unknown tools: %s", strings.Join(unknown, ", "))
+	}
+	if len(denied) > 0 {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrNotAllowed, strings.Join(denied, ", "))
+	}
+	if len(changed) > 0 {
+		// Copy the filter, which may be shared with the config it came from
+		enabled := make(map[string]bool, len(r.tools))
+		for name := range r.tools {
+			if r.enabled == nil || r.enabled[name] {
+				enabled[name] = true
+			}
+		}
+		for _, name := range changed {
+			enabled[name] = enable
+		}
+		r.enabled = enabled
+	}
+	r.mu.Unlock()
+	r.changed(before)
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// OnChange calls fn after the set of enabled tools changed, e.g. to send
+// notifications/tools/list_changed.
+func (r *Registry) OnChange(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, fn)
+}
+
+// changed calls the listeners if the enabled tools differ from before, and
+// reports whether they do.
+func (r *Registry) changed(before []string) bool {
+	after := r.EnabledToolNames()
+	if slices.Equal(before, after) {
+		return false
+	}
+	r.mu.RLock()
+	listeners := r.listeners
+	r.mu.RUnlock()
+	for _, fn := range listeners {
+		fn()
+	}
+	return true
+}
+
 // GetEnabledTools returns all enabled tool definitions for MCP listing.
 func (r *Registry) GetEnabledTools() []mcp.Tool {
 	r.mu.RLock()
@@ -73,7 +523,7 @@ func (r *Registry) GetEnabledTools() []mcp.Tool {
 	var tools []mcp.Tool
 	for name, def := range r.tools {
 		if r.enabled == nil || r.enabled[name] {
-			tools = append(tools, def.Tool)
+			tools = append(tools, withDefaultsDocumented(def.Tool, r.defaultsFor(name)))
 		}
 	}
 
@@ -85,6 +535,14 @@ func (r *Registry) GetEnabledTools() []mcp.Tool {
 	return tools
 }
 
+// Tool returns the definition of a registered tool, enabled or not.
+func (r *Registry) Tool(name string) (mcp.Tool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	def, ok := r.tools[name]
+	return def.Tool, ok
+}
+
 // GetHandler returns the handler for a tool, or nil if not found.
 func (r *Registry) GetHandler(name string) Handler {
 	r.mu.RLock()
@@ -1,11 +1,16 @@
 package registry
 
 import (
+	"errors"
 	"context"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/npcomplete777/dash0-mcp/internal/client"
 	mcp "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 )
 
 func TestNew(t *testing.T) {
@@ -52,6 +57,477 @@ func TestRegister(t *testing.T) {
 	}
 }
 
+func TestRegister_DatasetArgument(t *testing.T) {
+	reg := New(nil)
+
+	var gotDataset string
+	handler := func(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+		gotDataset = client.DatasetOverride(ctx)
+		return &client.ToolResult{Success: true}
+	}
+	reg.Register(mcp.NewTool("plain"), handler)
+	reg.Register(mcp.NewTool("custom",
+		mcp.WithString("dataset", mcp.Description("custom description")),
+	), handler)
+
+	for _, tool := range reg.GetEnabledTools() {
+		prop, ok := tool.InputSchema.Properties["dataset"].(map[string]interface{})
+		if !ok {
+			t.Fatalf("%s: expected a dataset property, got %v", tool.Name, tool.InputSchema.Properties)
+		}
+		if tool.Name == "custom" && prop["description"] != "custom description" {
+			t.Errorf("existing dataset property should be kept, got %v", prop)
+		}
+	}
+
+	reg.Call(context.Background(), "plain", map[string]interface{}{"dataset": "otel-demo"})
+	if gotDataset != "otel-demo" {
+		t.Errorf("dataset override = %q, want otel-demo", gotDataset)
+	}
+	reg.Call(context.Background(), "plain", map[string]interface{}{})
+	if gotDataset != "" {
+		t.Errorf("dataset override = %q, want none", gotDataset)
+	}
+}
+
+func TestRegister_BypassCacheArgument(t *testing.T) {
+	reg := New(nil)
+
+	var bypassed bool
+	reg.Register(mcp.NewTool("plain"), func(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+		bypassed = client.CacheBypassed(ctx)
+		return &client.ToolResult{Success: true}
+	})
+
+	prop, ok := reg.GetEnabledTools()[0].InputSchema.Properties["bypass_cache"].(map[string]interface{})
+	if !ok || prop["type"] != "boolean" {
+		t.Fatalf("expected a boolean bypass_cache property, got %v", prop)
+	}
+
+	reg.Call(context.Background(), "plain", map[string]interface{}{"bypass_cache": true})
+	if !bypassed {
+		t.Error("bypass_cache: true should bypass the cache")
+	}
+	reg.Call(context.Background(), "plain", map[string]interface{}{})
+	if bypassed {
+		t.Error("cache should be used without bypass_cache")
+	}
+}
+
+func TestRegister_OutputFormatArgument(t *testing.T) {
+	reg := New(nil)
+	reg.Register(mcp.NewTool("list"), func(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+		return &client.ToolResult{
+			Success:  true,
+			Markdown: "## Items\n",
+			Data:     map[string]interface{}{"items": []interface{}{map[string]interface{}{"name": "a"}}},
+		}
+	})
+	reg.Register(mcp.NewTool("fail"), func(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+		return client.ErrorResult(404, "not found")
+	})
+
+	prop, ok := reg.GetEnabledTools()[0].InputSchema.Properties["output_format"].(map[string]interface{})
+	if !ok || prop["type"] != "string" {
+		t.Fatalf("expected a string output_format property, got %v", prop)
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"", "## Items\n"},
+		{"json", `{"items":[{"name":"a"}]}`},
+		{"yaml", "items:\n    - name: a\n"},
+		{"markdown", "## Items\n"},
+	}
+	for _, tt := range tests {
+		args := map[string]interface{}{}
+		if tt.format != "" {
+			args["output_format"] = tt.format
+		}
+		result := reg.Call(context.Background(), "list", args)
+		if !result.Success || result.Markdown != tt.want {
+			t.Errorf("output_format %q: got %q, want %q", tt.format, result.Markdown, tt.want)
+		}
+	}
+
+	if result := reg.Call(context.Background(), "list", map[string]interface{}{"output_format": "xml"}); result.Success || result.Error.StatusCode != 400 {
+		t.Errorf("expected a 400 for an unknown format, got %+v", result)
+	}
+	if result := reg.Call(context.Background(), "fail", map[string]interface{}{"output_format": "yaml"}); result.Success || result.Markdown != "" {
+		t.Errorf("failed results should keep their error, got %+v", result)
+	}
+}
+
+func TestRegister_DryRunArgument(t *testing.T) {
+	reg := New(nil)
+
+	var dryRun bool
+	handler := func(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+		dryRun = (&client.Client{}).DryRun(ctx)
+		return &client.ToolResult{Success: true}
+	}
+	reg.Register(mcp.NewTool("dash0_views_create"), handler)
+	reg.Register(mcp.NewTool("dash0_views_list"), handler)
+
+	for _, tool := range reg.GetEnabledTools() {
+		_, ok := tool.InputSchema.Properties["dry_run"]
+		if want := tool.Name == "dash0_views_create"; ok != want {
+			t.Errorf("%s has dry_run = %v, want %v", tool.Name, ok, want)
+		}
+	}
+
+	reg.Call(context.Background(), "dash0_views_create", map[string]interface{}{"dry_run": true})
+	if !dryRun {
+		t.Error("dry_run: true should mark the call as a dry run")
+	}
+	reg.Call(context.Background(), "dash0_views_create", map[string]interface{}{})
+	if dryRun {
+		t.Error("calls without dry_run should be sent")
+	}
+}
+
+func TestRegister_ConfirmTokenArgument(t *testing.T) {
+	reg := New(nil)
+
+	var token string
+	handler := func(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+		token = client.ConfirmToken(ctx)
+		return &client.ToolResult{Success: true}
+	}
+	reg.Register(mcp.NewTool("dash0_views_delete"), handler)
+	reg.Register(mcp.NewTool("dash0_views_update"), handler)
+
+	for _, tool := range reg.GetEnabledTools() {
+		_, ok := tool.InputSchema.Properties["confirm_token"]
+		if want := tool.Name == "dash0_views_delete"; ok != want {
+			t.Errorf("%s has confirm_token = %v, want %v", tool.Name, ok, want)
+		}
+	}
+
+	reg.Call(context.Background(), "dash0_views_delete", map[string]interface{}{"confirm_token": "abc"})
+	if token != "abc" {
+		t.Errorf("confirm token = %q, want abc", token)
+	}
+}
+
+func TestRegister_TimeoutArgument(t *testing.T) {
+	reg := New(nil)
+	reg.Register(mcp.NewTool("slow"), func(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+		<-ctx.Done()
+		return client.ErrorResult(504, "request aborted: tool call timed out")
+	})
+	reg.Register(mcp.NewTool("fast"), func(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("expected a deadline with timeout_seconds")
+		}
+		return &client.ToolResult{Success: true}
+	})
+
+	if _, ok := reg.GetEnabledTools()[0].InputSchema.Properties["timeout_seconds"]; !ok {
+		t.Fatal("expected a timeout_seconds property")
+	}
+
+	start := time.Now()
+	result := reg.Call(context.Background(), "slow", map[string]interface{}{"timeout_seconds": 0.05})
+	if result.Success || result.Error.StatusCode != 504 || !strings.Contains(result.Error.Detail, "slow timed out after 50ms") {
+		t.Errorf("unexpected result: %+v", result.Error)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("call took %v, want it aborted after 50ms", elapsed)
+	}
+
+	if result := reg.Call(context.Background(), "fast", map[string]interface{}{"timeout_seconds": float64(10)}); !result.Success {
+		t.Errorf("expected success, got %+v", result.Error)
+	}
+	if result := reg.Call(context.Background(), "fast", map[string]interface{}{"timeout_seconds": float64(-1)}); result.Success {
+		t.Error("expected a negative timeout_seconds to be rejected")
+	}
+}
+
+// fakeSession is a client session that answers every elicitation with a
+// fixed result, and declared the elicitation capability unless unsupported.
+type fakeSession struct {
+	result      mcp.ElicitationResult
+	unsupported bool
+	schemas     []interface{}
+}
+
+func (f *fakeSession) SessionID() string { return "fake" }
+
+func (f *fakeSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return make(chan mcp.JSONRPCNotification, 1)
+}
+
+func (f *fakeSession) Initialize() {}
+
+func (f *fakeSession) Initialized() bool { return true }
+
+func (f *fakeSession) GetClientInfo() mcp.Implementation { return mcp.Implementation{} }
+
+func (f *fakeSession) SetClientInfo(mcp.Implementation) {}
+
+func (f *fakeSession) GetClientCapabilities() mcp.ClientCapabilities {
+	if f.unsupported {
+		return mcp.ClientCapabilities{}
+	}
+	return mcp.ClientCapabilities{Elicitation: &mcp.ElicitationCapability{}}
+}
+
+func (f *fakeSession) SetClientCapabilities(mcp.ClientCapabilities) {}
+
+func (f *fakeSession) RequestElicitation(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	f.schemas = append(f.schemas, request.Params.RequestedSchema)
+	return &f.result, nil
+}
+
+// callInSession calls a registered tool through an MCP server, as a
+// tools/call of the session would.
+func callInSession(t *testing.T, reg *Registry, session server.ClientSession, name string, args map[string]interface{}) *client.ToolResult {
+	t.Helper()
+	srv := server.NewMCPServer("test", "0.0.0", server.WithElicitation())
+	var result *client.ToolResult
+	srv.AddTool(mcp.NewTool(name), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result = reg.Call(ctx, name, args)
+		return mcp.NewToolResultText(""), nil
+	})
+	srv.HandleMessage(srv.WithContext(context.Background(), session), []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":%q}}`, name)))
+	if result == nil {
+		t.Fatalf("%s was not called", name)
+	}
+	return result
+}
+
+func TestRegister_ElicitsMissingArguments(t *testing.T) {
+	reg := New(nil)
+
+	var gotArgs map[string]interface{}
+	handler := func(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+		gotArgs = args
+		if id, _ := args["origin_or_id"].(string); id == "" {
+			return client.ErrorResult(400, "origin_or_id is required")
+		}
+		return &client.ToolResult{Success: true}
+	}
+	reg.Register(mcp.NewTool("get",
+		mcp.WithString("origin_or_id", mcp.Required(), mcp.Description("The origin or ID")),
+	), handler)
+	reg.Register(mcp.NewTool("create",
+		mcp.WithObject("body", mcp.Required()),
+	), handler)
+
+	t.Run("accepted", func(t *testing.T) {
+		session := &fakeSession{}
+		session.result.Action = mcp.ElicitationResponseActionAccept
+		session.result.Content = map[string]interface{}{"origin_or_id": "dash-1"}
+
+		result := callInSession(t, reg, session, "get", map[string]interface{}{"origin_or_id": " "})
+		if !result.Success || gotArgs["origin_or_id"] != "dash-1" {
+			t.Fatalf("expected elicited value to be used, got %+v with args %v", result, gotArgs)
+		}
+		if len(session.schemas) != 1 {
+			t.Fatalf("expected 1 elicitation, got %d", len(session.schemas))
+		}
+		schema := session.schemas[0].(map[string]interface{})
+		prop := schema["properties"].(map[string]interface{})["origin_or_id"].(map[string]interface{})
+		if prop["type"] != "string" || prop["description"] != "The origin or ID" {
+			t.Errorf("schema property = %v, want the tool's definition", prop)
+		}
+	})
+
+	t.Run("declined", func(t *testing.T) {
+		session := &fakeSession{}
+		session.result.Action = mcp.ElicitationResponseActionDecline
+		result := callInSession(t, reg, session, "get", map[string]interface{}{})
+		if result.Success || result.Error.Detail != "origin_or_id is required" {
+			t.Errorf("expected the handler's error, got %+v", result)
+		}
+	})
+
+	t.Run("unsupported client", func(t *testing.T) {
+		session := &fakeSession{unsupported: true}
+		result := callInSession(t, reg, session, "get", map[string]interface{}{})
+		if result.Success || result.Error.Detail != "origin_or_id is required" || len(session.schemas) != 0 {
+			t.Errorf("expected the handler's error without elicitation, got %+v", result)
+		}
+		if result := reg.Call(context.Background(), "get", map[string]interface{}{}); result.Success {
+			t.Errorf("expected the handler's error outside a session, got %+v", result)
+		}
+	})
+
+	t.Run("object arguments are not elicited", func(t *testing.T) {
+		session := &fakeSession{}
+		session.result.Action = mcp.ElicitationResponseActionAccept
+		callInSession(t, reg, session, "create", map[string]interface{}{})
+		if len(session.schemas) != 0 {
+			t.Errorf("expected no elicitation for an object body, got %v", session.schemas)
+		}
+	})
+
+	t.Run("nothing missing", func(t *testing.T) {
+		session := &fakeSession{}
+		callInSession(t, reg, session, "get", map[string]interface{}{"origin_or_id": "x"})
+		if len(session.schemas) != 0 {
+			t.Errorf("expected no elicitation, got %v", session.schemas)
+		}
+	})
+}
+
+func TestSetDefaults(t *testing.T) {
+	reg := New(nil)
+
+	var gotArgs map[string]interface{}
+	var gotDataset string
+	handler := func(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+		gotArgs, gotDataset = args, client.DatasetOverride(ctx)
+		return &client.ToolResult{Success: true}
+	}
+	reg.Register(mcp.NewTool("signals",
+		mcp.WithString("service_name", mcp.Required(), mcp.Description("The service")),
+		mcp.WithNumber("limit"),
+	), handler)
+	reg.Register(mcp.NewTool("list"), handler)
+
+	if err := reg.SetDefaults(map[string]map[string]interface{}{"signals": {"unknown": 1}}); err == nil {
+		t.Error("expected an error for an argument the tool does not have")
+	}
+	if err := reg.SetDefaults(map[string]map[string]interface{}{"missing": {"limit": 1}}); err == nil {
+		t.Error("expected an error for an unknown tool")
+	}
+	if err := reg.SetDefaults(map[string]map[string]interface{}{AllTools: {"unknown": 1}}); err == nil {
+		t.Error("expected an error for an argument no tool has")
+	}
+	err := reg.SetDefaults(map[string]map[string]interface{}{
+		AllTools:  {"dataset": "checkout", "limit": 10},
+		"signals": {"service_name": "checkout-api", "limit": 50},
+	})
+	if err != nil {
+		t.Fatalf("SetDefaults() error = %v", err)
+	}
+
+	// The tool's own default wins over *, and YAML integers become float64
+	reg.Call(context.Background(), "signals", map[string]interface{}{})
+	if gotArgs["service_name"] != "checkout-api" || gotArgs["limit"] != float64(50) || gotDataset != "checkout" {
+		t.Errorf("args = %v, dataset = %q; want the defaults", gotArgs, gotDataset)
+	}
+	reg.Call(context.Background(), "signals", map[string]interface{}{"service_name": "cart", "limit": nil, "dataset": "prod"})
+	if gotArgs["service_name"] != "cart" || gotArgs["limit"] != float64(50) || gotDataset != "prod" {
+		t.Errorf("args = %v, dataset = %q; want the caller's values to win", gotArgs, gotDataset)
+	}
+	// * only applies to the arguments a tool has
+	reg.Call(context.Background(), "list", map[string]interface{}{})
+	if _, ok := gotArgs["limit"]; ok || gotArgs["dataset"] != "checkout" {
+		t.Errorf("list args = %v, want only the dataset default", gotArgs)
+	}
+
+	for _, tool := range reg.GetEnabledTools() {
+		if tool.Name != "signals" {
+			continue
+		}
+		if len(tool.InputSchema.Required) != 0 {
+			t.Errorf("Required = %v, want none: service_name has a default", tool.InputSchema.Required)
+		}
+		prop := tool.InputSchema.Properties["service_name"].(map[string]interface{})
+		if prop["default"] != "checkout-api" || prop["description"] != `The service (profile default: "checkout-api")` {
+			t.Errorf("service_name = %v, want the default documented", prop)
+		}
+	}
+}
+
+func TestUse(t *testing.T) {
+	reg := New(nil)
+
+	var calls []string
+	trace := func(label string) Middleware {
+		return func(tool mcp.Tool, next Handler) Handler {
+			return func(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+				calls = append(calls, label+":"+tool.Name)
+				if _, ok := args["limit"]; !ok && tool.Name == "before" {
+					t.Errorf("%s: args = %v, want the default limit", label, args)
+				}
+				return next(ctx, args)
+			}
+		}
+	}
+	handlerCalls := 0
+	handler := func(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+		handlerCalls++
+		return &client.ToolResult{Success: true, Data: map[string]interface{}{"ok": true}}
+	}
+	reg.Register(mcp.NewTool("before", mcp.WithNumber("limit")), handler)
+	if err := reg.SetDefaults(map[string]map[string]interface{}{"before": {"limit": 5}}); err != nil {
+		t.Fatalf("SetDefaults() error = %v", err)
+	}
+	reg.Use(trace("first"), trace("second"))
+	reg.Register(mcp.NewTool("after"), handler)
+	reg.Use(func(tool mcp.Tool, next Handler) Handler {
+		if tool.Name != "after" {
+			return next
+		}
+		return func(ctx context.Context, args map[string]interface{}) *client.ToolResult {
+			calls = append(calls, "deny:"+tool.Name)
+			return client.ErrorResult(403, "denied")
+		}
+	})
+
+	// Middleware wrap the tools registered before and after Use, in order,
+	// and may end a call without running the handler
+	reg.Call(context.Background(), "before", map[string]interface{}{})
+	result := reg.Call(context.Background(), "after", map[string]interface{}{})
+	want := []string{"first:before", "second:before", "first:after", "second:after", "deny:after"}
+	if strings.Join(calls, ",") != strings.Join(want, ",") {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+	if result.Success || handlerCalls != 1 {
+		t.Errorf("after: result = %+v, handler calls = %d; want the call denied", result, handlerCalls)
+	}
+}
+
+func TestRegister_Examples(t *testing.T) {
+	reg := New(nil)
+	reg.Register(mcp.NewTool("test_tool"), nil)
+
+	reg.AddExamples("test_tool", Example{Title: "first", Arguments: map[string]interface{}{"a": 1}})
+	reg.AddExamples("test_tool", Example{Title: "second", Arguments: map[string]interface{}{}})
+	reg.AddExamples("unknown_tool", Example{Title: "ignored"})
+
+	examples := reg.Examples("test_tool")
+	if len(examples) != 2 || examples[0].Title != "first" || examples[1].Title != "second" {
+		t.Errorf("Examples() = %v, want first and second", examples)
+	}
+	if got := reg.Examples("unknown_tool"); got != nil {
+		t.Errorf("Examples(unknown) = %v, want nil", got)
+	}
+}
+
+func TestSetOutputSchema(t *testing.T) {
+	reg := New(map[string]bool{"test_tool": true})
+	reg.Register(mcp.NewTool("test_tool"), nil)
+	reg.Register(mcp.NewTool("disabled_tool"), nil)
+	schema := map[string]interface{}{"type": "object"}
+
+	reg.SetOutputSchema("test_tool", schema)
+	reg.SetOutputSchema("disabled_tool", schema)
+	reg.SetOutputSchema("unknown_tool", schema)
+
+	if got := reg.OutputSchema("test_tool"); got["type"] != "object" {
+		t.Errorf("OutputSchema(test_tool) = %v, want the schema", got)
+	}
+	if got := reg.OutputSchema("disabled_tool"); got != nil {
+		t.Errorf("OutputSchema(disabled_tool) = %v, want nil", got)
+	}
+	if got := reg.OutputSchema("unknown_tool"); got != nil {
+		t.Errorf("OutputSchema(unknown_tool) = %v, want nil", got)
+	}
+
+	// A nil schema keeps the declared one
+	reg.SetOutputSchema("test_tool", nil)
+	if reg.OutputSchema("test_tool") == nil {
+		t.Error("SetOutputSchema(nil) cleared the schema")
+	}
+}
+
 func TestIsEnabled(t *testing.T) {
 	t.Run("NilFilter", func(t *testing.T) {
 		reg := New(nil)
@@ -78,6 +554,81 @@ func TestIsEnabled(t *testing.T) {
 	})
 }
 
+func TestSetEnabled(t *testing.T) {
+	reg := New(map[string]bool{"tool1": true})
+	reg.Register(mcp.Tool{Name: "tool1"}, nil)
+	reg.Register(mcp.Tool{Name: "tool2"}, nil)
+
+	if !reg.SetEnabled(map[string]bool{"tool2": true}) {
+		t.Error("SetEnabled(tool2) = false, want a change")
+	}
+	if reg.IsEnabled("tool1") || !reg.IsEnabled("tool2") {
+		t.Errorf("enabled = %v, want only tool2", reg.EnabledToolNames())
+	}
+	// Unregistered tools do not count as a change
+	if reg.SetEnabled(map[string]bool{"tool2": true, "unregistered": true}) {
+		t.Error("SetEnabled() with the same registered tools = true, want no change")
+	}
+	if !reg.SetEnabled(nil) || reg.EnabledCount() != 2 {
+		t.Errorf("SetEnabled(nil): enabled = %v, want every tool", reg.EnabledToolNames())
+	}
+}
+
+func TestEnableDisable(t *testing.T) {
+	enabled := map[string]bool{"tool1": true, "tool2": true}
+	reg := New(enabled)
+	reg.Register(mcp.Tool{Name: "tool1"}, nil)
+	reg.Register(mcp.Tool{Name: "tool2"}, nil)
+	reg.Register(mcp.Tool{Name: "tool3"}, nil)
+	var notified int
+	reg.OnChange(func() { notified++ })
+
+	if changed, err := reg.Disable("tool1"); err != nil || len(changed) != 1 || reg.IsEnabled("tool1") {
+		t.Errorf("Disable() = %v, %v; want [tool1] disabled", changed, err)
+	}
+	if enabled["tool1"] != true {
+		t.Error("Disable() modified the filter passed to New")
+	}
+	if changed, err := reg.Enable("tool1", "tool2"); err != nil || len(changed) != 1 || changed[0] != "tool1" {
+		t.Errorf("Enable() = %v, %v; want [tool1]", changed, err)
+	}
+	if notified != 2 {
+		t.Errorf("%d notifications, want 2", notified)
+	}
+
+	// Tools the config disables cannot be enabled
+	if _, err := reg.Enable("tool3"); !errors.Is(err, ErrNotAllowed) || reg.IsEnabled("tool3") || reg.IsAllowed("tool3") {
+		t.Errorf("Enable(tool3) = %v, enabled %v; want ErrNotAllowed and no change", err, reg.IsEnabled("tool3"))
+	}
+	// Unknown tools change nothing
+	if _, err := reg.Disable("tool2", "unknown"); err == nil || !strings.Contains(err.Error(), "unknown") || !reg.IsEnabled("tool2") {
+		t.Errorf("Disable(unknown) = %v, tool2 enabled %v; want an error and no change", err, reg.IsEnabled("tool2"))
+	}
+	// Repeating a change does not notify
+	if changed, _ := reg.Enable("tool1"); len(changed) != 0 || notified != 2 {
+		t.Errorf("Enable() again = %v with %d notifications, want no change", changed, notified)
+	}
+
+	// A new config sets what may be enabled
+	reg.SetEnabled(map[string]bool{"tool3": true})
+	if _, err := reg.Enable("tool1"); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("Enable(tool1) after SetEnabled = %v, want ErrNotAllowed", err)
+	}
+
+	// Without a filter, disabling keeps the other tools enabled and every
+	// tool may be enabled again
+	reg = New(nil)
+	reg.Register(mcp.Tool{Name: "tool1"}, nil)
+	reg.Register(mcp.Tool{Name: "tool2"}, nil)
+	reg.Disable("tool1")
+	if reg.IsEnabled("tool1") || !reg.IsEnabled("tool2") {
+		t.Errorf("enabled = %v, want only tool2", reg.EnabledToolNames())
+	}
+	if _, err := reg.Enable("tool1"); err != nil || !reg.IsEnabled("tool1") {
+		t.Errorf("Enable(tool1) = %v, want it enabled again", err)
+	}
+}
+
 func TestGetEnabledTools(t *testing.T) {
 	t.Run("NilFilter", func(t *testing.T) {
 		reg := New(nil)
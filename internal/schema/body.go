@@ -0,0 +1,128 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"gopkg.in/yaml.v3"
+)
+
+// bodyYAMLDescription documents the body_yaml argument of CRD create and
+// update tools.
+const bodyYAMLDescription = "The same resource as a Kubernetes-style YAML string, as an alternative to body. Set either body or body_yaml."
+
+// WithBodyYAML adds the body_yaml property to the input schema properties of
+// a tool that takes a CRD body.
+func WithBodyYAML(props map[string]interface{}) map[string]interface{} {
+	props["body_yaml"] = map[string]interface{}{
+		"type":        "string",
+		"description": bodyYAMLDescription,
+	}
+	return props
+}
+
+// Body returns the CRD body of a create or update call: the body argument,
+// or the body_yaml argument parsed into the same JSON form. It returns an
+// error result when neither or both are set, or when body_yaml is not valid
+// YAML; YAML errors name the line of the problem.
+func Body(args map[string]interface{}) (interface{}, *client.ToolResult) {
+	body, hasBody := args["body"]
+	raw, hasYAML := args["body_yaml"]
+	switch {
+	case hasBody && hasYAML:
+		return nil, client.ErrorResult(http.StatusBadRequest, "set either body or body_yaml, not both")
+	case hasBody:
+		return body, nil
+	case !hasYAML:
+		return nil, client.ErrorResult(http.StatusBadRequest, "body is required (or set body_yaml to the resource as YAML)")
+	}
+
+	text, ok := raw.(string)
+	if !ok || strings.TrimSpace(text) == "" {
+		return nil, client.ErrorResult(http.StatusBadRequest, "body_yaml must be a non-empty YAML string")
+	}
+	body, err := parseYAML(text)
+	if err != nil {
+		result := client.ErrorResult(http.StatusBadRequest, fmt.Sprintf("body_yaml is not valid YAML: %v", err))
+		result.Error.Fields = []client.FieldError{{Pointer: "/body_yaml", Detail: err.Error()}}
+		if line := yamlErrorLine(err, text); line != "" {
+			result.Error.Hint = "the problem is at or before: " + line
+		}
+		return nil, result
+	}
+	return body, nil
+}
+
+// parseYAML decodes a single YAML document into the values JSON arguments
+// decode to: string-keyed maps, []interface{}, float64, bool, and string.
+func parseYAML(text string) (interface{}, error) {
+	dec := yaml.NewDecoder(strings.NewReader(text))
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, errors.New(strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+	var extra interface{}
+	if err := dec.Decode(&extra); err != io.EOF {
+		return nil, errors.New("body_yaml must hold a single document; remove the '---' separated documents after the first")
+	}
+	if _, ok := doc.(map[string]interface{}); !ok {
+		return nil, errors.New("the document must be a mapping with kind, metadata, and spec")
+	}
+
+	data, err := json.Marshal(jsonCompatible(doc))
+	if err != nil {
+		return nil, err
+	}
+	var body interface{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// jsonCompatible converts the maps with non-string keys that YAML allows into
+// string-keyed maps, so the document can be marshaled as JSON.
+func jsonCompatible(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			v[k] = jsonCompatible(val)
+		}
+		return v
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = jsonCompatible(val)
+		}
+		return m
+	case []interface{}:
+		for i, val := range v {
+			v[i] = jsonCompatible(val)
+		}
+		return v
+	}
+	return v
+}
+
+var yamlLine = regexp.MustCompile(`line (\d+)`)
+
+// yamlErrorLine returns the numbered source line a YAML error points to.
+func yamlErrorLine(err error, text string) string {
+	m := yamlLine.FindStringSubmatch(err.Error())
+	if m == nil {
+		return ""
+	}
+	n, _ := strconv.Atoi(m[1])
+	lines := strings.Split(text, "\n")
+	if n < 1 || n > len(lines) {
+		return ""
+	}
+	return fmt.Sprintf("line %d: %s", n, strings.TrimSpace(lines[n-1]))
+}
@@ -0,0 +1,218 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+)
+
+// KindCheckRule is the kind of a check rule. Check rules are flat JSON
+// objects without an embedded schema; the kind only selects their
+// normalizations.
+const KindCheckRule = "CheckRule"
+
+// Fix describes one change Normalize made to a body.
+type Fix struct {
+	// Pointer is the JSON pointer of the field that was changed, as it is in
+	// the normalized body.
+	Pointer string `json:"pointer"`
+	Detail  string `json:"detail"`
+}
+
+// requestFields are the fields of an HTTP synthetic check request, as they
+// show up when the request is not nested under plugin.spec.request.
+var requestFields = []string{"method", "url", "headers", "queryParameters", "body", "redirects", "tls", "basicAuthentication"}
+
+// Normalize repairs well-known mistakes in a body of kind and returns the
+// repaired copy with the list of fixes; body itself is not modified. Fields
+// are only moved or renamed when the correct field is not set, so a body
+// that is already valid comes back unchanged:
+//
+//   - sampling rules: probability instead of rate in probabilistic
+//     conditions, and rates given as a percentage
+//   - synthetic checks: the HTTP request flat in spec, plugin, or
+//     plugin.spec instead of nested under plugin.spec.request
+//   - check rules: Prometheus alert and expr instead of name and expression
+func Normalize(kind string, body interface{}) (interface{}, []Fix) {
+	m, ok := deepCopy(body).(map[string]interface{})
+	if !ok {
+		return body, nil
+	}
+	n := &normalizer{}
+	switch kind {
+	case KindSamplingRule:
+		if spec, ok := m["spec"].(map[string]interface{}); ok {
+			if cond, ok := spec["conditions"].(map[string]interface{}); ok {
+				n.samplingCondition(cond, "/spec/conditions")
+			}
+		}
+	case KindSyntheticCheck:
+		if spec, ok := m["spec"].(map[string]interface{}); ok {
+			n.syntheticCheck(spec)
+		}
+	case KindCheckRule:
+		n.rename(m, "", "alert", "name")
+		n.rename(m, "", "expr", "expression")
+	}
+	if len(n.fixes) == 0 {
+		return body, nil
+	}
+	return m, n.fixes
+}
+
+// normalizer collects the fixes made to one body.
+type normalizer struct {
+	fixes []Fix
+}
+
+func (n *normalizer) fix(pointer, format string, args ...interface{}) {
+	n.fixes = append(n.fixes, Fix{Pointer: pointer, Detail: fmt.Sprintf(format, args...)})
+}
+
+// rename moves m[from] to m[to] unless to is already set.
+func (n *normalizer) rename(m map[string]interface{}, pointer, from, to string) {
+	v, ok := m[from]
+	if _, set := m[to]; !ok || set {
+		return
+	}
+	delete(m, from)
+	m[to] = v
+	n.fix(pointer+"/"+escape(to), "renamed %q to %q", from, to)
+}
+
+// samplingCondition normalizes a sampling condition and its nested
+// conditions.
+func (n *normalizer) samplingCondition(cond map[string]interface{}, pointer string) {
+	spec, ok := cond["spec"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	switch cond["kind"] {
+	case "probabilistic":
+		n.rename(spec, pointer+"/spec", "probability", "rate")
+		if rate, ok := spec["rate"].(float64); ok && rate > 1 && rate <= 100 {
+			spec["rate"] = rate / 100
+			n.fix(pointer+"/spec/rate", "read %v as a percentage and changed it to %v", rate, rate/100)
+		}
+	case "and":
+		conditions, _ := spec["conditions"].([]interface{})
+		for i, c := range conditions {
+			if c, ok := c.(map[string]interface{}); ok {
+				n.samplingCondition(c, fmt.Sprintf("%s/spec/conditions/%d", pointer, i))
+			}
+		}
+	}
+}
+
+// syntheticCheck nests a misplaced HTTP request under plugin.spec.request.
+func (n *normalizer) syntheticCheck(spec map[string]interface{}) {
+	plugin, hasPlugin := spec["plugin"].(map[string]interface{})
+	if !hasPlugin {
+		// A request directly in spec: the whole plugin is missing
+		request, ok := spec["request"].(map[string]interface{})
+		if !ok {
+			return
+		}
+		delete(spec, "request")
+		spec["plugin"] = map[string]interface{}{
+			"kind": "http",
+			"spec": map[string]interface{}{"request": request},
+		}
+		n.fix("/spec/plugin/spec/request", "moved /spec/request into an http plugin")
+		return
+	}
+
+	pluginSpec, ok := plugin["spec"].(map[string]interface{})
+	if !ok {
+		pluginSpec = map[string]interface{}{}
+	}
+	if _, set := pluginSpec["request"]; set {
+		return
+	}
+	switch {
+	case plugin["request"] != nil:
+		pluginSpec["request"] = plugin["request"]
+		delete(plugin, "request")
+		n.fix("/spec/plugin/spec/request", "moved /spec/plugin/request to /spec/plugin/spec/request")
+	case pluginSpec["url"] != nil || pluginSpec["method"] != nil:
+		request := map[string]interface{}{}
+		var moved []string
+		for _, field := range requestFields {
+			if v, ok := pluginSpec[field]; ok {
+				request[field] = v
+				delete(pluginSpec, field)
+				moved = append(moved, field)
+			}
+		}
+		pluginSpec["request"] = request
+		n.fix("/spec/plugin/spec/request", "moved %s from /spec/plugin/spec into /spec/plugin/spec/request", strings.Join(moved, ", "))
+	default:
+		return
+	}
+	plugin["spec"] = pluginSpec
+	if _, set := plugin["kind"]; !set {
+		plugin["kind"] = "http"
+		n.fix("/spec/plugin/kind", "set the plugin kind to \"http\" for the request")
+	}
+}
+
+// Report adds the fixes made by Normalize to the result of the request that
+// sent the normalized body: to the markdown of a successful result, and to
+// the hint of a failed one. The fixes are also set as result.Meta.
+func Report(result *client.ToolResult, fixes []Fix) *client.ToolResult {
+	if len(fixes) == 0 || result == nil {
+		return result
+	}
+	result.Meta = map[string]interface{}{"normalized": fixes}
+
+	if result.Error != nil {
+		details := make([]string, len(fixes))
+		for i, f := range fixes {
+			details[i] = f.Pointer + ": " + f.Detail
+		}
+		note := "the body was normalized before sending (" + strings.Join(details, "; ") + ")"
+		if result.Error.Hint != "" {
+			note = result.Error.Hint + "; " + note
+		}
+		result.Error.Hint = note
+		return result
+	}
+
+	var sb strings.Builder
+	if result.Markdown != "" {
+		sb.WriteString(result.Markdown)
+		sb.WriteString("\n")
+	} else if result.Data != nil {
+		data, _ := json.MarshalIndent(result.Data, "", "  ")
+		fmt.Fprintf(&sb, "```json\n%s\n```\n\n", data)
+	}
+	sb.WriteString("## Normalized\n\n")
+	sb.WriteString("The body was repaired before it was sent:\n\n")
+	for _, f := range fixes {
+		fmt.Fprintf(&sb, "- `%s`: %s\n", f.Pointer, f.Detail)
+	}
+	result.Markdown = sb.String()
+	return result
+}
+
+// deepCopy returns a copy of a decoded JSON value that shares no maps or
+// slices with it.
+func deepCopy(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			out[k] = deepCopy(val)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, val := range v {
+			out[i] = deepCopy(val)
+		}
+		return out
+	}
+	return v
+}
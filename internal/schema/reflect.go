@@ -0,0 +1,105 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Reflect returns the JSON Schema of the JSON encoding of v's type, for tool
+// output schemas. Struct fields are named by their json tags and are
+// required unless tagged omitempty. Slices, maps, and pointers also allow
+// null, which is how they encode when nil, and interface{} values allow
+// anything.
+func Reflect(v interface{}) map[string]interface{} {
+	return reflectType(reflect.TypeOf(v))
+}
+
+func reflectType(t reflect.Type) map[string]interface{} {
+	if t == nil {
+		return map[string]interface{}{}
+	}
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Ptr:
+		return nullable(reflectType(t.Elem()))
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// []byte encodes as a base64 string
+			return map[string]interface{}{"type": "string"}
+		}
+		s := map[string]interface{}{"type": "array", "items": reflectType(t.Elem())}
+		if t.Kind() == reflect.Slice {
+			return nullable(s)
+		}
+		return s
+	case reflect.Map:
+		s := map[string]interface{}{"type": "object"}
+		if t.Elem().Kind() != reflect.Interface {
+			s["additionalProperties"] = reflectType(t.Elem())
+		}
+		return nullable(s)
+	case reflect.Struct:
+		return reflectStruct(t)
+	}
+	// interface{} and anything JSON cannot restrict further
+	return map[string]interface{}{}
+}
+
+// reflectStruct returns the schema of a struct's JSON object. Fields of
+// embedded structs are promoted, as encoding/json does.
+func reflectStruct(t reflect.Type) map[string]interface{} {
+	props := map[string]interface{}{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			embedded := reflectStruct(f.Type)
+			for k, v := range embedded["properties"].(map[string]interface{}) {
+				props[k] = v
+			}
+			if req, ok := embedded["required"].([]string); ok {
+				required = append(required, req...)
+			}
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = reflectType(f.Type)
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+	s := map[string]interface{}{"type": "object", "properties": props}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// nullable adds null to the types a schema allows.
+func nullable(s map[string]interface{}) map[string]interface{} {
+	if typ, ok := s["type"].(string); ok {
+		s["type"] = []string{typ, "null"}
+	}
+	return s
+}
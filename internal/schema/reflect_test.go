@@ -0,0 +1,89 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type reflectBase struct {
+	ID string `json:"id"`
+}
+
+type reflectSample struct {
+	reflectBase
+	Name     string                 `json:"name"`
+	Count    int                    `json:"count,omitempty"`
+	Ratio    float64                `json:"ratio"`
+	Enabled  *bool                  `json:"enabled,omitempty"`
+	Tags     []string               `json:"tags"`
+	Labels   map[string]string      `json:"labels,omitempty"`
+	Extra    map[string]interface{} `json:"extra,omitempty"`
+	Any      interface{}            `json:"any,omitempty"`
+	Created  time.Time              `json:"created"`
+	Internal string                 `json:"-"`
+	hidden   string
+}
+
+func TestReflect(t *testing.T) {
+	s := Reflect(reflectSample{})
+	props := s["properties"].(map[string]interface{})
+
+	want := map[string]interface{}{
+		"id":      map[string]interface{}{"type": "string"},
+		"name":    map[string]interface{}{"type": "string"},
+		"count":   map[string]interface{}{"type": "integer"},
+		"ratio":   map[string]interface{}{"type": "number"},
+		"enabled": map[string]interface{}{"type": []string{"boolean", "null"}},
+		"tags":    map[string]interface{}{"type": []string{"array", "null"}, "items": map[string]interface{}{"type": "string"}},
+		"labels":  map[string]interface{}{"type": []string{"object", "null"}, "additionalProperties": map[string]interface{}{"type": "string"}},
+		"extra":   map[string]interface{}{"type": []string{"object", "null"}},
+		"any":     map[string]interface{}{},
+		"created": map[string]interface{}{"type": "string", "format": "date-time"},
+	}
+	if !reflect.DeepEqual(props, want) {
+		t.Errorf("properties = %v, want %v", props, want)
+	}
+	if got, want := s["required"], []string{"id", "name", "ratio", "tags", "created"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("required = %v, want %v", got, want)
+	}
+}
+
+func TestReflect_ValidatesEncodedValue(t *testing.T) {
+	enabled := true
+	sample := []reflectSample{
+		{reflectBase: reflectBase{ID: "a"}, Name: "one", Enabled: &enabled, Labels: map[string]string{"k": "v"}, Created: time.Now()},
+		{Name: "two", Tags: []string{"x"}, Any: 3},
+	}
+	data, err := json.Marshal(sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		t.Fatal(err)
+	}
+
+	// Validate against the schema as clients receive it
+	b, err := json.Marshal(Reflect(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s map[string]interface{}
+	if err := json.Unmarshal(b, &s); err != nil {
+		t.Fatal(err)
+	}
+	v := &validator{root: s}
+	v.validate(s, value, "")
+	if len(v.errs) != 0 {
+		t.Errorf("encoded value does not match its schema: %+v", v.errs)
+	}
+
+	// A missing required field and a wrong type are caught
+	v = &validator{root: s}
+	v.validate(s, []interface{}{map[string]interface{}{"id": "a", "name": 1}}, "")
+	if len(v.errs) == 0 {
+		t.Error("invalid value passed its schema")
+	}
+}
@@ -0,0 +1,304 @@
+// Package schema validates CRD bodies against embedded JSON Schemas before
+// they are sent, so a malformed body fails with field-level errors instead of
+// an upstream 400. Normalize repairs the mistakes agents commonly make in
+// these bodies before they are validated.
+//
+// The validator implements the subset of JSON Schema draft 7 that the
+// embedded schemas use: type, required, properties, items, enum, const,
+// minimum, maximum, minLength, minItems, allOf, if/then, and local $ref.
+// Other keywords are ignored, and unknown fields are allowed, so a body the
+// API accepts is never rejected for using fields the schemas do not list.
+package schema
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+)
+
+// Kinds with an embedded schema.
+const (
+	KindDashboard      = "PersesDashboard"
+	KindSamplingRule   = "Dash0Sampling"
+	KindSyntheticCheck = "Dash0SyntheticCheck"
+	KindView           = "Dash0View"
+)
+
+//go:embed schemas/*.json
+var files embed.FS
+
+// schemaFiles maps a kind to its schema file.
+var schemaFiles = map[string]string{
+	KindDashboard:      "schemas/persesdashboard.json",
+	KindSamplingRule:   "schemas/dash0sampling.json",
+	KindSyntheticCheck: "schemas/dash0syntheticcheck.json",
+	KindView:           "schemas/dash0view.json",
+}
+
+// schemas are the parsed schemas by kind.
+var schemas = func() map[string]map[string]interface{} {
+	parsed := make(map[string]map[string]interface{}, len(schemaFiles))
+	for kind, name := range schemaFiles {
+		data, err := files.ReadFile(name)
+		if err != nil {
+			panic(fmt.Sprintf("schema: %v", err))
+		}
+		var s map[string]interface{}
+		if err := json.Unmarshal(data, &s); err != nil {
+			panic(fmt.Sprintf("schema: invalid %s: %v", name, err))
+		}
+		parsed[kind] = s
+	}
+	return parsed
+}()
+
+// Validate checks body against the schema of kind and returns every problem,
+// ordered by field. A kind without a schema has no problems.
+func Validate(kind string, body interface{}) []client.FieldError {
+	root, ok := schemas[kind]
+	if !ok {
+		return nil
+	}
+	v := &validator{root: root}
+	v.validate(root, body, "")
+	sort.SliceStable(v.errs, func(i, j int) bool { return v.errs[i].Pointer < v.errs[j].Pointer })
+	return v.errs
+}
+
+// Check validates body against the schema of kind. It returns a validation
+// error listing every problem, or nil when the body is valid.
+func Check(kind string, body interface{}) *client.ToolResult {
+	errs := Validate(kind, body)
+	if len(errs) == 0 {
+		return nil
+	}
+	result := client.ErrorResult(http.StatusBadRequest, fmt.Sprintf("body is not a valid %s; nothing was sent", kind))
+	result.Error.Fields = errs
+	result.Error.Hint = fmt.Sprintf("fix the listed fields; call dash0_examples for a valid %s body", kind)
+	return result
+}
+
+// validator collects the problems of one body.
+type validator struct {
+	root map[string]interface{}
+	errs []client.FieldError
+}
+
+func (v *validator) fail(pointer, format string, args ...interface{}) {
+	if pointer == "" {
+		pointer = "/"
+	}
+	v.errs = append(v.errs, client.FieldError{Pointer: pointer, Detail: fmt.Sprintf(format, args...)})
+}
+
+// validate checks value at pointer against schema s.
+func (v *validator) validate(s map[string]interface{}, value interface{}, pointer string) {
+	if ref, ok := s["$ref"].(string); ok {
+		if target := v.resolve(ref); target != nil {
+			v.validate(target, value, pointer)
+		}
+		return
+	}
+
+	if types := schemaTypes(s["type"]); len(types) > 0 && !matchesType(value, types) {
+		v.fail(pointer, "must be %s, got %s", article(strings.Join(types, " or ")), typeName(value))
+		return
+	}
+	if enum, ok := s["enum"].([]interface{}); ok && !contains(enum, value) {
+		v.fail(pointer, "must be one of %s, got %s", quoteAll(enum), quote(value))
+	}
+	if c, ok := s["const"]; ok && !equal(c, value) {
+		v.fail(pointer, "must be %s, got %s", quote(c), quote(value))
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(s, value, pointer)
+	case []interface{}:
+		if min, ok := s["minItems"].(float64); ok && float64(len(value)) < min {
+			v.fail(pointer, "must have at least %d items", int(min))
+		}
+		if items, ok := s["items"].(map[string]interface{}); ok {
+			for i, item := range value {
+				v.validate(items, item, fmt.Sprintf("%s/%d", pointer, i))
+			}
+		}
+	case string:
+		if min, ok := s["minLength"].(float64); ok && float64(len([]rune(value))) < min {
+			if min == 1 {
+				v.fail(pointer, "must not be empty")
+			} else {
+				v.fail(pointer, "must be at least %d characters", int(min))
+			}
+		}
+	case float64:
+		if min, ok := s["minimum"].(float64); ok && value < min {
+			v.fail(pointer, "must be at least %v, got %v", min, value)
+		}
+		if max, ok := s["maximum"].(float64); ok && value > max {
+			v.fail(pointer, "must be at most %v, got %v", max, value)
+		}
+	}
+
+	if all, ok := s["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			if sub, ok := sub.(map[string]interface{}); ok {
+				v.validate(sub, value, pointer)
+			}
+		}
+	}
+	if cond, ok := s["if"].(map[string]interface{}); ok {
+		if then, ok := s["then"].(map[string]interface{}); ok && v.matches(cond, value) {
+			v.validate(then, value, pointer)
+		}
+	}
+}
+
+func (v *validator) validateObject(s map[string]interface{}, value map[string]interface{}, pointer string) {
+	if required, ok := s["required"].([]interface{}); ok {
+		for _, name := range required {
+			name, _ := name.(string)
+			if _, ok := value[name]; !ok {
+				v.fail(pointer+"/"+escape(name), "missing required field %q", name)
+			}
+		}
+	}
+	props, _ := s["properties"].(map[string]interface{})
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop, ok := props[name].(map[string]interface{})
+		field, present := value[name]
+		if ok && present {
+			v.validate(prop, field, pointer+"/"+escape(name))
+		}
+	}
+}
+
+// matches reports whether value is valid against s, without recording
+// problems; it evaluates the if of an if/then.
+func (v *validator) matches(s map[string]interface{}, value interface{}) bool {
+	probe := &validator{root: v.root}
+	probe.validate(s, value, "")
+	return len(probe.errs) == 0
+}
+
+// resolve returns the schema a local reference such as
+// "#/definitions/condition" points to.
+func (v *validator) resolve(ref string) map[string]interface{} {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil
+	}
+	var node interface{} = v.root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		node = m[part]
+	}
+	s, _ := node.(map[string]interface{})
+	return s
+}
+
+// schemaTypes returns the types a schema allows.
+func schemaTypes(t interface{}) []string {
+	switch t := t.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		types := make([]string, 0, len(t))
+		for _, s := range t {
+			if s, ok := s.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+func matchesType(value interface{}, types []string) bool {
+	got := typeName(value)
+	for _, t := range types {
+		if t == got || (t == "number" && got == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// typeName returns the JSON Schema type of a decoded JSON value.
+func typeName(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if value == float64(int64(value)) {
+			return "integer"
+		}
+		return "number"
+	case int, int64:
+		return "integer"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func article(t string) string {
+	if strings.IndexAny(t[:1], "aeiou") == 0 {
+		return "an " + t
+	}
+	return "a " + t
+}
+
+func contains(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if equal(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func equal(a, b interface{}) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
+
+func quote(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+func quoteAll(values []interface{}) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = quote(v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// escape escapes a property name for a JSON pointer (RFC 6901).
+func escape(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
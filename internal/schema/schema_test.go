@@ -0,0 +1,383 @@
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+)
+
+// decode parses a JSON body as tool arguments arrive.
+func decode(t *testing.T, body string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		t.Fatalf("invalid test body: %v", err)
+	}
+	return v
+}
+
+func TestValidate_SyntheticCheck(t *testing.T) {
+	valid := `{
+		"kind": "Dash0SyntheticCheck",
+		"metadata": {"name": "api-health"},
+		"spec": {
+			"enabled": true,
+			"plugin": {"kind": "http", "spec": {"request": {"method": "get", "url": "https://example.com", "headers": {"Accept": "application/json"}}}},
+			"schedule": {"interval": "1m", "locations": ["eu-west-1"]},
+			"retries": {"count": 2, "delay": "5s"},
+			"notifications": {"channels": []}
+		}
+	}`
+	if errs := Validate(KindSyntheticCheck, decode(t, valid)); len(errs) != 0 {
+		t.Errorf("valid check has problems: %+v", errs)
+	}
+
+	// A flat request instead of plugin.spec.request
+	flat := `{
+		"kind": "Dash0SyntheticCheck",
+		"metadata": {"name": "api-health"},
+		"spec": {
+			"enabled": "yes",
+			"plugin": {"kind": "http", "spec": {"method": "get", "url": "https://example.com"}},
+			"schedule": {"interval": "1m", "locations": []},
+			"retries": {"count": 1.5}
+		}
+	}`
+	errs := Validate(KindSyntheticCheck, decode(t, flat))
+	want := map[string]string{
+		"/spec/enabled":             "must be a boolean, got string",
+		"/spec/plugin/spec/request": `missing required field "request"`,
+		"/spec/retries/count":       "must be an integer, got number",
+		"/spec/schedule/locations":  "must have at least 1 items",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("got %d problems, want %d: %+v", len(errs), len(want), errs)
+	}
+	for _, e := range errs {
+		if want[e.Pointer] != e.Detail {
+			t.Errorf("%s: %q, want %q", e.Pointer, e.Detail, want[e.Pointer])
+		}
+	}
+}
+
+func TestValidate_SyntheticCheckPluginKinds(t *testing.T) {
+	check := func(plugin string) string {
+		return `{"kind": "Dash0SyntheticCheck", "metadata": {"name": "probe"}, "spec": {"enabled": true, "plugin": ` +
+			plugin + `, "schedule": {"interval": "1m", "locations": ["eu-west-1"]}}}`
+	}
+	for name, plugin := range map[string]string{
+		"tcp":       `{"kind": "tcp", "spec": {"request": {"host": "db.example.com", "port": 5432, "tls": true}}}`,
+		"dns":       `{"kind": "dns", "spec": {"request": {"name": "example.com", "recordType": "AAAA"}}}`,
+		"icmp":      `{"kind": "icmp", "spec": {"request": {"host": "10.0.0.1", "count": 3}}}`,
+		"ping":      `{"kind": "ping", "spec": {"request": {"host": "10.0.0.1"}}}`,
+		"multistep": `{"kind": "multistep", "spec": {"steps": [{"name": "login", "request": {"method": "post", "url": "https://example.com/login"}}]}}`,
+		"unknown":   `{"kind": "browser", "spec": {"script": "await page.goto('https://example.com')"}}`,
+	} {
+		if errs := Validate(KindSyntheticCheck, decode(t, check(plugin))); len(errs) != 0 {
+			t.Errorf("%s: valid check has problems: %+v", name, errs)
+		}
+	}
+
+	for plugin, want := range map[string]map[string]string{
+		`{"kind": "tcp", "spec": {"request": {"host": "db.example.com", "port": 70000}}}`: {
+			"/spec/plugin/spec/request/port": "must be at most 65535, got 70000",
+		},
+		`{"kind": "dns", "spec": {"request": {"recordType": "ANY"}}}`: {
+			"/spec/plugin/spec/request/name":       `missing required field "name"`,
+			"/spec/plugin/spec/request/recordType": `must be one of "A", "AAAA", "CNAME", "MX", "NS", "PTR", "SOA", "SRV", "TXT", "CAA", got "ANY"`,
+		},
+		`{"kind": "icmp", "spec": {}}`: {
+			"/spec/plugin/spec/request": `missing required field "request"`,
+		},
+		`{"kind": "multistep", "spec": {"steps": [{"name": "home", "request": {"url": "https://example.com"}}]}}`: {
+			"/spec/plugin/spec/steps/0/request/method": `missing required field "method"`,
+		},
+		`{"kind": "http", "spec": {"request": {"method": "get", "url": "https://example.com"}, "assertions": [
+			{"kind": "status_code", "spec": {"operator": "in_range", "min": 200}},
+			{"kind": "json_path", "spec": {"path": "$.status", "operator": "is"}},
+			{"kind": "tls_certificate", "spec": {"minDaysUntilExpiry": 0}},
+			{"kind": "latency", "spec": {}}
+		]}}`: {
+			"/spec/plugin/spec/assertions/0/spec/max":                `missing required field "max"`,
+			"/spec/plugin/spec/assertions/1/spec/value":              `missing required field "value"`,
+			"/spec/plugin/spec/assertions/2/spec/minDaysUntilExpiry": "must be at least 1, got 0",
+			"/spec/plugin/spec/assertions/3/kind":                    `must be one of "status_code", "body", "json_path", "response_time", "tls_certificate", got "latency"`,
+		},
+	} {
+		errs := Validate(KindSyntheticCheck, decode(t, check(plugin)))
+		if len(errs) != len(want) {
+			t.Errorf("%s: got %d problems, want %d: %+v", plugin, len(errs), len(want), errs)
+			continue
+		}
+		for _, e := range errs {
+			if want[e.Pointer] != e.Detail {
+				t.Errorf("%s: %q, want %q", e.Pointer, e.Detail, want[e.Pointer])
+			}
+		}
+	}
+}
+
+func TestValidate_SamplingRule(t *testing.T) {
+	valid := `{
+		"kind": "Dash0Sampling",
+		"metadata": {"name": "errors"},
+		"spec": {"enabled": true, "conditions": {"kind": "and", "spec": {"conditions": [
+			{"kind": "error", "spec": {}},
+			{"kind": "probabilistic", "spec": {"rate": 0.5}}
+		]}}}
+	}`
+	if errs := Validate(KindSamplingRule, decode(t, valid)); len(errs) != 0 {
+		t.Errorf("valid rule has problems: %+v", errs)
+	}
+
+	invalid := `{
+		"kind": "Dash0Sampling",
+		"metadata": {"name": ""},
+		"spec": {"enabled": true, "conditions": {"kind": "and", "spec": {"conditions": [
+			{"kind": "probabilistic", "spec": {"probability": 0.1}},
+			{"kind": "probabilistic", "spec": {"rate": 10}},
+			{"kind": "tail", "spec": {}}
+		]}}}
+	}`
+	errs := Validate(KindSamplingRule, decode(t, invalid))
+	want := map[string]string{
+		"/metadata/name": "must not be empty",
+		"/spec/conditions/spec/conditions/0/spec/rate": `missing required field "rate"`,
+		"/spec/conditions/spec/conditions/1/spec/rate": "must be at most 1, got 10",
+		"/spec/conditions/spec/conditions/2/kind":      `must be one of "error", "probabilistic", "ottl", "and", got "tail"`,
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("got %d problems, want %d: %+v", len(errs), len(want), errs)
+	}
+	for _, e := range errs {
+		if want[e.Pointer] != e.Detail {
+			t.Errorf("%s: %q, want %q", e.Pointer, e.Detail, want[e.Pointer])
+		}
+	}
+}
+
+func TestValidate_ViewAndDashboard(t *testing.T) {
+	if errs := Validate(KindView, decode(t, `{"kind":"Dash0View","metadata":{"name":"v"},"spec":{"type":"resources"}}`)); len(errs) != 0 {
+		t.Errorf("valid view has problems: %+v", errs)
+	}
+	errs := Validate(KindView, decode(t, `{"kind":"PersesDashboard","spec":{}}`))
+	if len(errs) != 3 {
+		t.Errorf("got %+v, want wrong kind, missing metadata, and missing spec.type", errs)
+	}
+
+	full := `{"kind":"Dash0View","metadata":{"name":"v"},"spec":{
+		"type":"spans",
+		"display":{"name":"Checkout errors","folder":["Payments"]},
+		"query":"timeout",
+		"filter":[{"key":"service.name","operator":"is","value":"checkout"},{"key":"http.route","operator":"is_set"}],
+		"timeRange":{"from":"now-1h","to":"now"},
+		"table":{"columns":[{"key":"otel.span.name","label":"Operation"}],"sort":[{"key":"otel.span.duration","direction":"descending"}]},
+		"groupBy":["service.name"],
+		"visualizations":[{"renderedAs":"time_series","metric":"error_rate","groupBy":["otel.span.name"]}]
+	}}`
+	if errs := Validate(KindView, decode(t, full)); len(errs) != 0 {
+		t.Errorf("valid full view has problems: %+v", errs)
+	}
+	invalid := `{"kind":"Dash0View","metadata":{"name":"v"},"spec":{
+		"type":"traces",
+		"filter":[{"key":"service.name","operator":"equals","value":"checkout"},{"key":"http.route","operator":"is"}],
+		"table":{"columns":[{"label":"Operation"}],"sort":[{"key":"otel.span.duration","direction":"desc"}]},
+		"visualizations":[{"metric":"count"}]
+	}}`
+	errs = Validate(KindView, decode(t, invalid))
+	want := map[string]string{
+		"/spec/type":                        `must be one of "resources", "spans", "logs", "metrics", "web_events", got "traces"`,
+		"/spec/filter/0/operator":           `must be one of "is", "is_not", "contains", "starts_with", "gt", "lt", "is_set", "is_not_set", got "equals"`,
+		"/spec/filter/1/value":              `missing required field "value"`,
+		"/spec/table/columns/0/key":         `missing required field "key"`,
+		"/spec/table/sort/0/direction":      `must be one of "ascending", "descending", got "desc"`,
+		"/spec/visualizations/0/renderedAs": `missing required field "renderedAs"`,
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("got %d problems, want %d: %+v", len(errs), len(want), errs)
+	}
+	for _, e := range errs {
+		if want[e.Pointer] != e.Detail {
+			t.Errorf("%s: %q, want %q", e.Pointer, e.Detail, want[e.Pointer])
+		}
+	}
+
+	for _, panels := range []string{`{}`, `[]`} {
+		body := `{"kind":"PersesDashboard","metadata":{"name":"d"},"spec":{"display":{"name":"D"},"panels":` + panels + `}}`
+		if errs := Validate(KindDashboard, decode(t, body)); len(errs) != 0 {
+			t.Errorf("panels %s: %+v", panels, errs)
+		}
+	}
+	if errs := Validate(KindDashboard, decode(t, `"not an object"`)); len(errs) != 1 || errs[0].Pointer != "/" {
+		t.Errorf("got %+v, want one problem at the root", errs)
+	}
+	if errs := Validate("Unknown", decode(t, `{}`)); errs != nil {
+		t.Errorf("unknown kind has problems: %+v", errs)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if result := Check(KindView, decode(t, `{"kind":"Dash0View","metadata":{"name":"v"},"spec":{"type":"resources"}}`)); result != nil {
+		t.Errorf("Check() of a valid body = %+v", result)
+	}
+	result := Check(KindView, decode(t, `{"kind":"Dash0View","metadata":{"name":"v"}}`))
+	if result == nil || result.Success || result.Error.StatusCode != 400 || result.Error.Code != "validation_error" {
+		t.Fatalf("Check() = %+v, want a validation error", result)
+	}
+	msg := result.Error.Message()
+	for _, s := range []string{"not a valid Dash0View", `/spec: missing required field "spec"`, "dash0_examples"} {
+		if !strings.Contains(msg, s) {
+			t.Errorf("message missing %q:\n%s", s, msg)
+		}
+	}
+}
+
+func TestNormalize_SamplingRule(t *testing.T) {
+	body := decode(t, `{
+		"kind": "Dash0Sampling",
+		"metadata": {"name": "keep-errors"},
+		"spec": {
+			"enabled": true,
+			"conditions": {"kind": "and", "spec": {"conditions": [
+				{"kind": "error", "spec": {}},
+				{"kind": "probabilistic", "spec": {"probability": 10}}
+			]}}
+		}
+	}`)
+	normalized, fixes := Normalize(KindSamplingRule, body)
+	if len(fixes) != 2 {
+		t.Fatalf("fixes = %+v, want the rename and the percentage", fixes)
+	}
+	if fixes[0].Pointer != "/spec/conditions/spec/conditions/1/spec/rate" {
+		t.Errorf("fixes[0].Pointer = %q", fixes[0].Pointer)
+	}
+	if errs := Validate(KindSamplingRule, normalized); len(errs) != 0 {
+		t.Errorf("normalized rule has problems: %+v", errs)
+	}
+	data, _ := json.Marshal(normalized)
+	if !strings.Contains(string(data), `"rate":0.1`) || strings.Contains(string(data), "probability") {
+		t.Errorf("normalized = %s", data)
+	}
+	// The caller's body is left alone
+	if original, _ := json.Marshal(body); !strings.Contains(string(original), "probability") {
+		t.Error("Normalize modified its input")
+	}
+}
+
+func TestNormalize_SyntheticCheck(t *testing.T) {
+	tests := map[string]string{
+		"flat in plugin.spec": `{"enabled": true, "plugin": {"kind": "http", "spec": {"method": "get", "url": "https://example.com", "headers": {}}}}`,
+		"in plugin":           `{"enabled": true, "plugin": {"kind": "http", "request": {"method": "get", "url": "https://example.com"}}}`,
+		"in spec":             `{"enabled": true, "request": {"method": "get", "url": "https://example.com"}}`,
+	}
+	for name, spec := range tests {
+		t.Run(name, func(t *testing.T) {
+			body := decode(t, `{"kind": "Dash0SyntheticCheck", "metadata": {"name": "api"}, "spec": `+spec+`}`)
+			body.(map[string]interface{})["spec"].(map[string]interface{})["schedule"] = decode(t, `{"interval": "1m", "locations": ["eu-west-1"]}`)
+			normalized, fixes := Normalize(KindSyntheticCheck, body)
+			if len(fixes) != 1 || fixes[0].Pointer != "/spec/plugin/spec/request" {
+				t.Errorf("fixes = %+v", fixes)
+			}
+			if errs := Validate(KindSyntheticCheck, normalized); len(errs) != 0 {
+				t.Errorf("normalized check has problems: %+v", errs)
+			}
+		})
+	}
+
+	valid := decode(t, `{"kind": "Dash0SyntheticCheck", "spec": {"plugin": {"kind": "http", "spec": {"request": {"url": "https://example.com"}}}}}`)
+	if _, fixes := Normalize(KindSyntheticCheck, valid); fixes != nil {
+		t.Errorf("nested request was changed: %+v", fixes)
+	}
+}
+
+func TestNormalize_CheckRule(t *testing.T) {
+	body := decode(t, `{"alert": "HighErrorRate", "expr": "rate(errors[5m]) > 1", "name": "kept"}`)
+	normalized, fixes := Normalize(KindCheckRule, body)
+	rule := normalized.(map[string]interface{})
+	// name is already set, so only expr is renamed
+	if len(fixes) != 1 || rule["expression"] != "rate(errors[5m]) > 1" || rule["name"] != "kept" || rule["alert"] != "HighErrorRate" {
+		t.Errorf("normalized = %v, fixes = %+v", rule, fixes)
+	}
+
+	if _, fixes := Normalize(KindView, body); fixes != nil {
+		t.Errorf("kind without normalizations has fixes: %+v", fixes)
+	}
+}
+
+func TestReport(t *testing.T) {
+	fixes := []Fix{{Pointer: "/spec/rate", Detail: `renamed "probability" to "rate"`}}
+
+	ok := Report(&client.ToolResult{Success: true, Data: map[string]interface{}{"id": "r1"}}, fixes)
+	for _, s := range []string{`"id": "r1"`, "## Normalized", "`/spec/rate`"} {
+		if !strings.Contains(ok.Markdown, s) {
+			t.Errorf("markdown missing %q:\n%s", s, ok.Markdown)
+		}
+	}
+
+	failed := Report(client.ErrorResult(400, "bad"), fixes)
+	if !strings.Contains(failed.Error.Hint, "normalized before sending (/spec/rate") {
+		t.Errorf("hint = %q", failed.Error.Hint)
+	}
+
+	plain := &client.ToolResult{Success: true}
+	if Report(plain, nil).Markdown != "" {
+		t.Error("Report without fixes changed the result")
+	}
+}
+
+func TestBody(t *testing.T) {
+	body, result := Body(map[string]interface{}{"body": map[string]interface{}{"kind": "Dash0View"}})
+	if result != nil || body.(map[string]interface{})["kind"] != "Dash0View" {
+		t.Errorf("body = %v, %v; want the body argument", body, result)
+	}
+
+	yamlBody := `
+kind: Dash0SyntheticCheck
+metadata:
+  name: api-health
+spec:
+  enabled: true
+  plugin:
+    kind: http
+    spec:
+      request: {method: get, url: "https://example.com"}
+  schedule:
+    interval: 1m
+    locations: [eu-west-1]
+  retries:
+    count: 2
+`
+	body, result = Body(map[string]interface{}{"body_yaml": yamlBody})
+	if result != nil {
+		t.Fatalf("Body(body_yaml) failed: %v", result.Error)
+	}
+	if errs := Validate(KindSyntheticCheck, body); len(errs) != 0 {
+		t.Errorf("YAML body has problems: %+v", errs)
+	}
+	retries := body.(map[string]interface{})["spec"].(map[string]interface{})["retries"].(map[string]interface{})
+	if _, ok := retries["count"].(float64); !ok {
+		t.Errorf("count is %T, want float64 as in JSON arguments", retries["count"])
+	}
+
+	_, result = Body(map[string]interface{}{"body_yaml": "kind: Dash0View\nmetadata:\n  name: error: rate\nspec: {}\n"})
+	if result == nil {
+		t.Fatal("expected invalid YAML to fail")
+	}
+	if !strings.Contains(result.Error.Detail, "line 3") || !strings.Contains(result.Error.Hint, "line 3: name: error: rate") {
+		t.Errorf("error = %q, hint = %q; want line 3 named", result.Error.Detail, result.Error.Hint)
+	}
+
+	for name, args := range map[string]map[string]interface{}{
+		"neither":   {},
+		"both":      {"body": map[string]interface{}{}, "body_yaml": "kind: Dash0View"},
+		"not text":  {"body_yaml": 42.0},
+		"scalar":    {"body_yaml": "just a string"},
+		"documents": {"body_yaml": "kind: Dash0View\n---\nkind: Dash0View\n"},
+	} {
+		if _, result := Body(args); result == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
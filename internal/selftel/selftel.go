@@ -0,0 +1,566 @@
+// Package selftel records OpenTelemetry spans for the server's own work: one
+// SERVER span per tool call and a CLIENT span for every Dash0 API request it
+// makes. The spans are buffered and sent as OTLP JSON through the spans
+// ingestion endpoint, so the MCP server can be observed in Dash0 like any
+// other service. Per-tool and per-endpoint counters, including upstream
+// failures by class, are kept in memory for the session usage and server
+// stats reports and the optional periodic summary on stderr.
+package selftel
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// ServiceName is the service.name of the spans.
+	ServiceName = "dash0-mcp"
+	// scopeName is the instrumentation scope of the spans.
+	scopeName = "github.com/npcomplete777/dash0-mcp/internal/selftel"
+	// maxPending bounds the spans buffered between flushes; spans recorded
+	// while the buffer is full are dropped and counted.
+	maxPending = 2048
+	// bytesPerToken is the rough ratio used to estimate the tokens of a
+	// tool result from its size.
+	bytesPerToken = 4
+)
+
+// OTLP span kinds and status codes.
+const (
+	spanKindServer  = 2
+	spanKindClient  = 3
+	statusCodeError = 2
+)
+
+// Upstream failure classes of an API request.
+const (
+	// ClassRateLimited is a 429 response.
+	ClassRateLimited = "rate_limited"
+	// ClassServerError is a 5xx response.
+	ClassServerError = "server_error"
+	// ClassTimeout is a request that timed out.
+	ClassTimeout = "timeout"
+	// ClassNetwork is a request that failed without a response, e.g. on a
+	// refused connection or a DNS error.
+	ClassNetwork = "network"
+	// ClassClientError is any other 4xx response, usually a bad request.
+	ClassClientError = "client_error"
+)
+
+// Sender delivers an OTLP JSON traces payload.
+type Sender func(ctx context.Context, body interface{}) error
+
+// Recorder buffers spans and counts tool calls and API requests. A nil
+// Recorder records nothing, so callers need not check whether it exists.
+type Recorder struct {
+	version string
+	send    Sender
+	dropped atomic.Int64
+
+	mu        sync.Mutex
+	pending   []map[string]interface{}
+	tools     map[string]*ToolStats
+	endpoints map[endpointKey]*EndpointStats
+}
+
+// New creates a Recorder that reports the given server version and delivers
+// spans with send. With a nil send, no spans are recorded and only the
+// counters are kept.
+func New(version string, send Sender) *Recorder {
+	return &Recorder{
+		version:   version,
+		send:      send,
+		tools:     make(map[string]*ToolStats),
+		endpoints: make(map[endpointKey]*EndpointStats),
+	}
+}
+
+// FailureCounts counts failed API requests by class.
+type FailureCounts struct {
+	RateLimited int64 `json:"rate_limited,omitempty"`
+	ServerError int64 `json:"server_error,omitempty"`
+	Timeout     int64 `json:"timeout,omitempty"`
+	Network     int64 `json:"network,omitempty"`
+	ClientError int64 `json:"client_error,omitempty"`
+}
+
+// Total returns the number of failed requests.
+func (f FailureCounts) Total() int64 {
+	return f.RateLimited + f.ServerError + f.Timeout + f.Network + f.ClientError
+}
+
+// Transient returns the number of failures that are worth retrying: rate
+// limits, server errors, timeouts, and network errors.
+func (f FailureCounts) Transient() int64 {
+	return f.RateLimited + f.ServerError + f.Timeout + f.Network
+}
+
+func (f *FailureCounts) add(class string) {
+	switch class {
+	case ClassRateLimited:
+		f.RateLimited++
+	case ClassServerError:
+		f.ServerError++
+	case ClassTimeout:
+		f.Timeout++
+	case ClassNetwork:
+		f.Network++
+	case ClassClientError:
+		f.ClientError++
+	}
+}
+
+// ToolStats counts the calls of one tool.
+type ToolStats struct {
+	Tool   string `json:"tool"`
+	Calls  int64  `json:"calls"`
+	Errors int64  `json:"errors"`
+	// Flaky counts calls that succeeded although one of their API requests
+	// failed transiently, e.g. after a retried 429 or 503.
+	Flaky int64 `json:"flaky"`
+	// Upstream counts the failed API requests made by the tool's calls.
+	Upstream FailureCounts `json:"upstream_failures"`
+	// Duration is the total time spent in the tool's calls.
+	Duration time.Duration `json:"duration_ns"`
+	// MaxDuration is the duration of the slowest call.
+	MaxDuration time.Duration `json:"max_duration_ns"`
+	// ResultBytes is the total size of the results returned to the client.
+	ResultBytes int64 `json:"result_bytes"`
+}
+
+// EndpointStats counts the API requests one tool made to one endpoint.
+type EndpointStats struct {
+	// Tool is the tool that made the requests; empty for requests made
+	// outside a tool call.
+	Tool string `json:"tool,omitempty"`
+	// Endpoint is the method and path, with object IDs replaced by {id},
+	// e.g. "GET /api/dashboards/{id}".
+	Endpoint string        `json:"endpoint"`
+	Requests int64         `json:"requests"`
+	Failures FailureCounts `json:"failures"`
+}
+
+// FailureRate returns the share of failed requests, between 0 and 1.
+func (s EndpointStats) FailureRate() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return float64(s.Failures.Total()) / float64(s.Requests)
+}
+
+type endpointKey struct{ tool, endpoint string }
+
+// AvgLatency returns the mean duration of a call, or 0 without calls.
+func (s ToolStats) AvgLatency() time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.Duration / time.Duration(s.Calls)
+}
+
+// ToolStats returns the counters of every tool that was called, sorted by
+// tool name.
+func (r *Recorder) ToolStats() []ToolStats {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := make([]ToolStats, 0, len(r.tools))
+	for _, s := range r.tools {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Tool < stats[j].Tool })
+	return stats
+}
+
+// EndpointStats returns the request counters per tool and endpoint, those
+// with the most failures first.
+func (r *Recorder) EndpointStats() []EndpointStats {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := make([]EndpointStats, 0, len(r.endpoints))
+	for _, s := range r.endpoints {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if fa, fb := a.Failures.Total(), b.Failures.Total(); fa != fb {
+			return fa > fb
+		}
+		if a.Endpoint != b.Endpoint {
+			return a.Endpoint < b.Endpoint
+		}
+		return a.Tool < b.Tool
+	})
+	return stats
+}
+
+// Dropped returns the number of spans discarded because the buffer was full.
+func (r *Recorder) Dropped() int64 {
+	if r == nil {
+		return 0
+	}
+	return r.dropped.Load()
+}
+
+// spanRef identifies the span that requests made under a context belong to.
+type spanRef struct {
+	traceID string
+	spanID  string
+}
+
+type toolCallKey struct{}
+
+// ToolCall is an in-progress tool call span.
+type ToolCall struct {
+	r     *Recorder
+	tool  string
+	ref   spanRef
+	start time.Time
+	// upstream counts the failed API requests of the call.
+	upstream FailureCounts
+}
+
+// StartTool starts the span of a tool call. API requests made with the
+// returned context become children of the span and are counted for the
+// tool.
+func (r *Recorder) StartTool(ctx context.Context, tool string) (context.Context, *ToolCall) {
+	if r == nil {
+		return ctx, nil
+	}
+	call := &ToolCall{
+		r:     r,
+		tool:  tool,
+		ref:   spanRef{traceID: randomID(16), spanID: randomID(8)},
+		start: time.Now(),
+	}
+	return context.WithValue(ctx, toolCallKey{}, call), call
+}
+
+// End finishes the span of a tool call. errCode is the error code of a
+// failed call and empty on success; resultBytes is the size of the result
+// sent to the client.
+func (c *ToolCall) End(errCode string, resultBytes int) {
+	if c == nil {
+		return
+	}
+	end := time.Now()
+	attrs := []map[string]interface{}{
+		attr("mcp.method.name", "tools/call"),
+		attr("gen_ai.operation.name", "execute_tool"),
+		attr("gen_ai.tool.name", c.tool),
+		attr("mcp.tool.result.bytes", resultBytes),
+		attr("mcp.tool.result.tokens_estimate", resultBytes/bytesPerToken),
+	}
+	if errCode != "" {
+		attrs = append(attrs, attr("error.type", errCode))
+	}
+	c.r.record(newSpan(c.ref, "", "tools/call "+c.tool, spanKindServer, c.start, end, attrs, errCode != ""))
+
+	c.r.mu.Lock()
+	defer c.r.mu.Unlock()
+	s, ok := c.r.tools[c.tool]
+	if !ok {
+		s = &ToolStats{Tool: c.tool}
+		c.r.tools[c.tool] = s
+	}
+	s.Calls++
+	if errCode != "" {
+		s.Errors++
+	} else if c.upstream.Transient() > 0 {
+		s.Flaky++
+	}
+	s.Upstream.RateLimited += c.upstream.RateLimited
+	s.Upstream.ServerError += c.upstream.ServerError
+	s.Upstream.Timeout += c.upstream.Timeout
+	s.Upstream.Network += c.upstream.Network
+	s.Upstream.ClientError += c.upstream.ClientError
+	s.Duration += end.Sub(c.start)
+	if d := end.Sub(c.start); d > s.MaxDuration {
+		s.MaxDuration = d
+	}
+	s.ResultBytes += int64(resultBytes)
+}
+
+// ObserveRequest records the span of an API request that was sent at start
+// and answered with resp or failed with err, and counts it for the tool call
+// and endpoint. Requests made outside a tool call start a trace of their own.
+func (r *Recorder) ObserveRequest(req *http.Request, resp *http.Response, err error, start time.Time) {
+	if r == nil {
+		return
+	}
+	call, _ := req.Context().Value(toolCallKey{}).(*ToolCall)
+	parent := spanRef{traceID: randomID(16)}
+	tool := ""
+	if call != nil {
+		parent, tool = call.ref, call.tool
+	}
+	class := failureClass(resp, err)
+	r.count(call, endpointKey{tool: tool, endpoint: req.Method + " " + Endpoint(req.URL.Path)}, class)
+
+	ref := spanRef{traceID: parent.traceID, spanID: randomID(8)}
+
+	attrs := []map[string]interface{}{
+		attr("http.request.method", req.Method),
+		attr("server.address", req.URL.Hostname()),
+		attr("url.path", req.URL.Path),
+	}
+	failed := false
+	switch {
+	case err != nil:
+		failed = true
+		attrs = append(attrs, attr("error.type", errorType(err)))
+	case resp.StatusCode >= 400:
+		failed = true
+		attrs = append(attrs,
+			attr("http.response.status_code", resp.StatusCode),
+			attr("error.type", strconv.Itoa(resp.StatusCode)),
+		)
+	default:
+		attrs = append(attrs, attr("http.response.status_code", resp.StatusCode))
+	}
+	r.record(newSpan(ref, parent.spanID, req.Method+" "+req.URL.Path, spanKindClient, start, time.Now(), attrs, failed))
+}
+
+// count adds a request to the counters of its endpoint and tool call.
+func (r *Recorder) count(call *ToolCall, key endpointKey, class string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.endpoints[key]
+	if !ok {
+		s = &EndpointStats{Tool: key.tool, Endpoint: key.endpoint}
+		r.endpoints[key] = s
+	}
+	s.Requests++
+	s.Failures.add(class)
+	if call != nil {
+		call.upstream.add(class)
+	}
+}
+
+// failureClass returns the failure class of a request, or "" when it
+// succeeded or was canceled by the caller.
+func failureClass(resp *http.Response, err error) string {
+	switch {
+	case err != nil:
+		switch errorType(err) {
+		case "canceled":
+			return ""
+		case "timeout":
+			return ClassTimeout
+		}
+		return ClassNetwork
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return ClassRateLimited
+	case resp.StatusCode >= 500:
+		return ClassServerError
+	case resp.StatusCode >= 400:
+		return ClassClientError
+	}
+	return ""
+}
+
+// collections are the API paths whose next segment is an object ID.
+var collections = []string{
+	"/api/alerting/check-rules",
+	"/api/dashboards",
+	"/api/sampling-rules",
+	"/api/synthetic-checks",
+	"/api/views",
+}
+
+// Endpoint returns an API path with the object ID replaced by {id}, so the
+// requests for different objects are counted together.
+func Endpoint(path string) string {
+	for _, c := range collections {
+		rest, ok := strings.CutPrefix(path, c+"/")
+		if !ok || rest == "" {
+			continue
+		}
+		if i := strings.Index(rest, "/"); i >= 0 {
+			return c + "/{id}" + rest[i:]
+		}
+		return c + "/{id}"
+	}
+	return path
+}
+
+// errorType classifies a transport error for the error.type attribute.
+func errorType(err error) string {
+	var timeout interface{ Timeout() bool }
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &timeout) && timeout.Timeout():
+		return "timeout"
+	}
+	return "transport_error"
+}
+
+// record buffers a span, dropping it when the buffer is full. Without a
+// Sender, spans are not kept.
+func (r *Recorder) record(span map[string]interface{}) {
+	if r.send == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.pending) >= maxPending {
+		r.dropped.Add(1)
+		return
+	}
+	r.pending = append(r.pending, span)
+}
+
+// Flush sends the buffered spans. Spans that fail to send are discarded
+// rather than retried, so an unreachable endpoint cannot grow the buffer.
+func (r *Recorder) Flush(ctx context.Context) error {
+	if r == nil || r.send == nil {
+		return nil
+	}
+	r.mu.Lock()
+	spans := r.pending
+	r.pending = nil
+	r.mu.Unlock()
+	if len(spans) == 0 {
+		return nil
+	}
+	return r.send(ctx, r.payload(spans))
+}
+
+// Run flushes the buffered spans every interval until ctx is done. Flush
+// errors are logged at debug level.
+func (r *Recorder) Run(ctx context.Context, interval time.Duration) {
+	if r == nil || r.send == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Flush(ctx); err != nil {
+				slog.Debug("self-telemetry flush failed", "error", err)
+			}
+		}
+	}
+}
+
+// RunSummary logs the calls, errors, and latency of every tool that was
+// called every interval until ctx is done, skipping intervals without new
+// calls.
+func (r *Recorder) RunSummary(ctx context.Context, interval time.Duration, logger *slog.Logger) {
+	if r == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var logged int64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			logged = r.logSummary(logger, logged)
+		}
+	}
+}
+
+// logSummary logs the tool counters if there were more than since calls,
+// and returns the number of calls.
+func (r *Recorder) logSummary(logger *slog.Logger, since int64) int64 {
+	stats := r.ToolStats()
+	var calls, errs int64
+	for _, s := range stats {
+		calls += s.Calls
+		errs += s.Errors
+	}
+	if calls == since {
+		return calls
+	}
+	logger.Info("tool usage", "tools", len(stats), "calls", calls, "errors", errs)
+	for _, s := range stats {
+		logger.Info("tool usage", "tool", s.Tool, "calls", s.Calls, "errors", s.Errors,
+			"avg_latency", s.AvgLatency().Round(time.Millisecond), "max_latency", s.MaxDuration.Round(time.Millisecond))
+	}
+	return calls
+}
+
+// payload wraps spans in an OTLP JSON traces request.
+func (r *Recorder) payload(spans []map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": []map[string]interface{}{
+						attr("service.name", ServiceName),
+						attr("service.version", r.version),
+						attr("telemetry.sdk.language", "go"),
+					},
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"scope": map[string]interface{}{"name": scopeName},
+						"spans": spans,
+					},
+				},
+			},
+		},
+	}
+}
+
+// newSpan builds an OTLP JSON span.
+func newSpan(ref spanRef, parentSpanID, name string, kind int, start, end time.Time, attrs []map[string]interface{}, failed bool) map[string]interface{} {
+	span := map[string]interface{}{
+		"traceId":           ref.traceID,
+		"spanId":            ref.spanID,
+		"name":              name,
+		"kind":              kind,
+		"startTimeUnixNano": strconv.FormatInt(start.UnixNano(), 10),
+		"endTimeUnixNano":   strconv.FormatInt(end.UnixNano(), 10),
+		"attributes":        attrs,
+	}
+	if parentSpanID != "" {
+		span["parentSpanId"] = parentSpanID
+	}
+	if failed {
+		span["status"] = map[string]interface{}{"code": statusCodeError}
+	}
+	return span
+}
+
+// attr builds an OTLP JSON attribute. Integers are encoded as strings, as
+// the OTLP JSON mapping requires for 64-bit values.
+func attr(key string, value interface{}) map[string]interface{} {
+	var v map[string]interface{}
+	switch value := value.(type) {
+	case int:
+		v = map[string]interface{}{"intValue": strconv.Itoa(value)}
+	default:
+		v = map[string]interface{}{"stringValue": value}
+	}
+	return map[string]interface{}{"key": key, "value": v}
+}
+
+// randomID returns n random bytes as lowercase hex.
+func randomID(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,322 @@
+package selftel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// captureSender returns a Sender that stores the spans of each payload.
+func captureSender(t *testing.T, spans *[]map[string]interface{}) Sender {
+	t.Helper()
+	return func(ctx context.Context, body interface{}) error {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("failed to marshal payload: %v", err)
+		}
+		var payload struct {
+			ResourceSpans []struct {
+				Resource struct {
+					Attributes []interface{} `json:"attributes"`
+				} `json:"resource"`
+				ScopeSpans []struct {
+					Spans []map[string]interface{} `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		if err := json.Unmarshal(data, &payload); err != nil {
+			t.Fatalf("failed to decode payload: %v", err)
+		}
+		rs := payload.ResourceSpans[0]
+		if got := attrValue(rs.Resource.Attributes, "service.name"); got != ServiceName {
+			t.Errorf("service.name = %v, want %s", got, ServiceName)
+		}
+		*spans = append(*spans, rs.ScopeSpans[0].Spans...)
+		return nil
+	}
+}
+
+// attrValue returns the string or int value of a decoded OTLP JSON
+// attribute.
+func attrValue(attrs interface{}, key string) interface{} {
+	for _, a := range attrs.([]interface{}) {
+		a := a.(map[string]interface{})
+		if a["key"] != key {
+			continue
+		}
+		v := a["value"].(map[string]interface{})
+		if s, ok := v["stringValue"]; ok {
+			return s
+		}
+		return v["intValue"]
+	}
+	return nil
+}
+
+func TestRecorder_ToolAndRequestSpans(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var spans []map[string]interface{}
+	rec := New("1.2.3", captureSender(t, &spans))
+
+	ctx, call := rec.StartTool(context.Background(), "dash0_logs_query")
+	for _, path := range []string{"/api/logs", "/missing"} {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+path, nil)
+		start := time.Now()
+		resp, err := http.DefaultClient.Do(req)
+		rec.ObserveRequest(req, resp, err, start)
+		resp.Body.Close()
+	}
+	call.End("", 400)
+
+	if err := rec.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans, want 3", len(spans))
+	}
+
+	ok, missing, tool := spans[0], spans[1], spans[2]
+	if tool["name"] != "tools/call dash0_logs_query" || tool["kind"] != float64(spanKindServer) {
+		t.Errorf("tool span = %v", tool)
+	}
+	if _, hasParent := tool["parentSpanId"]; hasParent {
+		t.Error("tool span should be a root span")
+	}
+	if got := attrValue(tool["attributes"], "mcp.tool.result.tokens_estimate"); got != "100" {
+		t.Errorf("tokens estimate = %v, want 100", got)
+	}
+	for _, s := range []map[string]interface{}{ok, missing} {
+		if s["traceId"] != tool["traceId"] || s["parentSpanId"] != tool["spanId"] {
+			t.Errorf("request span %v is not a child of the tool span", s["name"])
+		}
+		if s["kind"] != float64(spanKindClient) {
+			t.Errorf("request span kind = %v, want client", s["kind"])
+		}
+	}
+	if ok["name"] != "POST /api/logs" || ok["status"] != nil {
+		t.Errorf("ok span = %v", ok)
+	}
+	if got := attrValue(missing["attributes"], "error.type"); got != "404" {
+		t.Errorf("error.type = %v, want 404", got)
+	}
+	if status, _ := missing["status"].(map[string]interface{}); status["code"] != float64(statusCodeError) {
+		t.Errorf("status = %v, want error", missing["status"])
+	}
+
+	// The buffer is empty after a flush
+	spans = nil
+	if err := rec.Flush(context.Background()); err != nil || len(spans) != 0 {
+		t.Errorf("second Flush() = %v with %d spans, want nothing sent", err, len(spans))
+	}
+}
+
+func TestRecorder_ToolStats(t *testing.T) {
+	rec := New("1.0.0", func(context.Context, interface{}) error { return nil })
+	for _, code := range []string{"", "not_found", ""} {
+		_, call := rec.StartTool(context.Background(), "dash0_spans_query")
+		call.End(code, 100)
+	}
+	_, call := rec.StartTool(context.Background(), "dash0_logs_query")
+	call.End("", 10)
+
+	stats := rec.ToolStats()
+	if len(stats) != 2 || stats[0].Tool != "dash0_logs_query" {
+		t.Fatalf("stats = %+v, want two tools sorted by name", stats)
+	}
+	if s := stats[1]; s.Calls != 3 || s.Errors != 1 || s.ResultBytes != 300 {
+		t.Errorf("spans stats = %+v, want 3 calls, 1 error, 300 bytes", s)
+	}
+	if s := stats[1]; s.MaxDuration <= 0 || s.MaxDuration > s.Duration {
+		t.Errorf("spans MaxDuration = %v, want between 0 and the total %v", s.MaxDuration, s.Duration)
+	}
+}
+
+func TestRecorder_LogSummary(t *testing.T) {
+	rec := New("1.0.0", nil)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	if got := rec.logSummary(logger, 0); got != 0 || buf.Len() != 0 {
+		t.Errorf("logSummary() without calls = %d, logged %q", got, buf.String())
+	}
+
+	_, call := rec.StartTool(context.Background(), "dash0_spans_query")
+	call.End("timeout", 0)
+	if got := rec.logSummary(logger, 0); got != 1 {
+		t.Errorf("logSummary() = %d, want 1 call", got)
+	}
+	for _, s := range []string{"tools=1 calls=1 errors=1", "tool=dash0_spans_query calls=1 errors=1 avg_latency=", "max_latency="} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("summary missing %q:\n%s", s, buf.String())
+		}
+	}
+
+	// Nothing is logged again until there are new calls
+	buf.Reset()
+	rec.logSummary(logger, 1)
+	if buf.Len() != 0 {
+		t.Errorf("logged without new calls: %q", buf.String())
+	}
+}
+
+func TestRecorder_UpstreamFailures(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/dashboards/a1":
+			// Rate limited once, then answered
+			if attempts++; attempts == 1 {
+				w.WriteHeader(http.StatusTooManyRequests)
+			}
+		case "/api/views":
+			w.WriteHeader(http.StatusBadGateway)
+		case "/api/spans":
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	rec := New("1.0.0", nil)
+	do := func(ctx context.Context, method, path string) {
+		req, _ := http.NewRequestWithContext(ctx, method, server.URL+path, nil)
+		resp, err := http.DefaultClient.Do(req)
+		rec.ObserveRequest(req, resp, err, time.Now())
+		resp.Body.Close()
+	}
+
+	ctx, call := rec.StartTool(context.Background(), "dash0_dashboards_get")
+	do(ctx, http.MethodGet, "/api/dashboards/a1")
+	do(ctx, http.MethodGet, "/api/dashboards/a1")
+	call.End("", 10)
+
+	ctx, call = rec.StartTool(context.Background(), "dash0_views_list")
+	do(ctx, http.MethodGet, "/api/views")
+	call.End("upstream_error", 10)
+
+	ctx, call = rec.StartTool(context.Background(), "dash0_spans_query")
+	do(ctx, http.MethodPost, "/api/spans")
+	call.End("bad_request", 10)
+
+	tools := map[string]ToolStats{}
+	for _, s := range rec.ToolStats() {
+		tools[s.Tool] = s
+	}
+	if s := tools["dash0_dashboards_get"]; s.Flaky != 1 || s.Errors != 0 || s.Upstream.RateLimited != 1 {
+		t.Errorf("dashboards stats = %+v, want 1 flaky call with 1 rate limit", s)
+	}
+	if s := tools["dash0_views_list"]; s.Flaky != 0 || s.Errors != 1 || s.Upstream.ServerError != 1 {
+		t.Errorf("views stats = %+v, want 1 error with 1 server error", s)
+	}
+	if s := tools["dash0_spans_query"]; s.Upstream.ClientError != 1 || s.Upstream.Transient() != 0 {
+		t.Errorf("spans stats = %+v, want 1 client error", s)
+	}
+
+	endpoints := rec.EndpointStats()
+	if len(endpoints) != 3 {
+		t.Fatalf("endpoints = %+v, want 3", endpoints)
+	}
+	if s := endpoints[0]; s.Endpoint != "GET /api/dashboards/{id}" || s.Tool != "dash0_dashboards_get" || s.Requests != 2 || s.FailureRate() != 0.5 {
+		t.Errorf("endpoints[0] = %+v, want the dashboard endpoint with 1 of 2 failed", s)
+	}
+
+	// Without a Sender, only the counters are kept
+	if len(rec.pending) != 0 {
+		t.Errorf("%d spans pending without a sender", len(rec.pending))
+	}
+}
+
+func TestFailureClass(t *testing.T) {
+	tests := []struct {
+		status int
+		err    error
+		want   string
+	}{
+		{status: http.StatusOK, want: ""},
+		{status: http.StatusNotFound, want: ClassClientError},
+		{status: http.StatusTooManyRequests, want: ClassRateLimited},
+		{status: http.StatusServiceUnavailable, want: ClassServerError},
+		{err: context.DeadlineExceeded, want: ClassTimeout},
+		{err: errors.New("connection refused"), want: ClassNetwork},
+		{err: context.Canceled, want: ""},
+	}
+	for _, tt := range tests {
+		var resp *http.Response
+		if tt.err == nil {
+			resp = &http.Response{StatusCode: tt.status}
+		}
+		if got := failureClass(resp, tt.err); got != tt.want {
+			t.Errorf("failureClass(%d, %v) = %q, want %q", tt.status, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	tests := map[string]string{
+		"/api/dashboards":                  "/api/dashboards",
+		"/api/dashboards/a1":               "/api/dashboards/{id}",
+		"/api/alerting/check-rules/r1":     "/api/alerting/check-rules/{id}",
+		"/api/synthetic-checks/c1/results": "/api/synthetic-checks/{id}/results",
+		"/api/logs":                        "/api/logs",
+	}
+	for path, want := range tests {
+		if got := Endpoint(path); got != want {
+			t.Errorf("Endpoint(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestRecorder_DropsWhenFull(t *testing.T) {
+	sendErr := errors.New("unreachable")
+	rec := New("1.0.0", func(context.Context, interface{}) error { return sendErr })
+	for i := 0; i < maxPending+5; i++ {
+		_, call := rec.StartTool(context.Background(), "dash0_examples")
+		call.End("", 0)
+	}
+	if got := rec.Dropped(); got != 5 {
+		t.Errorf("Dropped() = %d, want 5", got)
+	}
+	if err := rec.Flush(context.Background()); !errors.Is(err, sendErr) {
+		t.Errorf("Flush() error = %v, want %v", err, sendErr)
+	}
+	// Failed spans are discarded, not retried
+	if len(rec.pending) != 0 {
+		t.Errorf("%d spans still pending after a failed flush", len(rec.pending))
+	}
+}
+
+func TestRecorder_Nil(t *testing.T) {
+	var rec *Recorder
+	ctx, call := rec.StartTool(context.Background(), "dash0_examples")
+	call.End("", 10)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com", nil)
+	rec.ObserveRequest(req, nil, errors.New("boom"), time.Now())
+	if err := rec.Flush(ctx); err != nil || rec.ToolStats() != nil {
+		t.Error("nil recorder should do nothing")
+	}
+}
+
+func TestErrorType(t *testing.T) {
+	tests := map[error]string{
+		context.Canceled:         "canceled",
+		context.DeadlineExceeded: "timeout",
+		errors.New("refused"):    "transport_error",
+	}
+	for err, want := range tests {
+		if got := errorType(err); got != want {
+			t.Errorf("errorType(%v) = %q, want %q", err, got, want)
+		}
+	}
+}
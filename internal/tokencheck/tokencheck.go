@@ -0,0 +1,47 @@
+// Package tokencheck validates the auth token against the Dash0 API and
+// finds out what it may do, so that a rejected token or a token that may
+// only read shows up when the server starts rather than as failed tool calls
+// mid-conversation.
+package tokencheck
+
+import (
+	"context"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+)
+
+// ValidatePath is the endpoint the token is validated with: the dashboards
+// list needs a valid token and is one of the endpoints the server has used
+// from the start.
+const ValidatePath = "/api/dashboards"
+
+// Result is what Check found out about the token.
+type Result struct {
+	// Dataset is the dataset queries go to.
+	Dataset string `json:"dataset"`
+	// Write is whether the token may create, update, and delete objects;
+	// unchecked unless the write probe was asked for.
+	Write client.Access `json:"write"`
+}
+
+// Check lists the dashboards with the client's token, bypassing the cache.
+// With probeWrite it also probes whether the token may write, which sends a
+// DELETE, so it is only done when a read-only token changes what the server
+// does. A rejected token or an unreachable API returns the failed result of
+// the dashboards request.
+func Check(ctx context.Context, c *client.Client, probeWrite bool) (*Result, *client.ToolResult) {
+	result := c.Get(client.WithoutCache(ctx), ValidatePath)
+	if !result.Success {
+		return nil, result
+	}
+
+	res := &Result{Dataset: c.Dataset(ctx)}
+	if res.Dataset == "" {
+		res.Dataset = "default"
+	}
+	res.Write = client.AccessUnchecked
+	if probeWrite {
+		res.Write = c.ProbeWrite(ctx)
+	}
+	return res, nil
+}
@@ -0,0 +1,87 @@
+package tokencheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/npcomplete777/dash0-mcp/internal/client"
+	"github.com/npcomplete777/dash0-mcp/internal/config"
+	"github.com/npcomplete777/dash0-mcp/internal/mockapi"
+)
+
+func TestCheck(t *testing.T) {
+	server := httptest.NewServer(mockapi.New())
+	defer server.Close()
+
+	res, failed := Check(context.Background(), client.NewWithBaseURL(server.URL, "mock"), true)
+	if failed != nil {
+		t.Fatalf("Check() failed: %+v", failed.Error)
+	}
+	if res.Dataset != "default" {
+		t.Errorf("dataset = %q, want default", res.Dataset)
+	}
+	// The mock answers the probe with a 404, which does not tell.
+	if res.Write != client.AccessUnknown {
+		t.Errorf("Write = %q, want %q", res.Write, client.AccessUnknown)
+	}
+}
+
+func TestCheck_WithoutWriteProbe(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"items":[]}`)
+	}))
+	defer server.Close()
+
+	res, failed := Check(context.Background(), client.NewWithBaseURL(server.URL, "token"), false)
+	if failed != nil {
+		t.Fatalf("Check() failed: %+v", failed.Error)
+	}
+	if res.Write != client.AccessUnchecked {
+		t.Errorf("Write = %q, want %q", res.Write, client.AccessUnchecked)
+	}
+	if fmt.Sprint(methods) != "[GET /api/dashboards]" {
+		t.Errorf("requests = %v, want only the dashboards GET", methods)
+	}
+}
+
+func TestCheck_ReadOnlyToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"items":[]}`)
+	}))
+	defer server.Close()
+
+	c := client.New(&config.Config{BaseURL: server.URL, AuthToken: "read-token", Dataset: "prod"})
+	res, failed := Check(context.Background(), c, true)
+	if failed != nil {
+		t.Fatalf("Check() failed: %+v", failed.Error)
+	}
+	if res.Write != client.AccessDenied {
+		t.Errorf("Write = %q, want %q", res.Write, client.AccessDenied)
+	}
+	if res.Dataset != "prod" {
+		t.Errorf("dataset = %q, want prod", res.Dataset)
+	}
+}
+
+func TestCheck_Rejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	res, failed := Check(context.Background(), client.NewWithBaseURL(server.URL, "bad-token"), false)
+	if res != nil || failed == nil || failed.Error.Code != client.CodeAuth {
+		t.Fatalf("Check() = %+v, %+v; want an auth error", res, failed)
+	}
+}
@@ -0,0 +1,96 @@
+package transport
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// shutdownTimeout bounds how long open connections may take to close.
+const shutdownTimeout = 5 * time.Second
+
+// HTTP serves an MCP server over HTTP with server-sent events: clients open
+// a session with GET /sse and post requests to /message. Other handlers,
+// such as the alert webhook, are served next to them.
+type HTTP struct {
+	server *server.MCPServer
+	addr   string
+	token  string
+	mux    *http.ServeMux
+	wrap   []func(http.Handler) http.Handler
+}
+
+// NewHTTP creates an HTTP transport for s listening on addr. When token is
+// set, the MCP endpoints require it as a bearer token.
+func NewHTTP(s *server.MCPServer, addr, token string) *HTTP {
+	return &HTTP{server: s, addr: addr, token: token, mux: http.NewServeMux()}
+}
+
+// Handle serves h at pattern. It is not covered by the MCP token, so h
+// authenticates its own requests.
+func (t *HTTP) Handle(pattern string, h http.Handler) {
+	t.mux.Handle(pattern, h)
+}
+
+// Use wraps the MCP endpoints in middleware, e.g. to read settings of a
+// session from the request that opens it.
+func (t *HTTP) Use(middleware func(http.Handler) http.Handler) {
+	t.wrap = append(t.wrap, middleware)
+}
+
+// Listen serves until ctx is cancelled, then closes the open sessions.
+func (t *HTTP) Listen(ctx context.Context) error {
+	srv := &http.Server{Addr: t.addr, ReadHeaderTimeout: 10 * time.Second}
+	sse := server.NewSSEServer(t.server, server.WithHTTPServer(srv), server.WithKeepAlive(true))
+	srv.Handler = t.handler(sse)
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.ListenAndServe() }()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := sse.Shutdown(shutdownCtx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// handler mounts the MCP endpoints of sse next to the other handlers.
+func (t *HTTP) handler(sse *server.SSEServer) http.Handler {
+	var h http.Handler = sse
+	for _, middleware := range t.wrap {
+		h = middleware(h)
+	}
+	h = t.authorize(h)
+	t.mux.Handle(sse.CompleteSsePath(), h)
+	t.mux.Handle(sse.CompleteMessagePath(), h)
+	return t.mux
+}
+
+// authorize rejects requests without the transport's token, if it has one.
+func (t *HTTP) authorize(next http.Handler) http.Handler {
+	if t.token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(t.token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
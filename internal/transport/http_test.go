@@ -0,0 +1,80 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestHTTP_Handler(t *testing.T) {
+	s := server.NewMCPServer("test", "0.0.0")
+	h := NewHTTP(s, "", "secret")
+	h.Handle("/hook", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	var wrapped bool
+	h.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			wrapped = true
+			next.ServeHTTP(w, r)
+		})
+	})
+	srv := httptest.NewServer(h.handler(server.NewSSEServer(s)))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/sse")
+	if err != nil {
+		t.Fatalf("GET /sse: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized || wrapped {
+		t.Errorf("without token: status %d, middleware ran %v; want 401 before the middleware", resp.StatusCode, wrapped)
+	}
+
+	// Other handlers authenticate on their own
+	resp, err = http.Post(srv.URL+"/hook", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("POST /hook: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("/hook: status %d, want 204", resp.StatusCode)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/sse", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /sse with token: %v", err)
+	}
+	defer resp.Body.Close()
+	line, _ := bufio.NewReader(resp.Body).ReadString('\n')
+	if resp.StatusCode != http.StatusOK || line != "event: endpoint\n" || !wrapped {
+		t.Errorf("with token: status %d, first line %q, middleware ran %v", resp.StatusCode, line, wrapped)
+	}
+}
+
+func TestHTTP_ListenShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- NewHTTP(server.NewMCPServer("test", "0.0.0"), "127.0.0.1:0", "").Listen(ctx) }()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Listen() = %v, want nil after cancel", err)
+		}
+	case <-time.After(shutdownTimeout + time.Second):
+		t.Fatal("Listen() did not return after cancel")
+	}
+}
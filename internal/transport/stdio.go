@@ -0,0 +1,390 @@
+// Package transport serves the MCP server over stdio or HTTP. Unlike the stdio server
+// shipped with mcp-go, it runs long requests concurrently and honours
+// notifications/cancelled, so stopping a tool call in the client aborts the
+// handler's context and every upstream request made with it. Its session
+// implements mcp-go's elicitation, so tool calls can ask the user for
+// missing input with the server's RequestElicitation.
+//
+// The HTTP transport uses the server-sent events server of mcp-go, and can
+// serve other handlers, such as the alert webhook, on the same port.
+package transport
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	mcp "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// maxMessageSize bounds a single JSON-RPC message read from stdin.
+const maxMessageSize = 16 * 1024 * 1024
+
+// concurrentMethods are handled on their own goroutine and can be cancelled.
+// Everything else (initialize, list calls, notifications) is handled in order.
+var concurrentMethods = map[string]bool{
+	string(mcp.MethodToolsCall):     true,
+	string(mcp.MethodResourcesRead): true,
+	string(mcp.MethodPromptsGet):    true,
+}
+
+// session is the single client session of a stdio connection.
+type session struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+	initialized   atomic.Bool
+	transport     *Stdio
+
+	mu           sync.RWMutex
+	clientInfo   mcp.Implementation
+	capabilities mcp.ClientCapabilities
+}
+
+func (s *session) SessionID() string { return s.id }
+
+// ClientInfo returns the client's name and version as "name/version", or ""
+// before initialize.
+func (s *session) ClientInfo() string {
+	info := s.GetClientInfo()
+	return strings.TrimSuffix(info.Name+"/"+info.Version, "/")
+}
+
+func (s *session) NotificationChannel() chan<- mcp.JSONRPCNotification { return s.notifications }
+
+func (s *session) Initialize() { s.initialized.Store(true) }
+
+func (s *session) Initialized() bool { return s.initialized.Load() }
+
+func (s *session) GetClientInfo() mcp.Implementation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.clientInfo
+}
+
+func (s *session) SetClientInfo(info mcp.Implementation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clientInfo = info
+}
+
+func (s *session) GetClientCapabilities() mcp.ClientCapabilities {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.capabilities
+}
+
+func (s *session) SetClientCapabilities(capabilities mcp.ClientCapabilities) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.capabilities = capabilities
+}
+
+// RequestElicitation sends an elicitation/create request to the client and
+// waits for the user's response or for ctx to be done. It fails with
+// server.ErrElicitationNotSupported when the client did not declare the
+// elicitation capability.
+func (s *session) RequestElicitation(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	if s.GetClientCapabilities().Elicitation == nil {
+		return nil, server.ErrElicitationNotSupported
+	}
+	var result mcp.ElicitationResult
+	if err := s.transport.request(ctx, mcp.MethodElicitationCreate, request.Params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+var (
+	_ server.SessionWithClientInfo  = (*session)(nil)
+	_ server.SessionWithElicitation = (*session)(nil)
+)
+
+// envelope holds the JSON-RPC fields needed to route a message. Responses to
+// requests sent by the server have an ID but no method.
+type envelope struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is a JSON-RPC error object.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// cancelledParams are the params of notifications/cancelled.
+type cancelledParams struct {
+	RequestID json.RawMessage `json:"requestId"`
+	Reason    string          `json:"reason,omitempty"`
+}
+
+// inflight tracks a running request so it can be cancelled.
+type inflight struct {
+	cancel    context.CancelFunc
+	cancelled atomic.Bool
+}
+
+// Stdio serves an MCP server over a line-delimited JSON-RPC stream.
+type Stdio struct {
+	server  *server.MCPServer
+	session *session
+
+	writeMu sync.Mutex
+	out     io.Writer
+
+	mu       sync.Mutex
+	inflight map[string]*inflight
+	wg       sync.WaitGroup
+
+	// pending are the requests sent to the client, by JSON-RPC id, that
+	// wait for its response.
+	nextID    atomic.Int64
+	pendingMu sync.Mutex
+	pending   map[string]chan envelope
+}
+
+// NewStdio creates a stdio transport for s.
+func NewStdio(s *server.MCPServer) *Stdio {
+	t := &Stdio{
+		server:   s,
+		inflight: make(map[string]*inflight),
+		pending:  make(map[string]chan envelope),
+	}
+	t.session = &session{id: newSessionID(), notifications: make(chan mcp.JSONRPCNotification, 100), transport: t}
+	return t
+}
+
+// newSessionID returns a random ID that tells the connections of different
+// server processes apart, e.g. in the audit log.
+func newSessionID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "stdio-" + hex.EncodeToString(b)
+}
+
+// Listen reads requests from in and writes responses to out until in is
+// closed or ctx is cancelled. Cancelling ctx cancels all in-flight requests.
+func (t *Stdio) Listen(ctx context.Context, in io.Reader, out io.Writer) error {
+	t.out = out
+
+	if err := t.server.RegisterSession(ctx, t.session); err != nil {
+		return fmt.Errorf("register session: %w", err)
+	}
+	defer t.server.UnregisterSession(ctx, t.session.SessionID())
+
+	ctx, cancel := context.WithCancel(t.server.WithContext(ctx, t.session))
+	defer cancel()
+
+	go t.forwardNotifications(ctx)
+
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+		for scanner.Scan() {
+			line := append([]byte(nil), scanner.Bytes()...)
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr <- scanner.Err()
+	}()
+
+	var err error
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			break loop
+		case err = <-readErr:
+			break loop
+		case line := <-lines:
+			t.handle(ctx, line)
+		}
+	}
+
+	// On shutdown, abort anything still running. When stdin is simply closed,
+	// let in-flight requests finish so their responses are still written.
+	if err != nil {
+		cancel()
+	}
+	t.wg.Wait()
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// handle routes one message.
+func (t *Stdio) handle(ctx context.Context, line []byte) {
+	if len(line) == 0 {
+		return
+	}
+
+	var env envelope
+	if err := json.Unmarshal(line, &env); err != nil {
+		t.write(mcp.JSONRPCError{
+			JSONRPC: mcp.JSONRPC_VERSION,
+			Error: struct {
+				Code    int         `json:"code"`
+				Message string      `json:"message"`
+				Data    interface{} `json:"data,omitempty"`
+			}{Code: mcp.PARSE_ERROR, Message: "Parse error"},
+		})
+		return
+	}
+
+	if env.Method == "" && len(env.ID) > 0 {
+		t.resolve(env)
+		return
+	}
+
+	if env.Method == "notifications/cancelled" {
+		var p cancelledParams
+		if err := json.Unmarshal(env.Params, &p); err == nil {
+			t.cancelRequest(string(p.RequestID), p.Reason)
+		}
+		return
+	}
+
+	if len(env.ID) == 0 || !concurrentMethods[env.Method] {
+		if resp := t.server.HandleMessage(ctx, line); resp != nil {
+			t.write(resp)
+		}
+		return
+	}
+
+	key := string(env.ID)
+	reqCtx, cancel := context.WithCancel(ctx)
+	req := &inflight{cancel: cancel}
+	t.mu.Lock()
+	t.inflight[key] = req
+	t.mu.Unlock()
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		defer cancel()
+
+		resp := t.server.HandleMessage(reqCtx, line)
+
+		t.mu.Lock()
+		if t.inflight[key] == req {
+			delete(t.inflight, key)
+		}
+		t.mu.Unlock()
+
+		// The client does not expect a response to a request it cancelled.
+		if resp != nil && !req.cancelled.Load() {
+			t.write(resp)
+		}
+	}()
+}
+
+// cancelRequest cancels the in-flight request with the given JSON-RPC id.
+func (t *Stdio) cancelRequest(id, reason string) {
+	t.mu.Lock()
+	req, ok := t.inflight[id]
+	t.mu.Unlock()
+	if !ok {
+		return
+	}
+	req.cancelled.Store(true)
+	req.cancel()
+	slog.Debug("request cancelled by client", "id", id, "reason", reason)
+}
+
+// request sends a request to the client and decodes the result of its
+// response into result, or returns when ctx is done.
+func (t *Stdio) request(ctx context.Context, method mcp.MCPMethod, params interface{}, result interface{}) error {
+	id := fmt.Sprintf("server-%d", t.nextID.Add(1))
+	key, _ := json.Marshal(id)
+	ch := make(chan envelope, 1)
+	t.pendingMu.Lock()
+	t.pending[string(key)] = ch
+	t.pendingMu.Unlock()
+	defer func() {
+		t.pendingMu.Lock()
+		delete(t.pending, string(key))
+		t.pendingMu.Unlock()
+	}()
+
+	t.write(mcp.JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      mcp.NewRequestId(id),
+		Request: mcp.Request{Method: string(method)},
+		Params:  params,
+	})
+
+	select {
+	case resp := <-ch:
+		if resp.Error != nil {
+			return fmt.Errorf("%s failed: %s", method, resp.Error.Message)
+		}
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("invalid %s result: %w", method, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// resolve delivers a client response to the server request waiting for it.
+func (t *Stdio) resolve(env envelope) {
+	t.pendingMu.Lock()
+	ch, ok := t.pending[string(env.ID)]
+	t.pendingMu.Unlock()
+	if !ok {
+		slog.Debug("response to unknown request", "id", string(env.ID))
+		return
+	}
+	select {
+	case ch <- env:
+	default:
+	}
+}
+
+// forwardNotifications writes server notifications to the client.
+func (t *Stdio) forwardNotifications(ctx context.Context) {
+	for {
+		select {
+		case n := <-t.session.notifications:
+			t.write(n)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// write marshals msg and writes it as a single line.
+func (t *Stdio) write(msg interface{}) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		slog.Error("failed to marshal response", "error", err)
+		return
+	}
+
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	if _, err := fmt.Fprintf(t.out, "%s\n", data); err != nil {
+		slog.Error("failed to write response", "error", err)
+	}
+}
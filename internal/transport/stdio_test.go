@@ -0,0 +1,238 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	mcp "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// harness runs a Stdio transport over pipes.
+type harness struct {
+	in   *io.PipeWriter
+	out  *bufio.Scanner
+	done chan error
+}
+
+func newHarness(t *testing.T, s *server.MCPServer, ctx context.Context) *harness {
+	t.Helper()
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+
+	h := &harness{in: inW, out: bufio.NewScanner(outR), done: make(chan error, 1)}
+	go func() {
+		h.done <- NewStdio(s).Listen(ctx, inR, outW)
+		outW.Close()
+	}()
+	return h
+}
+
+func (h *harness) send(t *testing.T, msg string) {
+	t.Helper()
+	if _, err := io.WriteString(h.in, msg+"\n"); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+
+func (h *harness) next(t *testing.T) map[string]interface{} {
+	t.Helper()
+	if !h.out.Scan() {
+		t.Fatalf("expected a response, got EOF (%v)", h.out.Err())
+	}
+	var resp map[string]interface{}
+	if err := json.Unmarshal(h.out.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response %q: %v", h.out.Text(), err)
+	}
+	return resp
+}
+
+// newTestServer registers a "block" tool that waits for cancellation and an
+// "echo" tool that returns immediately.
+func newTestServer(started, cancelled chan struct{}) *server.MCPServer {
+	s := server.NewMCPServer("test", "0.0.0", server.WithToolCapabilities(false))
+	s.AddTool(mcp.NewTool("block"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		select {
+		case <-ctx.Done():
+			close(cancelled)
+			return mcp.NewToolResultError("cancelled"), nil
+		case <-time.After(5 * time.Second):
+			return mcp.NewToolResultText("finished"), nil
+		}
+	})
+	s.AddTool(mcp.NewTool("echo"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("echo"), nil
+	})
+	return s
+}
+
+func TestStdio_CancelledNotificationAbortsToolCall(t *testing.T) {
+	started, cancelled := make(chan struct{}), make(chan struct{})
+	h := newHarness(t, newTestServer(started, cancelled), context.Background())
+
+	h.send(t, `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"block"}}`)
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("block tool did not start")
+	}
+
+	// Other requests are served while the slow call is running.
+	h.send(t, `{"jsonrpc":"2.0","id":8,"method":"tools/call","params":{"name":"echo"}}`)
+	if resp := h.next(t); resp["id"] != float64(8) {
+		t.Fatalf("expected response to id 8, got %v", resp)
+	}
+
+	h.send(t, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7,"reason":"user stop"}}`)
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("tool context was not cancelled")
+	}
+
+	// No response is written for the cancelled request.
+	h.send(t, `{"jsonrpc":"2.0","id":9,"method":"ping"}`)
+	if resp := h.next(t); resp["id"] != float64(9) {
+		t.Fatalf("expected only the ping response, got %v", resp)
+	}
+
+	h.in.Close()
+	if err := <-h.done; err != nil {
+		t.Errorf("Listen() error = %v", err)
+	}
+}
+
+func TestStdio_ShutdownCancelsInFlight(t *testing.T) {
+	started, cancelled := make(chan struct{}), make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	h := newHarness(t, newTestServer(started, cancelled), ctx)
+
+	h.send(t, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"block"}}`)
+	<-started
+	go func() {
+		// Drain the aborted call's response
+		for h.out.Scan() {
+		}
+	}()
+	cancel()
+
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("in-flight call was not cancelled on shutdown")
+	}
+	select {
+	case err := <-h.done:
+		if err != nil {
+			t.Errorf("Listen() error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Listen() did not return after shutdown")
+	}
+}
+
+func TestStdio_ParseError(t *testing.T) {
+	h := newHarness(t, server.NewMCPServer("test", "0.0.0"), context.Background())
+
+	h.send(t, `{not json`)
+	resp := h.next(t)
+	errObj, ok := resp["error"].(map[string]interface{})
+	if !ok || errObj["code"] != float64(mcp.PARSE_ERROR) {
+		t.Errorf("expected parse error, got %v", resp)
+	}
+	h.in.Close()
+	<-h.done
+}
+
+func TestStdio_Elicitation(t *testing.T) {
+	s := server.NewMCPServer("test", "0.0.0", server.WithToolCapabilities(false), server.WithElicitation())
+	s.AddTool(mcp.NewTool("ask"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		res, err := server.ServerFromContext(ctx).RequestElicitation(ctx, mcp.ElicitationRequest{
+			Params: mcp.ElicitationParams{Message: "Which service?", RequestedSchema: map[string]interface{}{"type": "object"}},
+		})
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		content, _ := res.Content.(map[string]interface{})
+		service, _ := content["service"].(string)
+		return mcp.NewToolResultText(string(res.Action) + ":" + service), nil
+	})
+	h := newHarness(t, s, context.Background())
+
+	t.Run("unsupported", func(t *testing.T) {
+		h.send(t, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"c","version":"1"}}}`)
+		h.next(t)
+		h.send(t, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"ask"}}`)
+		resp := h.next(t)
+		if text := toolText(resp); text != server.ErrElicitationNotSupported.Error() {
+			t.Errorf("expected unsupported error, got %v", resp)
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		h.send(t, `{"jsonrpc":"2.0","id":3,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{"elicitation":{}},"clientInfo":{"name":"c","version":"1"}}}`)
+		h.next(t)
+		h.send(t, `{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"ask"}}`)
+
+		req := h.next(t)
+		if req["method"] != "elicitation/create" {
+			t.Fatalf("expected an elicitation request, got %v", req)
+		}
+		params := req["params"].(map[string]interface{})
+		if params["message"] != "Which service?" || params["requestedSchema"] == nil {
+			t.Errorf("unexpected elicitation params: %v", params)
+		}
+		id, _ := json.Marshal(req["id"])
+		h.send(t, `{"jsonrpc":"2.0","id":`+string(id)+`,"result":{"action":"accept","content":{"service":"cart"}}}`)
+
+		resp := h.next(t)
+		if resp["id"] != float64(4) || toolText(resp) != "accept:cart" {
+			t.Errorf("expected the tool to receive the answer, got %v", resp)
+		}
+	})
+
+	h.in.Close()
+	<-h.done
+}
+
+func TestStdio_Session(t *testing.T) {
+	s := server.NewMCPServer("test", "0.0.0", server.WithToolCapabilities(false))
+	s.AddTool(mcp.NewTool("whoami"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		session := server.ClientSessionFromContext(ctx)
+		return mcp.NewToolResultText(session.SessionID() + " " + session.(interface{ ClientInfo() string }).ClientInfo()), nil
+	})
+	h := newHarness(t, s, context.Background())
+
+	h.send(t, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"mcp-inspector","version":"0.9.2"}}}`)
+	h.next(t)
+	h.send(t, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"whoami"}}`)
+	text := toolText(h.next(t))
+	if !strings.HasPrefix(text, "stdio-") || !strings.HasSuffix(text, " mcp-inspector/0.9.2") {
+		t.Errorf("whoami = %q, want a stdio session ID and the client info", text)
+	}
+
+	if a, b := NewStdio(s).session.SessionID(), NewStdio(s).session.SessionID(); a == b {
+		t.Errorf("session IDs should be unique, got %q twice", a)
+	}
+
+	h.in.Close()
+	<-h.done
+}
+
+// toolText returns the first text content of a tools/call response.
+func toolText(resp map[string]interface{}) string {
+	result, _ := resp["result"].(map[string]interface{})
+	content, _ := result["content"].([]interface{})
+	if len(content) == 0 {
+		return ""
+	}
+	first, _ := content[0].(map[string]interface{})
+	text, _ := first["text"].(string)
+	return text
+}
@@ -0,0 +1,224 @@
+// Package truncate bounds the size of tool responses so large query results
+// do not exhaust the caller's context window.
+package truncate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// attributeKeys are the map keys elided first when a response is over budget.
+var attributeKeys = map[string]bool{
+	"attributes":          true,
+	"resource_attributes": true,
+}
+
+// Limits bounds a tool response. Zero values mean unlimited.
+type Limits struct {
+	// MaxBytes caps the encoded size of the response data and markdown.
+	MaxBytes int
+	// MaxItems caps the number of records returned.
+	MaxItems int
+}
+
+// Enabled reports whether any limit is set.
+func (l Limits) Enabled() bool {
+	return l.MaxBytes > 0 || l.MaxItems > 0
+}
+
+// Report describes what was removed from a response.
+type Report struct {
+	Truncated           bool `json:"truncated"`
+	OriginalItems       int  `json:"original_items,omitempty"`
+	ReturnedItems       int  `json:"returned_items,omitempty"`
+	ElidedAttributeMaps int  `json:"elided_attribute_maps,omitempty"`
+	OriginalBytes       int  `json:"original_bytes,omitempty"`
+	ReturnedBytes       int  `json:"returned_bytes,omitempty"`
+}
+
+// ParseLimits reads max_response_bytes and max_items from tool arguments,
+// falling back to defaults for any that are absent.
+func ParseLimits(args map[string]interface{}, defaults Limits) (Limits, error) {
+	l := defaults
+	if v, ok := args["max_response_bytes"].(float64); ok {
+		if v < 0 {
+			return l, fmt.Errorf("max_response_bytes must not be negative")
+		}
+		l.MaxBytes = int(v)
+	}
+	if v, ok := args["max_items"].(float64); ok {
+		if v < 0 {
+			return l, fmt.Errorf("max_items must not be negative")
+		}
+		l.MaxItems = int(v)
+	}
+	return l, nil
+}
+
+// WithSchemaProperties adds the max_response_bytes and max_items arguments to
+// a tool's input schema properties and returns them.
+func WithSchemaProperties(props map[string]interface{}) map[string]interface{} {
+	props["max_response_bytes"] = map[string]interface{}{
+		"type":        "integer",
+		"description": "Truncate the response to roughly this many bytes, eliding attribute maps and trimming lists first (default: server setting, 0 = unlimited)",
+	}
+	props["max_items"] = map[string]interface{}{
+		"type":        "integer",
+		"description": "Return at most this many records (default: server setting, 0 = unlimited)",
+	}
+	return props
+}
+
+// Items trims items to l.MaxItems and records the counts in r.
+func Items[T any](items []T, l Limits, r *Report) []T {
+	r.OriginalItems = len(items)
+	if l.MaxItems > 0 && len(items) > l.MaxItems {
+		items = items[:l.MaxItems]
+		r.Truncated = true
+	}
+	r.ReturnedItems = len(items)
+	return items
+}
+
+// Data fits data into l.MaxBytes when encoded as JSON. It first replaces
+// attribute maps with a key count, then repeatedly halves the longest arrays.
+// Data that already fits is returned unchanged; otherwise the result is a
+// generic JSON value (maps, slices, and scalars).
+func Data(data interface{}, l Limits, r *Report) interface{} {
+	if l.MaxBytes <= 0 {
+		return data
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil || len(encoded) <= l.MaxBytes {
+		return data
+	}
+	r.Truncated = true
+	r.OriginalBytes = len(encoded)
+
+	var generic interface{}
+	if err := json.Unmarshal(encoded, &generic); err != nil {
+		return data
+	}
+
+	r.ElidedAttributeMaps += elideAttributes(generic)
+	size := encodedSize(generic)
+
+	for size > l.MaxBytes {
+		if !halveLongestArray(generic) {
+			break
+		}
+		size = encodedSize(generic)
+	}
+
+	r.ReturnedBytes = size
+	return generic
+}
+
+// Markdown cuts md at a line boundary so it fits within l.MaxBytes, leaving
+// room for a trailing note.
+func Markdown(md string, l Limits, r *Report) string {
+	const note = "\n\n_Response truncated by max_response_bytes; narrow the query or raise the limit._\n"
+	if l.MaxBytes <= 0 || len(md) <= l.MaxBytes {
+		return md
+	}
+	r.Truncated = true
+
+	budget := l.MaxBytes - len(note)
+	if budget < 0 {
+		budget = 0
+	}
+	cut := md[:budget]
+	if i := strings.LastIndex(cut, "\n"); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + note
+}
+
+// Apply bounds data and markdown to l and, when limits are set, annotates
+// map data with the truncation report.
+func Apply(data interface{}, md string, l Limits, r *Report) (interface{}, string) {
+	if !l.Enabled() {
+		return data, md
+	}
+	data = Data(data, l, r)
+	md = Markdown(md, l, r)
+	Annotate(data, *r)
+	return data, md
+}
+
+// Annotate adds truncated and truncation fields to a map result.
+func Annotate(data interface{}, r Report) {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return
+	}
+	m["truncated"] = r.Truncated
+	if r.Truncated {
+		m["truncation"] = r
+	}
+}
+
+// elideAttributes replaces attribute maps in v with a count of their keys and
+// returns the number of maps elided.
+func elideAttributes(v interface{}) int {
+	count := 0
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			if attrs, ok := child.(map[string]interface{}); ok && attributeKeys[k] && len(attrs) > 0 {
+				t[k] = map[string]interface{}{"_elided_keys": len(attrs)}
+				count++
+				continue
+			}
+			count += elideAttributes(child)
+		}
+	case []interface{}:
+		for _, child := range t {
+			count += elideAttributes(child)
+		}
+	}
+	return count
+}
+
+// halveLongestArray finds the longest array with more than one element and
+// drops its second half. It returns false when no array can be shortened.
+func halveLongestArray(root interface{}) bool {
+	var longest []interface{}
+	var set func([]interface{})
+
+	var walk func(v interface{}, assign func([]interface{}))
+	walk = func(v interface{}, assign func([]interface{})) {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			for k, child := range t {
+				k := k
+				walk(child, func(s []interface{}) { t[k] = s })
+			}
+		case []interface{}:
+			if len(t) > 1 && len(t) > len(longest) {
+				longest, set = t, assign
+			}
+			for i, child := range t {
+				i := i
+				walk(child, func(s []interface{}) { t[i] = s })
+			}
+		}
+	}
+	walk(root, nil)
+
+	if longest == nil || set == nil {
+		return false
+	}
+	set(longest[:(len(longest)+1)/2])
+	return true
+}
+
+func encodedSize(v interface{}) int {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(b)
+}
@@ -0,0 +1,153 @@
+package truncate
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseLimits(t *testing.T) {
+	defaults := Limits{MaxBytes: 1000, MaxItems: 10}
+
+	tests := []struct {
+		name    string
+		args    map[string]interface{}
+		want    Limits
+		wantErr string
+	}{
+		{name: "defaults", args: map[string]interface{}{}, want: defaults},
+		{name: "override", args: map[string]interface{}{"max_response_bytes": float64(500), "max_items": float64(3)}, want: Limits{MaxBytes: 500, MaxItems: 3}},
+		{name: "zero disables", args: map[string]interface{}{"max_items": float64(0)}, want: Limits{MaxBytes: 1000}},
+		{name: "negative bytes", args: map[string]interface{}{"max_response_bytes": float64(-1)}, wantErr: "max_response_bytes must not be negative"},
+		{name: "negative items", args: map[string]interface{}{"max_items": float64(-1)}, wantErr: "max_items must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLimits(tt.args, defaults)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLimits() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestItems(t *testing.T) {
+	var r Report
+	got := Items([]int{1, 2, 3, 4, 5}, Limits{MaxItems: 2}, &r)
+	if len(got) != 2 || !r.Truncated || r.OriginalItems != 5 || r.ReturnedItems != 2 {
+		t.Errorf("Items() = %v, report = %+v", got, r)
+	}
+
+	r = Report{}
+	got = Items([]int{1, 2}, Limits{MaxItems: 5}, &r)
+	if len(got) != 2 || r.Truncated {
+		t.Errorf("Items() under limit = %v, report = %+v", got, r)
+	}
+}
+
+func TestData_ElidesAttributesFirst(t *testing.T) {
+	type record struct {
+		Name       string                 `json:"name"`
+		Attributes map[string]interface{} `json:"attributes"`
+	}
+	records := []record{
+		{Name: "a", Attributes: map[string]interface{}{"k1": strings.Repeat("x", 200), "k2": "y"}},
+		{Name: "b", Attributes: map[string]interface{}{"k1": strings.Repeat("x", 200)}},
+	}
+	data := map[string]interface{}{"records": records}
+
+	var r Report
+	out := Data(data, Limits{MaxBytes: 200}, &r)
+
+	if !r.Truncated || r.ElidedAttributeMaps != 2 {
+		t.Fatalf("report = %+v, expected 2 elided attribute maps", r)
+	}
+	got := out.(map[string]interface{})["records"].([]interface{})
+	if len(got) != 2 {
+		t.Errorf("records = %d, expected both kept once attributes were elided", len(got))
+	}
+	first := got[0].(map[string]interface{})["attributes"].(map[string]interface{})
+	if first["_elided_keys"] != 2 {
+		t.Errorf("attributes = %v, expected _elided_keys 2", first)
+	}
+	if r.ReturnedBytes > 200 {
+		t.Errorf("ReturnedBytes = %d, expected <= 200", r.ReturnedBytes)
+	}
+}
+
+func TestData_HalvesArraysUntilFit(t *testing.T) {
+	items := make([]string, 100)
+	for i := range items {
+		items[i] = "item-value"
+	}
+	data := map[string]interface{}{"items": items, "count": 100}
+
+	var r Report
+	out := Data(data, Limits{MaxBytes: 300}, &r)
+
+	encoded, _ := json.Marshal(out)
+	if len(encoded) > 300 {
+		t.Errorf("encoded size = %d, expected <= 300", len(encoded))
+	}
+	if n := len(out.(map[string]interface{})["items"].([]interface{})); n == 0 || n >= 100 {
+		t.Errorf("items = %d, expected a non-empty prefix", n)
+	}
+	if r.OriginalBytes <= 300 || !r.Truncated {
+		t.Errorf("report = %+v", r)
+	}
+}
+
+func TestData_UnderBudgetUnchanged(t *testing.T) {
+	data := map[string]interface{}{"items": []int{1, 2}}
+	var r Report
+	out := Data(data, Limits{MaxBytes: 1000}, &r)
+	if _, ok := out.(map[string]interface{})["items"].([]int); !ok || r.Truncated {
+		t.Errorf("expected data returned unchanged, got %#v (report %+v)", out, r)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	md := strings.Repeat("| row | value |\n", 100)
+	var r Report
+	out := Markdown(md, Limits{MaxBytes: 300}, &r)
+
+	if len(out) > 300 {
+		t.Errorf("len = %d, expected <= 300", len(out))
+	}
+	if !strings.Contains(out, "truncated by max_response_bytes") || !r.Truncated {
+		t.Errorf("expected truncation note, got %q", out)
+	}
+	if strings.Contains(strings.SplitN(out, "\n\n_", 2)[0], "| row | val\n") {
+		t.Error("expected cut at a line boundary")
+	}
+}
+
+func TestApply_Annotates(t *testing.T) {
+	data := map[string]interface{}{"items": []int{1}}
+	var r Report
+
+	out, md := Apply(data, "ok", Limits{}, &r)
+	if _, ok := out.(map[string]interface{})["truncated"]; ok || md != "ok" {
+		t.Error("disabled limits should leave the result untouched")
+	}
+
+	r = Report{Truncated: true, OriginalItems: 5, ReturnedItems: 1}
+	out, _ = Apply(data, "ok", Limits{MaxItems: 1}, &r)
+	m := out.(map[string]interface{})
+	if m["truncated"] != true {
+		t.Errorf("truncated = %v, expected true", m["truncated"])
+	}
+	if rep, ok := m["truncation"].(Report); !ok || rep.OriginalItems != 5 {
+		t.Errorf("truncation = %#v", m["truncation"])
+	}
+}
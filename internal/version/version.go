@@ -0,0 +1,51 @@
+// Package version describes the running build of the server.
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Version is the version of the server, reported to MCP clients on
+// initialize, in self-telemetry, and by dash0_server_version.
+const Version = "1.0.0"
+
+// Info describes the running build.
+type Info struct {
+	Version string `json:"version"`
+	// Module is the version of the Go module the binary was built from,
+	// "(devel)" for a local build.
+	Module       string `json:"module,omitempty"`
+	Revision     string `json:"revision,omitempty"`
+	RevisionTime string `json:"revision_time,omitempty"`
+	// Modified is set when the working tree had uncommitted changes.
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// Get returns the Info of the running binary. The module and VCS fields
+// are empty when the binary carries no build information, e.g. in tests.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Module = build.Main.Version
+	for _, s := range build.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Revision = s.Value
+		case "vcs.time":
+			info.RevisionTime = s.Value
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	return info
+}
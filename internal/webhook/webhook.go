@@ -0,0 +1,396 @@
+// Package webhook receives Dash0 alert notifications over HTTP and forwards
+// them to the connected MCP sessions as notifications/message log messages,
+// so agents can react to alerts as they fire. Alerts can be filtered by
+// label for all sessions and, with the alert_labels query parameter of the
+// SSE connection, per session.
+package webhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/npcomplete777/dash0-mcp/internal/listing/fields"
+
+	mcp "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// Path is where the receiver is mounted.
+	Path = "/webhooks/dash0"
+
+	// Logger names the source of the log messages.
+	Logger = "dash0.alerts"
+
+	// SessionFilterParam is the query parameter of the SSE connection that
+	// sets the session's label filter, e.g. ?alert_labels=severity=critical.
+	SessionFilterParam = "alert_labels"
+
+	// maxPayloadSize bounds a webhook request body.
+	maxPayloadSize = 1 << 20
+)
+
+// Alert is one alert of a webhook payload.
+type Alert struct {
+	ID string `json:"id,omitempty"`
+	// Name is the check rule, or the alertname label.
+	Name string `json:"name,omitempty"`
+	// Status is the Dash0 issue status (critical, degraded, resolved) or the
+	// Alertmanager status (firing, resolved).
+	Status      string            `json:"status"`
+	Summary     string            `json:"summary,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Labels      map[string]string `json:"labels"`
+	StartsAt    string            `json:"starts_at,omitempty"`
+	EndsAt      string            `json:"ends_at,omitempty"`
+	URL         string            `json:"url,omitempty"`
+}
+
+// Level returns the MCP log level of the alert: info once resolved,
+// otherwise critical or warning by status or severity label, and error when
+// neither says.
+func (a Alert) Level() mcp.LoggingLevel {
+	status := strings.ToLower(a.Status)
+	switch status {
+	case "resolved", "closed", "ok", "inactive":
+		return mcp.LoggingLevelInfo
+	}
+	for _, s := range []string{status, strings.ToLower(a.Labels["severity"])} {
+		switch s {
+		case "critical":
+			return mcp.LoggingLevelCritical
+		case "degraded", "warning":
+			return mcp.LoggingLevelWarning
+		}
+	}
+	return mcp.LoggingLevelError
+}
+
+// Parse reads the alerts of a Dash0 webhook payload ({"type", "data":
+// {"issue": {...}}}) or of an Alertmanager-style payload ({"alerts":
+// [...]}), as sent by Prometheus-compatible notification channels.
+func Parse(body []byte) ([]Alert, error) {
+	var payload struct {
+		Type string `json:"type"`
+		Data struct {
+			Issue map[string]interface{} `json:"issue"`
+		} `json:"data"`
+		Alerts []map[string]interface{} `json:"alerts"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	switch {
+	case payload.Data.Issue != nil:
+		alert := parseIssue(payload.Data.Issue)
+		if alert.Status == "" && strings.HasSuffix(payload.Type, ".resolved") {
+			alert.Status = "resolved"
+		}
+		return []Alert{alert}, nil
+	case payload.Alerts != nil:
+		alerts := make([]Alert, len(payload.Alerts))
+		for i, a := range payload.Alerts {
+			alerts[i] = parseAlertmanager(a)
+		}
+		return alerts, nil
+	}
+	return nil, errors.New("invalid payload: expected data.issue or alerts")
+}
+
+// parseIssue reads a Dash0 issue.
+func parseIssue(issue map[string]interface{}) Alert {
+	labels := parseLabels(issue["labels"])
+	annotations := parseLabels(issue["annotations"])
+	alert := Alert{
+		ID:          fields.String(issue, "id", "issueIdentifier"),
+		Status:      fields.String(issue, "status"),
+		Summary:     coalesce(fields.String(issue, "summary"), annotations["summary"]),
+		Description: coalesce(fields.String(issue, "description"), annotations["description"]),
+		Labels:      labels,
+		StartsAt:    fields.String(issue, "start"),
+		EndsAt:      fields.String(issue, "end"),
+		URL:         fields.String(issue, "url"),
+	}
+	if rule, ok := issue["checkrule"].(map[string]interface{}); ok {
+		alert.Name = fields.String(rule, "name")
+	} else if rules, ok := issue["checkrules"].([]interface{}); ok && len(rules) > 0 {
+		if rule, ok := rules[0].(map[string]interface{}); ok {
+			alert.Name = fields.String(rule, "name")
+		}
+	}
+	if alert.Name == "" {
+		alert.Name = labels["alertname"]
+	}
+	return alert
+}
+
+// parseAlertmanager reads one alert of an Alertmanager payload.
+func parseAlertmanager(a map[string]interface{}) Alert {
+	labels := parseLabels(a["labels"])
+	annotations := parseLabels(a["annotations"])
+	return Alert{
+		ID:          fields.String(a, "fingerprint"),
+		Name:        labels["alertname"],
+		Status:      fields.String(a, "status"),
+		Summary:     annotations["summary"],
+		Description: annotations["description"],
+		Labels:      labels,
+		StartsAt:    fields.String(a, "startsAt"),
+		EndsAt:      fields.String(a, "endsAt"),
+		URL:         fields.String(a, "generatorURL"),
+	}
+}
+
+// parseLabels reads labels given as an object or as a list of OTLP
+// key-value pairs.
+func parseLabels(raw interface{}) map[string]string {
+	labels := map[string]string{}
+	switch v := raw.(type) {
+	case map[string]interface{}:
+		for k, value := range v {
+			labels[k] = fmt.Sprint(value)
+		}
+	case []interface{}:
+		for _, item := range v {
+			kv, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			key, _ := kv["key"].(string)
+			if key == "" {
+				continue
+			}
+			switch value := kv["value"].(type) {
+			case map[string]interface{}:
+				// An OTLP AnyValue holds a single typed value
+				for _, typed := range value {
+					labels[key] = fmt.Sprint(typed)
+				}
+			case nil:
+				labels[key] = ""
+			default:
+				labels[key] = fmt.Sprint(value)
+			}
+		}
+	}
+	return labels
+}
+
+// coalesce returns the first non-empty string.
+func coalesce(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Filter selects alerts by label: every key must have one of its values.
+// An empty filter matches every alert.
+type Filter map[string][]string
+
+// ParseFilter parses "key=value,key=value" filters. A value may list
+// alternatives separated by "|", e.g. "severity=critical|warning".
+func ParseFilter(s string) (Filter, error) {
+	f := Filter{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("label filter %q must be key=value", part)
+		}
+		for _, v := range strings.Split(value, "|") {
+			f[key] = append(f[key], strings.TrimSpace(v))
+		}
+	}
+	return f, nil
+}
+
+// Matches reports whether labels satisfy the filter.
+func (f Filter) Matches(labels map[string]string) bool {
+	for key, values := range f {
+		value, ok := labels[key]
+		if !ok || !containsString(values, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// String renders the filter in the form ParseFilter reads.
+func (f Filter) String() string {
+	parts := make([]string, 0, len(f))
+	for key, values := range f {
+		parts = append(parts, key+"="+strings.Join(values, "|"))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+type filterKey struct{}
+
+// withFilter returns a context carrying a session's label filter.
+func withFilter(ctx context.Context, f Filter) context.Context {
+	return context.WithValue(ctx, filterKey{}, f)
+}
+
+// subscriber is a session receiving alerts.
+type subscriber struct {
+	session server.ClientSession
+	filter  Filter
+}
+
+// Receiver is the webhook endpoint. It tracks the sessions of the MCP
+// server through its hooks.
+type Receiver struct {
+	token  string
+	filter Filter
+
+	mu       sync.Mutex
+	sessions map[string]subscriber
+}
+
+// New creates a receiver that accepts requests bearing token and forwards
+// the alerts matching filter.
+func New(token string, filter Filter) *Receiver {
+	return &Receiver{token: token, filter: filter, sessions: make(map[string]subscriber)}
+}
+
+// AddHooks makes the receiver track the sessions registered with the
+// server the hooks are installed in.
+func (r *Receiver) AddHooks(hooks *server.Hooks) {
+	hooks.AddOnRegisterSession(func(ctx context.Context, session server.ClientSession) {
+		f, _ := ctx.Value(filterKey{}).(Filter)
+		r.mu.Lock()
+		r.sessions[session.SessionID()] = subscriber{session: session, filter: f}
+		r.mu.Unlock()
+	})
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		r.mu.Lock()
+		delete(r.sessions, session.SessionID())
+		r.mu.Unlock()
+	})
+}
+
+// SessionFilter reads the alert_labels query parameter of a request opening
+// a session into the session's filter. Requests with an invalid filter are
+// rejected.
+func (r *Receiver) SessionFilter(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if raw := req.URL.Query().Get(SessionFilterParam); raw != "" {
+			f, err := ParseFilter(raw)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			req = req.WithContext(withFilter(req.Context(), f))
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// ServeHTTP accepts a webhook payload and forwards its alerts. The token is
+// read from the Authorization header or, for senders that cannot set
+// headers, the token query parameter.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		token = req.URL.Query().Get("token")
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(r.token)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxPayloadSize))
+	if err != nil {
+		http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	alerts, err := Parse(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	forwarded, delivered := 0, 0
+	for _, alert := range alerts {
+		if !r.filter.Matches(alert.Labels) {
+			continue
+		}
+		forwarded++
+		delivered += r.forward(alert)
+	}
+	slog.Debug("webhook alerts received", "alerts", len(alerts), "forwarded", forwarded, "notifications", delivered)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{
+		"alerts":        len(alerts),
+		"forwarded":     forwarded,
+		"notifications": delivered,
+	})
+}
+
+// forward sends alert to the initialized sessions whose filter it matches
+// and returns the number of notifications sent. A session whose
+// notification queue is full misses the alert.
+func (r *Receiver) forward(alert Alert) int {
+	notification := mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: "notifications/message",
+			Params: mcp.NotificationParams{
+				AdditionalFields: map[string]interface{}{
+					"level":  alert.Level(),
+					"logger": Logger,
+					"data":   alert,
+				},
+			},
+		},
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sent := 0
+	for id, sub := range r.sessions {
+		if !sub.session.Initialized() || !sub.filter.Matches(alert.Labels) {
+			continue
+		}
+		select {
+		case sub.session.NotificationChannel() <- notification:
+			sent++
+		default:
+			slog.Warn("alert dropped, session notification queue is full", "session", id, "alert", alert.Name)
+		}
+	}
+	return sent
+}
@@ -0,0 +1,232 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mcp "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const issuePayload = `{
+	"type": "alert.ongoing",
+	"data": {
+		"issue": {
+			"id": "issue-1",
+			"status": "critical",
+			"summary": "High error rate on checkout",
+			"start": "2026-10-17T10:00:00Z",
+			"url": "https://app.dash0.com/alerting/issue-1",
+			"checkrule": {"name": "Checkout errors"},
+			"labels": [
+				{"key": "service.name", "value": {"stringValue": "checkout"}},
+				{"key": "team", "value": {"stringValue": "payments"}}
+			]
+		}
+	}
+}`
+
+const alertmanagerPayload = `{
+	"alerts": [
+		{"status": "firing", "labels": {"alertname": "HighLatency", "severity": "warning", "team": "payments"}, "annotations": {"summary": "p99 above 2s"}, "fingerprint": "a1"},
+		{"status": "resolved", "labels": {"alertname": "PodCrashLooping", "team": "platform"}, "fingerprint": "b2"}
+	]
+}`
+
+func TestParse(t *testing.T) {
+	alerts, err := Parse([]byte(issuePayload))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("Parse() = %+v, want 1 alert", alerts)
+	}
+	a := alerts[0]
+	if a.ID != "issue-1" || a.Name != "Checkout errors" || a.Status != "critical" || a.Summary != "High error rate on checkout" {
+		t.Errorf("issue alert = %+v", a)
+	}
+	if a.Labels["service.name"] != "checkout" || a.Labels["team"] != "payments" {
+		t.Errorf("issue labels = %v", a.Labels)
+	}
+
+	alerts, err = Parse([]byte(alertmanagerPayload))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(alerts) != 2 || alerts[0].Name != "HighLatency" || alerts[0].Summary != "p99 above 2s" || alerts[1].Status != "resolved" {
+		t.Errorf("alertmanager alerts = %+v", alerts)
+	}
+
+	resolved, err := Parse([]byte(`{"type": "alert.resolved", "data": {"issue": {"id": "issue-1"}}}`))
+	if err != nil || resolved[0].Status != "resolved" {
+		t.Errorf("resolved issue = %+v, %v; want status resolved", resolved, err)
+	}
+
+	for _, body := range []string{`not json`, `{"type": "ping"}`} {
+		if _, err := Parse([]byte(body)); err == nil {
+			t.Errorf("Parse(%s) error = nil, want an error", body)
+		}
+	}
+}
+
+func TestAlertLevel(t *testing.T) {
+	tests := []struct {
+		alert Alert
+		want  mcp.LoggingLevel
+	}{
+		{Alert{Status: "critical"}, mcp.LoggingLevelCritical},
+		{Alert{Status: "degraded"}, mcp.LoggingLevelWarning},
+		{Alert{Status: "firing", Labels: map[string]string{"severity": "critical"}}, mcp.LoggingLevelCritical},
+		{Alert{Status: "firing", Labels: map[string]string{"severity": "warning"}}, mcp.LoggingLevelWarning},
+		{Alert{Status: "firing"}, mcp.LoggingLevelError},
+		{Alert{Status: "resolved", Labels: map[string]string{"severity": "critical"}}, mcp.LoggingLevelInfo},
+	}
+	for _, tt := range tests {
+		if got := tt.alert.Level(); got != tt.want {
+			t.Errorf("Level(%+v) = %s, want %s", tt.alert, got, tt.want)
+		}
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	f, err := ParseFilter(" severity=critical|warning , team=payments")
+	if err != nil {
+		t.Fatalf("ParseFilter() error = %v", err)
+	}
+	if f.String() != "severity=critical|warning,team=payments" {
+		t.Errorf("ParseFilter() = %s", f)
+	}
+	tests := []struct {
+		labels map[string]string
+		want   bool
+	}{
+		{map[string]string{"severity": "warning", "team": "payments", "env": "prod"}, true},
+		{map[string]string{"severity": "info", "team": "payments"}, false},
+		{map[string]string{"severity": "critical"}, false},
+	}
+	for _, tt := range tests {
+		if got := f.Matches(tt.labels); got != tt.want {
+			t.Errorf("Matches(%v) = %v, want %v", tt.labels, got, tt.want)
+		}
+	}
+
+	if empty, err := ParseFilter(""); err != nil || !empty.Matches(nil) {
+		t.Errorf("empty filter = %v, %v; want one matching every alert", empty, err)
+	}
+	if _, err := ParseFilter("severity"); err == nil {
+		t.Error("ParseFilter(\"severity\") error = nil, want an error")
+	}
+}
+
+// testSession is a client session with a buffered notification channel.
+type testSession struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+}
+
+func newTestSession(id string) *testSession {
+	return &testSession{id: id, notifications: make(chan mcp.JSONRPCNotification, 10)}
+}
+
+func (s *testSession) SessionID() string                                   { return s.id }
+func (s *testSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return s.notifications }
+func (s *testSession) Initialize()                                         {}
+func (s *testSession) Initialized() bool                                   { return true }
+
+// register registers session with s as the SSE transport would, through
+// the receiver's session filter middleware.
+func register(t *testing.T, r *Receiver, s *server.MCPServer, session *testSession, query string) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/sse"+query, nil)
+	rec := httptest.NewRecorder()
+	r.SessionFilter(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := s.RegisterSession(req.Context(), session); err != nil {
+			t.Fatalf("RegisterSession() error = %v", err)
+		}
+	})).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("session %s: status %d: %s", session.id, rec.Code, rec.Body)
+	}
+}
+
+func post(r *Receiver, target, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestReceiver(t *testing.T) {
+	filter, _ := ParseFilter("team=payments|platform")
+	r := New("secret", filter)
+	hooks := &server.Hooks{}
+	r.AddHooks(hooks)
+	s := server.NewMCPServer("test", "0.0.0", server.WithLogging(), server.WithHooks(hooks))
+
+	all, payments, platform := newTestSession("all"), newTestSession("payments"), newTestSession("platform")
+	register(t, r, s, all, "")
+	register(t, r, s, payments, "?alert_labels=team%3Dpayments")
+	register(t, r, s, platform, "?alert_labels=team%3Dplatform")
+
+	rec := post(r, Path, "secret", alertmanagerPayload)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var counts map[string]int
+	json.Unmarshal(rec.Body.Bytes(), &counts)
+	if counts["alerts"] != 2 || counts["forwarded"] != 2 || counts["notifications"] != 4 {
+		t.Errorf("counts = %v, want 2 alerts forwarded as 4 notifications", counts)
+	}
+	if len(all.notifications) != 2 || len(payments.notifications) != 1 || len(platform.notifications) != 1 {
+		t.Fatalf("notifications: all %d, payments %d, platform %d; want 2, 1, 1", len(all.notifications), len(payments.notifications), len(platform.notifications))
+	}
+	n := <-payments.notifications
+	params := n.Params.AdditionalFields
+	if n.Method != "notifications/message" || params["level"] != mcp.LoggingLevelWarning || params["logger"] != Logger {
+		t.Errorf("notification = %s %v", n.Method, params)
+	}
+	if alert, ok := params["data"].(Alert); !ok || alert.Name != "HighLatency" {
+		t.Errorf("notification data = %#v, want the HighLatency alert", params["data"])
+	}
+
+	// The server-wide filter drops alerts of other teams
+	rec = post(r, Path+"?token=secret", "", `{"alerts": [{"status": "firing", "labels": {"team": "search"}}]}`)
+	if rec.Code != http.StatusOK || len(all.notifications) != 2 {
+		t.Errorf("filtered alert: status %d, %d notifications; want it dropped", rec.Code, len(all.notifications))
+	}
+
+	// Unregistered sessions no longer receive alerts
+	s.UnregisterSession(context.Background(), "all")
+	post(r, Path, "secret", issuePayload)
+	if len(all.notifications) != 2 || len(payments.notifications) != 1 {
+		t.Errorf("after unregister: all %d, payments %d; want 2, 1", len(all.notifications), len(payments.notifications))
+	}
+}
+
+func TestReceiver_Rejects(t *testing.T) {
+	r := New("secret", Filter{})
+	if rec := post(r, Path, "wrong", issuePayload); rec.Code != http.StatusUnauthorized {
+		t.Errorf("wrong token: status %d, want 401", rec.Code)
+	}
+	if rec := post(r, Path, "secret", `{"type": "ping"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad payload: status %d, want 400", rec.Code)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: status %d, want 405", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	r.SessionFilter(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sse?alert_labels=team", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid session filter: status %d, want 400", rec.Code)
+	}
+}